@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/setting/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	// maxAbuseWords is the maximum number of words and phrases in the abuse word list.
+	maxAbuseWords = 1000
+	// maxAbuseWordLength is the maximum length of a word or phrase in the abuse word list.
+	maxAbuseWordLength = 100
+)
+
+// handleGetAbuseSettings returns the abusive message detection settings.
+func handleGetAbuseSettings(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		out = models.Abuse{}
+	)
+	b, err := app.setting.GetByPrefix("abuse")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+	}
+	if out.Words == nil {
+		out.Words = []string{}
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleUpdateAbuseSettings updates the abusive message detection settings.
+func handleUpdateAbuseSettings(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = models.Abuse{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+
+	// Trim and drop empty and duplicate words.
+	words := make([]string, 0, len(req.Words))
+	for _, w := range req.Words {
+		w = strings.TrimSpace(w)
+		if w == "" || slices.Contains(words, w) {
+			continue
+		}
+		if len(w) > maxAbuseWordLength {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxAbuseWordLength)), nil, envelope.InputError)
+		}
+		words = append(words, w)
+	}
+	if len(words) > maxAbuseWords {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	req.Words = words
+	req.Tag = strings.TrimSpace(req.Tag)
+
+	if req.NotifyTeamID < 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	if req.NotifyTeamID > 0 {
+		if _, err := app.team.Get(req.NotifyTeamID); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
+	if err := app.setting.Update(req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+type agentScheduleReq struct {
+	Timezone string         `json:"timezone"`
+	Hours    types.JSONText `json:"hours"`
+}
+
+// handleGetOnShiftAgents returns the agents currently within their working hours.
+func handleGetOnShiftAgents(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	shifts, err := app.user.GetAgentShifts(time.Now())
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	out := make([]umodels.AgentShift, 0, len(shifts))
+	for _, s := range shifts {
+		if s.OnShift {
+			out = append(out, s)
+		}
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleGetAgentSchedule returns the working hours of an agent, null if none are set.
+func handleGetAgentSchedule(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := scheduleAgentID(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	schedule, err := app.user.GetAgentSchedule(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(schedule)
+}
+
+// handleUpdateAgentSchedule sets the working hours of an agent.
+func handleUpdateAgentSchedule(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = agentScheduleReq{}
+	)
+	id, err := scheduleAgentID(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	if len(req.Hours) == 0 {
+		req.Hours = types.JSONText(`{}`)
+	}
+	schedule, err := app.user.UpsertAgentSchedule(id, req.Timezone, req.Hours)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(schedule)
+}
+
+// handleDeleteAgentSchedule removes the working hours of an agent.
+func handleDeleteAgentSchedule(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := scheduleAgentID(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.user.DeleteAgentSchedule(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetAgentScheduleOverrides returns the current and upcoming schedule overrides of an agent.
+func handleGetAgentScheduleOverrides(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := scheduleAgentID(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	overrides, err := app.user.GetAgentScheduleOverrides(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(overrides)
+}
+
+// handleCreateAgentScheduleOverride adds a schedule override, e.g. leave or an extra shift, for an agent.
+func handleCreateAgentScheduleOverride(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		override = umodels.AgentScheduleOverride{}
+	)
+	id, err := scheduleAgentID(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := r.Decode(&override, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	override.UserID = id
+	out, err := app.user.CreateAgentScheduleOverride(override)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleDeleteAgentScheduleOverride deletes a schedule override of an agent.
+func handleDeleteAgentScheduleOverride(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := scheduleAgentID(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	overrideID, err := strconv.Atoi(r.RequestCtx.UserValue("override_id").(string))
+	if err != nil || overrideID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	if err := app.user.DeleteAgentScheduleOverride(id, overrideID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// scheduleAgentID returns the ID of the agent in the request path after checking the agent exists.
+func scheduleAgentID(r *fastglue.Request) (int, error) {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id <= 0 {
+		return 0, envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	if _, err := app.user.GetAgent(id, ""); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
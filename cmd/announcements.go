@@ -0,0 +1,175 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/announcement/models"
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const maxAnnouncementLength = 2000
+
+// handleGetAnnouncements returns all announcements.
+func handleGetAnnouncements(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	announcements, err := app.announcement.GetAll()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(announcements)
+}
+
+// handleGetAnnouncement returns an announcement by ID.
+func handleGetAnnouncement(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	announcement, err := app.announcement.Get(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(announcement)
+}
+
+// handleGetActiveAnnouncements returns the announcements currently shown to the logged in agent.
+func handleGetActiveAnnouncements(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	agent, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	announcements, err := app.announcement.GetActive(agent.ID, agent.Teams.IDs())
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(announcements)
+}
+
+// handleCreateAnnouncement creates a new announcement.
+func handleCreateAnnouncement(r *fastglue.Request) error {
+	var (
+		app          = r.Context.(*App)
+		auser        = r.RequestCtx.UserValue("user").(amodels.User)
+		announcement = models.Announcement{}
+	)
+	if err := r.Decode(&announcement, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateAnnouncement(app, &announcement); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	announcement.CreatedByID = null.IntFrom(auser.ID)
+
+	result, err := app.announcement.Create(announcement)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateAnnouncement updates an announcement.
+func handleUpdateAnnouncement(r *fastglue.Request) error {
+	var (
+		app          = r.Context.(*App)
+		announcement = models.Announcement{}
+		id, _        = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&announcement, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateAnnouncement(app, &announcement); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	result, err := app.announcement.Update(id, announcement)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteAnnouncement deletes an announcement.
+func handleDeleteAnnouncement(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.announcement.Delete(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleAcknowledgeAnnouncement records the logged in agent's acknowledgement of an announcement.
+func handleAcknowledgeAnnouncement(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.announcement.Acknowledge(id, auser.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetAnnouncementAcknowledgements returns the agents who acknowledged an announcement.
+func handleGetAnnouncementAcknowledgements(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	acks, err := app.announcement.GetAcknowledgements(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(acks)
+}
+
+func validateAnnouncement(app *App, a *models.Announcement) error {
+	a.Message = strings.TrimSpace(a.Message)
+	if a.Message == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`message`"), nil)
+	}
+	if len(a.Message) > maxAnnouncementLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxAnnouncementLength)), nil)
+	}
+	switch a.Severity {
+	case "":
+		a.Severity = models.SeverityInfo
+	case models.SeverityInfo, models.SeverityWarning, models.SeverityCritical:
+	default:
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	if a.StartsAt.Valid && a.EndsAt.Valid && !a.EndsAt.Time.After(a.StartsAt.Time) {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	if a.TeamIDs == nil {
+		a.TeamIDs = []int64{}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/asset/models"
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	maxAssetNameLength        = 140
+	maxAssetDescriptionLength = 1000
+)
+
+// handleGetAssets returns all assets.
+func handleGetAssets(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	assets, err := app.asset.GetAll()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(assets)
+}
+
+// handleGetAvailableAssets returns the assets the logged in agent can attach to replies.
+func handleGetAvailableAssets(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	agent, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	assets, err := app.asset.GetAvailable(agent.Teams.IDs())
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(assets)
+}
+
+// handleCreateAsset creates an asset from an uploaded media file.
+func handleCreateAsset(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		asset = models.Asset{}
+	)
+	if err := r.Decode(&asset, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateAsset(app, &asset); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if asset.MediaID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.notFoundFile"), nil, envelope.InputError)
+	}
+	asset.CreatedByID = null.IntFrom(auser.ID)
+
+	result, err := app.asset.Create(asset)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateAsset updates an asset.
+func handleUpdateAsset(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		asset = models.Asset{}
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&asset, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateAsset(app, &asset); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	result, err := app.asset.Update(id, asset)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteAsset deletes an asset.
+func handleDeleteAsset(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.asset.Delete(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleUseAsset returns a copy of the file of an asset as a media file the logged in agent can attach to a reply.
+func handleUseAsset(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	agent, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	media, err := app.asset.Use(id, agent.Teams.IDs())
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(media)
+}
+
+func validateAsset(app *App, a *models.Asset) error {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Description = strings.TrimSpace(a.Description)
+	if a.Name == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if len(a.Name) > maxAssetNameLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxAssetNameLength)), nil)
+	}
+	if len(a.Description) > maxAssetDescriptionLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxAssetDescriptionLength)), nil)
+	}
+	if a.TeamIDs == nil {
+		a.TeamIDs = []int64{}
+	}
+	return nil
+}
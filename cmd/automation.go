@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"strconv"
+	"strings"
 
 	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
@@ -13,6 +15,12 @@ type updateAutomationRuleExecutionModeReq struct {
 	Mode string `json:"mode"`
 }
 
+// automationDryRunReq is a rule to evaluate against a conversation, referenced by its reference number.
+type automationDryRunReq struct {
+	Rules           json.RawMessage `json:"rules"`
+	ReferenceNumber string          `json:"reference_number"`
+}
+
 // handleGetAutomationRules gets all automation rules
 func handleGetAutomationRules(r *fastglue.Request) error {
 	var (
@@ -143,3 +151,43 @@ func handleUpdateAutomationRuleExecutionMode(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(true)
 }
+
+// handleGetAutomationRuleLogs returns the latest executions of an automation rule.
+func handleGetAutomationRuleLogs(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	logs, err := app.automation.GetRuleLogs(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(logs)
+}
+
+// handleAutomationRuleDryRun evaluates a rule against a conversation without applying its actions.
+func handleAutomationRuleDryRun(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = automationDryRunReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	req.ReferenceNumber = strings.TrimPrefix(strings.TrimSpace(req.ReferenceNumber), "#")
+	if req.ReferenceNumber == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.required", "name", "`reference_number`"), nil, envelope.InputError)
+	}
+	conversation, err := app.conversation.GetConversation(0, "", req.ReferenceNumber)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	results, err := app.automation.DryRun(req.Rules, conversation)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(results)
+}
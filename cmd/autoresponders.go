@@ -0,0 +1,146 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/autoresponder/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	maxAutoresponderNameLength    = 140
+	maxAutoresponderContentLength = 10000
+)
+
+// handleGetAutoresponders returns all autoresponders of an inbox.
+func handleGetAutoresponders(r *fastglue.Request) error {
+	var (
+		app        = r.Context.(*App)
+		inboxID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if inboxID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	autoresponders, err := app.autoresponder.GetAll(inboxID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(autoresponders)
+}
+
+// handleCreateAutoresponder creates an autoresponder for an inbox.
+func handleCreateAutoresponder(r *fastglue.Request) error {
+	var (
+		app           = r.Context.(*App)
+		inboxID, _    = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		autoresponder = models.Autoresponder{}
+	)
+	if inboxID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&autoresponder, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateAutoresponder(app, &autoresponder); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	autoresponder.InboxID = inboxID
+
+	result, err := app.autoresponder.Create(autoresponder)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateAutoresponder updates an autoresponder of an inbox.
+func handleUpdateAutoresponder(r *fastglue.Request) error {
+	var (
+		app           = r.Context.(*App)
+		inboxID, _    = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		id, _         = strconv.Atoi(r.RequestCtx.UserValue("autoresponder_id").(string))
+		autoresponder = models.Autoresponder{}
+	)
+	if inboxID <= 0 || id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&autoresponder, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateAutoresponder(app, &autoresponder); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	result, err := app.autoresponder.Update(inboxID, id, autoresponder)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteAutoresponder deletes an autoresponder of an inbox.
+func handleDeleteAutoresponder(r *fastglue.Request) error {
+	var (
+		app        = r.Context.(*App)
+		inboxID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		id, _      = strconv.Atoi(r.RequestCtx.UserValue("autoresponder_id").(string))
+	)
+	if inboxID <= 0 || id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.autoresponder.Delete(inboxID, id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+func validateAutoresponder(app *App, a *models.Autoresponder) error {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if len(a.Name) > maxAutoresponderNameLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxAutoresponderNameLength)), nil)
+	}
+	if strings.TrimSpace(a.Content) == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`content`"), nil)
+	}
+	if len(a.Content) > maxAutoresponderContentLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxAutoresponderContentLength)), nil)
+	}
+	if a.EndsAt.Valid && a.StartsAt.Valid && !a.EndsAt.Time.After(a.StartsAt.Time) {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+
+	switch a.Condition {
+	case models.ConditionAlways:
+	case models.ConditionOutsideBusinessHours, models.ConditionHoliday:
+		if a.BusinessHoursID.Int <= 0 || a.Timezone.String == "" {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+		if _, err := time.LoadLocation(a.Timezone.String); err != nil {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+	case models.ConditionHighBacklog:
+		if a.BacklogThreshold.Int <= 0 {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+	default:
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+
+	// Only keep the settings used by the condition.
+	if a.Condition != models.ConditionOutsideBusinessHours && a.Condition != models.ConditionHoliday {
+		a.BusinessHoursID = null.Int{}
+		a.Timezone = null.String{}
+	}
+	if a.Condition != models.ConditionHighBacklog {
+		a.BacklogThreshold = null.Int{}
+	}
+	return nil
+}
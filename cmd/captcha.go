@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/captcha"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/setting/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	realip "github.com/ferluci/fast-realip"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// honeypotField is a hidden input of public HTML forms that humans leave empty.
+const honeypotField = "website"
+
+// captchaTokenFields are the form fields CAPTCHA widgets submit their response token in.
+var captchaTokenFields = captcha.TokenFields()
+
+// getCaptchaVerifier returns the CAPTCHA verifier if CAPTCHA is enabled for the endpoint, nil otherwise.
+func getCaptchaVerifier(app *App, endpoint string) *captcha.Verifier {
+	app.Lock()
+	var (
+		provider  = ko.String("captcha.provider")
+		siteKey   = ko.String("captcha.site_key")
+		secret    = ko.String("captcha.secret_key")
+		endpoints = ko.Strings("captcha.endpoints")
+	)
+	app.Unlock()
+
+	if provider == "" || !slices.Contains(endpoints, endpoint) {
+		return nil
+	}
+	v, err := captcha.New(provider, siteKey, secret)
+	if err != nil {
+		app.lo.Error("error initializing captcha verifier", "provider", provider, "error", err)
+		return nil
+	}
+	return v
+}
+
+// getCaptchaWidget returns the CAPTCHA widget to render on the page of an endpoint, nil if disabled.
+func getCaptchaWidget(app *App, endpoint string) *captcha.Widget {
+	v := getCaptchaVerifier(app, endpoint)
+	if v == nil {
+		return nil
+	}
+	w := v.Widget()
+	return &w
+}
+
+// verifyCaptcha verifies the CAPTCHA response of a request to a protected endpoint.
+// The token is read from the X-Captcha-Token header or the provider's form field.
+// Requests pass if CAPTCHA is disabled for the endpoint. Verification fails closed.
+func verifyCaptcha(app *App, r *fastglue.Request, endpoint string) bool {
+	v := getCaptchaVerifier(app, endpoint)
+	if v == nil {
+		return true
+	}
+	token := string(r.RequestCtx.Request.Header.Peek("X-Captcha-Token"))
+	if token == "" {
+		token = string(r.RequestCtx.FormValue(v.TokenField()))
+	}
+	ok, err := v.Verify(token, realip.FromRequest(r.RequestCtx))
+	if err != nil {
+		app.lo.Error("error verifying captcha", "endpoint", endpoint, "error", err)
+		return false
+	}
+	return ok
+}
+
+// requireCaptcha rejects requests to a JSON endpoint that fail CAPTCHA verification.
+func requireCaptcha(handler fastglue.FastRequestHandler, endpoint string) fastglue.FastRequestHandler {
+	return func(r *fastglue.Request) error {
+		app := r.Context.(*App)
+		if !verifyCaptcha(app, r, endpoint) {
+			return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("validation.captchaFailed"), nil, envelope.PermissionError)
+		}
+		return handler(r)
+	}
+}
+
+// handleGetCaptchaSettings returns the CAPTCHA settings with the secret key masked.
+func handleGetCaptchaSettings(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		out = models.Captcha{}
+	)
+	b, err := app.setting.GetByPrefix("captcha")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+	}
+	if out.SecretKey != "" {
+		out.SecretKey = strings.Repeat(stringutil.PasswordDummy, 10)
+	}
+	if out.Endpoints == nil {
+		out.Endpoints = []string{}
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleUpdateCaptchaSettings updates the CAPTCHA settings.
+func handleUpdateCaptchaSettings(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = models.Captcha{}
+		cur = models.Captcha{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.badRequest"), nil, envelope.InputError)
+	}
+
+	req.Provider = strings.TrimSpace(req.Provider)
+	req.SiteKey = strings.TrimSpace(req.SiteKey)
+	if req.Provider != "" && !captcha.ValidProvider(req.Provider) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidProvider"), nil, envelope.InputError)
+	}
+	for _, e := range req.Endpoints {
+		if !slices.Contains(captcha.Endpoints, e) {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+		}
+	}
+	if req.Endpoints == nil {
+		req.Endpoints = []string{}
+	}
+
+	b, err := app.setting.GetByPrefix("captcha")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := json.Unmarshal(b, &cur); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+	}
+
+	// Retain current secret if not changed.
+	if req.SecretKey == "" || strings.Contains(req.SecretKey, stringutil.PasswordDummy) {
+		req.SecretKey = cur.SecretKey
+	}
+	if req.Provider != "" && (req.SiteKey == "" || req.SecretKey == "") {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.required", "name", "`site_key`, `secret_key`"), nil, envelope.InputError)
+	}
+
+	if err := app.setting.Update(req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := reloadSettings(app); err != nil {
+		app.lo.Error("error reloading settings", "error", err)
+		return envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return r.SendEnvelope(true)
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"maps"
 	"math"
+	"net/url"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/abhinavxd/libredesk/internal/attachment"
 	bhmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/captcha"
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/inbox/channel/livechat"
@@ -35,14 +37,21 @@ const (
 	maxChatConversationsPerContact  = 50
 	chatConversationRateLimitWindow = 24 * time.Hour
 	widgetSessionPrefix             = "widget_session:"
+	chatIntakeLockPrefix            = "widget_chat_intake:"
+	chatIntakeLockTTL               = 15 * time.Second
 	defaultSessionTTL               = 180 * 24 * time.Hour
 	minSessionTTL                   = 1 * time.Hour
 	maxChatMessageLength            = 10000
 	maxEmailLength                  = 254
 	maxNameLength                   = 128
 	maxExternalUserIDLength         = 128
+	maxPageURLLength                = 2048
+	maxUTMValueLength               = 256
 )
 
+// utmParams are the UTM parameters of page URLs recorded on chat conversations.
+var utmParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
 // WidgetSession holds session data stored in Redis.
 type WidgetSession struct {
 	UserID         int
@@ -78,6 +87,17 @@ type customAttributeWidget struct {
 type chatInitReq struct {
 	Message  string         `json:"message"`
 	FormData map[string]any `json:"form_data"`
+	// PageContext is the page the widget is embedded on.
+	PageContext chatPageContext `json:"page_context"`
+	// NewConversation is set when the contact explicitly asks to start a new conversation
+	// instead of continuing their active one.
+	NewConversation bool `json:"new_conversation"`
+}
+
+// chatPageContext is the browsing context of a widget session.
+type chatPageContext struct {
+	URL      string `json:"url"`
+	Referrer string `json:"referrer"`
 }
 
 type chatSettingsResponse struct {
@@ -91,6 +111,7 @@ type chatSettingsResponse struct {
 	DefaultBusinessHoursID int                           `json:"default_business_hours_id,omitempty"`
 	WorkingHoursUTCOffset  *int                          `json:"working_hours_utc_offset,omitempty"`
 	CustomAttributes       map[int]customAttributeWidget `json:"custom_attributes,omitempty"`
+	Captcha                *captcha.Widget               `json:"captcha,omitempty"`
 }
 
 // conversationResponseWithBusinessHours includes business hours info for the widget
@@ -124,7 +145,8 @@ func handleGetChatSettings(r *fastglue.Request) error {
 	}
 
 	response := chatSettingsResponse{
-		Config: config,
+		Config:  config,
+		Captcha: getCaptchaWidget(app, captcha.EndpointWidget),
 	}
 
 	// Get business hours data if office hours feature is enabled.
@@ -225,11 +247,42 @@ func handleChatInit(r *fastglue.Request) error {
 		contactID = visitor.ID
 	}
 
+	if newSessionToken == "" {
+		// Serialize intake per contact so that concurrent requests, e.g. a double submit
+		// or two open tabs, can't create duplicate conversations.
+		unlock, ok := lockChatIntake(app, contactID)
+		if !ok {
+			return r.SendErrorEnvelope(fasthttp.StatusConflict, app.i18n.T("widget.conversationStarting"), nil, envelope.ConflictError)
+		}
+		defer unlock()
+
+		// Continue the active conversation unless a new one is explicitly requested.
+		if !req.NewConversation {
+			activeUUID, err := app.conversation.GetContactActiveChatConversationUUID(contactID, inbox.ID)
+			if err != nil {
+				return sendErrorEnvelope(r, err)
+			}
+			if activeUUID != "" {
+				return continueChatConversation(app, r, activeUUID, contactID, req.Message)
+			}
+		}
+	}
+
 	// Check conversation permissions based on user type.
 	if err := checkConversationPermissions(app, config, isVisitor, contactID, inbox.ID); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
 
+	// Record the browsing context, form attributes take precedence.
+	if req.PageContext.URL == "" {
+		if visits := getPageVisitsFromRedis(app, contactID); len(visits) > 0 {
+			req.PageContext.URL = visits[0]["url"]
+		}
+	}
+	browsingAttrs := browsingContextAttrs(req.PageContext, userAgent)
+	maps.Copy(browsingAttrs, conversationAttrs)
+	conversationAttrs = browsingAttrs
+
 	app.lo.Info("creating new live chat conversation for user", "user_id", contactID, "inbox_id", inbox.ID, "is_visitor", isVisitor)
 
 	// Create conversation and insert message.
@@ -309,12 +362,116 @@ func handleChatInit(r *fastglue.Request) error {
 			"is_visitor": isVisitor,
 			"first_name": visitor.FirstName,
 			"last_name":  visitor.LastName,
+			"has_email":  visitor.Email.Valid,
 		}
 	}
 
 	return r.SendEnvelope(response)
 }
 
+// browsingContextAttrs returns the conversation custom attributes describing the browsing context
+// of a widget session: the page URL and referrer, UTM parameters of the page URL and the browser and OS.
+func browsingContextAttrs(pc chatPageContext, userAgent string) map[string]any {
+	attrs := make(map[string]any)
+	if u, ok := parseHTTPURL(pc.URL); ok {
+		attrs["page_url"] = pc.URL
+		q := u.Query()
+		for _, p := range utmParams {
+			if v := strings.TrimSpace(q.Get(p)); v != "" && len(v) <= maxUTMValueLength {
+				attrs[p] = v
+			}
+		}
+	}
+	if _, ok := parseHTTPURL(pc.Referrer); ok {
+		attrs["referrer"] = pc.Referrer
+	}
+	browser, os := stringutil.ParseUserAgent(userAgent)
+	if browser != "" {
+		attrs["browser"] = browser
+	}
+	if os != "" {
+		attrs["os"] = os
+	}
+	return attrs
+}
+
+// parseHTTPURL parses an absolute http(s) URL of at most maxPageURLLength bytes.
+func parseHTTPURL(s string) (*url.URL, bool) {
+	if s == "" || len(s) > maxPageURLLength {
+		return nil, false
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// lockChatIntake acquires the conversation intake lock of a contact. It returns false if the lock
+// is held by another request. Intake isn't blocked if Redis is unavailable.
+func lockChatIntake(app *App, contactID int) (func(), bool) {
+	var (
+		ctx = context.Background()
+		key = chatIntakeLockPrefix + strconv.Itoa(contactID)
+	)
+	ok, err := app.redis.SetNX(ctx, key, 1, chatIntakeLockTTL).Result()
+	if err != nil {
+		app.lo.Error("error acquiring chat intake lock", "contact_id", contactID, "error", err)
+		return func() {}, true
+	}
+	if !ok {
+		return nil, false
+	}
+	return func() {
+		if err := app.redis.Del(ctx, key).Err(); err != nil {
+			app.lo.Error("error releasing chat intake lock", "contact_id", contactID, "error", err)
+		}
+	}, true
+}
+
+// continueChatConversation adds the message of a chat init request to the contact's active
+// conversation and responds like a newly started conversation.
+func continueChatConversation(app *App, r *fastglue.Request, conversationUUID string, contactID int, content string) error {
+	conversation, err := app.conversation.GetConversation(0, conversationUUID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	app.lo.Info("continuing active live chat conversation", "user_id", contactID, "conversation_uuid", conversationUUID)
+
+	message := cmodels.Message{
+		ConversationUUID: conversationUUID,
+		ConversationID:   conversation.ID,
+		SenderID:         contactID,
+		Type:             cmodels.MessageIncoming,
+		SenderType:       cmodels.SenderTypeContact,
+		Status:           cmodels.MessageStatusReceived,
+		Content:          content,
+		ContentType:      cmodels.ContentTypeText,
+		Private:          false,
+	}
+	if _, err := app.conversation.ProcessIncomingLiveChatMessage(message); err != nil {
+		app.lo.Error("error processing incoming message", "conversation_uuid", conversationUUID, "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, app.i18n.T("globals.messages.errorSendingMessage"), nil, envelope.GeneralError)
+	}
+
+	conversation, err = app.conversation.GetConversation(0, conversationUUID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	resp, err := buildConversationResponseWithBusinessHours(app, conversation)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(map[string]any{
+		"conversation":             resp.Conversation,
+		"messages":                 resp.Messages,
+		"business_hours_id":        resp.BusinessHoursID,
+		"working_hours_utc_offset": resp.WorkingHoursUTCOffset,
+		"continued":                true,
+	})
+}
+
 // handleChatUpdateLastSeen updates contact last seen timestamp for a conversation
 func handleChatUpdateLastSeen(r *fastglue.Request) error {
 	var (
@@ -447,6 +604,7 @@ func handleWidgetAuthMe(r *fastglue.Request) error {
 		"is_visitor": u.Type == umodels.UserTypeVisitor,
 		"first_name": u.FirstName,
 		"last_name":  u.LastName,
+		"has_email":  u.Email.Valid && u.Email.String != "",
 	})
 }
 
@@ -2,13 +2,17 @@ package main
 
 import (
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/inbox"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
 	"github.com/abhinavxd/libredesk/internal/user/models"
+	realip "github.com/ferluci/fast-realip"
 	"github.com/valyala/fasthttp"
 	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/fastglue"
@@ -22,6 +26,17 @@ type blockContactReq struct {
 	Enabled bool `json:"enabled"`
 }
 
+type riskFlagsReq struct {
+	Flags []string `json:"flags"`
+}
+
+const (
+	// maxContactRiskFlags is the maximum number of risk flags on a contact.
+	maxContactRiskFlags = 10
+	// maxContactRiskFlagLength is the maximum length of a risk flag.
+	maxContactRiskFlagLength = 100
+)
+
 // handleGetContacts returns a list of contacts from the database.
 func handleGetContacts(r *fastglue.Request) error {
 	var (
@@ -292,3 +307,99 @@ func handleBlockContact(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(contact)
 }
+
+// handleUpdateContactRiskFlags replaces the risk flags of a contact and records the change in the activity log.
+func handleUpdateContactRiskFlags(r *fastglue.Request) error {
+	var (
+		app          = r.Context.(*App)
+		contactID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		auser        = r.RequestCtx.UserValue("user").(amodels.User)
+		ip           = realip.FromRequest(r.RequestCtx)
+		req          = riskFlagsReq{}
+	)
+	if contactID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+
+	// Trim and drop empty and duplicate flags.
+	flags := make([]string, 0, len(req.Flags))
+	for _, f := range req.Flags {
+		f = strings.TrimSpace(f)
+		if f == "" || slices.Contains(flags, f) {
+			continue
+		}
+		if utf8.RuneCountInString(f) > maxContactRiskFlagLength {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxContactRiskFlagLength)), nil, envelope.InputError)
+		}
+		flags = append(flags, f)
+	}
+	if len(flags) > maxContactRiskFlags {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+
+	contact, err := app.user.GetContactOrVisitor(contactID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if slices.Equal(flags, []string(contact.RiskFlags)) {
+		return r.SendEnvelope(contact)
+	}
+	if err := app.user.SetRiskFlags(contactID, flags); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.activityLog.ContactRiskFlagsChanged(auser.ID, auser.Email, ip, contactID, contact.Email.String, flags); err != nil {
+		app.lo.Error("error creating activity log", "error", err)
+	}
+
+	contact.RiskFlags = flags
+	return r.SendEnvelope(contact)
+}
+
+// handleGetContactPreferences returns the communication preferences of a contact.
+func handleGetContactPreferences(r *fastglue.Request) error {
+	var (
+		app          = r.Context.(*App)
+		contactID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if contactID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	prefs, err := app.user.GetContactPreferences(contactID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
+
+// handleUpdateContactPreferences updates the communication preferences of a contact.
+func handleUpdateContactPreferences(r *fastglue.Request) error {
+	var (
+		app          = r.Context.(*App)
+		contactID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		auser        = r.RequestCtx.UserValue("user").(amodels.User)
+		req          = models.ContactPreferences{}
+	)
+	if contactID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.T("errors.parsingRequest"), nil))
+	}
+	if req.PreferredChannel.String == "" {
+		req.PreferredChannel = null.String{}
+	}
+	if req.PreferredChannel.Valid && req.PreferredChannel.String != inbox.ChannelEmail && req.PreferredChannel.String != inbox.ChannelLiveChat {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+
+	app.lo.Info("updating contact preferences", "contact_id", contactID, "do_not_contact", req.DoNotContact, "actor_id", auser.ID)
+
+	prefs, err := app.user.UpdateContactPreferences(contactID, req)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	authzModels "github.com/abhinavxd/libredesk/internal/authz/models"
@@ -12,6 +15,7 @@ import (
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/transcript"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	vmodels "github.com/abhinavxd/libredesk/internal/view/models"
 	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
@@ -21,7 +25,25 @@ import (
 )
 
 type assigneeChangeReq struct {
-	AssigneeID int `json:"assignee_id"`
+	AssigneeID int             `json:"assignee_id"`
+	Handoff    *handoffNoteReq `json:"handoff"`
+}
+
+// handoffNoteReq is an optional note for the new assignee when reassigning a conversation.
+type handoffNoteReq struct {
+	Summary      string `json:"summary"`
+	CurrentState string `json:"current_state"`
+	NextStep     string `json:"next_step"`
+}
+
+const maxHandoffNoteFieldLength = 2000
+
+const maxConversationSummaryLength = 2000
+
+const maxRelatedConversations = 10
+
+type summaryReq struct {
+	Summary string `json:"summary"`
 }
 
 type teamAssigneeChangeReq struct {
@@ -35,6 +57,8 @@ type priorityUpdateReq struct {
 type statusUpdateReq struct {
 	Status       string `json:"status"`
 	SnoozedUntil string `json:"snoozed_until,omitempty"`
+	// SnoozePresetID snoozes using a preset instead of a duration in SnoozedUntil.
+	SnoozePresetID int `json:"snooze_preset_id,omitempty"`
 }
 
 type tagsUpdateReq struct {
@@ -56,17 +80,43 @@ type createConversationRequest struct {
 	CustomAttributes map[string]any `json:"custom_attributes"`
 }
 
+// getListSort returns the sort and pagination of a conversation list request, falling back to the
+// user's saved preference for the list for the ones the request leaves out.
+func getListSort(r *fastglue.Request, userID int, listKey string) (order, orderBy string, page, pageSize int) {
+	var (
+		app  = r.Context.(*App)
+		args = r.RequestCtx.QueryArgs()
+	)
+	order = string(args.Peek("order"))
+	orderBy = string(args.Peek("order_by"))
+	page, pageSize = getPagination(r)
+	if order != "" && orderBy != "" && args.Has("page_size") {
+		return order, orderBy, page, pageSize
+	}
+
+	// The list defaults are used if the preference can't be fetched.
+	pref, err := app.view.GetPreference(userID, listKey)
+	if err != nil {
+		return order, orderBy, page, pageSize
+	}
+	if order == "" && orderBy == "" {
+		order, orderBy = pref.Order, pref.OrderBy
+	}
+	if !args.Has("page_size") && pref.PageSize > 0 {
+		pageSize = pref.PageSize
+	}
+	return order, orderBy, page, pageSize
+}
+
 // handleGetAllConversations retrieves all conversations.
 func handleGetAllConversations(r *fastglue.Request) error {
 	var (
 		app     = r.Context.(*App)
 		user    = r.RequestCtx.UserValue("user").(amodels.User)
-		order   = string(r.RequestCtx.QueryArgs().Peek("order"))
-		orderBy = string(r.RequestCtx.QueryArgs().Peek("order_by"))
 		filters = string(r.RequestCtx.QueryArgs().Peek("filters"))
 		total   = 0
 	)
-	page, pageSize := getPagination(r)
+	order, orderBy, page, pageSize := getListSort(r, user.ID, vmodels.ListAll)
 
 	conversations, err := app.conversation.GetAllConversationsList(user.ID, order, orderBy, filters, page, pageSize)
 	if err != nil {
@@ -86,17 +136,51 @@ func handleGetAllConversations(r *fastglue.Request) error {
 	})
 }
 
+// handleGetTrashedConversations retrieves the conversations in the trash the user can read.
+func handleGetTrashedConversations(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		auser   = r.RequestCtx.UserValue("user").(amodels.User)
+		filters = string(r.RequestCtx.QueryArgs().Peek("filters"))
+		total   = 0
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	lists := readableConversationLists(user)
+	if len(lists) == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("status.deniedPermission"), nil, envelope.PermissionError)
+	}
+	order, orderBy, page, pageSize := getListSort(r, user.ID, vmodels.ListAll)
+
+	conversations, err := app.conversation.GetTrashedConversationsList(user.ID, user.Teams.IDs(), lists, order, orderBy, filters, page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if len(conversations) > 0 {
+		total = conversations[0].Total
+	}
+
+	return r.SendEnvelope(envelope.PageResults{
+		Results:    conversations,
+		Total:      total,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+		Page:       page,
+	})
+}
+
 // handleGetAssignedConversations retrieves conversations assigned to the current user.
 func handleGetAssignedConversations(r *fastglue.Request) error {
 	var (
 		app     = r.Context.(*App)
 		user    = r.RequestCtx.UserValue("user").(amodels.User)
-		order   = string(r.RequestCtx.QueryArgs().Peek("order"))
-		orderBy = string(r.RequestCtx.QueryArgs().Peek("order_by"))
 		filters = string(r.RequestCtx.QueryArgs().Peek("filters"))
 		total   = 0
 	)
-	page, pageSize := getPagination(r)
+	order, orderBy, page, pageSize := getListSort(r, user.ID, vmodels.ListAssigned)
 	conversations, err := app.conversation.GetAssignedConversationsList(user.ID, user.ID, order, orderBy, filters, page, pageSize)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
@@ -119,12 +203,10 @@ func handleGetUnassignedConversations(r *fastglue.Request) error {
 	var (
 		app     = r.Context.(*App)
 		user    = r.RequestCtx.UserValue("user").(amodels.User)
-		order   = string(r.RequestCtx.QueryArgs().Peek("order"))
-		orderBy = string(r.RequestCtx.QueryArgs().Peek("order_by"))
 		filters = string(r.RequestCtx.QueryArgs().Peek("filters"))
 		total   = 0
 	)
-	page, pageSize := getPagination(r)
+	order, orderBy, page, pageSize := getListSort(r, user.ID, vmodels.ListUnassigned)
 
 	conversations, err := app.conversation.GetUnassignedConversationsList(user.ID, order, orderBy, filters, page, pageSize)
 	if err != nil {
@@ -148,12 +230,10 @@ func handleGetMentionedConversations(r *fastglue.Request) error {
 	var (
 		app     = r.Context.(*App)
 		user    = r.RequestCtx.UserValue("user").(amodels.User)
-		order   = string(r.RequestCtx.QueryArgs().Peek("order"))
-		orderBy = string(r.RequestCtx.QueryArgs().Peek("order_by"))
 		filters = string(r.RequestCtx.QueryArgs().Peek("filters"))
 		total   = 0
 	)
-	page, pageSize := getPagination(r)
+	order, orderBy, page, pageSize := getListSort(r, user.ID, vmodels.ListMentioned)
 
 	conversations, err := app.conversation.GetMentionedConversationsList(user.ID, order, orderBy, filters, page, pageSize)
 	if err != nil {
@@ -172,17 +252,44 @@ func handleGetMentionedConversations(r *fastglue.Request) error {
 	})
 }
 
+// readableConversationLists returns the conversation lists the user's permissions give access to,
+// none if the user can't read any conversations.
+func readableConversationLists(user umodels.User) []string {
+	var (
+		lists      = []string{}
+		hasTeamAll = slices.Contains(user.Permissions, authzModels.PermConversationsReadTeamAll)
+		hasTeams   = len(user.Teams) > 0
+	)
+	for _, perm := range user.Permissions {
+		if perm == authzModels.PermConversationsReadAll {
+			// No further lists required as user has access to all conversations.
+			return []string{cmodels.AllConversations}
+		}
+		if perm == authzModels.PermConversationsReadUnassigned {
+			lists = append(lists, cmodels.UnassignedConversations)
+		}
+		if perm == authzModels.PermConversationsReadAssigned {
+			lists = append(lists, cmodels.AssignedConversations)
+		}
+		// Skip TeamUnassignedConversations if user has TeamAllConversations (superset).
+		if perm == authzModels.PermConversationsReadTeamInbox && !hasTeamAll && hasTeams {
+			lists = append(lists, cmodels.TeamUnassignedConversations)
+		}
+		if perm == authzModels.PermConversationsReadTeamAll && hasTeams {
+			lists = append(lists, cmodels.TeamAllConversations)
+		}
+	}
+	return lists
+}
+
 // handleGetViewConversations retrieves conversations for a view.
 func handleGetViewConversations(r *fastglue.Request) error {
 	var (
 		app       = r.Context.(*App)
 		auser     = r.RequestCtx.UserValue("user").(amodels.User)
 		viewID, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
-		order     = string(r.RequestCtx.QueryArgs().Peek("order"))
-		orderBy   = string(r.RequestCtx.QueryArgs().Peek("order_by"))
 		total     = 0
 	)
-	page, pageSize := getPagination(r)
 	if viewID < 1 {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
 	}
@@ -198,51 +305,17 @@ func handleGetViewConversations(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	hasAccess := false
-	switch view.Visibility {
-	case vmodels.VisibilityUser:
-		hasAccess = view.UserID != nil && *view.UserID == auser.ID
-	case vmodels.VisibilityAll:
-		hasAccess = true
-	case vmodels.VisibilityTeam:
-		if view.TeamID != nil {
-			hasAccess = slices.Contains(user.Teams.IDs(), *view.TeamID)
-		}
-	}
-
-	if !hasAccess {
+	if !canAccessView(view, user) {
 		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("conversation.viewPermissionDenied"), nil, envelope.PermissionError)
 	}
 
-	// Prepare lists user has access to based on user permissions, internally this prepares the SQL query.
-	lists := []string{}
-	hasTeamAll := slices.Contains(user.Permissions, authzModels.PermConversationsReadTeamAll)
-	for _, perm := range user.Permissions {
-		if perm == authzModels.PermConversationsReadAll {
-			// No further lists required as user has access to all conversations.
-			lists = []string{cmodels.AllConversations}
-			break
-		}
-		if perm == authzModels.PermConversationsReadUnassigned {
-			lists = append(lists, cmodels.UnassignedConversations)
-		}
-		if perm == authzModels.PermConversationsReadAssigned {
-			lists = append(lists, cmodels.AssignedConversations)
-		}
-		// Skip TeamUnassignedConversations if user has TeamAllConversations (superset).
-		if perm == authzModels.PermConversationsReadTeamInbox && !hasTeamAll {
-			lists = append(lists, cmodels.TeamUnassignedConversations)
-		}
-		if perm == authzModels.PermConversationsReadTeamAll {
-			lists = append(lists, cmodels.TeamAllConversations)
-		}
-	}
-
 	// No lists found, user doesn't have access to any conversations.
+	lists := readableConversationLists(user)
 	if len(lists) == 0 {
 		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("status.deniedPermission"), nil, envelope.PermissionError)
 	}
 
+	order, orderBy, page, pageSize := getListSort(r, auser.ID, vmodels.ListKey(vmodels.ListView, viewID))
 	conversations, err := app.conversation.GetViewConversationsList(user.ID, user.ID, user.Teams.IDs(), lists, order, orderBy, string(view.Filters), page, pageSize)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
@@ -266,12 +339,9 @@ func handleGetTeamUnassignedConversations(r *fastglue.Request) error {
 		app       = r.Context.(*App)
 		auser     = r.RequestCtx.UserValue("user").(amodels.User)
 		teamIDStr = r.RequestCtx.UserValue("id").(string)
-		order     = string(r.RequestCtx.QueryArgs().Peek("order"))
-		orderBy   = string(r.RequestCtx.QueryArgs().Peek("order_by"))
 		filters   = string(r.RequestCtx.QueryArgs().Peek("filters"))
 		total     = 0
 	)
-	page, pageSize := getPagination(r)
 	teamID, _ := strconv.Atoi(teamIDStr)
 	if teamID < 1 {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
@@ -287,6 +357,7 @@ func handleGetTeamUnassignedConversations(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, envelope.NewError(envelope.PermissionError, app.i18n.T("conversation.notMemberOfTeam"), nil))
 	}
 
+	order, orderBy, page, pageSize := getListSort(r, auser.ID, vmodels.ListKey(vmodels.ListTeamUnassigned, teamID))
 	conversations, err := app.conversation.GetTeamUnassignedConversationsList(auser.ID, teamID, order, orderBy, filters, page, pageSize)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
@@ -324,9 +395,183 @@ func handleGetConversation(r *fastglue.Request) error {
 
 	prev, _ := app.conversation.GetContactPreviousConversations(conv.ContactID, 10)
 	conv.PreviousConversations = filterCurrentPreviousConv(prev, conv.UUID)
+	conv.PinnedMessages, _ = app.conversation.GetPinnedMessages(conv.ID)
 	return r.SendEnvelope(conv)
 }
 
+// handleGetRelatedConversations suggests related conversations the user can access.
+func handleGetRelatedConversations(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Fetch extra candidates as the ones the user can't access are dropped.
+	related, err := app.conversation.GetRelatedConversations(conv.ID, maxRelatedConversations*3)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	out := make([]cmodels.RelatedConversation, 0, maxRelatedConversations)
+	for _, rc := range related {
+		allowed, err := app.authz.EnforceConversationAccess(user, cmodels.Conversation{
+			ID:             rc.ID,
+			AssignedUserID: rc.AssignedUserID,
+			AssignedTeamID: rc.AssignedTeamID,
+		})
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		if !allowed {
+			continue
+		}
+		out = append(out, rc)
+		if len(out) == maxRelatedConversations {
+			break
+		}
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleGetConversationStats returns computed metrics for a conversation.
+func handleGetConversationStats(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	stats, err := app.conversation.GetConversationStats(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(stats)
+}
+
+// bundleFormat is the export format of conversation bundles that can be imported into another instance.
+const bundleFormat = "bundle"
+
+// handleExportConversation renders the message history of a conversation as a PDF, EML or HTML download,
+// or exports it as a JSON bundle. Private notes are left out unless include_private is set, attachment
+// contents are added to bundles if include_attachments is set.
+func handleExportConversation(r *fastglue.Request) error {
+	var (
+		app            = r.Context.(*App)
+		uuid           = r.RequestCtx.UserValue("uuid").(string)
+		auser          = r.RequestCtx.UserValue("user").(amodels.User)
+		format         = string(r.RequestCtx.QueryArgs().Peek("format"))
+		includePrivate = r.RequestCtx.QueryArgs().GetBool("include_private")
+	)
+	if format == "" {
+		format = transcript.FormatPDF
+	}
+	if format != bundleFormat && !slices.Contains(transcript.Formats, format) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if format == bundleFormat {
+		bundle, err := app.conversation.ExportBundle(uuid, includePrivate, r.RequestCtx.QueryArgs().GetBool("include_attachments"))
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		b, err := json.Marshal(bundle)
+		if err != nil {
+			app.lo.Error("error marshalling conversation bundle", "uuid", uuid, "error", err)
+			return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+		}
+		r.RequestCtx.Response.Header.Set("Content-Type", "application/json")
+		r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="conversation-%s.json"`, bundle.Conversation.ReferenceNumber))
+		r.RequestCtx.Response.Header.Set("X-Content-Type-Options", "nosniff")
+		r.RequestCtx.SetBody(b)
+		return nil
+	}
+
+	t, err := app.conversation.GetTranscript(uuid, includePrivate)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	b, contentType, err := transcript.Render(t, format)
+	if err != nil {
+		app.lo.Error("error rendering conversation transcript", "uuid", uuid, "format", format, "error", err)
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+	}
+
+	r.RequestCtx.Response.Header.Set("Content-Type", contentType)
+	r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, transcript.FileName(t, format)))
+	r.RequestCtx.Response.Header.Set("X-Content-Type-Options", "nosniff")
+	r.RequestCtx.SetBody(b)
+	return nil
+}
+
+// handleImportConversation creates a conversation in the inbox given by inbox_id from a bundle exported by
+// this or another instance.
+func handleImportConversation(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		inboxID = r.RequestCtx.QueryArgs().GetUintOrZero("inbox_id")
+		bundle  = cmodels.Bundle{}
+	)
+	if inboxID <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.required", "name", "`inbox_id`"), nil, envelope.InputError)
+	}
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &bundle); err != nil {
+		app.lo.Error("error decoding conversation bundle", "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	uuid, err := app.conversation.ImportBundle(bundle, inboxID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(map[string]string{"uuid": uuid})
+}
+
+// handleSendConversationTranscript emails the contact a transcript of the conversation.
+func handleSendConversationTranscript(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.SendTranscript(uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
 // handleGetContactPageVisits returns the recent page visits for the contact of a conversation.
 func handleGetContactPageVisits(r *fastglue.Request) error {
 	var (
@@ -444,6 +689,16 @@ func handleUpdateUserAssignee(r *fastglue.Request) error {
 		return r.SendEnvelope(true)
 	}
 
+	// Record the handoff note before assigning so it is included in the assignment notification.
+	if req.Handoff != nil {
+		if err := validateHandoffNote(app, req.Handoff); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		if err := app.conversation.AddHandoffNote(conversation.ID, user.ID, req.AssigneeID, req.Handoff.Summary, req.Handoff.CurrentState, req.Handoff.NextStep); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
 	if err := app.conversation.UpdateConversationUserAssignee(uuid, req.AssigneeID, user); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -451,6 +706,73 @@ func handleUpdateUserAssignee(r *fastglue.Request) error {
 	return r.SendEnvelope(true)
 }
 
+// handleUpdateConversationSummary updates the agent maintained summary of a conversation.
+func handleUpdateConversationSummary(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = summaryReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	req.Summary = strings.TrimSpace(req.Summary)
+	if utf8.RuneCountInString(req.Summary) > maxConversationSummaryLength {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxConversationSummaryLength)), nil, envelope.InputError)
+	}
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.UpdateConversationSummary(uuid, req.Summary); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// validateHandoffNote trims and validates a handoff note.
+func validateHandoffNote(app *App, n *handoffNoteReq) error {
+	n.Summary = strings.TrimSpace(n.Summary)
+	n.CurrentState = strings.TrimSpace(n.CurrentState)
+	n.NextStep = strings.TrimSpace(n.NextStep)
+	if n.Summary == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`summary`"), nil)
+	}
+	for _, v := range []string{n.Summary, n.CurrentState, n.NextStep} {
+		if utf8.RuneCountInString(v) > maxHandoffNoteFieldLength {
+			return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxHandoffNoteFieldLength)), nil)
+		}
+	}
+	return nil
+}
+
+// handleGetHandoffNote returns the handoff note left for the current assignee of a conversation.
+func handleGetHandoffNote(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	note, err := app.conversation.GetActiveHandoffNote(conversation.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(note)
+}
+
 // handleUpdateTeamAssignee updates the team assigned to a conversation.
 func handleUpdateTeamAssignee(r *fastglue.Request) error {
 	var (
@@ -548,10 +870,10 @@ func handleUpdateConversationStatus(r *fastglue.Request) error {
 	if status == "" {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.empty", "name", "`status`"), nil, envelope.InputError)
 	}
-	if snoozedUntil == "" && status == cmodels.StatusSnoozed {
+	if snoozedUntil == "" && req.SnoozePresetID == 0 && status == cmodels.StatusSnoozed {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.empty", "name", "`snoozed_until`"), nil, envelope.InputError)
 	}
-	if status == cmodels.StatusSnoozed {
+	if status == cmodels.StatusSnoozed && req.SnoozePresetID == 0 {
 		_, err := time.ParseDuration(snoozedUntil)
 		if err != nil {
 			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.badRequest"), nil, envelope.InputError)
@@ -568,23 +890,57 @@ func handleUpdateConversationStatus(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
+	// Resolve the snooze preset against the team's business hours.
+	if status == cmodels.StatusSnoozed && req.SnoozePresetID > 0 {
+		snoozedUntil, err = snoozePresetDuration(app, req.SnoozePresetID, conversation)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
 	// Update conversation status.
 	if err := app.conversation.UpdateConversationStatus(uuid, 0 /**status_id**/, status, snoozedUntil, user); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
+	return r.SendEnvelope(true)
+}
 
-	// If status is `Resolved`, send CSAT survey if enabled on inbox.
-	if status == cmodels.StatusResolved {
-		// Check if CSAT is enabled on the inbox and send CSAT survey message.
-		inbox, err := app.inbox.GetDBRecord(conversation.InboxID)
-		if err != nil {
-			return sendErrorEnvelope(r, err)
-		}
-		if inbox.CSATEnabled {
-			if err := app.conversation.SendCSATReply(user.ID, *conversation); err != nil {
-				return sendErrorEnvelope(r, err)
-			}
-		}
+// handleTrashConversation moves a conversation to the trash, it is purged after the trash retention period.
+func handleTrashConversation(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.TrashConversation(uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleRestoreConversation restores a conversation from the trash.
+func handleRestoreConversation(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.RestoreConversation(uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
 	}
 	return r.SendEnvelope(true)
 }
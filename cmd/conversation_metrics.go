@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+const jobNSConversationMetrics = "conversation_metrics"
+
+// recalculateMetricsReq is the date range of conversations to recalculate, both dates inclusive.
+type recalculateMetricsReq struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// handleRecalculateConversationMetrics starts a background job recomputing the reply, waiting and resolution
+// times and the SLA states of the conversations created in a date range from their message history.
+func handleRecalculateConversationMetrics(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = recalculateMetricsReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	from, err := time.Parse(time.DateOnly, req.From)
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	to, err := time.Parse(time.DateOnly, req.To)
+	if err != nil || to.Before(from) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	to = to.AddDate(0, 0, 1)
+
+	err = app.importer.Submit(jobNSConversationMetrics, func() error {
+		total, err := app.conversation.CountConversationsCreatedBetween(from, to)
+		if err != nil {
+			return err
+		}
+		app.importer.UpdateCounts(jobNSConversationMetrics, total, 0, 0)
+		app.importer.AddLog(jobNSConversationMetrics, app.i18n.Ts("conversation.metrics.recalculating",
+			"count", strconv.Itoa(total),
+			"from", req.From,
+			"to", req.To))
+
+		done, err := app.conversation.RecalculateMetrics(from, to, func(n int) {
+			app.importer.UpdateCounts(jobNSConversationMetrics, 0, n, 0)
+		})
+		if err != nil {
+			return err
+		}
+		app.importer.AddLog(jobNSConversationMetrics, app.i18n.Ts("conversation.metrics.recalculated", "count", strconv.Itoa(done)))
+		return nil
+	})
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusConflict, app.i18n.T("conversation.metrics.alreadyRunning"), nil, envelope.ConflictError)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetConversationMetricsRecalculationStatus returns the status of the last metrics recalculation job.
+func handleGetConversationMetricsRecalculationStatus(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	status, err := app.importer.GetStatus(jobNSConversationMetrics)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(status)
+}
@@ -1,34 +1,60 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/abhinavxd/libredesk/internal/captcha"
+	csatModels "github.com/abhinavxd/libredesk/internal/csat/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+	"github.com/knadh/go-i18n"
 	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/fastglue"
 )
 
 type csatResponse struct {
-	Rating   int    `json:"rating"`
-	Feedback string `json:"feedback"`
+	Rating   null.Int `json:"rating"`
+	Feedback string   `json:"feedback"`
 }
 
+// csatRating is a rating option rendered on survey pages.
+type csatRating struct {
+	Value int
+	Emoji string
+	Label string
+}
+
+// langCodeRe matches language codes such as "en" and "pt-BR".
+var langCodeRe = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})?$`)
+
 const (
 	maxCsatFeedbackLength = 1000
 	maxCsatMetaKeys       = 100
 	maxCsatMetaKeyLength  = 100
 	maxCsatMetaValLength  = 1000
+	maxCSATQuestionLength = 300
+	maxCSATThrottleDays   = 365
 )
 
-// handleShowCSAT renders the CSAT page for a given csat.
+// handleShowCSAT renders the CSAT page for a given survey token.
 func handleShowCSAT(r *fastglue.Request) error {
 	var (
-		app  = r.Context.(*App)
-		uuid = r.RequestCtx.UserValue("uuid").(string)
+		app      = r.Context.(*App)
+		tok, err = app.csat.ParseToken(r.RequestCtx.UserValue("token").(string))
 	)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
 
-	csat, err := app.csat.Get(uuid)
+	csat, err := app.csat.Get(tok.UUID)
 	if err != nil {
 		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
 			"Data": map[string]interface{}{
@@ -36,14 +62,8 @@ func handleShowCSAT(r *fastglue.Request) error {
 			},
 		})
 	}
-
-	if csat.ResponseTimestamp.Valid {
-		return app.tmpl.RenderWebPage(r.RequestCtx, "info", map[string]interface{}{
-			"Data": map[string]interface{}{
-				"Title":   app.i18n.T("globals.messages.thankYou"),
-				"Message": app.i18n.T("csat.thankYouMessage"),
-			},
-		})
+	if !app.csat.ValidToken(tok, csat) {
+		return renderCSATInvalidLink(app, r)
 	}
 
 	conversation, err := app.conversation.GetConversation(csat.ConversationID, "", "")
@@ -54,38 +74,105 @@ func handleShowCSAT(r *fastglue.Request) error {
 			},
 		})
 	}
+	cfg := getCSATConfig(app, conversation.InboxID)
+	lang := getCSATI18n(app, r, cfg.Language)
+
+	if csat.ResponseTimestamp.Valid && !app.csat.LatestWins() {
+		return renderCSATThankYou(app, r, lang)
+	}
+
+	question := cfg.Question
+	if question == "" {
+		question = lang.T("csat.rateYourInteraction")
+		if csat.Scale == csatModels.ScaleNPS {
+			question = lang.T("csat.npsQuestion")
+		}
+	}
 
 	return app.tmpl.RenderWebPage(r.RequestCtx, "csat", map[string]interface{}{
 		"Data": map[string]interface{}{
-			"Title": app.i18n.T("csat.pageTitle"),
+			"Title": lang.T("csat.pageTitle"),
+			"L":     lang,
 			"CSAT": map[string]interface{}{
-				"UUID": csat.UUID,
+				"UUID":        csat.UUID,
+				"Action":      "/csat/" + r.RequestCtx.UserValue("token").(string),
+				"Scale":       csat.Scale,
+				"Ratings":     csatRatings(lang, csat.Scale),
+				"Question":    question,
+				"HideComment": cfg.HideComment,
 			},
 			"Conversation": map[string]interface{}{
 				"Subject":         conversation.Subject.String,
 				"ReferenceNumber": conversation.ReferenceNumber,
 			},
+			"Captcha": getCaptchaWidget(app, captcha.EndpointCSAT),
 		},
 	})
 }
 
-// handleUpdateCSATResponse updates the CSAT response for a given csat.
+// handleUpdateCSATResponse updates the CSAT response for a given survey token.
 func handleUpdateCSATResponse(r *fastglue.Request) error {
 	var (
-		app  = r.Context.(*App)
-		uuid = r.RequestCtx.UserValue("uuid").(string)
+		app      = r.Context.(*App)
+		tok, err = app.csat.ParseToken(r.RequestCtx.UserValue("token").(string))
 	)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
+	if err := app.rateLimit.CheckKey(r.RequestCtx, "public", "csat:"+tok.UUID); err != nil {
+		return err
+	}
+
+	csat, err := app.csat.Get(tok.UUID)
+	if err != nil {
+		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+			"Data": map[string]interface{}{
+				"ErrorMessage": app.i18n.T("globals.messages.pageNotFound"),
+			},
+		})
+	}
+	if !app.csat.ValidToken(tok, csat) {
+		return renderCSATInvalidLink(app, r)
+	}
+	var cfg imodels.CSATConfig
+	if conversation, err := app.conversation.GetConversation(csat.ConversationID, "", ""); err == nil {
+		cfg = getCSATConfig(app, conversation.InboxID)
+	}
+	lang := getCSATI18n(app, r, cfg.Language)
+
+	// Bots fill every input, drop the response silently.
+	if len(r.RequestCtx.FormValue(honeypotField)) > 0 {
+		app.lo.Info("dropping csat response with filled honeypot", "uuid", tok.UUID)
+		return renderCSATThankYou(app, r, lang)
+	}
+	if !verifyCaptcha(app, r, captcha.EndpointCSAT) {
+		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+			"Data": map[string]interface{}{
+				"ErrorMessage": lang.T("validation.captchaFailed"),
+			},
+		})
+	}
 
-	rating, feedback, metaJSON, errKey := validateCSATForm(r)
+	rating, feedback, metaJSON, errKey := validateCSATForm(r, csat.Scale)
 	if errKey != "" {
 		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
 			"Data": map[string]interface{}{
-				"ErrorMessage": app.i18n.T(errKey),
+				"ErrorMessage": lang.T(errKey),
 			},
 		})
 	}
+	if cfg.HideComment {
+		feedback = ""
+		if !rating.Valid {
+			return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+				"Data": map[string]interface{}{
+					"ErrorMessage": lang.T("csat.pleaseFillRequired"),
+				},
+			})
+		}
+	}
 
-	if err := app.csat.UpdateResponse(uuid, rating, feedback, metaJSON); err != nil {
+	if err := app.csat.UpdateResponse(tok, rating, feedback, metaJSON); err != nil {
 		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
 			"Data": map[string]interface{}{
 				"ErrorMessage": err.Error(),
@@ -93,22 +180,20 @@ func handleUpdateCSATResponse(r *fastglue.Request) error {
 		})
 	}
 
-	return app.tmpl.RenderWebPage(r.RequestCtx, "info", map[string]interface{}{
-		"Data": map[string]interface{}{
-			"Title":   app.i18n.T("globals.messages.thankYou"),
-			"Message": app.i18n.T("csat.thankYouMessage"),
-		},
-	})
+	return renderCSATThankYou(app, r, lang)
 }
 
 // handleShowCSATWidget renders a minimal CSAT widget page (just stars) for iframe embedding.
 func handleShowCSATWidget(r *fastglue.Request) error {
 	var (
-		app  = r.Context.(*App)
-		uuid = r.RequestCtx.UserValue("uuid").(string)
+		app      = r.Context.(*App)
+		tok, err = app.csat.ParseToken(r.RequestCtx.UserValue("token").(string))
 	)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
 
-	csat, err := app.csat.Get(uuid)
+	csat, err := app.csat.Get(tok.UUID)
 	if err != nil {
 		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
 			"Data": map[string]interface{}{
@@ -116,12 +201,25 @@ func handleShowCSATWidget(r *fastglue.Request) error {
 			},
 		})
 	}
+	if !app.csat.ValidToken(tok, csat) {
+		return renderCSATInvalidLink(app, r)
+	}
+
+	var cfg imodels.CSATConfig
+	if conversation, err := app.conversation.GetConversation(csat.ConversationID, "", ""); err == nil {
+		cfg = getCSATConfig(app, conversation.InboxID)
+	}
+	lang := getCSATI18n(app, r, cfg.Language)
 
 	return app.tmpl.RenderWebPage(r.RequestCtx, "csat-widget", map[string]interface{}{
 		"Data": map[string]interface{}{
+			"L": lang,
 			"CSAT": map[string]interface{}{
 				"UUID":      csat.UUID,
-				"Responded": csat.ResponseTimestamp.Valid,
+				"Token":     r.RequestCtx.UserValue("token").(string),
+				"Scale":     csat.Scale,
+				"Ratings":   csatRatings(lang, csat.Scale),
+				"Responded": csat.ResponseTimestamp.Valid && !app.csat.LatestWins(),
 			},
 		},
 	})
@@ -130,26 +228,40 @@ func handleShowCSATWidget(r *fastglue.Request) error {
 // handleSubmitCSATResponse handles CSAT response submission from the widget API.
 func handleSubmitCSATResponse(r *fastglue.Request) error {
 	var (
-		app  = r.Context.(*App)
-		uuid = r.RequestCtx.UserValue("uuid").(string)
-		req  = csatResponse{}
+		app      = r.Context.(*App)
+		req      = csatResponse{}
+		tok, err = app.csat.ParseToken(r.RequestCtx.UserValue("token").(string))
 	)
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("csat.invalidLink"), nil, envelope.PermissionError)
+	}
+	if err := app.rateLimit.CheckKey(r.RequestCtx, "public", "csat:"+tok.UUID); err != nil {
+		return err
+	}
 
 	if err := r.Decode(&req, "json"); err != nil {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid JSON", nil, envelope.InputError)
 	}
 
-	if req.Rating < 0 || req.Rating > 5 {
-		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Rating must be between 0 and 5 (0 means no rating)", nil, envelope.InputError)
+	csat, err := app.csat.Get(tok.UUID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if !app.csat.ValidToken(tok, csat) {
+		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("csat.invalidLink"), nil, envelope.PermissionError)
 	}
 
-	// At least one of rating or feedback must be provided
-	if req.Rating == 0 && req.Feedback == "" {
-		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Either rating or feedback must be provided", nil, envelope.InputError)
+	// 0 means no rating on scales that don't start at 0.
+	if req.Rating.Valid && req.Rating.Int == 0 && !csatModels.ValidRating(csat.Scale, 0) {
+		req.Rating = null.Int{}
+	}
+	if req.Rating.Valid && !csatModels.ValidRating(csat.Scale, req.Rating.Int) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
 	}
 
-	if uuid == "" {
-		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid UUID", nil, envelope.InputError)
+	// At least one of rating or feedback must be provided
+	if !req.Rating.Valid && req.Feedback == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Either rating or feedback must be provided", nil, envelope.InputError)
 	}
 
 	// Trim feedback if it exceeds max length.
@@ -158,33 +270,33 @@ func handleSubmitCSATResponse(r *fastglue.Request) error {
 	}
 
 	// Update CSAT response
-	if err := app.csat.UpdateResponse(uuid, req.Rating, req.Feedback, nil); err != nil {
+	if err := app.csat.UpdateResponse(tok, req.Rating, req.Feedback, nil); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
 
 	return r.SendEnvelope(true)
 }
 
-// validateCSATForm parses and validates the CSAT form submission.
-// Returns rating (0 if not provided), trimmed feedback, meta JSON, and error message key if invalid.
-func validateCSATForm(r *fastglue.Request) (int, string, json.RawMessage, string) {
+// validateCSATForm parses and validates the CSAT form submission against the rating scale of the survey.
+// Returns rating (null if not provided), trimmed feedback, meta JSON, and error message key if invalid.
+func validateCSATForm(r *fastglue.Request, scale string) (null.Int, string, json.RawMessage, string) {
 	var (
 		feedback = string(r.RequestCtx.FormValue("feedback"))
-		rating   int
+		rating   null.Int
 	)
 
-	// Rating is optional (0 = not provided). If provided, must be 1-5.
+	// Rating is optional. If provided, must be on the scale.
 	if rs := string(r.RequestCtx.FormValue("rating")); rs != "" {
 		v, err := strconv.Atoi(rs)
-		if err != nil || v < 1 || v > 5 {
-			return 0, "", nil, "globals.messages.somethingWentWrong"
+		if err != nil || !csatModels.ValidRating(scale, v) {
+			return rating, "", nil, "globals.messages.somethingWentWrong"
 		}
-		rating = v
+		rating = null.IntFrom(v)
 	}
 
 	// At least one of rating or feedback must be provided.
-	if rating == 0 && feedback == "" {
-		return 0, "", nil, "csat.pleaseFillRequired"
+	if !rating.Valid && feedback == "" {
+		return rating, "", nil, "csat.pleaseFillRequired"
 	}
 
 	if len(feedback) > maxCsatFeedbackLength {
@@ -195,7 +307,7 @@ func validateCSATForm(r *fastglue.Request) (int, string, json.RawMessage, string
 	meta := make(map[string]string)
 	r.RequestCtx.PostArgs().VisitAll(func(key, value []byte) {
 		k := string(key)
-		if k == "rating" || k == "feedback" {
+		if k == "rating" || k == "feedback" || k == honeypotField || slices.Contains(captchaTokenFields, k) {
 			return
 		}
 		if len(meta) >= maxCsatMetaKeys {
@@ -220,3 +332,106 @@ func validateCSATForm(r *fastglue.Request) (int, string, json.RawMessage, string
 
 	return rating, feedback, metaJSON, ""
 }
+
+// renderCSATInvalidLink renders the error page for a malformed, forged or expired survey token.
+func renderCSATInvalidLink(app *App, r *fastglue.Request) error {
+	return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+		"Data": map[string]interface{}{
+			"ErrorMessage": app.i18n.T("csat.invalidLink"),
+		},
+	})
+}
+
+// renderCSATThankYou renders the page shown after a survey is answered.
+func renderCSATThankYou(app *App, r *fastglue.Request, lang *i18n.I18n) error {
+	return app.tmpl.RenderWebPage(r.RequestCtx, "info", map[string]interface{}{
+		"Data": map[string]interface{}{
+			"Title":   lang.T("globals.messages.thankYou"),
+			"Message": lang.T("csat.thankYouMessage"),
+		},
+	})
+}
+
+// getCSATConfig returns the CSAT survey settings of an inbox, the defaults if the inbox can't be fetched.
+func getCSATConfig(app *App, inboxID int) imodels.CSATConfig {
+	inbox, err := app.inbox.GetDBRecord(inboxID)
+	if err != nil {
+		app.lo.Error("error fetching inbox for CSAT", "inbox_id", inboxID, "error", err)
+		return imodels.CSATConfig{}
+	}
+	return inbox.CSATConfig
+}
+
+// getCSATI18n returns the language pack survey pages are rendered in: the inbox's survey language,
+// otherwise the first available language of the contact's browser, otherwise the app language.
+func getCSATI18n(app *App, r *fastglue.Request, lang string) *i18n.I18n {
+	candidates := []string{lang}
+	if lang == "" {
+		candidates = parseAcceptLanguage(string(r.RequestCtx.Request.Header.Peek("Accept-Language")))
+	}
+	for _, code := range candidates {
+		if code == "" || !langCodeRe.MatchString(code) {
+			continue
+		}
+		if code == cmp.Or(ko.String("app.lang"), defLang) {
+			return app.i18n
+		}
+		if _, err := app.fs.Get(fmt.Sprintf("/i18n/%s.json", code)); err != nil {
+			continue
+		}
+		i, err := loadI18nLang(code, app.fs)
+		if err != nil {
+			app.lo.Error("error loading CSAT survey language", "lang", code, "error", err)
+			continue
+		}
+		return i
+	}
+	return app.i18n
+}
+
+// parseAcceptLanguage returns the language codes of an Accept-Language header in the order of preference,
+// each followed by its primary language, e.g. "pt-BR,de;q=0.8" returns pt-BR, pt, de.
+func parseAcceptLanguage(header string) []string {
+	type tag struct {
+		code string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		code, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if code == "" || code == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		tags = append(tags, tag{code: code, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	var codes []string
+	for _, t := range tags {
+		codes = append(codes, t.code)
+		if primary, _, ok := strings.Cut(t.code, "-"); ok {
+			codes = append(codes, primary)
+		}
+	}
+	return codes
+}
+
+// csatRatings returns the rating options of a scale with their labels translated.
+func csatRatings(lang *i18n.I18n, scale string) []csatRating {
+	opts := csatModels.RatingOptions(scale)
+	ratings := make([]csatRating, 0, len(opts))
+	for _, o := range opts {
+		label := strconv.Itoa(o.Value)
+		if o.LabelKey != "" {
+			label = lang.T(o.LabelKey)
+		}
+		ratings = append(ratings, csatRating{Value: o.Value, Emoji: o.Emoji, Label: label})
+	}
+	return ratings
+}
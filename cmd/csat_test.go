@@ -0,0 +1,27 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "empty", header: "", want: nil},
+		{name: "single", header: "de", want: []string{"de"}},
+		{name: "region adds primary language", header: "pt-BR", want: []string{"pt-BR", "pt"}},
+		{name: "ordered by quality", header: "fr;q=0.5, de-CH, en;q=0.8", want: []string{"de-CH", "de", "en", "fr"}},
+		{name: "wildcard skipped", header: "*, es", want: []string{"es"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"slices"
 	"strconv"
 
+	customAttribute "github.com/abhinavxd/libredesk/internal/custom_attribute"
 	cmodels "github.com/abhinavxd/libredesk/internal/custom_attribute/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/valyala/fasthttp"
@@ -126,6 +127,12 @@ func validateCustomAttribute(app *App, attribute cmodels.CustomAttribute) error
 	if attribute.DataType == "" {
 		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`type`"), nil)
 	}
+	if !slices.Contains(customAttribute.DataTypes, attribute.DataType) {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	if attribute.DataType == customAttribute.DataTypeList && len(attribute.Values) == 0 {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`values`"), nil)
+	}
 	if attribute.Description == "" {
 		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`description`"), nil)
 	}
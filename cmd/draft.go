@@ -15,6 +15,7 @@ const maxMetaSize = 32 * 1024 // 32KB
 type draftReq struct {
 	Content string          `json:"content"`
 	Meta    json.RawMessage `json:"meta"`
+	Version int             `json:"version"`
 }
 
 // handleUpsertConversationDraft saves or updates a draft for a conversation.
@@ -51,7 +52,34 @@ func handleUpsertConversationDraft(r *fastglue.Request) error {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
 	}
 
-	draft, err := app.conversation.UpsertConversationDraft(conv.ID, user.ID, req.Content, req.Meta)
+	draft, err := app.conversation.UpsertConversationDraft(conv.ID, user.ID, req.Content, req.Meta, req.Version)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	return r.SendEnvelope(draft)
+}
+
+// handleGetConversationDraft retrieves the current user's draft for a conversation.
+func handleGetConversationDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Check access to conversation.
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	draft, err := app.conversation.GetConversationDraft(conv.ID, user.ID)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -98,3 +126,156 @@ func handleDeleteConversationDraft(r *fastglue.Request) error {
 
 	return r.SendEnvelope(true)
 }
+
+// handleGetTeamDraft returns the shared team draft of a conversation, null if there is none.
+func handleGetTeamDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	draft, err := app.conversation.GetTeamDraft(conv.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handlePromoteConversationDraft shares the current user's draft of a conversation as its team draft.
+func handlePromoteConversationDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	draft, err := app.conversation.PromoteDraft(conv.UUID, conv.ID, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handleUpdateTeamDraft saves the team draft of a conversation. The current user must hold its lock.
+func handleUpdateTeamDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		req   = draftReq{}
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if err := r.Decode(&req, "json"); err != nil {
+		app.lo.Error("error decoding team draft request", "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	if len(req.Meta) > maxMetaSize {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+
+	draft, err := app.conversation.UpdateTeamDraft(conv.UUID, conv.ID, user.ID, req.Content, req.Meta)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handleDeleteTeamDraft discards the team draft of a conversation.
+func handleDeleteTeamDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if err := app.conversation.DeleteTeamDraft(conv.UUID, conv.ID, user.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleLockTeamDraft locks the team draft of a conversation for editing by the current user.
+// Locks expire unless renewed by saving or locking again.
+func handleLockTeamDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	draft, err := app.conversation.LockTeamDraft(conv.UUID, conv.ID, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handleUnlockTeamDraft releases the current user's lock on the team draft of a conversation.
+func handleUnlockTeamDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	draft, err := app.conversation.UnlockTeamDraft(conv.UUID, conv.ID, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
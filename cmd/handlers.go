@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/abhinavxd/libredesk/internal/captcha"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/ws"
 	"github.com/valyala/fasthttp"
@@ -17,7 +18,7 @@ import (
 const maxPageSize = 500
 
 // initHandlers initializes the HTTP routes and handlers for the application.
-func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
+func initHandlers(g *fastglue.Fastglue, app *App, hub *ws.Hub) {
 	// Authentication.
 	g.POST("/api/v1/auth/login", rateLimit(handleLogin, "auth"))
 	g.GET("/logout", auth(handleLogout))
@@ -40,6 +41,10 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/settings/general", perm(handleUpdateGeneralSettings, "general_settings:manage"))
 	g.GET("/api/v1/settings/notifications/email", perm(handleGetEmailNotificationSettings, "notification_settings:manage"))
 	g.PUT("/api/v1/settings/notifications/email", perm(handleUpdateEmailNotificationSettings, "notification_settings:manage"))
+	g.GET("/api/v1/settings/captcha", perm(handleGetCaptchaSettings, "general_settings:manage"))
+	g.PUT("/api/v1/settings/captcha", perm(handleUpdateCaptchaSettings, "general_settings:manage"))
+	g.GET("/api/v1/settings/abuse", perm(handleGetAbuseSettings, "general_settings:manage"))
+	g.PUT("/api/v1/settings/abuse", perm(handleUpdateAbuseSettings, "general_settings:manage"))
 
 	// OpenID connect single sign-on.
 	g.GET("/api/v1/oidc", perm(handleGetAllOIDC, "oidc:manage"))
@@ -50,6 +55,7 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 
 	// Conversations.
 	g.GET("/api/v1/conversations/all", perm(handleGetAllConversations, "conversations:read_all"))
+	g.GET("/api/v1/conversations/trash", perm(handleGetTrashedConversations, "conversations:trash"))
 	g.GET("/api/v1/conversations/unassigned", perm(handleGetUnassignedConversations, "conversations:read_unassigned"))
 	g.GET("/api/v1/conversations/assigned", perm(handleGetAssignedConversations, "conversations:read_assigned"))
 	g.GET("/api/v1/conversations/mentioned", perm(handleGetMentionedConversations, "conversations:read"))
@@ -57,32 +63,62 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/views/{id}/conversations", perm(handleGetViewConversations, "conversations:read"))
 	g.GET("/api/v1/conversations/{uuid}", perm(handleGetConversation, "conversations:read"))
 	g.GET("/api/v1/conversations/{uuid}/participants", perm(handleGetConversationParticipants, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/stats", perm(handleGetConversationStats, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/export", perm(handleExportConversation, "messages:read"))
+	g.POST("/api/v1/conversations/{uuid}/transcript", perm(handleSendConversationTranscript, "messages:write"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/user", perm(handleUpdateUserAssignee, "conversations:update_user_assignee"))
+	g.GET("/api/v1/conversations/{uuid}/related", perm(handleGetRelatedConversations, "conversations:read"))
+	g.PUT("/api/v1/conversations/{uuid}/summary", perm(handleUpdateConversationSummary, "messages:write"))
+	g.GET("/api/v1/conversations/{uuid}/handoff-note", perm(handleGetHandoffNote, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/threads", perm(handleGetThreads, "messages:read"))
+	g.POST("/api/v1/conversations/{uuid}/threads", perm(handleCreateThread, "messages:write_private"))
+	g.PUT("/api/v1/conversations/{uuid}/threads/{id}", perm(handleUpdateThread, "messages:write_private"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/team", perm(handleUpdateTeamAssignee, "conversations:update_team_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/user/remove", perm(handleRemoveUserAssignee, "conversations:update_user_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/team/remove", perm(handleRemoveTeamAssignee, "conversations:update_team_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/priority", perm(handleUpdateConversationPriority, "conversations:update_priority"))
 	g.PUT("/api/v1/conversations/{uuid}/status", perm(handleUpdateConversationStatus, "conversations:update_status"))
+	g.POST("/api/v1/conversations/{uuid}/spam", perm(handleMarkConversationSpam, "conversations:update_status"))
+	g.DELETE("/api/v1/conversations/{uuid}/spam", perm(handleMarkConversationNotSpam, "conversations:update_status"))
+	g.POST("/api/v1/conversations/{uuid}/trash", perm(handleTrashConversation, "conversations:trash"))
+	g.DELETE("/api/v1/conversations/{uuid}/trash", perm(handleRestoreConversation, "conversations:trash"))
 	g.PUT("/api/v1/conversations/{uuid}/last-seen", perm(handleUpdateConversationAssigneeLastSeen, "conversations:read"))
 	g.PUT("/api/v1/conversations/{uuid}/mark-unread", perm(handleMarkConversationAsUnread, "conversations:read"))
 	g.POST("/api/v1/conversations/{uuid}/tags", perm(handleUpdateConversationtags, "conversations:update_tags"))
 	g.GET("/api/v1/conversations/{uuid}/page-visits", perm(handleGetContactPageVisits, "conversations:read"))
 	g.GET("/api/v1/conversations/{cuuid}/messages/{uuid}", perm(handleGetMessage, "messages:read"))
 	g.GET("/api/v1/conversations/{uuid}/messages", perm(handleGetMessages, "messages:read"))
-	g.POST("/api/v1/conversations/{cuuid}/messages", perm(handleSendMessage, "messages:write"))
+	g.POST("/api/v1/conversations/{cuuid}/messages", perm(handleSendMessage, "messages:write_private"))
+	g.GET("/api/v1/conversations/{cuuid}/messages/{uuid}/headers", perm(handleGetMessageHeaders, "messages:read"))
+	g.PUT("/api/v1/conversations/{cuuid}/messages/{uuid}/pin", perm(handlePinMessage, "messages:write_private"))
+	g.DELETE("/api/v1/conversations/{cuuid}/messages/{uuid}/pin", perm(handleUnpinMessage, "messages:write_private"))
+	g.POST("/api/v1/conversations/{cuuid}/messages/{uuid}/reactions", perm(handleAddMessageReaction, "messages:write_private"))
+	g.DELETE("/api/v1/conversations/{cuuid}/messages/{uuid}/reactions", perm(handleRemoveMessageReaction, "messages:write_private"))
 	g.PUT("/api/v1/conversations/{cuuid}/messages/{uuid}/retry", perm(handleRetryMessage, "messages:write"))
 	g.POST("/api/v1/conversations", perm(handleCreateConversation, "conversations:write"))
-	g.PUT("/api/v1/conversations/{uuid}/custom-attributes", auth(handleUpdateConversationCustomAttributes))
+	g.POST("/api/v1/conversations/import", perm(handleImportConversation, "conversations:write"))
+	g.POST("/api/v1/integrations/notes", perm(handleCreateIntegrationNote, "messages:write_integration_notes"))
+	g.PUT("/api/v1/conversations/{uuid}/custom-attributes", perm(handleUpdateConversationCustomAttributes, "conversations:update_custom_attributes"))
 	g.PUT("/api/v1/conversations/{uuid}/contacts/custom-attributes", auth(handleUpdateContactCustomAttributes))
 	// Draft endpoints
 	g.GET("/api/v1/drafts", auth(handleGetAllDrafts))
+	g.GET("/api/v1/conversations/{uuid}/draft", auth(handleGetConversationDraft))
 	g.POST("/api/v1/conversations/{uuid}/draft", auth(handleUpsertConversationDraft))
 	g.DELETE("/api/v1/conversations/{uuid}/draft", auth(handleDeleteConversationDraft))
+	g.POST("/api/v1/conversations/{uuid}/draft/promote", auth(handlePromoteConversationDraft))
+	g.GET("/api/v1/conversations/{uuid}/team-draft", auth(handleGetTeamDraft))
+	g.PUT("/api/v1/conversations/{uuid}/team-draft", auth(handleUpdateTeamDraft))
+	g.DELETE("/api/v1/conversations/{uuid}/team-draft", auth(handleDeleteTeamDraft))
+	g.POST("/api/v1/conversations/{uuid}/team-draft/lock", auth(handleLockTeamDraft))
+	g.DELETE("/api/v1/conversations/{uuid}/team-draft/lock", auth(handleUnlockTeamDraft))
 
 	// Search.
 	g.GET("/api/v1/conversations/search", perm(handleSearchConversations, "conversations:read"))
+	g.POST("/api/v1/conversations/recalculate-metrics", perm(handleRecalculateConversationMetrics, "general_settings:manage"))
+	g.GET("/api/v1/conversations/recalculate-metrics/status", perm(handleGetConversationMetricsRecalculationStatus, "general_settings:manage"))
 	g.GET("/api/v1/messages/search", perm(handleSearchMessages, "messages:read"))
 	g.GET("/api/v1/contacts/search", perm(handleSearchContacts, "contacts:read"))
+	g.GET("/api/v1/mentions/search", perm(handleSearchMentions, "messages:write_private"))
 
 	// Views.
 	g.GET("/api/v1/views/me", perm(handleGetUserViews, "view:manage"))
@@ -91,6 +127,8 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.DELETE("/api/v1/views/me/{id}", perm(handleDeleteUserView, "view:manage"))
 
 	g.GET("/api/v1/views/shared", auth(handleGetSharedViews))
+	g.GET("/api/v1/views/preferences", auth(handleGetViewPreferences))
+	g.PUT("/api/v1/views/preferences", auth(handleUpdateViewPreference))
 
 	g.GET("/api/v1/shared-views", perm(handleGetAllSharedViews, "shared_views:manage"))
 	g.GET("/api/v1/shared-views/{id}", perm(handleGetSharedView, "shared_views:manage"))
@@ -103,6 +141,10 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.POST("/api/v1/statuses", perm(handleCreateStatus, "status:manage"))
 	g.PUT("/api/v1/statuses/{id}", perm(handleUpdateStatus, "status:manage"))
 	g.DELETE("/api/v1/statuses/{id}", perm(handleDeleteStatus, "status:manage"))
+	g.GET("/api/v1/snooze-presets", auth(handleGetSnoozePresets))
+	g.POST("/api/v1/snooze-presets", perm(handleCreateSnoozePreset, "status:manage"))
+	g.PUT("/api/v1/snooze-presets/{id}", perm(handleUpdateSnoozePreset, "status:manage"))
+	g.DELETE("/api/v1/snooze-presets/{id}", perm(handleDeleteSnoozePreset, "status:manage"))
 	g.GET("/api/v1/priorities", auth(handleGetPriorities))
 
 	// Tags.
@@ -125,10 +167,22 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/agents/me", auth(handleGetCurrentAgent))
 	g.PUT("/api/v1/agents/me", auth(handleUpdateCurrentAgent))
 	g.GET("/api/v1/agents/me/teams", auth(handleGetCurrentAgentTeams))
+	g.GET("/api/v1/agents/me/dashboard", auth(handleGetCurrentAgentDashboard))
 	g.PUT("/api/v1/agents/me/availability", auth(handleUpdateAgentAvailability))
 	g.DELETE("/api/v1/agents/me/avatar", auth(handleDeleteCurrentAgentAvatar))
+	g.GET("/api/v1/agents/me/keyboard-bindings", auth(handleGetCurrentAgentKeyboardBindings))
+	g.PUT("/api/v1/agents/me/keyboard-bindings", auth(handleUpdateCurrentAgentKeyboardBindings))
+	g.GET("/api/v1/agents/me/notification-preferences", auth(handleGetCurrentAgentNotificationPreferences))
+	g.PUT("/api/v1/agents/me/notification-preferences", auth(handleUpdateCurrentAgentNotificationPreferences))
+	g.GET("/api/v1/agents/me/signature", auth(handleGetCurrentAgentSignature))
+	g.PUT("/api/v1/agents/me/signature", auth(handleUpdateCurrentAgentSignature))
+	g.POST("/api/v1/agents/me/totp/setup", auth(handleSetupTOTP))
+	g.POST("/api/v1/agents/me/totp/enable", auth(handleEnableTOTP))
+	g.POST("/api/v1/agents/me/totp/disable", auth(handleDisableTOTP))
+	g.POST("/api/v1/agents/me/totp/recovery-codes", auth(handleRegenerateRecoveryCodes))
 
 	g.GET("/api/v1/agents/compact", auth(handleGetAgentsCompact))
+	g.GET("/api/v1/agents/on-shift", auth(handleGetOnShiftAgents))
 	g.GET("/api/v1/agents", perm(handleGetAgents, "users:manage"))
 	g.GET("/api/v1/agents/{id}", perm(handleGetAgent, "users:manage"))
 	g.POST("/api/v1/agents", perm(handleCreateAgent, "users:manage"))
@@ -138,6 +192,13 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/agents/import/status", perm(handleGetAgentImportStatus, "users:manage"))
 	g.POST("/api/v1/agents/{id}/api-key", perm(handleGenerateAPIKey, "users:manage"))
 	g.DELETE("/api/v1/agents/{id}/api-key", perm(handleRevokeAPIKey, "users:manage"))
+	g.DELETE("/api/v1/agents/{id}/totp", perm(handleResetAgentTOTP, "users:manage"))
+	g.GET("/api/v1/agents/{id}/schedule", perm(handleGetAgentSchedule, "users:manage"))
+	g.PUT("/api/v1/agents/{id}/schedule", perm(handleUpdateAgentSchedule, "users:manage"))
+	g.DELETE("/api/v1/agents/{id}/schedule", perm(handleDeleteAgentSchedule, "users:manage"))
+	g.GET("/api/v1/agents/{id}/schedule/overrides", perm(handleGetAgentScheduleOverrides, "users:manage"))
+	g.POST("/api/v1/agents/{id}/schedule/overrides", perm(handleCreateAgentScheduleOverride, "users:manage"))
+	g.DELETE("/api/v1/agents/{id}/schedule/overrides/{override_id}", perm(handleDeleteAgentScheduleOverride, "users:manage"))
 	g.POST("/api/v1/agents/reset-password", rateLimit(tryAuth(handleResetPassword), "auth"))
 	g.POST("/api/v1/agents/set-password", rateLimit(tryAuth(handleSetPassword), "auth"))
 
@@ -146,6 +207,9 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/contacts/{id}", perm(handleGetContact, "contacts:read"))
 	g.PUT("/api/v1/contacts/{id}", perm(handleUpdateContact, "contacts:write"))
 	g.PUT("/api/v1/contacts/{id}/block", perm(handleBlockContact, "contacts:block"))
+	g.PUT("/api/v1/contacts/{id}/risk-flags", perm(handleUpdateContactRiskFlags, "contacts:manage_risk_flags"))
+	g.GET("/api/v1/contacts/{id}/preferences", perm(handleGetContactPreferences, "contacts:read"))
+	g.PUT("/api/v1/contacts/{id}/preferences", perm(handleUpdateContactPreferences, "contacts:write"))
 
 	// Contact notes.
 	g.GET("/api/v1/contacts/{id}/notes", perm(handleGetContactNotes, "contact_notes:read"))
@@ -169,14 +233,44 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/automations/rules/weights", perm(handleUpdateAutomationRuleWeights, "automations:manage"))
 	g.PUT("/api/v1/automations/rules/execution-mode", perm(handleUpdateAutomationRuleExecutionMode, "automations:manage"))
 	g.DELETE("/api/v1/automations/rules/{id}", perm(handleDeleteAutomationRule, "automations:manage"))
+	g.GET("/api/v1/automations/rules/{id}/logs", perm(handleGetAutomationRuleLogs, "automations:manage"))
+	g.POST("/api/v1/automations/rules/dry-run", perm(handleAutomationRuleDryRun, "automations:manage"))
 
 	// Inboxes.
 	g.GET("/api/v1/inboxes", auth(handleGetInboxes))
+	g.GET("/api/v1/inboxes/health", perm(handleGetHealth, "inboxes:manage"))
 	g.GET("/api/v1/inboxes/{id}", perm(handleGetInbox, "inboxes:manage"))
 	g.POST("/api/v1/inboxes", perm(handleCreateInbox, "inboxes:manage"))
 	g.PUT("/api/v1/inboxes/{id}/toggle", perm(handleToggleInbox, "inboxes:manage"))
 	g.PUT("/api/v1/inboxes/{id}", perm(handleUpdateInbox, "inboxes:manage"))
 	g.DELETE("/api/v1/inboxes/{id}", perm(handleDeleteInbox, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/outbox", perm(handleGetInboxOutbox, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/email-template/preview", perm(handleGetInboxEmailTemplatePreview, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/migration", perm(handleGetInboxMigration, "inboxes:manage"))
+	g.POST("/api/v1/inboxes/{id}/migration", perm(handleCreateInboxMigration, "inboxes:manage"))
+	g.DELETE("/api/v1/inboxes/{id}/migration", perm(handleCancelInboxMigration, "inboxes:manage"))
+	g.POST("/api/v1/inboxes/{id}/migration/verify", perm(handleVerifyInboxMigration, "inboxes:manage"))
+	g.POST("/api/v1/inboxes/{id}/migration/complete", perm(handleCompleteInboxMigration, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/migration/notice-status", perm(handleGetInboxMigrationNoticeStatus, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/sender-rules", perm(handleGetSenderRules, "inboxes:manage"))
+	g.PUT("/api/v1/inboxes/{id}/sender-rules", perm(handleSetSenderRule, "inboxes:manage"))
+	g.DELETE("/api/v1/inboxes/{id}/sender-rules", perm(handleDeleteSenderRule, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/autoresponders", perm(handleGetAutoresponders, "inboxes:manage"))
+	g.POST("/api/v1/inboxes/{id}/autoresponders", perm(handleCreateAutoresponder, "inboxes:manage"))
+	g.PUT("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}", perm(handleUpdateAutoresponder, "inboxes:manage"))
+	g.DELETE("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}", perm(handleDeleteAutoresponder, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}/variants", perm(handleGetVariants, "inboxes:manage"))
+	g.GET("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}/variants/stats", perm(handleGetVariantStats, "inboxes:manage"))
+	g.POST("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}/variants", perm(handleCreateVariant, "inboxes:manage"))
+	g.PUT("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}/variants/{variant_id}", perm(handleUpdateVariant, "inboxes:manage"))
+	g.DELETE("/api/v1/inboxes/{id}/autoresponders/{autoresponder_id}/variants/{variant_id}", perm(handleDeleteVariant, "inboxes:manage"))
+
+	// Web forms.
+	g.GET("/api/v1/web-forms", perm(handleGetWebForms, "inboxes:manage"))
+	g.GET("/api/v1/web-forms/{id}", perm(handleGetWebForm, "inboxes:manage"))
+	g.POST("/api/v1/web-forms", perm(handleCreateWebForm, "inboxes:manage"))
+	g.PUT("/api/v1/web-forms/{id}", perm(handleUpdateWebForm, "inboxes:manage"))
+	g.DELETE("/api/v1/web-forms/{id}", perm(handleDeleteWebForm, "inboxes:manage"))
 
 	// OAuth endpoints for email inboxes.
 	g.POST("/api/v1/inboxes/oauth/{provider}/authorize", perm(handleOAuthAuthorize, "inboxes:manage"))
@@ -208,20 +302,62 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/context-links/{id}/toggle", perm(handleToggleContextLink, "context_links:manage"))
 	g.GET("/api/v1/context-links/{id}/url", auth(handleGetContextLinkURL))
 
+	// Announcements.
+	g.GET("/api/v1/announcements", perm(handleGetAnnouncements, "announcements:manage"))
+	g.GET("/api/v1/announcements/active", auth(handleGetActiveAnnouncements))
+	g.GET("/api/v1/announcements/{id}", perm(handleGetAnnouncement, "announcements:manage"))
+	g.GET("/api/v1/announcements/{id}/acknowledgements", perm(handleGetAnnouncementAcknowledgements, "announcements:manage"))
+	g.POST("/api/v1/announcements", perm(handleCreateAnnouncement, "announcements:manage"))
+	g.PUT("/api/v1/announcements/{id}", perm(handleUpdateAnnouncement, "announcements:manage"))
+	g.DELETE("/api/v1/announcements/{id}", perm(handleDeleteAnnouncement, "announcements:manage"))
+	g.POST("/api/v1/announcements/{id}/acknowledge", auth(handleAcknowledgeAnnouncement))
+
+	// Assets.
+	g.GET("/api/v1/assets", perm(handleGetAssets, "assets:manage"))
+	g.GET("/api/v1/assets/available", auth(handleGetAvailableAssets))
+	g.POST("/api/v1/assets", perm(handleCreateAsset, "assets:manage"))
+	g.PUT("/api/v1/assets/{id}", perm(handleUpdateAsset, "assets:manage"))
+	g.DELETE("/api/v1/assets/{id}", perm(handleDeleteAsset, "assets:manage"))
+	g.POST("/api/v1/assets/{id}/use", perm(handleUseAsset, "messages:write"))
+
+	// Maintenance windows.
+	g.GET("/api/v1/maintenance-windows", perm(handleGetMaintenanceWindows, "notification_settings:manage"))
+	g.GET("/api/v1/maintenance-windows/{id}", perm(handleGetMaintenanceWindow, "notification_settings:manage"))
+	g.POST("/api/v1/maintenance-windows", perm(handleCreateMaintenanceWindow, "notification_settings:manage"))
+	g.PUT("/api/v1/maintenance-windows/{id}", perm(handleUpdateMaintenanceWindow, "notification_settings:manage"))
+	g.DELETE("/api/v1/maintenance-windows/{id}", perm(handleDeleteMaintenanceWindow, "notification_settings:manage"))
+
 	// Reports.
 	g.GET("/api/v1/reports/overview/sla", perm(handleOverviewSLA, "reports:manage"))
 	g.GET("/api/v1/reports/overview/counts", perm(handleOverviewCounts, "reports:manage"))
 	g.GET("/api/v1/reports/overview/charts", perm(handleOverviewCharts, "reports:manage"))
 	g.GET("/api/v1/reports/overview/csat", perm(handleOverviewCSAT, "reports:manage"))
+	g.GET("/api/v1/reports/csat/breakdown", perm(handleCSATBreakdown, "reports:manage"))
+	g.GET("/api/v1/reports/csat/low-scores", perm(handleCSATLowScores, "reports:manage"))
+	g.GET("/api/v1/reports/nps", perm(handleNPSTrend, "reports:manage"))
+	g.GET("/api/v1/reports/abuse", perm(handleAbuseVolume, "reports:manage"))
 	g.GET("/api/v1/reports/overview/messages", perm(handleOverviewMessageVolume, "reports:manage"))
 	g.GET("/api/v1/reports/overview/tags", perm(handleOverviewTagDistribution, "reports:manage"))
+	g.GET("/api/v1/reports/tags/trend", perm(handleTagTrend, "reports:manage"))
+	g.GET("/api/v1/reports/tags/co-occurrence", perm(handleTagCoOccurrence, "reports:manage"))
+	g.GET("/api/v1/reports/overview/heatmap", perm(handleWaitingTimeHeatmap, "reports:manage"))
+	g.GET("/api/v1/reports/overview/sla/incidents", perm(handleOverviewSLAIncidents, "reports:manage"))
+	g.GET("/api/v1/reports/sla/assignment", perm(handleAssignmentSLA, "reports:manage"))
+	g.GET("/api/v1/reports/overview/topics", perm(handleOverviewTopics, "reports:manage"))
+	g.GET("/api/v1/reports/overview/collaboration", perm(handleOverviewCollaboration, "reports:manage"))
 
 	// Templates.
 	g.GET("/api/v1/templates", perm(handleGetTemplates, "templates:manage"))
+	g.GET("/api/v1/templates/variables", auth(handleGetTemplateVariables))
 	g.GET("/api/v1/templates/{id}", perm(handleGetTemplate, "templates:manage"))
 	g.POST("/api/v1/templates", perm(handleCreateTemplate, "templates:manage"))
 	g.PUT("/api/v1/templates/{id}", perm(handleUpdateTemplate, "templates:manage"))
 	g.DELETE("/api/v1/templates/{id}", perm(handleDeleteTemplate, "templates:manage"))
+	g.GET("/api/v1/templates/{id}/variants", perm(handleGetVariants, "templates:manage"))
+	g.GET("/api/v1/templates/{id}/variants/stats", perm(handleGetVariantStats, "templates:manage"))
+	g.POST("/api/v1/templates/{id}/variants", perm(handleCreateVariant, "templates:manage"))
+	g.PUT("/api/v1/templates/{id}/variants/{variant_id}", perm(handleUpdateVariant, "templates:manage"))
+	g.DELETE("/api/v1/templates/{id}/variants/{variant_id}", perm(handleDeleteVariant, "templates:manage"))
 
 	// Business hours.
 	g.GET("/api/v1/business-hours", auth(handleGetBusinessHours))
@@ -237,6 +373,14 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/sla/{id}", perm(handleUpdateSLA, "sla:manage"))
 	g.DELETE("/api/v1/sla/{id}", perm(handleDeleteSLA, "sla:manage"))
 
+	// SLA incidents.
+	g.GET("/api/v1/sla/incidents", perm(handleGetIncidents, "sla:manage"))
+	g.GET("/api/v1/sla/incidents/{id}", perm(handleGetIncident, "sla:manage"))
+	g.POST("/api/v1/sla/incidents", perm(handleCreateIncident, "sla:manage"))
+	g.PUT("/api/v1/sla/incidents/{id}", perm(handleUpdateIncident, "sla:manage"))
+	g.DELETE("/api/v1/sla/incidents/{id}", perm(handleDeleteIncident, "sla:manage"))
+	g.POST("/api/v1/sla/incidents/statuspage", handleStatuspageWebhook)
+
 	// AI completions.
 	g.GET("/api/v1/ai/prompts", auth(handleGetAIPrompts))
 	g.POST("/api/v1/ai/completion", auth(handleAICompletion))
@@ -253,7 +397,7 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/activity-logs", perm(handleGetActivityLogs, "activity_logs:manage"))
 
 	// CSAT.
-	g.POST("/api/v1/csat/{uuid}/response", rateLimit(handleSubmitCSATResponse, "public"))
+	g.POST("/api/v1/csat/{token}/response", rateLimit(handleSubmitCSATResponse, "public"))
 
 	// User notifications.
 	g.GET("/api/v1/notifications", auth(handleGetUserNotifications))
@@ -262,6 +406,9 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/notifications/read-all", auth(handleMarkAllNotificationsAsRead))
 	g.DELETE("/api/v1/notifications/{id}", auth(handleDeleteNotification))
 	g.DELETE("/api/v1/notifications", auth(handleDeleteAllNotifications))
+	g.GET("/api/v1/notifications/devices", auth(handleGetDeviceTokens))
+	g.POST("/api/v1/notifications/devices", auth(handleRegisterDeviceToken))
+	g.DELETE("/api/v1/notifications/devices/{id}", auth(handleDeleteDeviceToken))
 
 	// WebSocket.
 	g.GET("/ws", auth(func(r *fastglue.Request) error {
@@ -276,7 +423,8 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/widget/chat/settings", rateLimit(validateWidgetInbox(handleGetChatSettings), "widget"))
 	g.POST("/api/v1/widget/chat/auth/exchange", rateLimit(validateWidgetInbox(handleAuthExchange), "widget"))
 	g.GET("/api/v1/widget/chat/auth/me", rateLimit(widgetAuth(handleWidgetAuthMe), "widget"))
-	g.POST("/api/v1/widget/chat/conversations/init", rateLimit(widgetAuth(handleChatInit), "widget"))
+	g.POST("/api/v1/widget/chat/identify", rateLimit(widgetAuth(handleChatIdentify), "widget"))
+	g.POST("/api/v1/widget/chat/conversations/init", rateLimit(requireCaptcha(widgetAuth(handleChatInit), captcha.EndpointWidget), "widget"))
 	g.GET("/api/v1/widget/chat/conversations", rateLimit(widgetAuth(handleGetConversations), "widget"))
 	g.POST("/api/v1/widget/chat/conversations/{uuid}/update-last-seen", rateLimit(widgetAuth(handleChatUpdateLastSeen), "widget"))
 	g.GET("/api/v1/widget/chat/conversations/{uuid}", rateLimit(widgetAuth(handleChatGetConversation), "widget"))
@@ -305,12 +453,35 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/static/public/{all:*}", serveStaticFiles)
 
 	// Public pages.
-	g.GET("/csat/{uuid}", rateLimit(handleShowCSAT, "public"))
-	g.GET("/csat/{uuid}/widget", rateLimit(handleShowCSATWidget, "public"))
-	g.POST("/csat/{uuid}", rateLimit(handleUpdateCSATResponse, "public"))
+	g.GET("/csat/{token}", rateLimit(handleShowCSAT, "public"))
+	g.GET("/csat/{token}/widget", rateLimit(handleShowCSATWidget, "public"))
+	g.POST("/csat/{token}", rateLimit(handleUpdateCSATResponse, "public"))
+	g.GET("/nps/{token}", rateLimit(handleShowNPS, "public"))
+	g.POST("/nps/{token}", rateLimit(handleSubmitNPS, "public"))
+	g.GET("/nps/{token}/unsubscribe", rateLimit(handleNPSUnsubscribe, "public"))
+	g.GET("/forms/{uuid}", rateLimit(handleShowWebForm, "public"))
+	g.GET("/email/open/{uuid}", rateLimit(handleTrackEmailOpen, "public"))
+	g.POST("/forms/{uuid}", rateLimit(handleSubmitWebForm, "public"))
+
+	// SCIM 2.0 provisioning.
+	g.GET("/scim/v2/ServiceProviderConfig", rateLimit(scimAuth(handleSCIMServiceProviderConfig), "api"))
+	g.GET("/scim/v2/Users", rateLimit(scimAuth(handleSCIMGetUsers), "api"))
+	g.POST("/scim/v2/Users", rateLimit(scimAuth(handleSCIMCreateUser), "api"))
+	g.GET("/scim/v2/Users/{id}", rateLimit(scimAuth(handleSCIMGetUser), "api"))
+	g.PUT("/scim/v2/Users/{id}", rateLimit(scimAuth(handleSCIMReplaceUser), "api"))
+	patch(g, app, "/scim/v2/Users/{id}", rateLimit(scimAuth(handleSCIMPatchUser), "api"))
+	g.DELETE("/scim/v2/Users/{id}", rateLimit(scimAuth(handleSCIMDeleteUser), "api"))
+	g.GET("/scim/v2/Groups", rateLimit(scimAuth(handleSCIMGetGroups), "api"))
+	g.POST("/scim/v2/Groups", rateLimit(scimAuth(handleSCIMCreateGroup), "api"))
+	g.GET("/scim/v2/Groups/{id}", rateLimit(scimAuth(handleSCIMGetGroup), "api"))
+	g.PUT("/scim/v2/Groups/{id}", rateLimit(scimAuth(handleSCIMReplaceGroup), "api"))
+	patch(g, app, "/scim/v2/Groups/{id}", rateLimit(scimAuth(handleSCIMPatchGroup), "api"))
+	g.DELETE("/scim/v2/Groups/{id}", rateLimit(scimAuth(handleSCIMDeleteGroup), "api"))
 
 	// Health check.
 	g.GET("/health", handleHealthCheck)
+	g.GET("/healthz", handleHealthz)
+	g.GET("/readyz", handleReadyz)
 }
 
 // serveIndexPage serves the main index page of the application.
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/inbox"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// healthCheckTimeout bounds each dependency check.
+const healthCheckTimeout = 3 * time.Second
+
+// dependencyHealth is the result of checking a single dependency.
+type dependencyHealth struct {
+	Healthy   bool   `json:"healthy"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthStatus is the response of the unauthenticated health and readiness probes.
+type healthStatus struct {
+	Healthy bool `json:"healthy"`
+}
+
+// healthReport is the detailed status of the dependencies and inboxes.
+type healthReport struct {
+	Healthy bool                        `json:"healthy"`
+	Checks  map[string]dependencyHealth `json:"checks"`
+	Inboxes []inbox.InboxHealth         `json:"inboxes,omitempty"`
+}
+
+// handleHealthz is the liveness probe, it checks the DB and Redis connections.
+func handleHealthz(r *fastglue.Request) error {
+	app := r.Context.(*App)
+	return sendHealthReport(r, checkCoreDependencies(app))
+}
+
+// handleReadyz is the readiness probe, it checks the DB and Redis connections.
+// Inbox health is not part of readiness as a single failing mailbox or SMTP server
+// should not take the node out of rotation, it is reported by handleGetHealth instead.
+func handleReadyz(r *fastglue.Request) error {
+	app := r.Context.(*App)
+	return sendHealthReport(r, checkCoreDependencies(app))
+}
+
+// handleGetHealth returns the detailed status of the DB and Redis connections and
+// the IMAP receivers and SMTP senders of all running inboxes.
+func handleGetHealth(r *fastglue.Request) error {
+	app := r.Context.(*App)
+	report := checkCoreDependencies(app)
+	report.Inboxes = app.inbox.Health()
+	return r.SendEnvelope(report)
+}
+
+// checkCoreDependencies pings the DB and Redis.
+func checkCoreDependencies(app *App) healthReport {
+	report := healthReport{
+		Healthy: true,
+		Checks: map[string]dependencyHealth{
+			"db": checkDependency(func(ctx context.Context) error {
+				return app.db.PingContext(ctx)
+			}),
+			"redis": checkDependency(func(ctx context.Context) error {
+				return app.redis.Ping(ctx).Err()
+			}),
+		},
+	}
+	for _, c := range report.Checks {
+		report.Healthy = report.Healthy && c.Healthy
+	}
+	return report
+}
+
+// checkDependency runs a dependency check with a timeout and measures its latency.
+func checkDependency(check func(ctx context.Context) error) dependencyHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	h := dependencyHealth{
+		Healthy:   err == nil,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		h.Error = err.Error()
+	}
+	return h
+}
+
+// sendHealthReport responds with 200 if the report is healthy, 503 otherwise.
+// Only the status is sent as the probes are unauthenticated.
+func sendHealthReport(r *fastglue.Request, report healthReport) error {
+	status := healthStatus{Healthy: report.Healthy}
+	if !report.Healthy {
+		return r.SendErrorEnvelope(fasthttp.StatusServiceUnavailable, "unhealthy", status, envelope.GeneralError)
+	}
+	return r.SendEnvelope(status)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// inboxMigrationReq starts the address migration of an email inbox.
+type inboxMigrationReq struct {
+	From                 string `json:"from"`
+	ReplyTo              string `json:"reply_to"`
+	EnablePlusAddressing bool   `json:"enable_plus_addressing"`
+}
+
+// jobNSInboxMigration returns the job namespace of the address change notices of an inbox.
+func jobNSInboxMigration(inboxID int) string {
+	return "inbox_migration_" + strconv.Itoa(inboxID)
+}
+
+// handleGetInboxMigration returns the pending address migration of an inbox, null if there is none.
+func handleGetInboxMigration(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	mig, err := app.inbox.GetPendingMigration(id)
+	if err != nil {
+		if envErr, ok := err.(envelope.Error); ok && envErr.ErrorType == envelope.NotFoundError {
+			return r.SendEnvelope(nil)
+		}
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(mig)
+}
+
+// handleCreateInboxMigration starts moving an email inbox to a new address and emails a verification
+// code to the new address, sent from it through the inbox's SMTP servers.
+func handleCreateInboxMigration(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		req   = inboxMigrationReq{}
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	req.From = strings.TrimSpace(req.From)
+	req.ReplyTo = strings.TrimSpace(req.ReplyTo)
+	to, err := mail.ParseAddress(req.From)
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidFromAddress"), nil, envelope.InputError)
+	}
+	if req.ReplyTo != "" {
+		if _, err := mail.ParseAddress(req.ReplyTo); err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidEmail"), nil, envelope.InputError)
+		}
+	}
+
+	inb, err := app.inbox.Get(id)
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.notFoundInbox"), nil, envelope.InputError)
+	}
+	mig, err := app.inbox.CreateMigration(id, req.From, req.ReplyTo, req.EnablePlusAddressing)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	body := app.i18n.Ts("admin.inbox.migration.verificationBody", "code", mig.VerificationCode)
+	if err := inb.Send(cmodels.OutboundMessage{
+		From:       req.From,
+		To:         []string{to.Address},
+		Subject:    app.i18n.T("admin.inbox.migration.verificationSubject"),
+		Content:    "<p>" + html.EscapeString(body) + "</p>",
+		AltContent: body,
+	}); err != nil {
+		app.lo.Error("error sending inbox migration verification email", "inbox_id", id, "to", to.Address, "error", err)
+		app.inbox.CancelMigration(id)
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("admin.inbox.migration.sendFailed", "error", err.Error()), nil, envelope.InputError)
+	}
+	return r.SendEnvelope(mig)
+}
+
+// handleVerifyInboxMigration verifies the new address of the pending migration of an inbox.
+func handleVerifyInboxMigration(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		req   = struct {
+			Code string `json:"code"`
+		}{}
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	mig, err := app.inbox.VerifyMigration(id, req.Code)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(mig)
+}
+
+// handleCompleteInboxMigration switches an inbox to its verified new address and, if a notice is given,
+// sends it in the background to the inbox's conversations that aren't resolved.
+func handleCompleteInboxMigration(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		req   = struct {
+			Notice string `json:"notice"`
+		}{}
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+
+	mig, err := app.inbox.CompleteMigration(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := reloadInbox(app, id); err != nil {
+		app.lo.Error("error reloading inbox", "id", id, "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.GeneralError)
+	}
+
+	notice := strings.TrimSpace(req.Notice)
+	if notice == "" {
+		return r.SendEnvelope(mig)
+	}
+	content := strings.ReplaceAll(html.EscapeString(notice), "\n", "<br>")
+	ns := jobNSInboxMigration(id)
+	err = app.importer.Submit(ns, func() error {
+		var sent, failed int
+		err := app.conversation.SendAddressChangeNotice(id, content, func(uuid string, err error) {
+			if err != nil {
+				failed++
+				app.importer.UpdateCounts(ns, 0, 0, 1)
+				app.importer.AddLog(ns, fmt.Sprintf("%s: %v", uuid, err))
+				return
+			}
+			sent++
+			app.importer.UpdateCounts(ns, 0, 1, 0)
+		})
+		if err != nil {
+			return err
+		}
+		app.importer.UpdateCounts(ns, sent+failed, 0, 0)
+		app.importer.AddLog(ns, app.i18n.Ts("admin.inbox.migration.noticesSent", "count", strconv.Itoa(sent), "errors", strconv.Itoa(failed)))
+		return nil
+	})
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusConflict, app.i18n.T("admin.inbox.migration.noticeInProgress"), nil, envelope.ConflictError)
+	}
+	return r.SendEnvelope(mig)
+}
+
+// handleGetInboxMigrationNoticeStatus returns the status of the address change notices of an inbox.
+func handleGetInboxMigrationNoticeStatus(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	status, err := app.importer.GetStatus(jobNSInboxMigration(id))
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(status)
+}
+
+// handleCancelInboxMigration cancels the pending address migration of an inbox.
+func handleCancelInboxMigration(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.inbox.CancelMigration(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
@@ -2,18 +2,22 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/mail"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	csatModels "github.com/abhinavxd/libredesk/internal/csat/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/httputil"
 	"github.com/abhinavxd/libredesk/internal/inbox"
 	"github.com/abhinavxd/libredesk/internal/inbox/channel/email/oauth"
 	"github.com/abhinavxd/libredesk/internal/inbox/channel/livechat"
 	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+	"github.com/abhinavxd/libredesk/internal/template"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
@@ -163,6 +167,44 @@ func handleToggleInbox(r *fastglue.Request) error {
 	return r.SendEnvelope(toggledInbox)
 }
 
+// handleGetInboxEmailTemplatePreview renders a sample reply in the inbox's outgoing email template, or in
+// the template given by the template_id query param to preview it before saving.
+func handleGetInboxEmailTemplatePreview(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	inbox, err := app.inbox.GetDBRecord(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	templateID := int(inbox.EmailTemplateID.Int)
+	if r.RequestCtx.QueryArgs().Has("template_id") {
+		templateID, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("template_id")))
+	}
+	html, err := app.tmpl.PreviewEmailTemplate(templateID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(html)
+}
+
+// handleGetInboxOutbox returns the number of pending outgoing messages of an inbox by send priority.
+func handleGetInboxOutbox(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	depth, err := app.conversation.GetOutboxDepth(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(depth)
+}
+
 // handleDeleteInbox deletes an inbox
 func handleDeleteInbox(r *fastglue.Request) error {
 	var (
@@ -205,6 +247,18 @@ func validateInbox(app *App, inbox imodels.Inbox) error {
 	if inbox.Channel == "" {
 		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "channel"), nil)
 	}
+	if err := validateCSATConfig(app, inbox.CSATConfig); err != nil {
+		return err
+	}
+	if inbox.EmailTemplateID.Valid {
+		tmpl, err := app.tmpl.Get(int(inbox.EmailTemplateID.Int))
+		if err != nil {
+			return err
+		}
+		if tmpl.Type != template.TypeEmailOutgoing {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+	}
 
 	// Validate livechat-specific configuration
 	if inbox.Channel == livechat.ChannelLiveChat {
@@ -453,3 +507,25 @@ func trimEmailConfig(cfg *imodels.Config) {
 		cfg.OAuth.TenantID = strings.TrimSpace(cfg.OAuth.TenantID)
 	}
 }
+
+// validateCSATConfig validates the CSAT survey settings of an inbox.
+func validateCSATConfig(app *App, cfg imodels.CSATConfig) error {
+	if cfg.Scale != "" && !csatModels.ValidScale(cfg.Scale) {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	if utf8.RuneCountInString(cfg.Question) > maxCSATQuestionLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxCSATQuestionLength)), nil)
+	}
+	if cfg.Language != "" {
+		if !langCodeRe.MatchString(cfg.Language) {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+		if _, err := app.fs.Get(fmt.Sprintf("/i18n/%s.json", cfg.Language)); err != nil {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+	}
+	if cfg.ThrottleDays < 0 || cfg.ThrottleDays > maxCSATThrottleDays {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	return nil
+}
@@ -13,14 +13,19 @@ import (
 
 	"html/template"
 
+	"github.com/abhinavxd/libredesk/internal/abuse"
 	activitylog "github.com/abhinavxd/libredesk/internal/activity_log"
 	"github.com/abhinavxd/libredesk/internal/ai"
+	"github.com/abhinavxd/libredesk/internal/announcement"
+	"github.com/abhinavxd/libredesk/internal/asset"
 	auth_ "github.com/abhinavxd/libredesk/internal/auth"
 	"github.com/abhinavxd/libredesk/internal/authz"
 	"github.com/abhinavxd/libredesk/internal/autoassigner"
 	"github.com/abhinavxd/libredesk/internal/automation"
+	"github.com/abhinavxd/libredesk/internal/autoresponder"
 	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
 	"github.com/abhinavxd/libredesk/internal/colorlog"
+	contactdigest "github.com/abhinavxd/libredesk/internal/contact_digest"
 	contextlink "github.com/abhinavxd/libredesk/internal/context_link"
 	"github.com/abhinavxd/libredesk/internal/conversation"
 	"github.com/abhinavxd/libredesk/internal/conversation/priority"
@@ -33,11 +38,14 @@ import (
 	"github.com/abhinavxd/libredesk/internal/inbox/channel/livechat"
 	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
 	"github.com/abhinavxd/libredesk/internal/macro"
+	"github.com/abhinavxd/libredesk/internal/maintenance"
 	"github.com/abhinavxd/libredesk/internal/media"
 	fs "github.com/abhinavxd/libredesk/internal/media/stores/localfs"
 	"github.com/abhinavxd/libredesk/internal/media/stores/s3"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	emailnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/email"
+	pushnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/push"
+	"github.com/abhinavxd/libredesk/internal/nps"
 	"github.com/abhinavxd/libredesk/internal/oidc"
 	"github.com/abhinavxd/libredesk/internal/ratelimit"
 	"github.com/abhinavxd/libredesk/internal/report"
@@ -48,8 +56,13 @@ import (
 	"github.com/abhinavxd/libredesk/internal/tag"
 	"github.com/abhinavxd/libredesk/internal/team"
 	tmpl "github.com/abhinavxd/libredesk/internal/template"
+	"github.com/abhinavxd/libredesk/internal/topic"
+	"github.com/abhinavxd/libredesk/internal/tracing"
 	"github.com/abhinavxd/libredesk/internal/user"
+	"github.com/abhinavxd/libredesk/internal/variant"
 	"github.com/abhinavxd/libredesk/internal/view"
+	watchdigest "github.com/abhinavxd/libredesk/internal/watch_digest"
+	"github.com/abhinavxd/libredesk/internal/webform"
 	"github.com/abhinavxd/libredesk/internal/webhook"
 	"github.com/abhinavxd/libredesk/internal/ws"
 	"github.com/jmoiron/sqlx"
@@ -70,6 +83,9 @@ import (
 	"github.com/zerodha/logf"
 )
 
+// defaultRetainHeaders are the raw headers of incoming emails stored when `message.retain_headers` is not configured.
+var defaultRetainHeaders = []string{"Received", "Authentication-Results", "X-Mailer"}
+
 // constants holds the app constants.
 type constants struct {
 	AppBaseURL                  string
@@ -137,6 +153,9 @@ func initFlags() {
 	f.Bool("yes", false, "skip confirmation prompt")
 	f.Bool("upgrade", false, "upgrade the database schema")
 	f.Bool("set-system-user-password", false, "set password for the system user")
+	f.Int("generate-test-data", 0, "generate the given number of fake conversations with contacts and messages, for staging and performance testing")
+	f.Int("test-data-max-messages", 8, "maximum number of messages per conversation generated with --generate-test-data")
+	f.Bool("partition-messages", false, "partition the messages table by month, for large installs. Stop all app instances and take a backup first")
 	f.String("static-dir", "", "path to a directory with custom static files and templates to override the defaults")
 
 	if err := f.Parse(os.Args[1:]); err != nil {
@@ -269,8 +288,9 @@ func initSettings(db *sqlx.DB) *setting.Manager {
 // initUser inits user manager.
 func initUser(i18n *i18n.I18n, DB *sqlx.DB) *user.Manager {
 	mgr, err := user.New(i18n, user.Opts{
-		DB: DB,
-		Lo: initLogger("user_manager"),
+		DB:            DB,
+		Lo:            initLogger("user_manager"),
+		EncryptionKey: ko.MustString("app.encryption_key"),
 	})
 	if err != nil {
 		log.Fatalf("error initializing user manager: %v", err)
@@ -295,6 +315,7 @@ func initConversations(
 	automationEngine *automation.Engine,
 	template *tmpl.Manager,
 	webhook *webhook.Manager,
+	autoresponder *autoresponder.Manager,
 	dispatcher *notifier.Dispatcher,
 ) *conversation.Manager {
 	continuityConfig := &conversation.ContinuityConfig{}
@@ -302,13 +323,15 @@ func initConversations(
 		continuityConfig.BatchCheckInterval = ko.MustDuration("conversation.continuity_scan_interval")
 	}
 
-	c, err := conversation.New(hub, i18n, sla, status, priority, inboxStore, userStore, teamStore, mediaStore, settings, csat, automationEngine, template, webhook, dispatcher, conversation.Opts{
+	c, err := conversation.New(hub, i18n, sla, status, priority, inboxStore, userStore, teamStore, mediaStore, settings, csat, automationEngine, template, webhook, autoresponder, dispatcher, conversation.Opts{
 		DB:                       db,
 		Lo:                       initLogger("conversation_manager"),
 		OutgoingMessageQueueSize: ko.MustInt("message.outgoing_queue_size"),
 		IncomingMessageQueueSize: ko.MustInt("message.incoming_queue_size"),
 		ContinuityConfig:         continuityConfig,
 		SubjectRefFormat:         ko.String("conversation.subject_ref_format"),
+		ReferenceLinks:           ko.Bool("conversation.reference_links"),
+		OutgoingClaimLease:       ko.Duration("message.outgoing_claim_lease"),
 	})
 	if err != nil {
 		log.Fatalf("error initializing conversation manager: %v", err)
@@ -373,13 +396,13 @@ func initBusinessHours(db *sqlx.DB, i18n *i18n.I18n) *businesshours.Manager {
 }
 
 // initSLA inits SLA manager.
-func initSLA(db *sqlx.DB, teamManager *team.Manager, settings *setting.Manager, businessHours *businesshours.Manager, template *tmpl.Manager, userManager *user.Manager, i18n *i18n.I18n, dispatcher *notifier.Dispatcher) *sla.Manager {
+func initSLA(db *sqlx.DB, teamManager *team.Manager, settings *setting.Manager, businessHours *businesshours.Manager, template *tmpl.Manager, userManager *user.Manager, i18n *i18n.I18n, dispatcher *notifier.Dispatcher, webhook *webhook.Manager) *sla.Manager {
 	var lo = initLogger("sla")
 	m, err := sla.New(sla.Opts{
 		DB:   db,
 		Lo:   lo,
 		I18n: i18n,
-	}, teamManager, settings, businessHours, template, userManager, dispatcher)
+	}, teamManager, settings, businessHours, template, userManager, dispatcher, webhook)
 	if err != nil {
 		log.Fatalf("error initializing SLA manager: %v", err)
 	}
@@ -390,9 +413,12 @@ func initSLA(db *sqlx.DB, teamManager *team.Manager, settings *setting.Manager,
 func initCSAT(db *sqlx.DB, i18n *i18n.I18n) *csat.Manager {
 	var lo = initLogger("csat")
 	m, err := csat.New(csat.Opts{
-		DB:   db,
-		Lo:   lo,
-		I18n: i18n,
+		DB:          db,
+		Lo:          lo,
+		I18n:        i18n,
+		SigningKey:  ko.MustString("app.encryption_key"),
+		TokenExpiry: cmp.Or(ko.Duration("csat.token_expiry"), 30*24*time.Hour),
+		LatestWins:  ko.String("csat.duplicate_response") == "latest",
 	})
 	if err != nil {
 		log.Fatalf("error initializing CSAT manager: %v", err)
@@ -400,9 +426,35 @@ func initCSAT(db *sqlx.DB, i18n *i18n.I18n) *csat.Manager {
 	return m
 }
 
-// initWS inits websocket hub.
-func initWS(user *user.Manager) *ws.Hub {
-	return ws.NewHub(user)
+// initWS inits websocket hub. If a broker is configured, broadcasts are fanned out to all app instances.
+func initWS(user *user.Manager, rd *redis.Client) *ws.Hub {
+	hub := ws.NewHub(user)
+	switch broker := ko.String("websocket.broker"); broker {
+	case "":
+	case "redis":
+		hub.SetBroker(ws.NewRedisBroker(rd, cmp.Or(ko.String("websocket.channel"), "libredesk:ws")))
+	default:
+		log.Fatalf("unknown websocket broker: %s", broker)
+	}
+	return hub
+}
+
+// initTracing sets up OpenTelemetry tracing if enabled and returns a function that flushes and stops it.
+func initTracing(ctx context.Context) func(context.Context) error {
+	if !ko.Bool("tracing.enabled") {
+		return func(context.Context) error { return nil }
+	}
+	shutdown, err := tracing.Init(ctx, tracing.Opts{
+		Endpoint:       cmp.Or(ko.String("tracing.endpoint"), "localhost:4318"),
+		Insecure:       ko.Bool("tracing.insecure"),
+		ServiceName:    cmp.Or(ko.String("tracing.service_name"), appName),
+		ServiceVersion: versionString,
+		SampleRatio:    ko.Float64("tracing.sample_ratio"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing tracing: %v", err)
+	}
+	return shutdown
 }
 
 // getCustomStaticDir returns the custom static directory path from CLI flag or config.
@@ -467,6 +519,11 @@ func getTmplFuncs(consts *constants, i18n *i18n.I18n) template.FuncMap {
 		"L": func() interface{} {
 			return i18n
 		},
+		// JSON encodes a value, e.g. to embed a string in a JSON automation webhook body.
+		"JSON": func(v any) string {
+			b, _ := json.Marshal(v)
+			return string(b)
+		},
 	}
 }
 
@@ -604,9 +661,10 @@ func initInbox(db *sqlx.DB, i18n *i18n.I18n) *inbox.Manager {
 func initAutomationEngine(db *sqlx.DB, i18n *i18n.I18n) *automation.Engine {
 	var lo = initLogger("automation_engine")
 	engine, err := automation.New(automation.Opts{
-		DB:   db,
-		Lo:   lo,
-		I18n: i18n,
+		DB:                  db,
+		Lo:                  lo,
+		I18n:                i18n,
+		TimeTriggerInterval: cmp.Or(ko.Duration("automation.time_trigger_interval"), time.Hour),
 	})
 	if err != nil {
 		log.Fatalf("error initializing automation engine: %v", err)
@@ -620,7 +678,7 @@ func initAutoAssigner(teamManager *team.Manager, userManager *user.Manager, conv
 	if err != nil {
 		log.Fatalf("error fetching system user: %v", err)
 	}
-	e, err := autoassigner.New(teamManager, conversationManager, systemUser, initLogger("autoassigner"))
+	e, err := autoassigner.New(teamManager, conversationManager, userManager, systemUser, initLogger("autoassigner"))
 	if err != nil {
 		log.Fatalf("error initializing auto assigner: %v", err)
 	}
@@ -628,7 +686,7 @@ func initAutoAssigner(teamManager *team.Manager, userManager *user.Manager, conv
 }
 
 // initNotifier initializes the notifier service with available providers.
-func initNotifier() *notifier.Service {
+func initNotifier(userNotification *notifier.UserNotificationManager) *notifier.Service {
 	smtpCfg := imodels.SMTPConfig{}
 	if err := ko.UnmarshalWithConf("notification.email", &smtpCfg, koanf.UnmarshalConf{Tag: "json"}); err != nil {
 		log.Fatalf("error unmarshalling email notification provider config: %v", err)
@@ -646,9 +704,40 @@ func initNotifier() *notifier.Service {
 		emailNotifier.Name(): emailNotifier,
 	}
 
+	if ko.Bool("notification.push.enabled") {
+		pushNotifier, err := initPushNotifier(userNotification)
+		if err != nil {
+			log.Fatalf("error initializing push notifier: %v", err)
+		}
+		notifierProviders[pushNotifier.Name()] = pushNotifier
+	}
+
 	return notifier.NewService(notifierProviders, ko.MustInt("notification.concurrency"), ko.MustInt("notification.queue_size"), initLogger("notifier"))
 }
 
+// initPushNotifier initializes the APNs/FCM push notification provider.
+func initPushNotifier(userNotification *notifier.UserNotificationManager) (*pushnotifier.Push, error) {
+	opts := pushnotifier.Opts{
+		Lo:             initLogger("push-notifier"),
+		OnInvalidToken: userNotification.DeleteDeviceTokenByToken,
+	}
+	if ko.Exists("notification.push.apns") {
+		var cfg pushnotifier.APNsConfig
+		if err := ko.UnmarshalWithConf("notification.push.apns", &cfg, koanf.UnmarshalConf{Tag: "json"}); err != nil {
+			return nil, err
+		}
+		opts.APNs = &cfg
+	}
+	if ko.Exists("notification.push.fcm") {
+		var cfg pushnotifier.FCMConfig
+		if err := ko.UnmarshalWithConf("notification.push.fcm", &cfg, koanf.UnmarshalConf{Tag: "json"}); err != nil {
+			return nil, err
+		}
+		opts.FCM = &cfg
+	}
+	return pushnotifier.New(opts)
+}
+
 // initEmailInbox loads inbox config from DB and initializes the email inbox.
 func initEmailInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, usrStore inbox.UserStore, mgr *inbox.Manager) (inbox.Inbox, error) {
 	var config imodels.Config
@@ -695,11 +784,19 @@ func initEmailInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, usrS
 		return nil
 	}
 
+	// Raw headers of incoming messages stored for abuse investigations, an empty list disables it.
+	retainHeaders := defaultRetainHeaders
+	if ko.Exists("message.retain_headers") {
+		retainHeaders = ko.Strings("message.retain_headers")
+	}
+
 	inbox, err := email.New(msgStore, usrStore, email.Opts{
 		ID:                   inboxRecord.ID,
 		Config:               config,
 		Lo:                   initLogger("email_inbox"),
 		TokenRefreshCallback: tokenRefreshCallback,
+		RetainHeaders:        retainHeaders,
+		SenderFilter:         mgr,
 	})
 
 	if err != nil {
@@ -712,7 +809,7 @@ func initEmailInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, usrS
 }
 
 // initLiveChatInbox initializes the live chat inbox.
-func initLiveChatInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, usrStore inbox.UserStore, signAvatarURL func(*null.String)) (inbox.Inbox, error) {
+func initLiveChatInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, usrStore inbox.UserStore, signAvatarURL func(*null.String), relay livechat.Relay) (inbox.Inbox, error) {
 	var config livechat.Config
 
 	// Load JSON data into Koanf.
@@ -729,6 +826,7 @@ func initLiveChatInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, u
 		Config:        config,
 		Lo:            initLogger("livechat_inbox"),
 		SignAvatarURL: signAvatarURL,
+		Relay:         relay,
 	})
 
 	if err != nil {
@@ -741,13 +839,13 @@ func initLiveChatInbox(inboxRecord imodels.Inbox, msgStore inbox.MessageStore, u
 }
 
 // makeInboxInitializer creates an inbox initializer function.
-func makeInboxInitializer(mgr *inbox.Manager, signAvatarURL func(*null.String)) func(imodels.Inbox, inbox.MessageStore, inbox.UserStore) (inbox.Inbox, error) {
+func makeInboxInitializer(mgr *inbox.Manager, signAvatarURL func(*null.String), relay livechat.Relay) func(imodels.Inbox, inbox.MessageStore, inbox.UserStore) (inbox.Inbox, error) {
 	return func(inboxR imodels.Inbox, msgStore inbox.MessageStore, usrStore inbox.UserStore) (inbox.Inbox, error) {
 		switch inboxR.Channel {
 		case inbox.ChannelEmail:
 			return initEmailInbox(inboxR, msgStore, usrStore, mgr)
 		case inbox.ChannelLiveChat:
-			return initLiveChatInbox(inboxR, msgStore, usrStore, signAvatarURL)
+			return initLiveChatInbox(inboxR, msgStore, usrStore, signAvatarURL, relay)
 		default:
 			return nil, fmt.Errorf("unknown inbox channel: %s", inboxR.Channel)
 		}
@@ -757,15 +855,15 @@ func makeInboxInitializer(mgr *inbox.Manager, signAvatarURL func(*null.String))
 // reloadInbox reloads a single inbox by ID using the signal-aware context.
 func reloadInbox(app *App, id int) error {
 	app.lo.Info("reloading inbox", "id", id)
-	return app.inbox.ReloadInbox(app.ctx, id, makeInboxInitializer(app.inbox, app.conversation.SignAvatarURL))
+	return app.inbox.ReloadInbox(app.ctx, id, makeInboxInitializer(app.inbox, app.conversation.SignAvatarURL, app.wsHub))
 }
 
 // startInboxes registers the active inboxes and starts receiver for each.
-func startInboxes(ctx context.Context, mgr *inbox.Manager, msgStore inbox.MessageStore, usrStore inbox.UserStore, signAvatarURL func(*null.String)) {
+func startInboxes(ctx context.Context, mgr *inbox.Manager, msgStore inbox.MessageStore, usrStore inbox.UserStore, signAvatarURL func(*null.String), relay livechat.Relay) {
 	mgr.SetMessageStore(msgStore)
 	mgr.SetUserStore(usrStore)
 
-	if err := mgr.InitInboxes(makeInboxInitializer(mgr, signAvatarURL)); err != nil {
+	if err := mgr.InitInboxes(makeInboxInitializer(mgr, signAvatarURL, relay)); err != nil {
 		log.Fatalf("error initializing inboxes: %v", err)
 	}
 
@@ -970,6 +1068,106 @@ func initAI(db *sqlx.DB, i18n *i18n.I18n) *ai.Manager {
 	return m
 }
 
+// initAbuse inits the manager checking incoming messages for abuse.
+func initAbuse(db *sqlx.DB, settings *setting.Manager, ai *ai.Manager) *abuse.Manager {
+	m, err := abuse.New(abuse.Opts{
+		DB:       db,
+		Lo:       initLogger("abuse"),
+		Settings: settings,
+		AI:       ai,
+	})
+	if err != nil {
+		log.Fatalf("error initializing abuse manager: %v", err)
+	}
+	return m
+}
+
+// initTopic inits conversation topic clustering manager.
+func initTopic(db *sqlx.DB, i18n *i18n.I18n, ai *ai.Manager) *topic.Manager {
+	var lo = initLogger("topic")
+	m, err := topic.New(topic.Opts{
+		DB:                  db,
+		Lo:                  lo,
+		I18n:                i18n,
+		AI:                  ai,
+		Window:              cmp.Or(ko.Duration("topics.window"), 7*24*time.Hour),
+		MinClusterSize:      cmp.Or(ko.Int("topics.min_cluster_size"), 5),
+		SimilarityThreshold: cmp.Or(ko.Float64("topics.similarity_threshold"), 0.8),
+	})
+	if err != nil {
+		log.Fatalf("error initializing topic manager: %v", err)
+	}
+	return m
+}
+
+// initContactDigest inits the manager sending digests of open conversations to contacts.
+func initContactDigest(db *sqlx.DB, template *tmpl.Manager, notifier *notifier.Service, maintenance *maintenance.Manager) *contactdigest.Manager {
+	var lo = initLogger("contact-digest")
+	m, err := contactdigest.New(contactdigest.Opts{
+		DB:          db,
+		Lo:          lo,
+		Template:    template,
+		Notifier:    notifier,
+		Maintenance: maintenance,
+		Period:      cmp.Or(ko.Duration("contact_digest.period"), 7*24*time.Hour),
+		LinkURL:     ko.String("contact_digest.conversation_url"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing contact digest manager: %v", err)
+	}
+	return m
+}
+
+// initWatchDigest inits the manager sending agents digests of activity on the conversations they watch.
+func initWatchDigest(db *sqlx.DB, template *tmpl.Manager, notifier *notifier.Service, maintenance *maintenance.Manager) *watchdigest.Manager {
+	var lo = initLogger("watch-digest")
+	m, err := watchdigest.New(watchdigest.Opts{
+		DB:          db,
+		Lo:          lo,
+		Template:    template,
+		Notifier:    notifier,
+		Maintenance: maintenance,
+	})
+	if err != nil {
+		log.Fatalf("error initializing watch digest manager: %v", err)
+	}
+	return m
+}
+
+// initMaintenance inits the manager of maintenance windows.
+func initMaintenance(db *sqlx.DB, i18n *i18n.I18n, dispatcher *notifier.Dispatcher) *maintenance.Manager {
+	m, err := maintenance.New(maintenance.Opts{
+		DB:         db,
+		Lo:         initLogger("maintenance"),
+		I18n:       i18n,
+		Dispatcher: dispatcher,
+	})
+	if err != nil {
+		log.Fatalf("error initializing maintenance manager: %v", err)
+	}
+	return m
+}
+
+// initNPS inits the manager sending periodic NPS surveys to contacts.
+func initNPS(db *sqlx.DB, i18n *i18n.I18n, template *tmpl.Manager, notifier *notifier.Service, settings *setting.Manager) *nps.Manager {
+	var lo = initLogger("nps")
+	m, err := nps.New(nps.Opts{
+		DB:         db,
+		Lo:         lo,
+		I18n:       i18n,
+		Template:   template,
+		Notifier:   notifier,
+		Settings:   settings,
+		SigningKey: ko.MustString("app.encryption_key"),
+		Period:     cmp.Or(ko.Duration("nps.period"), 90*24*time.Hour),
+		BatchSize:  cmp.Or(ko.Int("nps.batch_size"), 200),
+	})
+	if err != nil {
+		log.Fatalf("error initializing NPS manager: %v", err)
+	}
+	return m
+}
+
 // initSearch inits search manager.
 func initSearch(db *sqlx.DB, i18n *i18n.I18n) *search.Manager {
 	lo := initLogger("search")
@@ -1041,6 +1239,78 @@ func initContextLink(db *sqlx.DB, i18n *i18n.I18n) *contextlink.Manager {
 	return m
 }
 
+// initAnnouncement inits announcement manager.
+func initAnnouncement(db *sqlx.DB, i18n *i18n.I18n, wsHub *ws.Hub) *announcement.Manager {
+	var lo = initLogger("announcement")
+	m, err := announcement.New(announcement.Opts{
+		DB:    db,
+		Lo:    lo,
+		I18n:  i18n,
+		WSHub: wsHub,
+	})
+	if err != nil {
+		log.Fatalf("error initializing announcement manager: %v", err)
+	}
+	return m
+}
+
+// initAsset inits asset manager.
+func initAsset(db *sqlx.DB, i18n *i18n.I18n, mediaStore *media.Manager) *asset.Manager {
+	var lo = initLogger("asset")
+	m, err := asset.New(asset.Opts{
+		DB:         db,
+		Lo:         lo,
+		I18n:       i18n,
+		MediaStore: mediaStore,
+	})
+	if err != nil {
+		log.Fatalf("error initializing asset manager: %v", err)
+	}
+	return m
+}
+
+// initAutoresponder inits autoresponder manager.
+func initAutoresponder(db *sqlx.DB, i18n *i18n.I18n, businessHours *businesshours.Manager) *autoresponder.Manager {
+	var lo = initLogger("autoresponder")
+	m, err := autoresponder.New(autoresponder.Opts{
+		DB:                 db,
+		Lo:                 lo,
+		I18n:               i18n,
+		BusinessHoursStore: businessHours,
+	})
+	if err != nil {
+		log.Fatalf("error initializing autoresponder manager: %v", err)
+	}
+	return m
+}
+
+// initVariant inits the manager of A/B variants of automated messages.
+func initVariant(db *sqlx.DB, i18n *i18n.I18n) *variant.Manager {
+	m, err := variant.New(variant.Opts{
+		DB:   db,
+		Lo:   initLogger("variant"),
+		I18n: i18n,
+	})
+	if err != nil {
+		log.Fatalf("error initializing variant manager: %v", err)
+	}
+	return m
+}
+
+// initWebForm inits web form manager.
+func initWebForm(db *sqlx.DB, i18n *i18n.I18n) *webform.Manager {
+	var lo = initLogger("web_form")
+	m, err := webform.New(webform.Opts{
+		DB:   db,
+		Lo:   lo,
+		I18n: i18n,
+	})
+	if err != nil {
+		log.Fatalf("error initializing web form manager: %v", err)
+	}
+	return m
+}
+
 // initWebhook inits webhook manager.
 func initWebhook(db *sqlx.DB, i18n *i18n.I18n) *webhook.Manager {
 	var lo = initLogger("webhook")
@@ -1089,6 +1359,7 @@ func initNotifDispatcher(userNotification *notifier.UserNotificationManager, out
 		Outbound:     outbound,
 		WSHub:        wsHub,
 		EmailEnabled: emailEnabled,
+		PushEnabled:  ko.Bool("notification.push.enabled"),
 		Lo:           initLogger("notification-dispatcher"),
 	})
 }
@@ -1143,6 +1414,7 @@ func initRateLimit(redisClient *redis.Client) *ratelimit.Limiter {
 		{"widget", 100},
 		{"auth", 30},
 		{"public", 100},
+		{"api", 600},
 	}
 
 	for _, d := range defaults {
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// maxIntegrationNameLength is the max length of the integration name recorded on notes.
+const maxIntegrationNameLength = 100
+
+// integrationNoteReq is a private note posted by an external system.
+type integrationNoteReq struct {
+	ReferenceNumber string `json:"reference_number"`
+	OrderNumber     string `json:"order_number"`
+	Content         string `json:"content"`
+	// Integration optionally names the external system, it is recorded on the note.
+	Integration string `json:"integration"`
+}
+
+// handleCreateIntegrationNote posts a private note on a conversation referenced by its reference number
+// or by its `order_number` custom attribute, the attribute key is fixed. The note is attributed to the
+// authenticated user, usually an agent created for the integration with an API key and a role scoped to
+// this permission, so access to the conversation isn't checked. The integration name and the API key used
+// are recorded in the meta of the note.
+func handleCreateIntegrationNote(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = integrationNoteReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	req.ReferenceNumber = strings.TrimSpace(req.ReferenceNumber)
+	req.OrderNumber = strings.TrimSpace(req.OrderNumber)
+	req.Integration = strings.TrimSpace(req.Integration)
+	if strings.TrimSpace(req.Content) == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.empty", "name", "`content`"), nil, envelope.InputError)
+	}
+	if req.ReferenceNumber == "" && req.OrderNumber == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.required", "name", "`reference_number` or `order_number`"), nil, envelope.InputError)
+	}
+	if len(req.Integration) > maxIntegrationNameLength {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.maxLength", "name", "`integration`", "max", strconv.Itoa(maxIntegrationNameLength)), nil, envelope.InputError)
+	}
+
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var uuid string
+	if req.ReferenceNumber != "" {
+		conv, err := app.conversation.GetConversation(0, "", req.ReferenceNumber)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		uuid = conv.UUID
+	} else {
+		if uuid, err = app.conversation.GetConversationUUIDByOrderNumber(req.OrderNumber); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
+	source := map[string]any{
+		"name":        req.Integration,
+		"auth_method": r.RequestCtx.UserValue("auth_method"),
+	}
+	if r.RequestCtx.UserValue("auth_method") == "api_key" {
+		source["api_key"] = user.APIKey.String
+	}
+	message, err := app.conversation.SendIntegrationNote(user.ID, uuid, req.Content, map[string]any{"integration": source})
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(message)
+}
@@ -11,6 +11,8 @@ import (
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// TOTPCode is a code from an authenticator app or a recovery code, required when two-factor authentication is enabled.
+	TOTPCode string `json:"totp_code"`
 }
 
 // handleLogin logs in the user and returns the user.
@@ -41,11 +43,22 @@ func handleLogin(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("user.accountDisabled"), nil))
 	}
 
+	// Verify the second factor, the client prompts for a code when `two_factor_required` is returned.
+	if user.TOTPEnabled {
+		if loginReq.TOTPCode == "" {
+			return r.SendErrorEnvelope(fasthttp.StatusUnauthorized, app.i18n.T("user.twoFactorCodeRequired"), map[string]bool{"two_factor_required": true}, envelope.InputError)
+		}
+		if err := app.user.VerifyTOTP(user.ID, loginReq.TOTPCode); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
 	if err := app.auth.SaveSession(amodels.User{
-		ID:        user.ID,
-		Email:     user.Email.String,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
+		ID:            user.ID,
+		Email:         user.Email.String,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		PasswordLogin: true,
 	}, r); err != nil {
 		app.lo.Error("error saving session", "error", err)
 		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
@@ -16,19 +16,25 @@ import (
 
 	activitylog "github.com/abhinavxd/libredesk/internal/activity_log"
 	"github.com/abhinavxd/libredesk/internal/ai"
+	"github.com/abhinavxd/libredesk/internal/announcement"
+	"github.com/abhinavxd/libredesk/internal/asset"
 	auth_ "github.com/abhinavxd/libredesk/internal/auth"
 	"github.com/abhinavxd/libredesk/internal/authz"
+	"github.com/abhinavxd/libredesk/internal/autoresponder"
 	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
 	"github.com/abhinavxd/libredesk/internal/colorlog"
 	"github.com/abhinavxd/libredesk/internal/csat"
 	customAttribute "github.com/abhinavxd/libredesk/internal/custom_attribute"
 	"github.com/abhinavxd/libredesk/internal/macro"
+	"github.com/abhinavxd/libredesk/internal/maintenance"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/nps"
 	"github.com/abhinavxd/libredesk/internal/report"
 	"github.com/abhinavxd/libredesk/internal/search"
 	"github.com/abhinavxd/libredesk/internal/sla"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/abhinavxd/libredesk/internal/view"
+	"github.com/abhinavxd/libredesk/internal/webform"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/abhinavxd/libredesk/internal/automation"
@@ -46,8 +52,12 @@ import (
 	"github.com/abhinavxd/libredesk/internal/tag"
 	"github.com/abhinavxd/libredesk/internal/team"
 	"github.com/abhinavxd/libredesk/internal/template"
+	"github.com/abhinavxd/libredesk/internal/topic"
 	"github.com/abhinavxd/libredesk/internal/user"
+	"github.com/abhinavxd/libredesk/internal/variant"
 	"github.com/abhinavxd/libredesk/internal/webhook"
+	"github.com/abhinavxd/libredesk/internal/ws"
+	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
 	"github.com/knadh/koanf/v2"
 	"github.com/knadh/stuffbin"
@@ -108,8 +118,18 @@ type App struct {
 	report           *report.Manager
 	webhook          *webhook.Manager
 	contextLink      *contextlink.Manager
+	announcement     *announcement.Manager
+	asset            *asset.Manager
+	autoresponder    *autoresponder.Manager
+	variant          *variant.Manager
+	maintenance      *maintenance.Manager
+	webform          *webform.Manager
+	topic            *topic.Manager
+	nps              *nps.Manager
 	rateLimit        *ratelimit.Limiter
+	db               *sqlx.DB
 	redis            *redis.Client
+	wsHub            *ws.Hub
 	importer         *importer.Importer
 
 	// Global state that stores data on an available app update.
@@ -178,6 +198,16 @@ func main() {
 
 	checkPendingUpgrade(db)
 
+	if n := ko.Int("generate-test-data"); n > 0 {
+		generateTestData(db, n, ko.Int("test-data-max-messages"), !ko.Bool("yes"))
+		os.Exit(0)
+	}
+
+	if ko.Bool("partition-messages") {
+		partitionMessages(db, !ko.Bool("yes"))
+		os.Exit(0)
+	}
+
 	// Load app settings from DB into the Koanf instance.
 	settings := initSettings(db)
 	loadSettings(settings)
@@ -198,12 +228,17 @@ func main() {
 		autoAssignInterval          = ko.MustDuration("autoassigner.autoassign_interval")
 		unsnoozeInterval            = ko.MustDuration("conversation.unsnooze_interval")
 		draftRetentionDuration      = cmp.Or(ko.Duration("conversation.draft_retention_duration"), 360*time.Hour)
+		headerRetentionDuration     = cmp.Or(ko.Duration("message.header_retention"), 2160*time.Hour)
+		deliveryConfirmDuration     = cmp.Or(ko.Duration("message.delivery_confirm_after"), 24*time.Hour)
+		trashRetentionDuration      = cmp.Or(ko.Duration("conversation.trash_retention_period"), 720*time.Hour)
+		archiveAfterDuration        = ko.Duration("conversation.archive_after")
 		automationWorkers           = ko.MustInt("automation.worker_count")
 		messageOutgoingQWorkers     = ko.MustDuration("message.outgoing_queue_workers")
 		messageIncomingQWorkers     = ko.MustDuration("message.incoming_queue_workers")
 		messageOutgoingScanInterval = ko.MustDuration(msgOutgoingScanIntervalKey)
 		slaEvaluationInterval       = ko.MustDuration("sla.evaluation_interval")
 		lo                          = initLogger(appName)
+		shutdownTracing             = initTracing(ctx)
 		rdb                         = initRedis()
 		constants                   = initConstants()
 		i18n                        = initI18n(fs)
@@ -219,23 +254,39 @@ func main() {
 		businessHours               = initBusinessHours(db, i18n)
 		webhook                     = initWebhook(db, i18n)
 		user                        = initUser(i18n, db)
-		wsHub                       = initWS(user)
-		notifier                    = initNotifier()
+		wsHub                       = initWS(user, rdb)
 		userNotification            = initUserNotification(db, i18n)
+		notifier                    = initNotifier(userNotification)
 		notifDispatcher             = initNotifDispatcher(userNotification, notifier, wsHub, ko.Bool("notification.email.enabled"))
 		automation                  = initAutomationEngine(db, i18n)
-		sla                         = initSLA(db, team, settings, businessHours, template, user, i18n, notifDispatcher)
-		conversation                = initConversations(i18n, sla, status, priority, wsHub, db, inbox, user, team, media, settings, csat, automation, template, webhook, notifDispatcher)
+		sla                         = initSLA(db, team, settings, businessHours, template, user, i18n, notifDispatcher, webhook)
+		autoresponder               = initAutoresponder(db, i18n, businessHours)
+		webForm                     = initWebForm(db, i18n)
+		conversation                = initConversations(i18n, sla, status, priority, wsHub, db, inbox, user, team, media, settings, csat, automation, template, webhook, autoresponder, notifDispatcher)
 		autoassigner                = initAutoAssigner(team, user, conversation)
 		rateLimiter                 = initRateLimit(rdb)
+		announcement                = initAnnouncement(db, i18n, wsHub)
+		ai                          = initAI(db, i18n)
+		topic                       = initTopic(db, i18n, ai)
+		abuse                       = initAbuse(db, settings, ai)
+		variant                     = initVariant(db, i18n)
+		maintenance                 = initMaintenance(db, i18n, notifDispatcher)
+		report                      = initReport(db, i18n)
+		contactDigest               = initContactDigest(db, template, notifier, maintenance)
+		watchDigest                 = initWatchDigest(db, template, notifier, maintenance)
+		nps                         = initNPS(db, i18n, template, notifier, settings)
 	)
 
 	wsHub.SetConversationStore(conversation)
 	automation.SetConversationStore(conversation)
+	conversation.SetAbuseStore(abuse)
+	conversation.SetVariantStore(variant)
+	notifDispatcher.SetHolder(maintenance)
 
 	// Start inboxes.
-	startInboxes(ctx, inbox, conversation, user, conversation.SignAvatarURL)
+	startInboxes(ctx, inbox, conversation, user, conversation.SignAvatarURL, wsHub)
 
+	go wsHub.Run(ctx)
 	go automation.Run(ctx, automationWorkers)
 	go autoassigner.Run(ctx, autoAssignInterval)
 	go conversation.Run(ctx, messageIncomingQWorkers, messageOutgoingQWorkers, messageOutgoingScanInterval)
@@ -248,7 +299,27 @@ func main() {
 	go media.DeleteUnlinkedMedia(ctx)
 	go user.MonitorUserAvailability(ctx, onUsersOffline(conversation))
 	go conversation.RunDraftCleaner(ctx, draftRetentionDuration)
+	go conversation.RunMessageHeaderCleaner(ctx, headerRetentionDuration)
+	go conversation.RunDeliveryConfirmer(ctx, deliveryConfirmDuration)
+	go conversation.RunTrashPurger(ctx, trashRetentionDuration)
+	go conversation.RunMessagePartitioner(ctx, messagePartitionsAhead)
+	go conversation.RunArchiver(ctx, archiveAfterDuration)
 	go userNotification.RunNotificationCleaner(ctx)
+	go announcement.Run(ctx, time.Minute)
+	go maintenance.Run(ctx, time.Minute)
+	go report.RunWaitingTimeRollup(ctx, 15*time.Minute)
+	if ko.Bool("contact_digest.enabled") {
+		go contactDigest.Run(ctx, cmp.Or(ko.Duration("contact_digest.interval"), time.Hour))
+	}
+	if ko.Bool("watch_digest.enabled") {
+		go watchDigest.Run(ctx, cmp.Or(ko.Duration("watch_digest.interval"), 10*time.Minute))
+	}
+	if ko.Bool("nps.enabled") {
+		go nps.Run(ctx, cmp.Or(ko.Duration("nps.interval"), time.Hour))
+	}
+	if ko.Bool("topics.enabled") {
+		go topic.Run(ctx, cmp.Or(ko.Duration("topics.interval"), 6*time.Hour))
+	}
 
 	var app = &App{
 		ctx:              ctx,
@@ -276,24 +347,36 @@ func main() {
 		customAttribute:  initCustomAttribute(db, i18n),
 		authz:            initAuthz(i18n),
 		view:             initView(db, i18n),
-		report:           initReport(db, i18n),
+		report:           report,
 		search:           initSearch(db, i18n),
 		role:             initRole(db, i18n),
 		tag:              initTag(db, i18n),
 		macro:            initMacro(db, i18n),
-		ai:               initAI(db, i18n),
+		ai:               ai,
 		importer:         initImporter(i18n),
 		webhook:          webhook,
 		contextLink:      initContextLink(db, i18n),
+		announcement:     announcement,
+		asset:            initAsset(db, i18n, media),
+		autoresponder:    autoresponder,
+		variant:          variant,
+		maintenance:      maintenance,
+		webform:          webForm,
+		topic:            topic,
+		nps:              nps,
 		rateLimit:        rateLimiter,
+		db:               db,
 		redis:            rdb,
+		wsHub:            wsHub,
 		userNotification: userNotification,
 	}
 	app.consts.Store(constants)
 
+	wsHub.SetViewStore(viewSubscriptions{app: app})
+
 	g := fastglue.NewGlue()
 	g.SetContext(app)
-	initHandlers(g, wsHub)
+	initHandlers(g, app, wsHub)
 
 	s := &fasthttp.Server{
 		Name:                 appName,
@@ -339,6 +422,10 @@ func main() {
 	sla.Close()
 	colorlog.Red("Shutting down importer...")
 	app.importer.Close()
+	colorlog.Red("Shutting down tracing...")
+	if err := shutdownTracing(context.Background()); err != nil {
+		lo.Error("error shutting down tracing", "error", err)
+	}
 	colorlog.Red("Shutting down database...")
 	db.Close()
 	colorlog.Red("Shutting down redis...")
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/maintenance/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+const maxMaintenanceWindowNameLength = 140
+
+// handleGetMaintenanceWindows returns all maintenance windows.
+func handleGetMaintenanceWindows(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	windows, err := app.maintenance.GetAll()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(windows)
+}
+
+// handleGetMaintenanceWindow returns a maintenance window by ID.
+func handleGetMaintenanceWindow(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	window, err := app.maintenance.Get(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(window)
+}
+
+// handleCreateMaintenanceWindow creates a maintenance window.
+func handleCreateMaintenanceWindow(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		window = models.Window{}
+	)
+	if err := r.Decode(&window, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateMaintenanceWindow(app, &window); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	result, err := app.maintenance.Create(window)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateMaintenanceWindow updates a maintenance window.
+func handleUpdateMaintenanceWindow(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		window = models.Window{}
+		id, _  = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&window, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateMaintenanceWindow(app, &window); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	result, err := app.maintenance.Update(id, window)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteMaintenanceWindow deletes a maintenance window.
+func handleDeleteMaintenanceWindow(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.maintenance.Delete(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+func validateMaintenanceWindow(app *App, w *models.Window) error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if len(w.Name) > maxMaintenanceWindowNameLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxMaintenanceWindowNameLength)), nil)
+	}
+	if w.StartsAt.IsZero() || !w.EndsAt.After(w.StartsAt) {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	return nil
+}
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"encoding/json"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	authzModels "github.com/abhinavxd/libredesk/internal/authz/models"
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
 
+// maxReactionRunes is the maximum length of a reaction emoji in runes.
+const maxReactionRunes = 16
+
+type messageReactionReq struct {
+	Emoji string `json:"emoji"`
+}
+
 type messageReq struct {
 	Attachments []int                  `json:"attachments"`
 	Message     string                 `json:"message"`
@@ -22,6 +34,7 @@ type messageReq struct {
 	SenderType  string                 `json:"sender_type"`
 	Mentions    []cmodels.MentionInput `json:"mentions"`
 	EchoID      string                 `json:"echo_id"`
+	ThreadID    int                    `json:"thread_id"`
 }
 
 // handleGetMessages returns messages for a conversation.
@@ -41,6 +54,9 @@ func handleGetMessages(r *fastglue.Request) error {
 		private = &p
 	}
 
+	// Notes of an internal thread are listed separately from the main timeline.
+	threadID, _ := strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("thread_id")))
+
 	// Parse repeated type params: ?type=incoming&type=outgoing
 	var msgTypes []string
 	for _, v := range r.RequestCtx.QueryArgs().PeekMulti("type") {
@@ -58,7 +74,7 @@ func handleGetMessages(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	messages, pageSize, err := app.conversation.GetConversationMessages(uuid, page, pageSize, private, msgTypes)
+	messages, pageSize, err := app.conversation.GetConversationMessages(uuid, page, pageSize, private, msgTypes, threadID)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -139,6 +155,160 @@ func handleGetMessage(r *fastglue.Request) error {
 	return r.SendEnvelope(message)
 }
 
+// handlePinMessage pins a message or note to the top of its conversation.
+func handlePinMessage(r *fastglue.Request) error {
+	return togglePinnedMessage(r, true)
+}
+
+// handleUnpinMessage unpins a message of a conversation.
+func handleUnpinMessage(r *fastglue.Request) error {
+	return togglePinnedMessage(r, false)
+}
+
+// togglePinnedMessage pins or unpins a message and returns the conversation's pinned messages.
+func togglePinnedMessage(r *fastglue.Request, pin bool) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conv, err := enforceConversationAccess(app, cuuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	message, err := app.conversation.GetMessage(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if message.ConversationUUID != cuuid {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.NotFoundError, app.i18n.T("globals.messages.notFound"), nil))
+	}
+	if message.Type == cmodels.MessageActivity {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.badRequest"), nil, envelope.InputError)
+	}
+
+	var pinned []cmodels.PinnedMessage
+	if pin {
+		pinned, err = app.conversation.PinMessage(cuuid, conv.ID, uuid, user.ID)
+	} else {
+		pinned, err = app.conversation.UnpinMessage(cuuid, conv.ID, uuid)
+	}
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(pinned)
+}
+
+// handleAddMessageReaction adds the current user's emoji reaction to a private note.
+func handleAddMessageReaction(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = messageReactionReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	return toggleMessageReaction(r, req.Emoji, true)
+}
+
+// handleRemoveMessageReaction removes the current user's emoji reaction from a private note.
+func handleRemoveMessageReaction(r *fastglue.Request) error {
+	return toggleMessageReaction(r, string(r.RequestCtx.QueryArgs().Peek("emoji")), false)
+}
+
+// toggleMessageReaction adds or removes a reaction on a private note and returns the note's reactions.
+func toggleMessageReaction(r *fastglue.Request, emoji string, add bool) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	if !isValidReactionEmoji(emoji) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	message, err := app.conversation.GetMessage(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if message.ConversationUUID != cuuid {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.NotFoundError, app.i18n.T("globals.messages.notFound"), nil))
+	}
+	if !message.Private || message.Type == cmodels.MessageActivity {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("conversation.reactionsOnlyOnNotes"), nil, envelope.InputError)
+	}
+
+	var reactions json.RawMessage
+	if add {
+		reactions, err = app.conversation.AddMessageReaction(cuuid, uuid, user.ID, emoji)
+	} else {
+		reactions, err = app.conversation.RemoveMessageReaction(cuuid, uuid, user.ID, emoji)
+	}
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(reactions)
+}
+
+// isValidReactionEmoji reports whether s looks like a single emoji: a short run of non-ASCII,
+// non-space runes. Emoji sequences joined with ZWJ or modifiers span several runes.
+func isValidReactionEmoji(s string) bool {
+	if s == "" || utf8.RuneCountInString(s) > maxReactionRunes {
+		return false
+	}
+	for _, r := range s {
+		if r < utf8.RuneSelf || unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleGetMessageHeaders returns the retained raw email headers of an incoming message.
+func handleGetMessageHeaders(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Check permission
+	if _, err = enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	message, err := app.conversation.GetMessage(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if message.ConversationUUID != cuuid {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.NotFoundError, app.i18n.T("globals.messages.notFound"), nil))
+	}
+
+	headers, err := app.conversation.GetMessageHeaders(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(headers)
+}
+
 // handleRetryMessage changes message status to `pending`, so it's enqueued for sending.
 func handleRetryMessage(r *fastglue.Request) error {
 	var (
@@ -217,6 +387,23 @@ func handleSendMessage(r *fastglue.Request) error {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.badRequest"), nil, envelope.InputError)
 	}
 
+	// Only private notes can be posted to an open internal thread of this conversation.
+	if req.ThreadID > 0 {
+		if !req.Private {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.badRequest"), nil, envelope.InputError)
+		}
+		thread, err := app.conversation.GetThread(req.ThreadID)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		if thread.ConversationID != conv.ID {
+			return r.SendErrorEnvelope(fasthttp.StatusNotFound, app.i18n.T("globals.messages.notFound"), nil, envelope.NotFoundError)
+		}
+		if thread.ResolvedAt.Valid {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("conversation.thread.resolved"), nil, envelope.InputError)
+		}
+	}
+
 	// Check if user has permission to send messages as contact
 	if req.SenderType == umodels.UserTypeContact {
 		parts := strings.Split(authzModels.PermMessagesWriteAsContact, ":")
@@ -234,6 +421,19 @@ func handleSendMessage(r *fastglue.Request) error {
 		}
 	}
 
+	// Private notes only need messages:write_private, anything the contact sees needs messages:write.
+	if !req.Private {
+		parts := strings.Split(authzModels.PermMessagesWrite, ":")
+		ok, err := app.authz.Enforce(user, parts[0], parts[1])
+		if err != nil {
+			app.lo.Error("error checking permission", "error", err)
+			return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+		}
+		if !ok {
+			return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("status.deniedPermission"), nil, envelope.PermissionError)
+		}
+	}
+
 	// Get media for all attachments, skip any already associated with a model.
 	media, err := getUnassociatedMedia(app, req.Attachments)
 	if err != nil {
@@ -251,7 +451,7 @@ func handleSendMessage(r *fastglue.Request) error {
 
 	// Send private note.
 	if req.Private {
-		message, err := app.conversation.SendPrivateNote(media, user.ID, cuuid, req.Message, req.Mentions)
+		message, err := app.conversation.SendPrivateNote(media, user.ID, cuuid, req.Message, req.Mentions, req.ThreadID)
 		if err != nil {
 			return sendErrorEnvelope(r, err)
 		}
@@ -282,3 +482,26 @@ func resolveContentCIDs(msg *cmodels.Message, rootURL string) {
 		msg.Content = strings.ReplaceAll(msg.Content, `src='/uploads/`, `src='`+rootURL+`/uploads/`)
 	}
 }
+
+// trackingPixel is a transparent 1x1 GIF.
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// handleTrackEmailOpen serves the open tracking pixel of an outgoing email and records the open.
+// The pixel is served for unknown messages too.
+func handleTrackEmailOpen(r *fastglue.Request) error {
+	var (
+		app         = r.Context.(*App)
+		messageUUID = r.RequestCtx.UserValue("uuid").(string)
+	)
+	if _, err := uuid.Parse(messageUUID); err == nil {
+		app.conversation.RecordMessageOpen(messageUUID)
+	}
+	r.RequestCtx.Response.Header.Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	r.RequestCtx.SetContentType("image/gif")
+	r.RequestCtx.SetBody(trackingPixel)
+	return nil
+}
@@ -66,9 +66,30 @@ func authenticateUser(r *fastglue.Request, app *App) (models.User, error) {
 	}
 
 	r.RequestCtx.SetUserValue("auth_method", "session")
+	r.RequestCtx.SetUserValue("password_login", sessUser.PasswordLogin)
 	return user, nil
 }
 
+// twoFactorSetupPending reports whether the user logged in with a password without having enrolled
+// in two-factor authentication required by their roles.
+func twoFactorSetupPending(r *fastglue.Request, user models.User) bool {
+	passwordLogin, _ := r.RequestCtx.UserValue("password_login").(bool)
+	return passwordLogin && user.TwoFactorRequired && !user.TOTPEnabled
+}
+
+// twoFactorSetupPaths are the only endpoints a user with pending two-factor enrollment can access.
+var twoFactorSetupPaths = map[string]bool{
+	"GET /api/v1/agents/me":              true,
+	"POST /api/v1/agents/me/totp/setup":  true,
+	"POST /api/v1/agents/me/totp/enable": true,
+	"GET /logout":                        true,
+}
+
+// twoFactorSetupAllowed reports whether the request is allowed while two-factor enrollment is pending.
+func twoFactorSetupAllowed(r *fastglue.Request) bool {
+	return twoFactorSetupPaths[string(r.RequestCtx.Method())+" "+string(r.RequestCtx.Path())]
+}
+
 // tryAuth attempts to authenticate the user and add them to the context but doesn't enforce authentication.
 // Handlers can check if user exists in context optionally.
 // Supports both API key authentication (Authorization header) and session-based authentication.
@@ -113,6 +134,15 @@ func auth(handler fastglue.FastRequestHandler) fastglue.FastRequestHandler {
 			return sendErrorEnvelope(r, err)
 		}
 
+		if err := rateLimitUser(r, app, user.ID); err != nil {
+			return err
+		}
+
+		// Only the enrollment endpoints are allowed until two-factor authentication is set up.
+		if twoFactorSetupPending(r, user) && !twoFactorSetupAllowed(r) {
+			return r.SendErrorEnvelope(http.StatusForbidden, app.i18n.T("user.twoFactorSetupRequired"), nil, envelope.PermissionError)
+		}
+
 		// Set user in the request context.
 		r.RequestCtx.SetUserValue("user", amodels.User{
 			ID:        user.ID,
@@ -143,6 +173,15 @@ func perm(handler fastglue.FastRequestHandler, perm string) fastglue.FastRequest
 			return sendErrorEnvelope(r, err)
 		}
 
+		if err := rateLimitUser(r, app, user.ID); err != nil {
+			return err
+		}
+
+		// No permissioned endpoint is allowed until two-factor authentication is set up.
+		if twoFactorSetupPending(r, user) {
+			return r.SendErrorEnvelope(http.StatusForbidden, app.i18n.T("user.twoFactorSetupRequired"), nil, envelope.PermissionError)
+		}
+
 		// Split the permission string into object and action and enforce it.
 		parts := strings.Split(perm, ":")
 		if len(parts) != 2 {
@@ -237,6 +276,16 @@ func rateLimit(handler fastglue.FastRequestHandler, ruleName string) fastglue.Fa
 	}
 }
 
+// rateLimitUser applies the "api" rate limit rule to an authenticated user, counting requests made
+// with an API key separately from session requests.
+func rateLimitUser(r *fastglue.Request, app *App, userID int) error {
+	subject := "user:" + strconv.Itoa(userID)
+	if r.RequestCtx.UserValue("auth_method") == "api_key" {
+		subject = "api_key:" + strconv.Itoa(userID)
+	}
+	return app.rateLimit.CheckKey(r.RequestCtx, "api", subject)
+}
+
 // authOrSignedURL allows access if user is authenticated OR if URL has valid signature.
 // Used for media endpoints that support both access methods.
 func authOrSignedURL(handler fastglue.FastRequestHandler) fastglue.FastRequestHandler {
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/captcha"
+	csatModels "github.com/abhinavxd/libredesk/internal/csat/models"
+	"github.com/abhinavxd/libredesk/internal/nps"
+	"github.com/zerodha/fastglue"
+)
+
+// handleShowNPS renders the NPS survey page for a given survey token, on the CSAT survey page layout.
+func handleShowNPS(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		token     = r.RequestCtx.UserValue("token").(string)
+		uuid, err = app.nps.ParseToken(token)
+	)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
+	survey, err := app.nps.Get(uuid)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
+
+	lang := getCSATI18n(app, r, "")
+	if survey.RespondedAt.Valid {
+		return renderCSATThankYou(app, r, lang)
+	}
+
+	return app.tmpl.RenderWebPage(r.RequestCtx, "csat", map[string]interface{}{
+		"Data": map[string]interface{}{
+			"Title": lang.T("csat.pageTitle"),
+			"L":     lang,
+			"CSAT": map[string]interface{}{
+				"Action":   "/nps/" + token,
+				"Scale":    csatModels.ScaleNPS,
+				"Ratings":  csatRatings(lang, csatModels.ScaleNPS),
+				"Question": lang.T("csat.npsQuestion"),
+			},
+			"Captcha": getCaptchaWidget(app, captcha.EndpointCSAT),
+		},
+	})
+}
+
+// handleSubmitNPS records the response to an NPS survey. A rating is required, the comment is optional.
+func handleSubmitNPS(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		uuid, err = app.nps.ParseToken(r.RequestCtx.UserValue("token").(string))
+	)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
+	if err := app.rateLimit.CheckKey(r.RequestCtx, "public", "nps:"+uuid); err != nil {
+		return err
+	}
+	lang := getCSATI18n(app, r, "")
+
+	// Bots fill every input, drop the response silently.
+	if len(r.RequestCtx.FormValue(honeypotField)) > 0 {
+		app.lo.Info("dropping NPS response with filled honeypot", "uuid", uuid)
+		return renderCSATThankYou(app, r, lang)
+	}
+	if !verifyCaptcha(app, r, captcha.EndpointCSAT) {
+		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+			"Data": map[string]interface{}{
+				"ErrorMessage": lang.T("validation.captchaFailed"),
+			},
+		})
+	}
+
+	rating, err := strconv.Atoi(string(r.RequestCtx.FormValue("rating")))
+	if err != nil || rating < 0 || rating > nps.MaxRating {
+		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+			"Data": map[string]interface{}{
+				"ErrorMessage": lang.Ts("globals.messages.pleaseSelect", "name", "rating"),
+			},
+		})
+	}
+	comment := strings.TrimSpace(string(r.RequestCtx.FormValue("feedback")))
+	if len(comment) > maxCsatFeedbackLength {
+		comment = comment[:maxCsatFeedbackLength]
+	}
+
+	if err := app.nps.Respond(uuid, rating, comment); err != nil {
+		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+			"Data": map[string]interface{}{
+				"ErrorMessage": err.Error(),
+			},
+		})
+	}
+	return renderCSATThankYou(app, r, lang)
+}
+
+// handleNPSUnsubscribe opts the contact of an NPS survey out of all surveys.
+func handleNPSUnsubscribe(r *fastglue.Request) error {
+	var (
+		app       = r.Context.(*App)
+		uuid, err = app.nps.ParseToken(r.RequestCtx.UserValue("token").(string))
+	)
+	if err != nil {
+		return renderCSATInvalidLink(app, r)
+	}
+	if err := app.nps.Unsubscribe(uuid); err != nil {
+		return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+			"Data": map[string]interface{}{
+				"ErrorMessage": err.Error(),
+			},
+		})
+	}
+	lang := getCSATI18n(app, r, "")
+	return app.tmpl.RenderWebPage(r.RequestCtx, "info", map[string]interface{}{
+		"Data": map[string]interface{}{
+			"Title":   lang.T("nps.unsubscribed"),
+			"Message": lang.T("nps.unsubscribedMessage"),
+		},
+	})
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/colorlog"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// messagePartitionsAhead is the number of upcoming monthly partitions of the messages table kept created.
+	messagePartitionsAhead = 3
+
+	// legacyMessagesTable holds the messages that existed when the table was partitioned.
+	legacyMessagesTable = "conversation_messages_legacy"
+)
+
+// messageRef is a foreign key referencing conversation_messages(id).
+type messageRef struct {
+	Name       string `db:"name"`
+	Table      string `db:"table_name"`
+	Column     string `db:"column_name"`
+	Columns    int    `db:"columns"`
+	DeleteType string `db:"delete_type"`
+	UpdateType string `db:"update_type"`
+}
+
+// messageTableDef is the name and definition of a constraint or an index of conversation_messages.
+type messageTableDef struct {
+	Name string `db:"name"`
+	Def  string `db:"def"`
+}
+
+// partitionMessages converts conversation_messages into a table partitioned by month on created_at.
+//
+// Existing rows are not copied, the old table is attached as a partition holding everything before the
+// start of the next month and new messages go into monthly partitions from then on, or the default partition
+// for months without one. Partitioned tables can't be referenced by foreign keys on id alone, so the foreign
+// keys of other tables that reference messages are registered in conversation_message_refs and enforced by
+// triggers, see reference_conversation_messages() in schema.sql.
+func partitionMessages(db *sqlx.DB, prompt bool) {
+	var partitioned bool
+	if err := db.Get(&partitioned, `SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'conversation_messages'::regclass)`); err != nil {
+		log.Fatalf("error checking messages table: %v", err)
+	}
+	if partitioned {
+		log.Println("messages table is already partitioned")
+		return
+	}
+
+	colorlog.Red(fmt.Sprintf("WARNING: This will partition the messages table of the database - '%s'. Stop all app instances and take a backup of the database before continuing.", ko.String("db.database")))
+	if prompt {
+		log.Print("Continue (y/n)? ")
+		var ok string
+		fmt.Scanf("%s", &ok)
+		if !strings.EqualFold(ok, "y") {
+			log.Fatalf("partitioning cancelled")
+		}
+	}
+
+	start := time.Now()
+	if err := convertMessagesTable(db); err != nil {
+		log.Fatalf("error partitioning messages table: %v", err)
+	}
+	log.Printf("messages table partitioned in %s", time.Since(start).Round(time.Second))
+}
+
+// convertMessagesTable partitions conversation_messages in a single transaction.
+func convertMessagesTable(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE conversation_messages IN ACCESS EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+
+	// Messages before the start of the next month stay in the legacy partition.
+	var cutover time.Time
+	if err := tx.Get(&cutover, `SELECT (date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '1 month') AT TIME ZONE 'UTC'`); err != nil {
+		return err
+	}
+	bound := pq.QuoteLiteral(cutover.UTC().Format(time.RFC3339))
+
+	var (
+		refs    []messageRef
+		fkeys   []messageTableDef
+		keys    []messageTableDef
+		indexes []messageTableDef
+		seq     string
+	)
+	if err := tx.Select(&refs, `
+		SELECT c.conname AS name, t.relname AS table_name, a.attname AS column_name, array_length(c.conkey, 1) AS columns,
+			c.confdeltype AS delete_type, c.confupdtype AS update_type
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		WHERE c.contype = 'f' AND c.confrelid = 'conversation_messages'::regclass
+		ORDER BY c.conname`); err != nil {
+		return err
+	}
+	for _, r := range refs {
+		if r.Columns != 1 || !strings.Contains("cnar", r.DeleteType) || !strings.Contains("cnar", r.UpdateType) {
+			return fmt.Errorf("foreign key %s of %s can't be replaced, only single column keys with CASCADE, SET NULL, RESTRICT or NO ACTION actions are supported", r.Name, r.Table)
+		}
+	}
+	if err := tx.Select(&fkeys, `
+		SELECT conname AS name, pg_get_constraintdef(oid) AS def
+		FROM pg_constraint
+		WHERE contype = 'f' AND conrelid = 'conversation_messages'::regclass
+		ORDER BY conname`); err != nil {
+		return err
+	}
+	if err := tx.Select(&keys, `
+		SELECT conname AS name, pg_get_constraintdef(oid) AS def
+		FROM pg_constraint
+		WHERE contype IN ('p', 'u') AND conrelid = 'conversation_messages'::regclass
+		ORDER BY conname`); err != nil {
+		return err
+	}
+	if err := tx.Select(&indexes, `
+		SELECT c.relname AS name, pg_get_indexdef(i.indexrelid) AS def
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE i.indrelid = 'conversation_messages'::regclass AND NOT i.indisunique
+		ORDER BY c.relname`); err != nil {
+		return err
+	}
+	if err := tx.Get(&seq, `SELECT pg_get_serial_sequence('conversation_messages', 'id')`); err != nil {
+		return err
+	}
+
+	var stmts []string
+
+	// Foreign keys referencing messages are registered again once the table is partitioned.
+	for _, r := range refs {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT %s`, pq.QuoteIdentifier(r.Table), pq.QuoteIdentifier(r.Name)))
+	}
+
+	// Unique keys of a partitioned table must include the partition key.
+	for _, k := range keys {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE conversation_messages DROP CONSTRAINT %s`, pq.QuoteIdentifier(k.Name)))
+	}
+
+	// The check lets the column be set NOT NULL and the table be attached without another scan.
+	stmts = append(stmts,
+		`UPDATE conversation_messages SET created_at = COALESCE(updated_at, to_timestamp(0)) WHERE created_at IS NULL`,
+		fmt.Sprintf(`ALTER TABLE conversation_messages ADD CONSTRAINT constraint_conversation_messages_legacy_bound CHECK (created_at IS NOT NULL AND created_at < %s)`, bound),
+		`ALTER TABLE conversation_messages ALTER COLUMN created_at SET NOT NULL`,
+		fmt.Sprintf(`ALTER TABLE conversation_messages RENAME TO %s`, legacyMessagesTable),
+	)
+
+	// Free the index names for the indexes of the partitioned table, which adopt the renamed ones.
+	for n, idx := range indexes {
+		stmts = append(stmts, fmt.Sprintf(`ALTER INDEX %s RENAME TO %s`, pq.QuoteIdentifier(idx.Name), pq.QuoteIdentifier(fmt.Sprintf("%s_index_%d", legacyMessagesTable, n+1))))
+	}
+
+	stmts = append(stmts,
+		fmt.Sprintf(`CREATE TABLE conversation_messages (LIKE %s INCLUDING DEFAULTS INCLUDING STORAGE INCLUDING COMMENTS) PARTITION BY RANGE (created_at)`, legacyMessagesTable),
+		`ALTER TABLE conversation_messages ADD PRIMARY KEY (id, created_at)`,
+		`ALTER TABLE conversation_messages ADD UNIQUE ("uuid", created_at)`,
+	)
+	for _, fk := range fkeys {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE conversation_messages ADD CONSTRAINT %s %s`, pq.QuoteIdentifier(fk.Name), fk.Def))
+	}
+	stmts = append(stmts,
+		fmt.Sprintf(`ALTER SEQUENCE %s OWNED BY conversation_messages.id`, seq),
+		fmt.Sprintf(`ALTER TABLE conversation_messages ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO (%s)`, legacyMessagesTable, bound),
+		fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT constraint_conversation_messages_legacy_bound`, legacyMessagesTable),
+	)
+	for _, idx := range indexes {
+		stmts = append(stmts, idx.Def)
+	}
+	stmts = append(stmts,
+		`CREATE TABLE conversation_messages_default PARTITION OF conversation_messages DEFAULT`,
+		`CREATE TRIGGER trigger_apply_message_refs AFTER DELETE OR UPDATE OF id ON conversation_messages
+			FOR EACH ROW EXECUTE FUNCTION apply_conversation_message_refs()`,
+	)
+	for _, r := range refs {
+		stmts = append(stmts, fmt.Sprintf(`SELECT reference_conversation_messages(%s, %s, %s, %s)`,
+			pq.QuoteLiteral(r.Table), pq.QuoteLiteral(r.Column), pq.QuoteLiteral(r.DeleteType), pq.QuoteLiteral(r.UpdateType)))
+	}
+	stmts = append(stmts, fmt.Sprintf(`SELECT create_message_partitions(%d)`, messagePartitionsAhead))
+
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("%s: %w", s, err)
+		}
+	}
+	return tx.Commit()
+}
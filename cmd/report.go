@@ -1,8 +1,10 @@
 package main
 
 import (
+	"cmp"
 	"strconv"
 
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/zerodha/fastglue"
 )
 
@@ -44,6 +46,32 @@ func handleOverviewSLA(r *fastglue.Request) error {
 	return r.SendEnvelope(sla)
 }
 
+// handleOverviewSLAIncidents retrieves SLA breaches split by whether they happened during a declared incident.
+func handleOverviewSLAIncidents(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	breaches, err := app.report.GetOverviewSLAIncidents(days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(breaches)
+}
+
+// handleOverviewCollaboration retrieves internal collaboration metrics for the dashboard.
+func handleOverviewCollaboration(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	stats, err := app.report.GetOverviewCollaboration(days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(stats)
+}
+
 // handleOverviewCSAT retrieves CSAT metrics for the dashboard.
 func handleOverviewCSAT(r *fastglue.Request) error {
 	var (
@@ -57,6 +85,76 @@ func handleOverviewCSAT(r *fastglue.Request) error {
 	return r.SendEnvelope(csat)
 }
 
+// handleCSATBreakdown retrieves CSAT response rates and scores grouped by agent, team or inbox.
+func handleCSATBreakdown(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+		groupBy = string(r.RequestCtx.QueryArgs().Peek("group_by"))
+	)
+	if groupBy == "" {
+		groupBy = "agent"
+	}
+	breakdown, err := app.report.GetCSATBreakdown(groupBy, days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(breakdown)
+}
+
+// handleCSATLowScores retrieves the latest low-score CSAT responses for coaching.
+func handleCSATLowScores(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		days, _  = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+		limit, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("limit")))
+	)
+	responses, err := app.report.GetCSATLowScores(days, limit)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(responses)
+}
+
+// handleNPSTrend retrieves the score of the periodic NPS surveys of contacts by week.
+func handleNPSTrend(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	trend, err := app.report.GetNPSTrend(days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(trend)
+}
+
+// handleAbuseVolume retrieves the abusive messages flagged in the period by day and by assigned agent.
+func handleAbuseVolume(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	volume, err := app.report.GetAbuseVolume(days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(volume)
+}
+
+// handleAssignmentSLA retrieves the time-to-assignment SLA report.
+func handleAssignmentSLA(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	report, err := app.report.GetAssignmentSLA(days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(report)
+}
+
 // handleOverviewMessageVolume retrieves message volume metrics for the dashboard.
 func handleOverviewMessageVolume(r *fastglue.Request) error {
 	var (
@@ -82,3 +180,69 @@ func handleOverviewTagDistribution(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(tags)
 }
+
+// handleWaitingTimeHeatmap retrieves customer waiting time by weekday and hour.
+func handleWaitingTimeHeatmap(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	heatmap, err := app.report.GetWaitingTimeHeatmap(days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(heatmap)
+}
+
+// handleOverviewTopics retrieves the conversation topics found by the latest clustering run.
+func handleOverviewTopics(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	topics, err := app.topic.GetLatest()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(topics)
+}
+
+// handleGetCurrentAgentDashboard retrieves the signed-in agent's own metrics, it doesn't need report access.
+func handleGetCurrentAgentDashboard(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	stats, err := app.report.GetAgentDashboard(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(stats)
+}
+
+// handleTagTrend retrieves the conversation volume of the most used tags over time.
+func handleTagTrend(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		days, _  = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+		limit, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("limit")))
+		interval = cmp.Or(string(r.RequestCtx.QueryArgs().Peek("interval")), "day")
+	)
+	trend, err := app.report.GetTagTrend(days, interval, limit)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(trend)
+}
+
+// handleTagCoOccurrence retrieves the pairs of tags most often applied together to conversations.
+func handleTagCoOccurrence(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		days, _  = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+		tagID, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("tag_id")))
+		limit, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("limit")))
+	)
+	pairs, err := app.report.GetTagCoOccurrence(days, tagID, limit)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(pairs)
+}
@@ -106,5 +106,10 @@ func handleUpdateRole(r *fastglue.Request) error {
 		}
 	}
 
+	// Cached agents carry whether two-factor authentication is required by their roles.
+	if oldRole.RequireTwoFactor != updatedRole.RequireTwoFactor {
+		app.user.InvalidateAllAgentCache()
+	}
+
 	return r.SendEnvelope(updatedRole)
 }
@@ -0,0 +1,601 @@
+package main
+
+import (
+	"cmp"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	rmodels "github.com/abhinavxd/libredesk/internal/role/models"
+	"github.com/abhinavxd/libredesk/internal/scim"
+	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	scimBasePath        = "/scim/v2"
+	scimDefaultPageSize = 100
+	scimMaxPageSize     = 200
+
+	// Teams created by SCIM use manual assignment and UTC until an admin configures them.
+	scimTeamAssignmentType = "Manual"
+	scimTeamTimezone       = "UTC"
+)
+
+// patch registers a PATCH route, which fastglue does not wrap, on the underlying router.
+func patch(g *fastglue.Fastglue, app *App, path string, h fastglue.FastRequestHandler) {
+	g.Router.PATCH(path, func(ctx *fasthttp.RequestCtx) {
+		_ = h(&fastglue.Request{RequestCtx: ctx, Context: app})
+	})
+}
+
+// scimAuth checks the bearer token configured in `scim.token`. SCIM is disabled when no token is set.
+func scimAuth(handler fastglue.FastRequestHandler) fastglue.FastRequestHandler {
+	return func(r *fastglue.Request) error {
+		var (
+			token  = ko.String("scim.token")
+			header = string(r.RequestCtx.Request.Header.Peek("Authorization"))
+		)
+		bearer, ok := strings.CutPrefix(header, "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(bearer)), []byte(token)) != 1 {
+			return sendSCIMError(r, fasthttp.StatusUnauthorized, "", "invalid or missing bearer token")
+		}
+		return handler(r)
+	}
+}
+
+// handleSCIMServiceProviderConfig describes the supported SCIM features.
+func handleSCIMServiceProviderConfig(r *fastglue.Request) error {
+	return sendSCIM(r, fasthttp.StatusOK, map[string]any{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": scimMaxPageSize},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":    "oauthbearertoken",
+			"name":    "OAuth Bearer Token",
+			"primary": true,
+		}},
+	})
+}
+
+// handleSCIMGetUsers lists agents, optionally filtered by `userName eq "<email>"`.
+func handleSCIMGetUsers(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	attr, value, err := scim.ParseFilter(string(r.RequestCtx.QueryArgs().Peek("filter")))
+	if err != nil || (attr != "" && attr != "username") {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidFilter", "only `userName eq` filters are supported")
+	}
+
+	systemUser, err := app.user.GetSystemUser()
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+
+	var ids []int
+	if attr == "username" {
+		agent, err := app.user.GetAgent(0, strings.ToLower(value))
+		if err == nil && agent.ID != systemUser.ID {
+			ids = append(ids, agent.ID)
+		}
+	} else {
+		agents, err := app.user.GetAgents()
+		if err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+		for _, a := range agents {
+			if a.ID != systemUser.ID {
+				ids = append(ids, a.ID)
+			}
+		}
+		slices.Sort(ids)
+	}
+
+	start, page := scimPage(r, ids)
+	resources := make([]any, 0, len(page))
+	for _, id := range page {
+		agent, err := app.user.GetAgent(id, "")
+		if err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+		resources = append(resources, toSCIMUser(agent))
+	}
+	return sendSCIM(r, fasthttp.StatusOK, scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(ids),
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// handleSCIMGetUser returns an agent.
+func handleSCIMGetUser(r *fastglue.Request) error {
+	agent, err := getSCIMAgent(r)
+	if err != nil {
+		return err
+	}
+	return sendSCIM(r, fasthttp.StatusOK, toSCIMUser(agent))
+}
+
+// handleSCIMCreateUser creates an agent with the roles in `scim.default_roles`.
+func handleSCIMCreateUser(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = scim.User{}
+	)
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &req); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email()))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", "a valid email is required in `userName` or `emails`")
+	}
+	if _, err := app.user.GetAgent(0, email); err == nil {
+		return sendSCIMError(r, fasthttp.StatusConflict, "uniqueness", "an agent with this email already exists")
+	}
+
+	firstName := cmp.Or(strings.TrimSpace(req.Name.GivenName), strings.TrimSpace(req.DisplayName), strings.Split(email, "@")[0])
+	roles := ko.Strings("scim.default_roles")
+	if len(roles) == 0 {
+		roles = []string{rmodels.RoleAgent}
+	}
+
+	agent, err := app.user.CreateAgent(firstName, strings.TrimSpace(req.Name.FamilyName), email, roles)
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	if req.Active != nil && !*req.Active {
+		if err := app.user.UpdateAgent(agent.ID, agent.FirstName, agent.LastName, agent.Email.String, agent.Roles, false, agent.AvailabilityStatus, ""); err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+	}
+	app.lo.Info("agent provisioned via SCIM", "id", agent.ID, "email", email)
+
+	agent, err = app.user.GetAgent(agent.ID, "")
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	return sendSCIM(r, fasthttp.StatusCreated, toSCIMUser(agent))
+}
+
+// handleSCIMReplaceUser replaces the name, email and active state of an agent.
+func handleSCIMReplaceUser(r *fastglue.Request) error {
+	var req = scim.User{}
+	agent, err := getSCIMAgent(r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &req); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	patch := scim.UserPatch{
+		FirstName: cmp.Or(strings.TrimSpace(req.Name.GivenName), agent.FirstName),
+		LastName:  strings.TrimSpace(req.Name.FamilyName),
+		Email:     cmp.Or(req.Email(), agent.Email.String),
+		Active:    agent.Enabled,
+	}
+	if req.Active != nil {
+		patch.Active = *req.Active
+	}
+	return updateSCIMAgent(r, agent, patch)
+}
+
+// handleSCIMPatchUser applies PATCH operations to an agent, used by identity providers to deactivate agents.
+func handleSCIMPatchUser(r *fastglue.Request) error {
+	var req = scim.PatchRequest{}
+	agent, err := getSCIMAgent(r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &req); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	patch := scim.UserPatch{
+		FirstName: agent.FirstName,
+		LastName:  agent.LastName,
+		Email:     agent.Email.String,
+		Active:    agent.Enabled,
+	}
+	if err := patch.Apply(req.Operations); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", err.Error())
+	}
+	return updateSCIMAgent(r, agent, patch)
+}
+
+// handleSCIMDeleteUser soft deletes an agent and unassigns their open conversations.
+func handleSCIMDeleteUser(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	agent, err := getSCIMAgent(r)
+	if err != nil {
+		return err
+	}
+	if err := app.user.SoftDeleteAgent(agent.ID); err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	if err := app.conversation.UnassignOpen(agent.ID); err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	app.lo.Info("agent deleted via SCIM", "id", agent.ID)
+	r.RequestCtx.SetStatusCode(fasthttp.StatusNoContent)
+	return nil
+}
+
+// handleSCIMGetGroups lists teams, optionally filtered by `displayName eq "<name>"`.
+func handleSCIMGetGroups(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	attr, value, err := scim.ParseFilter(string(r.RequestCtx.QueryArgs().Peek("filter")))
+	if err != nil || (attr != "" && attr != "displayname") {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidFilter", "only `displayName eq` filters are supported")
+	}
+
+	teams, err := app.team.GetAll()
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	if attr == "displayname" {
+		teams = slices.DeleteFunc(teams, func(t tmodels.Team) bool { return !strings.EqualFold(t.Name, value) })
+	}
+	slices.SortFunc(teams, func(a, b tmodels.Team) int { return a.ID - b.ID })
+
+	start, page := scimPage(r, teams)
+	resources := make([]any, 0, len(page))
+	for _, t := range page {
+		group, err := toSCIMGroup(app, t)
+		if err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+		resources = append(resources, group)
+	}
+	return sendSCIM(r, fasthttp.StatusOK, scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(teams),
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// handleSCIMGetGroup returns a team with its agents.
+func handleSCIMGetGroup(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	team, err := getSCIMTeam(r)
+	if err != nil {
+		return err
+	}
+	group, err := toSCIMGroup(app, team)
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	return sendSCIM(r, fasthttp.StatusOK, group)
+}
+
+// handleSCIMCreateGroup creates a team with the given agents.
+func handleSCIMCreateGroup(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = scim.Group{}
+	)
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &req); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+	name := strings.TrimSpace(req.DisplayName)
+	if name == "" {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", "`displayName` is required")
+	}
+	members, err := scim.ParseMemberIDs(req.Members)
+	if err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", err.Error())
+	}
+
+	team, err := app.team.Create(name, scimTeamTimezone, scimTeamAssignmentType, null.Int{}, null.Int{}, "", 0)
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	if err := setSCIMTeamMembers(app, team.ID, members); err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	app.lo.Info("team provisioned via SCIM", "id", team.ID, "name", name)
+
+	group, err := toSCIMGroup(app, team)
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	return sendSCIM(r, fasthttp.StatusCreated, group)
+}
+
+// handleSCIMReplaceGroup replaces the name and agents of a team.
+func handleSCIMReplaceGroup(r *fastglue.Request) error {
+	var req = scim.Group{}
+	team, err := getSCIMTeam(r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &req); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+	members, err := scim.ParseMemberIDs(req.Members)
+	if err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", err.Error())
+	}
+	return updateSCIMTeam(r, team, scim.GroupPatch{
+		DisplayName:    strings.TrimSpace(req.DisplayName),
+		ReplaceMembers: true,
+		Members:        members,
+	})
+}
+
+// handleSCIMPatchGroup applies PATCH operations to a team, used by identity providers to add and remove agents.
+func handleSCIMPatchGroup(r *fastglue.Request) error {
+	var req = scim.PatchRequest{}
+	team, err := getSCIMTeam(r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(r.RequestCtx.PostBody(), &req); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+	var patch scim.GroupPatch
+	if err := patch.Apply(req.Operations); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", err.Error())
+	}
+	return updateSCIMTeam(r, team, patch)
+}
+
+// handleSCIMDeleteGroup deletes a team.
+func handleSCIMDeleteGroup(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	team, err := getSCIMTeam(r)
+	if err != nil {
+		return err
+	}
+	if err := app.team.Delete(team.ID); err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	app.lo.Info("team deleted via SCIM", "id", team.ID)
+	r.RequestCtx.SetStatusCode(fasthttp.StatusNoContent)
+	return nil
+}
+
+// updateSCIMAgent saves the patched attributes of an agent, unassigning their open conversations when deactivated.
+func updateSCIMAgent(r *fastglue.Request, agent umodels.User, patch scim.UserPatch) error {
+	var app = r.Context.(*App)
+	email := strings.ToLower(strings.TrimSpace(patch.Email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", "invalid email")
+	}
+	if strings.TrimSpace(patch.FirstName) == "" {
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", "`name.givenName` is required")
+	}
+	if err := scim.CheckEmail(agent.ID, agent.Email.String, email, func(email string) (int, error) {
+		existing, err := app.user.GetAgent(0, email)
+		if err != nil {
+			if envErr, ok := err.(envelope.Error); ok && envErr.ErrorType == envelope.NotFoundError {
+				return 0, nil
+			}
+			return 0, err
+		}
+		return existing.ID, nil
+	}); err != nil {
+		if errors.Is(err, scim.ErrEmailTaken) {
+			return sendSCIMError(r, fasthttp.StatusConflict, "uniqueness", "an agent with this email already exists")
+		}
+		return sendSCIMEnvelopeError(r, err)
+	}
+
+	if err := app.user.UpdateAgent(agent.ID, strings.TrimSpace(patch.FirstName), strings.TrimSpace(patch.LastName), email, agent.Roles, patch.Active, agent.AvailabilityStatus, ""); err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	app.authz.InvalidateUserCache(agent.ID)
+
+	if agent.Enabled && !patch.Active {
+		app.lo.Info("agent deactivated via SCIM", "id", agent.ID)
+		if err := app.conversation.UnassignOpen(agent.ID); err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+	}
+
+	agent, err := app.user.GetAgent(agent.ID, "")
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	return sendSCIM(r, fasthttp.StatusOK, toSCIMUser(agent))
+}
+
+// updateSCIMTeam applies group changes to a team.
+func updateSCIMTeam(r *fastglue.Request, team tmodels.Team, patch scim.GroupPatch) error {
+	var app = r.Context.(*App)
+	if patch.DisplayName != "" && patch.DisplayName != team.Name {
+		updated, err := app.team.Update(team.ID, patch.DisplayName, team.Timezone, team.ConversationAssignmentType, team.BusinessHoursID, team.SLAPolicyID, team.Emoji.String, team.MaxAutoAssignedConversations)
+		if err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+		team = updated
+	}
+	if patch.ReplaceMembers {
+		if err := setSCIMTeamMembers(app, team.ID, patch.Members); err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+	}
+	if len(patch.Add) > 0 {
+		if err := app.team.AddMembers(team.ID, patch.Add); err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+		invalidateSCIMAgents(app, patch.Add)
+	}
+	if len(patch.Remove) > 0 {
+		if err := app.team.RemoveMembers(team.ID, patch.Remove); err != nil {
+			return sendSCIMEnvelopeError(r, err)
+		}
+		invalidateSCIMAgents(app, patch.Remove)
+	}
+
+	group, err := toSCIMGroup(app, team)
+	if err != nil {
+		return sendSCIMEnvelopeError(r, err)
+	}
+	return sendSCIM(r, fasthttp.StatusOK, group)
+}
+
+// setSCIMTeamMembers replaces the agents of a team.
+func setSCIMTeamMembers(app *App, teamID int, userIDs []int) error {
+	current, err := app.team.GetAgents(teamID)
+	if err != nil {
+		return err
+	}
+	if err := app.team.SetMembers(teamID, userIDs); err != nil {
+		return err
+	}
+	for _, a := range current {
+		userIDs = append(userIDs, a.ID)
+	}
+	invalidateSCIMAgents(app, userIDs)
+	return nil
+}
+
+// invalidateSCIMAgents drops cached agents whose teams changed.
+func invalidateSCIMAgents(app *App, userIDs []int) {
+	for _, id := range userIDs {
+		app.user.InvalidateAgentCache(id)
+	}
+}
+
+// getSCIMAgent returns the agent in the `id` path parameter, sending a SCIM error if it is missing.
+// The system user cannot be managed through SCIM.
+func getSCIMAgent(r *fastglue.Request) (umodels.User, error) {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return umodels.User{}, sendSCIMError(r, fasthttp.StatusNotFound, "", "user not found")
+	}
+	agent, err := app.user.GetAgent(id, "")
+	if err != nil {
+		return agent, sendSCIMEnvelopeError(r, err)
+	}
+	if agent.IsSystemUser() {
+		return agent, sendSCIMError(r, fasthttp.StatusNotFound, "", "user not found")
+	}
+	return agent, nil
+}
+
+// getSCIMTeam returns the team in the `id` path parameter, sending a SCIM error if it is missing.
+func getSCIMTeam(r *fastglue.Request) (tmodels.Team, error) {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return tmodels.Team{}, sendSCIMError(r, fasthttp.StatusNotFound, "", "group not found")
+	}
+	team, err := app.team.Get(id)
+	if err != nil {
+		return team, sendSCIMEnvelopeError(r, err)
+	}
+	return team, nil
+}
+
+func toSCIMUser(u umodels.User) scim.User {
+	active := u.Enabled
+	groups := make([]scim.MultiValue, 0, len(u.Teams))
+	for _, t := range u.Teams {
+		groups = append(groups, scim.MultiValue{Value: strconv.Itoa(t.ID), Display: t.Name})
+	}
+	return scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.Itoa(u.ID),
+		UserName:    u.Email.String,
+		Name:        scim.Name{GivenName: u.FirstName, FamilyName: u.LastName, Formatted: u.FullName()},
+		DisplayName: u.FullName(),
+		Emails:      []scim.MultiValue{{Value: u.Email.String, Type: "work", Primary: true}},
+		Active:      &active,
+		Groups:      groups,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+			Location:     scimBasePath + "/Users/" + strconv.Itoa(u.ID),
+		},
+	}
+}
+
+func toSCIMGroup(app *App, t tmodels.Team) (scim.Group, error) {
+	agents, err := app.team.GetAgents(t.ID)
+	if err != nil {
+		return scim.Group{}, err
+	}
+	members := make([]scim.MultiValue, 0, len(agents))
+	for _, a := range agents {
+		members = append(members, scim.MultiValue{Value: strconv.Itoa(a.ID), Display: strings.TrimSpace(a.FirstName + " " + a.LastName)})
+	}
+	return scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.Itoa(t.ID),
+		DisplayName: t.Name,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      t.CreatedAt,
+			LastModified: t.UpdatedAt,
+			Location:     scimBasePath + "/Groups/" + strconv.Itoa(t.ID),
+		},
+	}, nil
+}
+
+// scimPage returns the 1-based start index and the page of items selected by the `startIndex` and `count` query params.
+func scimPage[T any](r *fastglue.Request, items []T) (int, []T) {
+	start, _ := strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("startIndex")))
+	start = max(start, 1)
+	count, err := strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("count")))
+	if err != nil {
+		count = scimDefaultPageSize
+	}
+	count = min(max(count, 0), scimMaxPageSize)
+
+	from := min(start-1, len(items))
+	to := min(from+count, len(items))
+	return start, items[from:to]
+}
+
+func sendSCIM(r *fastglue.Request, code int, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return r.SendBytes(code, scim.ContentType, b)
+}
+
+func sendSCIMError(r *fastglue.Request, code int, scimType, detail string) error {
+	return sendSCIM(r, code, scim.NewError(code, scimType, detail))
+}
+
+// sendSCIMEnvelopeError maps an envelope error to a SCIM error response.
+func sendSCIMEnvelopeError(r *fastglue.Request, err error) error {
+	var envErr envelope.Error
+	if !errors.As(err, &envErr) {
+		return sendSCIMError(r, fasthttp.StatusInternalServerError, "", err.Error())
+	}
+	switch envErr.ErrorType {
+	case envelope.NotFoundError:
+		return sendSCIMError(r, fasthttp.StatusNotFound, "", envErr.Message)
+	case envelope.InputError:
+		return sendSCIMError(r, fasthttp.StatusBadRequest, "invalidValue", envErr.Message)
+	default:
+		return sendSCIMError(r, fasthttp.StatusInternalServerError, "", envErr.Message)
+	}
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/zerodha/fastglue"
 )
 
 const (
 	minSearchQueryLength = 3
+	maxMentionResults    = 20
 )
 
 // handleSearchConversations searches conversations based on the query.
@@ -38,6 +41,30 @@ func handleSearchContacts(r *fastglue.Request) error {
 	return handleSearch(r, wrapper)
 }
 
+// handleSearchMentions returns agents and teams the current user can mention. Users who can't
+// see all conversations only get their own teams and agents sharing one of them.
+func handleSearchMentions(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		q     = strings.TrimSpace(string(r.RequestCtx.QueryArgs().Peek("q")))
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	readAll, err := app.authz.Enforce(user, "conversations", "read_all")
+	if err != nil {
+		app.lo.Error("error checking permission", "error", err)
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+	}
+	results, err := app.search.Mentions(user.ID, q, !readAll, maxMentionResults)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(results)
+}
+
 // handleSearch searches for the given query using the provided search function.
 func handleSearch(r *fastglue.Request, searchFunc func(string) (interface{}, error)) error {
 	var (
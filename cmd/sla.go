@@ -54,7 +54,7 @@ func handleCreateSLA(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	createdSLA, err := app.sla.Create(sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.Notifications)
+	createdSLA, err := app.sla.Create(sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.AssignmentTime, sla.Notifications)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -82,7 +82,7 @@ func handleUpdateSLA(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	updatedSLA, err := app.sla.Update(id, sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.Notifications)
+	updatedSLA, err := app.sla.Update(id, sla.Name, sla.Description, sla.FirstResponseTime, sla.ResolutionTime, sla.NextResponseTime, sla.AssignmentTime, sla.Notifications)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -112,8 +112,8 @@ func validateSLA(app *App, sla *smodels.SLAPolicy) error {
 	if sla.Name == "" {
 		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
 	}
-	if sla.FirstResponseTime.String == "" && sla.NextResponseTime.String == "" && sla.ResolutionTime.String == "" {
-		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "At least one of `first_response_time`, `next_response_time`, or `resolution_time` must be provided."), nil)
+	if sla.FirstResponseTime.String == "" && sla.NextResponseTime.String == "" && sla.ResolutionTime.String == "" && sla.AssignmentTime.String == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "At least one of `first_response_time`, `next_response_time`, `resolution_time` or `assignment_time` must be provided."), nil)
 	}
 
 	// Validate notifications if any.
@@ -185,5 +185,16 @@ func validateSLA(app *App, sla *smodels.SLAPolicy) error {
 		}
 	}
 
+	// Validate assignment time duration string if not empty.
+	if sla.AssignmentTime.String != "" {
+		at, err := time.ParseDuration(sla.AssignmentTime.String)
+		if err != nil {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidDuration"), nil)
+		}
+		if at.Minutes() < 1 {
+			return envelope.NewError(envelope.InputError, app.i18n.T("sla.minimumDurationOneMinute"), nil)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	smodels "github.com/abhinavxd/libredesk/internal/sla/models"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	maxIncidentTitleLength       = 255
+	maxIncidentDescriptionLength = 5000
+
+	incidentSourceStatuspage = "statuspage"
+)
+
+// statuspageWebhook is the subset of a Statuspage incident webhook payload used to sync incidents.
+type statuspageWebhook struct {
+	Incident *struct {
+		ID              string    `json:"id"`
+		Name            string    `json:"name"`
+		Status          string    `json:"status"`
+		CreatedAt       time.Time `json:"created_at"`
+		StartedAt       null.Time `json:"started_at"`
+		ResolvedAt      null.Time `json:"resolved_at"`
+		IncidentUpdates []struct {
+			Body string `json:"body"`
+		} `json:"incident_updates"`
+	} `json:"incident"`
+}
+
+// handleGetIncidents returns all SLA incidents.
+func handleGetIncidents(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	incidents, err := app.sla.GetAllIncidents()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(incidents)
+}
+
+// handleGetIncident returns an SLA incident by ID.
+func handleGetIncident(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	incident, err := app.sla.GetIncident(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(incident)
+}
+
+// handleCreateIncident declares a new SLA incident.
+func handleCreateIncident(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		incident = smodels.Incident{}
+	)
+	if err := r.Decode(&incident, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateIncident(app, &incident); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	result, err := app.sla.CreateIncident(incident)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateIncident updates an SLA incident, setting `ends_at` resolves it.
+func handleUpdateIncident(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		incident = smodels.Incident{}
+		id, _    = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&incident, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateIncident(app, &incident); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	result, err := app.sla.UpdateIncident(id, incident)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteIncident deletes an SLA incident.
+func handleDeleteIncident(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.sla.DeleteIncident(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleStatuspageWebhook syncs incidents pushed by a Statuspage webhook subscription.
+// The subscription URL must carry the token configured in `sla.statuspage.token`.
+func handleStatuspageWebhook(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		token   = ko.String("sla.statuspage.token")
+		payload = statuspageWebhook{}
+	)
+	if token == "" || subtle.ConstantTimeCompare(r.RequestCtx.QueryArgs().Peek("token"), []byte(token)) != 1 {
+		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("status.deniedPermission"), nil, envelope.PermissionError)
+	}
+	if err := r.Decode(&payload, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+
+	// Component status updates carry no incident.
+	in := payload.Incident
+	if in == nil || in.ID == "" {
+		return r.SendEnvelope(true)
+	}
+
+	startsAt := in.CreatedAt
+	if in.StartedAt.Valid {
+		startsAt = in.StartedAt.Time
+	}
+	var endsAt null.Time
+	if in.Status == "resolved" || in.Status == "postmortem" || in.Status == "completed" {
+		endsAt = null.TimeFrom(time.Now())
+		if in.ResolvedAt.Valid {
+			endsAt = in.ResolvedAt
+		}
+	}
+	var description string
+	if len(in.IncidentUpdates) > 0 {
+		description = in.IncidentUpdates[0].Body
+	}
+
+	// Truncate to the column limits, dropping any rune split by the cut.
+	title := strings.TrimSpace(in.Name)
+	if len(title) > maxIncidentTitleLength {
+		title = strings.ToValidUTF8(title[:maxIncidentTitleLength], "")
+	}
+	if len(description) > maxIncidentDescriptionLength {
+		description = strings.ToValidUTF8(description[:maxIncidentDescriptionLength], "")
+	}
+
+	incident, err := app.sla.SyncExternalIncident(incidentSourceStatuspage, in.ID, title, description, startsAt, endsAt, ko.Bool("sla.statuspage.pause_sla"))
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(incident)
+}
+
+func validateIncident(app *App, i *smodels.Incident) error {
+	i.Title = strings.TrimSpace(i.Title)
+	if i.Title == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`title`"), nil)
+	}
+	if len(i.Title) > maxIncidentTitleLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxIncidentTitleLength)), nil)
+	}
+	if len(i.Description) > maxIncidentDescriptionLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxIncidentDescriptionLength)), nil)
+	}
+	if i.StartsAt.IsZero() {
+		i.StartsAt = time.Now()
+	}
+	if i.EndsAt.Valid && !i.EndsAt.Time.After(i.StartsAt) {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	if i.InboxIDs == nil {
+		i.InboxIDs = []int64{}
+	}
+	if i.TagIDs == nil {
+		i.TagIDs = []int64{}
+	}
+	return nil
+}
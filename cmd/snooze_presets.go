@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/status"
+	smodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// handleGetSnoozePresets returns all snooze presets.
+func handleGetSnoozePresets(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	out, err := app.status.GetAllSnoozePresets()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleCreateSnoozePreset creates a snooze preset.
+func handleCreateSnoozePreset(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		preset = smodels.SnoozePreset{}
+	)
+	if err := r.Decode(&preset, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	out, err := app.status.CreateSnoozePreset(preset)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleUpdateSnoozePreset updates a snooze preset.
+func handleUpdateSnoozePreset(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		preset = smodels.SnoozePreset{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&preset, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	out, err := app.status.UpdateSnoozePreset(id, preset)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleDeleteSnoozePreset deletes a snooze preset.
+func handleDeleteSnoozePreset(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	if err := app.status.DeleteSnoozePreset(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// snoozePresetDuration returns the snooze duration of a preset for a conversation, computed against
+// the business hours and time zone of its team, or the helpdesk defaults.
+func snoozePresetDuration(app *App, presetID int, conv *cmodels.Conversation) (string, error) {
+	preset, err := app.status.GetSnoozePreset(presetID)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		bh bmodels.BusinessHours
+		tz string
+	)
+	if preset.Kind != smodels.SnoozeKindDuration {
+		bh, tz, err = app.sla.GetBusinessHoursAndTimezone(conv.AssignedTeamID.Int)
+		if err != nil {
+			app.lo.Warn("could not get business hours for snooze preset", "preset_id", presetID, "team_id", conv.AssignedTeamID.Int, "error", err)
+			return "", envelope.NewError(envelope.InputError, app.i18n.T("conversation.snoozePresetNeedsBusinessHours"), nil)
+		}
+	}
+
+	now := time.Now()
+	until, err := status.SnoozeUntil(preset, bh, tz, now)
+	if err != nil {
+		app.lo.Error("error computing snooze preset time", "preset_id", presetID, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if !until.After(now) {
+		return "", envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidSnoozeDuration"), nil)
+	}
+	return until.Sub(now).Round(time.Second).String(), nil
+}
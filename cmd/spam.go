@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strconv"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/inbox"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+type senderRuleReq struct {
+	Email string `json:"email"`
+	Type  string `json:"type"`
+}
+
+// handleMarkConversationSpam moves a conversation to spam and blocks future mail from its contact in the
+// conversation's inbox, unless the contact is on the inbox's allow list.
+func handleMarkConversationSpam(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if err := app.conversation.UpdateConversationStatus(uuid, 0 /**status_id**/, cmodels.StatusSpam, "", user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	email := conversation.Contact.Email.String
+	if conversation.InboxChannel != inbox.ChannelEmail || email == "" {
+		return r.SendEnvelope(true)
+	}
+	ruleType, err := app.inbox.GetSenderRuleType(conversation.InboxID, email)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if ruleType == imodels.SenderRuleAllow {
+		return r.SendEnvelope(true)
+	}
+	if _, err := app.inbox.SetSenderRule(conversation.InboxID, email, imodels.SenderRuleBlock, user.ID, conversation.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleMarkConversationNotSpam reopens a conversation marked as spam and unblocks its contact in the
+// conversation's inbox.
+func handleMarkConversationNotSpam(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if conversation.Status.String != cmodels.StatusSpam {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+
+	if email := conversation.Contact.Email.String; email != "" {
+		ruleType, err := app.inbox.GetSenderRuleType(conversation.InboxID, email)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		if ruleType == imodels.SenderRuleBlock {
+			if err := app.inbox.DeleteSenderRule(conversation.InboxID, email); err != nil {
+				return sendErrorEnvelope(r, err)
+			}
+		}
+	}
+
+	if err := app.conversation.UpdateConversationStatus(uuid, 0 /**status_id**/, cmodels.StatusOpen, "", user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetSenderRules returns the blocked and allowed senders of an inbox, filtered by the optional `type` query param.
+func handleGetSenderRules(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		id, _    = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		ruleType = string(r.RequestCtx.QueryArgs().Peek("type"))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if ruleType != "" && ruleType != imodels.SenderRuleBlock && ruleType != imodels.SenderRuleAllow {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	rules, err := app.inbox.GetSenderRules(id, ruleType)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(rules)
+}
+
+// handleSetSenderRule blocks or allows a sender in an inbox.
+func handleSetSenderRule(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		req   = senderRuleReq{}
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if _, err := app.inbox.GetDBRecord(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	rule, err := app.inbox.SetSenderRule(id, req.Email, req.Type, auser.ID, 0)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(rule)
+}
+
+// handleDeleteSenderRule removes the rule of the sender in the `email` query param from an inbox.
+func handleDeleteSenderRule(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		email = string(r.RequestCtx.QueryArgs().Peek("email"))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if email == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.empty", "name", "`email`"), nil, envelope.InputError)
+	}
+	if err := app.inbox.DeleteSenderRule(id, email); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template"
 	"github.com/abhinavxd/libredesk/internal/template/models"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
@@ -25,6 +26,11 @@ func handleGetTemplates(r *fastglue.Request) error {
 	return r.SendEnvelope(t)
 }
 
+// handleGetTemplateVariables returns the registry of template variables.
+func handleGetTemplateVariables(r *fastglue.Request) error {
+	return r.SendEnvelope(template.Variables)
+}
+
 // handleGetTemplate returns a template by id.
 func handleGetTemplate(r *fastglue.Request) error {
 	var (
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/colorlog"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// testDataBatchSize is the number of conversations generated in one transaction.
+	testDataBatchSize = 1000
+
+	// testDataDays is how far back the generated conversations are spread.
+	testDataDays = 180
+
+	// testDataEmailDomain is the reserved domain of generated contacts, mail to it is never delivered.
+	testDataEmailDomain = "example.com"
+)
+
+var (
+	testFirstNames = []string{"Olivia", "Liam", "Charlotte", "Noah", "Amelia", "Jack", "Isla", "William", "Mia", "Oliver",
+		"Ava", "Thomas", "Grace", "James", "Chloe", "Lucas", "Zoe", "Henry", "Ruby", "Leo", "Priya", "Wei", "Aisha", "Mateo",
+		"Sofia", "Hiroshi", "Fatima", "Arjun", "Elena", "Kofi"}
+	testLastNames = []string{"Smith", "Jones", "Williams", "Brown", "Wilson", "Taylor", "Nguyen", "Johnson", "Martin", "White",
+		"Anderson", "Walker", "Thompson", "Harris", "Lee", "Ryan", "Robinson", "Kelly", "King", "Patel", "Chen", "Garcia",
+		"Tanaka", "Okafor", "Rossi", "Kumar", "Silva", "Murphy", "Davies", "Hughes"}
+	testSubjects = []string{"Where is my order?", "Refund request for order #%d", "Item arrived damaged", "Can't log in to my account",
+		"Question about invoice %d", "Change delivery address", "Wrong size received", "Warranty claim for order #%d",
+		"Payment failed at checkout", "How do I cancel my subscription?", "Missing item in package", "Product not working as expected",
+		"Request for a tax invoice", "Update my billing details", "Discount code not applying", "Exchange request for order #%d"}
+	testContactMessages = []string{
+		"Hi, I placed an order last week and haven't received any tracking details yet. Could you check on it for me?",
+		"The package arrived today but the box was crushed and the item inside is broken. What can you do?",
+		"I've tried resetting my password three times and never get the email. Can you help?",
+		"I was charged twice for the same order. Please refund the duplicate payment.",
+		"Can I still change the delivery address? I've moved since placing the order.",
+		"Thanks for the quick reply. I've attached the photos you asked for.",
+		"That didn't fix it unfortunately, the same error still shows up.",
+		"Any update on this? It's been a few days since I last heard back.",
+		"Perfect, that's sorted it. Thanks so much for your help!",
+		"Could you send me a copy of the invoice for my records?",
+		"The size I received is wrong, I ordered a medium and got a large.",
+		"Is there a way to pause my subscription rather than cancelling it?",
+	}
+	testAgentMessages = []string{
+		"Thanks for reaching out! I've checked your order and it's with the courier now, you should receive tracking details within 24 hours.",
+		"I'm sorry to hear the item arrived damaged. Could you send us a couple of photos of the item and the packaging?",
+		"I've sent a password reset link to your email address, please check your spam folder too.",
+		"I can see the duplicate charge and have processed a refund, it should show up in 3-5 business days.",
+		"I've updated the delivery address on your order, you'll receive a confirmation email shortly.",
+		"Thanks for the photos. I've arranged a replacement which will ship today.",
+		"Sorry about that! I've escalated this to our technical team and will update you as soon as I hear back.",
+		"I've attached a copy of your invoice to this email.",
+		"Glad to hear it's sorted. I'll close this conversation for now, just reply if you need anything else.",
+		"No problem at all, I've paused your subscription for two months.",
+	}
+	testPrivateNotes = []string{
+		"Customer has contacted us about this before, see previous conversation.",
+		"Checked with the warehouse, the parcel left yesterday.",
+		"Refund approved by finance.",
+		"Waiting on the courier to confirm the damage claim.",
+	}
+)
+
+// testDataLookups holds existing records the generated conversations are linked to.
+type testDataLookups struct {
+	InboxIDs    []int
+	AgentIDs    []int
+	TeamIDs     []int
+	PriorityIDs []int
+	Statuses    []struct {
+		ID       int    `db:"id"`
+		Category string `db:"category"`
+	}
+}
+
+// generateTestData generates the given number of fake contacts, conversations and messages spread over
+// the last testDataDays days, for staging environments and performance testing. Each conversation gets
+// between 1 and maxMessages messages.
+func generateTestData(db *sqlx.DB, conversations, maxMessages int, prompt bool) {
+	if maxMessages < 1 {
+		maxMessages = 1
+	}
+
+	colorlog.Red(fmt.Sprintf("WARNING: This will add %d fake conversations to the database - '%s'. Do not run this on a production database.", conversations, ko.String("db.database")))
+	if prompt {
+		log.Print("Continue (y/n)? ")
+		var ok string
+		fmt.Scanf("%s", &ok)
+		if !strings.EqualFold(ok, "y") {
+			log.Fatalf("test data generation cancelled")
+		}
+	}
+
+	lookups, err := getTestDataLookups(db)
+	if err != nil {
+		log.Fatalf("error fetching records for test data: %v", err)
+	}
+	if len(lookups.InboxIDs) == 0 || len(lookups.Statuses) == 0 {
+		log.Fatalf("at least one inbox and conversation status are required to generate test data")
+	}
+
+	start := time.Now()
+	for done := 0; done < conversations; {
+		n := min(testDataBatchSize, conversations-done)
+		messages, err := insertTestDataBatch(db, lookups, n, maxMessages)
+		if err != nil {
+			log.Fatalf("error generating test data: %v", err)
+		}
+		done += n
+		log.Printf("generated %d/%d conversations (%d messages in this batch)", done, conversations, messages)
+	}
+	log.Printf("test data generated in %s", time.Since(start).Round(time.Second))
+}
+
+// getTestDataLookups fetches the inboxes, agents, teams, statuses and priorities to link generated conversations to.
+func getTestDataLookups(db *sqlx.DB) (testDataLookups, error) {
+	var l testDataLookups
+	if err := db.Select(&l.InboxIDs, `SELECT id FROM inboxes WHERE deleted_at IS NULL ORDER BY id`); err != nil {
+		return l, err
+	}
+	if err := db.Select(&l.AgentIDs, `SELECT id FROM users WHERE type = 'agent' AND deleted_at IS NULL AND enabled AND email != $1 ORDER BY id`, umodels.SystemUserEmail); err != nil {
+		return l, err
+	}
+	if err := db.Select(&l.TeamIDs, `SELECT id FROM teams ORDER BY id`); err != nil {
+		return l, err
+	}
+	if err := db.Select(&l.PriorityIDs, `SELECT id FROM conversation_priorities ORDER BY id`); err != nil {
+		return l, err
+	}
+	if err := db.Select(&l.Statuses, `SELECT id, category FROM conversation_statuses ORDER BY id`); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+// testMessage is a generated message of a conversation.
+type testMessage struct {
+	typ        string
+	status     string
+	private    bool
+	content    string
+	senderID   int64
+	senderType string
+	createdAt  time.Time
+}
+
+// insertTestDataBatch generates n conversations with their contacts and messages in one transaction using
+// COPY, and returns the number of messages generated.
+func insertTestDataBatch(db *sqlx.DB, l testDataLookups, n, maxMessages int) (int, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// IDs are reserved upfront so the rows can be linked without reading them back.
+	contactIDs, err := reserveIDs(tx, "users", max(1, n*7/10))
+	if err != nil {
+		return 0, err
+	}
+	conversationIDs, err := reserveIDs(tx, "conversations", n)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	stmt, err := tx.Prepare(pq.CopyIn("users", "id", "type", "first_name", "last_name", "email", "created_at", "updated_at"))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range contactIDs {
+		first, last := pick(testFirstNames), pick(testLastNames)
+		email := fmt.Sprintf("%s.%s.%d@%s", strings.ToLower(first), strings.ToLower(last), id, testDataEmailDomain)
+		createdAt := now.Add(-time.Duration(rand.Int64N(int64(testDataDays * 24 * time.Hour))))
+		if _, err := stmt.Exec(id, "contact", first, last, email, createdAt, createdAt); err != nil {
+			return 0, err
+		}
+	}
+	if err := closeCopy(stmt); err != nil {
+		return 0, err
+	}
+
+	convStmt, err := tx.Prepare(pq.CopyIn("conversations", "id", "contact_id", "inbox_id", "status_id", "priority_id",
+		"assigned_user_id", "assigned_team_id", "subject", "meta", "created_at", "updated_at", "status_changed_at",
+		"first_reply_at", "last_reply_at", "resolved_at", "closed_at", "snoozed_until", "waiting_since",
+		"last_message", "last_message_at", "last_message_sender", "last_message_sender_id",
+		"last_interaction", "last_interaction_at", "last_interaction_sender", "last_interaction_sender_id"))
+	if err != nil {
+		return 0, err
+	}
+	meta, _ := json.Marshal(map[string]any{"synthetic": true})
+	var all [][]testMessage
+	for _, id := range conversationIDs {
+		var (
+			contactID = contactIDs[rand.IntN(len(contactIDs))]
+			status    = l.Statuses[rand.IntN(len(l.Statuses))]
+			createdAt = now.Add(-time.Duration(rand.Int64N(int64(testDataDays * 24 * time.Hour))))
+			agentID   any
+			teamID    any
+			priority  any
+		)
+		if len(l.AgentIDs) > 0 && rand.IntN(10) < 7 {
+			agentID = l.AgentIDs[rand.IntN(len(l.AgentIDs))]
+		}
+		if len(l.TeamIDs) > 0 && rand.IntN(10) < 5 {
+			teamID = l.TeamIDs[rand.IntN(len(l.TeamIDs))]
+		}
+		if len(l.PriorityIDs) > 0 && rand.IntN(10) < 6 {
+			priority = l.PriorityIDs[rand.IntN(len(l.PriorityIDs))]
+		}
+		// Agents without an assignee reply as the first agent found.
+		replierID := int64(0)
+		if agentID != nil {
+			replierID = int64(agentID.(int))
+		} else if len(l.AgentIDs) > 0 {
+			replierID = int64(l.AgentIDs[0])
+		}
+		messages := generateTestMessages(contactID, replierID, createdAt, now, 1+rand.IntN(maxMessages))
+		all = append(all, messages)
+
+		var firstReplyAt, lastReplyAt, waitingSince, resolvedAt, closedAt, snoozedUntil any
+		var lastPublic, lastAny *testMessage
+		for i := range messages {
+			m := &messages[i]
+			lastAny = m
+			if m.private {
+				continue
+			}
+			lastPublic = m
+			if m.senderType == "agent" {
+				if firstReplyAt == nil {
+					firstReplyAt = m.createdAt
+				}
+				lastReplyAt = m.createdAt
+			}
+		}
+		if lastPublic == nil {
+			lastPublic = lastAny
+		}
+		updatedAt := lastAny.createdAt
+		switch status.Category {
+		case "resolved":
+			resolvedAt = updatedAt.Add(time.Hour)
+			closedAt = resolvedAt
+		case "waiting":
+			snoozedUntil = now.Add(time.Duration(1+rand.IntN(72)) * time.Hour)
+		default:
+			if lastPublic.senderType == "contact" {
+				waitingSince = lastPublic.createdAt
+			}
+		}
+
+		subject := pick(testSubjects)
+		if strings.Contains(subject, "%d") {
+			subject = fmt.Sprintf(subject, 10000+rand.IntN(90000))
+		}
+		if _, err := convStmt.Exec(id, contactID, l.InboxIDs[rand.IntN(len(l.InboxIDs))], status.ID, priority,
+			agentID, teamID, subject, string(meta), createdAt, updatedAt, updatedAt,
+			firstReplyAt, lastReplyAt, resolvedAt, closedAt, snoozedUntil, waitingSince,
+			lastPublic.content, lastPublic.createdAt, lastPublic.senderType, lastPublic.senderID,
+			lastPublic.content, lastPublic.createdAt, lastPublic.senderType, lastPublic.senderID); err != nil {
+			return 0, err
+		}
+	}
+	if err := closeCopy(convStmt); err != nil {
+		return 0, err
+	}
+
+	msgStmt, err := tx.Prepare(pq.CopyIn("conversation_messages", "conversation_id", "type", "status", "private",
+		"content_type", "content", "text_content", "sender_id", "sender_type", "created_at", "updated_at"))
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i, messages := range all {
+		for _, m := range messages {
+			if _, err := msgStmt.Exec(conversationIDs[i], m.typ, m.status, m.private, "text", m.content, m.content,
+				m.senderID, m.senderType, m.createdAt, m.createdAt); err != nil {
+				return 0, err
+			}
+			count++
+		}
+	}
+	if err := closeCopy(msgStmt); err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+// generateTestMessages generates a thread that starts with the contact and alternates with agent replies,
+// with the occasional private note, spaced minutes to hours apart and never later than now.
+func generateTestMessages(contactID, agentID int64, start, now time.Time, count int) []testMessage {
+	var (
+		messages  = make([]testMessage, 0, count)
+		at        = start
+		agentTurn = false
+	)
+	for i := 0; i < count; i++ {
+		m := testMessage{
+			typ:        "incoming",
+			status:     "received",
+			content:    pick(testContactMessages),
+			senderID:   contactID,
+			senderType: "contact",
+			createdAt:  at,
+		}
+		if agentTurn && agentID > 0 {
+			m.typ, m.status, m.senderID, m.senderType = "outgoing", "sent", agentID, "agent"
+			m.content = pick(testAgentMessages)
+			if rand.IntN(10) == 0 {
+				m.private = true
+				m.content = pick(testPrivateNotes)
+			}
+		}
+		messages = append(messages, m)
+		agentTurn = !agentTurn
+		at = at.Add(time.Duration(5+rand.IntN(24*60)) * time.Minute)
+		if at.After(now) {
+			break
+		}
+	}
+	return messages
+}
+
+// reserveIDs reserves n IDs from the ID sequence of the given table.
+func reserveIDs(tx *sqlx.Tx, table string, n int) ([]int64, error) {
+	var ids []int64
+	if err := tx.Select(&ids, `SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)`, table, n); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// closeCopy flushes and closes a COPY statement.
+func closeCopy(stmt *sql.Stmt) error {
+	if _, err := stmt.Exec(); err != nil {
+		return err
+	}
+	return stmt.Close()
+}
+
+func pick(items []string) string {
+	return items[rand.IntN(len(items))]
+}
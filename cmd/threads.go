@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+const maxThreadNameLength = 140
+
+type threadReq struct {
+	Name           string `json:"name"`
+	ParticipantIDs []int  `json:"participant_ids"`
+	Resolved       bool   `json:"resolved"`
+}
+
+// handleGetThreads returns the internal threads of a conversation.
+func handleGetThreads(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	threads, err := app.conversation.GetThreads(conversation.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(threads)
+}
+
+// handleCreateThread creates an internal thread in a conversation.
+func handleCreateThread(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = threadReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := validateThread(app, &req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	thread, err := app.conversation.CreateThread(conversation.ID, req.Name, user.ID, req.ParticipantIDs)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(thread)
+}
+
+// handleUpdateThread renames an internal thread, replaces its participants and sets its resolution state.
+func handleUpdateThread(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = threadReq{}
+	)
+	id, _ := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := validateThread(app, &req); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	thread, err := app.conversation.GetThread(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if thread.ConversationID != conversation.ID {
+		return r.SendErrorEnvelope(fasthttp.StatusNotFound, app.i18n.T("globals.messages.notFound"), nil, envelope.NotFoundError)
+	}
+
+	thread, err = app.conversation.UpdateThread(id, req.Name, req.ParticipantIDs, req.Resolved, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(thread)
+}
+
+// validateThread trims and validates a thread request. Participants must be agents.
+func validateThread(app *App, req *threadReq) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if utf8.RuneCountInString(req.Name) > maxThreadNameLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxThreadNameLength)), nil)
+	}
+	seen := make(map[int]struct{}, len(req.ParticipantIDs))
+	ids := make([]int, 0, len(req.ParticipantIDs))
+	for _, id := range req.ParticipantIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if _, err := app.user.GetAgent(id, ""); err != nil {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+		ids = append(ids, id)
+	}
+	req.ParticipantIDs = ids
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"cmp"
+	"strconv"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+type totpCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// handleSetupTOTP generates a TOTP secret for the current agent to add to an authenticator app.
+func handleSetupTOTP(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		auser  = r.RequestCtx.UserValue("user").(amodels.User)
+		consts = app.consts.Load().(*constants)
+	)
+	secret, uri, err := app.user.SetupTOTP(auser.ID, cmp.Or(consts.SiteName, "Libredesk"))
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(map[string]string{
+		"secret": secret,
+		"uri":    uri,
+	})
+}
+
+// handleEnableTOTP enables two-factor authentication for the current agent after confirming a code,
+// returning the recovery codes. The codes are only shown once.
+func handleEnableTOTP(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = totpCodeRequest{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	codes, err := app.user.EnableTOTP(auser.ID, req.Code)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	app.lo.Info("two-factor authentication enabled", "user_id", auser.ID)
+	return r.SendEnvelope(map[string][]string{"recovery_codes": codes})
+}
+
+// handleDisableTOTP disables two-factor authentication for the current agent after confirming a code.
+// Agents whose roles require two-factor authentication cannot disable it.
+func handleDisableTOTP(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = totpCodeRequest{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	agent, err := app.user.GetAgent(auser.ID, "")
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if agent.TwoFactorRequired {
+		return r.SendErrorEnvelope(fasthttp.StatusForbidden, app.i18n.T("user.twoFactorRequiredByRole"), nil, envelope.PermissionError)
+	}
+	if err := app.user.VerifyTOTP(auser.ID, req.Code); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.user.DisableTOTP(auser.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	app.lo.Info("two-factor authentication disabled", "user_id", auser.ID)
+	return r.SendEnvelope(true)
+}
+
+// handleRegenerateRecoveryCodes replaces the recovery codes of the current agent after confirming a code.
+func handleRegenerateRecoveryCodes(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = totpCodeRequest{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := app.user.VerifyTOTP(auser.ID, req.Code); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	codes, err := app.user.RegenerateRecoveryCodes(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(map[string][]string{"recovery_codes": codes})
+}
+
+// handleResetAgentTOTP disables two-factor authentication for an agent who lost access to their authenticator
+// and recovery codes. Agents whose roles require it are asked to enroll again on their next password login.
+func handleResetAgentTOTP(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if _, err := app.user.GetAgent(id, ""); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.user.DisableTOTP(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	app.lo.Info("two-factor authentication reset", "user_id", id, "actor_id", auser.ID)
+	return r.SendEnvelope(true)
+}
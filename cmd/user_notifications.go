@@ -2,10 +2,13 @@ package main
 
 import (
 	"strconv"
+	"strings"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	nmodels "github.com/abhinavxd/libredesk/internal/notification/models"
 	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/fastglue"
 )
 
@@ -97,3 +100,66 @@ func handleDeleteAllNotifications(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(true)
 }
+
+// deviceTokenReq is the request body for registering a push device token.
+type deviceTokenReq struct {
+	Platform   string      `json:"platform"`
+	Token      string      `json:"token"`
+	DeviceName null.String `json:"device_name"`
+}
+
+func handleGetDeviceTokens(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	tokens, err := app.userNotification.GetDeviceTokens(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(tokens)
+}
+
+func handleRegisterDeviceToken(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = deviceTokenReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.Ts("globals.messages.empty", "name", "`token`"), nil, envelope.InputError)
+	}
+	if len(req.Token) > 4096 || len(req.DeviceName.String) > 255 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+	if req.Platform != nmodels.PlatformAPNs && req.Platform != nmodels.PlatformFCM {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+	}
+
+	token, err := app.userNotification.RegisterDeviceToken(auser.ID, req.Platform, req.Token, req.DeviceName)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(token)
+}
+
+func handleDeleteDeviceToken(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest,
+			app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+
+	if err := app.userNotification.DeleteDeviceToken(id, auser.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
@@ -15,6 +15,7 @@ import (
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
 	tmpl "github.com/abhinavxd/libredesk/internal/template"
+	"github.com/abhinavxd/libredesk/internal/user"
 	"github.com/abhinavxd/libredesk/internal/user/models"
 	realip "github.com/ferluci/fast-realip"
 	"github.com/valyala/fasthttp"
@@ -351,6 +352,116 @@ func handleGetCurrentAgent(r *fastglue.Request) error {
 	return r.SendEnvelope(u)
 }
 
+// handleGetCurrentAgentKeyboardBindings returns the keyboard bindings of the current agent.
+func handleGetCurrentAgentKeyboardBindings(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	bindings, err := app.user.GetKeyboardBindings(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(bindings)
+}
+
+// handleGetCurrentAgentNotificationPreferences returns the notification preferences of the current agent.
+func handleGetCurrentAgentNotificationPreferences(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	prefs, err := app.user.GetAgentNotificationPreferences(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
+
+// handleUpdateCurrentAgentNotificationPreferences updates the notification preferences of the current agent.
+func handleUpdateCurrentAgentNotificationPreferences(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   models.AgentNotificationPreferences
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	prefs, err := app.user.UpdateAgentNotificationPreferences(auser.ID, req)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
+
+// handleGetCurrentAgentSignature returns the email signature of the current agent.
+func handleGetCurrentAgentSignature(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	signature, err := app.user.GetSignature(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(map[string]string{"signature": signature})
+}
+
+// handleUpdateCurrentAgentSignature updates the email signature of the current agent.
+func handleUpdateCurrentAgentSignature(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   struct {
+			Signature string `json:"signature"`
+		}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	signature, err := app.user.UpdateSignature(auser.ID, req.Signature)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(map[string]string{"signature": signature})
+}
+
+// handleUpdateCurrentAgentKeyboardBindings replaces the keyboard bindings of the current agent.
+func handleUpdateCurrentAgentKeyboardBindings(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		auser    = r.RequestCtx.UserValue("user").(amodels.User)
+		bindings = []models.KeyboardBinding{}
+	)
+	if err := r.Decode(&bindings, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+
+	// Bound macros and statuses must exist.
+	for _, b := range bindings {
+		id, err := strconv.Atoi(b.Target)
+		if err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidValue"), nil, envelope.InputError)
+		}
+		switch b.Action {
+		case user.KeyboardActionMacro:
+			_, err = app.macro.Get(id)
+		case user.KeyboardActionStatus:
+			_, err = app.status.Get(id)
+		}
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
+	bindings, err := app.user.SetKeyboardBindings(auser.ID, bindings)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(bindings)
+}
+
 // handleDeleteCurrentAgentAvatar deletes the current agent's avatar.
 func handleDeleteCurrentAgentAvatar(r *fastglue.Request) error {
 	var (
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	tmpl "github.com/abhinavxd/libredesk/internal/template"
+	"github.com/abhinavxd/libredesk/internal/variant/models"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	maxVariantNameLength    = 140
+	maxVariantContentLength = 10000
+	maxVariantWeight        = 100
+	defaultVariantStatsDays = 30
+)
+
+// handleGetVariants returns the variants of a template or an autoresponder.
+func handleGetVariants(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	templateID, autoresponderID, err := variantOwner(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	variants, err := app.variant.GetAll(templateID, autoresponderID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(variants)
+}
+
+// handleCreateVariant creates a variant of a template or an autoresponder.
+func handleCreateVariant(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		variant = models.Variant{}
+	)
+	templateID, autoresponderID, err := variantOwner(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := r.Decode(&variant, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateVariant(app, &variant); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	variant.TemplateID, variant.AutoresponderID = variantOwnerIDs(templateID, autoresponderID)
+
+	result, err := app.variant.Create(variant)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateVariant updates a variant of a template or an autoresponder.
+func handleUpdateVariant(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		id, _   = strconv.Atoi(r.RequestCtx.UserValue("variant_id").(string))
+		variant = models.Variant{}
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	templateID, autoresponderID, err := variantOwner(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := r.Decode(&variant, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateVariant(app, &variant); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	variant.TemplateID, variant.AutoresponderID = variantOwnerIDs(templateID, autoresponderID)
+
+	result, err := app.variant.Update(id, variant)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteVariant deletes a variant of a template or an autoresponder.
+func handleDeleteVariant(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("variant_id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	templateID, autoresponderID, err := variantOwner(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.variant.Delete(id, templateID, autoresponderID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetVariantStats returns the sends, replies and completed CSAT surveys of each variant of a
+// template or an autoresponder over the last days.
+func handleGetVariantStats(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		days, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("days")))
+	)
+	if days <= 0 {
+		days = defaultVariantStatsDays
+	}
+	templateID, autoresponderID, err := variantOwner(r)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	stats, err := app.variant.GetStats(templateID, autoresponderID, days)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(stats)
+}
+
+// variantOwner returns the ID of the template or, on autoresponder routes, the autoresponder the
+// variants of the request belong to. Only the CSAT request template supports variants.
+func variantOwner(r *fastglue.Request) (int, int, error) {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return 0, 0, envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+
+	if arID, ok := r.RequestCtx.UserValue("autoresponder_id").(string); ok {
+		autoresponderID, _ := strconv.Atoi(arID)
+		if autoresponderID <= 0 {
+			return 0, 0, envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+		if _, err := app.autoresponder.Get(id, autoresponderID); err != nil {
+			return 0, 0, err
+		}
+		return 0, autoresponderID, nil
+	}
+
+	t, err := app.tmpl.Get(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	if t.Name != tmpl.TmplCSATRequest {
+		return 0, 0, envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	return id, 0, nil
+}
+
+// variantOwnerIDs returns the nullable owner columns of a variant.
+func variantOwnerIDs(templateID, autoresponderID int) (null.Int, null.Int) {
+	if templateID > 0 {
+		return null.IntFrom(templateID), null.Int{}
+	}
+	return null.Int{}, null.IntFrom(autoresponderID)
+}
+
+func validateVariant(app *App, v *models.Variant) error {
+	v.Name = strings.TrimSpace(v.Name)
+	if v.Name == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if len(v.Name) > maxVariantNameLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxVariantNameLength)), nil)
+	}
+	if strings.TrimSpace(v.Content) == "" {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`content`"), nil)
+	}
+	if len(v.Content) > maxVariantContentLength {
+		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.maxLength", "max", strconv.Itoa(maxVariantContentLength)), nil)
+	}
+	if v.Weight < 0 || v.Weight > maxVariantWeight {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	return nil
+}
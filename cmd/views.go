@@ -1,10 +1,12 @@
 package main
 
 import (
+	"slices"
 	"strconv"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	vmodels "github.com/abhinavxd/libredesk/internal/view/models"
 	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
@@ -24,6 +26,9 @@ func handleGetUserViews(r *fastglue.Request) error {
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
+	if err := app.view.AttachPreferences(user.ID, v); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
 	return r.SendEnvelope(v)
 }
 
@@ -121,6 +126,36 @@ func handleUpdateUserView(r *fastglue.Request) error {
 	return r.SendEnvelope(updatedView)
 }
 
+// handleGetViewPreferences returns the current user's sort and page size preferences of conversation lists.
+func handleGetViewPreferences(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	prefs, err := app.view.GetPreferences(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(prefs)
+}
+
+// handleUpdateViewPreference saves the current user's sort and page size preference of a conversation list.
+func handleUpdateViewPreference(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		pref  = vmodels.Preference{}
+	)
+	if err := r.Decode(&pref, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	saved, err := app.view.UpdatePreference(auser.ID, pref)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(saved)
+}
+
 // handleGetSharedViews returns shared views accessible to the current user.
 func handleGetSharedViews(r *fastglue.Request) error {
 	var (
@@ -135,6 +170,9 @@ func handleGetSharedViews(r *fastglue.Request) error {
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
+	if err := app.view.AttachPreferences(user.ID, views); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
 	return r.SendEnvelope(views)
 }
 
@@ -272,3 +310,57 @@ func validateSharedView(app *App, view vmodels.View) error {
 	}
 	return nil
 }
+
+// canAccessView returns true if the agent can see the view.
+func canAccessView(view vmodels.View, user umodels.User) bool {
+	switch view.Visibility {
+	case vmodels.VisibilityUser:
+		return view.UserID != nil && *view.UserID == user.ID
+	case vmodels.VisibilityAll:
+		return true
+	case vmodels.VisibilityTeam:
+		return view.TeamID != nil && slices.Contains(user.Teams.IDs(), *view.TeamID)
+	}
+	return false
+}
+
+// viewSubscriptions evaluates the views websocket clients subscribe to, with the same access checks
+// and list query as handleGetViewConversations.
+type viewSubscriptions struct {
+	app *App
+}
+
+// CanAccessView returns true if the agent can see the view.
+func (v viewSubscriptions) CanAccessView(userID, viewID int) bool {
+	view, err := v.app.view.Get(viewID)
+	if err != nil {
+		return false
+	}
+	user, err := v.app.user.GetAgent(userID, "")
+	if err != nil {
+		return false
+	}
+	return canAccessView(view, user)
+}
+
+// ConversationInView returns true if the conversation is listed in the view for the agent.
+func (v viewSubscriptions) ConversationInView(userID, viewID int, conversationUUID string) bool {
+	view, err := v.app.view.Get(viewID)
+	if err != nil {
+		return false
+	}
+	user, err := v.app.user.GetAgent(userID, "")
+	if err != nil || !canAccessView(view, user) {
+		return false
+	}
+	lists := readableConversationLists(user)
+	if len(lists) == 0 {
+		return false
+	}
+	ok, err := v.app.conversation.ConversationInViewList(user.ID, user.ID, user.Teams.IDs(), lists, string(view.Filters), conversationUUID)
+	if err != nil {
+		v.app.lo.Error("error matching conversation to view", "view_id", viewID, "uuid", conversationUUID, "error", err)
+		return false
+	}
+	return ok
+}
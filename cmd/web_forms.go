@@ -0,0 +1,328 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/captcha"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/inbox"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/webform"
+	"github.com/abhinavxd/libredesk/internal/webform/models"
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+const (
+	maxWebFormAttachments   = 5
+	maxWebFormNameLength    = 280
+	maxWebFormSubjectLength = 500
+	maxWebFormMessageLength = 20000
+)
+
+// handleGetWebForms returns all web forms.
+func handleGetWebForms(r *fastglue.Request) error {
+	app := r.Context.(*App)
+	forms, err := app.webform.GetAll()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(forms)
+}
+
+// handleGetWebForm returns a web form by ID.
+func handleGetWebForm(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	form, err := app.webform.Get(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(form)
+}
+
+// handleCreateWebForm creates a web form.
+func handleCreateWebForm(r *fastglue.Request) error {
+	var (
+		app  = r.Context.(*App)
+		form = models.Form{}
+	)
+	if err := r.Decode(&form, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateWebFormInbox(app, form.InboxID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	result, err := app.webform.Create(form)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleUpdateWebForm updates a web form.
+func handleUpdateWebForm(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+		form  = models.Form{}
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := r.Decode(&form, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), err.Error(), envelope.InputError)
+	}
+	if err := validateWebFormInbox(app, form.InboxID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	result, err := app.webform.Update(id, form)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(result)
+}
+
+// handleDeleteWebForm deletes a web form.
+func handleDeleteWebForm(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		id, _ = strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	)
+	if id <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.InputError)
+	}
+	if err := app.webform.Delete(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// validateWebFormInbox makes sure submissions of a form can be replied to, which
+// requires an email inbox as form contacts have no widget session.
+func validateWebFormInbox(app *App, inboxID int) error {
+	if inboxID <= 0 {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidInbox"), nil)
+	}
+	inb, err := app.inbox.GetDBRecord(inboxID)
+	if err != nil {
+		return err
+	}
+	if inb.Channel != inbox.ChannelEmail {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidInbox"), nil)
+	}
+	return nil
+}
+
+// handleShowWebForm renders the public page of a web form.
+func handleShowWebForm(r *fastglue.Request) error {
+	var (
+		app  = r.Context.(*App)
+		uuid = r.RequestCtx.UserValue("uuid").(string)
+	)
+	form, err := app.webform.GetByUUID(uuid)
+	if err != nil || !form.Enabled {
+		return renderWebFormNotFound(app, r)
+	}
+	return renderWebForm(app, r, form, map[string]string{}, "", "")
+}
+
+// handleSubmitWebForm validates a web form submission and enqueues it as an incoming
+// message on the form's inbox, which creates a new conversation.
+func handleSubmitWebForm(r *fastglue.Request) error {
+	var (
+		app      = r.Context.(*App)
+		formUUID = r.RequestCtx.UserValue("uuid").(string)
+	)
+	form, err := app.webform.GetByUUID(formUUID)
+	if err != nil || !form.Enabled {
+		return renderWebFormNotFound(app, r)
+	}
+	successMsg := cmp.Or(form.SuccessMessage, app.i18n.T("globals.messages.thankYou"))
+
+	mf, err := r.RequestCtx.MultipartForm()
+	if err != nil {
+		return renderWebForm(app, r, form, map[string]string{}, app.i18n.T("errors.parsingRequest"), "")
+	}
+	values := make(map[string]string, len(mf.Value))
+	for k, v := range mf.Value {
+		if len(v) > 0 {
+			values[k] = strings.TrimSpace(v[0])
+		}
+	}
+
+	// Bots fill every input. Pretend the submission went through so that they don't retry.
+	if values[honeypotField] != "" {
+		app.lo.Info("dropping web form submission with filled honeypot", "form_id", form.ID)
+		return renderWebForm(app, r, form, values, "", successMsg)
+	}
+	if !verifyCaptcha(app, r, captcha.EndpointWebForm) {
+		return renderWebForm(app, r, form, values, app.i18n.T("validation.captchaFailed"), "")
+	}
+
+	var (
+		name    = values["name"]
+		email   = strings.ToLower(values["email"])
+		subject = values["subject"]
+		message = values["message"]
+	)
+	switch {
+	case name == "":
+		return renderWebForm(app, r, form, values, app.i18n.Ts("globals.messages.required", "name", "{globals.terms.name}"), "")
+	case len(name) > maxWebFormNameLength:
+		return renderWebForm(app, r, form, values, app.i18n.Ts("globals.messages.maxLength", "name", "{globals.terms.name}", "max", strconv.Itoa(maxWebFormNameLength)), "")
+	case !stringutil.ValidEmail(email):
+		return renderWebForm(app, r, form, values, app.i18n.T("validation.invalidEmail"), "")
+	case len(subject) > maxWebFormSubjectLength:
+		return renderWebForm(app, r, form, values, app.i18n.Ts("globals.messages.maxLength", "name", "{globals.terms.subject}", "max", strconv.Itoa(maxWebFormSubjectLength)), "")
+	case message == "":
+		return renderWebForm(app, r, form, values, app.i18n.T("validation.messageCannotBeEmpty"), "")
+	case len(message) > maxWebFormMessageLength:
+		return renderWebForm(app, r, form, values, app.i18n.Ts("globals.messages.maxLength", "name", "{globals.terms.message}", "max", strconv.Itoa(maxWebFormMessageLength)), "")
+	}
+
+	fields, err := webform.FormatFields(form, values)
+	if err != nil {
+		msg := app.i18n.T("validation.invalidValue")
+		if fe, ok := err.(*webform.FieldError); ok && errors.Is(fe, webform.ErrRequired) {
+			msg = app.i18n.Ts("globals.messages.required", "name", fe.Field.Label)
+		}
+		return renderWebForm(app, r, form, values, msg, "")
+	}
+
+	var attachments attachment.Attachments
+	if form.AllowAttachments {
+		attachments, err = readWebFormAttachments(app, mf.File["attachments"])
+		if err != nil {
+			return renderWebForm(app, r, form, values, err.Error(), "")
+		}
+	}
+
+	inb, err := app.inbox.GetDBRecord(form.InboxID)
+	if err != nil || !inb.Enabled {
+		app.lo.Error("web form inbox unavailable", "form_id", form.ID, "inbox_id", form.InboxID, "error", err)
+		return renderWebForm(app, r, form, values, app.i18n.T("globals.messages.errorSendingMessage"), "")
+	}
+
+	content := message
+	if fields != "" {
+		content += "\n\n" + fields
+	}
+	firstName, lastName, _ := strings.Cut(name, " ")
+	if err := app.conversation.EnqueueIncoming(cmodels.IncomingMessage{
+		Channel: inb.Channel,
+		InboxID: inb.ID,
+		Contact: cmodels.IncomingContact{
+			FirstName: firstName,
+			LastName:  strings.TrimSpace(lastName),
+			Email:     null.StringFrom(email),
+		},
+		Subject:     cmp.Or(subject, form.Name),
+		SourceID:    null.StringFrom(fmt.Sprintf("<webform-%s@%s>", uuid.NewString(), form.UUID)),
+		Content:     content,
+		ContentType: cmodels.ContentTypeText,
+		Attachments: attachments,
+	}); err != nil {
+		app.lo.Error("error enqueuing web form submission", "form_id", form.ID, "error", err)
+		return renderWebForm(app, r, form, values, app.i18n.T("globals.messages.errorSendingMessage"), "")
+	}
+
+	return renderWebForm(app, r, form, values, "", successMsg)
+}
+
+// readWebFormAttachments reads and validates the files uploaded with a web form.
+func readWebFormAttachments(app *App, files []*multipart.FileHeader) (attachment.Attachments, error) {
+	if len(files) > maxWebFormAttachments {
+		return nil, errors.New(app.i18n.T("validation.invalidValue"))
+	}
+	var (
+		consts      = app.consts.Load().(*constants)
+		attachments = make(attachment.Attachments, 0, len(files))
+	)
+	for _, fh := range files {
+		name := stringutil.SanitizeFilename(fh.Filename)
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+		if fh.Size <= 0 {
+			return nil, errors.New(app.i18n.T("media.fileEmpty"))
+		}
+		if bytesToMegabytes(fh.Size) > float64(consts.MaxFileUploadSizeMB) {
+			return nil, errors.New(app.i18n.Ts("media.fileSizeTooLarge", "size", fmt.Sprintf("%dMB", consts.MaxFileUploadSizeMB)))
+		}
+		if !slices.Contains(consts.AllowedUploadFileExtensions, "*") && !slices.Contains(consts.AllowedUploadFileExtensions, ext) {
+			return nil, errors.New(app.i18n.T("media.fileTypeNotAllowed"))
+		}
+
+		file, err := fh.Open()
+		if err != nil {
+			app.lo.Error("error reading uploaded file", "error", err)
+			return nil, errors.New(app.i18n.T("globals.messages.somethingWentWrong"))
+		}
+		content, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			app.lo.Error("error reading file content", "error", err)
+			return nil, errors.New(app.i18n.T("globals.messages.somethingWentWrong"))
+		}
+		attachments = append(attachments, attachment.Attachment{
+			Name:        name,
+			ContentType: fh.Header.Get("Content-Type"),
+			Size:        int(fh.Size),
+			Content:     content,
+			Disposition: attachment.DispositionAttachment,
+		})
+	}
+	return attachments, nil
+}
+
+// renderWebForm renders the web form page, setting the frame-ancestors policy of the form.
+func renderWebForm(app *App, r *fastglue.Request, form models.Form, values map[string]string, errMsg, success string) error {
+	if len(form.TrustedDomains) > 0 {
+		r.RequestCtx.Response.Header.Set("Content-Security-Policy", webFormFrameAncestors(form.TrustedDomains))
+	}
+	return app.tmpl.RenderWebPage(r.RequestCtx, "web-form", map[string]interface{}{
+		"Data": map[string]interface{}{
+			"Form":    form,
+			"Values":  values,
+			"Error":   errMsg,
+			"Success": success,
+			"Captcha": getCaptchaWidget(app, captcha.EndpointWebForm),
+		},
+	})
+}
+
+// webFormFrameAncestors returns the frame-ancestors policy allowing the trusted domains of a form.
+// Domains are validated on save, invalid ones saved before that are skipped instead of breaking the header.
+func webFormFrameAncestors(domains []string) string {
+	policy := "frame-ancestors 'self'"
+	for _, d := range domains {
+		if webform.ValidTrustedDomain(d) {
+			policy += " " + d
+		}
+	}
+	return policy
+}
+
+func renderWebFormNotFound(app *App, r *fastglue.Request) error {
+	return app.tmpl.RenderWebPage(r.RequestCtx, "error", map[string]interface{}{
+		"Data": map[string]interface{}{
+			"ErrorMessage": app.i18n.T("globals.messages.pageNotFound"),
+		},
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/knadh/go-i18n"
+	"github.com/zerodha/logf"
+)
+
+// webFormFiles returns the file headers of a multipart form with a file of the given size per name.
+func webFormFiles(t *testing.T, files map[string]int) []*multipart.FileHeader {
+	t.Helper()
+	var (
+		buf bytes.Buffer
+		w   = multipart.NewWriter(&buf)
+	)
+	for name, size := range files {
+		fw, err := w.CreateFormFile("attachments", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(bytes.Repeat([]byte("a"), size))
+	}
+	w.Close()
+	form, err := multipart.NewReader(&buf, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["attachments"]
+}
+
+func TestReadWebFormAttachments(t *testing.T) {
+	i, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo := logf.New(logf.Opts{})
+	app := &App{i18n: i, lo: &lo}
+	app.consts.Store(&constants{MaxFileUploadSizeMB: 1, AllowedUploadFileExtensions: []string{"pdf", "txt"}})
+
+	tests := []struct {
+		name    string
+		files   map[string]int
+		want    int
+		wantErr bool
+	}{
+		{"none", nil, 0, false},
+		{"allowed", map[string]int{"a.pdf": 10, "b.TXT": 10}, 2, false},
+		{"at the count limit", map[string]int{"1.txt": 1, "2.txt": 1, "3.txt": 1, "4.txt": 1, "5.txt": 1}, maxWebFormAttachments, false},
+		{"over the count limit", map[string]int{"1.txt": 1, "2.txt": 1, "3.txt": 1, "4.txt": 1, "5.txt": 1, "6.txt": 1}, 0, true},
+		{"at the size limit", map[string]int{"a.pdf": 1 << 20}, 1, false},
+		{"over the size limit", map[string]int{"a.pdf": 1<<20 + 1}, 0, true},
+		{"empty file", map[string]int{"a.pdf": 0}, 0, true},
+		{"extension not allowed", map[string]int{"a.exe": 10}, 0, true},
+		{"no extension", map[string]int{"pdf": 10}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readWebFormAttachments(app, webFormFiles(t, tt.files))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readWebFormAttachments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("readWebFormAttachments() = %d attachments, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestWebFormFrameAncestors(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []string
+		want    string
+	}{
+		{"domains", []string{"example.com", "*.example.org:8443"}, "frame-ancestors 'self' example.com *.example.org:8443"},
+		{"invalid domains skipped", []string{"example.com; script-src *", "a.com\r\nX-A: b", "'unsafe-inline'", "example.net"}, "frame-ancestors 'self' example.net"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := webFormFrameAncestors(tt.domains)
+			if got != tt.want {
+				t.Errorf("webFormFrameAncestors() = %q, want %q", got, tt.want)
+			}
+			if strings.ContainsAny(got, ";\r\n") {
+				t.Errorf("webFormFrameAncestors() = %q, contains a policy or header separator", got)
+			}
+		})
+	}
+}
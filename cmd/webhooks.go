@@ -1,6 +1,8 @@
 package main
 
 import (
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,6 +13,12 @@ import (
 	"github.com/zerodha/fastglue"
 )
 
+// maxWebhookFieldPaths caps the payload fields included and excluded by a webhook.
+const maxWebhookFieldPaths = 50
+
+// webhookFieldPathRe matches a dot separated path of payload fields, e.g. `contact.email`.
+var webhookFieldPathRe = regexp.MustCompile(`^[a-zA-Z0-9_]+(\.[a-zA-Z0-9_]+)*$`)
+
 // handleGetWebhooks returns all webhooks from the database.
 func handleGetWebhooks(r *fastglue.Request) error {
 	var (
@@ -178,5 +186,13 @@ func validateWebhook(app *App, webhook models.Webhook) error {
 	if len(webhook.Events) == 0 {
 		return envelope.NewError(envelope.InputError, app.i18n.Ts("globals.messages.empty", "name", "`events`"), nil)
 	}
+	if len(webhook.IncludeFields)+len(webhook.ExcludeFields) > maxWebhookFieldPaths {
+		return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+	}
+	for _, p := range slices.Concat(webhook.IncludeFields, webhook.ExcludeFields) {
+		if !webhookFieldPathRe.MatchString(p) {
+			return envelope.NewError(envelope.InputError, app.i18n.T("validation.invalidValue"), nil)
+		}
+	}
 	return nil
 }
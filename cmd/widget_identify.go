@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// handleChatIdentify records the email address of an anonymous visitor.
+//
+// If the email belongs to an existing contact, the visitor's conversations are moved to that
+// contact's history and the visitor is deleted. As the email isn't verified, the widget session
+// ends instead of being handed the contact's identity, and the conversation continues over email.
+// Otherwise the email is saved on the visitor and the session continues.
+func handleChatIdentify(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = struct {
+			Email string `json:"email"`
+		}{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("errors.parsingRequest"), nil, envelope.InputError)
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if len(req.Email) > maxEmailLength || !stringutil.ValidEmail(req.Email) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("validation.invalidEmail"), nil, envelope.InputError)
+	}
+
+	visitorID, err := getWidgetContactID(r)
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusUnauthorized, app.i18n.T("globals.terms.unAuthorized"), nil, envelope.UnauthorizedError)
+	}
+	if !getWidgetIsVisitor(r) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, app.i18n.T("widget.alreadyIdentified"), nil, envelope.InputError)
+	}
+	visitor, err := app.user.Get(visitorID, "", []string{umodels.UserTypeVisitor})
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	contact, err := app.user.GetContactByEmail(req.Email)
+	if err != nil {
+		if envErr, ok := err.(envelope.Error); !ok || envErr.ErrorType != envelope.NotFoundError {
+			return sendErrorEnvelope(r, err)
+		}
+		// No existing contact, keep the email on the visitor so that replies can reach them.
+		if err := app.user.UpdateContactBasicInfo(visitorID, "", "", req.Email); err != nil {
+			return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+		}
+		return r.SendEnvelope(map[string]any{"merged": false})
+	}
+
+	inbox, err := getWidgetInbox(r)
+	if err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, app.i18n.T("globals.messages.somethingWentWrong"), nil, envelope.GeneralError)
+	}
+	conversations, err := app.conversation.GetContactChatConversations(visitorID, inbox.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.user.MergeVisitorToContact(visitorID, contact.ID); err != nil {
+		return sendErrorEnvelope(r, envelope.NewError(envelope.GeneralError, app.i18n.T("globals.messages.somethingWentWrong"), nil))
+	}
+	app.lo.Info("merged identified visitor to contact", "visitor_id", visitorID, "contact_id", contact.ID, "conversations", len(conversations))
+
+	// Record the merge on the moved conversations.
+	uuids := make([]string, 0, len(conversations))
+	systemUser, err := app.user.GetSystemUser()
+	if err != nil {
+		app.lo.Error("error fetching system user", "error", err)
+	}
+	for _, c := range conversations {
+		uuids = append(uuids, c.UUID)
+		if systemUser.ID == 0 {
+			continue
+		}
+		if err := app.conversation.InsertConversationActivity(cmodels.ActivityContactIdentified, c.UUID, req.Email, systemUser); err != nil {
+			app.lo.Error("error recording contact identified activity", "conversation_uuid", c.UUID, "error", err)
+		}
+	}
+	app.webhook.TriggerEvent(wmodels.EventContactMerged, map[string]any{
+		"visitor_id":         visitor.ID,
+		"contact_id":         contact.ID,
+		"email":              req.Email,
+		"inbox_id":           inbox.ID,
+		"conversation_uuids": uuids,
+	})
+
+	// End the visitor's session, it no longer has a user.
+	token := strings.TrimPrefix(string(r.RequestCtx.Request.Header.Peek("Authorization")), "Bearer ")
+	deleteSessionToken(app, token)
+
+	return r.SendEnvelope(map[string]any{"merged": true})
+}
@@ -58,7 +58,22 @@ const deleteCustomAttribute = (id) => http.delete(`/api/v1/custom-attributes/${i
 const searchConversations = (params) => http.get('/api/v1/conversations/search', { params })
 const searchMessages = (params) => http.get('/api/v1/messages/search', { params })
 const searchContacts = (params) => http.get('/api/v1/contacts/search', { params })
+const searchMentions = (params) => http.get('/api/v1/mentions/search', { params })
 const getEmailNotificationSettings = () => http.get('/api/v1/settings/notifications/email')
+const getCaptchaSettings = () => http.get('/api/v1/settings/captcha')
+const updateCaptchaSettings = (data) =>
+  http.put('/api/v1/settings/captcha', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getAbuseSettings = () => http.get('/api/v1/settings/abuse')
+const updateAbuseSettings = (data) =>
+  http.put('/api/v1/settings/abuse', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
 const updateEmailNotificationSettings = (data) =>
   http.put('/api/v1/settings/notifications/email', data)
 const getPriorities = () => http.get('/api/v1/priorities')
@@ -66,11 +81,16 @@ const getStatuses = () => http.get('/api/v1/statuses')
 const createStatus = (data) => http.post('/api/v1/statuses', data)
 const updateStatus = (id, data) => http.put(`/api/v1/statuses/${id}`, data)
 const deleteStatus = (id) => http.delete(`/api/v1/statuses/${id}`)
+const getSnoozePresets = () => http.get('/api/v1/snooze-presets')
+const createSnoozePreset = (data) => http.post('/api/v1/snooze-presets', data)
+const updateSnoozePreset = (id, data) => http.put(`/api/v1/snooze-presets/${id}`, data)
+const deleteSnoozePreset = (id) => http.delete(`/api/v1/snooze-presets/${id}`)
 const createTag = (data) => http.post('/api/v1/tags', data)
 const updateTag = (id, data) => http.put(`/api/v1/tags/${id}`, data)
 const deleteTag = (id) => http.delete(`/api/v1/tags/${id}`)
 const getTemplate = (id) => http.get(`/api/v1/templates/${id}`)
 const getTemplates = (type) => http.get('/api/v1/templates', { params: { type: type } })
+const getTemplateVariables = () => http.get('/api/v1/templates/variables')
 const createTemplate = (data) =>
   http.post('/api/v1/templates', data, {
     headers: {
@@ -84,6 +104,23 @@ const updateTemplate = (id, data) =>
       'Content-Type': 'application/json'
     }
   })
+const getTemplateVariants = (id) => http.get(`/api/v1/templates/${id}/variants`)
+const getTemplateVariantStats = (id, params) =>
+  http.get(`/api/v1/templates/${id}/variants/stats`, { params })
+const createTemplateVariant = (id, data) =>
+  http.post(`/api/v1/templates/${id}/variants`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateTemplateVariant = (id, variantId, data) =>
+  http.put(`/api/v1/templates/${id}/variants/${variantId}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteTemplateVariant = (id, variantId) =>
+  http.delete(`/api/v1/templates/${id}/variants/${variantId}`)
 
 const getAllBusinessHours = () => http.get('/api/v1/business-hours')
 const getBusinessHours = (id) => http.get(`/api/v1/business-hours/${id}`)
@@ -116,6 +153,21 @@ const updateSLA = (id, data) =>
     }
   })
 const deleteSLA = (id) => http.delete(`/api/v1/sla/${id}`)
+const getSLAIncidents = () => http.get('/api/v1/sla/incidents')
+const getSLAIncident = (id) => http.get(`/api/v1/sla/incidents/${id}`)
+const createSLAIncident = (data) =>
+  http.post('/api/v1/sla/incidents', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateSLAIncident = (id, data) =>
+  http.put(`/api/v1/sla/incidents/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteSLAIncident = (id) => http.delete(`/api/v1/sla/incidents/${id}`)
 const createOIDC = (data) =>
   http.post('/api/v1/oidc', data, {
     headers: {
@@ -163,6 +215,13 @@ const createAutomationRule = (data) =>
     }
   })
 const deleteAutomationRule = (id) => http.delete(`/api/v1/automations/rules/${id}`)
+const getAutomationRuleLogs = (id) => http.get(`/api/v1/automations/rules/${id}/logs`)
+const dryRunAutomationRule = (data) =>
+  http.post(`/api/v1/automations/rules/dry-run`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
 const updateAutomationRuleWeights = (data) =>
   http.put(`/api/v1/automations/rules/weights`, data, {
     headers: {
@@ -203,6 +262,18 @@ const blockContact = (id, data) => http.put(`/api/v1/contacts/${id}/block`, data
     'Content-Type': 'application/json'
   }
 })
+const updateContactRiskFlags = (id, data) =>
+  http.put(`/api/v1/contacts/${id}/risk-flags`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getContactPreferences = (id) => http.get(`/api/v1/contacts/${id}/preferences`)
+const updateContactPreferences = (id, data) => http.put(`/api/v1/contacts/${id}/preferences`, data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
 const getTeam = (id) => http.get(`/api/v1/teams/${id}`)
 const getTeams = () => http.get('/api/v1/teams')
 const updateTeam = (id, data) => http.put(`/api/v1/teams/${id}`, data, {
@@ -235,6 +306,19 @@ const getUser = (id) => http.get(`/api/v1/agents/${id}`)
 const deleteUserAvatar = () => http.delete('/api/v1/agents/me/avatar')
 const getCurrentUser = () => http.get('/api/v1/agents/me')
 const getCurrentUserTeams = () => http.get('/api/v1/agents/me/teams')
+const getCurrentUserDashboard = () => http.get('/api/v1/agents/me/dashboard')
+const getCurrentUserKeyboardBindings = () => http.get('/api/v1/agents/me/keyboard-bindings')
+const updateCurrentUserKeyboardBindings = (data) => http.put('/api/v1/agents/me/keyboard-bindings', data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const getCurrentUserNotificationPreferences = () =>
+  http.get('/api/v1/agents/me/notification-preferences')
+const updateCurrentUserNotificationPreferences = (data) =>
+  http.put('/api/v1/agents/me/notification-preferences', data)
+const getCurrentUserSignature = () => http.get('/api/v1/agents/me/signature')
+const updateCurrentUserSignature = (data) => http.put('/api/v1/agents/me/signature', data)
 const updateCurrentUserAvailability = (data) => http.put('/api/v1/agents/me/availability', data, {
   headers: {
     'Content-Type': 'application/json'
@@ -272,6 +356,10 @@ const importTags = (data) =>
     }
   })
 const getTagImportStatus = () => http.get('/api/v1/tags/import/status')
+const recalculateConversationMetrics = (data) =>
+  http.post('/api/v1/conversations/recalculate-metrics', data)
+const getConversationMetricsRecalculationStatus = () =>
+  http.get('/api/v1/conversations/recalculate-metrics/status')
 const upsertTags = (uuid, data) => http.post(`/api/v1/conversations/${uuid}/tags`, data, {
   headers: {
     'Content-Type': 'application/json'
@@ -283,6 +371,14 @@ const updateAssignee = (uuid, assignee_type, data) =>
       'Content-Type': 'application/json'
     }
   })
+const getRelatedConversations = (uuid) => http.get(`/api/v1/conversations/${uuid}/related`)
+const updateConversationSummary = (uuid, data) =>
+  http.put(`/api/v1/conversations/${uuid}/summary`, data)
+const getHandoffNote = (uuid) => http.get(`/api/v1/conversations/${uuid}/handoff-note`)
+const getThreads = (uuid) => http.get(`/api/v1/conversations/${uuid}/threads`)
+const createThread = (uuid, data) => http.post(`/api/v1/conversations/${uuid}/threads`, data)
+const sendConversationTranscript = (uuid) => http.post(`/api/v1/conversations/${uuid}/transcript`)
+const updateThread = (uuid, id, data) => http.put(`/api/v1/conversations/${uuid}/threads/${id}`, data)
 const removeAssignee = (uuid, assignee_type) =>
   http.put(`/api/v1/conversations/${uuid}/assignee/${assignee_type}/remove`)
 const updateContactCustomAttribute = (uuid, data) =>
@@ -303,6 +399,18 @@ const createConversation = (data) =>
       'Content-Type': 'application/json'
     }
   })
+const importConversation = (inboxId, bundle) =>
+  http.post('/api/v1/conversations/import', bundle, {
+    params: { inbox_id: inboxId },
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getTrashedConversations = (params) => http.get('/api/v1/conversations/trash', { params })
+const trashConversation = (uuid) => http.post(`/api/v1/conversations/${uuid}/trash`)
+const restoreConversation = (uuid) => http.delete(`/api/v1/conversations/${uuid}/trash`)
+const markConversationSpam = (uuid) => http.post(`/api/v1/conversations/${uuid}/spam`)
+const markConversationNotSpam = (uuid) => http.delete(`/api/v1/conversations/${uuid}/spam`)
 const updateConversationStatus = (uuid, data) =>
   http.put(`/api/v1/conversations/${uuid}/status`, data, {
     headers: {
@@ -319,6 +427,14 @@ const updateAssigneeLastSeen = (uuid) => http.put(`/api/v1/conversations/${uuid}
 const markConversationAsUnread = (uuid) => http.put(`/api/v1/conversations/${uuid}/mark-unread`)
 const getConversationMessage = (cuuid, uuid) =>
   http.get(`/api/v1/conversations/${cuuid}/messages/${uuid}`)
+const getMessageHeaders = (cuuid, uuid) =>
+  http.get(`/api/v1/conversations/${cuuid}/messages/${uuid}/headers`)
+const pinMessage = (cuuid, uuid) => http.put(`/api/v1/conversations/${cuuid}/messages/${uuid}/pin`)
+const unpinMessage = (cuuid, uuid) => http.delete(`/api/v1/conversations/${cuuid}/messages/${uuid}/pin`)
+const addMessageReaction = (cuuid, uuid, emoji) =>
+  http.post(`/api/v1/conversations/${cuuid}/messages/${uuid}/reactions`, { emoji })
+const removeMessageReaction = (cuuid, uuid, emoji) =>
+  http.delete(`/api/v1/conversations/${cuuid}/messages/${uuid}/reactions`, { params: { emoji } })
 const retryMessage = (cuuid, uuid) =>
   http.put(`/api/v1/conversations/${cuuid}/messages/${uuid}/retry`)
 const getConversationMessages = (uuid, params) =>
@@ -331,6 +447,7 @@ const sendMessage = (uuid, data) =>
   })
 const getConversation = (uuid) => http.get(`/api/v1/conversations/${uuid}`)
 const getConversationParticipants = (uuid) => http.get(`/api/v1/conversations/${uuid}/participants`)
+const getConversationStats = (uuid) => http.get(`/api/v1/conversations/${uuid}/stats`)
 const getContactPageVisits = (uuid) => http.get(`/api/v1/conversations/${uuid}/page-visits`)
 const getAllMacros = () => http.get('/api/v1/macros')
 const getMacro = (id) => http.get(`/api/v1/macros/${id}`)
@@ -373,8 +490,20 @@ const getOverviewCounts = () => http.get('/api/v1/reports/overview/counts')
 const getOverviewCharts = (params) => http.get('/api/v1/reports/overview/charts', { params })
 const getOverviewSLA = (params) => http.get('/api/v1/reports/overview/sla', { params })
 const getOverviewCSAT = (params) => http.get('/api/v1/reports/overview/csat', { params })
+const getCSATBreakdown = (params) => http.get('/api/v1/reports/csat/breakdown', { params })
+const getCSATLowScores = (params) => http.get('/api/v1/reports/csat/low-scores', { params })
+const getNPSTrend = (params) => http.get('/api/v1/reports/nps', { params })
+const getAbuseVolume = (params) => http.get('/api/v1/reports/abuse', { params })
+const getTagTrend = (params) => http.get('/api/v1/reports/tags/trend', { params })
+const getTagCoOccurrence = (params) => http.get('/api/v1/reports/tags/co-occurrence', { params })
 const getOverviewMessageVolume = (params) => http.get('/api/v1/reports/overview/messages', { params })
 const getOverviewTagDistribution = (params) => http.get('/api/v1/reports/overview/tags', { params })
+const getWaitingTimeHeatmap = (params) => http.get('/api/v1/reports/overview/heatmap', { params })
+const getOverviewSLAIncidents = (params) => http.get('/api/v1/reports/overview/sla/incidents', { params })
+const getAssignmentSLA = (params) => http.get('/api/v1/reports/sla/assignment', { params })
+const getOverviewCollaboration = (params) =>
+  http.get('/api/v1/reports/overview/collaboration', { params })
+const getOverviewTopics = () => http.get('/api/v1/reports/overview/topics')
 const getLanguage = (lang) => http.get(`/api/v1/lang/${lang}`)
 const getAvailableLanguages = () => http.get('/api/v1/lang')
 const createInbox = (data) =>
@@ -385,6 +514,8 @@ const createInbox = (data) =>
   })
 const getInboxes = () => http.get('/api/v1/inboxes')
 const getInbox = (id) => http.get(`/api/v1/inboxes/${id}`)
+const getInboxEmailTemplatePreview = (id, params) =>
+  http.get(`/api/v1/inboxes/${id}/email-template/preview`, { params })
 const toggleInbox = (id) => http.put(`/api/v1/inboxes/${id}/toggle`)
 const updateInbox = (id, data) =>
   http.put(`/api/v1/inboxes/${id}`, data, {
@@ -393,6 +524,67 @@ const updateInbox = (id, data) =>
     }
   })
 const deleteInbox = (id) => http.delete(`/api/v1/inboxes/${id}`)
+const getSenderRules = (id, params) => http.get(`/api/v1/inboxes/${id}/sender-rules`, { params })
+const setSenderRule = (id, data) => http.put(`/api/v1/inboxes/${id}/sender-rules`, data)
+const deleteSenderRule = (id, email) =>
+  http.delete(`/api/v1/inboxes/${id}/sender-rules`, { params: { email } })
+const getInboxMigration = (id) => http.get(`/api/v1/inboxes/${id}/migration`)
+const createInboxMigration = (id, data) => http.post(`/api/v1/inboxes/${id}/migration`, data)
+const verifyInboxMigration = (id, data) =>
+  http.post(`/api/v1/inboxes/${id}/migration/verify`, data)
+const completeInboxMigration = (id, data) =>
+  http.post(`/api/v1/inboxes/${id}/migration/complete`, data)
+const cancelInboxMigration = (id) => http.delete(`/api/v1/inboxes/${id}/migration`)
+const getInboxMigrationNoticeStatus = (id) =>
+  http.get(`/api/v1/inboxes/${id}/migration/notice-status`)
+const getAutoresponders = (inboxId) => http.get(`/api/v1/inboxes/${inboxId}/autoresponders`)
+const createAutoresponder = (inboxId, data) =>
+  http.post(`/api/v1/inboxes/${inboxId}/autoresponders`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateAutoresponder = (inboxId, id, data) =>
+  http.put(`/api/v1/inboxes/${inboxId}/autoresponders/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteAutoresponder = (inboxId, id) =>
+  http.delete(`/api/v1/inboxes/${inboxId}/autoresponders/${id}`)
+const getAutoresponderVariants = (inboxId, id) =>
+  http.get(`/api/v1/inboxes/${inboxId}/autoresponders/${id}/variants`)
+const getAutoresponderVariantStats = (inboxId, id, params) =>
+  http.get(`/api/v1/inboxes/${inboxId}/autoresponders/${id}/variants/stats`, { params })
+const createAutoresponderVariant = (inboxId, id, data) =>
+  http.post(`/api/v1/inboxes/${inboxId}/autoresponders/${id}/variants`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateAutoresponderVariant = (inboxId, id, variantId, data) =>
+  http.put(`/api/v1/inboxes/${inboxId}/autoresponders/${id}/variants/${variantId}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteAutoresponderVariant = (inboxId, id, variantId) =>
+  http.delete(`/api/v1/inboxes/${inboxId}/autoresponders/${id}/variants/${variantId}`)
+const getWebForms = () => http.get('/api/v1/web-forms')
+const getWebForm = (id) => http.get(`/api/v1/web-forms/${id}`)
+const createWebForm = (data) =>
+  http.post('/api/v1/web-forms', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateWebForm = (id, data) =>
+  http.put(`/api/v1/web-forms/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteWebForm = (id) => http.delete(`/api/v1/web-forms/${id}`)
 const saveDraft = (uuid, data) =>
   http.post(`/api/v1/conversations/${uuid}/draft`, data, {
     headers: {
@@ -401,8 +593,20 @@ const saveDraft = (uuid, data) =>
   })
 
 const getAllDrafts = () => http.get('/api/v1/drafts')
+const getDraft = (uuid) => http.get(`/api/v1/conversations/${uuid}/draft`)
 
 const deleteDraft = (uuid) => http.delete(`/api/v1/conversations/${uuid}/draft`)
+const promoteDraft = (uuid) => http.post(`/api/v1/conversations/${uuid}/draft/promote`)
+const getTeamDraft = (uuid) => http.get(`/api/v1/conversations/${uuid}/team-draft`)
+const updateTeamDraft = (uuid, data) =>
+  http.put(`/api/v1/conversations/${uuid}/team-draft`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteTeamDraft = (uuid) => http.delete(`/api/v1/conversations/${uuid}/team-draft`)
+const lockTeamDraft = (uuid) => http.post(`/api/v1/conversations/${uuid}/team-draft/lock`)
+const unlockTeamDraft = (uuid) => http.delete(`/api/v1/conversations/${uuid}/team-draft/lock`)
 const getCurrentUserViews = () => http.get('/api/v1/views/me')
 const createView = (data) =>
   http.post('/api/v1/views/me', data, {
@@ -419,6 +623,13 @@ const updateView = (id, data) =>
 const deleteView = (id) => http.delete(`/api/v1/views/me/${id}`)
 
 const getSharedViews = () => http.get('/api/v1/views/shared')
+const getViewPreferences = () => http.get('/api/v1/views/preferences')
+const updateViewPreference = (data) =>
+  http.put('/api/v1/views/preferences', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
 const getAllSharedViews = () => http.get('/api/v1/shared-views')
 const getSharedView = (id) => http.get(`/api/v1/shared-views/${id}`)
 const createSharedView = (data) =>
@@ -488,6 +699,45 @@ const getActiveContextLinks = () => http.get('/api/v1/context-links/active')
 const getContextLinkURL = (id, conversationUUID) =>
   http.get(`/api/v1/context-links/${id}/url`, { params: { conversation_uuid: conversationUUID } })
 
+const getAnnouncements = () => http.get('/api/v1/announcements')
+const getAnnouncement = (id) => http.get(`/api/v1/announcements/${id}`)
+const getActiveAnnouncements = () => http.get('/api/v1/announcements/active')
+const getAnnouncementAcknowledgements = (id) => http.get(`/api/v1/announcements/${id}/acknowledgements`)
+const createAnnouncement = (data) =>
+  http.post('/api/v1/announcements', data, {
+    headers: { 'Content-Type': 'application/json' }
+  })
+const updateAnnouncement = (id, data) =>
+  http.put(`/api/v1/announcements/${id}`, data, {
+    headers: { 'Content-Type': 'application/json' }
+  })
+const deleteAnnouncement = (id) => http.delete(`/api/v1/announcements/${id}`)
+const acknowledgeAnnouncement = (id) => http.post(`/api/v1/announcements/${id}/acknowledge`)
+
+const getAssets = () => http.get('/api/v1/assets')
+const getAvailableAssets = () => http.get('/api/v1/assets/available')
+const createAsset = (data) =>
+  http.post('/api/v1/assets', data, {
+    headers: { 'Content-Type': 'application/json' }
+  })
+const updateAsset = (id, data) =>
+  http.put(`/api/v1/assets/${id}`, data, {
+    headers: { 'Content-Type': 'application/json' }
+  })
+const deleteAsset = (id) => http.delete(`/api/v1/assets/${id}`)
+const useAsset = (id) => http.post(`/api/v1/assets/${id}/use`)
+
+const getMaintenanceWindows = () => http.get('/api/v1/maintenance-windows')
+const createMaintenanceWindow = (data) =>
+  http.post('/api/v1/maintenance-windows', data, {
+    headers: { 'Content-Type': 'application/json' }
+  })
+const updateMaintenanceWindow = (id, data) =>
+  http.put(`/api/v1/maintenance-windows/${id}`, data, {
+    headers: { 'Content-Type': 'application/json' }
+  })
+const deleteMaintenanceWindow = (id) => http.delete(`/api/v1/maintenance-windows/${id}`)
+
 const generateAPIKey = (id) => 
   http.post(`/api/v1/agents/${id}/api-key`, {}, {
     headers: {
@@ -497,6 +747,33 @@ const generateAPIKey = (id) =>
 
 const revokeAPIKey = (id) => http.delete(`/api/v1/agents/${id}/api-key`)
 
+const setupTOTP = () => http.post('/api/v1/agents/me/totp/setup')
+const enableTOTP = (data) => http.post('/api/v1/agents/me/totp/enable', data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const disableTOTP = (data) => http.post('/api/v1/agents/me/totp/disable', data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const regenerateRecoveryCodes = (data) => http.post('/api/v1/agents/me/totp/recovery-codes', data, {
+  headers: {
+    'Content-Type': 'application/json'
+  }
+})
+const resetAgentTOTP = (id) => http.delete(`/api/v1/agents/${id}/totp`)
+const getOnShiftAgents = () => http.get('/api/v1/agents/on-shift')
+const getAgentSchedule = (id) => http.get(`/api/v1/agents/${id}/schedule`)
+const updateAgentSchedule = (id, data) => http.put(`/api/v1/agents/${id}/schedule`, data)
+const deleteAgentSchedule = (id) => http.delete(`/api/v1/agents/${id}/schedule`)
+const getAgentScheduleOverrides = (id) => http.get(`/api/v1/agents/${id}/schedule/overrides`)
+const createAgentScheduleOverride = (id, data) =>
+  http.post(`/api/v1/agents/${id}/schedule/overrides`, data)
+const deleteAgentScheduleOverride = (id, overrideId) =>
+  http.delete(`/api/v1/agents/${id}/schedule/overrides/${overrideId}`)
+
 const initiateOAuthFlow = (provider, data) =>
   http.post(`/api/v1/inboxes/oauth/${provider}/authorize`, data, {
     headers: {
@@ -511,6 +788,9 @@ const markNotificationAsRead = (id) => http.put(`/api/v1/notifications/${id}/rea
 const markAllNotificationsAsRead = () => http.put('/api/v1/notifications/read-all')
 const deleteNotification = (id) => http.delete(`/api/v1/notifications/${id}`)
 const deleteAllNotifications = () => http.delete('/api/v1/notifications')
+const getDeviceTokens = () => http.get('/api/v1/notifications/devices')
+const registerDeviceToken = (data) => http.post('/api/v1/notifications/devices', data)
+const deleteDeviceToken = (id) => http.delete(`/api/v1/notifications/devices/${id}`)
 
 export default {
   login,
@@ -519,6 +799,17 @@ export default {
   getAgentImportStatus,
   importTags,
   getTagImportStatus,
+  recalculateConversationMetrics,
+  getInboxMigration,
+  getSenderRules,
+  setSenderRule,
+  deleteSenderRule,
+  createInboxMigration,
+  verifyInboxMigration,
+  completeInboxMigration,
+  cancelInboxMigration,
+  getInboxMigrationNoticeStatus,
+  getConversationMetricsRecalculationStatus,
   resetPassword,
   setPassword,
   getTags,
@@ -533,6 +824,7 @@ export default {
   deleteTeam,
   getUsers,
   getInbox,
+  getInboxEmailTemplatePreview,
   getInboxes,
   getLanguage,
   getAvailableLanguages,
@@ -549,6 +841,11 @@ export default {
   createSLA,
   updateSLA,
   deleteSLA,
+  getSLAIncidents,
+  getSLAIncident,
+  createSLAIncident,
+  updateSLAIncident,
+  deleteSLAIncident,
   getAssignedConversations,
   getUnassignedConversations,
   getAllConversations,
@@ -559,13 +856,31 @@ export default {
   getOverviewCounts,
   getOverviewSLA,
   getOverviewCSAT,
+  getCSATBreakdown,
+  getCSATLowScores,
+  getNPSTrend,
+  getAbuseVolume,
+  getTagTrend,
+  getTagCoOccurrence,
   getOverviewMessageVolume,
   getOverviewTagDistribution,
+  getWaitingTimeHeatmap,
+  getOverviewSLAIncidents,
+  getAssignmentSLA,
+  getOverviewCollaboration,
+  getOverviewTopics,
   getConversationParticipants,
+  getConversationStats,
   getConversationMessage,
+  getMessageHeaders,
+  pinMessage,
+  unpinMessage,
+  addMessageReaction,
+  removeMessageReaction,
   getConversationMessages,
   getCurrentUser,
   getCurrentUserTeams,
+  getCurrentUserDashboard,
   getAllMacros,
   getMacro,
   createMacro,
@@ -574,7 +889,18 @@ export default {
   applyMacro,
   updateCurrentUser,
   updateAssignee,
+  getHandoffNote,
+  updateConversationSummary,
+  getRelatedConversations,
+  getThreads,
+  createThread,
+  updateThread,
   updateConversationStatus,
+  markConversationSpam,
+  getTrashedConversations,
+  trashConversation,
+  restoreConversation,
+  markConversationNotSpam,
   updateConversationPriority,
   upsertTags,
   updateConversationCustomAttribute,
@@ -584,6 +910,12 @@ export default {
   markConversationAsUnread,
   updateUser,
   updateCurrentUserAvailability,
+  getCurrentUserKeyboardBindings,
+  updateCurrentUserKeyboardBindings,
+  getCurrentUserNotificationPreferences,
+  updateCurrentUserNotificationPreferences,
+  getCurrentUserSignature,
+  updateCurrentUserSignature,
   updateAutomationRule,
   updateAutomationRuleWeights,
   updateAutomationRulesExecutionMode,
@@ -591,13 +923,30 @@ export default {
   createAutomationRule,
   toggleAutomationRule,
   deleteAutomationRule,
+  getAutomationRuleLogs,
+  dryRunAutomationRule,
   createConversation,
+  importConversation,
   sendMessage,
   retryMessage,
   createUser,
   createInbox,
   updateInbox,
   deleteInbox,
+  getAutoresponders,
+  createAutoresponder,
+  updateAutoresponder,
+  deleteAutoresponder,
+  getAutoresponderVariants,
+  getAutoresponderVariantStats,
+  createAutoresponderVariant,
+  updateAutoresponderVariant,
+  deleteAutoresponderVariant,
+  getWebForms,
+  getWebForm,
+  createWebForm,
+  updateWebForm,
+  deleteWebForm,
   toggleInbox,
   createTeam,
   updateTeam,
@@ -611,14 +960,24 @@ export default {
   deleteOIDC,
   getTemplate,
   getTemplates,
+  getTemplateVariables,
   createTemplate,
   updateTemplate,
   deleteTemplate,
+  getTemplateVariants,
+  getTemplateVariantStats,
+  createTemplateVariant,
+  updateTemplateVariant,
+  deleteTemplateVariant,
   deleteUserAvatar,
   createTag,
   updateTag,
   deleteTag,
   getStatuses,
+  getSnoozePresets,
+  createSnoozePreset,
+  updateSnoozePreset,
+  deleteSnoozePreset,
   getPriorities,
   createStatus,
   updateStatus,
@@ -626,15 +985,28 @@ export default {
   getTeamsCompact,
   getUsersCompact,
   getEmailNotificationSettings,
+  getCaptchaSettings,
+  updateCaptchaSettings,
+  getAbuseSettings,
+  updateAbuseSettings,
   updateEmailNotificationSettings,
   saveDraft,
   getAllDrafts,
+  getDraft,
   deleteDraft,
+  promoteDraft,
+  getTeamDraft,
+  updateTeamDraft,
+  deleteTeamDraft,
+  lockTeamDraft,
+  unlockTeamDraft,
   getCurrentUserViews,
   createView,
   updateView,
   deleteView,
   getSharedViews,
+  getViewPreferences,
+  updateViewPreference,
   getAllSharedViews,
   getSharedView,
   createSharedView,
@@ -645,11 +1017,16 @@ export default {
   searchConversations,
   searchMessages,
   searchContacts,
+  searchMentions,
   removeAssignee,
   getContacts,
   getContact,
   updateContact,
   blockContact,
+  updateContactRiskFlags,
+  sendConversationTranscript,
+  getContactPreferences,
+  updateContactPreferences,
   getCustomAttributes,
   createCustomAttribute,
   updateCustomAttribute,
@@ -674,8 +1051,38 @@ export default {
   toggleContextLink,
   getActiveContextLinks,
   getContextLinkURL,
+  getAnnouncements,
+  getAnnouncement,
+  getActiveAnnouncements,
+  getAnnouncementAcknowledgements,
+  createAnnouncement,
+  updateAnnouncement,
+  deleteAnnouncement,
+  acknowledgeAnnouncement,
+  getAssets,
+  getAvailableAssets,
+  createAsset,
+  updateAsset,
+  deleteAsset,
+  useAsset,
+  getMaintenanceWindows,
+  createMaintenanceWindow,
+  updateMaintenanceWindow,
+  deleteMaintenanceWindow,
   generateAPIKey,
   revokeAPIKey,
+  setupTOTP,
+  enableTOTP,
+  disableTOTP,
+  regenerateRecoveryCodes,
+  resetAgentTOTP,
+  getOnShiftAgents,
+  getAgentSchedule,
+  updateAgentSchedule,
+  deleteAgentSchedule,
+  getAgentScheduleOverrides,
+  createAgentScheduleOverride,
+  deleteAgentScheduleOverride,
   initiateOAuthFlow,
   getNotifications,
   getNotificationStats,
@@ -683,5 +1090,8 @@ export default {
   markAllNotificationsAsRead,
   deleteNotification,
   deleteAllNotifications,
+  getDeviceTokens,
+  registerDeviceToken,
+  deleteDeviceToken,
   getContactPageVisits
 }
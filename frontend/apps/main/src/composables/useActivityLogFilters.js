@@ -38,6 +38,9 @@ export function useActivityLogFilters () {
             }, {
                 label: t('activityLog.type.agentRolePermissionsChanged'),
                 value: 'agent_role_permissions_changed'
+            }, {
+                label: t('activityLog.type.contactRiskFlagsChanged'),
+                value: 'contact_risk_flags_changed'
             }]
         },
     }))
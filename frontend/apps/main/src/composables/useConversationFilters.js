@@ -37,6 +37,11 @@ export function useConversationFilters () {
         'list': FIELD_OPERATORS.SELECT,
     }
 
+    const booleanOptions = computed(() => [
+        { label: t('globals.messages.yes'), value: 'true' },
+        { label: t('globals.messages.no'), value: 'false' }
+    ])
+
     const conversationsListFilters = computed(() => ({
         status_id: {
             label: t('globals.terms.status'),
@@ -78,6 +83,23 @@ export function useConversationFilters () {
             label: t('globals.terms.createdAt'),
             type: FIELD_TYPE.DATE,
             operators: FIELD_OPERATORS.DATE
+        },
+        awaiting_agent: {
+            label: t('conversation.filter.awaitingAgent'),
+            type: FIELD_TYPE.BOOLEAN,
+            operators: FIELD_OPERATORS.BOOLEAN,
+            options: booleanOptions.value
+        },
+        awaiting_customer: {
+            label: t('conversation.filter.awaitingCustomer'),
+            type: FIELD_TYPE.BOOLEAN,
+            operators: FIELD_OPERATORS.BOOLEAN,
+            options: booleanOptions.value
+        },
+        idle_days: {
+            label: t('conversation.filter.idleDays'),
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
         }
     }))
 
@@ -98,6 +120,69 @@ export function useConversationFilters () {
             }, {})
     })
 
+    const conversationCustomAttributes = computed(() => {
+        return customAttributeStore.conversationAttributeOptions
+            .reduce((acc, attribute) => {
+                acc[attribute.key] = {
+                    label: attribute.label,
+                    type: customAttributeDataTypeToFieldType[attribute.data_type] || FIELD_TYPE.TEXT,
+                    operators: customAttributeDataTypeToFieldOperators[attribute.data_type] || FIELD_OPERATORS.TEXT,
+                    options: attribute.values.map(value => ({
+                        label: value,
+                        value: value
+                    })) || [],
+                }
+                return acc
+            }, {})
+    })
+
+    const contactFields = computed(() => ({
+        first_name: {
+            label: t('globals.terms.firstName'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        last_name: {
+            label: t('globals.terms.lastName'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        email: {
+            label: t('globals.terms.email'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        phone_number: {
+            label: t('globals.terms.phoneNumber'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        country: {
+            label: t('globals.terms.country'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        external_user_id: {
+            label: t('globals.terms.externalUserId'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        risk_flags: {
+            label: t('contact.riskFlags.title'),
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        type: {
+            label: t('globals.terms.type'),
+            type: FIELD_TYPE.SELECT,
+            operators: FIELD_OPERATORS.SELECT,
+            options: [
+                { label: t('globals.terms.contact'), value: 'contact' },
+                { label: t('globals.terms.visitor'), value: 'visitor' }
+            ]
+        }
+    }))
+
     const newConversationFilters = computed(() => ({
         contact_email: {
             label: t('globals.terms.email'),
@@ -191,6 +276,25 @@ export function useConversationFilters () {
             type: FIELD_TYPE.NUMBER,
             operators: FIELD_OPERATORS.NUMBER
         },
+        hours_since_last_message: {
+            label: t('globals.messages.hoursSinceLastMessage'),
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
+        },
+        hours_in_status: {
+            label: t('globals.messages.hoursInStatus'),
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
+        },
+        last_message_sender: {
+            label: t('globals.messages.lastMessageSender'),
+            type: FIELD_TYPE.SELECT,
+            operators: FIELD_OPERATORS.SELECT,
+            options: [
+                { label: t('globals.terms.agent'), value: 'agent' },
+                { label: t('globals.terms.contact'), value: 'contact' }
+            ]
+        },
         inbox: {
             label: t('globals.terms.inbox'),
             type: FIELD_TYPE.SELECT,
@@ -231,6 +335,9 @@ export function useConversationFilters () {
         send_csat: {
             label: t('actions.sendCsat'),
         },
+        send_transcript: {
+            label: t('actions.sendTranscript'),
+        },
         set_sla: {
             label: t('actions.setSla'),
             type: FIELD_TYPE.SELECT,
@@ -247,6 +354,14 @@ export function useConversationFilters () {
         remove_tags: {
             label: t('actions.removeTags'),
             type: FIELD_TYPE.TAG
+        },
+        call_webhook: {
+            label: t('actions.callWebhook'),
+            type: FIELD_TYPE.WEBHOOK
+        },
+        set_custom_attribute: {
+            label: t('actions.setCustomAttribute'),
+            type: FIELD_TYPE.CUSTOM_ATTRIBUTE
         }
     }))
 
@@ -293,5 +408,7 @@ export function useConversationFilters () {
         conversationActions,
         macroActions,
         contactCustomAttributes,
+        conversationCustomAttributes,
+        contactFields,
     }
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+import { ref, onMounted, onBeforeUnmount } from 'vue'
+import { useConversationStore } from '@main/stores/conversation'
+import { useMacroStore } from '@main/stores/macro'
+import { MACRO_CONTEXT } from '@main/constants/conversation'
+import api from '@main/api'
+
+// Modifiers in the order the server normalizes key combinations to.
+const MODIFIERS = [
+    ['ctrl', 'ctrlKey'],
+    ['alt', 'altKey'],
+    ['shift', 'shiftKey'],
+    ['meta', 'metaKey']
+]
+
+// eventKeyCombo returns the normalized key combination of a keydown event, e.g. "ctrl+shift+1".
+// The key is taken from the physical key for letters and digits so that shift doesn't change it.
+function eventKeyCombo (e) {
+    let key = e.key.toLowerCase()
+    if (/^Key[A-Z]$/.test(e.code)) key = e.code.slice(3).toLowerCase()
+    else if (/^Digit\d$/.test(e.code)) key = e.code.slice(5)
+    const mods = MODIFIERS.filter(([, prop]) => e[prop]).map(([name]) => name)
+    return [...mods, key].join('+')
+}
+
+// useKeyboardBindings applies the current agent's keyboard bindings to the open conversation.
+export function useKeyboardBindings () {
+    const conversationStore = useConversationStore()
+    const macroStore = useMacroStore()
+    const bindings = ref({})
+
+    const load = async () => {
+        try {
+            const resp = await api.getCurrentUserKeyboardBindings()
+            bindings.value = Object.fromEntries(resp.data.data.map(b => [b.keys, b]))
+        } catch (error) {
+            // Bindings are optional, the app works without them.
+            console.error('Error fetching keyboard bindings', error)
+        }
+    }
+
+    const onKeydown = (e) => {
+        if (!e.ctrlKey && !e.altKey && !e.metaKey) return
+        const binding = bindings.value[eventKeyCombo(e)]
+        if (!binding || !conversationStore.current?.uuid) return
+
+        if (binding.action === 'macro') {
+            const macro = macroStore.macroOptions.find(m => m.value === binding.target)
+            if (!macro) return
+            e.preventDefault()
+            conversationStore.setMacro(JSON.parse(JSON.stringify(macro)), MACRO_CONTEXT.REPLY)
+        } else if (binding.action === 'status') {
+            const status = conversationStore.statusOptionsNoSnooze.find(s => String(s.value) === binding.target)
+            if (!status) return
+            e.preventDefault()
+            conversationStore.updateStatus(status.label)
+        }
+    }
+
+    onMounted(() => {
+        load()
+        window.addEventListener('keydown', onKeydown)
+    })
+
+    onBeforeUnmount(() => {
+        window.removeEventListener('keydown', onKeydown)
+    })
+
+    return { bindings, reload: load }
+}
@@ -7,6 +7,8 @@ export const FIELD_TYPE = {
     RICHTEXT: 'richtext',
     BOOLEAN: 'boolean',
     DATE: 'date',
+    WEBHOOK: 'webhook',
+    CUSTOM_ATTRIBUTE: 'custom-attribute',
 }
 
 export const OPERATOR = {
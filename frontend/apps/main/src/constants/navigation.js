@@ -30,6 +30,13 @@ export const adminNavItems = [
         permission: 'sla:manage',
         isTitleKeyPlural: true,
         icon: 'Timer'
+      },
+      {
+        titleKey: 'globals.terms.announcement',
+        href: '/admin/announcements',
+        permission: 'announcements:manage',
+        isTitleKeyPlural: true,
+        icon: 'Megaphone'
       }
     ]
   },
@@ -43,6 +50,13 @@ export const adminNavItems = [
         permission: 'inboxes:manage',
         isTitleKeyPlural: true,
         icon: 'Inbox'
+      },
+      {
+        titleKey: 'globals.terms.webForm',
+        href: '/admin/web-forms',
+        permission: 'inboxes:manage',
+        isTitleKeyPlural: true,
+        icon: 'FileText'
       }
     ]
   },
@@ -90,6 +104,13 @@ export const adminNavItems = [
         isTitleKeyPlural: true,
         icon: 'Zap'
       },
+      {
+        titleKey: 'globals.terms.asset',
+        href: '/admin/conversations/assets',
+        permission: 'assets:manage',
+        isTitleKeyPlural: true,
+        icon: 'Library'
+      },
       {
         titleKey: 'globals.terms.automation',
         href: '/admin/automations',
@@ -190,6 +211,17 @@ export const accountNavItems = [
     titleKey: 'globals.terms.profile',
     href: '/account/profile',
     icon: 'CircleUser'
+  },
+  {
+    titleKey: 'globals.terms.notification',
+    isTitleKeyPlural: true,
+    href: '/account/notifications',
+    icon: 'Bell'
+  },
+  {
+    titleKey: 'account.signature',
+    href: '/account/signature',
+    icon: 'PenLine'
   }
 ]
 
@@ -11,9 +11,13 @@ export const permissions = {
   CONVERSATIONS_UPDATE_PRIORITY: 'conversations:update_priority',
   CONVERSATIONS_UPDATE_STATUS: 'conversations:update_status',
   CONVERSATIONS_UPDATE_TAGS: 'conversations:update_tags',
+  CONVERSATIONS_UPDATE_CUSTOM_ATTRIBUTES: 'conversations:update_custom_attributes',
+  CONVERSATIONS_TRASH: 'conversations:trash',
   MESSAGES_READ: 'messages:read',
   MESSAGES_WRITE: 'messages:write',
   MESSAGES_WRITE_AS_CONTACT: 'messages:write_as_contact',
+  MESSAGES_WRITE_PRIVATE: 'messages:write_private',
+  MESSAGES_WRITE_INTEGRATION_NOTES: 'messages:write_integration_notes',
   VIEW_MANAGE: 'view:manage',
   SHARED_VIEWS_MANAGE: 'shared_views:manage',
   GENERAL_SETTINGS_MANAGE: 'general_settings:manage',
@@ -37,10 +41,13 @@ export const permissions = {
   CONTACTS_READ: 'contacts:read',
   CONTACTS_WRITE: 'contacts:write',
   CONTACTS_BLOCK: 'contacts:block',
+  CONTACTS_MANAGE_RISK_FLAGS: 'contacts:manage_risk_flags',
   CONTACT_NOTES_READ: 'contact_notes:read',
   CONTACT_NOTES_WRITE: 'contact_notes:write',
   CONTACT_NOTES_DELETE: 'contact_notes:delete',
   ACTIVITY_LOGS_MANAGE: 'activity_logs:manage',
   WEBHOOKS_MANAGE: 'webhooks:manage',
-  CONTEXT_LINKS_MANAGE: 'context_links:manage'
+  CONTEXT_LINKS_MANAGE: 'context_links:manage',
+  ANNOUNCEMENTS_MANAGE: 'announcements:manage',
+  ASSETS_MANAGE: 'assets:manage'
 }
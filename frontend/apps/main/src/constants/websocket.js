@@ -7,6 +7,11 @@ export const WS_EVENT = {
     CONVERSATION_SUBSCRIBED: 'conversation_subscribed',
     TYPING: 'typing',
     NEW_NOTIFICATION: 'new_notification',
+    ANNOUNCEMENT: 'announcement',
+    ANNOUNCEMENT_DELETED: 'announcement_deleted',
+    SUBSCRIBE: 'subscribe',
+    UNSUBSCRIBE: 'unsubscribe',
+    SUBSCRIBED: 'subscribed',
 }
 
 // Message types that should not be queued because they become stale quickly
@@ -0,0 +1,93 @@
+import { h } from 'vue'
+import { RouterLink } from 'vue-router'
+import dropdown from './dataTableDropdown.vue'
+import { format } from 'date-fns'
+import { Badge } from '@shared-ui/components/ui/badge'
+
+const severityVariants = {
+  info: 'secondary',
+  warning: 'outline',
+  critical: 'destructive'
+}
+
+export const createColumns = (t) => [
+  {
+    accessorKey: 'message',
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.message'))
+    },
+    cell: function ({ row }) {
+      return h(
+        'div',
+        { class: 'text-center' },
+        h(
+          RouterLink,
+          {
+            to: { name: 'edit-announcement', params: { id: row.original.id } },
+            class: 'text-primary hover:underline block max-w-sm truncate mx-auto'
+          },
+          () => row.getValue('message')
+        )
+      )
+    }
+  },
+  {
+    accessorKey: 'severity',
+    enableGlobalFilter: false,
+    header: () => h('div', { class: 'text-center' }, t('announcement.severity')),
+    cell: ({ row }) => {
+      const severity = row.getValue('severity')
+      return h('div', { class: 'text-center' }, [
+        h(
+          Badge,
+          { variant: severityVariants[severity] || 'secondary', class: 'text-xs' },
+          () => t(`announcement.severity.${severity}`)
+        )
+      ])
+    }
+  },
+  {
+    accessorKey: 'ends_at',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('announcement.endsAt'))
+    },
+    cell: function ({ row }) {
+      const endsAt = row.getValue('ends_at')
+      return h('div', { class: 'text-center text-sm' }, endsAt ? format(endsAt, 'PPpp') : '-')
+    }
+  },
+  {
+    accessorKey: 'acknowledged_count',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('announcement.acknowledgedBy'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center text-sm' }, row.getValue('acknowledged_count'))
+    }
+  },
+  {
+    accessorKey: 'created_at',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.createdAt'))
+    },
+    cell: function ({ row }) {
+      return h(
+        'div',
+        { class: 'text-center text-sm' },
+        format(row.getValue('created_at'), 'PPpp')
+      )
+    }
+  },
+  {
+    id: 'actions',
+    enableHiding: false,
+    enableSorting: false,
+    cell: ({ row }) => {
+      const announcement = row.original
+      return h('div', { class: 'relative' }, h(dropdown, { announcement }))
+    }
+  }
+]
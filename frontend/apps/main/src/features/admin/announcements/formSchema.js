@@ -0,0 +1,22 @@
+import * as z from 'zod'
+
+export const createFormSchema = (t) =>
+  z
+    .object({
+      message: z
+        .string({
+          required_error: t('globals.messages.required')
+        })
+        .min(1, {
+          message: t('globals.messages.required')
+        })
+        .max(2000),
+      severity: z.enum(['info', 'warning', 'critical']).default('info'),
+      starts_at: z.string().optional().nullable(),
+      ends_at: z.string().optional().nullable(),
+      team_ids: z.array(z.string()).default([])
+    })
+    .refine((data) => !data.starts_at || !data.ends_at || new Date(data.ends_at) > new Date(data.starts_at), {
+      message: t('announcement.endsAfterStart'),
+      path: ['ends_at']
+    })
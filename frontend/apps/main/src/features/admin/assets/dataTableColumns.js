@@ -0,0 +1,74 @@
+import { h } from 'vue'
+import dropdown from './dataTableDropdown.vue'
+import { format } from 'date-fns'
+
+export const createColumns = (t, { onEdit } = {}) => [
+  {
+    accessorKey: 'name',
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.name'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center' },
+        onEdit
+          ? h('span', {
+              class: 'text-primary hover:underline cursor-pointer',
+              onClick: () => onEdit(row.original)
+            }, row.getValue('name'))
+          : row.getValue('name')
+      )
+    }
+  },
+  {
+    accessorKey: 'filename',
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.file'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center' },
+        h('a', {
+          class: 'text-primary hover:underline',
+          href: row.original.url,
+          target: '_blank',
+          rel: 'noopener noreferrer'
+        }, row.getValue('filename'))
+      )
+    }
+  },
+  {
+    accessorKey: 'usage_count',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('asset.usageCount'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center' }, row.getValue('usage_count'))
+    }
+  },
+  {
+    accessorKey: 'last_used_at',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('asset.lastUsedAt'))
+    },
+    cell: function ({ row }) {
+      const lastUsedAt = row.getValue('last_used_at')
+      return h('div', { class: 'text-center' }, lastUsedAt ? format(lastUsedAt, 'PPpp') : '-')
+    }
+  },
+  {
+    id: 'actions',
+    enableHiding: false,
+    enableSorting: false,
+    cell: ({ row }) => {
+      const asset = row.original
+      return h(
+        'div',
+        { class: 'relative' },
+        h(dropdown, {
+          asset
+        })
+      )
+    }
+  }
+]
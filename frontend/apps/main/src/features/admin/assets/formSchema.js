@@ -0,0 +1,22 @@
+import * as z from 'zod'
+
+export const createFormSchema = (t) => z.object({
+  name: z
+    .string({
+      required_error: t('globals.messages.required'),
+    })
+    .min(1, {
+      message: t('globals.messages.required', { name: t('globals.terms.name') }),
+    })
+    .max(140, {
+      message: t('globals.messages.maxLength', { max: 140 }),
+    }),
+  description: z
+    .string()
+    .max(1000, {
+      message: t('globals.messages.maxLength', { max: 1000 }),
+    })
+    .optional()
+    .default(''),
+  team_ids: z.array(z.string()).optional().default([])
+})
@@ -11,6 +11,9 @@ export const createFormSchema = (t) => z
             required_error: t('globals.messages.required'),
         }),
         events: z.array(z.string()).optional(),
+        stop_processing: z.boolean().default(false),
+        active_from: z.string().optional().nullable(),
+        active_until: z.string().optional().nullable(),
     })
     .superRefine((data, ctx) => {
         if (data.type === 'conversation_update' && (!data.events || data.events.length === 0)) {
@@ -20,4 +23,11 @@ export const createFormSchema = (t) => z
                 code: z.ZodIssueCode.custom,
             });
         }
+        if (data.active_from && data.active_until && new Date(data.active_until) <= new Date(data.active_from)) {
+            ctx.addIssue({
+                path: ['active_until'],
+                message: t('admin.automation.validation.activeWindow'),
+                code: z.ZodIssueCode.custom,
+            });
+        }
     });
@@ -2,6 +2,39 @@ import * as z from 'zod'
 import { isGoDuration, validateEmail } from '@shared-ui/utils/string'
 import { AUTH_TYPE_PASSWORD, AUTH_TYPE_OAUTH2 } from '@main/constants/auth.js'
 
+// Default CSAT survey settings of an inbox.
+export const defaultCSATConfig = {
+  scale: 'stars',
+  question: '',
+  hide_comment: false,
+  language: '',
+  throttle_days: 0,
+  exclude_tags: []
+}
+
+export const csatConfigSchema = (t) =>
+  z
+    .object({
+      // Empty for inboxes saved before scales existed, same as stars.
+      scale: z.enum(['', 'stars', 'thumbs', 'nps']).default('stars'),
+      question: z
+        .string()
+        .max(300, t('globals.messages.maxLength', { max: 300 }))
+        .optional()
+        .default(''),
+      hide_comment: z.boolean().optional().default(false),
+      language: z.string().optional().default(''),
+      throttle_days: z.coerce
+        .number()
+        .int()
+        .min(0, t('validation.invalidValue'))
+        .max(365, t('validation.invalidValue'))
+        .optional()
+        .default(0),
+      exclude_tags: z.array(z.string()).optional().default([])
+    })
+    .default(defaultCSATConfig)
+
 export const createFormSchema = (t) => z.object({
   name: z.string().min(1, t('globals.messages.required')),
   from: z.string().min(1, t('globals.messages.required')),
@@ -13,8 +46,16 @@ export const createFormSchema = (t) => z.object({
     }),
   enabled: z.boolean().optional(),
   csat_enabled: z.boolean().optional(),
+  csat_config: csatConfigSchema(t),
   prompt_tags_on_reply: z.boolean().optional(),
   enable_plus_addressing: z.boolean().optional(),
+  request_read_receipts: z.boolean().optional(),
+  track_opens: z.boolean().optional(),
+  send_rate_limit: z.number().int().min(0).optional(),
+  send_concurrency: z.number().int().min(0).optional(),
+  mdn_policy: z.enum(['ignore', 'send']).optional(),
+  // Select values are strings, '0' is the default outgoing template.
+  email_template_id: z.string().optional(),
   auth_type: z.enum([AUTH_TYPE_PASSWORD, AUTH_TYPE_OAUTH2]),
   oauth: z.object({
     access_token: z.string().optional(),
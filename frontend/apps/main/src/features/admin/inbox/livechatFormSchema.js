@@ -1,5 +1,6 @@
 import { z } from 'zod'
 import { isGoDuration } from '@shared-ui/utils/string'
+import { csatConfigSchema } from './formSchema.js'
 
 const hexColorRegex = /^#([A-Fa-f0-9]{6}|[A-Fa-f0-9]{3})$/
 const hexColor = (t) => z.string().regex(hexColorRegex, { message: t('validation.invalidColor') })
@@ -14,6 +15,7 @@ export const createFormSchema = (t) => z.object({
   name: z.string().min(1, { message: t('globals.messages.required') }),
   enabled: z.boolean(),
   csat_enabled: z.boolean(),
+  csat_config: csatConfigSchema(t),
   prompt_tags_on_reply: z.boolean(),
   secret: z.string().nullable().optional(),
   linked_email_inbox_id: z.number().nullable().optional(),
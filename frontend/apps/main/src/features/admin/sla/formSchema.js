@@ -21,6 +21,9 @@ export const createFormSchema = (t) =>
             next_response_time: z.string().nullable().optional().refine(val => !val || isGoHourMinuteDuration(val), {
                 message: t('validation.invalidDuration'),
             }),
+            assignment_time: z.string().nullable().optional().refine(val => !val || isGoHourMinuteDuration(val), {
+                message: t('validation.invalidDuration'),
+            }),
             notifications: z
                 .array(
                     z
@@ -28,7 +31,7 @@ export const createFormSchema = (t) =>
                             type: z.enum(['breach', 'warning']),
                             time_delay_type: z.enum(['immediately', 'after', 'before']),
                             time_delay: z.string().optional(),
-                            metric: z.enum(['first_response', 'resolution', 'next_response', 'all']),
+                            metric: z.enum(['first_response', 'resolution', 'next_response', 'assignment', 'all']),
                             recipients: z
                                 .array(z.string())
                                 .min(1, {
@@ -57,8 +60,8 @@ export const createFormSchema = (t) =>
                 .default([]),
         })
         .superRefine((data, ctx) => {
-            const { first_response_time, resolution_time, next_response_time } = data
-            const isEmpty = !first_response_time && !resolution_time && !next_response_time
+            const { first_response_time, resolution_time, next_response_time, assignment_time } = data
+            const isEmpty = !first_response_time && !resolution_time && !next_response_time && !assignment_time
 
             if (isEmpty) {
                 const msg = t('admin.sla.atleastOneSLATimeRequired')
@@ -77,5 +80,10 @@ export const createFormSchema = (t) =>
                     path: ['next_response_time'],
                     message: msg,
                 })
+                ctx.addIssue({
+                    code: z.ZodIssueCode.custom,
+                    path: ['assignment_time'],
+                    message: msg,
+                })
             }
         })
@@ -0,0 +1,88 @@
+import { h } from 'vue'
+import dropdown from './dataTableDropdown.vue'
+import { format } from 'date-fns'
+
+export const createColumns = (t, { onEdit, inboxName } = {}) => [
+  {
+    accessorKey: 'name',
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.name'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center' },
+        onEdit
+          ? h('span', {
+              class: 'text-primary hover:underline cursor-pointer',
+              onClick: () => onEdit(row.original)
+            }, row.getValue('name'))
+          : row.getValue('name')
+      )
+    }
+  },
+  {
+    accessorKey: 'inbox_id',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.inbox'))
+    },
+    cell: function ({ row }) {
+      const id = row.getValue('inbox_id')
+      return h('div', { class: 'text-center' }, inboxName ? inboxName(id) : id)
+    }
+  },
+  {
+    accessorKey: 'enabled',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.enabled'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center' },
+        row.getValue('enabled') ? t('globals.messages.yes') : t('globals.messages.no')
+      )
+    }
+  },
+  {
+    accessorKey: 'uuid',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.url'))
+    },
+    cell: function ({ row }) {
+      const url = `${window.location.origin}/forms/${row.getValue('uuid')}`
+      return h('div', { class: 'text-center' },
+        h('a', {
+          class: 'text-primary hover:underline',
+          href: url,
+          target: '_blank',
+          rel: 'noopener noreferrer'
+        }, url)
+      )
+    }
+  },
+  {
+    accessorKey: 'updated_at',
+    enableGlobalFilter: false,
+    header: function () {
+      return h('div', { class: 'text-center' }, t('globals.terms.updatedAt'))
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center' }, format(row.getValue('updated_at'), 'PPpp'))
+    }
+  },
+  {
+    id: 'actions',
+    enableHiding: false,
+    enableSorting: false,
+    cell: ({ row }) => {
+      const webForm = row.original
+      return h(
+        'div',
+        { class: 'relative' },
+        h(dropdown, {
+          webForm
+        })
+      )
+    }
+  }
+]
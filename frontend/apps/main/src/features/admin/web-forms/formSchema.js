@@ -0,0 +1,63 @@
+import * as z from 'zod'
+
+// Matches the trusted domain check of the server, example.com, *.example.com or example.com:8080.
+const domainRe = /^(\*\.)?[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(:\d{1,5})?$/i
+
+// Reserved for the built-in and honeypot inputs of the form page.
+const reservedKeys = ['name', 'email', 'subject', 'message', 'attachments', 'website']
+
+export const createFormSchema = (t) => z.object({
+  name: z
+    .string({
+      required_error: t('globals.messages.required'),
+    })
+    .min(1, {
+      message: t('globals.messages.required', { name: t('globals.terms.name') }),
+    })
+    .max(140, {
+      message: t('globals.messages.maxLength', { max: 140 }),
+    }),
+  inbox_id: z.string({
+    required_error: t('globals.messages.required', { name: t('globals.terms.inbox') }),
+  }),
+  enabled: z.boolean().default(true),
+  allow_attachments: z.boolean().default(false),
+  success_message: z
+    .string()
+    .max(1000, {
+      message: t('globals.messages.maxLength', { max: 1000 }),
+    })
+    .optional()
+    .default(''),
+  trusted_domains: z
+    .array(z.string().regex(domainRe, { message: t('webForm.invalidDomain') }))
+    .optional()
+    .default([]),
+  fields: z
+    .array(
+      z.object({
+        key: z
+          .string()
+          .regex(/^[a-z][a-z0-9_]{0,49}$/, { message: t('webForm.invalidFieldKey') })
+          .refine((key) => !reservedKeys.includes(key), { message: t('webForm.reservedFieldKey') }),
+        label: z.string().min(1, {
+          message: t('globals.messages.required', { name: t('globals.terms.label') }),
+        }),
+        type: z.enum(['text', 'textarea', 'select']),
+        required: z.boolean().default(false),
+        options: z.array(z.string()).optional().default([])
+      })
+      .refine((field) => field.type !== 'select' || field.options.length > 0, {
+        message: t('webForm.optionsRequired'),
+        path: ['options']
+      })
+    )
+    .max(20, {
+      message: t('globals.messages.maxLength', { max: 20 }),
+    })
+    .refine((fields) => new Set(fields.map((f) => f.key)).size === fields.length, {
+      message: t('webForm.duplicateFieldKey')
+    })
+    .optional()
+    .default([])
+})
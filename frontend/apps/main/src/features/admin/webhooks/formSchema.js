@@ -21,5 +21,7 @@ export const createFormSchema = (t) =>
     }),
     secret: z.string().optional(),
     is_active: z.boolean().default(true).optional(),
-    headers: z.string().optional()
+    headers: z.string().optional(),
+    include_fields: z.array(z.string()).default([]),
+    exclude_fields: z.array(z.string()).default([])
   })
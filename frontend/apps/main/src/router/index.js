@@ -172,6 +172,19 @@ const routes = [
             name: 'profile',
             component: () => import('@main/views/account/profile/ProfileEditView.vue'),
             meta: { titleKey: 'account.editProfile' }
+          },
+          {
+            path: 'notifications',
+            name: 'account-notifications',
+            component: () =>
+              import('@main/views/account/notifications/NotificationPreferencesView.vue'),
+            meta: { titleKey: 'globals.terms.notification', titleCount: 2 }
+          },
+          {
+            path: 'signature',
+            name: 'account-signature',
+            component: () => import('@main/views/account/signature/SignatureView.vue'),
+            meta: { titleKey: 'account.signature' }
           }
         ]
       },
@@ -270,6 +283,11 @@ const routes = [
               }
             ]
           },
+          {
+            path: 'web-forms',
+            component: () => import('@main/views/admin/web-forms/WebFormsView.vue'),
+            meta: { titleKey: 'globals.terms.webForm', titleCount: 2 }
+          },
           {
             path: 'notification',
             component: () => import('@main/features/admin/notification/NotificationSetting.vue'),
@@ -466,6 +484,34 @@ const routes = [
               }
             ]
           },
+          {
+            path: 'announcements',
+            component: () => import('@main/views/admin/announcements/Announcements.vue'),
+            name: 'announcements',
+            meta: { titleKey: 'globals.terms.announcement', titleCount: 2 },
+            children: [
+              {
+                path: '',
+                name: 'announcement-list',
+                component: () => import('@main/views/admin/announcements/AnnouncementList.vue')
+              },
+              {
+                path: ':id/edit',
+                props: true,
+                name: 'edit-announcement',
+                component: () =>
+                  import('@main/views/admin/announcements/CreateEditAnnouncement.vue'),
+                meta: { titleKey: 'announcement.edit' }
+              },
+              {
+                path: 'new',
+                name: 'new-announcement',
+                component: () =>
+                  import('@main/views/admin/announcements/CreateEditAnnouncement.vue'),
+                meta: { titleKey: 'announcement.new' }
+              }
+            ]
+          },
           {
             path: 'context-links',
             component: () => import('@main/views/admin/context-links/ContextLinks.vue'),
@@ -503,6 +549,11 @@ const routes = [
                 component: () => import('@main/views/admin/tags/TagsView.vue'),
                 meta: { titleKey: 'globals.terms.tag', titleCount: 2 }
               },
+              {
+                path: 'assets',
+                component: () => import('@main/views/admin/assets/AssetsView.vue'),
+                meta: { titleKey: 'globals.terms.asset', titleCount: 2 }
+              },
               {
                 path: 'statuses',
                 component: () => import('@main/views/admin/status/StatusView.vue'),
@@ -0,0 +1,52 @@
+import { ref, computed } from 'vue'
+import { defineStore } from 'pinia'
+import { handleHTTPError } from '@shared-ui/utils/http.js'
+import { useEmitter } from '@main/composables/useEmitter'
+import { EMITTER_EVENTS } from '@main/constants/emitterEvents'
+import api from '@main/api'
+
+export const useAnnouncementStore = defineStore('announcement', () => {
+  const announcements = ref([])
+  const emitter = useEmitter()
+
+  // Announcements the agent has not acknowledged yet and that have not ended.
+  const pending = computed(() =>
+    announcements.value.filter(
+      (a) => !a.acknowledged && (!a.ends_at || new Date(a.ends_at) > new Date())
+    )
+  )
+
+  const fetchActive = async () => {
+    try {
+      const response = await api.getActiveAnnouncements()
+      announcements.value = response?.data?.data || []
+    } catch {
+      // pass
+    }
+  }
+
+  const remove = (id) => {
+    announcements.value = announcements.value.filter((a) => a.id !== id)
+  }
+
+  const acknowledge = async (id) => {
+    try {
+      await api.acknowledgeAnnouncement(id)
+      const announcement = announcements.value.find((a) => a.id === id)
+      if (announcement) announcement.acknowledged = true
+    } catch (error) {
+      emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+        variant: 'destructive',
+        description: handleHTTPError(error).message
+      })
+    }
+  }
+
+  return {
+    announcements,
+    pending,
+    fetchActive,
+    remove,
+    acknowledge
+  }
+})
@@ -16,6 +16,7 @@ import api from '../api'
 
 export const useConversationStore = defineStore('conversation', () => {
   const CONV_LIST_PAGE_SIZE = 50
+  const CONV_LIST_PAGE_SIZES = [25, 50, 100]
   const MESSAGE_LIST_PAGE_SIZE = 30
   const priorities = ref([])
   const statuses = ref([])
@@ -96,6 +97,7 @@ export const useConversationStore = defineStore('conversation', () => {
     listType: null,
     status: 'Open',
     sortField: 'newest',
+    pageSize: CONV_LIST_PAGE_SIZE,
     listFilters: [],
     viewID: 0,
     teamID: 0,
@@ -122,6 +124,9 @@ export const useConversationStore = defineStore('conversation', () => {
     version: 0,
   })
 
+  // Saved sort and page size of conversation lists keyed by list key, loaded on first list fetch.
+  const listPreferences = ref(null)
+
   let seenConversationUUIDs = new Map()
   const emitter = useEmitter()
 
@@ -142,10 +147,64 @@ export const useConversationStore = defineStore('conversation', () => {
   function setListSortField (field) {
     if (conversations.sortField === field) return
     conversations.sortField = field
+    saveListPreference()
+    resetConversations()
+    reFetchConversationsList()
+  }
+
+  function setListPageSize (size) {
+    if (conversations.pageSize === size) return
+    conversations.pageSize = size
+    saveListPreference()
     resetConversations()
     reFetchConversationsList()
   }
 
+  // Key of a conversation list in the saved list preferences.
+  function getListKey (listType, teamID, viewID) {
+    if (listType === CONVERSATION_LIST_TYPE.TEAM_UNASSIGNED) return `${listType}:${teamID}`
+    if (listType === CONVERSATION_LIST_TYPE.VIEW) return `${listType}:${viewID}`
+    return listType
+  }
+
+  // Applies the saved sort and page size of a list, lists without a saved preference use the defaults.
+  async function applyListPreference (listType, teamID, viewID) {
+    if (listPreferences.value === null) {
+      try {
+        const response = await api.getViewPreferences()
+        listPreferences.value = Object.fromEntries(response.data.data.map(p => [p.view_key, p]))
+      } catch (error) {
+        listPreferences.value = {}
+      }
+    }
+    const pref = listPreferences.value[getListKey(listType, teamID, viewID)]
+    conversations.sortField = Object.keys(sortFieldMap).find(key =>
+      pref &&
+      sortFieldMap[key].model + '.' + sortFieldMap[key].field === pref.order_by &&
+      sortFieldMap[key].order === pref.order
+    ) || 'newest'
+    conversations.pageSize = pref?.page_size || CONV_LIST_PAGE_SIZE
+  }
+
+  async function saveListPreference () {
+    const sort = sortFieldMap[conversations.sortField]
+    const pref = {
+      view_key: getListKey(conversations.listType, conversations.teamID, conversations.viewID),
+      order_by: sort.model + '.' + sort.field,
+      order: sort.order,
+      page_size: conversations.pageSize
+    }
+    try {
+      const response = await api.updateViewPreference(pref)
+      listPreferences.value[pref.view_key] = response.data.data
+    } catch (error) {
+      emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+        variant: 'destructive',
+        description: handleHTTPError(error).message
+      })
+    }
+  }
+
   const getListSortField = computed(() => {
     const i18n = getI18n()
     const t = i18n?.global?.t || ((key) => key.split('.').pop())
@@ -290,7 +349,8 @@ export const useConversationStore = defineStore('conversation', () => {
       latestMessage,
       conv.contact?.email || '',
       inboxEmail,
-      conv?.inbox_reply_to || ''
+      conv?.inbox_reply_to || '',
+      conv?.inbox_previous_addresses || []
     )
     currentTo.value = to
     currentCC.value = cc
@@ -418,6 +478,7 @@ export const useConversationStore = defineStore('conversation', () => {
     if (!listType) return
     if (conversations.listType !== listType || conversations.teamID !== teamID || conversations.viewID !== viewID) {
       resetConversations()
+      await applyListPreference(listType, teamID, viewID)
     }
     if (listType) conversations.listType = listType
     if (teamID) conversations.teamID = teamID
@@ -453,7 +514,7 @@ export const useConversationStore = defineStore('conversation', () => {
       case CONVERSATION_LIST_TYPE.ASSIGNED:
         return await api.getAssignedConversations({
           page: page,
-          page_size: CONV_LIST_PAGE_SIZE,
+          page_size: conversations.pageSize,
           order_by: sortFieldMap[conversations.sortField].model + "." + sortFieldMap[conversations.sortField].field,
           order: sortFieldMap[conversations.sortField].order,
           filters
@@ -461,7 +522,7 @@ export const useConversationStore = defineStore('conversation', () => {
       case CONVERSATION_LIST_TYPE.UNASSIGNED:
         return await api.getUnassignedConversations({
           page: page,
-          page_size: CONV_LIST_PAGE_SIZE,
+          page_size: conversations.pageSize,
           order_by: sortFieldMap[conversations.sortField].model + "." + sortFieldMap[conversations.sortField].field,
           order: sortFieldMap[conversations.sortField].order,
           filters
@@ -469,7 +530,7 @@ export const useConversationStore = defineStore('conversation', () => {
       case CONVERSATION_LIST_TYPE.ALL:
         return await api.getAllConversations({
           page: page,
-          page_size: CONV_LIST_PAGE_SIZE,
+          page_size: conversations.pageSize,
           order_by: sortFieldMap[conversations.sortField].model + "." + sortFieldMap[conversations.sortField].field,
           order: sortFieldMap[conversations.sortField].order,
           filters
@@ -477,7 +538,7 @@ export const useConversationStore = defineStore('conversation', () => {
       case CONVERSATION_LIST_TYPE.TEAM_UNASSIGNED:
         return await api.getTeamUnassignedConversations(teamID, {
           page: page,
-          page_size: CONV_LIST_PAGE_SIZE,
+          page_size: conversations.pageSize,
           order_by: sortFieldMap[conversations.sortField].model + "." + sortFieldMap[conversations.sortField].field,
           order: sortFieldMap[conversations.sortField].order,
           filters
@@ -485,14 +546,14 @@ export const useConversationStore = defineStore('conversation', () => {
       case CONVERSATION_LIST_TYPE.VIEW:
         return await api.getViewConversations(viewID, {
           page: page,
-          page_size: CONV_LIST_PAGE_SIZE,
+          page_size: conversations.pageSize,
           order_by: sortFieldMap[conversations.sortField].model + "." + sortFieldMap[conversations.sortField].field,
           order: sortFieldMap[conversations.sortField].order
         })
       case CONVERSATION_LIST_TYPE.MENTIONED:
         return await api.getMentionedConversations({
           page: page,
-          page_size: CONV_LIST_PAGE_SIZE,
+          page_size: conversations.pageSize,
           order_by: sortFieldMap[conversations.sortField].model + "." + sortFieldMap[conversations.sortField].field,
           order: sortFieldMap[conversations.sortField].order,
           filters
@@ -561,6 +622,29 @@ export const useConversationStore = defineStore('conversation', () => {
     }
   }
 
+  // Marking as spam also blocks the contact's future mail in the inbox, marking as not spam unblocks it.
+  async function markSpam () {
+    try {
+      await api.markConversationSpam(conversation.data.uuid)
+    } catch (error) {
+      emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+        variant: 'destructive',
+        description: handleHTTPError(error).message
+      })
+    }
+  }
+
+  async function markNotSpam () {
+    try {
+      await api.markConversationNotSpam(conversation.data.uuid)
+    } catch (error) {
+      emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+        variant: 'destructive',
+        description: handleHTTPError(error).message
+      })
+    }
+  }
+
   async function snoozeConversation (snoozeDuration) {
     try {
       await api.updateConversationStatus(conversation.data.uuid, { status: CONVERSATION_DEFAULT_STATUSES.SNOOZED, snoozed_until: snoozeDuration })
@@ -572,6 +656,17 @@ export const useConversationStore = defineStore('conversation', () => {
     }
   }
 
+  async function snoozeConversationWithPreset (presetId) {
+    try {
+      await api.updateConversationStatus(conversation.data.uuid, { status: CONVERSATION_DEFAULT_STATUSES.SNOOZED, snooze_preset_id: presetId })
+    } catch (error) {
+      emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+        variant: 'destructive',
+        description: handleHTTPError(error).message
+      })
+    }
+  }
+
   async function upsertTags (v) {
     try {
       await api.upsertTags(conversation.data.uuid, v)
@@ -940,6 +1035,9 @@ export const useConversationStore = defineStore('conversation', () => {
     markAsUnread,
     updateConversationMessage,
     snoozeConversation,
+    markSpam,
+    markNotSpam,
+    snoozeConversationWithPreset,
     fetchConversation,
     fetchConversationsList,
     fetchMessages,
@@ -954,6 +1052,8 @@ export const useConversationStore = defineStore('conversation', () => {
     fetchStatuses,
     fetchPriorities,
     setListSortField,
+    setListPageSize,
+    CONV_LIST_PAGE_SIZES,
     setListStatus,
     removeMacroAction,
     getMacro,
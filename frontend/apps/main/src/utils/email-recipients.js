@@ -6,7 +6,7 @@ export function stripConvUUID (email) {
     return email.replace(/\+conv-[a-f0-9]{8}-[a-f0-9]{4}-4[a-f0-9]{3}-[a-f0-9]{4}-[a-f0-9]{12}@/i, '@')
 }
 
-export function computeRecipientsFromMessage (message, contactEmail, inboxEmail, inboxReplyTo = '') {
+export function computeRecipientsFromMessage (message, contactEmail, inboxEmail, inboxReplyTo = '', previousAddresses = []) {
     const meta = message?.meta || {}
     const isIncoming = message.type === 'incoming'
 
@@ -41,7 +41,8 @@ export function computeRecipientsFromMessage (message, contactEmail, inboxEmail,
         }
     }
 
-    const inboxAddresses = [inboxEmail, inboxReplyTo]
+    // Addresses the inbox used before its address was migrated are excluded too.
+    const inboxAddresses = [inboxEmail, inboxReplyTo, ...(previousAddresses || [])]
         .filter(Boolean)
         .map(e => e.toLowerCase())
     const clean = list =>
@@ -123,6 +123,18 @@ describe('computeRecipientsFromMessage', () => {
             expect(result.cc).toContain('support@domain.com')
             expect(result.cc).toContain('other@domain.com')
         })
+
+        test('removes previous inbox addresses and their plus-addressed variants', () => {
+            const message = {
+                type: 'incoming',
+                meta: {
+                    from: ['customer@example.com'],
+                    to: ['Support@old-domain.com', 'support+conv-13216cf7-6626-4b0d-a938-46ce65a20701@old-domain.com', 'other@domain.com']
+                }
+            }
+            const result = computeRecipientsFromMessage(message, contactEmail, inboxEmail, '', ['support@old-domain.com'])
+            expect(result.cc).toEqual(['other@domain.com'])
+        })
     })
 
     describe('incoming message handling', () => {
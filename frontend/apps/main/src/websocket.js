@@ -1,5 +1,6 @@
 import { useConversationStore } from './stores/conversation'
 import { useNotificationStore } from './stores/notification'
+import { useAnnouncementStore } from './stores/announcement'
 import { WS_EVENT, WS_EPHEMERAL_TYPES } from './constants/websocket'
 import { playNotificationSound } from '@shared-ui/composables/useNotificationSound'
 
@@ -16,6 +17,7 @@ export class WebSocketClient {
     this.lastPong = Date.now()
     this.convStore = useConversationStore()
     this.notificationStore = useNotificationStore()
+    this.announcementStore = useAnnouncementStore()
     this.messageQueue = []
     this.maxQueueSize = 50
     // 30 sec.
@@ -89,7 +91,10 @@ export class WebSocketClient {
           this.convStore.updateTypingStatus(data.data)
         },
         // New notification.
-        [WS_EVENT.NEW_NOTIFICATION]: () => this.notificationStore.addNotification(data.data)
+        [WS_EVENT.NEW_NOTIFICATION]: () => this.notificationStore.addNotification(data.data),
+        // Announcements are refetched so acknowledgements and schedules stay in sync.
+        [WS_EVENT.ANNOUNCEMENT]: () => this.announcementStore.fetchActive(),
+        [WS_EVENT.ANNOUNCEMENT_DELETED]: () => this.announcementStore.remove(data.data.id)
       }
 
       const handler = handlers[data.type]
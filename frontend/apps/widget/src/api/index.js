@@ -43,7 +43,7 @@ export function registerStores (stores) {
 }
 
 // Clears all session state, cookies, and closes widget on 401/session expiry.
-function handleSessionExpired () {
+export function handleSessionExpired () {
     if (!_stores) return
     const { userStore, chatStore, widgetStore } = _stores
     userStore.clearSessionToken()
@@ -132,7 +132,11 @@ const getLanguage = (lang) => http.get(`/api/v1/lang/${lang}`)
 const getAvailableLanguages = () => http.get('/api/v1/lang')
 const exchangeJWTForSession = (jwt) => http.post('/api/v1/widget/chat/auth/exchange', { jwt })
 const getAuthMe = () => http.get('/api/v1/widget/chat/auth/me')
-const initChatConversation = (data) => http.post('/api/v1/widget/chat/conversations/init', data)
+const identifyVisitor = (email) => http.post('/api/v1/widget/chat/identify', { email })
+const initChatConversation = (data, captchaToken) =>
+  http.post('/api/v1/widget/chat/conversations/init', data, {
+    headers: captchaToken ? { 'X-Captcha-Token': captchaToken } : {}
+  })
 const getChatConversations = () => http.get('/api/v1/widget/chat/conversations')
 const getChatConversation = (uuid) => http.get(`/api/v1/widget/chat/conversations/${uuid}`)
 const sendChatMessage = (uuid, data) => http.post(`/api/v1/widget/chat/conversations/${uuid}/message`, data)
@@ -149,8 +153,8 @@ const uploadMedia = (conversationUUID, files) => {
     })
 }
 const updateConversationLastSeen = (uuid) => http.post(`/api/v1/widget/chat/conversations/${uuid}/update-last-seen`)
-const submitCSATResponse = (csatUuid, rating, feedback) =>
-    http.post(`/api/v1/csat/${csatUuid}/response`, {
+const submitCSATResponse = (csatToken, rating, feedback) =>
+    http.post(`/api/v1/csat/${csatToken}/response`, {
         rating,
         feedback,
     })
@@ -161,6 +165,7 @@ export default {
     getAvailableLanguages,
     exchangeJWTForSession,
     getAuthMe,
+    identifyVisitor,
     initChatConversation,
     getChatConversations,
     getChatConversation,
@@ -13,6 +13,9 @@ export const useChatStore = defineStore('chat', () => {
     const isTyping = ref(false)
     const currentConversation = ref({})
     const conversations = ref(null)
+    // Set when the contact explicitly chose to start a new conversation instead of
+    // continuing their active one.
+    const startingNewConversation = ref(false)
     // Conversation messages cache, evict old conversation messages after 50 conversations.
     const messageCache = reactive(new MessageCache(50))
     const isLoadingConversations = ref(false)
@@ -276,6 +279,7 @@ export const useChatStore = defineStore('chat', () => {
         currentConversation,
         isLoadingConversations,
         isLoadingConversation,
+        startingNewConversation,
 
         // Getters
         getCurrentConversationMessages,
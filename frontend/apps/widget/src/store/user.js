@@ -8,12 +8,14 @@ export const useUserStore = defineStore('user', () => {
     const userID = ref(null)
     const firstName = ref('')
     const lastName = ref('')
+    const hasEmail = ref(false)
 
-    const setUserMeta = ({ user_id, is_visitor, first_name, last_name }) => {
+    const setUserMeta = ({ user_id, is_visitor, first_name, last_name, has_email }) => {
         userID.value = user_id || null
         isVisitor.value = is_visitor !== undefined ? is_visitor : true
         firstName.value = first_name || ''
         lastName.value = last_name || ''
+        hasEmail.value = !!has_email
     }
 
     const clearSessionToken = () => {
@@ -23,6 +25,7 @@ export const useUserStore = defineStore('user', () => {
         userID.value = null
         firstName.value = ''
         lastName.value = ''
+        hasEmail.value = false
     }
 
     const setSessionToken = (token) => {
@@ -38,6 +41,7 @@ export const useUserStore = defineStore('user', () => {
         userID,
         firstName,
         lastName,
+        hasEmail,
         setUserMeta,
         clearSessionToken,
         setSessionToken
@@ -10,7 +10,8 @@ export const useWidgetStore = defineStore('widget', () => {
     const isMobileFullScreen = ref(false)
     const isExpanded = ref(false)
     const wasExpandedBeforeLeaving = ref(false)
-
+    // Page the widget is embedded on, sent along when starting a conversation.
+    const pageContext = ref({ url: '', referrer: '' })
 
     // Getters
     const isChatView = computed(() => isInChatView.value)
@@ -114,6 +115,7 @@ export const useWidgetStore = defineStore('widget', () => {
         isMobileFullScreen,
         isExpanded,
         wasExpandedBeforeLeaving,
+        pageContext,
 
         // Getters
         isChatView,
@@ -0,0 +1,137 @@
+// Package abuse flags abusive and profane incoming messages using a word list and, optionally,
+// the AI provider as a classifier.
+package abuse
+
+import (
+	"embed"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abhinavxd/libredesk/internal/abuse/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	smodels "github.com/abhinavxd/libredesk/internal/setting/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// maxClassifiedChars is the length of message text sent to the classifier.
+	maxClassifiedChars = 2000
+
+	classifierSystemPrompt = "You moderate messages customers send to a support team. " +
+		"Reply with only YES if the message is abusive, threatening, harassing or profane towards the recipient, otherwise reply with only NO."
+)
+
+type settingsStore interface {
+	GetByPrefix(prefix string) (types.JSONText, error)
+}
+
+type aiStore interface {
+	CompletionWithSystemPrompt(systemPrompt, prompt string) (string, error)
+}
+
+// Manager checks incoming messages for abuse and records flagged ones.
+type Manager struct {
+	q        queries
+	lo       *logf.Logger
+	settings settingsStore
+	ai       aiStore
+}
+
+// Opts contains options for initializing the abuse Manager.
+type Opts struct {
+	DB       *sqlx.DB
+	Lo       *logf.Logger
+	Settings settingsStore
+	AI       aiStore
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	InsertFlag *sqlx.Stmt `query:"insert-flag"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:        q,
+		lo:       opts.Lo,
+		settings: opts.Settings,
+		ai:       opts.AI,
+	}, nil
+}
+
+// Check checks the text of a message against the word list and, if enabled and the word list
+// doesn't match, the classifier. Returns a zero Verdict if detection is disabled.
+func (m *Manager) Check(text string) (models.Verdict, error) {
+	var cfg smodels.Abuse
+	b, err := m.settings.GetByPrefix("abuse")
+	if err != nil {
+		return models.Verdict{}, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return models.Verdict{}, err
+	}
+	if !cfg.Enabled || strings.TrimSpace(text) == "" {
+		return models.Verdict{}, nil
+	}
+
+	v := models.Verdict{
+		Tag:          cfg.Tag,
+		NotifyTeamID: cfg.NotifyTeamID,
+	}
+	if v.Matches = MatchWords(text, cfg.Words); len(v.Matches) > 0 {
+		v.Abusive = true
+		v.Source = models.SourceWordList
+		return v, nil
+	}
+	if cfg.Classifier && m.ai != nil {
+		abusive, err := m.classify(text)
+		if err != nil {
+			return models.Verdict{}, err
+		}
+		if abusive {
+			v.Abusive = true
+			v.Source = models.SourceClassifier
+		}
+	}
+	return v, nil
+}
+
+// classify asks the AI provider whether the text is abusive.
+func (m *Manager) classify(text string) (bool, error) {
+	if utf8.RuneCountInString(text) > maxClassifiedChars {
+		text = string([]rune(text)[:maxClassifiedChars])
+	}
+	resp, err := m.ai.CompletionWithSystemPrompt(classifierSystemPrompt, text)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(resp)), "YES"), nil
+}
+
+// Flag records an abusive message along with the agent the conversation was assigned to.
+func (m *Manager) Flag(messageID, conversationID, contactID int, assignedUserID null.Int, v models.Verdict) error {
+	matches := v.Matches
+	if matches == nil {
+		matches = []string{}
+	}
+	if _, err := m.q.InsertFlag.Exec(messageID, conversationID, contactID, assignedUserID, v.Source, pq.Array(matches)); err != nil {
+		m.lo.Error("error inserting abuse flag", "message_id", messageID, "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package abuse
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// normalize lowercases text and collapses everything that isn't a letter or a digit into single spaces,
+// padding the result with a space on both ends so that whole words can be matched with " word ".
+func normalize(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return " " + strings.Join(fields, " ") + " "
+}
+
+// MatchWords returns the words and phrases of the list that appear in the text as whole words,
+// ignoring case and punctuation.
+func MatchWords(text string, words []string) []string {
+	var (
+		norm    = normalize(text)
+		matches []string
+	)
+	if norm == "" {
+		return nil
+	}
+	for _, w := range words {
+		nw := normalize(w)
+		if nw == "" || slices.Contains(matches, w) {
+			continue
+		}
+		if strings.Contains(norm, nw) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
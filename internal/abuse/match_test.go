@@ -0,0 +1,25 @@
+package abuse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchWords(t *testing.T) {
+	words := []string{"idiot", "shut up", "Useless"}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"You IDIOT!", []string{"idiot"}},
+		{"Just shut   up, you useless lot.", []string{"shut up", "Useless"}},
+		{"The idiotic printer is broken", nil},
+		{"shutup", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := MatchWords(tt.text, words); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchWords(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+const (
+	// SourceWordList flags messages containing words of the word list.
+	SourceWordList = "word_list"
+	// SourceClassifier flags messages the AI provider classified as abusive.
+	SourceClassifier = "classifier"
+)
+
+// Verdict is the result of checking a message for abuse, with the actions to take on abusive messages.
+type Verdict struct {
+	Abusive bool
+	Source  string
+	Matches []string
+	// Tag is applied to the conversation, empty for none.
+	Tag string
+	// NotifyTeamID is the team whose members are notified, 0 for none.
+	NotifyTeamID int
+}
@@ -0,0 +1,4 @@
+-- name: insert-flag
+INSERT INTO abuse_flags (message_id, conversation_id, contact_id, assigned_user_id, "source", matches)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (message_id) DO NOTHING;
@@ -261,6 +261,28 @@ func (al *Manager) RolePermissionsChanged(actorID int, actorEmail, ip string, ro
 	)
 }
 
+// ContactRiskFlagsChanged records a change of the risk flags of a contact.
+func (al *Manager) ContactRiskFlagsChanged(actorID int, actorEmail, ip string, contactID int, contactEmail string, flags []string) error {
+	list := "-"
+	if len(flags) > 0 {
+		list = strings.Join(flags, ", ")
+	}
+	description := al.i18n.Ts("activityLog.contactRiskFlagsChanged",
+		"actorEmail", actorEmail,
+		"actorId", fmt.Sprintf("#%d", actorID),
+		"targetEmail", contactEmail,
+		"targetId", fmt.Sprintf("#%d", contactID),
+		"flags", list)
+	return al.create(
+		models.ContactRiskFlagsChanged,
+		description,
+		actorID,
+		"user",
+		contactID,
+		ip,
+	)
+}
+
 // create creates a new activity log in DB.
 func (m *Manager) create(activityType, activityDescription string, actorID int, targetModelType string, targetModelID int, ip string) error {
 	if _, err := m.q.InsertActivity.Exec(activityType, activityDescription, actorID, targetModelType, targetModelID, ip); err != nil {
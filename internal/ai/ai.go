@@ -67,7 +67,11 @@ func (m *Manager) Completion(k string, prompt string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return m.CompletionWithSystemPrompt(systemPrompt, prompt)
+}
 
+// CompletionWithSystemPrompt sends a prompt with the given system prompt to the default provider and returns the response.
+func (m *Manager) CompletionWithSystemPrompt(systemPrompt, prompt string) (string, error) {
 	client, err := m.getDefaultProviderClient()
 	if err != nil {
 		m.lo.Error("error getting provider client", "error", err)
@@ -96,6 +100,23 @@ func (m *Manager) Completion(k string, prompt string) (string, error) {
 	return response, nil
 }
 
+// Embed returns the embedding vectors of the given inputs from the default provider, in input order.
+func (m *Manager) Embed(inputs []string) ([][]float64, error) {
+	client, err := m.getDefaultProviderClient()
+	if err != nil {
+		return nil, err
+	}
+	embeddings, err := client.Embed(inputs)
+	if err != nil {
+		if errors.Is(err, ErrApiKeyNotSet) {
+			return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("ai.apiKeyNotSet", "provider", "OpenAI"), nil)
+		}
+		m.lo.Error("error fetching embeddings from provider", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return embeddings, nil
+}
+
 // GetPrompts returns a list of prompts from the database.
 func (m *Manager) GetPrompts() ([]models.Prompt, error) {
 	var prompts = make([]models.Prompt, 0)
@@ -91,3 +91,68 @@ func (o *OpenAIClient) SendPrompt(payload PromptPayload) (string, error) {
 	}
 	return "", fmt.Errorf("no response found")
 }
+
+// Embed returns the embedding vectors of the given inputs from the OpenAI API, in input order.
+func (o *OpenAIClient) Embed(inputs []string) ([][]float64, error) {
+	if o.apikey == "" {
+		return nil, ErrApiKeyNotSet
+	}
+
+	apiURL := "https://api.openai.com/v1/embeddings"
+	requestBody := map[string]interface{}{
+		"model": "text-embedding-3-small",
+		"input": inputs,
+	}
+
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	req, err := http.NewRequest(fasthttp.MethodPost, apiURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+o.apikey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		o.lo.Error("error making HTTP request", "error", err)
+		return nil, fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		o.lo.Error("non-ok response received from openai API", "status", resp.Status, "code", resp.StatusCode, "response_text", body)
+		return nil, fmt.Errorf("API error: %s, body: %s", resp.Status, body)
+	}
+
+	var responseBody struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
+		return nil, fmt.Errorf("decoding response body: %w", err)
+	}
+	if len(responseBody.Data) != len(inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(inputs), len(responseBody.Data))
+	}
+
+	out := make([][]float64, len(inputs))
+	for _, d := range responseBody.Data {
+		if d.Index < 0 || d.Index >= len(out) {
+			return nil, fmt.Errorf("invalid embedding index %d", d.Index)
+		}
+		out[d.Index] = d.Embedding
+	}
+	return out, nil
+}
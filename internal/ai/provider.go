@@ -3,6 +3,7 @@ package ai
 // ProviderClient is the interface all providers should implement.
 type ProviderClient interface {
 	SendPrompt(payload PromptPayload) (string, error)
+	Embed(inputs []string) ([][]float64, error)
 }
 
 // ProviderType is an enum-like type for different providers.
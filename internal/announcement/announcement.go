@@ -0,0 +1,231 @@
+// Package announcement handles workspace announcement banners shown to agents.
+package announcement
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/announcement/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	wsmodels "github.com/abhinavxd/libredesk/internal/ws/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/lib/pq"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+// WS message types for announcements.
+const (
+	MessageTypeAnnouncement        = "announcement"
+	MessageTypeAnnouncementDeleted = "announcement_deleted"
+)
+
+type wsHub interface {
+	BroadcastMessage(msg wsmodels.BroadcastMessage)
+}
+
+// Manager manages announcements.
+type Manager struct {
+	q     queries
+	lo    *logf.Logger
+	i18n  *i18n.I18n
+	wsHub wsHub
+}
+
+// Opts contains options for initializing the announcement Manager.
+type Opts struct {
+	DB    *sqlx.DB
+	Lo    *logf.Logger
+	I18n  *i18n.I18n
+	WSHub wsHub
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAll                *sqlx.Stmt `query:"get-all-announcements"`
+	Get                   *sqlx.Stmt `query:"get-announcement"`
+	GetActive             *sqlx.Stmt `query:"get-active-announcements"`
+	GetPendingBroadcast   *sqlx.Stmt `query:"get-pending-broadcast-announcements"`
+	GetAudience           *sqlx.Stmt `query:"get-announcement-audience"`
+	Insert                *sqlx.Stmt `query:"insert-announcement"`
+	Update                *sqlx.Stmt `query:"update-announcement"`
+	SetBroadcast          *sqlx.Stmt `query:"set-announcement-broadcast"`
+	Delete                *sqlx.Stmt `query:"delete-announcement"`
+	InsertAcknowledgement *sqlx.Stmt `query:"insert-acknowledgement"`
+	GetAcknowledgements   *sqlx.Stmt `query:"get-acknowledgements"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:     q,
+		lo:    opts.Lo,
+		i18n:  opts.I18n,
+		wsHub: opts.WSHub,
+	}, nil
+}
+
+// GetAll returns all announcements.
+func (m *Manager) GetAll() ([]models.Announcement, error) {
+	var announcements = make([]models.Announcement, 0)
+	if err := m.q.GetAll.Select(&announcements); err != nil {
+		m.lo.Error("error fetching announcements", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return announcements, nil
+}
+
+// Get returns an announcement by ID.
+func (m *Manager) Get(id int) (models.Announcement, error) {
+	var announcement models.Announcement
+	if err := m.q.Get.Get(&announcement, id); err != nil {
+		if err == sql.ErrNoRows {
+			return announcement, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching announcement", "error", err)
+		return announcement, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return announcement, nil
+}
+
+// GetActive returns the announcements currently visible to the given agent.
+func (m *Manager) GetActive(userID int, teamIDs []int) ([]models.Announcement, error) {
+	var announcements = make([]models.Announcement, 0)
+	if err := m.q.GetActive.Select(&announcements, userID, pq.Array(teamIDs)); err != nil {
+		m.lo.Error("error fetching active announcements", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return announcements, nil
+}
+
+// Create creates a new announcement and broadcasts it if it is already active.
+func (m *Manager) Create(a models.Announcement) (models.Announcement, error) {
+	var result models.Announcement
+	if err := m.q.Insert.Get(&result, a.Message, a.Severity, a.StartsAt, a.EndsAt, a.TeamIDs, a.CreatedByID); err != nil {
+		m.lo.Error("error inserting announcement", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.broadcastPending()
+	return result, nil
+}
+
+// Update updates an announcement and re-broadcasts it to agents once active.
+func (m *Manager) Update(id int, a models.Announcement) (models.Announcement, error) {
+	var result models.Announcement
+	if err := m.q.Update.Get(&result, id, a.Message, a.Severity, a.StartsAt, a.EndsAt, a.TeamIDs); err != nil {
+		if err == sql.ErrNoRows {
+			return result, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error updating announcement", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.broadcastPending()
+	return result, nil
+}
+
+// Delete deletes an announcement and tells connected agents to hide it.
+func (m *Manager) Delete(id int) error {
+	var teamIDs pq.Int64Array
+	if err := m.q.Delete.Get(&teamIDs, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		m.lo.Error("error deleting announcement", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.broadcast(MessageTypeAnnouncementDeleted, map[string]int{"id": id}, teamIDs)
+	return nil
+}
+
+// Acknowledge records that an agent has acknowledged an announcement.
+func (m *Manager) Acknowledge(id, userID int) error {
+	if _, err := m.q.InsertAcknowledgement.Exec(id, userID); err != nil {
+		if dbutil.IsForeignKeyError(err) {
+			return envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error acknowledging announcement", "id", id, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// GetAcknowledgements returns the agents who acknowledged an announcement.
+func (m *Manager) GetAcknowledgements(id int) ([]models.Acknowledgement, error) {
+	var acks = make([]models.Acknowledgement, 0)
+	if err := m.q.GetAcknowledgements.Select(&acks, id); err != nil {
+		m.lo.Error("error fetching announcement acknowledgements", "id", id, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return acks, nil
+}
+
+// Run periodically broadcasts scheduled announcements once their start time is reached.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.broadcastPending()
+		}
+	}
+}
+
+// broadcastPending broadcasts active announcements that have not been pushed to agents yet.
+func (m *Manager) broadcastPending() {
+	var announcements []models.Announcement
+	if err := m.q.GetPendingBroadcast.Select(&announcements); err != nil {
+		m.lo.Error("error fetching pending announcements", "error", err)
+		return
+	}
+	for _, a := range announcements {
+		m.broadcast(MessageTypeAnnouncement, a, a.TeamIDs)
+		if _, err := m.q.SetBroadcast.Exec(a.ID); err != nil {
+			m.lo.Error("error marking announcement as broadcast", "id", a.ID, "error", err)
+		}
+	}
+}
+
+// broadcast pushes a WS message to the members of the given teams, or to all agents if no teams are set.
+func (m *Manager) broadcast(typ string, data any, teamIDs pq.Int64Array) {
+	var users = []int{}
+	if len(teamIDs) > 0 {
+		if err := m.q.GetAudience.Select(&users, teamIDs); err != nil {
+			m.lo.Error("error fetching announcement audience", "error", err)
+			return
+		}
+		// No members in the audience teams, nothing to push.
+		if len(users) == 0 {
+			return
+		}
+	}
+
+	message, err := json.Marshal(wsmodels.Message{
+		Type: typ,
+		Data: data,
+	})
+	if err != nil {
+		m.lo.Error("error marshalling announcement WS message", "error", err)
+		return
+	}
+	m.wsHub.BroadcastMessage(wsmodels.BroadcastMessage{
+		Data:  message,
+		Users: users,
+	})
+}
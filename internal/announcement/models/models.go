@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// Announcement severities.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Announcement is a workspace wide banner shown to agents.
+type Announcement struct {
+	ID          int           `db:"id" json:"id"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at" json:"updated_at"`
+	Message     string        `db:"message" json:"message"`
+	Severity    string        `db:"severity" json:"severity"`
+	StartsAt    null.Time     `db:"starts_at" json:"starts_at"`
+	EndsAt      null.Time     `db:"ends_at" json:"ends_at"`
+	TeamIDs     pq.Int64Array `db:"team_ids" json:"team_ids"`
+	CreatedByID null.Int      `db:"created_by_id" json:"created_by_id"`
+	BroadcastAt null.Time     `db:"broadcast_at" json:"-"`
+
+	// Acknowledgement stats, only set when listing for admins.
+	AcknowledgedCount int `db:"acknowledged_count" json:"acknowledged_count"`
+
+	// Whether the requesting agent has acknowledged the announcement.
+	Acknowledged bool `db:"acknowledged" json:"acknowledged"`
+}
+
+// Acknowledgement is an agent's acknowledgement of an announcement.
+type Acknowledgement struct {
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UserID    int       `db:"user_id" json:"user_id"`
+	FirstName string    `db:"first_name" json:"first_name"`
+	LastName  string    `db:"last_name" json:"last_name"`
+	Email     string    `db:"email" json:"email"`
+}
@@ -0,0 +1,134 @@
+-- name: get-all-announcements
+SELECT
+    a.id,
+    a.created_at,
+    a.updated_at,
+    a.message,
+    a.severity,
+    a.starts_at,
+    a.ends_at,
+    a.team_ids,
+    a.created_by_id,
+    a.broadcast_at,
+    (SELECT COUNT(*) FROM announcement_acknowledgements aa WHERE aa.announcement_id = a.id) AS acknowledged_count
+FROM
+    announcements a
+ORDER BY a.created_at DESC;
+
+-- name: get-announcement
+SELECT
+    a.id,
+    a.created_at,
+    a.updated_at,
+    a.message,
+    a.severity,
+    a.starts_at,
+    a.ends_at,
+    a.team_ids,
+    a.created_by_id,
+    a.broadcast_at,
+    (SELECT COUNT(*) FROM announcement_acknowledgements aa WHERE aa.announcement_id = a.id) AS acknowledged_count
+FROM
+    announcements a
+WHERE
+    a.id = $1;
+
+-- name: get-active-announcements
+-- $1 = user ID, $2 = team IDs of the user.
+SELECT
+    a.id,
+    a.created_at,
+    a.updated_at,
+    a.message,
+    a.severity,
+    a.starts_at,
+    a.ends_at,
+    a.team_ids,
+    a.created_by_id,
+    EXISTS (
+        SELECT 1 FROM announcement_acknowledgements aa
+        WHERE aa.announcement_id = a.id AND aa.user_id = $1
+    ) AS acknowledged
+FROM
+    announcements a
+WHERE
+    (a.starts_at IS NULL OR a.starts_at <= NOW())
+    AND (a.ends_at IS NULL OR a.ends_at > NOW())
+    AND (cardinality(a.team_ids) = 0 OR a.team_ids && $2::INT[])
+ORDER BY
+    CASE a.severity WHEN 'critical' THEN 0 WHEN 'warning' THEN 1 ELSE 2 END,
+    a.created_at DESC;
+
+-- name: get-pending-broadcast-announcements
+SELECT
+    id,
+    created_at,
+    updated_at,
+    message,
+    severity,
+    starts_at,
+    ends_at,
+    team_ids,
+    created_by_id
+FROM
+    announcements
+WHERE
+    broadcast_at IS NULL
+    AND (starts_at IS NULL OR starts_at <= NOW())
+    AND (ends_at IS NULL OR ends_at > NOW());
+
+-- name: get-announcement-audience
+SELECT DISTINCT user_id FROM team_members WHERE team_id = ANY($1::INT[]);
+
+-- name: insert-announcement
+INSERT INTO
+    announcements (message, severity, starts_at, ends_at, team_ids, created_by_id)
+VALUES
+    ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: update-announcement
+UPDATE
+    announcements
+SET
+    message = $2,
+    severity = $3,
+    starts_at = $4,
+    ends_at = $5,
+    team_ids = $6,
+    broadcast_at = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING *;
+
+-- name: set-announcement-broadcast
+UPDATE announcements SET broadcast_at = NOW() WHERE id = $1;
+
+-- name: delete-announcement
+DELETE FROM
+    announcements
+WHERE
+    id = $1
+RETURNING team_ids;
+
+-- name: insert-acknowledgement
+INSERT INTO
+    announcement_acknowledgements (announcement_id, user_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (announcement_id, user_id) DO NOTHING;
+
+-- name: get-acknowledgements
+SELECT
+    aa.created_at,
+    aa.user_id,
+    u.first_name,
+    COALESCE(u.last_name, '') AS last_name,
+    COALESCE(u.email, '') AS email
+FROM
+    announcement_acknowledgements aa
+    INNER JOIN users u ON u.id = aa.user_id
+WHERE
+    aa.announcement_id = $1
+ORDER BY aa.created_at DESC;
@@ -0,0 +1,231 @@
+// Package asset handles the shared library of files agents can attach to replies.
+package asset
+
+import (
+	"bytes"
+	"database/sql"
+	"embed"
+	"io"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/asset/models"
+	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/image"
+	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+type mediaStore interface {
+	Get(id int, uuid string) (mmodels.Media, error)
+	GetBlob(name string) ([]byte, error)
+	GetURL(uuid, contentType, fileName string) string
+	Upload(fileName, contentType string, content io.ReadSeeker) (string, string, error)
+	UploadAndInsert(srcFilename, contentType, contentID string, modelType null.String, modelID null.Int, content io.ReadSeeker, fileSize int, disposition null.String, meta []byte) (mmodels.Media, error)
+	Attach(id int, model string, modelID int) error
+	Delete(name string) error
+}
+
+// Manager manages assets.
+type Manager struct {
+	q          queries
+	lo         *logf.Logger
+	i18n       *i18n.I18n
+	mediaStore mediaStore
+}
+
+// Opts contains options for initializing the asset Manager.
+type Opts struct {
+	DB         *sqlx.DB
+	Lo         *logf.Logger
+	I18n       *i18n.I18n
+	MediaStore mediaStore
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAll       *sqlx.Stmt `query:"get-all-assets"`
+	GetAvailable *sqlx.Stmt `query:"get-available-assets"`
+	Get          *sqlx.Stmt `query:"get-asset"`
+	Insert       *sqlx.Stmt `query:"insert-asset"`
+	Update       *sqlx.Stmt `query:"update-asset"`
+	Delete       *sqlx.Stmt `query:"delete-asset"`
+	RecordUsage  *sqlx.Stmt `query:"record-asset-usage"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:          q,
+		lo:         opts.Lo,
+		i18n:       opts.I18n,
+		mediaStore: opts.MediaStore,
+	}, nil
+}
+
+// GetAll returns all assets.
+func (m *Manager) GetAll() ([]models.Asset, error) {
+	var assets = make([]models.Asset, 0)
+	if err := m.q.GetAll.Select(&assets); err != nil {
+		m.lo.Error("error fetching assets", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.setURLs(assets)
+	return assets, nil
+}
+
+// GetAvailable returns the assets visible to members of the given teams, most used first.
+func (m *Manager) GetAvailable(teamIDs []int) ([]models.Asset, error) {
+	var assets = make([]models.Asset, 0)
+	if err := m.q.GetAvailable.Select(&assets, pq.Array(teamIDs)); err != nil {
+		m.lo.Error("error fetching available assets", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.setURLs(assets)
+	return assets, nil
+}
+
+// Get returns an asset by ID.
+func (m *Manager) Get(id int) (models.Asset, error) {
+	var asset models.Asset
+	if err := m.q.Get.Get(&asset, id); err != nil {
+		if err == sql.ErrNoRows {
+			return asset, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching asset", "id", id, "error", err)
+		return asset, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	asset.URL = m.mediaStore.GetURL(asset.MediaUUID, asset.ContentType, asset.Filename)
+	return asset, nil
+}
+
+// Create creates an asset from an uploaded media file that is not attached to anything yet.
+func (m *Manager) Create(a models.Asset) (models.Asset, error) {
+	media, err := m.mediaStore.Get(a.MediaID, "")
+	if err != nil {
+		return models.Asset{}, err
+	}
+	if media.ModelID.Int > 0 {
+		return models.Asset{}, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+
+	var id int
+	if err := m.q.Insert.Get(&id, a.Name, a.Description, a.MediaID, a.TeamIDs, a.CreatedByID); err != nil {
+		m.lo.Error("error inserting asset", "error", err)
+		return models.Asset{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := m.mediaStore.Attach(a.MediaID, mmodels.ModelAssets, id); err != nil {
+		return models.Asset{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.Get(id)
+}
+
+// Update updates the name, description and teams of an asset, its file can't be changed.
+func (m *Manager) Update(id int, a models.Asset) (models.Asset, error) {
+	if err := m.q.Update.Get(&id, id, a.Name, a.Description, a.TeamIDs); err != nil {
+		if err == sql.ErrNoRows {
+			return models.Asset{}, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error updating asset", "id", id, "error", err)
+		return models.Asset{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.Get(id)
+}
+
+// Delete deletes an asset and its media file. Files already attached to messages are copies and are kept.
+func (m *Manager) Delete(id int) error {
+	asset, err := m.Get(id)
+	if err != nil {
+		if envErr, ok := err.(envelope.Error); ok && envErr.ErrorType == envelope.NotFoundError {
+			return nil
+		}
+		return err
+	}
+	if _, err := m.q.Delete.Exec(id); err != nil {
+		m.lo.Error("error deleting asset", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := m.mediaStore.Delete(asset.MediaUUID); err != nil {
+		return err
+	}
+	if strings.HasPrefix(asset.ContentType, "image/") {
+		m.mediaStore.Delete(image.ThumbPrefix + asset.MediaUUID)
+	}
+	return nil
+}
+
+// Use copies the file of an asset visible to the given teams into a new media file that can be
+// attached to a reply like an upload, and records the usage of the asset.
+func (m *Manager) Use(id int, teamIDs []int) (mmodels.Media, error) {
+	asset, err := m.Get(id)
+	if err != nil {
+		return mmodels.Media{}, err
+	}
+	if !isVisible(asset, teamIDs) {
+		return mmodels.Media{}, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+	}
+
+	source, err := m.mediaStore.Get(asset.MediaID, "")
+	if err != nil {
+		return mmodels.Media{}, err
+	}
+	blob, err := m.mediaStore.GetBlob(source.UUID)
+	if err != nil {
+		m.lo.Error("error fetching asset blob", "id", id, "media_uuid", source.UUID, "error", err)
+		return mmodels.Media{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	media, err := m.mediaStore.UploadAndInsert(source.Filename, source.ContentType, "", null.StringFrom(mmodels.ModelMessages), null.Int{},
+		bytes.NewReader(blob), len(blob), null.StringFrom(attachment.DispositionAttachment), source.Meta)
+	if err != nil {
+		return mmodels.Media{}, err
+	}
+
+	// Image attachments are previewed with their thumbnail.
+	if strings.HasPrefix(media.ContentType, "image/") {
+		if thumb, err := image.CreateThumb(image.DefThumbSize, bytes.NewReader(blob)); err != nil {
+			m.lo.Error("error creating asset thumbnail", "id", id, "error", err)
+		} else if _, _, err := m.mediaStore.Upload(image.ThumbPrefix+media.UUID, media.ContentType, thumb); err != nil {
+			m.lo.Error("error uploading asset thumbnail", "id", id, "error", err)
+		}
+	}
+
+	if _, err := m.q.RecordUsage.Exec(id); err != nil {
+		m.lo.Error("error recording asset usage", "id", id, "error", err)
+	}
+	return media, nil
+}
+
+// isVisible returns true if the asset is shared with all agents or with one of the given teams.
+func isVisible(a models.Asset, teamIDs []int) bool {
+	if len(a.TeamIDs) == 0 {
+		return true
+	}
+	for _, teamID := range a.TeamIDs {
+		for _, id := range teamIDs {
+			if int(teamID) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *Manager) setURLs(assets []models.Asset) {
+	for i := range assets {
+		assets[i].URL = m.mediaStore.GetURL(assets[i].MediaUUID, assets[i].ContentType, assets[i].Filename)
+	}
+}
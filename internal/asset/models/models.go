@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// Asset is a shared file, e.g. a warranty form, that agents can attach to replies without uploading it again.
+type Asset struct {
+	ID          int           `db:"id" json:"id"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at" json:"updated_at"`
+	Name        string        `db:"name" json:"name"`
+	Description string        `db:"description" json:"description"`
+	MediaID     int           `db:"media_id" json:"media_id"`
+	TeamIDs     pq.Int64Array `db:"team_ids" json:"team_ids"`
+	CreatedByID null.Int      `db:"created_by_id" json:"created_by_id"`
+	UsageCount  int           `db:"usage_count" json:"usage_count"`
+	LastUsedAt  null.Time     `db:"last_used_at" json:"last_used_at"`
+
+	// Fields of the media file of the asset.
+	MediaUUID   string `db:"media_uuid" json:"media_uuid"`
+	Filename    string `db:"filename" json:"filename"`
+	ContentType string `db:"content_type" json:"content_type"`
+	Size        int    `db:"size" json:"size"`
+
+	// Pseudo fields
+	URL string `db:"-" json:"url"`
+}
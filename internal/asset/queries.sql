@@ -0,0 +1,100 @@
+-- name: get-all-assets
+SELECT
+    a.id,
+    a.created_at,
+    a.updated_at,
+    a.name,
+    a.description,
+    a.media_id,
+    a.team_ids,
+    a.created_by_id,
+    a.usage_count,
+    a.last_used_at,
+    m.uuid AS media_uuid,
+    m.filename,
+    m.content_type,
+    COALESCE(m.size, 0) AS size
+FROM
+    assets a
+    INNER JOIN media m ON m.id = a.media_id
+ORDER BY a.name;
+
+-- name: get-available-assets
+-- $1 = team IDs of the agent.
+SELECT
+    a.id,
+    a.created_at,
+    a.updated_at,
+    a.name,
+    a.description,
+    a.media_id,
+    a.team_ids,
+    a.created_by_id,
+    a.usage_count,
+    a.last_used_at,
+    m.uuid AS media_uuid,
+    m.filename,
+    m.content_type,
+    COALESCE(m.size, 0) AS size
+FROM
+    assets a
+    INNER JOIN media m ON m.id = a.media_id
+WHERE
+    cardinality(a.team_ids) = 0 OR a.team_ids && $1::INT[]
+ORDER BY a.usage_count DESC, a.name;
+
+-- name: get-asset
+SELECT
+    a.id,
+    a.created_at,
+    a.updated_at,
+    a.name,
+    a.description,
+    a.media_id,
+    a.team_ids,
+    a.created_by_id,
+    a.usage_count,
+    a.last_used_at,
+    m.uuid AS media_uuid,
+    m.filename,
+    m.content_type,
+    COALESCE(m.size, 0) AS size
+FROM
+    assets a
+    INNER JOIN media m ON m.id = a.media_id
+WHERE
+    a.id = $1;
+
+-- name: insert-asset
+INSERT INTO
+    assets (name, description, media_id, team_ids, created_by_id)
+VALUES
+    ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: update-asset
+UPDATE
+    assets
+SET
+    name = $2,
+    description = $3,
+    team_ids = $4,
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING id;
+
+-- name: delete-asset
+DELETE FROM
+    assets
+WHERE
+    id = $1;
+
+-- name: record-asset-usage
+UPDATE
+    assets
+SET
+    usage_count = usage_count + 1,
+    last_used_at = NOW()
+WHERE
+    id = $1;
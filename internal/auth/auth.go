@@ -13,12 +13,10 @@ import (
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
-	"github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/knadh/go-i18n"
 	"github.com/redis/go-redis/v9"
 	"github.com/valyala/fasthttp"
-	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/fastglue"
 	"github.com/zerodha/logf"
 	sessredisstore "github.com/zerodha/simplesessions/stores/redis/v3"
@@ -234,10 +232,11 @@ func (a *Auth) SaveSession(user amodels.User, r *fastglue.Request) error {
 	}
 
 	if err := sess.SetMulti(map[string]interface{}{
-		"id":         user.ID,
-		"email":      user.Email,
-		"first_name": user.FirstName,
-		"last_name":  user.LastName,
+		"id":             user.ID,
+		"email":          user.Email,
+		"first_name":     user.FirstName,
+		"last_name":      user.LastName,
+		"password_login": user.PasswordLogin,
 	}); err != nil {
 		a.logger.Error("error setting login session", "error", err)
 		return err
@@ -306,20 +305,20 @@ func (a *Auth) SetCSRFCookie(r *fastglue.Request) error {
 }
 
 // ValidateSession validates the session and returns the user.
-func (a *Auth) ValidateSession(r *fastglue.Request) (models.User, error) {
+func (a *Auth) ValidateSession(r *fastglue.Request) (amodels.User, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
 	sess, err := a.sess.Acquire(r.RequestCtx, r, r)
 	if err != nil {
 		a.logger.Error("error acquiring session", "error", err)
-		return models.User{}, err
+		return amodels.User{}, err
 	}
 
-	sessVals, err := sess.GetMulti("id", "email", "first_name", "last_name")
+	sessVals, err := sess.GetMulti("id", "email", "first_name", "last_name", "password_login")
 	if err != nil {
 		a.logger.Error("error fetching session variables", "error", err)
-		return models.User{}, err
+		return amodels.User{}, err
 	}
 
 	var (
@@ -327,13 +326,16 @@ func (a *Auth) ValidateSession(r *fastglue.Request) (models.User, error) {
 		email, _     = sess.String(sessVals["email"], nil)
 		firstName, _ = sess.String(sessVals["first_name"], nil)
 		lastName, _  = sess.String(sessVals["last_name"], nil)
+		// Missing in sessions created before two-factor authentication was added.
+		passwordLogin, _ = sess.Bool(sessVals["password_login"], nil)
 	)
 
-	return models.User{
-		ID:        userID,
-		Email:     null.NewString(email, email != ""),
-		FirstName: firstName,
-		LastName:  lastName,
+	return amodels.User{
+		ID:            userID,
+		Email:         email,
+		FirstName:     firstName,
+		LastName:      lastName,
+		PasswordLogin: passwordLogin,
 	}, nil
 }
 
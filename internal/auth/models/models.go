@@ -6,4 +6,6 @@ type User struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Email     string `json:"email,omitempty"`
+	// PasswordLogin is set for sessions created by logging in with a password.
+	PasswordLogin bool `json:"-"`
 }
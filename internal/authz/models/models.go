@@ -13,10 +13,14 @@ const (
 	PermConversationsUpdatePriority     = "conversations:update_priority"
 	PermConversationsUpdateStatus       = "conversations:update_status"
 	PermConversationsUpdateTags         = "conversations:update_tags"
+	PermConversationsUpdateAttributes   = "conversations:update_custom_attributes"
 	PermConversationWrite               = "conversations:write"
+	PermConversationsTrash              = "conversations:trash"
 	PermMessagesRead                    = "messages:read"
 	PermMessagesWrite                   = "messages:write"
 	PermMessagesWriteAsContact          = "messages:write_as_contact"
+	PermMessagesWritePrivate            = "messages:write_private"
+	PermMessagesWriteIntegrationNotes   = "messages:write_integration_notes"
 
 	// View
 	PermViewManage        = "view:manage"
@@ -52,6 +56,12 @@ const (
 	// Context Links
 	PermContextLinksManage = "context_links:manage"
 
+	// Announcements
+	PermAnnouncementsManage = "announcements:manage"
+
+	// Assets
+	PermAssetsManage = "assets:manage"
+
 	// Templates
 	PermTemplatesManage = "templates:manage"
 
@@ -81,6 +91,8 @@ const (
 	PermContactsRead    = "contacts:read"
 	PermContactsWrite   = "contacts:write"
 	PermContactsBlock   = "contacts:block"
+	// PermContactsManageRiskFlags allows setting the warning flags shown on a contact's conversations.
+	PermContactsManageRiskFlags = "contacts:manage_risk_flags"
 
 	// Contact Notes
 	PermContactNotesRead   = "contact_notes:read"
@@ -106,10 +118,14 @@ var validPermissions = map[string]struct{}{
 	PermConversationsUpdatePriority:     {},
 	PermConversationsUpdateStatus:       {},
 	PermConversationsUpdateTags:         {},
+	PermConversationsUpdateAttributes:   {},
 	PermConversationWrite:               {},
+	PermConversationsTrash:              {},
 	PermMessagesRead:                    {},
 	PermMessagesWrite:                   {},
 	PermMessagesWriteAsContact:          {},
+	PermMessagesWritePrivate:            {},
+	PermMessagesWriteIntegrationNotes:   {},
 	PermViewManage:                      {},
 	PermSharedViewsManage:               {},
 	PermStatusManage:                    {},
@@ -133,12 +149,15 @@ var validPermissions = map[string]struct{}{
 	PermContactsRead:                    {},
 	PermContactsWrite:                   {},
 	PermContactsBlock:                   {},
+	PermContactsManageRiskFlags:         {},
 	PermContactNotesRead:                {},
 	PermContactNotesWrite:               {},
 	PermContactNotesDelete:              {},
 	PermActivityLogsManage:              {},
 	PermWebhooksManage:                  {},
 	PermContextLinksManage:              {},
+	PermAnnouncementsManage:             {},
+	PermAssetsManage:                    {},
 }
 
 // PermissionExists returns true if the permission exists else false
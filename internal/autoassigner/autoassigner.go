@@ -37,6 +37,10 @@ type teamStore interface {
 	GetMembers(teamID int) ([]tmodels.TeamMember, error)
 }
 
+type shiftStore interface {
+	GetOnShiftAgentIDs(at time.Time) (map[int]struct{}, error)
+}
+
 // Engine represents a manager for assigning unassigned conversations
 // to team agents in a round-robin pattern.
 type Engine struct {
@@ -48,6 +52,7 @@ type Engine struct {
 	systemUser        umodels.User
 	conversationStore conversationStore
 	teamStore         teamStore
+	shiftStore        shiftStore
 	lo                *logf.Logger
 	closed            bool
 	closedMu          sync.Mutex
@@ -55,11 +60,12 @@ type Engine struct {
 }
 
 // New initializes a new Engine instance, set up with the provided team manager,
-// conversation manager, and logger.
-func New(teamStore teamStore, conversationStore conversationStore, systemUser umodels.User, lo *logf.Logger) (*Engine, error) {
+// conversation manager, agent shift store and logger.
+func New(teamStore teamStore, conversationStore conversationStore, shiftStore shiftStore, systemUser umodels.User, lo *logf.Logger) (*Engine, error) {
 	var e = Engine{
 		conversationStore:      conversationStore,
 		teamStore:              teamStore,
+		shiftStore:             shiftStore,
 		systemUser:             systemUser,
 		lo:                     lo,
 		teamMaxAutoAssignments: make(map[int]int),
@@ -132,6 +138,14 @@ func (e *Engine) populateTeamBalancer() error {
 		return err
 	}
 
+	// Agents outside their working hours are left out of the pool. If shifts can't be
+	// fetched, nobody is left out.
+	onShift, err := e.shiftStore.GetOnShiftAgentIDs(time.Now())
+	if err != nil {
+		e.lo.Error("error fetching on shift agents", "error", err)
+		onShift = nil
+	}
+
 	for _, team := range teams {
 		if team.ConversationAssignmentType != AssignmentTypeRoundRobin {
 			continue
@@ -163,6 +177,14 @@ func (e *Engine) populateTeamBalancer() error {
 				continue
 			}
 
+			// Skip user if outside their working hours.
+			if onShift != nil {
+				if _, ok := onShift[user.ID]; !ok {
+					e.lo.Debug("user is off shift, skipping autoassignment", "team_id", team.ID, "user_id", user.ID)
+					continue
+				}
+			}
+
 			// Add user to the balancer pool
 			uid := strconv.Itoa(user.ID)
 			existingUsers[uid] = struct{}{}
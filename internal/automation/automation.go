@@ -15,11 +15,13 @@ import (
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/tracing"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
 	"github.com/lib/pq"
 	"github.com/zerodha/logf"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -27,6 +29,16 @@ var (
 	efs embed.FS
 	// MaxQueueSize is the maximum size of the task queue.
 	MaxQueueSize = 10000
+	// timeTriggerLookback is how far back conversations with no activity are evaluated for time triggers, resolved conversations older than this are skipped.
+	timeTriggerLookback = 30 * 24 * time.Hour
+	// ruleLogRetention is how long rule executions are kept in the rule log.
+	ruleLogRetention = 30 * 24 * time.Hour
+	// maxRuleLogs is the maximum number of rule log entries returned for a rule.
+	maxRuleLogs = 100
+	// delayedActionsInterval is how often due delayed actions are run.
+	delayedActionsInterval = time.Minute
+	// delayedActionsBatchSize is the maximum number of delayed actions run at a time.
+	delayedActionsBatchSize = 100
 )
 
 // TaskType represents the type of conversation task.
@@ -36,6 +48,7 @@ const (
 	NewConversation    TaskType = "new"
 	UpdateConversation TaskType = "update"
 	TimeTrigger        TaskType = "time-trigger"
+	DelayedActions     TaskType = "delayed-actions"
 )
 
 // ConversationTask represents a unit of work for processing conversations.
@@ -43,6 +56,8 @@ type ConversationTask struct {
 	taskType     TaskType
 	eventType    string
 	conversation cmodels.Conversation
+	// ctx carries the trace context of the code that queued the task.
+	ctx context.Context
 }
 
 type Engine struct {
@@ -56,18 +71,22 @@ type Engine struct {
 	closed            bool
 	closedMu          sync.RWMutex
 	wg                sync.WaitGroup
+	timeTriggerEvery  time.Duration
 }
 
 type Opts struct {
 	DB   *sqlx.DB
 	Lo   *logf.Logger
 	I18n *i18n.I18n
+	// TimeTriggerInterval is how often time trigger rules are evaluated.
+	TimeTriggerInterval time.Duration
 }
 
 type conversationStore interface {
 	ApplyAction(action models.RuleAction, conversation cmodels.Conversation, user umodels.User) error
+	ValidateAction(action models.RuleAction) error
 	GetConversation(teamID int, uuid, refNum string) (cmodels.Conversation, error)
-	GetConversationsCreatedAfter(time.Time) ([]cmodels.Conversation, error)
+	GetTimeTriggerConversations(time.Time) ([]cmodels.Conversation, error)
 }
 
 type queries struct {
@@ -80,6 +99,13 @@ type queries struct {
 	GetEnabledRules         *sqlx.Stmt `query:"get-enabled-rules"`
 	UpdateRuleWeight        *sqlx.Stmt `query:"update-rule-weight"`
 	UpdateRuleExecutionMode *sqlx.Stmt `query:"update-rule-execution-mode"`
+	GetExecutedRules        *sqlx.Stmt `query:"get-executed-rules"`
+	UpsertRuleExecution     *sqlx.Stmt `query:"upsert-rule-execution"`
+	InsertRuleLog           *sqlx.Stmt `query:"insert-rule-log"`
+	InsertPendingAction     *sqlx.Stmt `query:"insert-pending-action"`
+	ClaimDuePendingActions  *sqlx.Stmt `query:"claim-due-pending-actions"`
+	GetRuleLogs             *sqlx.Stmt `query:"get-rule-logs"`
+	DeleteOldRuleLogs       *sqlx.Stmt `query:"delete-old-rule-logs"`
 }
 
 // New initializes a new Engine.
@@ -87,14 +113,18 @@ func New(opt Opts) (*Engine, error) {
 	var (
 		q queries
 		e = &Engine{
-			lo:        opt.Lo,
-			i18n:      opt.I18n,
-			taskQueue: make(chan ConversationTask, MaxQueueSize),
+			lo:               opt.Lo,
+			i18n:             opt.I18n,
+			taskQueue:        make(chan ConversationTask, MaxQueueSize),
+			timeTriggerEvery: opt.TimeTriggerInterval,
 		}
 	)
 	if err := dbutil.ScanSQLFile("queries.sql", &q, opt.DB, efs); err != nil {
 		return nil, err
 	}
+	if e.timeTriggerEvery <= 0 {
+		e.timeTriggerEvery = time.Hour
+	}
 	e.q = q
 	e.rules = e.queryRules()
 	return e, nil
@@ -121,10 +151,12 @@ func (e *Engine) Run(ctx context.Context, workerCount int) {
 		go e.worker(ctx)
 	}
 
-	// Hourly ticker for timed triggers.
-	ticker := time.NewTicker(1 * time.Hour)
+	// Tickers for timed triggers and delayed actions.
+	ticker := time.NewTicker(e.timeTriggerEvery)
+	delayedTicker := time.NewTicker(delayedActionsInterval)
 	defer func() {
 		ticker.Stop()
+		delayedTicker.Stop()
 	}()
 
 	for {
@@ -134,6 +166,8 @@ func (e *Engine) Run(ctx context.Context, workerCount int) {
 		case <-ticker.C:
 			e.lo.Info("queuing time triggers")
 			e.taskQueue <- ConversationTask{taskType: TimeTrigger}
+		case <-delayedTicker.C:
+			e.taskQueue <- ConversationTask{taskType: DelayedActions}
 		}
 	}
 }
@@ -149,18 +183,36 @@ func (e *Engine) worker(ctx context.Context) {
 			if !ok {
 				return
 			}
-			switch task.taskType {
-			case NewConversation:
-				e.handleNewConversation(task.conversation)
-			case UpdateConversation:
-				e.handleUpdateConversation(task.conversation, task.eventType)
-			case TimeTrigger:
-				e.handleTimeTrigger()
-			}
+			e.handleTask(task)
 		}
 	}
 }
 
+// handleTask evaluates the rules for a single task inside a trace span.
+func (e *Engine) handleTask(task ConversationTask) {
+	ctx := task.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracing.Start(ctx, "automation.evaluate",
+		attribute.String("automation.task_type", string(task.taskType)),
+		attribute.String("automation.event_type", task.eventType),
+		attribute.String("conversation.uuid", task.conversation.UUID),
+	)
+	defer span.End()
+
+	switch task.taskType {
+	case NewConversation:
+		e.handleNewConversation(task.conversation)
+	case UpdateConversation:
+		e.handleUpdateConversation(task.conversation, task.eventType)
+	case TimeTrigger:
+		e.handleTimeTrigger()
+	case DelayedActions:
+		e.handleDelayedActions()
+	}
+}
+
 // Close signals the Engine to stop accepting any more messages and waits for all workers to finish.
 func (e *Engine) Close() {
 	e.closedMu.Lock()
@@ -214,8 +266,14 @@ func (e *Engine) UpdateRule(id int, rule models.RuleRecord) (models.RuleRecord,
 	if rule.Events == nil {
 		rule.Events = pq.StringArray{}
 	}
+	if err := e.validateActiveWindow(rule); err != nil {
+		return models.RuleRecord{}, err
+	}
+	if err := e.validateActions(rule); err != nil {
+		return models.RuleRecord{}, err
+	}
 	var result models.RuleRecord
-	if err := e.q.UpdateRule.Get(&result, id, rule.Name, rule.Description, rule.Type, rule.Events, rule.Rules, rule.Enabled); err != nil {
+	if err := e.q.UpdateRule.Get(&result, id, rule.Name, rule.Description, rule.Type, rule.Events, rule.Rules, rule.Enabled, rule.StopProcessing, rule.ActiveFrom, rule.ActiveUntil); err != nil {
 		e.lo.Error("error updating rule", "error", err)
 		return models.RuleRecord{}, envelope.NewError(envelope.GeneralError, e.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -229,8 +287,14 @@ func (e *Engine) CreateRule(rule models.RuleRecord) (models.RuleRecord, error) {
 	if rule.Events == nil {
 		rule.Events = pq.StringArray{}
 	}
+	if err := e.validateActiveWindow(rule); err != nil {
+		return models.RuleRecord{}, err
+	}
+	if err := e.validateActions(rule); err != nil {
+		return models.RuleRecord{}, err
+	}
 	var result models.RuleRecord
-	if err := e.q.InsertRule.Get(&result, rule.Name, rule.Description, rule.Type, rule.Events, rule.Rules); err != nil {
+	if err := e.q.InsertRule.Get(&result, rule.Name, rule.Description, rule.Type, rule.Events, rule.Rules, rule.StopProcessing, rule.ActiveFrom, rule.ActiveUntil); err != nil {
 		e.lo.Error("error creating rule", "error", err)
 		return models.RuleRecord{}, envelope.NewError(envelope.GeneralError, e.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -239,6 +303,33 @@ func (e *Engine) CreateRule(rule models.RuleRecord) (models.RuleRecord, error) {
 	return result, nil
 }
 
+// validateActiveWindow checks the end of a rule's active window is after its start.
+func (e *Engine) validateActiveWindow(rule models.RuleRecord) error {
+	if rule.ActiveFrom.Valid && rule.ActiveUntil.Valid && !rule.ActiveUntil.Time.After(rule.ActiveFrom.Time) {
+		return envelope.NewError(envelope.InputError, e.i18n.T("admin.automation.validation.activeWindow"), nil)
+	}
+	return nil
+}
+
+// validateActions checks the values of a rule's actions, e.g. the URL and body template of call webhook actions.
+func (e *Engine) validateActions(rule models.RuleRecord) error {
+	if len(rule.Rules) == 0 {
+		return nil
+	}
+	var batch []models.Rule
+	if err := json.Unmarshal(rule.Rules, &batch); err != nil {
+		return envelope.NewError(envelope.InputError, e.i18n.T("errors.parsingRequest"), nil)
+	}
+	for _, r := range batch {
+		for _, action := range r.Actions {
+			if err := e.conversationStore.ValidateAction(action); err != nil {
+				return envelope.NewError(envelope.InputError, e.i18n.Ts("admin.automation.validation.invalidAction", "name", action.Type, "error", err.Error()), nil)
+			}
+		}
+	}
+	return nil
+}
+
 // DeleteRule deletes a rule by ID.
 func (e *Engine) DeleteRule(id int) error {
 	if _, err := e.q.DeleteRule.Exec(id); err != nil {
@@ -276,6 +367,11 @@ func (e *Engine) UpdateRuleExecutionMode(ruleType, mode string) error {
 
 // EvaluateNewConversationRules enqueues a new conversation for rule evaluation.
 func (e *Engine) EvaluateNewConversationRules(conversation cmodels.Conversation) {
+	e.EvaluateNewConversationRulesContext(context.Background(), conversation)
+}
+
+// EvaluateNewConversationRulesContext is like EvaluateNewConversationRules but the evaluation is traced as a child of the span in ctx.
+func (e *Engine) EvaluateNewConversationRulesContext(ctx context.Context, conversation cmodels.Conversation) {
 	e.closedMu.RLock()
 	defer e.closedMu.RUnlock()
 	if e.closed {
@@ -285,6 +381,7 @@ func (e *Engine) EvaluateNewConversationRules(conversation cmodels.Conversation)
 	case e.taskQueue <- ConversationTask{
 		taskType:     NewConversation,
 		conversation: conversation,
+		ctx:          ctx,
 	}:
 	default:
 		// Queue is full.
@@ -294,6 +391,11 @@ func (e *Engine) EvaluateNewConversationRules(conversation cmodels.Conversation)
 
 // EvaluateConversationUpdateRules enqueues a conversation for rule evaluation, this function exists along with EvaluateConversationUpdateRulesByID to reduce DB queries for fetching conversations.
 func (e *Engine) EvaluateConversationUpdateRules(conversation cmodels.Conversation, eventType string) {
+	e.EvaluateConversationUpdateRulesContext(context.Background(), conversation, eventType)
+}
+
+// EvaluateConversationUpdateRulesContext is like EvaluateConversationUpdateRules but the evaluation is traced as a child of the span in ctx.
+func (e *Engine) EvaluateConversationUpdateRulesContext(ctx context.Context, conversation cmodels.Conversation, eventType string) {
 	if eventType == "" {
 		e.lo.Error("error evaluating conversation update rules: eventType is empty")
 		return
@@ -308,6 +410,7 @@ func (e *Engine) EvaluateConversationUpdateRules(conversation cmodels.Conversati
 		taskType:     UpdateConversation,
 		eventType:    eventType,
 		conversation: conversation,
+		ctx:          ctx,
 	}:
 	default:
 		// Queue is full.
@@ -334,7 +437,7 @@ func (e *Engine) handleNewConversation(conversation cmodels.Conversation) {
 		e.lo.Info("no rules to evaluate for new conversation rule evaluation", "uuid", conversation.UUID)
 		return
 	}
-	e.evalConversationRules(rules, conversation)
+	e.logExecutions(conversation, e.evalConversationRules(rules, conversation))
 }
 
 // handleUpdateConversation handles update conversation events with specific eventType.
@@ -345,14 +448,20 @@ func (e *Engine) handleUpdateConversation(conversation cmodels.Conversation, eve
 		e.lo.Info("no rules to evaluate for conversation update", "uuid", conversation.UUID, "event_type", eventType)
 		return
 	}
-	e.evalConversationRules(rules, conversation)
+	e.logExecutions(conversation, e.evalConversationRules(rules, conversation))
 }
 
 // handleTimeTrigger handles time trigger events.
+// A rule executes at most once on a conversation until the conversation gets a new message or its status changes,
+// so that e.g. a follow-up isn't sent on every run.
 func (e *Engine) handleTimeTrigger() {
 	e.lo.Info("running time trigger evaluation for automation rules")
-	thirtyDaysAgo := time.Now().Add(-30 * 24 * time.Hour)
-	conversations, err := e.conversationStore.GetConversationsCreatedAfter(thirtyDaysAgo)
+	if res, err := e.q.DeleteOldRuleLogs.Exec(ruleLogRetention.Seconds()); err != nil {
+		e.lo.Error("error deleting old automation rule logs", "error", err)
+	} else if n, _ := res.RowsAffected(); n > 0 {
+		e.lo.Info("deleted old automation rule logs", "count", n)
+	}
+	conversations, err := e.conversationStore.GetTimeTriggerConversations(time.Now().Add(-timeTriggerLookback))
 	if err != nil {
 		e.lo.Error("error fetching conversations for time trigger", "error", err)
 		return
@@ -370,8 +479,139 @@ func (e *Engine) handleTimeTrigger() {
 			e.lo.Error("error fetching conversation for time trigger", "uuid", c.UUID, "error", err)
 			continue
 		}
-		e.evalConversationRules(rules, conversation)
+		var executed []int
+		if err := e.q.GetExecutedRules.Select(&executed, conversation.ID); err != nil {
+			e.lo.Error("error fetching executed time trigger rules", "uuid", c.UUID, "error", err)
+			continue
+		}
+		pending := slices.DeleteFunc(slices.Clone(rules), func(r models.Rule) bool {
+			return slices.Contains(executed, r.ID)
+		})
+		executions := e.evalConversationRules(pending, conversation)
+		for _, rule := range executions {
+			if _, err := e.q.UpsertRuleExecution.Exec(rule.ID, conversation.ID); err != nil {
+				e.lo.Error("error recording time trigger rule execution", "rule_id", rule.ID, "uuid", c.UUID, "error", err)
+			}
+		}
+		e.logExecutions(conversation, executions)
+	}
+}
+
+// logExecutions schedules the delayed actions of rules executed on a conversation and records the
+// executions in the rule log.
+func (e *Engine) logExecutions(conversation cmodels.Conversation, executions []ruleExecution) {
+	for _, ex := range executions {
+		for _, action := range ex.Delayed {
+			b, err := json.Marshal(action)
+			if err != nil {
+				e.lo.Error("error marshalling delayed automation action", "rule_id", ex.ID, "error", err)
+				continue
+			}
+			if _, err := e.q.InsertPendingAction.Exec(ex.ID, conversation.ID, b, min(action.DelayMinutes, maxActionDelayMinutes)); err != nil {
+				e.lo.Error("error scheduling delayed automation action", "rule_id", ex.ID, "conversation_uuid", conversation.UUID, "error", err)
+			}
+		}
+		e.logResults(ex.ID, conversation, ex.Results)
+	}
+}
+
+// logResults records the outcome of a rule's actions on a conversation in the rule log.
+func (e *Engine) logResults(ruleID int, conversation cmodels.Conversation, results []models.ActionResult) {
+	failed := slices.ContainsFunc(results, func(r models.ActionResult) bool { return r.Error != "" })
+	actions, err := json.Marshal(results)
+	if err != nil {
+		e.lo.Error("error marshalling automation action results", "rule_id", ruleID, "error", err)
+		return
+	}
+	if _, err := e.q.InsertRuleLog.Exec(ruleID, conversation.ID, failed, actions); err != nil {
+		e.lo.Error("error inserting automation rule log", "rule_id", ruleID, "conversation_uuid", conversation.UUID, "error", err)
+	}
+}
+
+// handleDelayedActions runs the delayed actions that are due. An action is cancelled if its rule was
+// disabled or no longer matches the conversation, or if the contact replied and the action is cancelled on reply.
+func (e *Engine) handleDelayedActions() {
+	var pending []models.PendingAction
+	if err := e.q.ClaimDuePendingActions.Select(&pending, delayedActionsBatchSize); err != nil {
+		e.lo.Error("error fetching due delayed automation actions", "error", err)
+		return
+	}
+	for _, p := range pending {
+		var action models.RuleAction
+		if err := json.Unmarshal(p.Action, &action); err != nil {
+			e.lo.Error("error unmarshalling delayed automation action", "id", p.ID, "error", err)
+			continue
+		}
+		conversation, err := e.conversationStore.GetConversation(0, p.ConversationUUID, "")
+		if err != nil {
+			e.lo.Error("error fetching conversation for delayed automation action", "id", p.ID, "uuid", p.ConversationUUID, "error", err)
+			continue
+		}
+
+		res := models.ActionResult{Type: action.Type, Value: action.Value}
+		if reason := e.delayedActionCancelReason(p, action, conversation); reason != "" {
+			e.lo.Info("cancelled delayed automation action", "id", p.ID, "rule_id", p.RuleID, "uuid", p.ConversationUUID, "reason", reason)
+			res.Cancelled = reason
+		} else if err := e.conversationStore.ApplyAction(action, conversation, umodels.User{}); err != nil {
+			e.lo.Error("error applying delayed action on conversation", "action", action, "conversation_uuid", p.ConversationUUID, "error", err)
+			res.Error = err.Error()
+		}
+		e.logResults(p.RuleID, conversation, []models.ActionResult{res})
+	}
+}
+
+// delayedActionCancelReason returns why a due delayed action shouldn't run, or an empty string if it should.
+func (e *Engine) delayedActionCancelReason(p models.PendingAction, action models.RuleAction, conversation cmodels.Conversation) string {
+	if action.CancelOnReply && conversation.LastMessageSender.String == cmodels.SenderTypeContact &&
+		conversation.LastMessageAt.Time.After(p.CreatedAt) {
+		return "contact replied"
+	}
+	rule, ok := e.enabledRule(p.RuleID)
+	if !ok {
+		return "rule disabled"
+	}
+	if !ruleActive(rule, time.Now()) {
+		return "rule outside its active window"
+	}
+	if !e.ruleMatches(rule, conversation) {
+		return "rule no longer matches"
+	}
+	return ""
+}
+
+// enabledRule returns the enabled rule with the given rule record ID.
+func (e *Engine) enabledRule(id int) (models.Rule, bool) {
+	e.rulesMu.RLock()
+	defer e.rulesMu.RUnlock()
+	for _, rule := range e.rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return models.Rule{}, false
+}
+
+// GetRuleLogs returns the latest executions of a rule.
+func (e *Engine) GetRuleLogs(ruleID int) ([]models.RuleLog, error) {
+	var logs = make([]models.RuleLog, 0)
+	if err := e.q.GetRuleLogs.Select(&logs, ruleID, maxRuleLogs); err != nil {
+		e.lo.Error("error fetching automation rule logs", "rule_id", ruleID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, e.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return logs, nil
+}
+
+// DryRun evaluates the rules of a rule record against a conversation without applying any actions.
+func (e *Engine) DryRun(rules json.RawMessage, conversation cmodels.Conversation) ([]models.DryRunResult, error) {
+	var batch []models.Rule
+	if err := json.Unmarshal(rules, &batch); err != nil {
+		return nil, envelope.NewError(envelope.InputError, e.i18n.T("errors.parsingRequest"), nil)
+	}
+	results := make([]models.DryRunResult, 0, len(batch))
+	for _, rule := range batch {
+		results = append(results, e.explainRule(rule, conversation))
 	}
+	return results, nil
 }
 
 // queryRules fetches automation rules from the database.
@@ -394,9 +634,13 @@ func (e *Engine) queryRules() []models.Rule {
 		}
 		// Set values from DB.
 		for i := range rulesBatch {
+			rulesBatch[i].ID = rule.ID
 			rulesBatch[i].Type = rule.Type
 			rulesBatch[i].Events = rule.Events
 			rulesBatch[i].ExecutionMode = rule.ExecutionMode
+			rulesBatch[i].StopProcessing = rule.StopProcessing
+			rulesBatch[i].ActiveFrom = rule.ActiveFrom
+			rulesBatch[i].ActiveUntil = rule.ActiveUntil
 		}
 		filteredRules = append(filteredRules, rulesBatch...)
 	}
@@ -12,45 +12,139 @@ import (
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 )
 
+const (
+	// maxGroupDepth is the maximum nesting depth of rule groups, including the top level groups.
+	maxGroupDepth = 3
+	// maxActionDelayMinutes is the longest an action can be delayed, 30 days.
+	maxActionDelayMinutes = 30 * 24 * 60
+)
+
+// ruleExecution is a rule whose actions were executed on a conversation, with the outcome of each action.
+type ruleExecution struct {
+	models.Rule
+	Results []models.ActionResult
+	// Delayed are the actions to schedule instead of executing right away.
+	Delayed []models.RuleAction
+}
+
 // evalConversationRules evaluates a list of rules against a given conversation.
 // If all the groups of a rule pass their evaluations based on the defined logical operations,
-// the corresponding actions are executed.
-func (e *Engine) evalConversationRules(rules []models.Rule, conversation cmodels.Conversation) {
+// the corresponding actions are executed. The rules whose actions were executed are returned.
+func (e *Engine) evalConversationRules(rules []models.Rule, conversation cmodels.Conversation) []ruleExecution {
+	var (
+		matched []ruleExecution
+		now     = time.Now()
+	)
 	for _, rule := range rules {
 		e.lo.Debug("evaluating rules for conversation", "rule", rule, "conversation_id", conversation.ID)
 
-		// At max there can be only 2 groups.
-		if len(rule.Groups) > 2 {
-			e.lo.Warn("WARNING: more than 2 groups found for rules skipping evaluation")
+		if !ruleActive(rule, now) {
+			e.lo.Debug("rule is outside its active window, skipping evaluation", "rule_id", rule.ID, "conversation_uuid", conversation.UUID)
 			continue
 		}
 
-		var groupEvalResults []bool
-		for idx, group := range rule.Groups {
-			if len(group.Rules) == 0 {
-				e.lo.Debug("no rules found in group, skipping rule group evaluation", "group_num", idx+1, "conversation_uuid", conversation.UUID)
-				continue
-			}
-			result := e.evaluateGroup(group.Rules, group.LogicalOp, conversation)
-			e.lo.Debug("group rule evaluation complete", "logical_op", group.LogicalOp, "result", result, "conversation_uuid", conversation.UUID)
-			groupEvalResults = append(groupEvalResults, result)
+		if !e.ruleMatches(rule, conversation) {
+			continue
 		}
 
-		if evaluateFinalResult(groupEvalResults, rule.GroupOperator) {
-			e.lo.Debug("all rules within groups evaluated successfully, executing actions", "conversation_uuid", conversation.UUID)
-			for _, action := range rule.Actions {
-				if err := e.conversationStore.ApplyAction(action, conversation, umodels.User{}); err != nil {
-					e.lo.Error("error applying action on conversation", "action", action, "conversation_uuid", conversation.UUID, "error", err)
-				}
+		e.lo.Debug("all rules within groups evaluated successfully, executing actions", "conversation_uuid", conversation.UUID)
+		exec := ruleExecution{Rule: rule, Results: make([]models.ActionResult, 0, len(rule.Actions))}
+		for _, action := range rule.Actions {
+			res := models.ActionResult{Type: action.Type, Value: action.Value}
+			if action.DelayMinutes > 0 {
+				// Delayed actions are scheduled by the caller.
+				runAt := time.Now().Add(time.Duration(min(action.DelayMinutes, maxActionDelayMinutes)) * time.Minute)
+				res.ScheduledAt = &runAt
+				exec.Results = append(exec.Results, res)
+				exec.Delayed = append(exec.Delayed, action)
+				continue
 			}
-			if rule.ExecutionMode == models.ExecutionModeFirstMatch {
-				e.lo.Debug("automation is first match rule execution mode, breaking out of rule evaluation", "conversation_uuid", conversation.UUID)
-				break
+			if err := e.conversationStore.ApplyAction(action, conversation, umodels.User{}); err != nil {
+				e.lo.Error("error applying action on conversation", "action", action, "conversation_uuid", conversation.UUID, "error", err)
+				res.Error = err.Error()
 			}
-		} else {
-			e.lo.Debug("rule evaluation failed, skipping actions", "group_eval_results", groupEvalResults, "conversation_uuid", conversation.UUID)
+			exec.Results = append(exec.Results, res)
+		}
+		matched = append(matched, exec)
+		if rule.ExecutionMode == models.ExecutionModeFirstMatch {
+			e.lo.Debug("automation is first match rule execution mode, breaking out of rule evaluation", "conversation_uuid", conversation.UUID)
+			break
+		}
+		if rule.StopProcessing {
+			e.lo.Debug("rule stops processing, breaking out of rule evaluation", "rule_id", rule.ID, "conversation_uuid", conversation.UUID)
+			break
+		}
+	}
+	return matched
+}
+
+// ruleActive reports whether the time is within the rule's active window.
+func ruleActive(rule models.Rule, now time.Time) bool {
+	if rule.ActiveFrom.Valid && now.Before(rule.ActiveFrom.Time) {
+		return false
+	}
+	if rule.ActiveUntil.Valid && !now.Before(rule.ActiveUntil.Time) {
+		return false
+	}
+	return true
+}
+
+// ruleMatches reports whether the groups of a rule pass their evaluations against a conversation.
+func (e *Engine) ruleMatches(rule models.Rule, conversation cmodels.Conversation) bool {
+	// At max there can be only 2 groups.
+	if len(rule.Groups) > 2 {
+		e.lo.Warn("WARNING: more than 2 groups found for rules skipping evaluation")
+		return false
+	}
+
+	var groupEvalResults []bool
+	for idx, group := range rule.Groups {
+		if isEmptyGroup(group) {
+			e.lo.Debug("no rules found in group, skipping rule group evaluation", "group_num", idx+1, "conversation_uuid", conversation.UUID)
+			continue
+		}
+		result := e.evaluateRuleGroup(group, conversation, 1)
+		e.lo.Debug("group rule evaluation complete", "logical_op", group.LogicalOp, "negate", group.Negate, "result", result, "conversation_uuid", conversation.UUID)
+		groupEvalResults = append(groupEvalResults, result)
+	}
+
+	if !evaluateFinalResult(groupEvalResults, rule.GroupOperator) {
+		e.lo.Debug("rule evaluation failed, skipping actions", "group_eval_results", groupEvalResults, "conversation_uuid", conversation.UUID)
+		return false
+	}
+	return true
+}
+
+// explainRule evaluates a rule against a conversation without applying its actions, returning the
+// result of every group and condition.
+func (e *Engine) explainRule(rule models.Rule, conversation cmodels.Conversation) models.DryRunResult {
+	res := models.DryRunResult{
+		Matched: e.ruleMatches(rule, conversation),
+		Groups:  make([]models.GroupResult, 0, len(rule.Groups)),
+		Actions: rule.Actions,
+	}
+	for _, group := range rule.Groups {
+		res.Groups = append(res.Groups, e.explainGroup(group, conversation, 1))
+	}
+	return res
+}
+
+// explainGroup evaluates a group and each of its conditions and nested groups against a conversation.
+func (e *Engine) explainGroup(group models.RuleGroup, conversation cmodels.Conversation, depth int) models.GroupResult {
+	res := models.GroupResult{
+		Result:     !isEmptyGroup(group) && e.evaluateRuleGroup(group, conversation, depth),
+		Empty:      isEmptyGroup(group),
+		Conditions: make([]models.ConditionResult, 0, len(group.Rules)),
+	}
+	for _, rule := range group.Rules {
+		res.Conditions = append(res.Conditions, models.ConditionResult{RuleDetail: rule, Result: e.evaluateRule(rule, conversation)})
+	}
+	if depth < maxGroupDepth {
+		for _, sub := range group.Groups {
+			res.Groups = append(res.Groups, e.explainGroup(sub, conversation, depth+1))
 		}
 	}
+	return res
 }
 
 // evaluateFinalResult computes the final result of multiple group evaluations
@@ -75,6 +169,63 @@ func evaluateFinalResult(results []bool, operator string) bool {
 	return false
 }
 
+// evaluateRuleGroup evaluates the rules and nested groups of a group against a given conversation
+// using the group's logical operator (AND/OR), inverting the result if the group is negated.
+// Groups nested deeper than maxGroupDepth fail the evaluation.
+func (e *Engine) evaluateRuleGroup(group models.RuleGroup, conversation cmodels.Conversation, depth int) bool {
+	if depth > maxGroupDepth {
+		e.lo.Warn("WARNING: rule groups nested too deep, skipping evaluation", "max_depth", maxGroupDepth, "conversation_uuid", conversation.UUID)
+		return false
+	}
+
+	var result bool
+	switch group.LogicalOp {
+	case models.OperatorAnd:
+		result = e.evaluateGroup(group.Rules, group.LogicalOp, conversation)
+		for _, sub := range group.Groups {
+			if !result {
+				break
+			}
+			if isEmptyGroup(sub) {
+				continue
+			}
+			result = e.evaluateRuleGroup(sub, conversation, depth+1)
+		}
+	case models.OperatorOR:
+		result = e.evaluateGroup(group.Rules, group.LogicalOp, conversation)
+		for _, sub := range group.Groups {
+			if result {
+				break
+			}
+			if isEmptyGroup(sub) {
+				continue
+			}
+			result = e.evaluateRuleGroup(sub, conversation, depth+1)
+		}
+	default:
+		e.lo.Error("invalid group operator", "operator", group.LogicalOp)
+		return false
+	}
+
+	if group.Negate {
+		return !result
+	}
+	return result
+}
+
+// isEmptyGroup returns true if a group and its nested groups have no rules.
+func isEmptyGroup(group models.RuleGroup) bool {
+	if len(group.Rules) > 0 {
+		return false
+	}
+	for _, sub := range group.Groups {
+		if !isEmptyGroup(sub) {
+			return false
+		}
+	}
+	return true
+}
+
 // evaluateGroup evaluates a set of rules within a group against a given conversation
 // based on the specified logical operator (AND/OR).
 func (e *Engine) evaluateGroup(rules []models.RuleDetail, operator string, conversation cmodels.Conversation) bool {
@@ -106,6 +257,7 @@ func (e *Engine) evaluateGroup(rules []models.RuleDetail, operator string, conve
 func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conversation) bool {
 	var (
 		valueToCompare   string
+		numberToCompare  *float64
 		ruleValues       []string
 		conditionMet     bool
 		customAttributes map[string]any
@@ -154,15 +306,50 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 			if !conversation.ResolvedAt.IsZero() {
 				valueToCompare = fmt.Sprintf("%.0f", (time.Since(conversation.ResolvedAt.Time).Hours()))
 			}
+		case models.ConversationHoursSinceLastMsg:
+			if !conversation.LastMessageAt.IsZero() {
+				valueToCompare = fmt.Sprintf("%.0f", (time.Since(conversation.LastMessageAt.Time).Hours()))
+			}
+		case models.ConversationHoursInStatus:
+			if !conversation.StatusChangedAt.IsZero() {
+				valueToCompare = fmt.Sprintf("%.0f", (time.Since(conversation.StatusChangedAt.Time).Hours()))
+			}
+		case models.ConversationLastMessageSender:
+			valueToCompare = conversation.LastMessageSender.String
 		case models.ConversationInbox:
 			valueToCompare = strconv.Itoa(conversation.InboxID)
 		default:
 			e.lo.Error("error unrecognized conversation field", "field", rule.Field, "field_type", rule.FieldType, "conversation_uuid", conversation.UUID)
 			return false
 		}
-	} else if rule.FieldType == models.FieldTypeContactCustomAttribute {
+	} else if rule.FieldType == models.FieldTypeContactField {
+		switch rule.Field {
+		case models.ContactFirstName:
+			valueToCompare = conversation.Contact.FirstName
+		case models.ContactLastName:
+			valueToCompare = conversation.Contact.LastName
+		case models.ContactFieldEmail:
+			valueToCompare = conversation.Contact.Email.String
+		case models.ContactPhoneNumber:
+			valueToCompare = conversation.Contact.PhoneNumber.String
+		case models.ContactCountry:
+			valueToCompare = conversation.Contact.Country.String
+		case models.ContactExternalUserID:
+			valueToCompare = conversation.Contact.ExternalUserID.String
+		case models.ContactType:
+			valueToCompare = conversation.Contact.Type
+		case models.ContactRiskFlags:
+			valueToCompare = strings.Join(conversation.Contact.RiskFlags, ", ")
+		default:
+			e.lo.Error("error unrecognized contact field", "field", rule.Field, "field_type", rule.FieldType, "conversation_uuid", conversation.UUID)
+			return false
+		}
+	} else if rule.FieldType == models.FieldTypeContactCustomAttribute || rule.FieldType == models.FieldTypeConversationCustomAttribute {
 		// If the field type is custom attribute, need to extract the value from the custom attributes
 		var attributes json.RawMessage = conversation.Contact.CustomAttributes
+		if rule.FieldType == models.FieldTypeConversationCustomAttribute {
+			attributes = conversation.CustomAttributes
+		}
 
 		// Unmarshal the custom attributes
 		if err := json.Unmarshal(attributes, &customAttributes); err != nil {
@@ -182,11 +369,15 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 			// Float type does not exist in the custom attributes.
 			case float64:
 				valueToCompare = strconv.FormatInt(int64(v), 10)
+				// Keep the fraction for greater and less than comparisons, e.g. order totals.
+				numberToCompare = &v
 			case bool:
 				valueToCompare = strconv.FormatBool(v)
 			default:
 				valueToCompare = fmt.Sprintf("%v", v)
 			}
+		} else if rule.Operator == models.RuleOperatorNotSet {
+			return true
 		} else {
 			e.lo.Warn("field not found in custom attribute", "field", rule.Field, "field_type", rule.FieldType, "conversation_uuid", conversation.UUID, "custom_attributes", customAttributes)
 			return false
@@ -280,12 +471,18 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 	case models.RuleOperatorNotSet:
 		conditionMet = len(valueToCompare) == 0
 	case models.RuleOperatorGreaterThan:
-		value1, _ := strconv.Atoi(valueToCompare)
-		value2, _ := strconv.Atoi(rule.Value)
+		value1, _ := strconv.ParseFloat(valueToCompare, 64)
+		if numberToCompare != nil {
+			value1 = *numberToCompare
+		}
+		value2, _ := strconv.ParseFloat(rule.Value, 64)
 		conditionMet = value1 > value2
 	case models.RuleOperatorLessThan:
-		value1, _ := strconv.Atoi(valueToCompare)
-		value2, _ := strconv.Atoi(rule.Value)
+		value1, _ := strconv.ParseFloat(valueToCompare, 64)
+		if numberToCompare != nil {
+			value1 = *numberToCompare
+		}
+		value2, _ := strconv.ParseFloat(rule.Value, 64)
 		conditionMet = value1 < value2
 	default:
 		e.lo.Error("error unrecognized rule logical operator", "operator", rule.Operator)
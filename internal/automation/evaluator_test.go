@@ -33,7 +33,11 @@ func (m *mockConversationStore) GetConversation(teamID int, uuid, refNum string)
 	return args.Get(0).(cmodels.Conversation), args.Error(1)
 }
 
-func (m *mockConversationStore) GetConversationsCreatedAfter(t time.Time) ([]cmodels.Conversation, error) {
+func (m *mockConversationStore) ValidateAction(action models.RuleAction) error {
+	return nil
+}
+
+func (m *mockConversationStore) GetTimeTriggerConversations(t time.Time) ([]cmodels.Conversation, error) {
 	args := m.Called(t)
 	return args.Get(0).([]cmodels.Conversation), args.Error(1)
 }
@@ -971,6 +975,42 @@ func TestCustomAttributeTypes(t *testing.T) {
 	})
 }
 
+// Test: Conversation custom attributes and contact fields
+func TestConversationAttributesAndContactFields(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	engine := createTestEngine(mockStore)
+
+	conversation := createTestConversation(func(c *cmodels.Conversation) {
+		c.CustomAttributes = json.RawMessage(`{"order_total": 149.95, "channel": "web"}`)
+		c.Contact.FirstName = "Jane"
+		c.Contact.Country = null.StringFrom("AU")
+		c.Contact.RiskFlags = []string{"Chargeback risk", "Abusive"}
+	})
+
+	tests := []struct {
+		name     string
+		rule     models.RuleDetail
+		expected bool
+	}{
+		{"decimal greater than", models.RuleDetail{Field: "order_total", Operator: models.RuleOperatorGreaterThan, Value: "149.9", FieldType: models.FieldTypeConversationCustomAttribute}, true},
+		{"decimal less than", models.RuleDetail{Field: "order_total", Operator: models.RuleOperatorLessThan, Value: "149.9", FieldType: models.FieldTypeConversationCustomAttribute}, false},
+		{"text equals", models.RuleDetail{Field: "channel", Operator: models.RuleOperatorEquals, Value: "web", FieldType: models.FieldTypeConversationCustomAttribute}, true},
+		{"missing attribute not set", models.RuleDetail{Field: "coupon", Operator: models.RuleOperatorNotSet, FieldType: models.FieldTypeConversationCustomAttribute}, true},
+		{"missing attribute equals", models.RuleDetail{Field: "coupon", Operator: models.RuleOperatorEquals, Value: "x", FieldType: models.FieldTypeConversationCustomAttribute}, false},
+		{"contact first name", models.RuleDetail{Field: models.ContactFirstName, Operator: models.RuleOperatorEquals, Value: "jane", FieldType: models.FieldTypeContactField}, true},
+		{"contact country", models.RuleDetail{Field: models.ContactCountry, Operator: models.RuleOperatorNotEqual, Value: "AU", FieldType: models.FieldTypeContactField}, false},
+		{"contact risk flags contain", models.RuleDetail{Field: models.ContactRiskFlags, Operator: models.RuleOperatorContains, Value: "chargeback risk", FieldType: models.FieldTypeContactField}, true},
+		{"contact risk flags set", models.RuleDetail{Field: models.ContactRiskFlags, Operator: models.RuleOperatorSet, FieldType: models.FieldTypeContactField}, true},
+		{"unknown contact field", models.RuleDetail{Field: "shoe_size", Operator: models.RuleOperatorSet, FieldType: models.FieldTypeContactField}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, engine.evaluateRule(tt.rule, conversation))
+		})
+	}
+}
+
 // Test: Hours since fields with null values
 func TestHoursSinceFields_NullHandling(t *testing.T) {
 	mockStore := new(mockConversationStore)
@@ -1003,6 +1043,137 @@ func TestHoursSinceFields_NullHandling(t *testing.T) {
 	assert.Equal(t, 0, mockStore.callCount, "Should not trigger action when time field is null")
 }
 
+// Test: No customer reply for 72 hours after an agent response
+func TestNoCustomerReplyAfterAgentResponse(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	engine := createTestEngine(mockStore)
+
+	rules := []models.Rule{
+		{
+			ID: 7,
+			Groups: []models.RuleGroup{
+				{
+					LogicalOp: models.OperatorAnd,
+					Rules: []models.RuleDetail{
+						{Field: models.ConversationLastMessageSender, Operator: models.RuleOperatorEquals, Value: "agent", FieldType: models.FieldTypeConversationField},
+						{Field: models.ConversationHoursSinceLastMsg, Operator: models.RuleOperatorGreaterThan, Value: "72", FieldType: models.FieldTypeConversationField},
+					},
+				},
+			},
+			Actions: []models.RuleAction{
+				{Type: models.ActionSetStatus, Value: []string{"4"}},
+			},
+			GroupOperator: models.OperatorAnd,
+			ExecutionMode: models.ExecutionModeAll,
+		},
+	}
+
+	waiting := createTestConversation(func(c *cmodels.Conversation) {
+		c.LastMessageSender = null.StringFrom("agent")
+		c.LastMessageAt = null.TimeFrom(time.Now().Add(-80 * time.Hour))
+	})
+	matched := engine.evalConversationRules(rules, waiting)
+	assert.Len(t, matched, 1)
+	assert.Equal(t, 7, matched[0].ID)
+
+	replied := createTestConversation(func(c *cmodels.Conversation) {
+		c.LastMessageSender = null.StringFrom("contact")
+		c.LastMessageAt = null.TimeFrom(time.Now().Add(-80 * time.Hour))
+	})
+	assert.Empty(t, engine.evalConversationRules(rules, replied))
+	assert.Equal(t, 1, mockStore.callCount)
+}
+
+// Test: Hours in status
+func TestHoursInStatus(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	engine := createTestEngine(mockStore)
+
+	rules := []models.Rule{
+		createTestRule([]models.RuleGroup{
+			{
+				LogicalOp: models.OperatorAnd,
+				Rules: []models.RuleDetail{
+					{Field: models.ConversationHoursInStatus, Operator: models.RuleOperatorGreaterThan, Value: "168", FieldType: models.FieldTypeConversationField},
+				},
+			},
+		}, []models.RuleAction{{Type: models.ActionSendPrivateNote, Value: []string{"Pending for a week"}}}, models.OperatorAnd),
+	}
+
+	fresh := createTestConversation(func(c *cmodels.Conversation) {
+		c.StatusChangedAt = null.TimeFrom(time.Now().Add(-24 * time.Hour))
+	})
+	assert.Empty(t, engine.evalConversationRules(rules, fresh))
+
+	stale := createTestConversation(func(c *cmodels.Conversation) {
+		c.StatusChangedAt = null.TimeFrom(time.Now().Add(-8 * 24 * time.Hour))
+	})
+	assert.Len(t, engine.evalConversationRules(rules, stale), 1)
+}
+
+// Test: status open AND NOT (tag refunds OR priority urgent)
+func TestNestedNegatedGroups(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	engine := createTestEngine(mockStore)
+
+	rules := []models.Rule{
+		createTestRule([]models.RuleGroup{
+			{
+				LogicalOp: models.OperatorAnd,
+				Rules: []models.RuleDetail{
+					{Field: models.ConversationStatus, Operator: models.RuleOperatorEquals, Value: "1", FieldType: models.FieldTypeConversationField},
+				},
+				Groups: []models.RuleGroup{
+					{
+						LogicalOp: models.OperatorOR,
+						Negate:    true,
+						Rules: []models.RuleDetail{
+							{Field: models.ConversationSubject, Operator: models.RuleOperatorContains, Value: "refund", FieldType: models.FieldTypeConversationField},
+							{Field: models.ConversationPriority, Operator: models.RuleOperatorEquals, Value: "4", FieldType: models.FieldTypeConversationField},
+						},
+					},
+				},
+			},
+		}, []models.RuleAction{{Type: models.ActionSetPriority, Value: []string{"2"}}}, models.OperatorAnd),
+	}
+
+	tests := []struct {
+		name    string
+		conv    cmodels.Conversation
+		matches bool
+	}{
+		{"open, neither", createTestConversation(func(c *cmodels.Conversation) { c.Subject = null.StringFrom("Hello") }), true},
+		{"open, refund", createTestConversation(func(c *cmodels.Conversation) { c.Subject = null.StringFrom("Refund please") }), false},
+		{"open, urgent", createTestConversation(func(c *cmodels.Conversation) { c.PriorityID = null.IntFrom(4) }), false},
+		{"closed, neither", createTestConversation(func(c *cmodels.Conversation) { c.StatusID = null.IntFrom(2) }), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, len(engine.evalConversationRules(rules, tt.conv)) == 1)
+		})
+	}
+}
+
+// Test: Groups nested deeper than the maximum depth don't match
+func TestNestedGroupsMaxDepth(t *testing.T) {
+	engine := createTestEngine(new(mockConversationStore))
+
+	group := models.RuleGroup{
+		LogicalOp: models.OperatorAnd,
+		Rules: []models.RuleDetail{
+			{Field: models.ConversationStatus, Operator: models.RuleOperatorEquals, Value: "1", FieldType: models.FieldTypeConversationField},
+		},
+	}
+	for i := 0; i < maxGroupDepth; i++ {
+		group = models.RuleGroup{LogicalOp: models.OperatorAnd, Groups: []models.RuleGroup{group}}
+	}
+	assert.False(t, engine.evaluateRuleGroup(group, createTestConversation(), 1))
+	assert.True(t, engine.evaluateRuleGroup(group.Groups[0], createTestConversation(), 1))
+}
+
 // Test: Multiple actions per rule
 func TestMultipleActions(t *testing.T) {
 	mockStore := new(mockConversationStore)
@@ -1198,4 +1369,144 @@ func TestComplexRealWorldScenario(t *testing.T) {
 	assert.Equal(t, 2, mockStore.callCount, "Complex conditions met, both actions should trigger")
 	assert.Equal(t, models.ActionSendCSAT, mockStore.appliedActions[0].Type)
 	assert.Equal(t, models.ActionSetTags, mockStore.appliedActions[1].Type)
-}
\ No newline at end of file
+}
+// Test: Dry run explains conditions without applying actions
+func TestExplainRule(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	engine := createTestEngine(mockStore)
+
+	conversation := createTestConversation(func(c *cmodels.Conversation) {
+		c.Subject = null.StringFrom("Refund request")
+	})
+	rule := models.Rule{
+		GroupOperator: models.OperatorOR,
+		Groups: []models.RuleGroup{
+			{
+				LogicalOp: models.OperatorAnd,
+				Rules: []models.RuleDetail{
+					{Field: models.ConversationSubject, Operator: models.RuleOperatorContains, Value: "refund", FieldType: models.FieldTypeConversationField},
+					{Field: models.ConversationSubject, Operator: models.RuleOperatorContains, Value: "invoice", FieldType: models.FieldTypeConversationField},
+				},
+				Groups: []models.RuleGroup{
+					{LogicalOp: models.OperatorOR, Rules: []models.RuleDetail{
+						{Field: models.ConversationSubject, Operator: models.RuleOperatorSet, FieldType: models.FieldTypeConversationField},
+					}},
+				},
+			},
+			{LogicalOp: models.OperatorAnd},
+		},
+		Actions: []models.RuleAction{{Type: models.ActionSetStatus, Value: []string{"2"}}},
+	}
+
+	res := engine.explainRule(rule, conversation)
+	assert.False(t, res.Matched)
+	assert.Len(t, res.Groups, 2)
+	assert.False(t, res.Groups[0].Result)
+	assert.True(t, res.Groups[0].Conditions[0].Result)
+	assert.False(t, res.Groups[0].Conditions[1].Result)
+	assert.True(t, res.Groups[0].Groups[0].Result)
+	assert.True(t, res.Groups[1].Empty)
+	assert.Equal(t, rule.Actions, res.Actions)
+	assert.Equal(t, 0, mockStore.callCount, "Dry run must not apply actions")
+}
+
+// Test: Delayed actions are scheduled instead of applied
+func TestDelayedActions(t *testing.T) {
+	mockStore := new(mockConversationStore)
+	mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	engine := createTestEngine(mockStore)
+
+	rules := []models.Rule{
+		{
+			Groups: []models.RuleGroup{
+				{
+					LogicalOp: models.OperatorOR,
+					Rules: []models.RuleDetail{
+						{Field: models.ConversationSubject, Operator: models.RuleOperatorSet, FieldType: models.FieldTypeConversationField},
+					},
+				},
+			},
+			Actions: []models.RuleAction{
+				{Type: models.ActionAddTags, Value: []string{"follow-up"}},
+				{Type: models.ActionReply, Value: []string{"Any update?"}, DelayMinutes: 24 * 60, CancelOnReply: true},
+			},
+			GroupOperator: models.OperatorOR,
+			ExecutionMode: models.ExecutionModeAll,
+		},
+	}
+	conversation := createTestConversation(func(c *cmodels.Conversation) {
+		c.Subject = null.StringFrom("Order")
+	})
+
+	executions := engine.evalConversationRules(rules, conversation)
+	assert.Len(t, executions, 1)
+	assert.Equal(t, 1, mockStore.callCount, "Only the immediate action should be applied")
+	assert.Equal(t, models.ActionAddTags, mockStore.appliedActions[0].Type)
+	assert.Len(t, executions[0].Delayed, 1)
+	assert.Equal(t, models.ActionReply, executions[0].Delayed[0].Type)
+	assert.Len(t, executions[0].Results, 2)
+	assert.Nil(t, executions[0].Results[0].ScheduledAt)
+	assert.NotNil(t, executions[0].Results[1].ScheduledAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *executions[0].Results[1].ScheduledAt, time.Minute)
+}
+
+// Test: Stop processing flag and active windows
+func TestStopProcessingAndActiveWindow(t *testing.T) {
+	conversation := createTestConversation(func(c *cmodels.Conversation) {
+		c.Subject = null.StringFrom("Order")
+	})
+	newRule := func(id int, opts ...func(*models.Rule)) models.Rule {
+		r := models.Rule{
+			ID: id,
+			Groups: []models.RuleGroup{
+				{
+					LogicalOp: models.OperatorOR,
+					Rules: []models.RuleDetail{
+						{Field: models.ConversationSubject, Operator: models.RuleOperatorSet, FieldType: models.FieldTypeConversationField},
+					},
+				},
+			},
+			Actions:       []models.RuleAction{{Type: models.ActionSetStatus, Value: []string{"2"}}},
+			GroupOperator: models.OperatorOR,
+			ExecutionMode: models.ExecutionModeAll,
+		}
+		for _, o := range opts {
+			o(&r)
+		}
+		return r
+	}
+	ids := func(executions []ruleExecution) []int {
+		var out []int
+		for _, e := range executions {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	t.Run("stop processing", func(t *testing.T) {
+		mockStore := new(mockConversationStore)
+		mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		engine := createTestEngine(mockStore)
+		rules := []models.Rule{
+			newRule(1),
+			newRule(2, func(r *models.Rule) { r.StopProcessing = true }),
+			newRule(3),
+		}
+		assert.Equal(t, []int{1, 2}, ids(engine.evalConversationRules(rules, conversation)))
+	})
+
+	t.Run("active window", func(t *testing.T) {
+		mockStore := new(mockConversationStore)
+		mockStore.On("ApplyAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		engine := createTestEngine(mockStore)
+		rules := []models.Rule{
+			newRule(1, func(r *models.Rule) { r.ActiveFrom = null.TimeFrom(time.Now().Add(time.Hour)) }),
+			newRule(2, func(r *models.Rule) { r.ActiveUntil = null.TimeFrom(time.Now().Add(-time.Hour)) }),
+			newRule(3, func(r *models.Rule) {
+				r.ActiveFrom = null.TimeFrom(time.Now().Add(-time.Hour))
+				r.ActiveUntil = null.TimeFrom(time.Now().Add(time.Hour))
+			}),
+		}
+		assert.Equal(t, []int{3}, ids(engine.evalConversationRules(rules, conversation)))
+	})
+}
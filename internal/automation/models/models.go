@@ -6,6 +6,7 @@ import (
 
 	authzModels "github.com/abhinavxd/libredesk/internal/authz/models"
 	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
 )
 
 const (
@@ -20,6 +21,9 @@ const (
 	ActionSetTags         = "set_tags"
 	ActionRemoveTags      = "remove_tags"
 	ActionSendCSAT        = "send_csat"
+	ActionSendTranscript  = "send_transcript"
+	ActionCallWebhook     = "call_webhook"
+	ActionSetAttribute    = "set_custom_attribute"
 
 	OperatorAnd = "AND"
 	OperatorOR  = "OR"
@@ -47,9 +51,21 @@ const (
 	ConversationHoursSinceFirstReply = "hours_since_first_reply"
 	ConversationHoursSinceLastReply  = "hours_since_last_reply"
 	ConversationHoursSinceResolved   = "hours_since_resolved"
+	ConversationHoursSinceLastMsg    = "hours_since_last_message"
+	ConversationHoursInStatus        = "hours_in_status"
+	ConversationLastMessageSender    = "last_message_sender"
 	ConversationInbox                = "inbox"
 	ContactEmail                     = "contact_email"
 
+	ContactFirstName      = "first_name"
+	ContactLastName       = "last_name"
+	ContactFieldEmail     = "email"
+	ContactPhoneNumber    = "phone_number"
+	ContactCountry        = "country"
+	ContactExternalUserID = "external_user_id"
+	ContactType           = "type"
+	ContactRiskFlags      = "risk_flags"
+
 	EventConversationUserAssigned    = "conversation.user.assigned"
 	EventConversationTeamAssigned    = "conversation.team.assigned"
 	EventConversationStatusChange    = "conversation.status.change"
@@ -62,19 +78,26 @@ const (
 
 	FieldTypeContactCustomAttribute      = "contact_custom_attribute"
 	FieldTypeConversationField           = "conversation"
+	FieldTypeConversationCustomAttribute = "conversation_custom_attribute"
+	FieldTypeContactField                = "contact"
 )
 
-// ActionPermissions maps actions to permissions
+// ActionPermissions maps actions to permissions, actions missing from it cannot be applied on behalf of an agent.
 var ActionPermissions = map[string]string{
 	ActionAssignTeam:      authzModels.PermConversationsUpdateTeamAssignee,
 	ActionAssignUser:      authzModels.PermConversationsUpdateUserAssignee,
 	ActionSetStatus:       authzModels.PermConversationsUpdateStatus,
 	ActionSetPriority:     authzModels.PermConversationsUpdatePriority,
-	ActionSendPrivateNote: authzModels.PermMessagesWrite,
+	ActionSendPrivateNote: authzModels.PermMessagesWritePrivate,
 	ActionReply:           authzModels.PermMessagesWrite,
+	ActionSendTranscript:  authzModels.PermMessagesWrite,
 	ActionAddTags:         authzModels.PermConversationsUpdateTags,
 	ActionSetTags:         authzModels.PermConversationsUpdateTags,
 	ActionRemoveTags:      authzModels.PermConversationsUpdateTags,
+	ActionCallWebhook:     authzModels.PermWebhooksManage,
+	ActionSetSLA:          authzModels.PermSLAManage,
+	ActionSendCSAT:        authzModels.PermMessagesWrite,
+	ActionSetAttribute:    authzModels.PermConversationsUpdateAttributes,
 }
 
 // RuleRecord represents a rule record in the database
@@ -90,20 +113,35 @@ type RuleRecord struct {
 	Weight        int             `db:"weight" json:"weight"`
 	ExecutionMode string          `db:"execution_mode" json:"execution_mode"`
 	Rules         json.RawMessage `db:"rules" json:"rules"`
+	// StopProcessing stops the evaluation of further rules once the rule matches.
+	StopProcessing bool `db:"stop_processing" json:"stop_processing"`
+	// ActiveFrom and ActiveUntil limit when the rule is evaluated, either can be unset.
+	ActiveFrom  null.Time `db:"active_from" json:"active_from"`
+	ActiveUntil null.Time `db:"active_until" json:"active_until"`
 }
 
 type Rule struct {
+	// ID is the ID of the rule record the rule belongs to.
+	ID            int          `json:"-"`
 	Type          string       `json:"type"`
 	ExecutionMode string       `json:"execution_mode"`
 	Events        []string     `json:"event"`
 	GroupOperator string       `json:"group_operator"`
 	Groups        []RuleGroup  `json:"groups"`
 	Actions       []RuleAction `json:"actions"`
+	// StopProcessing, ActiveFrom and ActiveUntil are set from the rule record.
+	StopProcessing bool      `json:"-"`
+	ActiveFrom     null.Time `json:"-"`
+	ActiveUntil    null.Time `json:"-"`
 }
 
 type RuleGroup struct {
 	LogicalOp string       `json:"logical_op" db:"logical_op"`
 	Rules     []RuleDetail `json:"rules" db:"rules"`
+	// Groups are nested groups, evaluated along with the rules using the group's logical operator.
+	Groups []RuleGroup `json:"groups,omitempty" db:"-"`
+	// Negate inverts the result of the group.
+	Negate bool `json:"negate,omitempty" db:"-"`
 }
 
 type RuleDetail struct {
@@ -114,8 +152,63 @@ type RuleDetail struct {
 	CaseSensitiveMatch bool   `json:"case_sensitive_match" db:"case_sensitive_match"`
 }
 
+// ActionResult is the outcome of an action executed by a rule. Error is empty if the action succeeded.
+// Delayed actions are logged when scheduled, with the time they run at, and again when they run or are cancelled.
+type ActionResult struct {
+	Type        string     `json:"type"`
+	Value       []string   `json:"value"`
+	Error       string     `json:"error,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	Cancelled   string     `json:"cancelled,omitempty"`
+}
+
+// RuleLog is a record of a rule executing its actions on a conversation.
+type RuleLog struct {
+	ID               int             `db:"id" json:"id"`
+	CreatedAt        time.Time       `db:"created_at" json:"created_at"`
+	RuleID           int             `db:"rule_id" json:"rule_id"`
+	ConversationUUID string          `db:"conversation_uuid" json:"conversation_uuid"`
+	ReferenceNumber  string          `db:"reference_number" json:"reference_number"`
+	Failed           bool            `db:"failed" json:"failed"`
+	Actions          json.RawMessage `db:"actions" json:"actions"`
+}
+
+// DryRunResult is the evaluation of a rule against a conversation, with the actions that would run if it matched.
+type DryRunResult struct {
+	Matched bool          `json:"matched"`
+	Groups  []GroupResult `json:"groups"`
+	Actions []RuleAction  `json:"actions"`
+}
+
+// GroupResult is the evaluation of a rule group. Empty groups are skipped when evaluating a rule.
+type GroupResult struct {
+	Result     bool              `json:"result"`
+	Empty      bool              `json:"empty"`
+	Conditions []ConditionResult `json:"conditions"`
+	Groups     []GroupResult     `json:"groups,omitempty"`
+}
+
+// ConditionResult is the evaluation of a single condition.
+type ConditionResult struct {
+	RuleDetail
+	Result bool `json:"result"`
+}
+
 type RuleAction struct {
 	Type         string   `json:"type" db:"type"`
 	Value        []string `json:"value" db:"value"`
 	DisplayValue []string `json:"display_value" db:"-"`
+	// DelayMinutes schedules the action to run after the delay instead of right away.
+	DelayMinutes int `json:"delay_minutes,omitempty" db:"-"`
+	// CancelOnReply cancels a delayed action if the contact replies before it runs.
+	CancelOnReply bool `json:"cancel_on_reply,omitempty" db:"-"`
+}
+
+// PendingAction is a delayed rule action waiting to run on a conversation.
+type PendingAction struct {
+	ID               int             `db:"id"`
+	CreatedAt        time.Time       `db:"created_at"`
+	RuleID           int             `db:"rule_id"`
+	ConversationUUID string          `db:"conversation_uuid"`
+	Action           json.RawMessage `db:"action"`
 }
@@ -1,20 +1,24 @@
 -- name: get-enabled-rules
 select
+    id,
     type,
     events,
     rules,
-    execution_mode
-from automation_rules where enabled is TRUE ORDER BY weight ASC;
+    execution_mode,
+    stop_processing,
+    active_from,
+    active_until
+from automation_rules where enabled is TRUE ORDER BY weight ASC, id ASC;
 
 -- name: get-all
-SELECT id, created_at, updated_at, "name", description, "type", rules, events, enabled, weight, execution_mode from automation_rules where type = $1 ORDER BY weight ASC;
+SELECT id, created_at, updated_at, "name", description, "type", rules, events, enabled, weight, execution_mode, stop_processing, active_from, active_until from automation_rules where type = $1 ORDER BY weight ASC, id ASC;
 
 -- name: get-rule
-SELECT id, created_at, updated_at, "name", description, "type", rules, events, enabled, weight, execution_mode from automation_rules where id = $1;
+SELECT id, created_at, updated_at, "name", description, "type", rules, events, enabled, weight, execution_mode, stop_processing, active_from, active_until from automation_rules where id = $1;
 
 -- name: update-rule
-INSERT INTO automation_rules(id, name, description, type, events, rules, enabled)
-VALUES($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO automation_rules(id, name, description, type, events, rules, enabled, stop_processing, active_from, active_until)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 ON CONFLICT (id)
 DO UPDATE SET
     name = EXCLUDED.name,
@@ -23,13 +27,17 @@ DO UPDATE SET
     events = EXCLUDED.events,
     rules = EXCLUDED.rules,
     enabled = EXCLUDED.enabled,
+    stop_processing = EXCLUDED.stop_processing,
+    active_from = EXCLUDED.active_from,
+    active_until = EXCLUDED.active_until,
     updated_at = now()
 WHERE $1 > 0
 RETURNING *;
 
 -- name: insert-rule
-INSERT into automation_rules (name, description, type, events, rules) 
-values ($1, $2, $3, $4, $5)
+-- New rules are evaluated after the existing rules of their type.
+INSERT into automation_rules (name, description, type, events, rules, stop_processing, active_from, active_until, weight)
+values ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT COALESCE(MAX(weight), 0) + 1 FROM automation_rules WHERE type = $3))
 RETURNING *;
 
 -- name: delete-rule
@@ -49,4 +57,54 @@ WHERE id = $1;
 -- name: update-rule-execution-mode
 UPDATE automation_rules
 SET execution_mode = $2, updated_at = NOW()
-WHERE type = $1;
\ No newline at end of file
+WHERE type = $1;
+-- name: get-executed-rules
+-- Rules that executed on the conversation after its last message and status change.
+SELECT e.rule_id
+FROM automation_rule_executions e
+INNER JOIN conversations c ON c.id = e.conversation_id
+WHERE e.conversation_id = $1
+    AND e.executed_at >= GREATEST(c.created_at, c.last_message_at, c.status_changed_at);
+
+-- name: upsert-rule-execution
+INSERT INTO automation_rule_executions (rule_id, conversation_id)
+VALUES ($1, $2)
+ON CONFLICT (rule_id, conversation_id)
+DO UPDATE SET executed_at = NOW();
+
+-- name: insert-rule-log
+INSERT INTO automation_rule_logs (rule_id, conversation_id, failed, actions)
+VALUES ($1, $2, $3, $4);
+
+-- name: get-rule-logs
+SELECT l.id, l.created_at, l.rule_id, c.uuid AS conversation_uuid, c.reference_number, l.failed, l.actions
+FROM automation_rule_logs l
+INNER JOIN conversations c ON c.id = l.conversation_id
+WHERE l.rule_id = $1
+ORDER BY l.created_at DESC
+LIMIT $2;
+
+-- name: delete-old-rule-logs
+DELETE FROM automation_rule_logs WHERE created_at < NOW() - make_interval(secs => $1);
+
+-- name: insert-pending-action
+INSERT INTO automation_pending_actions (rule_id, conversation_id, action, run_at)
+VALUES ($1, $2, $3, NOW() + make_interval(mins => $4));
+
+-- name: claim-due-pending-actions
+-- Deletes and returns the due delayed actions, oldest first.
+WITH due AS (
+    DELETE FROM automation_pending_actions
+    WHERE id IN (
+        SELECT id FROM automation_pending_actions
+        WHERE run_at <= NOW()
+        ORDER BY run_at
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, created_at, rule_id, conversation_id, action
+)
+SELECT due.id, due.created_at, due.rule_id, c.uuid AS conversation_uuid, due.action
+FROM due
+INNER JOIN conversations c ON c.id = due.conversation_id
+ORDER BY due.id;
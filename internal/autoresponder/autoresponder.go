@@ -0,0 +1,179 @@
+// Package autoresponder manages per-inbox automatic replies sent to new conversations.
+package autoresponder
+
+import (
+	"database/sql"
+	"embed"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/autoresponder/models"
+	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+type businessHoursStore interface {
+	Get(id int) (bmodels.BusinessHours, error)
+}
+
+// Manager manages autoresponders.
+type Manager struct {
+	q                  queries
+	lo                 *logf.Logger
+	i18n               *i18n.I18n
+	businessHoursStore businessHoursStore
+}
+
+// Opts contains options for initializing the autoresponder Manager.
+type Opts struct {
+	DB                 *sqlx.DB
+	Lo                 *logf.Logger
+	I18n               *i18n.I18n
+	BusinessHoursStore businessHoursStore
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAll          *sqlx.Stmt `query:"get-autoresponders"`
+	Get             *sqlx.Stmt `query:"get-autoresponder"`
+	GetActive       *sqlx.Stmt `query:"get-active-autoresponders"`
+	Insert          *sqlx.Stmt `query:"insert-autoresponder"`
+	Update          *sqlx.Stmt `query:"update-autoresponder"`
+	Delete          *sqlx.Stmt `query:"delete-autoresponder"`
+	GetInboxBacklog *sqlx.Stmt `query:"get-inbox-backlog"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:                  q,
+		lo:                 opts.Lo,
+		i18n:               opts.I18n,
+		businessHoursStore: opts.BusinessHoursStore,
+	}, nil
+}
+
+// GetAll returns all autoresponders of an inbox in evaluation order.
+func (m *Manager) GetAll(inboxID int) ([]models.Autoresponder, error) {
+	var autoresponders = make([]models.Autoresponder, 0)
+	if err := m.q.GetAll.Select(&autoresponders, inboxID); err != nil {
+		m.lo.Error("error fetching autoresponders", "inbox_id", inboxID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return autoresponders, nil
+}
+
+// Get returns an autoresponder of an inbox by ID.
+func (m *Manager) Get(inboxID, id int) (models.Autoresponder, error) {
+	var autoresponder models.Autoresponder
+	if err := m.q.Get.Get(&autoresponder, id, inboxID); err != nil {
+		if err == sql.ErrNoRows {
+			return autoresponder, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching autoresponder", "id", id, "error", err)
+		return autoresponder, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return autoresponder, nil
+}
+
+// Create creates a new autoresponder.
+func (m *Manager) Create(a models.Autoresponder) (models.Autoresponder, error) {
+	var result models.Autoresponder
+	if err := m.q.Insert.Get(&result, a.InboxID, a.Name, a.Enabled, a.Priority, a.Condition, a.BusinessHoursID, a.Timezone, a.BacklogThreshold, a.StartsAt, a.EndsAt, a.Content); err != nil {
+		if dbutil.IsForeignKeyError(err) {
+			return result, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+		m.lo.Error("error inserting autoresponder", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Update updates an autoresponder of an inbox.
+func (m *Manager) Update(inboxID, id int, a models.Autoresponder) (models.Autoresponder, error) {
+	var result models.Autoresponder
+	if err := m.q.Update.Get(&result, id, inboxID, a.Name, a.Enabled, a.Priority, a.Condition, a.BusinessHoursID, a.Timezone, a.BacklogThreshold, a.StartsAt, a.EndsAt, a.Content); err != nil {
+		if err == sql.ErrNoRows {
+			return result, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		if dbutil.IsForeignKeyError(err) {
+			return result, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+		m.lo.Error("error updating autoresponder", "id", id, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Delete deletes an autoresponder of an inbox.
+func (m *Manager) Delete(inboxID, id int) error {
+	if _, err := m.q.Delete.Exec(id, inboxID); err != nil {
+		m.lo.Error("error deleting autoresponder", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// Match returns the first autoresponder of an inbox, in priority order, whose schedule and condition match at the given time.
+func (m *Manager) Match(inboxID int, at time.Time) (models.Autoresponder, bool) {
+	var autoresponders []models.Autoresponder
+	if err := m.q.GetActive.Select(&autoresponders, inboxID, at); err != nil {
+		m.lo.Error("error fetching active autoresponders", "inbox_id", inboxID, "error", err)
+		return models.Autoresponder{}, false
+	}
+	for _, a := range autoresponders {
+		ok, err := m.matches(a, at)
+		if err != nil {
+			m.lo.Error("error evaluating autoresponder", "id", a.ID, "inbox_id", inboxID, "error", err)
+			continue
+		}
+		if ok {
+			return a, true
+		}
+	}
+	return models.Autoresponder{}, false
+}
+
+// matches reports whether the condition of an autoresponder matches at the given time.
+func (m *Manager) matches(a models.Autoresponder, at time.Time) (bool, error) {
+	switch a.Condition {
+	case models.ConditionAlways:
+		return true, nil
+	case models.ConditionOutsideBusinessHours, models.ConditionHoliday:
+		if !a.BusinessHoursID.Valid || !a.Timezone.Valid {
+			return false, nil
+		}
+		bh, err := m.businessHoursStore.Get(a.BusinessHoursID.Int)
+		if err != nil {
+			return false, err
+		}
+		if a.Condition == models.ConditionHoliday {
+			return businesshours.IsHoliday(bh, a.Timezone.String, at)
+		}
+		open, err := businesshours.IsOpen(bh, a.Timezone.String, at)
+		return !open, err
+	case models.ConditionHighBacklog:
+		if !a.BacklogThreshold.Valid {
+			return false, nil
+		}
+		var backlog int
+		if err := m.q.GetInboxBacklog.Get(&backlog, a.InboxID); err != nil {
+			return false, err
+		}
+		return backlog >= a.BacklogThreshold.Int, nil
+	}
+	return false, nil
+}
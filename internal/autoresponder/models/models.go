@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Autoresponder conditions.
+const (
+	ConditionAlways               = "always"
+	ConditionOutsideBusinessHours = "outside_business_hours"
+	ConditionHoliday              = "holiday"
+	ConditionHighBacklog          = "high_backlog"
+)
+
+// Autoresponder is an automatic reply sent to new conversations of an inbox when its condition matches.
+type Autoresponder struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	InboxID   int       `db:"inbox_id" json:"inbox_id"`
+	Name      string    `db:"name" json:"name"`
+	Enabled   bool      `db:"enabled" json:"enabled"`
+	Priority  int       `db:"priority" json:"priority"`
+	Condition string    `db:"condition" json:"condition"`
+
+	// Business hours and time zone, used by the outside business hours and holiday conditions.
+	BusinessHoursID null.Int    `db:"business_hours_id" json:"business_hours_id"`
+	Timezone        null.String `db:"timezone" json:"timezone"`
+
+	// Number of open conversations in the inbox at which the high backlog condition matches.
+	BacklogThreshold null.Int `db:"backlog_threshold" json:"backlog_threshold"`
+
+	// Optional window during which the autoresponder is active.
+	StartsAt null.Time `db:"starts_at" json:"starts_at"`
+	EndsAt   null.Time `db:"ends_at" json:"ends_at"`
+
+	// Reply content, can use conversation template variables.
+	Content string `db:"content" json:"content"`
+}
@@ -0,0 +1,101 @@
+-- name: get-autoresponders
+SELECT
+    id,
+    created_at,
+    updated_at,
+    inbox_id,
+    "name",
+    enabled,
+    priority,
+    "condition",
+    business_hours_id,
+    timezone,
+    backlog_threshold,
+    starts_at,
+    ends_at,
+    content
+FROM
+    inbox_autoresponders
+WHERE
+    inbox_id = $1
+ORDER BY priority, id;
+
+-- name: get-autoresponder
+SELECT
+    id,
+    created_at,
+    updated_at,
+    inbox_id,
+    "name",
+    enabled,
+    priority,
+    "condition",
+    business_hours_id,
+    timezone,
+    backlog_threshold,
+    starts_at,
+    ends_at,
+    content
+FROM
+    inbox_autoresponders
+WHERE
+    id = $1 AND inbox_id = $2;
+
+-- name: get-active-autoresponders
+-- Enabled autoresponders of an inbox whose schedule window covers $2, in evaluation order.
+SELECT
+    id,
+    created_at,
+    updated_at,
+    inbox_id,
+    "name",
+    enabled,
+    priority,
+    "condition",
+    business_hours_id,
+    timezone,
+    backlog_threshold,
+    starts_at,
+    ends_at,
+    content
+FROM
+    inbox_autoresponders
+WHERE
+    inbox_id = $1
+    AND enabled = TRUE
+    AND (starts_at IS NULL OR starts_at <= $2)
+    AND (ends_at IS NULL OR ends_at > $2)
+ORDER BY priority, id;
+
+-- name: insert-autoresponder
+INSERT INTO inbox_autoresponders (inbox_id, "name", enabled, priority, "condition", business_hours_id, timezone, backlog_threshold, starts_at, ends_at, content)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: update-autoresponder
+UPDATE inbox_autoresponders
+SET
+    "name" = $3,
+    enabled = $4,
+    priority = $5,
+    "condition" = $6,
+    business_hours_id = $7,
+    timezone = $8,
+    backlog_threshold = $9,
+    starts_at = $10,
+    ends_at = $11,
+    content = $12,
+    updated_at = NOW()
+WHERE
+    id = $1 AND inbox_id = $2
+RETURNING *;
+
+-- name: delete-autoresponder
+DELETE FROM inbox_autoresponders WHERE id = $1 AND inbox_id = $2;
+
+-- name: get-inbox-backlog
+-- Number of open conversations in an inbox.
+SELECT COUNT(*)
+FROM conversations c
+INNER JOIN conversation_statuses s ON s.id = c.status_id
+WHERE c.inbox_id = $1 AND s.category = 'open';
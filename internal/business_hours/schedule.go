@@ -0,0 +1,120 @@
+package businesshours
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+)
+
+// IsHoliday reports whether the given time falls on a holiday of the business hours in the given time zone.
+func IsHoliday(bh models.BusinessHours, timeZone string, at time.Time) (bool, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return false, fmt.Errorf("invalid time zone %s: %v", timeZone, err)
+	}
+	if len(bh.Holidays) == 0 {
+		return false, nil
+	}
+	var holidays []models.Holiday
+	if err := json.Unmarshal(bh.Holidays, &holidays); err != nil {
+		return false, fmt.Errorf("could not unmarshal holidays: %v", err)
+	}
+	date := at.In(loc).Format(time.DateOnly)
+	for _, h := range holidays {
+		if h.Date == date {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsOpen reports whether the business is open at the given time in the given time zone.
+// Holidays and days without working hours are closed.
+func IsOpen(bh models.BusinessHours, timeZone string, at time.Time) (bool, error) {
+	if bh.IsAlwaysOpen {
+		return true, nil
+	}
+	holiday, err := IsHoliday(bh, timeZone, at)
+	if err != nil || holiday {
+		return false, err
+	}
+
+	loc, _ := time.LoadLocation(timeZone)
+	local := at.In(loc)
+
+	var workingHours map[string]models.WorkingHours
+	if err := json.Unmarshal(bh.Hours, &workingHours); err != nil {
+		return false, fmt.Errorf("could not unmarshal working hours: %v", err)
+	}
+	day, ok := workingHours[local.Weekday().String()]
+	if !ok {
+		return false, nil
+	}
+
+	// Times are "HH:MM", so they compare correctly as strings.
+	now := local.Format("15:04")
+	return now >= day.Open && now < day.Close, nil
+}
+
+// NextOpenDay returns the clock time ("HH:MM") on the first open day after the day of from in the given time zone.
+func NextOpenDay(bh models.BusinessHours, timeZone string, from time.Time, clock string) (time.Time, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time zone %s: %v", timeZone, err)
+	}
+	local := from.In(loc)
+	return nextOpenDay(bh, time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc), clock)
+}
+
+// NextWeekday returns the clock time ("HH:MM") on the next given weekday after the day of from in the given
+// time zone. If the business is closed that day, the first open day after it is used instead.
+func NextWeekday(bh models.BusinessHours, timeZone string, from time.Time, weekday time.Weekday, clock string) (time.Time, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time zone %s: %v", timeZone, err)
+	}
+	local := from.In(loc)
+	days := (int(weekday)-int(local.Weekday())+6)%7 + 1
+	return nextOpenDay(bh, time.Date(local.Year(), local.Month(), local.Day()+days, 0, 0, 0, 0, loc), clock)
+}
+
+// nextOpenDay returns the clock time on the first open day starting with the given day.
+// Looks at most a year ahead.
+func nextOpenDay(bh models.BusinessHours, day time.Time, clock string) (time.Time, error) {
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s: %v", clock, err)
+	}
+	if bh.IsAlwaysOpen {
+		return time.Date(day.Year(), day.Month(), day.Day(), at.Hour(), at.Minute(), 0, 0, day.Location()), nil
+	}
+
+	var (
+		workingHours map[string]models.WorkingHours
+		holidays     []models.Holiday
+	)
+	if err := json.Unmarshal(bh.Hours, &workingHours); err != nil {
+		return time.Time{}, fmt.Errorf("could not unmarshal working hours: %v", err)
+	}
+	if len(bh.Holidays) > 0 {
+		if err := json.Unmarshal(bh.Holidays, &holidays); err != nil {
+			return time.Time{}, fmt.Errorf("could not unmarshal holidays: %v", err)
+		}
+	}
+
+	for i := 0; i < 366; i++ {
+		d := day.AddDate(0, 0, i)
+		if _, ok := workingHours[d.Weekday().String()]; !ok {
+			continue
+		}
+		date := d.Format(time.DateOnly)
+		if slices.ContainsFunc(holidays, func(h models.Holiday) bool { return h.Date == date }) {
+			continue
+		}
+		return time.Date(d.Year(), d.Month(), d.Day(), at.Hour(), at.Minute(), 0, 0, d.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("no open day in the next year")
+}
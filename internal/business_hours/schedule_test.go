@@ -0,0 +1,83 @@
+package businesshours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextOpenDay(t *testing.T) {
+	weekdays := models.BusinessHours{
+		Hours: types.JSONText(`{
+			"Monday": {"open": "09:00", "close": "17:00"},
+			"Tuesday": {"open": "09:00", "close": "17:00"},
+			"Wednesday": {"open": "09:00", "close": "17:00"},
+			"Thursday": {"open": "09:00", "close": "17:00"},
+			"Friday": {"open": "09:00", "close": "17:00"}
+		}`),
+		Holidays: types.JSONText(`[{"name": "Holiday", "date": "2025-06-10"}]`),
+	}
+
+	tests := []struct {
+		name string
+		bh   models.BusinessHours
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "next weekday",
+			bh:   weekdays,
+			from: time.Date(2025, 6, 4, 15, 0, 0, 0, time.UTC), // Wednesday
+			want: time.Date(2025, 6, 5, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "skips weekend",
+			bh:   weekdays,
+			from: time.Date(2025, 6, 6, 15, 0, 0, 0, time.UTC), // Friday
+			want: time.Date(2025, 6, 9, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "skips holiday",
+			bh:   weekdays,
+			from: time.Date(2025, 6, 9, 15, 0, 0, 0, time.UTC), // Monday
+			want: time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "always open",
+			bh:   models.BusinessHours{IsAlwaysOpen: true},
+			from: time.Date(2025, 6, 6, 15, 0, 0, 0, time.UTC),
+			want: time.Date(2025, 6, 7, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextOpenDay(tt.bh, "UTC", tt.from, "09:00")
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %v, got %v", tt.want, got)
+		})
+	}
+}
+
+func TestNextWeekday(t *testing.T) {
+	bh := models.BusinessHours{
+		Hours: types.JSONText(`{
+			"Monday": {"open": "09:00", "close": "17:00"},
+			"Tuesday": {"open": "09:00", "close": "17:00"}
+		}`),
+		Holidays: types.JSONText(`[{"name": "Holiday", "date": "2025-06-16"}]`),
+	}
+
+	// From a Monday, the next Monday is a week later.
+	got, err := NextWeekday(bh, "UTC", time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC), time.Monday, "09:00")
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 6, 9, 9, 0, 0, 0, time.UTC).Equal(got), "got %v", got)
+
+	// A holiday Monday moves to the Tuesday after it.
+	got, err = NextWeekday(bh, "UTC", time.Date(2025, 6, 11, 8, 0, 0, 0, time.UTC), time.Monday, "09:00")
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC).Equal(got), "got %v", got)
+}
@@ -0,0 +1,153 @@
+// Package captcha verifies CAPTCHA challenge responses of public endpoints with a
+// third-party provider (hCaptcha or Cloudflare Turnstile).
+package captcha
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderTurnstile = "turnstile"
+
+	// Endpoints that can be protected.
+	EndpointWebForm = "web_form"
+	EndpointWidget  = "widget"
+	EndpointCSAT    = "csat"
+
+	httpTimeout = 10 * time.Second
+)
+
+var (
+	// Endpoints is the list of endpoints that can be protected.
+	Endpoints = []string{EndpointWebForm, EndpointWidget, EndpointCSAT}
+
+	// ErrUnknownProvider is returned for an unsupported provider name.
+	ErrUnknownProvider = errors.New("unknown captcha provider")
+)
+
+// provider describes a CAPTCHA service. Both supported services share the same
+// siteverify protocol and only differ in URLs and the name of the form field holding
+// the response token.
+type provider struct {
+	verifyURL  string
+	scriptURL  string
+	widgetCls  string
+	tokenField string
+}
+
+var providers = map[string]provider{
+	ProviderHCaptcha: {
+		verifyURL:  "https://api.hcaptcha.com/siteverify",
+		scriptURL:  "https://js.hcaptcha.com/1/api.js",
+		widgetCls:  "h-captcha",
+		tokenField: "h-captcha-response",
+	},
+	ProviderTurnstile: {
+		verifyURL:  "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+		scriptURL:  "https://challenges.cloudflare.com/turnstile/v0/api.js",
+		widgetCls:  "cf-turnstile",
+		tokenField: "cf-turnstile-response",
+	},
+}
+
+// Verifier verifies challenge response tokens with a provider.
+type Verifier struct {
+	provider provider
+	name     string
+	siteKey  string
+	secret   string
+	client   *http.Client
+}
+
+// Widget contains what a page needs to render the challenge.
+type Widget struct {
+	Provider  string `json:"provider"`
+	SiteKey   string `json:"site_key"`
+	ScriptURL string `json:"script_url"`
+	Class     string `json:"class"`
+}
+
+// New returns a Verifier for the named provider.
+func New(name, siteKey, secret string) (*Verifier, error) {
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return &Verifier{
+		provider: p,
+		name:     name,
+		siteKey:  siteKey,
+		secret:   secret,
+		client:   &http.Client{Timeout: httpTimeout},
+	}, nil
+}
+
+// ValidProvider reports whether name is a supported provider.
+func ValidProvider(name string) bool {
+	_, ok := providers[name]
+	return ok
+}
+
+// TokenFields returns the token form field names of all providers.
+func TokenFields() []string {
+	out := make([]string, 0, len(providers))
+	for _, p := range providers {
+		out = append(out, p.tokenField)
+	}
+	return out
+}
+
+// TokenField returns the name of the form field the provider's widget submits the token in.
+func (v *Verifier) TokenField() string {
+	return v.provider.tokenField
+}
+
+// Widget returns the details needed to render the challenge on a page.
+func (v *Verifier) Widget() Widget {
+	return Widget{
+		Provider:  v.name,
+		SiteKey:   v.siteKey,
+		ScriptURL: v.provider.scriptURL,
+		Class:     v.provider.widgetCls,
+	}
+}
+
+// Verify checks a challenge response token with the provider. remoteIP is optional.
+func (v *Verifier) Verify(token, remoteIP string) (bool, error) {
+	if strings.TrimSpace(token) == "" {
+		return false, nil
+	}
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+		"sitekey":  {v.siteKey},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := v.client.PostForm(v.provider.verifyURL, form)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status from captcha provider: %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return false, err
+	}
+	return out.Success, nil
+}
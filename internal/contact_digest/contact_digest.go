@@ -0,0 +1,175 @@
+// Package contactdigest periodically emails contacts a digest of their open conversations.
+package contactdigest
+
+import (
+	"context"
+	"embed"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/contact_digest/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/jmoiron/sqlx"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// TmplDigest is the name of the stored email template of the digest.
+	TmplDigest = "Open conversations digest"
+
+	// contactsPerRun caps the digests sent per run, the rest are sent in later runs.
+	contactsPerRun = 500
+	// maxConversations is the number of most recently active conversations listed per digest.
+	maxConversations = 25
+)
+
+type templateStore interface {
+	RenderStoredEmailTemplate(name string, data any) (string, string, error)
+}
+
+type notifierStore interface {
+	Send(message notifier.Message) error
+}
+
+type maintenanceStore interface {
+	InWindow(at time.Time) bool
+}
+
+// Manager sends digests of open conversations to contacts.
+type Manager struct {
+	q           queries
+	lo          *logf.Logger
+	template    templateStore
+	notifier    notifierStore
+	maintenance maintenanceStore
+	period      time.Duration
+	linkURL     string
+}
+
+// Opts contains options for initializing the contact digest Manager.
+type Opts struct {
+	DB       *sqlx.DB
+	Lo       *logf.Logger
+	Template templateStore
+	Notifier notifierStore
+	// Maintenance defers digests while a maintenance window is in progress.
+	Maintenance maintenanceStore
+	// Period is the minimum time between two digests to the same contact.
+	Period time.Duration
+	// LinkURL is the URL of a conversation in the customer portal, with {uuid} and {reference_number}
+	// placeholders. Conversations are listed without links when empty.
+	LinkURL string
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetDueContacts       *sqlx.Stmt `query:"get-due-contacts"`
+	GetOpenConversations *sqlx.Stmt `query:"get-open-conversations"`
+	MarkDigestSent       *sqlx.Stmt `query:"mark-digest-sent"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:           q,
+		lo:          opts.Lo,
+		template:    opts.Template,
+		notifier:    opts.Notifier,
+		maintenance: opts.Maintenance,
+		period:      opts.Period,
+		linkURL:     opts.LinkURL,
+	}, nil
+}
+
+// Run periodically sends digests to contacts that are due one.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.sendDue(ctx); err != nil {
+				m.lo.Error("error sending contact digests", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends a digest to every contact with open conversations that was not sent one within the period.
+// Contacts that opted out of digests or all automated messages are skipped. Nothing is sent during
+// a maintenance window, contacts stay due and get their digest after it.
+func (m *Manager) sendDue(ctx context.Context) error {
+	if m.maintenance != nil && m.maintenance.InWindow(time.Now()) {
+		return nil
+	}
+
+	var contacts []models.Contact
+	if err := m.q.GetDueContacts.SelectContext(ctx, &contacts, int(m.period.Seconds()), contactsPerRun); err != nil {
+		return err
+	}
+
+	for _, contact := range contacts {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := m.send(ctx, contact); err != nil {
+			m.lo.Error("error sending contact digest", "contact_id", contact.ID, "error", err)
+			continue
+		}
+		if _, err := m.q.MarkDigestSent.ExecContext(ctx, contact.ID); err != nil {
+			return err
+		}
+	}
+	if len(contacts) > 0 {
+		m.lo.Info("sent contact digests", "count", len(contacts))
+	}
+	return nil
+}
+
+func (m *Manager) send(ctx context.Context, contact models.Contact) error {
+	var conversations []models.Conversation
+	if err := m.q.GetOpenConversations.SelectContext(ctx, &conversations, contact.ID, maxConversations); err != nil {
+		return err
+	}
+	if len(conversations) == 0 {
+		return nil
+	}
+	for i := range conversations {
+		conversations[i].Link = m.link(conversations[i])
+	}
+
+	content, subject, err := m.template.RenderStoredEmailTemplate(TmplDigest, map[string]any{
+		"Contact":       contact,
+		"Conversations": conversations,
+	})
+	if err != nil {
+		return err
+	}
+	return m.notifier.Send(notifier.Message{
+		RecipientEmails: []string{contact.Email},
+		Subject:         subject,
+		Content:         content,
+		Provider:        notifier.ProviderEmail,
+	})
+}
+
+// link returns the portal URL of a conversation.
+func (m *Manager) link(c models.Conversation) string {
+	if m.linkURL == "" {
+		return ""
+	}
+	return strings.NewReplacer("{uuid}", c.UUID, "{reference_number}", c.ReferenceNumber).Replace(m.linkURL)
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"github.com/volatiletech/null/v9"
+)
+
+// Contact is a contact due for a digest of their open conversations.
+type Contact struct {
+	ID        int    `db:"id" json:"id"`
+	FirstName string `db:"first_name" json:"first_name"`
+	LastName  string `db:"last_name" json:"last_name"`
+	Email     string `db:"email" json:"email"`
+}
+
+// Conversation is an open conversation listed in a digest.
+type Conversation struct {
+	UUID            string    `db:"uuid" json:"uuid"`
+	ReferenceNumber string    `db:"reference_number" json:"reference_number"`
+	Subject         string    `db:"subject" json:"subject"`
+	Status          string    `db:"status" json:"status"`
+	LastMessageAt   null.Time `db:"last_message_at" json:"last_message_at"`
+	// Link to the conversation in the customer portal, empty when no portal URL is configured.
+	Link string `db:"-" json:"link"`
+}
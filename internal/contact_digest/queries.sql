@@ -0,0 +1,31 @@
+-- name: get-due-contacts
+-- Contacts with open conversations that have not been sent a digest within the interval (in seconds).
+SELECT u.id, u.first_name, u.last_name, u.email
+FROM users u
+LEFT JOIN contact_preferences p ON p.contact_id = u.id
+WHERE u.type = 'contact'
+    AND u.deleted_at IS NULL
+    AND COALESCE(u.email, '') <> ''
+    AND NOT COALESCE(p.do_not_contact, FALSE)
+    AND NOT COALESCE(p.no_digest, FALSE)
+    AND (p.digest_sent_at IS NULL OR p.digest_sent_at < NOW() - $1 * INTERVAL '1 second')
+    AND EXISTS (
+        SELECT 1 FROM conversations c
+        JOIN conversation_statuses s ON s.id = c.status_id
+        WHERE c.contact_id = u.id AND s.category <> 'resolved'
+    )
+ORDER BY u.id
+LIMIT $2;
+
+-- name: get-open-conversations
+SELECT c.uuid, c.reference_number, COALESCE(c.subject, '') AS subject, s.name AS status, c.last_message_at
+FROM conversations c
+JOIN conversation_statuses s ON s.id = c.status_id
+WHERE c.contact_id = $1 AND s.category <> 'resolved'
+ORDER BY c.last_message_at DESC NULLS LAST
+LIMIT $2;
+
+-- name: mark-digest-sent
+INSERT INTO contact_preferences (contact_id, digest_sent_at)
+VALUES ($1, NOW())
+ON CONFLICT (contact_id) DO UPDATE SET digest_sent_at = NOW();
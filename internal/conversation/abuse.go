@@ -0,0 +1,76 @@
+package conversation
+
+import (
+	abmodels "github.com/abhinavxd/libredesk/internal/abuse/models"
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	nmodels "github.com/abhinavxd/libredesk/internal/notification/models"
+	"github.com/volatiletech/null/v9"
+)
+
+type abuseStore interface {
+	Check(text string) (abmodels.Verdict, error)
+	Flag(messageID, conversationID, contactID int, assignedUserID null.Int, v abmodels.Verdict) error
+}
+
+// SetAbuseStore sets the store that checks incoming messages for abuse.
+func (m *Manager) SetAbuseStore(store abuseStore) {
+	m.abuseStore = store
+}
+
+// checkAbuse flags an incoming message if it is abusive, tags its conversation and notifies
+// the members of the configured team.
+func (m *Manager) checkAbuse(message models.Message) {
+	if m.abuseStore == nil {
+		return
+	}
+	verdict, err := m.abuseStore.Check(message.TextContent)
+	if err != nil {
+		m.lo.Error("error checking message for abuse", "message_id", message.ID, "error", err)
+		return
+	}
+	if !verdict.Abusive {
+		return
+	}
+
+	conversation, err := m.GetConversation(message.ConversationID, "", "")
+	if err != nil {
+		m.lo.Error("error fetching conversation of abusive message", "message_id", message.ID, "error", err)
+		return
+	}
+	m.lo.Info("abusive message flagged", "message_id", message.ID, "conversation_uuid", conversation.UUID, "source", verdict.Source)
+	if err := m.abuseStore.Flag(message.ID, conversation.ID, conversation.ContactID, conversation.AssignedUserID, verdict); err != nil {
+		return
+	}
+
+	if verdict.Tag != "" {
+		systemUser, err := m.userStore.GetSystemUser()
+		if err != nil {
+			m.lo.Error("error fetching system user", "error", err)
+		} else if err := m.SetConversationTags(conversation.UUID, amodels.ActionAddTags, []string{verdict.Tag}, systemUser); err != nil {
+			m.lo.Error("error tagging abusive conversation", "conversation_uuid", conversation.UUID, "error", err)
+		}
+	}
+
+	if verdict.NotifyTeamID > 0 {
+		members, err := m.teamStore.GetMembers(verdict.NotifyTeamID)
+		if err != nil {
+			m.lo.Error("error fetching team members for abuse notification", "team_id", verdict.NotifyTeamID, "error", err)
+			return
+		}
+		recipientIDs := make([]int, 0, len(members))
+		for _, member := range members {
+			recipientIDs = append(recipientIDs, member.ID)
+		}
+		m.dispatcher.Send(notifier.Notification{
+			Type:             nmodels.NotificationTypeAbuse,
+			RecipientIDs:     recipientIDs,
+			Title:            m.i18n.Ts("notification.abusiveMessage", "referenceNumber", conversation.ReferenceNumber),
+			Body:             null.StringFrom(conversation.Contact.FullName()),
+			ConversationID:   null.IntFrom(conversation.ID),
+			MessageID:        null.IntFrom(message.ID),
+			ConversationUUID: conversation.UUID,
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package conversation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// archiveBatchSize is the number of conversations archived per run of the archiver.
+const archiveBatchSize = 100
+
+// archivedBody is the body of a message moved to cold storage.
+type archivedBody struct {
+	ID          int    `db:"id" json:"-"`
+	Content     string `db:"content" json:"content"`
+	TextContent string `db:"text_content" json:"text_content"`
+}
+
+// RunArchiver moves the message bodies of conversations resolved and inactive for longer than archiveAfter
+// to cold storage every hour, keeping the messages table small. Archived conversations are restored when
+// their messages are fetched.
+func (m *Manager) RunArchiver(ctx context.Context, archiveAfter time.Duration) {
+	if archiveAfter <= 0 {
+		m.lo.Info("conversation archive period is non-positive, skipping archiver", "archive_after", archiveAfter)
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var ids []int
+			if err := m.q.GetArchivableConversations.SelectContext(ctx, &ids, time.Now().Add(-archiveAfter), archiveBatchSize); err != nil {
+				m.lo.Error("error fetching conversations to archive", "error", err)
+				continue
+			}
+			for _, id := range ids {
+				if err := m.archiveConversation(ctx, id); err != nil {
+					m.lo.Error("error archiving conversation", "conversation_id", id, "error", err)
+				}
+			}
+			if len(ids) > 0 {
+				m.lo.Info("archived conversations", "count", len(ids))
+			}
+		}
+	}
+}
+
+// archiveConversation compresses the message bodies of a conversation into the archive table.
+func (m *Manager) archiveConversation(ctx context.Context, conversationID int) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Skip conversations archived or restored by another instance meanwhile.
+	var id int
+	if err := tx.Stmtx(m.q.LockConversationForArchive).Get(&id, conversationID, true); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	var bodies []archivedBody
+	if err := tx.Stmtx(m.q.GetArchiveMessageBodies).Select(&bodies, conversationID); err != nil {
+		return fmt.Errorf("fetching message bodies: %w", err)
+	}
+	for _, b := range bodies {
+		blob, err := compressBody(b)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Stmtx(m.q.InsertMessageArchive).Exec(b.ID, blob); err != nil {
+			return fmt.Errorf("archiving message %d: %w", b.ID, err)
+		}
+	}
+	if _, err := tx.Stmtx(m.q.SetConversationArchived).Exec(conversationID, true); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// restoreArchivedConversation moves the message bodies of an archived conversation back to the messages
+// table. It does nothing for conversations that aren't archived.
+func (m *Manager) restoreArchivedConversation(conversationUUID string) error {
+	var conversationID int
+	if err := m.q.GetArchivedConversationID.Get(&conversationID, conversationUUID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	tx, err := m.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Another request may have restored it already.
+	var id int
+	if err := tx.Stmtx(m.q.LockConversationForArchive).Get(&id, conversationID, false); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	var archives []struct {
+		MessageID int    `db:"message_id"`
+		Body      []byte `db:"body"`
+	}
+	if err := tx.Stmtx(m.q.GetMessageArchives).Select(&archives, conversationID); err != nil {
+		return fmt.Errorf("fetching message archives: %w", err)
+	}
+	for _, a := range archives {
+		b, err := decompressBody(a.Body)
+		if err != nil {
+			return fmt.Errorf("decompressing message %d: %w", a.MessageID, err)
+		}
+		if _, err := tx.Stmtx(m.q.RestoreMessageArchive).Exec(a.MessageID, b.Content, b.TextContent); err != nil {
+			return fmt.Errorf("restoring message %d: %w", a.MessageID, err)
+		}
+	}
+	if _, err := tx.Stmtx(m.q.SetConversationArchived).Exec(conversationID, false); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	m.lo.Info("restored archived conversation", "conversation_id", conversationID, "messages", len(archives))
+	return nil
+}
+
+func compressBody(b archivedBody) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressBody(blob []byte) (archivedBody, error) {
+	var b archivedBody
+	zr, err := gzip.NewReader(bytes.NewReader(blob))
+	if err != nil {
+		return b, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return b, err
+	}
+	err = json.Unmarshal(data, &b)
+	return b, err
+}
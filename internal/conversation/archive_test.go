@@ -0,0 +1,104 @@
+package conversation
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/zerodha/logf"
+)
+
+func TestCompressBody(t *testing.T) {
+	tests := []archivedBody{
+		{},
+		{ID: 1, Content: "<p>Hello</p>", TextContent: "Hello"},
+		{ID: 2, Content: "<p>" + strings.Repeat("Ünïcödé 👋 ", 5000) + "</p>", TextContent: strings.Repeat("Ünïcödé 👋 ", 5000)},
+	}
+	for _, want := range tests {
+		blob, err := compressBody(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decompressBody(blob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The ID isn't stored, it is the message_id of the archive row.
+		if got.Content != want.Content || got.TextContent != want.TextContent {
+			t.Errorf("decompressBody(compressBody(%d)) = %+v, want the same content", want.ID, got)
+		}
+	}
+
+	if _, err := decompressBody([]byte("not gzip")); err == nil {
+		t.Error("decompressBody of invalid data: want error")
+	}
+}
+
+// TestArchiveRestore archives a conversation and checks that fetching one of its messages restores the bodies.
+//
+// It needs a migrated database with test data in LIBREDESK_TEST_DSN, e.g. filled with
+// `./libredesk --generate-test-data 100`. The conversation it archives is restored at the end.
+func TestArchiveRestore(t *testing.T) {
+	dsn := os.Getenv("LIBREDESK_TEST_DSN")
+	if dsn == "" {
+		t.Skip("LIBREDESK_TEST_DSN not set")
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, db, efs); err != nil {
+		t.Fatal(err)
+	}
+	lo := logf.New(logf.Opts{Level: logf.ErrorLevel})
+	m := &Manager{q: q, db: db, lo: &lo}
+
+	var msg struct {
+		ConversationID int    `db:"conversation_id"`
+		UUID           string `db:"uuid"`
+		Content        string `db:"content"`
+		TextContent    string `db:"text_content"`
+	}
+	if err := db.Get(&msg, `
+		SELECT m.conversation_id, m.uuid, m."content", COALESCE(m.text_content, '') AS text_content
+		FROM conversation_messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE m."content" IS NOT NULL AND c.archived_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM media WHERE media.model_type = 'messages' AND media.model_id = m.id)
+		ORDER BY m.id LIMIT 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.archiveConversation(context.Background(), msg.ConversationID); err != nil {
+		t.Fatal(err)
+	}
+	var stored *string
+	if err := db.Get(&stored, `SELECT "content" FROM conversation_messages WHERE uuid = $1`, msg.UUID); err != nil {
+		t.Fatal(err)
+	}
+	if stored != nil {
+		t.Fatalf("content of archived message = %q, want NULL", *stored)
+	}
+
+	got, err := m.GetMessage(msg.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != msg.Content || got.TextContent != msg.TextContent {
+		t.Errorf("GetMessage of archived message = %q/%q, want %q/%q", got.Content, got.TextContent, msg.Content, msg.TextContent)
+	}
+	var archives int
+	if err := db.Get(&archives, `SELECT COUNT(*) FROM conversation_message_archives a JOIN conversation_messages m ON m.id = a.message_id WHERE m.conversation_id = $1`, msg.ConversationID); err != nil {
+		t.Fatal(err)
+	}
+	if archives != 0 {
+		t.Errorf("archives after restore = %d, want 0", archives)
+	}
+}
@@ -0,0 +1,58 @@
+package conversation
+
+import (
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// sendAutoresponse replies to a new conversation with the first matching autoresponder of its inbox, if any.
+func (m *Manager) sendAutoresponse(conversation models.Conversation) {
+	autoresponder, ok := m.autoresponderStore.Match(conversation.InboxID, time.Now())
+	if !ok {
+		return
+	}
+	if !m.contactAllows(conversation.ContactID, umodels.ContactPreferences.AllowsAutomated) {
+		m.lo.Info("contact opted out of automated messages, skipping autoresponse", "conversation_uuid", conversation.UUID, "contact_id", conversation.ContactID)
+		return
+	}
+
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		m.lo.Error("error fetching system user for autoresponse", "error", err)
+		return
+	}
+	data, err := m.BuildTemplateData(conversation.UUID, systemUser.ID)
+	if err != nil {
+		m.lo.Error("error building autoresponse template data", "conversation_uuid", conversation.UUID, "error", err)
+		return
+	}
+	content := autoresponder.Content
+	variant, hasVariant := m.pickAutoresponderVariant(autoresponder.ID)
+	if hasVariant {
+		content = variant.Content
+	}
+	content = m.template.RenderString(data, content)
+
+	to, cc, bcc, err := m.makeRecipients(conversation)
+	if err != nil {
+		m.lo.Error("error making autoresponse recipients", "conversation_uuid", conversation.UUID, "error", err)
+		return
+	}
+	meta := map[string]any{
+		"autoresponder_id": autoresponder.ID,
+	}
+	if hasVariant {
+		meta["variant_id"] = variant.ID
+	}
+	if _, err := m.QueueReply(nil /**media**/, conversation.InboxID, systemUser.ID, conversation.ContactID, conversation.UUID, content, to, cc, bcc, meta); err != nil {
+		m.lo.Error("error sending autoresponse", "conversation_uuid", conversation.UUID, "autoresponder_id", autoresponder.ID, "error", err)
+		return
+	}
+	if hasVariant {
+		m.variantStore.RecordSend(variant.ID, conversation.ID, null.Int{})
+	}
+	m.lo.Info("sent autoresponse", "conversation_uuid", conversation.UUID, "autoresponder_id", autoresponder.ID)
+}
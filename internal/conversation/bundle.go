@@ -0,0 +1,281 @@
+package conversation
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// ExportBundle returns a conversation as a bundle that can be imported into another instance, with its
+// contact, tags and incoming and outgoing messages, oldest first. Private notes are included only if
+// includePrivate is set and attachment contents only if includeAttachments is set, otherwise the
+// attachments are only listed.
+func (m *Manager) ExportBundle(uuid string, includePrivate, includeAttachments bool) (models.Bundle, error) {
+	conversation, err := m.GetConversation(0, uuid, "")
+	if err != nil {
+		return models.Bundle{}, err
+	}
+
+	var private *bool
+	if !includePrivate {
+		private = new(bool)
+	}
+	types := []string{models.MessageIncoming, models.MessageOutgoing}
+
+	var messages []models.Message
+	for page := 1; ; page++ {
+		batch, _, err := m.GetConversationMessages(uuid, page, maxMessagesPerPage, private, types, 0)
+		if err != nil {
+			return models.Bundle{}, err
+		}
+		messages = append(messages, batch...)
+		if len(batch) < maxMessagesPerPage {
+			break
+		}
+	}
+	// Messages are fetched newest first.
+	slices.Reverse(messages)
+
+	tags := []string{}
+	if conversation.Tags.Valid {
+		if err := json.Unmarshal(conversation.Tags.JSON, &tags); err != nil {
+			m.lo.Error("error unmarshalling conversation tags", "uuid", uuid, "error", err)
+		}
+	}
+
+	b := models.Bundle{
+		Version:    models.BundleVersion,
+		ExportedAt: time.Now(),
+		Conversation: models.BundleConversation{
+			UUID:            conversation.UUID,
+			ReferenceNumber: conversation.ReferenceNumber,
+			Subject:         conversation.Subject.String,
+			Status:          conversation.Status.String,
+			Priority:        conversation.Priority.String,
+			InboxName:       conversation.InboxName,
+			CreatedAt:       conversation.CreatedAt,
+		},
+		Contact: models.BundleContact{
+			FirstName:   conversation.Contact.FirstName,
+			LastName:    conversation.Contact.LastName,
+			Email:       conversation.Contact.Email.String,
+			PhoneNumber: conversation.Contact.PhoneNumber.String,
+		},
+		Tags:     tags,
+		Messages: make([]models.BundleMessage, 0, len(messages)),
+	}
+	for _, msg := range messages {
+		bm := models.BundleMessage{
+			Type:        msg.Type,
+			SenderType:  msg.SenderType,
+			AuthorName:  strings.TrimSpace(msg.Author.FirstName + " " + msg.Author.LastName),
+			AuthorEmail: msg.Author.Email.String,
+			Private:     msg.Private,
+			ContentType: msg.ContentType,
+			Content:     msg.Content,
+			SourceID:    msg.SourceID.String,
+			CreatedAt:   msg.CreatedAt,
+			Attachments: make([]models.BundleAttachment, 0, len(msg.Attachments)),
+		}
+		for _, a := range msg.Attachments {
+			ba := models.BundleAttachment{
+				Name:        a.Name,
+				ContentType: a.ContentType,
+				ContentID:   a.ContentID,
+				Disposition: a.Disposition,
+				Size:        a.Size,
+			}
+			if includeAttachments {
+				blob, err := m.mediaStore.GetBlob(a.UUID)
+				if err != nil {
+					m.lo.Error("error fetching attachment blob for bundle", "uuid", uuid, "media_uuid", a.UUID, "error", err)
+					return models.Bundle{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+				}
+				ba.Data = blob
+			}
+			bm.Attachments = append(bm.Attachments, ba)
+		}
+		b.Messages = append(b.Messages, bm)
+	}
+	return b, nil
+}
+
+// ImportBundle creates a conversation in the given inbox from an exported bundle and returns its UUID.
+// The contact is matched by email and created if missing, agents are matched by email and messages of
+// unknown agents are attributed to the system user. Messages keep their original timestamps, missing
+// tags are created and attachments are imported only if the bundle carries their contents.
+func (m *Manager) ImportBundle(b models.Bundle, inboxID int) (string, error) {
+	if b.Version != models.BundleVersion {
+		return "", envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+	contactEmail := strings.TrimSpace(b.Contact.Email)
+	if !stringutil.ValidEmail(contactEmail) {
+		return "", envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidEmail"), nil)
+	}
+	if len(b.Messages) == 0 {
+		return "", envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "messages"), nil)
+	}
+	for _, msg := range b.Messages {
+		if msg.Type != models.MessageIncoming && msg.Type != models.MessageOutgoing {
+			return "", envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+	}
+	if _, err := m.inboxStore.Get(inboxID); err != nil {
+		return "", err
+	}
+
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	contact := umodels.User{
+		Email:     null.StringFrom(contactEmail),
+		FirstName: b.Contact.FirstName,
+		LastName:  b.Contact.LastName,
+	}
+	if err := m.userStore.CreateContact(&contact); err != nil {
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	meta := map[string]any{
+		"imported_from": map[string]any{
+			"uuid":             b.Conversation.UUID,
+			"reference_number": b.Conversation.ReferenceNumber,
+			"inbox_name":       b.Conversation.InboxName,
+		},
+	}
+	conversationID, conversationUUID, err := m.CreateConversation(contact.ID, inboxID, "", time.Now(), b.Conversation.Subject, false, meta, nil, 0, 0)
+	if err != nil {
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	// Agents are looked up once per email.
+	agentIDs := map[string]int{}
+	var last *models.Message
+	for _, bm := range b.Messages {
+		msg := models.Message{
+			Type:             bm.Type,
+			Status:           models.MessageStatusSent,
+			ConversationID:   conversationID,
+			ConversationUUID: conversationUUID,
+			Content:          bm.Content,
+			ContentType:      bm.ContentType,
+			Private:          bm.Private,
+			SourceID:         null.NewString(bm.SourceID, bm.SourceID != ""),
+			Meta:             json.RawMessage(`{}`),
+			CreatedAt:        bm.CreatedAt,
+		}
+		if msg.ContentType != models.ContentTypeHTML {
+			msg.ContentType = models.ContentTypeText
+		}
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = time.Now()
+		}
+
+		if bm.Type == models.MessageIncoming && bm.SenderType == models.SenderTypeContact {
+			msg.Status = models.MessageStatusReceived
+			msg.SenderID = contact.ID
+			msg.SenderType = models.SenderTypeContact
+		} else {
+			email := strings.ToLower(strings.TrimSpace(bm.AuthorEmail))
+			id, ok := agentIDs[email]
+			if !ok {
+				id = systemUser.ID
+				if email != "" {
+					if agent, err := m.userStore.GetAgent(0, email); err == nil {
+						id = agent.ID
+					}
+				}
+				agentIDs[email] = id
+			}
+			msg.SenderID = id
+			msg.SenderType = models.SenderTypeAgent
+		}
+
+		if msg.ContentType == models.ContentTypeText {
+			msg.TextContent = msg.Content
+		} else {
+			msg.TextContent = stringutil.HTML2Text(msg.Content)
+		}
+		if msg.SenderType == models.SenderTypeContact {
+			msg.TextContent = stringutil.StripSignature(msg.TextContent)
+		}
+
+		// Upload attachments carried by the bundle, this also rewrites inline image references in the content.
+		for _, a := range bm.Attachments {
+			if len(a.Data) == 0 {
+				continue
+			}
+			msg.Attachments = append(msg.Attachments, attachment.Attachment{
+				Name:        a.Name,
+				ContentType: a.ContentType,
+				ContentID:   a.ContentID,
+				Disposition: a.Disposition,
+				Size:        len(a.Data),
+				Content:     a.Data,
+			})
+		}
+		if err := m.uploadMessageAttachments(&msg); err != nil {
+			m.lo.Error("error uploading imported message attachments", "conversation_uuid", conversationUUID, "error", err)
+			return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+
+		if err := m.q.InsertImportedMessage.Get(&msg, msg.Type, msg.Status, msg.ConversationID, msg.Content, msg.TextContent, msg.SenderID, msg.SenderType,
+			msg.Private, msg.ContentType, msg.SourceID, msg.Meta, msg.CreatedAt); err != nil {
+			m.lo.Error("error inserting imported message", "conversation_uuid", conversationUUID, "error", err)
+			return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		for _, media := range msg.Media {
+			m.mediaStore.Attach(media.ID, mmodels.ModelMessages, msg.ID)
+		}
+		m.addConversationParticipant(msg.SenderID, conversationUUID)
+		if !msg.Private {
+			last = &msg
+		}
+	}
+
+	if last != nil {
+		lastMessage := last.TextContent
+		if strings.TrimSpace(lastMessage) == "" && len(last.Media) > 0 {
+			lastMessage = m.getMediaPreview(last.Media[0])
+		}
+		m.UpdateConversationLastMessage(conversationID, conversationUUID, lastMessage, last.SenderType, last.Type, false, last.CreatedAt, last.SenderID)
+	}
+
+	createdAt := b.Conversation.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = b.Messages[0].CreatedAt
+	}
+	if _, err := m.q.UpdateImportedConversation.Exec(conversationID, createdAt, b.Conversation.Status, b.Conversation.Priority); err != nil {
+		m.lo.Error("error updating imported conversation", "conversation_uuid", conversationUUID, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	if len(b.Tags) > 0 {
+		if _, err := m.q.InsertMissingTags.Exec(pq.Array(b.Tags)); err != nil {
+			m.lo.Error("error inserting imported conversation tags", "conversation_uuid", conversationUUID, "error", err)
+			return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		if _, err := m.q.AddConversationTags.Exec(conversationUUID, pq.Array(b.Tags)); err != nil {
+			m.lo.Error("error adding imported conversation tags", "conversation_uuid", conversationUUID, "error", err)
+			return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+	}
+
+	// Reply and waiting times are derived from the imported messages.
+	if _, err := m.q.RecalculateConversationMetrics.Exec(pq.Array([]int{conversationID}), systemUser.ID); err != nil {
+		m.lo.Error("error recalculating imported conversation metrics", "conversation_uuid", conversationUUID, "error", err)
+	}
+	return conversationUUID, nil
+}
@@ -0,0 +1,71 @@
+package conversation
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+)
+
+// conversationComputedFields are the conversation list filter fields computed from the conversation's
+// last public interaction, mapped to their SQL expressions.
+var conversationComputedFields = map[string]string{
+	// The contact replied last and is waiting on an agent.
+	"awaiting_agent": "COALESCE(conversations.last_interaction_sender = 'contact', false)",
+	// An agent replied last and is waiting on the contact.
+	"awaiting_customer": "COALESCE(conversations.last_interaction_sender = 'agent', false)",
+	// Whole days since the last interaction, or since the conversation was created if it has none.
+	"idle_days": "FLOOR(EXTRACT(EPOCH FROM NOW() - COALESCE(conversations.last_interaction_at, conversations.created_at)) / 86400)",
+}
+
+// isComputedFilter returns true if the filter is on a computed conversation field.
+func isComputedFilter(f dbutil.Filter) bool {
+	_, ok := conversationComputedFields[f.Field]
+	return ok && f.Model == "conversations"
+}
+
+// computedFilterCondition returns the SQL condition of a filter on a computed conversation field and its
+// argument, if any, as parameter $paramIdx.
+func computedFilterCondition(f dbutil.Filter, paramIdx int) (string, []any, error) {
+	expr, ok := conversationComputedFields[f.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid computed field: %s", f.Field)
+	}
+
+	if f.Field == "idle_days" {
+		days, err := strconv.Atoi(f.Value)
+		if err != nil || days < 0 {
+			return "", nil, fmt.Errorf("invalid idle days %q", f.Value)
+		}
+		var op string
+		switch f.Operator {
+		case "equals":
+			op = "="
+		case "not equals":
+			op = "!="
+		case "greater than":
+			op = ">"
+		case "less than":
+			op = "<"
+		default:
+			return "", nil, fmt.Errorf("invalid operator %s for field %s", f.Operator, f.Field)
+		}
+		return fmt.Sprintf("%s %s $%d", expr, op, paramIdx), []any{days}, nil
+	}
+
+	val, err := strconv.ParseBool(f.Value)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid boolean %q for field %s", f.Value, f.Field)
+	}
+	switch f.Operator {
+	case "equals":
+	case "not equals":
+		val = !val
+	default:
+		return "", nil, fmt.Errorf("invalid operator %s for field %s", f.Operator, f.Field)
+	}
+	if !val {
+		return "NOT " + expr, nil, nil
+	}
+	return expr, nil, nil
+}
@@ -0,0 +1,43 @@
+package conversation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+)
+
+func TestComputedFilterCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  dbutil.Filter
+		want    string
+		args    int
+		wantErr bool
+	}{
+		{"awaiting agent", dbutil.Filter{Field: "awaiting_agent", Operator: "equals", Value: "true"}, "COALESCE(conversations.last_interaction_sender = 'contact', false)", 0, false},
+		{"not awaiting agent", dbutil.Filter{Field: "awaiting_agent", Operator: "equals", Value: "false"}, "NOT COALESCE(", 0, false},
+		{"not equals negates", dbutil.Filter{Field: "awaiting_customer", Operator: "not equals", Value: "true"}, "NOT COALESCE(conversations.last_interaction_sender = 'agent'", 0, false},
+		{"idle days", dbutil.Filter{Field: "idle_days", Operator: "greater than", Value: "3"}, "/ 86400) > $4", 1, false},
+		{"invalid boolean", dbutil.Filter{Field: "awaiting_agent", Operator: "equals", Value: "maybe"}, "", 0, true},
+		{"invalid days", dbutil.Filter{Field: "idle_days", Operator: "less than", Value: "-1"}, "", 0, true},
+		{"invalid operator", dbutil.Filter{Field: "idle_days", Operator: "set", Value: "1"}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args, err := computedFilterCondition(tt.filter, 4)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.HasPrefix(cond, tt.want) && !strings.HasSuffix(cond, tt.want) {
+				t.Errorf("cond = %q, want %q", cond, tt.want)
+			}
+			if len(args) != tt.args {
+				t.Errorf("args = %v, want %d", args, tt.args)
+			}
+		})
+	}
+}
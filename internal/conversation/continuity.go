@@ -202,7 +202,7 @@ func (m *Manager) sendContinuityEmail(conv models.ContinuityConversation, maxMes
 	references, inReplyTo := m.BuildEmailThreadingHeaders(conv.ID, sourceID)
 
 	// Render message template
-	if err := m.RenderMessageInTemplate(linkedEmailInbox.Channel(), &message); err != nil {
+	if err := m.RenderMessageInTemplate(linkedEmailInbox, &message); err != nil {
 		// Clean up the inserted message on failure
 		cleanUp = true
 		m.lo.Error("error rendering email template for continuity email", "error", err, "message_id", message.ID, "message_uuid", message.UUID, "conversation_uuid", conv.UUID)
@@ -2,6 +2,7 @@
 package conversation
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"embed"
@@ -9,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
@@ -17,11 +19,14 @@ import (
 
 	"github.com/abhinavxd/libredesk/internal/automation"
 	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	armodels "github.com/abhinavxd/libredesk/internal/autoresponder/models"
 	"github.com/abhinavxd/libredesk/internal/conversation/models"
 	pmodels "github.com/abhinavxd/libredesk/internal/conversation/priority/models"
 	smodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
 	"github.com/abhinavxd/libredesk/internal/csat"
 	csatModels "github.com/abhinavxd/libredesk/internal/csat/models"
+	customAttribute "github.com/abhinavxd/libredesk/internal/custom_attribute"
+	camodels "github.com/abhinavxd/libredesk/internal/custom_attribute/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/inbox"
@@ -29,6 +34,7 @@ import (
 	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	nmodels "github.com/abhinavxd/libredesk/internal/notification/models"
+	"github.com/abhinavxd/libredesk/internal/sla"
 	slaModels "github.com/abhinavxd/libredesk/internal/sla/models"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
@@ -36,6 +42,7 @@ import (
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/abhinavxd/libredesk/internal/ws"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 	"github.com/knadh/go-i18n"
@@ -53,6 +60,9 @@ var (
 	usersAllowedFields              = []string{"email"}
 )
 
+// customAttributeFilterModel is the filter model of conversation custom attributes in list filters.
+const customAttributeFilterModel = "conversation_custom_attributes"
+
 const (
 	conversationsListMaxPageSize = 500
 )
@@ -69,7 +79,10 @@ type Manager struct {
 	slaStore                   slaStore
 	settingsStore              settingsStore
 	csatStore                  csatStore
+	abuseStore                 abuseStore
+	variantStore               variantStore
 	webhookStore               webhookStore
+	autoresponderStore         autoresponderStore
 	dispatcher                 *notifier.Dispatcher
 	lo                         *logf.Logger
 	db                         *sqlx.DB
@@ -80,11 +93,14 @@ type Manager struct {
 	incomingMessageQueue       chan models.IncomingMessage
 	outgoingMessageQueue       chan models.Message
 	outgoingProcessingMessages sync.Map
+	outgoingClaimLease         time.Duration
+	sendLimiters               sync.Map // inbox ID -> *sendLimiter
 	closed                     bool
 	closedMu                   sync.RWMutex
 	wg                         sync.WaitGroup
 	continuityConfig           ContinuityConfig
 	subjectRefFormat           string
+	referenceLinks             bool
 }
 
 // WidgetConversationView represents the conversation data for widget clients
@@ -107,7 +123,9 @@ type WidgetConversationResponse struct {
 type slaStore interface {
 	ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaID int) (slaModels.SLAPolicy, error)
 	CreateNextResponseSLAEvent(conversationID, appliedSLAID, slaPolicyID, assignedTeamID int) (time.Time, error)
+	CreateAssignmentSLAEvent(conversationID, appliedSLAID, slaPolicyID, assignedTeamID int) (time.Time, error)
 	SetLatestSLAEventMetAt(appliedSLAID int, metric string) (time.Time, error)
+	RecalculateConversationSLAs(conversationIDs []int, systemUserID int) error
 }
 
 type statusStore interface {
@@ -130,6 +148,8 @@ type userStore interface {
 	GetSystemUser() (umodels.User, error)
 	CreateContact(user *umodels.User) error
 	UpgradeVisitorToContact(visitorID int) error
+	GetContactPreferences(contactID int) (umodels.ContactPreferences, error)
+	GetSignature(userID int) (string, error)
 }
 
 type mediaStore interface {
@@ -156,13 +176,21 @@ type settingsStore interface {
 }
 
 type csatStore interface {
-	Create(conversationID int) (csatModels.CSATResponse, error)
+	Create(conversationID int, scale string) (csatModels.CSATResponse, error)
 	Get(uuid string) (csatModels.CSATResponse, error)
-	MakePublicURL(appBaseURL, uuid string) string
+	NewToken(csat csatModels.CSATResponse) string
+	MakePublicURL(appBaseURL, token string) string
+	ContactSurveyedWithin(contactID, days int) (bool, error)
 }
 
 type webhookStore interface {
 	TriggerEvent(event wmodels.WebhookEvent, data any)
+	TriggerEventContext(ctx context.Context, event wmodels.WebhookEvent, data any)
+	Call(url string, body []byte)
+}
+
+type autoresponderStore interface {
+	Match(inboxID int, at time.Time) (armodels.Autoresponder, bool)
 }
 
 // ContinuityConfig holds configuration for conversation continuity emails
@@ -178,6 +206,10 @@ type Opts struct {
 	IncomingMessageQueueSize int
 	ContinuityConfig         *ContinuityConfig
 	SubjectRefFormat         string
+	// ReferenceLinks converts #<reference-number> mentions in private notes to links to the conversations.
+	ReferenceLinks bool
+	// How long a claimed outgoing message is reserved for this instance before others may retry it.
+	OutgoingClaimLease time.Duration
 }
 
 // New initializes a new conversation Manager.
@@ -196,6 +228,7 @@ func New(
 	automation *automation.Engine,
 	template *template.Manager,
 	webhook webhookStore,
+	autoresponder autoresponderStore,
 	dispatcher *notifier.Dispatcher,
 	opts Opts) (*Manager, error) {
 
@@ -235,6 +268,7 @@ func New(
 		settingsStore:              settingsStore,
 		csatStore:                  csatStore,
 		webhookStore:               webhook,
+		autoresponderStore:         autoresponder,
 		slaStore:                   slaStore,
 		statusStore:                statusStore,
 		priorityStore:              priorityStore,
@@ -245,8 +279,10 @@ func New(
 		incomingMessageQueue:       make(chan models.IncomingMessage, opts.IncomingMessageQueueSize),
 		outgoingMessageQueue:       make(chan models.Message, opts.OutgoingMessageQueueSize),
 		outgoingProcessingMessages: sync.Map{},
+		outgoingClaimLease:         cmp.Or(opts.OutgoingClaimLease, 5*time.Minute),
 		continuityConfig:           continuityConfig,
 		subjectRefFormat:           subjectRefFormat,
+		referenceLinks:             opts.ReferenceLinks,
 	}
 
 	return c, nil
@@ -254,43 +290,70 @@ func New(
 
 type queries struct {
 	// Conversation queries.
-	GetConversationUUID                *sqlx.Stmt `query:"get-conversation-uuid"`
-	GetConversation                    *sqlx.Stmt `query:"get-conversation"`
-	GetConversationsCreatedAfter       *sqlx.Stmt `query:"get-conversations-created-after"`
-	GetUnassignedConversations         *sqlx.Stmt `query:"get-unassigned-conversations"`
-	GetConversations                   string     `query:"get-conversations"`
-	GetContactChatConversations        *sqlx.Stmt `query:"get-contact-chat-conversations"`
-	GetChatConversation                *sqlx.Stmt `query:"get-chat-conversation"`
-	GetContactPreviousConversations    *sqlx.Stmt `query:"get-contact-previous-conversations"`
-	GetConversationParticipants        *sqlx.Stmt `query:"get-conversation-participants"`
-	GetUserActiveConversationsCount    *sqlx.Stmt `query:"get-user-active-conversations-count"`
-	UpdateConversationWaitingSince     *sqlx.Stmt `query:"update-conversation-waiting-since"`
-	UpdateConversationReplyTimestamps  *sqlx.Stmt `query:"update-conversation-reply-timestamps"`
-	UpdateConversationContactLastSeen  *sqlx.Stmt `query:"update-conversation-contact-last-seen"`
-	UpsertUserLastSeen                 *sqlx.Stmt `query:"upsert-user-last-seen"`
-	MarkConversationUnread             *sqlx.Stmt `query:"mark-conversation-unread"`
-	UpdateConversationAssignedUser     *sqlx.Stmt `query:"update-conversation-assigned-user"`
-	UpdateConversationAssignedTeam     *sqlx.Stmt `query:"update-conversation-assigned-team"`
-	UpdateConversationCustomAttributes *sqlx.Stmt `query:"update-conversation-custom-attributes"`
-	UpdateConversationPriority         *sqlx.Stmt `query:"update-conversation-priority"`
-	UpdateConversationStatus           *sqlx.Stmt `query:"update-conversation-status"`
-	UpdateConversationLastMessage      *sqlx.Stmt `query:"update-conversation-last-message"`
-	InsertConversationParticipant      *sqlx.Stmt `query:"insert-conversation-participant"`
-	InsertConversation                 *sqlx.Stmt `query:"insert-conversation"`
-	AddConversationTags                *sqlx.Stmt `query:"add-conversation-tags"`
-	SetConversationTags                *sqlx.Stmt `query:"set-conversation-tags"`
-	RemoveConversationTags             *sqlx.Stmt `query:"remove-conversation-tags"`
-	GetConversationTags                *sqlx.Stmt `query:"get-conversation-tags"`
-	UnassignOpenConversations          *sqlx.Stmt `query:"unassign-open-conversations"`
-	ReOpenConversation                 *sqlx.Stmt `query:"re-open-conversation"`
-	UnsnoozeAll                        *sqlx.Stmt `query:"unsnooze-all"`
-	DeleteConversation                 *sqlx.Stmt `query:"delete-conversation"`
-	RemoveConversationAssignee         *sqlx.Stmt `query:"remove-conversation-assignee"`
-	GetLatestMessage                   *sqlx.Stmt `query:"get-latest-message"`
+	GetConversationUUID                    *sqlx.Stmt `query:"get-conversation-uuid"`
+	GetConversationUUIDByOrderNumber       *sqlx.Stmt `query:"get-conversation-uuid-by-order-number"`
+	GetConversationUUIDsByReferenceNumbers *sqlx.Stmt `query:"get-conversation-uuids-by-reference-numbers"`
+	GetConversationStats                   *sqlx.Stmt `query:"get-conversation-stats"`
+	GetConversation                        *sqlx.Stmt `query:"get-conversation"`
+	GetTimeTriggerConversations            *sqlx.Stmt `query:"get-time-trigger-conversations"`
+	GetUnassignedConversations             *sqlx.Stmt `query:"get-unassigned-conversations"`
+	GetConversations                       string     `query:"get-conversations"`
+	GetConversationListItems               *sqlx.Stmt `query:"get-conversation-list-items"`
+	GetContactChatConversations            *sqlx.Stmt `query:"get-contact-chat-conversations"`
+	GetContactActiveChatConversation       *sqlx.Stmt `query:"get-contact-active-chat-conversation"`
+	GetChatConversation                    *sqlx.Stmt `query:"get-chat-conversation"`
+	GetContactPreviousConversations        *sqlx.Stmt `query:"get-contact-previous-conversations"`
+	GetConversationParticipants            *sqlx.Stmt `query:"get-conversation-participants"`
+	GetUserActiveConversationsCount        *sqlx.Stmt `query:"get-user-active-conversations-count"`
+	UpdateConversationWaitingSince         *sqlx.Stmt `query:"update-conversation-waiting-since"`
+	UpdateConversationReplyTimestamps      *sqlx.Stmt `query:"update-conversation-reply-timestamps"`
+	UpdateConversationContactLastSeen      *sqlx.Stmt `query:"update-conversation-contact-last-seen"`
+	UpsertUserLastSeen                     *sqlx.Stmt `query:"upsert-user-last-seen"`
+	MarkConversationUnread                 *sqlx.Stmt `query:"mark-conversation-unread"`
+	UpdateConversationSummary              *sqlx.Stmt `query:"update-conversation-summary"`
+	UpdateConversationAssignedUser         *sqlx.Stmt `query:"update-conversation-assigned-user"`
+	UpdateConversationAssignedTeam         *sqlx.Stmt `query:"update-conversation-assigned-team"`
+	UpdateConversationCustomAttributes     *sqlx.Stmt `query:"update-conversation-custom-attributes"`
+	GetConversationAttributeDefs           *sqlx.Stmt `query:"get-conversation-custom-attribute-definitions"`
+	UpdateConversationPriority             *sqlx.Stmt `query:"update-conversation-priority"`
+	UpdateConversationStatus               *sqlx.Stmt `query:"update-conversation-status"`
+	UpdateConversationLastMessage          *sqlx.Stmt `query:"update-conversation-last-message"`
+	InsertConversationParticipant          *sqlx.Stmt `query:"insert-conversation-participant"`
+	InsertConversation                     *sqlx.Stmt `query:"insert-conversation"`
+	AddConversationTags                    *sqlx.Stmt `query:"add-conversation-tags"`
+	SetConversationTags                    *sqlx.Stmt `query:"set-conversation-tags"`
+	RemoveConversationTags                 *sqlx.Stmt `query:"remove-conversation-tags"`
+	GetConversationTags                    *sqlx.Stmt `query:"get-conversation-tags"`
+	UnassignOpenConversations              *sqlx.Stmt `query:"unassign-open-conversations"`
+	ReOpenConversation                     *sqlx.Stmt `query:"re-open-conversation"`
+	UnsnoozeAll                            *sqlx.Stmt `query:"unsnooze-all"`
+	DeleteConversation                     *sqlx.Stmt `query:"delete-conversation"`
+	TrashConversation                      *sqlx.Stmt `query:"trash-conversation"`
+	RestoreConversation                    *sqlx.Stmt `query:"restore-conversation"`
+	PurgeTrashedConversations              *sqlx.Stmt `query:"purge-trashed-conversations"`
+	CreateMessagePartitions                *sqlx.Stmt `query:"create-message-partitions"`
+	GetArchivableConversations             *sqlx.Stmt `query:"get-archivable-conversations"`
+	LockConversationForArchive             *sqlx.Stmt `query:"lock-conversation-for-archive"`
+	GetArchiveMessageBodies                *sqlx.Stmt `query:"get-archive-message-bodies"`
+	InsertMessageArchive                   *sqlx.Stmt `query:"insert-message-archive"`
+	GetMessageArchives                     *sqlx.Stmt `query:"get-message-archives"`
+	RestoreMessageArchive                  *sqlx.Stmt `query:"restore-message-archive"`
+	SetConversationArchived                *sqlx.Stmt `query:"set-conversation-archived"`
+	GetArchivedConversationID              *sqlx.Stmt `query:"get-archived-conversation-id"`
+	RemoveConversationAssignee             *sqlx.Stmt `query:"remove-conversation-assignee"`
+	GetLatestMessage                       *sqlx.Stmt `query:"get-latest-message"`
+	GetRelatedConversations                *sqlx.Stmt `query:"get-related-conversations"`
 
 	// Draft queries.
 	UpsertConversationDraft *sqlx.Stmt `query:"upsert-conversation-draft"`
 	GetAllUserDrafts        *sqlx.Stmt `query:"get-all-user-drafts"`
+	GetConversationDraft    *sqlx.Stmt `query:"get-conversation-draft"`
+	GetTeamDraft            *sqlx.Stmt `query:"get-team-draft"`
+	PromoteDraft            *sqlx.Stmt `query:"promote-draft"`
+	LockTeamDraft           *sqlx.Stmt `query:"lock-team-draft"`
+	UnlockTeamDraft         *sqlx.Stmt `query:"unlock-team-draft"`
+	UpdateTeamDraft         *sqlx.Stmt `query:"update-team-draft"`
+	DeleteTeamDraft         *sqlx.Stmt `query:"delete-team-draft"`
 	DeleteConversationDraft *sqlx.Stmt `query:"delete-conversation-draft"`
 	DeleteStaleDrafts       *sqlx.Stmt `query:"delete-stale-drafts"`
 
@@ -298,14 +361,43 @@ type queries struct {
 	GetMessage                         *sqlx.Stmt `query:"get-message"`
 	GetMessages                        string     `query:"get-messages"`
 	GetOutgoingPendingMessages         *sqlx.Stmt `query:"get-outgoing-pending-messages"`
+	ReleaseOutgoingMessage             *sqlx.Stmt `query:"release-outgoing-message"`
+	ExtendOutgoingMessageClaim         *sqlx.Stmt `query:"extend-outgoing-message-claim"`
+	GetInboxOutboxDepth                *sqlx.Stmt `query:"get-inbox-outbox-depth"`
 	GetMessageSourceIDs                *sqlx.Stmt `query:"get-message-source-ids"`
 	GetConversationUUIDFromMessageUUID *sqlx.Stmt `query:"get-conversation-uuid-from-message-uuid"`
 	MessageExistsBySourceID            *sqlx.Stmt `query:"message-exists-by-source-id"`
 	GetConversationByMessageID         *sqlx.Stmt `query:"get-conversation-by-message-id"`
 	InsertMessage                      *sqlx.Stmt `query:"insert-message"`
 	UpdateMessageStatus                *sqlx.Stmt `query:"update-message-status"`
+	SetMessageReadReceipt              *sqlx.Stmt `query:"set-message-read-receipt"`
 	UpdateMessageSourceID              *sqlx.Stmt `query:"update-message-source-id"`
 	DeleteMessage                      *sqlx.Stmt `query:"delete-message"`
+	InsertMessageHeaders               *sqlx.Stmt `query:"insert-message-headers"`
+	InsertHandoffNote                  *sqlx.Stmt `query:"insert-handoff-note"`
+	InsertMessageReaction              *sqlx.Stmt `query:"insert-message-reaction"`
+	DeleteMessageReaction              *sqlx.Stmt `query:"delete-message-reaction"`
+	GetMessageReactions                *sqlx.Stmt `query:"get-message-reactions"`
+	PinMessage                         *sqlx.Stmt `query:"pin-message"`
+	UnpinMessage                       *sqlx.Stmt `query:"unpin-message"`
+	GetPinnedMessages                  *sqlx.Stmt `query:"get-pinned-messages"`
+	GetThreads                         *sqlx.Stmt `query:"get-threads"`
+	GetThread                          *sqlx.Stmt `query:"get-thread"`
+	InsertThread                       *sqlx.Stmt `query:"insert-thread"`
+	UpdateThread                       *sqlx.Stmt `query:"update-thread"`
+	TouchThread                        *sqlx.Stmt `query:"touch-thread"`
+	SetThreadParticipants              *sqlx.Stmt `query:"set-thread-participants"`
+	AddThreadParticipant               *sqlx.Stmt `query:"add-thread-participant"`
+	GetActiveHandoffNote               *sqlx.Stmt `query:"get-active-handoff-note"`
+	DeactivateHandoffNotes             *sqlx.Stmt `query:"deactivate-handoff-notes"`
+	GetMessageHeaders                  *sqlx.Stmt `query:"get-message-headers"`
+	DeleteExpiredMessageHeaders        *sqlx.Stmt `query:"delete-expired-message-headers"`
+
+	// Incoming message staging queries.
+	InsertStagedIncomingMessage *sqlx.Stmt `query:"insert-staged-incoming-message"`
+	ClaimStagedIncomingMessages *sqlx.Stmt `query:"claim-staged-incoming-messages"`
+	RetryStagedIncomingMessage  *sqlx.Stmt `query:"retry-staged-incoming-message"`
+	DeleteStagedIncomingMessage *sqlx.Stmt `query:"delete-staged-incoming-message"`
 
 	// Conversation continuity queries.
 	GetOfflineLiveChatConversations *sqlx.Stmt `query:"get-offline-livechat-conversations"`
@@ -318,6 +410,23 @@ type queries struct {
 
 	// Broadcast queries.
 	GetActiveLivechatConversationsByAgent *sqlx.Stmt `query:"get-active-livechat-conversations-by-agent"`
+
+	// Metrics recalculation queries.
+	GetConversationIDsCreatedBetween *sqlx.Stmt `query:"get-conversation-ids-created-between"`
+	CountConversationsCreatedBetween *sqlx.Stmt `query:"count-conversations-created-between"`
+	RecalculateConversationMetrics   *sqlx.Stmt `query:"recalculate-conversation-metrics"`
+
+	// Inbox address migration queries.
+	GetActiveConversationUUIDsByInbox *sqlx.Stmt `query:"get-active-conversation-uuids-by-inbox"`
+
+	// Conversation bundle import queries.
+	InsertImportedMessage      *sqlx.Stmt `query:"insert-imported-message"`
+	UpdateImportedConversation *sqlx.Stmt `query:"update-imported-conversation"`
+	InsertMissingTags          *sqlx.Stmt `query:"insert-missing-tags"`
+
+	// Message delivery queries.
+	AddMessageDeliveryEvent  *sqlx.Stmt `query:"add-message-delivery-event"`
+	GetUnconfirmedDeliveries *sqlx.Stmt `query:"get-unconfirmed-deliveries"`
 }
 
 // CreateConversation creates a new conversation. If maxConversations > 0, the insert is
@@ -401,6 +510,16 @@ func (c *Manager) GetContactPreviousConversations(contactID int, limit int) ([]m
 	return conversations, nil
 }
 
+// GetRelatedConversations returns conversations of the same contact or with a similar subject.
+func (c *Manager) GetRelatedConversations(conversationID int, limit int) ([]models.RelatedConversation, error) {
+	var conversations = make([]models.RelatedConversation, 0)
+	if err := c.q.GetRelatedConversations.Select(&conversations, conversationID, limit); err != nil {
+		c.lo.Error("error fetching related conversations", "conversation_id", conversationID, "error", err)
+		return conversations, envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return conversations, nil
+}
+
 // GetContactChatConversations retrieves chat conversations for a contact in a specific inbox.
 func (c *Manager) GetContactChatConversations(contactID, inboxID int) ([]models.ChatConversation, error) {
 	var conversations = make([]models.ChatConversation, 0)
@@ -420,6 +539,20 @@ func (c *Manager) GetContactChatConversations(contactID, inboxID int) ([]models.
 	return conversations, nil
 }
 
+// GetContactActiveChatConversationUUID returns the UUID of the most recent conversation of a
+// contact in an inbox that is not resolved or closed, or an empty string if there's none.
+func (c *Manager) GetContactActiveChatConversationUUID(contactID, inboxID int) (string, error) {
+	var uuid string
+	if err := c.q.GetContactActiveChatConversation.Get(&uuid, contactID, inboxID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		c.lo.Error("error fetching active chat conversation", "contact_id", contactID, "inbox_id", inboxID, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return uuid, nil
+}
+
 // GetChatConversation retrieves a single chat conversation by UUID
 func (c *Manager) GetChatConversation(conversationUUID string) (models.ChatConversation, error) {
 	var conversation models.ChatConversation
@@ -447,10 +580,10 @@ func (c *Manager) SignAvatarURL(avatarURL *null.String) {
 	}
 }
 
-// GetConversationsCreatedAfter retrieves conversations created after the specified time.
-func (c *Manager) GetConversationsCreatedAfter(time time.Time) ([]models.Conversation, error) {
+// GetTimeTriggerConversations retrieves conversations that aren't resolved or have had activity after the specified time.
+func (c *Manager) GetTimeTriggerConversations(time time.Time) ([]models.Conversation, error) {
 	var conversations = make([]models.Conversation, 0)
-	if err := c.q.GetConversationsCreatedAfter.Select(&conversations, time); err != nil {
+	if err := c.q.GetTimeTriggerConversations.Select(&conversations, time); err != nil {
 		c.lo.Error("error fetching conversation", "error", err)
 		return conversations, err
 	}
@@ -522,6 +655,34 @@ func (c *Manager) GetConversationUUID(id int) (string, error) {
 	return uuid, nil
 }
 
+// GetConversationUUIDByOrderNumber retrieves the UUID of the latest conversation, that isn't trashed, whose
+// `order_number` custom attribute is orderNumber. The attribute key is fixed as it is indexed.
+func (c *Manager) GetConversationUUIDByOrderNumber(orderNumber string) (string, error) {
+	var uuid string
+	if err := c.q.GetConversationUUIDByOrderNumber.QueryRow(orderNumber).Scan(&uuid); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid, envelope.NewError(envelope.NotFoundError, c.i18n.T("validation.notFoundConversation"), nil)
+		}
+		c.lo.Error("error fetching conversation by order number", "order_number", orderNumber, "error", err)
+		return uuid, envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return uuid, nil
+}
+
+// GetConversationStats returns computed metrics for a conversation: message counts by type,
+// response times, reopen count and time spent in each status.
+func (c *Manager) GetConversationStats(uuid string) (json.RawMessage, error) {
+	var stats json.RawMessage
+	if err := c.q.GetConversationStats.Get(&stats, uuid); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, envelope.NewError(envelope.NotFoundError, c.i18n.T("validation.notFoundConversation"), nil)
+		}
+		c.lo.Error("error fetching conversation stats", "uuid", uuid, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
 // GetAllConversationsList retrieves all conversations with optional filtering, ordering, and pagination.
 func (c *Manager) GetAllConversationsList(viewingUserID int, order, orderBy, filters string, page, pageSize int) ([]models.ConversationListItem, error) {
 	return c.GetConversations(viewingUserID, 0, []int{}, []string{models.AllConversations}, order, orderBy, filters, page, pageSize)
@@ -572,6 +733,27 @@ func (c *Manager) GetViewConversationsList(viewingUserID, userID int, teamIDs []
 	return c.GetConversations(viewingUserID, userID, teamIDs, listType, order, orderBy, filters, page, pageSize)
 }
 
+// ConversationInViewList returns true if a conversation is listed in a view with the given filters,
+// matched with the same list query as GetViewConversationsList.
+func (c *Manager) ConversationInViewList(viewingUserID, userID int, teamIDs []int, listTypes []string, filters, conversationUUID string) (bool, error) {
+	if _, err := uuid.Parse(conversationUUID); err != nil {
+		return false, nil
+	}
+	baseQuery := strings.Replace(c.q.GetConversations, "WHERE 1=1", "WHERE conversations.uuid = "+pq.QuoteLiteral(conversationUUID), 1)
+	query, qArgs, err := c.makeConversationsListQuery(viewingUserID, userID, teamIDs, listTypes, baseQuery, "", "", 1, 1, filters)
+	if err != nil {
+		return false, err
+	}
+	var rows []struct {
+		Total int `db:"total"`
+		ID    int `db:"id"`
+	}
+	if err := c.db.Select(&rows, query, qArgs...); err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
 // GetConversations retrieves conversations list based on user ID, type, and optional filtering, ordering, and pagination.
 // viewingUserID is used to calculate per-agent unread counts.
 func (c *Manager) GetConversations(viewingUserID, userID int, teamIDs []int, listTypes []string, order, orderBy, filters string, page, pageSize int) ([]models.ConversationListItem, error) {
@@ -593,10 +775,32 @@ func (c *Manager) GetConversations(viewingUserID, userID int, teamIDs []int, lis
 		return conversations, envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
 
-	if err := tx.Select(&conversations, query, qArgs...); err != nil {
+	// The page is selected first so the per conversation columns, e.g. unread counts and SLA deadlines,
+	// are fetched in one batch for the listed conversations only instead of for every matching conversation.
+	var pageRows []struct {
+		Total int `db:"total"`
+		ID    int `db:"id"`
+	}
+	if err := tx.Select(&pageRows, query, qArgs...); err != nil {
 		c.lo.Error("error fetching conversations", "error", err)
 		return conversations, envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
+	if len(pageRows) == 0 {
+		return conversations, nil
+	}
+	ids := make([]int, len(pageRows))
+	for i, row := range pageRows {
+		ids[i] = row.ID
+	}
+
+	includeMentions := slices.Contains(listTypes, models.MentionedConversations)
+	if err := tx.Stmtx(c.q.GetConversationListItems).Select(&conversations, viewingUserID, includeMentions, pq.Array(ids)); err != nil {
+		c.lo.Error("error fetching conversation list items", "error", err)
+		return conversations, envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	for i := range conversations {
+		conversations[i].Total = pageRows[0].Total
+	}
 	return conversations, nil
 }
 
@@ -661,6 +865,16 @@ func (c *Manager) UpdateConversationWaitingSince(conversationUUID string, at *ti
 	return nil
 }
 
+// UpdateConversationSummary updates the agent maintained summary of a conversation.
+func (c *Manager) UpdateConversationSummary(uuid, summary string) error {
+	if _, err := c.q.UpdateConversationSummary.Exec(uuid, summary); err != nil {
+		c.lo.Error("error updating conversation summary", "uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.errorUpdatingConversation"), nil)
+	}
+	c.BroadcastConversationUpdate(uuid, map[string]any{"summary": summary})
+	return nil
+}
+
 // UpdateConversationUserAssignee sets the assignee of a conversation to a specifc user.
 func (c *Manager) UpdateConversationUserAssignee(uuid string, assigneeID int, actor umodels.User) error {
 	if err := c.UpdateAssignee(uuid, assigneeID, models.AssigneeTypeUser); err != nil {
@@ -673,6 +887,13 @@ func (c *Manager) UpdateConversationUserAssignee(uuid string, assigneeID int, ac
 		return err
 	}
 
+	// Mark the assignment SLA event as met, the conversation has left the queue.
+	if conversation.AppliedSLAID.Valid {
+		if _, err := c.slaStore.SetLatestSLAEventMetAt(conversation.AppliedSLAID.Int, sla.MetricAssignment); err != nil && !errors.Is(err, sla.ErrLatestSLAEventNotFound) {
+			c.lo.Error("error setting assignment SLA event `met_at`", "conversation_id", conversation.ID, "applied_sla_id", conversation.AppliedSLAID.Int, "error", err)
+		}
+	}
+
 	c.webhookStore.TriggerEvent(wmodels.EventConversationAssigned, map[string]any{
 		"conversation_uuid": uuid,
 		"assigned_to":       assigneeID,
@@ -733,7 +954,7 @@ func (c *Manager) UpdateConversationTeamAssignee(uuid string, teamID int, actor
 	// Team changed?
 	if previousAssignedTeamID != teamID {
 		// Remove assigned user if team has changed.
-		c.RemoveConversationAssignee(uuid, models.AssigneeTypeUser, actor)
+		c.removeConversationAssignee(uuid, models.AssigneeTypeUser, actor)
 
 		// Apply SLA policy if this new team has a SLA policy.
 		team, err := c.teamStore.Get(teamID)
@@ -753,6 +974,9 @@ func (c *Manager) UpdateConversationTeamAssignee(uuid string, teamID int, actor
 			if err := c.ApplySLA(conversation, team.SLAPolicyID.Int, systemUser); err != nil {
 				return nil
 			}
+		} else {
+			// The conversation waits unassigned in the new team's queue under the SLA already applied.
+			c.startAssignmentSLA(conversation)
 		}
 
 		// Evaluate automation rules for conversation team assignment.
@@ -777,6 +1001,10 @@ func (c *Manager) UpdateAssignee(uuid string, assigneeID int, assigneeType strin
 			c.lo.Error("error updating conversation assignee", "error", err)
 			return fmt.Errorf("updating assignee: %w", err)
 		}
+		// Handoff notes left for a previous assignee no longer apply.
+		if _, err := c.q.DeactivateHandoffNotes.Exec(uuid, assigneeID); err != nil {
+			c.lo.Error("error deactivating handoff notes", "conversation_uuid", uuid, "error", err)
+		}
 	case models.AssigneeTypeTeam:
 		prop = "assigned_team_id"
 		if _, err := c.q.UpdateConversationAssignedTeam.Exec(uuid, assigneeID); err != nil {
@@ -906,6 +1134,11 @@ func (c *Manager) UpdateConversationStatus(uuid string, statusID int, status, sn
 		c.automation.EvaluateConversationUpdateRules(conversation, amodels.EventConversationStatusChange)
 	}
 
+	// Send the CSAT survey of the inbox on resolve.
+	if oldStatus != models.StatusResolved && status == models.StatusResolved && conversation.ID != 0 {
+		c.sendCSATOnResolve(conversation, actor)
+	}
+
 	// Broadcast conversation update to widget clients.
 	c.BroadcastConversationToWidget(uuid, conversationBeforeChange.ContactID, conversationBeforeChange.InboxID, map[string]any{
 		"status": status,
@@ -1033,12 +1266,30 @@ func (m *Manager) NotifyAssignment(userIDs []int, conversation models.Conversati
 		return fmt.Errorf("fetching agent: %w", err)
 	}
 
+	// Include the handoff note left for the assignee, if any.
+	handoff := map[string]any{
+		"FromName":     "",
+		"Summary":      "",
+		"CurrentState": "",
+		"NextStep":     "",
+	}
+	if note, err := m.GetActiveHandoffNote(conversation.ID); err != nil {
+		m.lo.Error("error fetching handoff note for assignment notification", "conversation_uuid", conversation.UUID, "error", err)
+	} else if note != nil && note.ToUserID == agent.ID {
+		handoff["FromName"] = note.FromUserName
+		handoff["Summary"] = note.Summary
+		handoff["CurrentState"] = note.CurrentState
+		handoff["NextStep"] = note.NextStep
+	}
+
 	// Render email template.
 	content, subject, err := m.template.RenderStoredEmailTemplate(template.TmplConversationAssigned,
 		map[string]any{
+			"Handoff": handoff,
 			"Conversation": map[string]any{
 				"ReferenceNumber": conversation.ReferenceNumber,
 				"Subject":         conversation.Subject.String,
+				"Summary":         conversation.Summary,
 				"Priority":        conversation.Priority.String,
 				"UUID":            conversation.UUID,
 			},
@@ -1145,6 +1396,7 @@ func (m *Manager) NotifyMention(conversationUUID string, message models.Message,
 					"Conversation": map[string]any{
 						"ReferenceNumber": conversation.ReferenceNumber,
 						"Subject":         conversation.Subject.String,
+						"Summary":         conversation.Summary,
 						"Priority":        conversation.Priority.String,
 						"UUID":            conversation.UUID,
 					},
@@ -1232,8 +1484,8 @@ func (m *Manager) ApplySLA(conversation models.Conversation, policyID int, actor
 // ApplyAction applies an action to a conversation, this can be called from multiple packages across the app to perform actions on conversations.
 // all actions are executed on behalf of the provided user if the user is not provided, system user is used.
 func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversation, user umodels.User) error {
-	// CSAT action does not require a value.
-	if len(action.Value) == 0 && action.Type != amodels.ActionSendCSAT {
+	// CSAT and transcript actions do not require a value.
+	if len(action.Value) == 0 && action.Type != amodels.ActionSendCSAT && action.Type != amodels.ActionSendTranscript {
 		return fmt.Errorf("empty value for action %s", action.Type)
 	}
 
@@ -1245,6 +1497,17 @@ func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversatio
 		}
 	}
 
+	// Actions on behalf of an agent need the agent to hold the action's permission, actions without one are rejected.
+	if !user.IsSystemUser() {
+		perm, ok := amodels.ActionPermissions[action.Type]
+		if !ok {
+			return fmt.Errorf("action %s is not permitted for user %d", action.Type, user.ID)
+		}
+		if !slices.Contains(user.Permissions, perm) {
+			return fmt.Errorf("user %d lacks permission %s for action %s", user.ID, perm, action.Type)
+		}
+	}
+
 	m.lo.Debug("executing action",
 		"type", action.Type,
 		"value", action.Value,
@@ -1278,13 +1541,18 @@ func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversatio
 		}
 		return m.UpdateConversationStatus(conv.UUID, statusID, "", "", user)
 	case amodels.ActionSendPrivateNote:
-		_, err := m.SendPrivateNote([]mmodels.Media{}, user.ID, conv.UUID, action.Value[0], nil)
+		_, err := m.SendPrivateNote([]mmodels.Media{}, user.ID, conv.UUID, action.Value[0], nil, 0)
 		if err != nil {
 			return fmt.Errorf("sending private note: %w", err)
 		}
 	case amodels.ActionReply:
+		// Replies sent by the system user are automated, skip them for contacts who opted out.
+		if user.IsSystemUser() && !m.contactAllows(conv.ContactID, umodels.ContactPreferences.AllowsAutomated) {
+			m.lo.Info("contact opted out of automated messages, skipping reply action", "conversation_uuid", conv.UUID, "contact_id", conv.ContactID)
+			return nil
+		}
 		// Make recipient list.
-		to, cc, bcc, err := m.makeRecipients(conv.ID, conv.Contact.Email.String, conv.InboxMail, conv.InboxReplyTo)
+		to, cc, bcc, err := m.makeRecipients(conv)
 		if err != nil {
 			return fmt.Errorf("making recipients for reply action: %w", err)
 		}
@@ -1313,14 +1581,156 @@ func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversatio
 		return m.SetConversationTags(conv.UUID, action.Type, action.Value, user)
 	case amodels.ActionSendCSAT:
 		return m.SendCSATReply(user.ID, conv)
+	case amodels.ActionSendTranscript:
+		return m.SendTranscript(conv.UUID, user)
+	case amodels.ActionCallWebhook:
+		return m.callWebhook(conv, user, action.Value)
+	case amodels.ActionSetAttribute:
+		return m.setCustomAttribute(conv, action.Value)
 	default:
 		return fmt.Errorf("unknown action: %s", action.Type)
 	}
 	return nil
 }
 
-// RemoveConversationAssignee removes assigned user from a conversation.
+// setCustomAttribute sets a conversation custom attribute to the value of a set custom attribute action,
+// converted to the attribute's data type. An empty value unsets the attribute.
+func (m *Manager) setCustomAttribute(conv models.Conversation, value []string) error {
+	if len(value) < 2 || value[0] == "" {
+		return fmt.Errorf("invalid set custom attribute value %v", value)
+	}
+	var defs []camodels.CustomAttribute
+	if err := m.q.GetConversationAttributeDefs.Select(&defs); err != nil {
+		return fmt.Errorf("fetching conversation custom attribute definitions: %w", err)
+	}
+	idx := slices.IndexFunc(defs, func(d camodels.CustomAttribute) bool { return d.Key == value[0] })
+	if idx < 0 {
+		return fmt.Errorf("unknown conversation custom attribute %q", value[0])
+	}
+
+	var (
+		raw = strings.TrimSpace(value[1])
+		val any
+	)
+	switch {
+	case raw == "":
+		val = nil
+	case defs[idx].DataType == customAttribute.DataTypeNumber:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q for custom attribute %q", raw, value[0])
+		}
+		val = f
+	case defs[idx].DataType == customAttribute.DataTypeCheckbox:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid checkbox value %q for custom attribute %q", raw, value[0])
+		}
+		val = b
+	default:
+		val = raw
+	}
+
+	attrs := map[string]any{}
+	if len(conv.CustomAttributes) > 0 {
+		if err := json.Unmarshal(conv.CustomAttributes, &attrs); err != nil {
+			return fmt.Errorf("unmarshalling conversation custom attributes: %w", err)
+		}
+	}
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	if val == nil {
+		delete(attrs, value[0])
+	} else {
+		attrs[value[0]] = val
+	}
+	return m.UpdateConversationCustomAttributes(conv.UUID, attrs)
+}
+
+// ValidateAction checks the value of an action when a rule is saved, so that it doesn't only fail when the rule runs.
+func (m *Manager) ValidateAction(action amodels.RuleAction) error {
+	if action.Type != amodels.ActionCallWebhook {
+		return nil
+	}
+	if len(action.Value) == 0 {
+		return fmt.Errorf("empty value for action %s", action.Type)
+	}
+	if _, err := parseWebhookURL(action.Value[0]); err != nil {
+		return err
+	}
+	if len(action.Value) > 1 && strings.TrimSpace(action.Value[1]) != "" {
+		if err := m.template.ValidateString(action.Value[1]); err != nil {
+			return fmt.Errorf("invalid webhook body: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseWebhookURL parses the URL of a call webhook action, which must be an absolute HTTP(S) URL.
+func parseWebhookURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q", raw)
+	}
+	return u, nil
+}
+
+// callWebhook queues a POST to the URL of a call webhook action. The optional body template is rendered
+// with the conversation template data and must be JSON, the conversation is posted if there's no body.
+func (m *Manager) callWebhook(conv models.Conversation, user umodels.User, value []string) error {
+	u, err := parseWebhookURL(value[0])
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if len(value) > 1 && strings.TrimSpace(value[1]) != "" {
+		data, err := m.BuildTemplateData(conv.UUID, user.ID)
+		if err != nil {
+			return fmt.Errorf("building webhook body template data: %w", err)
+		}
+		body = []byte(m.template.RenderString(data, value[1]))
+		if !json.Valid(body) {
+			return fmt.Errorf("webhook body is not valid JSON")
+		}
+	} else {
+		if body, err = json.Marshal(map[string]any{"conversation": conv}); err != nil {
+			return fmt.Errorf("marshalling webhook body: %w", err)
+		}
+	}
+
+	m.webhookStore.Call(u.String(), body)
+	return nil
+}
+
+// RemoveConversationAssignee removes the user or team assignee from a conversation.
 func (m *Manager) RemoveConversationAssignee(uuid, typ string, actor umodels.User) error {
+	if err := m.removeConversationAssignee(uuid, typ, actor); err != nil {
+		return err
+	}
+
+	// The conversation is back in the queue once the user is removed.
+	if typ == models.AssigneeTypeUser {
+		if conversation, err := m.GetConversation(0, uuid, ""); err == nil {
+			m.startAssignmentSLA(conversation)
+		}
+	}
+	return nil
+}
+
+// startAssignmentSLA starts the assignment SLA clock of an unassigned conversation if its applied SLA has an assignment time.
+func (m *Manager) startAssignmentSLA(conversation models.Conversation) {
+	if !conversation.AppliedSLAID.Valid || conversation.AssignedUserID.Valid {
+		return
+	}
+	if _, err := m.slaStore.CreateAssignmentSLAEvent(conversation.ID, conversation.AppliedSLAID.Int, conversation.SLAPolicyID.Int, conversation.AssignedTeamID.Int); err != nil && !errors.Is(err, sla.ErrUnmetSLAEventAlreadyExists) {
+		m.lo.Error("error creating assignment SLA event", "conversation_id", conversation.ID, "error", err)
+	}
+}
+
+// removeConversationAssignee removes the user or team assignee of a conversation.
+func (m *Manager) removeConversationAssignee(uuid, typ string, actor umodels.User) error {
 	if _, err := m.q.RemoveConversationAssignee.Exec(uuid, typ); err != nil {
 		m.lo.Error("error removing conversation assignee", "error", err)
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.errorUpdatingConversation"), nil)
@@ -1359,7 +1769,17 @@ func (m *Manager) RemoveConversationAssignee(uuid, typ string, actor umodels.Use
 
 // SendCSATReply sends a CSAT reply message to a conversation. No-op if one was already sent.
 func (m *Manager) SendCSATReply(actorUserID int, conversation models.Conversation) error {
-	csatResp, err := m.csatStore.Create(conversation.ID)
+	if !m.contactAllows(conversation.ContactID, umodels.ContactPreferences.AllowsSurveys) {
+		m.lo.Info("contact opted out of surveys, skipping CSAT", "conversation_uuid", conversation.UUID, "contact_id", conversation.ContactID)
+		return nil
+	}
+
+	inbox, err := m.inboxStore.GetDBRecord(conversation.InboxID)
+	if err != nil {
+		m.lo.Error("error fetching inbox for CSAT", "inbox_id", conversation.InboxID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	csatResp, err := m.csatStore.Create(conversation.ID, inbox.CSATConfig.Scale)
 	if err != nil {
 		if errors.Is(err, csat.ErrCSATAlreadyExists) {
 			return nil
@@ -1370,7 +1790,8 @@ func (m *Manager) SendCSATReply(actorUserID int, conversation models.Conversatio
 	if err != nil {
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
-	csatPublicURL := m.csatStore.MakePublicURL(appRootURL, csatResp.UUID)
+	csatToken := m.csatStore.NewToken(csatResp)
+	csatPublicURL := m.csatStore.MakePublicURL(appRootURL, csatToken)
 
 	// Render CSAT email template.
 	data, err := m.BuildTemplateData(conversation.UUID, actorUserID)
@@ -1380,20 +1801,33 @@ func (m *Manager) SendCSATReply(actorUserID int, conversation models.Conversatio
 	}
 	data["CSATLink"] = csatPublicURL
 	data["CSATUUID"] = csatResp.UUID
-	message, err := m.template.RenderStoredTemplate(template.TmplCSATRequest, data)
-	if err != nil {
-		m.lo.Error("error rendering CSAT template", "conversation_uuid", conversation.UUID, "error", err)
-		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	data["CSATScale"] = csatResp.Scale
+	data["CSATQuestion"] = inbox.CSATConfig.Question
+	data["CSATRatings"] = m.csatRatingLinks(csatResp.Scale, csatPublicURL)
+	var message string
+	variant, hasVariant := m.pickTemplateVariant(template.TmplCSATRequest)
+	if hasVariant {
+		message = m.template.RenderString(data, variant.Content)
+	} else {
+		message, err = m.template.RenderStoredTemplate(template.TmplCSATRequest, data)
+		if err != nil {
+			m.lo.Error("error rendering CSAT template", "conversation_uuid", conversation.UUID, "error", err)
+			return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
 	}
 
 	// Store `is_csat` meta to identify and filter CSAT public url from the message.
 	meta := map[string]interface{}{
-		"is_csat":   true,
-		"csat_uuid": csatResp.UUID,
+		"is_csat":    true,
+		"csat_uuid":  csatResp.UUID,
+		"csat_token": csatToken,
+	}
+	if hasVariant {
+		meta["variant_id"] = variant.ID
 	}
 
 	// Make recipient list.
-	to, cc, bcc, err := m.makeRecipients(conversation.ID, conversation.Contact.Email.String, conversation.InboxMail, conversation.InboxReplyTo)
+	to, cc, bcc, err := m.makeRecipients(conversation)
 	if err != nil {
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -1404,9 +1838,84 @@ func (m *Manager) SendCSATReply(actorUserID int, conversation models.Conversatio
 		m.lo.Error("error sending CSAT reply", "conversation_uuid", conversation.UUID, "error", err)
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
+	if hasVariant {
+		m.variantStore.RecordSend(variant.ID, conversation.ID, null.IntFrom(csatResp.ID))
+	}
 	return nil
 }
 
+// sendCSATOnResolve sends the CSAT survey to a resolved conversation if the inbox has CSAT enabled, unless the
+// conversation has one of the inbox's excluded tags or the contact was sent a survey within the throttle period.
+func (m *Manager) sendCSATOnResolve(conversation models.Conversation, actor umodels.User) {
+	inbox, err := m.inboxStore.GetDBRecord(conversation.InboxID)
+	if err != nil {
+		m.lo.Error("error fetching inbox for CSAT on resolve", "inbox_id", conversation.InboxID, "error", err)
+		return
+	}
+	if !inbox.CSATEnabled {
+		return
+	}
+
+	cfg := inbox.CSATConfig
+	if len(cfg.ExcludeTags) > 0 {
+		tags, err := m.getConversationTags(conversation.UUID)
+		if err != nil {
+			return
+		}
+		for _, tag := range tags {
+			if slices.Contains(cfg.ExcludeTags, tag) {
+				m.lo.Info("conversation has CSAT exclusion tag, skipping CSAT", "conversation_uuid", conversation.UUID, "tag", tag)
+				return
+			}
+		}
+	}
+	if cfg.ThrottleDays > 0 {
+		surveyed, err := m.csatStore.ContactSurveyedWithin(conversation.ContactID, cfg.ThrottleDays)
+		if err != nil {
+			return
+		}
+		if surveyed {
+			m.lo.Info("contact was surveyed recently, skipping CSAT", "conversation_uuid", conversation.UUID, "contact_id", conversation.ContactID, "throttle_days", cfg.ThrottleDays)
+			return
+		}
+	}
+
+	if err := m.SendCSATReply(actor.ID, conversation); err != nil {
+		m.lo.Error("error sending CSAT on resolve", "conversation_uuid", conversation.UUID, "error", err)
+	}
+}
+
+// csatRatingLinks returns the ratings of a scale with links to the survey with the rating picked,
+// for CSAT email templates to render one link per rating.
+func (m *Manager) csatRatingLinks(scale, surveyURL string) []map[string]any {
+	opts := csatModels.RatingOptions(scale)
+	links := make([]map[string]any, 0, len(opts))
+	for _, o := range opts {
+		label := strconv.Itoa(o.Value)
+		if o.LabelKey != "" {
+			label = m.i18n.T(o.LabelKey)
+		}
+		links = append(links, map[string]any{
+			"Value": o.Value,
+			"Emoji": o.Emoji,
+			"Label": label,
+			"Link":  surveyURL + "?rating=" + strconv.Itoa(o.Value),
+		})
+	}
+	return links
+}
+
+// contactAllows checks the communication preferences of a contact with the given check.
+// Messages are not sent if the preferences cannot be fetched.
+func (m *Manager) contactAllows(contactID int, check func(umodels.ContactPreferences) bool) bool {
+	prefs, err := m.userStore.GetContactPreferences(contactID)
+	if err != nil {
+		m.lo.Error("error fetching contact preferences", "contact_id", contactID, "error", err)
+		return false
+	}
+	return check(prefs)
+}
+
 // DeleteConversation deletes a conversation.
 func (m *Manager) DeleteConversation(uuid string) error {
 	m.lo.Info("deleting conversation", "uuid", uuid)
@@ -1417,8 +1926,22 @@ func (m *Manager) DeleteConversation(uuid string) error {
 	return nil
 }
 
-// UpdateConversationCustomAttributes updates the custom attributes of a conversation.
+// UpdateConversationCustomAttributes updates the custom attributes of a conversation
+// after validating them against the conversation custom attribute definitions.
 func (c *Manager) UpdateConversationCustomAttributes(uuid string, customAttributes map[string]any) error {
+	var defs []camodels.CustomAttribute
+	if err := c.q.GetConversationAttributeDefs.Select(&defs); err != nil {
+		c.lo.Error("error fetching conversation custom attribute definitions", "error", err)
+		return envelope.NewError(envelope.GeneralError, c.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	var verr *customAttribute.ValidationError
+	if err := customAttribute.Validate(defs, customAttributes); errors.As(err, &verr) {
+		if verr.Missing {
+			return envelope.NewError(envelope.InputError, c.i18n.Ts("globals.messages.required", "name", verr.Name), nil)
+		}
+		return envelope.NewError(envelope.InputError, c.i18n.Ts("validation.invalidCustomAttributeValue", "name", verr.Name), nil)
+	}
+
 	jsonb, err := json.Marshal(customAttributes)
 	if err != nil {
 		c.lo.Error("error marshalling custom attributes", "error", err)
@@ -1477,12 +2000,10 @@ func (c *Manager) getConversationTags(uuid string) ([]string, error) {
 	return tags, nil
 }
 
-// makeConversationsListQuery prepares a SQL query string for conversations list
-// viewingUserID is used as $1 for per-agent unread count calculation
-// $2 is includeMentions bool for conditional mentioned_message_uuid column
+// makeConversationsListQuery prepares a SQL query string selecting a page of the conversations list.
+// viewingUserID is used to match the mentions of the mentioned list.
 func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs []int, listTypes []string, baseQuery, order, orderBy string, page, pageSize int, filtersJSON string) (string, []interface{}, error) {
-	includeMentions := slices.Contains(listTypes, models.MentionedConversations)
-	qArgs := []any{viewingUserID, includeMentions}
+	qArgs := []any{}
 
 	// Set defaults
 	if orderBy == "" {
@@ -1504,11 +2025,13 @@ func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs
 		return "", nil, fmt.Errorf("no conversation list types specified")
 	}
 
-	// Parse filters to extract tag filters
+	// Parse filters to extract tag and custom attribute filters
 	var (
 		filters          []dbutil.Filter
 		tagFilters       []dbutil.Filter
+		computedFilters  []dbutil.Filter
 		remainingFilters []dbutil.Filter
+		allowedAttrs     dbutil.AllowedAttributes
 	)
 	if filtersJSON != "" && filtersJSON != "[]" {
 		if err := json.Unmarshal([]byte(filtersJSON), &filters); err != nil {
@@ -1519,6 +2042,24 @@ func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs
 		for _, f := range filters {
 			if f.Field == "tags" && (f.Operator == "contains" || f.Operator == "not contains" || f.Operator == "set" || f.Operator == "not set") {
 				tagFilters = append(tagFilters, f)
+			} else if isComputedFilter(f) {
+				computedFilters = append(computedFilters, f)
+			} else if f.Model == customAttributeFilterModel {
+				// Custom attribute filters are keys of the custom_attributes JSONB column.
+				if allowedAttrs == nil {
+					keys, err := c.conversationAttributeKeys()
+					if err != nil {
+						return "", nil, err
+					}
+					allowedAttrs = dbutil.AllowedAttributes{"conversations.custom_attributes": keys}
+				}
+				remainingFilters = append(remainingFilters, dbutil.Filter{
+					Model:     "conversations",
+					Field:     "custom_attributes",
+					Attribute: f.Field,
+					Operator:  f.Operator,
+					Value:     f.Value,
+				})
 			} else {
 				remainingFilters = append(remainingFilters, f)
 			}
@@ -1564,15 +2105,18 @@ func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs
 			// No conditions needed for all conversations.
 		case models.MentionedConversations:
 			// Filter to only conversations where user is mentioned (directly or via team)
-			conditions = append(conditions, `conversations.id IN (
+			conditions = append(conditions, fmt.Sprintf(`conversations.id IN (
 				SELECT cm.conversation_id
 				FROM conversation_mentions cm
-				WHERE cm.mentioned_user_id = $1
+				WHERE cm.mentioned_user_id = $%[1]d
 				   OR EXISTS(
 					   SELECT 1 FROM team_members tm
-					   WHERE tm.team_id = cm.mentioned_team_id AND tm.user_id = $1
+					   WHERE tm.team_id = cm.mentioned_team_id AND tm.user_id = $%[1]d
 				   )
-			)`)
+			)`, len(qArgs)+1))
+			qArgs = append(qArgs, viewingUserID)
+		case models.TrashedConversations:
+			// Trashed conversations are filtered below.
 		default:
 			return "", nil, fmt.Errorf("unknown conversation type: %s", lt)
 		}
@@ -1584,6 +2128,13 @@ func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs
 		whereClause = "AND (" + strings.Join(conditions, " OR ") + ")"
 	}
 
+	// Trashed conversations are only listed in the trash.
+	if slices.Contains(listTypes, models.TrashedConversations) {
+		whereClause += " AND conversations.trashed_at IS NOT NULL"
+	} else {
+		whereClause += " AND conversations.trashed_at IS NULL"
+	}
+
 	// Add tag filter conditions
 	// TODO: Evaluate - https://github.com/Masterminds/squirrel when required.
 	for _, tf := range tagFilters {
@@ -1630,9 +2181,19 @@ func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs
 		}
 	}
 
+	// Add computed field conditions, e.g. awaiting_agent.
+	for _, cf := range computedFilters {
+		cond, condArgs, err := computedFilterCondition(cf, len(qArgs)+1)
+		if err != nil {
+			return "", nil, err
+		}
+		whereClause += " AND " + cond
+		qArgs = append(qArgs, condArgs...)
+	}
+
 	baseQuery = fmt.Sprintf(baseQuery, whereClause)
 
-	return dbutil.BuildPaginatedQuery(baseQuery, qArgs, dbutil.PaginationOptions{
+	return dbutil.BuildPaginatedQueryWithAttributes(baseQuery, qArgs, dbutil.PaginationOptions{
 		Order:    order,
 		OrderBy:  orderBy,
 		Page:     page,
@@ -1641,7 +2202,20 @@ func (c *Manager) makeConversationsListQuery(viewingUserID, userID int, teamIDs
 		"conversations":         conversationsAllowedFields,
 		"conversation_statuses": conversationStatusAllowedFields,
 		"users":                 usersAllowedFields,
-	})
+	}, allowedAttrs)
+}
+
+// conversationAttributeKeys returns the keys of the conversation custom attribute definitions.
+func (c *Manager) conversationAttributeKeys() ([]string, error) {
+	var defs []camodels.CustomAttribute
+	if err := c.q.GetConversationAttributeDefs.Select(&defs); err != nil {
+		return nil, fmt.Errorf("fetching conversation custom attribute definitions: %w", err)
+	}
+	keys := make([]string, 0, len(defs))
+	for _, d := range defs {
+		keys = append(keys, d.Key)
+	}
+	return keys, nil
 }
 
 // ProcessCSATStatus processes messages and adds CSAT submission status for CSAT messages.
@@ -1655,10 +2229,14 @@ func (m *Manager) ProcessCSATStatus(messages []models.Message) {
 
 		var (
 			isSubmitted bool
-			rating      int
+			rating      null.Int
+			scale       = csatModels.ScaleStars
 			feedback    string
 		)
 		csat, err := m.csatStore.Get(csatUUID)
+		if err == nil {
+			scale = csat.Scale
+		}
 		if err == nil && csat.ResponseTimestamp.Valid {
 			isSubmitted = true
 			rating = csat.Rating
@@ -1666,7 +2244,7 @@ func (m *Manager) ProcessCSATStatus(messages []models.Message) {
 				feedback = csat.Feedback.String
 			}
 		}
-		msg.CensorCSATContentWithStatus(isSubmitted, csatUUID, rating, feedback)
+		msg.CensorCSATContentWithStatus(isSubmitted, csatUUID, scale, rating, feedback)
 	}
 }
 
@@ -1731,7 +2309,7 @@ func (m *Manager) BuildWidgetConversationResponse(conversation models.Conversati
 	if includeMessages {
 		private := false
 		// Fetch last 400 messages.
-		messages, _, err := m.GetConversationMessages(conversation.UUID, 1, 400, &private, []string{models.MessageIncoming, models.MessageOutgoing})
+		messages, _, err := m.GetConversationMessages(conversation.UUID, 1, 400, &private, []string{models.MessageIncoming, models.MessageOutgoing}, 0)
 		if err != nil {
 			m.lo.Error("error fetching conversation messages", "conversation_uuid", conversation.UUID, "error", err)
 			return resp, envelope.NewError(envelope.GeneralError, "Error fetching messages", nil)
@@ -0,0 +1,126 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+)
+
+// Delivery states of outgoing emails, recorded in the delivery timeline in the message meta.
+const (
+	// DeliveryAccepted is set when the mail server accepted the message.
+	DeliveryAccepted = "accepted"
+	// DeliveryDelayed is set when a delivery report says delivery is delayed but still being retried.
+	DeliveryDelayed = "delayed"
+	// DeliveryDelivered is set when a delivery report confirms delivery, or when no bounce arrived in time.
+	DeliveryDelivered = "delivered"
+	// DeliveryBounced is set when a delivery report says the message could not be delivered.
+	DeliveryBounced = "bounced"
+	// DeliveryOpened is set when the open tracking pixel of the message was loaded.
+	DeliveryOpened = "opened"
+)
+
+// maxDeliveryConfirmations is the number of messages confirmed as delivered in one run.
+const maxDeliveryConfirmations = 1000
+
+// addDeliveryEvent appends an event to the delivery timeline of the outgoing message with the given UUID
+// or source ID and broadcasts the updated meta. Events for unknown or bounced messages are ignored.
+func (m *Manager) addDeliveryEvent(messageUUID, sourceID, state, detail string) error {
+	var res struct {
+		UUID             string          `db:"uuid"`
+		ConversationUUID string          `db:"conversation_uuid"`
+		Meta             json.RawMessage `db:"meta"`
+	}
+	if err := m.q.AddMessageDeliveryEvent.Get(&res, messageUUID, state, detail, sourceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		m.lo.Error("error recording message delivery event", "message_uuid", messageUUID, "source_id", sourceID, "state", state, "error", err)
+		return err
+	}
+	m.BroadcastMessageUpdate(res.ConversationUUID, res.UUID, map[string]any{"meta": res.Meta})
+	return nil
+}
+
+// RecordDeliveryReport records a delivery status notification (DSN), e.g. a bounce, on the outgoing
+// message with the given source ID.
+func (m *Manager) RecordDeliveryReport(sourceID, action, status, diagnostic, recipient string) error {
+	var state string
+	switch action {
+	case "failed":
+		state = DeliveryBounced
+	case "delayed":
+		state = DeliveryDelayed
+	case "delivered", "relayed", "expanded":
+		state = DeliveryDelivered
+	default:
+		return nil
+	}
+	detail := strings.TrimSpace(strings.Join([]string{recipient, status, diagnostic}, " "))
+	return m.addDeliveryEvent("", sourceID, state, detail)
+}
+
+// RecordMessageOpen records the first open of an outgoing message reported by its tracking pixel.
+func (m *Manager) RecordMessageOpen(messageUUID string) error {
+	return m.addDeliveryEvent(messageUUID, "", DeliveryOpened, "")
+}
+
+// RunDeliveryConfirmer marks messages accepted by the mail server more than confirmAfter ago as
+// delivered if no bounce arrived for them, checking every hour.
+func (m *Manager) RunDeliveryConfirmer(ctx context.Context, confirmAfter time.Duration) {
+	if confirmAfter <= 0 {
+		m.lo.Info("delivery confirmation period is non-positive, skipping delivery confirmer", "confirm_after", confirmAfter)
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var uuids []string
+			if err := m.q.GetUnconfirmedDeliveries.SelectContext(ctx, &uuids, time.Now().Add(-confirmAfter), maxDeliveryConfirmations); err != nil {
+				m.lo.Error("error fetching unconfirmed message deliveries", "error", err)
+				continue
+			}
+			for _, uuid := range uuids {
+				m.addDeliveryEvent(uuid, "", DeliveryDelivered, "no bounce received")
+			}
+		}
+	}
+}
+
+// addOpenTrackingPixel adds the open tracking pixel of the message to its HTML content if the email
+// inbox has open tracking enabled.
+func (m *Manager) addOpenTrackingPixel(inboxID int, message *models.Message) {
+	if message.ContentType != models.ContentTypeHTML {
+		return
+	}
+	record, err := m.inboxStore.GetDBRecord(inboxID)
+	if err != nil {
+		return
+	}
+	var cfg imodels.Config
+	if err := json.Unmarshal(record.Config, &cfg); err != nil || !cfg.TrackOpens {
+		return
+	}
+	rootURL, err := m.settingsStore.GetAppRootURL()
+	if err != nil {
+		m.lo.Error("error fetching app root URL for open tracking", "error", err)
+		return
+	}
+	pixel := fmt.Sprintf(`<img src="%s/email/open/%s" width="1" height="1" alt="" style="display:none">`, strings.TrimRight(rootURL, "/"), message.UUID)
+	if i := strings.LastIndex(strings.ToLower(message.Content), "</body>"); i >= 0 {
+		message.Content = message.Content[:i] + pixel + message.Content[i:]
+		return
+	}
+	message.Content += pixel
+}
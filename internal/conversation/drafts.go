@@ -2,6 +2,7 @@ package conversation
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
@@ -9,11 +10,16 @@ import (
 	"github.com/abhinavxd/libredesk/internal/envelope"
 )
 
-// UpsertConversationDraft saves or updates a draft for a conversation.
-func (m *Manager) UpsertConversationDraft(conversationID, userID int, content string, meta json.RawMessage) (models.ConversationDraft, error) {
+// UpsertConversationDraft saves or updates a draft for a conversation. version is the draft version the
+// client last saw; the save is rejected with a conflict if the draft has since been saved elsewhere.
+// A version of 0 overwrites the draft unconditionally.
+func (m *Manager) UpsertConversationDraft(conversationID, userID int, content string, meta json.RawMessage, version int) (models.ConversationDraft, error) {
 	var draft models.ConversationDraft
 
-	if err := m.q.UpsertConversationDraft.Get(&draft, conversationID, userID, content, meta); err != nil {
+	if err := m.q.UpsertConversationDraft.Get(&draft, conversationID, userID, content, meta, version); err != nil {
+		if err == sql.ErrNoRows {
+			return draft, envelope.NewError(envelope.ConflictError, m.i18n.T("conversation.draftConflict"), nil)
+		}
 		m.lo.Error("error upserting conversation draft", "conversation_id", conversationID, "user_id", userID, "error", err)
 		return draft, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -21,6 +27,20 @@ func (m *Manager) UpsertConversationDraft(conversationID, userID int, content st
 	return draft, nil
 }
 
+// GetConversationDraft returns the user's draft for a conversation along with the number of messages
+// received since it was saved. Returns nil if there is no draft.
+func (m *Manager) GetConversationDraft(conversationID, userID int) (*models.ConversationDraft, error) {
+	var draft models.ConversationDraft
+	if err := m.q.GetConversationDraft.Get(&draft, conversationID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		m.lo.Error("error fetching conversation draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return &draft, nil
+}
+
 // GetAllUserDrafts retrieves all drafts for a user.
 func (m *Manager) GetAllUserDrafts(userID int) ([]models.ConversationDraft, error) {
 	var drafts = make([]models.ConversationDraft, 0)
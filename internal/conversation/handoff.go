@@ -0,0 +1,33 @@
+package conversation
+
+import (
+	"database/sql"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// AddHandoffNote records a handoff note for the user a conversation is being reassigned to.
+// The note replaces any earlier active note of the conversation.
+func (m *Manager) AddHandoffNote(conversationID, fromUserID, toUserID int, summary, currentState, nextStep string) error {
+	var id int
+	if err := m.q.InsertHandoffNote.Get(&id, conversationID, fromUserID, toUserID, summary, currentState, nextStep); err != nil {
+		m.lo.Error("error inserting handoff note", "conversation_id", conversationID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// GetActiveHandoffNote returns the handoff note left for the current assignee of a conversation,
+// nil if there is none.
+func (m *Manager) GetActiveHandoffNote(conversationID int) (*models.HandoffNote, error) {
+	var note models.HandoffNote
+	if err := m.q.GetActiveHandoffNote.Get(&note, conversationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		m.lo.Error("error fetching handoff note", "conversation_id", conversationID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return &note, nil
+}
@@ -0,0 +1,51 @@
+package conversation
+
+import (
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+// SendAddressChangeNotice replies to every conversation of an inbox that isn't resolved with a notice
+// that the inbox's address changed, sent by the system user and skipped for contacts who opted out of
+// automated messages. progress is called with the UUID of each conversation and the error queueing its notice, if any.
+func (m *Manager) SendAddressChangeNotice(inboxID int, content string, progress func(uuid string, err error)) error {
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		m.lo.Error("error fetching system user", "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	var uuids []string
+	if err := m.q.GetActiveConversationUUIDsByInbox.Select(&uuids, inboxID); err != nil {
+		m.lo.Error("error fetching active conversations of inbox", "inbox_id", inboxID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	for _, uuid := range uuids {
+		progress(uuid, m.sendAddressChangeNotice(uuid, content, systemUser.ID))
+	}
+	return nil
+}
+
+// sendAddressChangeNotice queues the address change notice as a reply to a conversation.
+func (m *Manager) sendAddressChangeNotice(uuid, content string, systemUserID int) error {
+	conversation, err := m.GetConversation(0, uuid, "")
+	if err != nil {
+		return err
+	}
+	if !m.contactAllows(conversation.ContactID, umodels.ContactPreferences.AllowsAutomated) {
+		m.lo.Info("contact opted out of automated messages, skipping address change notice", "conversation_uuid", uuid, "contact_id", conversation.ContactID)
+		return nil
+	}
+	to, cc, bcc, err := m.makeRecipients(conversation)
+	if err != nil {
+		m.lo.Error("error making address change notice recipients", "conversation_uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	meta := map[string]any{"is_address_change_notice": true}
+	if _, err := m.QueueReply(nil /**media**/, conversation.InboxID, systemUserID, conversation.ContactID, uuid, content, to, cc, bcc, meta); err != nil {
+		m.lo.Error("error sending address change notice", "conversation_uuid", uuid, "error", err)
+		return err
+	}
+	return nil
+}
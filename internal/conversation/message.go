@@ -22,14 +22,23 @@ import (
 	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
 	"github.com/abhinavxd/libredesk/internal/sla"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/tracing"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
 	maxMessagesPerPage = 500
+	// Incoming messages staged in the DB when the in-memory queue is full are drained in batches of this size.
+	stagedIncomingBatchSize = 100
+	// A claimed staged message is retried by another instance if not processed within this lease.
+	stagedIncomingClaimLease = 5 * time.Minute
+	// A staged message that fails to process is retried with exponential backoff up to this many times,
+	// after which it is kept in the staging table for inspection.
+	stagedIncomingMaxAttempts = 10
 	// Only allow visitor-to-contact upgrade within this window after the last continuity email.
 	upgradeWindowTTL = 7 * 24 * time.Hour
 )
@@ -54,6 +63,11 @@ func (m *Manager) Run(ctx context.Context, incomingQWorkers, outgoingQWorkers, s
 			m.IncomingMessageWorker(ctx)
 		}()
 	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.StagedIncomingMessageWorker(ctx, scanInterval)
+	}()
 
 	// Scan pending outgoing messages and send them.
 	for {
@@ -64,10 +78,17 @@ func (m *Manager) Run(ctx context.Context, incomingQWorkers, outgoingQWorkers, s
 			var (
 				pendingMessages = []models.Message{}
 				messageIDs      = m.getOutgoingProcessingMessageIDs()
+				// Claim only as many messages as there are idle workers, so claimed messages don't
+				// wait in the queue while their claim lapses and another instance sends them again.
+				free = int(outgoingQWorkers) - len(messageIDs)
 			)
+			if free <= 0 {
+				continue
+			}
 
-			// Get pending outgoing messages and skip the currently processing message ids.
-			if err := m.q.GetOutgoingPendingMessages.Select(&pendingMessages, pq.Array(messageIDs)); err != nil {
+			// Claim pending outgoing messages, skipping the ones this instance is already processing
+			// and the ones claimed by other instances.
+			if err := m.q.GetOutgoingPendingMessages.Select(&pendingMessages, pq.Array(messageIDs), free, m.outgoingClaimLease.Seconds(), pq.Array(m.getThrottledInboxIDs())); err != nil {
 				m.lo.Error("error fetching pending messages from db", "error", err)
 				continue
 			}
@@ -112,6 +133,65 @@ func (m *Manager) IncomingMessageWorker(ctx context.Context) {
 	}
 }
 
+// StagedIncomingMessageWorker periodically drains incoming messages that were staged in the DB because the
+// in-memory incoming queue was full.
+func (m *Manager) StagedIncomingMessageWorker(ctx context.Context, scanInterval time.Duration) {
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.closedMu.RLock()
+			closed := m.closed
+			m.closedMu.RUnlock()
+			if closed {
+				return
+			}
+			m.drainStagedIncomingMessages(ctx)
+		}
+	}
+}
+
+// drainStagedIncomingMessages claims and processes staged incoming messages until none are left.
+func (m *Manager) drainStagedIncomingMessages(ctx context.Context) {
+	for ctx.Err() == nil {
+		var staged []struct {
+			ID      int64           `db:"id"`
+			Payload json.RawMessage `db:"payload"`
+		}
+		if err := m.q.ClaimStagedIncomingMessages.Select(&staged, stagedIncomingBatchSize, stagedIncomingClaimLease.Seconds(), stagedIncomingMaxAttempts); err != nil {
+			m.lo.Error("error claiming staged incoming messages", "error", err)
+			return
+		}
+		if len(staged) == 0 {
+			return
+		}
+
+		m.lo.Info("processing staged incoming messages", "count", len(staged))
+		for _, s := range staged {
+			var msg models.IncomingMessage
+			err := json.Unmarshal(s.Payload, &msg)
+			if err == nil {
+				_, err = m.ProcessIncomingMessage(msg)
+			}
+			// Keep failed messages and retry them later, e.g. after a transient DB failure.
+			if err != nil {
+				m.lo.Error("error processing staged incoming msg, retrying later", "id", s.ID, "error", err)
+				if _, err := m.q.RetryStagedIncomingMessage.Exec(s.ID, err.Error()); err != nil {
+					m.lo.Error("error scheduling staged incoming message retry", "id", s.ID, "error", err)
+				}
+				continue
+			}
+			if _, err := m.q.DeleteStagedIncomingMessage.Exec(s.ID); err != nil {
+				m.lo.Error("error deleting staged incoming message", "id", s.ID, "error", err)
+			}
+		}
+	}
+}
+
 // MessageSenderWorker sends outgoing pending messages.
 func (m *Manager) MessageSenderWorker(ctx context.Context) {
 	for {
@@ -131,9 +211,31 @@ func (m *Manager) MessageSenderWorker(ctx context.Context) {
 func (m *Manager) sendOutgoingMessage(message models.Message) {
 	defer m.outgoingProcessingMessages.Delete(message.ID)
 
+	ctx, span := tracing.Start(context.Background(), "conversation.send_outgoing",
+		attribute.Int("message.id", message.ID),
+		attribute.String("conversation.uuid", message.ConversationUUID),
+		attribute.Int("inbox.id", message.InboxID),
+	)
+	var spanErr error
+	defer func() { tracing.End(span, spanErr) }()
+
+	// Renew the claim for the full lease now that the message is being sent. If the claim lapsed while the
+	// message waited in the queue, another instance may have claimed and sent it, so it is left to that instance.
+	res, err := m.q.ExtendOutgoingMessageClaim.Exec(message.ID, m.outgoingClaimLease.Seconds(), message.SendClaimToken)
+	if err != nil {
+		spanErr = err
+		m.lo.Error("error extending outgoing message claim", "message_id", message.ID, "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		m.lo.Warn("outgoing message claim lost, skipping send", "message_id", message.ID)
+		return
+	}
+
 	// Helper function to handle errors
 	handleError := func(err error, errorMsg string) bool {
 		if err != nil {
+			spanErr = err
 			m.lo.Error(errorMsg, "error", err, "message_id", message.ID)
 			m.UpdateMessageStatus(message.UUID, models.MessageStatusFailed)
 			return true
@@ -147,8 +249,19 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 		return
 	}
 
+	// Messages over the send limits of the inbox are released to be sent by a later scan, so that the
+	// worker moves on to the messages of other inboxes.
+	release, ok := m.acquireSendSlot(inb)
+	if !ok {
+		if _, err := m.q.ReleaseOutgoingMessage.Exec(message.ID, message.SendClaimToken); err != nil {
+			m.lo.Error("error releasing throttled outgoing message", "message_id", message.ID, "error", err)
+		}
+		return
+	}
+	defer release()
+
 	// Render content in template
-	if err := m.RenderMessageInTemplate(inb.Channel(), &message); err != nil {
+	if err := m.RenderMessageInTemplate(inb, &message); err != nil {
 		handleError(err, "error rendering content in template")
 		return
 	}
@@ -159,6 +272,11 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 		return
 	}
 
+	// Add the open tracking pixel to emails if enabled for the inbox.
+	if inb.Channel() == inbox.ChannelEmail {
+		m.addOpenTrackingPixel(inb.Identifier(), &message)
+	}
+
 	// Convert to OutboundMessage for transport
 	outbound := message.ToOutbound()
 
@@ -171,7 +289,9 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 	}
 
 	// Send message
+	_, sendSpan := tracing.Start(ctx, "inbox.send", attribute.String("message.channel", inb.Channel()))
 	err = inb.Send(outbound)
+	tracing.End(sendSpan, err)
 	if err != nil && err != livechat.ErrClientNotConnected {
 		handleError(err, "error sending message")
 		return
@@ -180,6 +300,11 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 	// Update status as sent.
 	m.UpdateMessageStatus(message.UUID, models.MessageStatusSent)
 
+	// Start the delivery timeline of emails, later events come from delivery reports and the open tracking pixel.
+	if inb.Channel() == inbox.ChannelEmail {
+		m.addDeliveryEvent(message.UUID, "", DeliveryAccepted, "")
+	}
+
 	// Skip system user replies since we only update timestamps and SLA for human replies.
 	systemUser, err := m.userStore.GetSystemUser()
 	if err != nil {
@@ -214,8 +339,13 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 
 		m.BroadcastConversationUpdate(message.ConversationUUID, wsData)
 
-		// Evaluate automation rules for outgoing message.
-		m.automation.EvaluateConversationUpdateRulesByID(message.ConversationID, "", amodels.EventConversationMessageOutgoing)
+		// Evaluate automation rules for outgoing message, refetching the conversation as it was updated above.
+		conversation, err = m.GetConversation(message.ConversationID, "", "")
+		if err != nil {
+			m.lo.Error("error fetching conversation", "conversation_id", message.ConversationID, "error", err)
+			return
+		}
+		m.automation.EvaluateConversationUpdateRulesContext(ctx, conversation, amodels.EventConversationMessageOutgoing)
 	}
 }
 
@@ -271,9 +401,23 @@ func (m *Manager) BuildTemplateData(conversationUUID string, senderID int) (map[
 	return data, nil
 }
 
-// RenderMessageInTemplate renders message content in the email base template for sending.
-func (m *Manager) RenderMessageInTemplate(channel string, message *models.Message) error {
-	switch channel {
+// appendSignature returns the content with the signature of the agent that sent it, if they have one.
+// The system user has no signature. A signature that can't be fetched is left out rather than failing the send.
+func (m *Manager) appendSignature(content string, senderID int) string {
+	signature, err := m.userStore.GetSignature(senderID)
+	if err != nil {
+		m.lo.Warn("could not fetch sender signature", "user_id", senderID, "error", err)
+		return content
+	}
+	if signature == "" {
+		return content
+	}
+	return content + `<div class="signature">` + signature + `</div>`
+}
+
+// RenderMessageInTemplate renders message content in the email template of the inbox for sending.
+func (m *Manager) RenderMessageInTemplate(inb inbox.Inbox, message *models.Message) error {
+	switch channel := inb.Channel(); channel {
 	case inbox.ChannelEmail:
 		data, err := m.BuildTemplateData(message.ConversationUUID, message.SenderID)
 		if err != nil {
@@ -290,7 +434,20 @@ func (m *Manager) RenderMessageInTemplate(channel string, message *models.Messag
 		}
 		data["IsContinuityEmail"] = isContinuity
 
-		message.Content, err = m.template.RenderEmailWithTemplate(data, message.Content)
+		// Append the sender's signature to their replies, it's rendered with the content so it can use template variables.
+		if !isContinuity {
+			message.Content = m.appendSignature(message.Content, message.SenderID)
+		}
+
+		// The inbox's own outgoing template, the default template is used if it has none.
+		var templateID int
+		if record, err := m.inboxStore.GetDBRecord(inb.Identifier()); err != nil {
+			m.lo.Warn("could not fetch inbox email template, using default", "inbox_id", inb.Identifier(), "error", err)
+		} else if record.EmailTemplateID.Valid {
+			templateID = int(record.EmailTemplateID.Int)
+		}
+
+		message.Content, err = m.template.RenderEmailWithTemplate(data, message.Content, templateID)
 		if err != nil {
 			m.lo.Error("could not render email content using template", "id", message.ID, "error", err)
 			return fmt.Errorf("could not render email content using template: %w", err)
@@ -306,7 +463,8 @@ func (m *Manager) RenderMessageInTemplate(channel string, message *models.Messag
 }
 
 // GetConversationMessages retrieves messages for a specific conversation.
-func (m *Manager) GetConversationMessages(conversationUUID string, page, pageSize int, private *bool, msgTypes []string) ([]models.Message, int, error) {
+// threadID 0 returns the main timeline, a positive threadID returns the notes of that internal thread.
+func (m *Manager) GetConversationMessages(conversationUUID string, page, pageSize int, private *bool, msgTypes []string, threadID int) ([]models.Message, int, error) {
 	var (
 		messages = make([]models.Message, 0)
 		qArgs    []any
@@ -318,7 +476,13 @@ func (m *Manager) GetConversationMessages(conversationUUID string, page, pageSiz
 		typesArg = pq.StringArray(msgTypes)
 	}
 
-	qArgs = append(qArgs, conversationUUID, private, typesArg)
+	// Messages of archived conversations are moved back from cold storage before they are read.
+	if err := m.restoreArchivedConversation(conversationUUID); err != nil {
+		m.lo.Error("error restoring archived conversation", "uuid", conversationUUID, "error", err)
+		return messages, pageSize, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	qArgs = append(qArgs, conversationUUID, private, typesArg, threadID)
 	query, pageSize, qArgs, err := m.generateMessagesQuery(m.q.GetMessages, qArgs, page, pageSize)
 	if err != nil {
 		m.lo.Error("error generating messages query", "error", err)
@@ -350,6 +514,18 @@ func (m *Manager) GetMessage(uuid string) (models.Message, error) {
 		return message, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
 
+	// The body of a message of an archived conversation is in cold storage, restore the conversation and fetch it again.
+	if message.Archived {
+		if err := m.restoreArchivedConversation(message.ConversationUUID); err != nil {
+			m.lo.Error("error restoring archived conversation", "uuid", message.ConversationUUID, "error", err)
+			return message, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		if err := m.q.GetMessage.Get(&message, uuid); err != nil {
+			m.lo.Error("error fetching message", "uuid", uuid, "error", err)
+			return message, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+	}
+
 	// Generate signed URLs for attachments.
 	for i := range message.Attachments {
 		message.Attachments[i].URL = m.mediaStore.GetSignedURL(message.Attachments[i].UUID)
@@ -358,6 +534,25 @@ func (m *Manager) GetMessage(uuid string) (models.Message, error) {
 	return message, nil
 }
 
+// RecordReadReceipt records a read receipt (MDN) on the outgoing message with the given source ID.
+// Receipts for unknown messages and repeated receipts are ignored.
+func (m *Manager) RecordReadReceipt(sourceID, disposition, from string) error {
+	var res struct {
+		UUID             string          `db:"uuid"`
+		ConversationUUID string          `db:"conversation_uuid"`
+		Meta             json.RawMessage `db:"meta"`
+	}
+	if err := m.q.SetMessageReadReceipt.Get(&res, sourceID, disposition, from); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		m.lo.Error("error recording message read receipt", "source_id", sourceID, "error", err)
+		return err
+	}
+	m.BroadcastMessageUpdate(res.ConversationUUID, res.UUID, map[string]any{"meta": res.Meta})
+	return nil
+}
+
 // UpdateMessageStatus updates the status of a message.
 func (m *Manager) UpdateMessageStatus(messageUUID string, status string) error {
 	if _, err := m.q.UpdateMessageStatus.Exec(status, messageUUID); err != nil {
@@ -389,11 +584,13 @@ func (m *Manager) MarkMessageAsPending(uuid string) error {
 }
 
 // SendPrivateNote inserts a private message in a conversation.
-func (m *Manager) SendPrivateNote(media []mmodels.Media, senderID int, conversationUUID, content string, mentions []models.MentionInput) (models.Message, error) {
+// A positive threadID posts the note to that internal thread of the conversation.
+func (m *Manager) SendPrivateNote(media []mmodels.Media, senderID int, conversationUUID, content string, mentions []models.MentionInput, threadID int) (models.Message, error) {
 	// Best-effort render template variables before saving.
 	if data, err := m.BuildTemplateData(conversationUUID, senderID); err == nil {
 		content = m.template.RenderString(data, content)
 	}
+	content = m.LinkConversationReferences(content)
 
 	message := models.Message{
 		ConversationUUID: conversationUUID,
@@ -406,10 +603,23 @@ func (m *Manager) SendPrivateNote(media []mmodels.Media, senderID int, conversat
 		Private:          true,
 		Media:            media,
 	}
+	if threadID > 0 {
+		message.ThreadID = null.IntFrom(threadID)
+	}
 	if err := m.InsertMessage(&message); err != nil {
 		return models.Message{}, err
 	}
 
+	// Note authors take part in the thread.
+	if threadID > 0 {
+		if _, err := m.q.AddThreadParticipant.Exec(threadID, senderID); err != nil {
+			m.lo.Error("error adding thread participant", "thread_id", threadID, "user_id", senderID, "error", err)
+		}
+		if _, err := m.q.TouchThread.Exec(threadID); err != nil {
+			m.lo.Error("error updating thread", "thread_id", threadID, "error", err)
+		}
+	}
+
 	// Insert mentions if any.
 	if len(mentions) > 0 {
 		if err := m.InsertMentions(message.ConversationID, message.ID, senderID, mentions); err != nil {
@@ -421,6 +631,31 @@ func (m *Manager) SendPrivateNote(media []mmodels.Media, senderID int, conversat
 	return message, nil
 }
 
+// SendIntegrationNote adds a private note posted by an external system. Unlike agent notes, the content isn't
+// rendered as a template. meta records the integration that posted the note.
+func (m *Manager) SendIntegrationNote(senderID int, conversationUUID, content string, meta map[string]any) (models.Message, error) {
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		m.lo.Error("error marshalling integration note meta", "error", err)
+		return models.Message{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	message := models.Message{
+		ConversationUUID: conversationUUID,
+		SenderID:         senderID,
+		Type:             models.MessageOutgoing,
+		SenderType:       models.SenderTypeAgent,
+		Status:           models.MessageStatusSent,
+		Content:          m.LinkConversationReferences(content),
+		ContentType:      models.ContentTypeHTML,
+		Private:          true,
+		Meta:             metaJSON,
+	}
+	if err := m.InsertMessage(&message); err != nil {
+		return models.Message{}, err
+	}
+	return message, nil
+}
+
 // CreateContactMessage creates a contact message in a conversation.
 func (m *Manager) CreateContactMessage(media []mmodels.Media, contactID int, conversationUUID, content, contentType string, isNewConversation bool) (models.Message, error) {
 	message := models.Message{
@@ -514,6 +749,7 @@ func (m *Manager) QueueReply(media []mmodels.Media, inboxID, senderID, contactID
 		SourceID:          null.StringFrom(sourceID),
 		MessageReceiverID: contactID,
 		Meta:              metaJSON,
+		SendPriority:      m.replySendPriority(senderID, metaMap),
 	}
 	if err := m.InsertMessage(&message); err != nil {
 		return models.Message{}, err
@@ -521,8 +757,43 @@ func (m *Manager) QueueReply(media []mmodels.Media, inboxID, senderID, contactID
 	return message, nil
 }
 
+// replySendPriority returns the send priority of a reply, CSAT surveys, autoresponses and replies sent
+// by the system user are queued behind agent replies.
+func (m *Manager) replySendPriority(senderID int, metaMap map[string]interface{}) int {
+	if isCSAT, _ := metaMap["is_csat"].(bool); isCSAT {
+		return models.MessageSendPriorityLow
+	}
+	if _, ok := metaMap["autoresponder_id"]; ok {
+		return models.MessageSendPriorityLow
+	}
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		m.lo.Error("error fetching system user", "error", err)
+		return models.MessageSendPriorityHigh
+	}
+	if senderID == systemUser.ID {
+		return models.MessageSendPriorityLow
+	}
+	return models.MessageSendPriorityHigh
+}
+
+// GetOutboxDepth returns the number of pending outgoing messages of an inbox.
+func (m *Manager) GetOutboxDepth(inboxID int) (models.OutboxDepth, error) {
+	var depth models.OutboxDepth
+	if err := m.q.GetInboxOutboxDepth.Get(&depth, inboxID); err != nil {
+		m.lo.Error("error fetching inbox outbox depth", "inbox_id", inboxID, "error", err)
+		return depth, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return depth, nil
+}
+
 // InsertMessage inserts a message and attaches the media to the message.
 func (m *Manager) InsertMessage(message *models.Message) error {
+	return m.insertMessage(context.Background(), message)
+}
+
+// insertMessage is InsertMessage with the message.created webhook traced as a child of the span in ctx.
+func (m *Manager) insertMessage(ctx context.Context, message *models.Message) error {
 	if message.Private {
 		message.Status = models.MessageStatusSent
 	}
@@ -542,9 +813,14 @@ func (m *Manager) InsertMessage(message *models.Message) error {
 		message.TextContent = stringutil.HTML2Text(message.Content)
 	}
 
+	// Leave the contact's signature and disclaimers out of the text used for previews, search and AI, the content keeps them.
+	if message.Type == models.MessageIncoming && message.SenderType == models.SenderTypeContact {
+		message.TextContent = stringutil.StripSignature(message.TextContent)
+	}
+
 	// Insert Message.
 	if err := m.q.InsertMessage.Get(message, message.Type, message.Status, message.ConversationID, message.ConversationUUID, message.Content, message.TextContent, message.SenderID, message.SenderType,
-		message.Private, message.ContentType, message.SourceID, message.Meta); err != nil {
+		message.Private, message.ContentType, message.SourceID, message.Meta, message.ThreadID, message.SendPriority); err != nil {
 		m.lo.Error("error inserting message in db", "error", err)
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -557,6 +833,11 @@ func (m *Manager) InsertMessage(message *models.Message) error {
 	// Add this user as a participant if not already present.
 	m.addConversationParticipant(message.SenderID, message.ConversationUUID)
 
+	// A contact writing to a trashed conversation brings it back.
+	if message.Type == models.MessageIncoming && message.SenderType == models.SenderTypeContact {
+		m.restoreOnContactMessage(message.ConversationUUID)
+	}
+
 	// Skip updating last_message and broadcasting for continuity emails.
 	if !message.IsContinuityMessage() {
 		// Hide CSAT message content as it contains a public link to the survey.
@@ -586,7 +867,7 @@ func (m *Manager) InsertMessage(message *models.Message) error {
 	}
 
 	// Trigger webhook for new message created.
-	m.webhookStore.TriggerEvent(wmodels.EventMessageCreated, message)
+	m.webhookStore.TriggerEventContext(ctx, wmodels.EventMessageCreated, message)
 
 	return nil
 }
@@ -648,6 +929,12 @@ func (m *Manager) InsertConversationActivity(activityType, conversationUUID, new
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
 
+	// Keep the activity type and value in meta so that history can be computed without parsing content.
+	meta, _ := json.Marshal(map[string]string{
+		"activity_type":  activityType,
+		"activity_value": newValue,
+	})
+
 	message := models.Message{
 		Type:             models.MessageActivity,
 		Status:           models.MessageStatusSent,
@@ -657,6 +944,7 @@ func (m *Manager) InsertConversationActivity(activityType, conversationUUID, new
 		Private:          true,
 		SenderID:         actor.ID,
 		SenderType:       models.SenderTypeAgent,
+		Meta:             meta,
 	}
 
 	if err := m.InsertMessage(&message); err != nil {
@@ -698,6 +986,8 @@ func (m *Manager) getMessageActivityContent(activityType, newValue, actorName st
 		content = fmt.Sprintf("%s set %s SLA policy", actorName, newValue)
 	case models.ActivityParticipantAdded:
 		content = fmt.Sprintf("%s joined the conversation", newValue)
+	case models.ActivityContactIdentified:
+		content = fmt.Sprintf("Visitor identified as %s, conversation moved to their contact", newValue)
 	default:
 		return "", fmt.Errorf("invalid activity type %s", activityType)
 	}
@@ -708,7 +998,17 @@ func (m *Manager) getMessageActivityContent(activityType, newValue, actorName st
 // associated contact. It finds or creates the contact, checks for existing
 // conversations, and creates a new conversation if necessary. It also
 // inserts the message, uploads any attachments, and queues the conversation evaluation of automation rules.
-func (m *Manager) ProcessIncomingMessage(in models.IncomingMessage) (models.Message, error) {
+func (m *Manager) ProcessIncomingMessage(in models.IncomingMessage) (msg models.Message, err error) {
+	ctx, span := tracing.Start(in.Trace.Extract(context.Background()), "conversation.process_incoming",
+		attribute.Int("inbox.id", in.InboxID),
+		attribute.String("message.channel", in.Channel),
+		attribute.String("message.source_id", in.SourceID.String),
+	)
+	defer func() {
+		span.SetAttributes(attribute.String("conversation.uuid", msg.ConversationUUID))
+		tracing.End(span, err)
+	}()
+
 	// Return early if this message already exists (same source ID).
 	dupConvID, err := m.messageExistsBySourceID([]string{in.SourceID.String})
 	if err != nil && err != errConversationNotFound {
@@ -758,7 +1058,7 @@ func (m *Manager) ProcessIncomingMessage(in models.IncomingMessage) (models.Mess
 	}
 
 	// Convert to Message for attachment upload and insertion.
-	msg := in.ToMessage(senderID, conversationID, conversationUUID)
+	msg = in.ToMessage(senderID, conversationID, conversationUUID)
 
 	// Upload message attachments. On failure, delete the conversation if it was just created for this message.
 	if upErr := m.uploadMessageAttachments(&msg); upErr != nil {
@@ -773,16 +1073,18 @@ func (m *Manager) ProcessIncomingMessage(in models.IncomingMessage) (models.Mess
 	}
 
 	// Insert message.
-	if err = m.InsertMessage(&msg); err != nil {
+	if err = m.insertMessage(ctx, &msg); err != nil {
 		return models.Message{}, err
 	}
+	m.insertMessageHeaders(msg.ID, in.Headers)
+	m.checkAbuse(msg)
 
 	// When a customer replies to a continuity emailsync the message to their live chat widget via WebSocket.
 	// No-op if the conversation's inbox isn't livechat.
 	m.broadcastMessageToWidgetClients(&msg)
 
 	// Process post-message hooks (automation rules, webhooks, SLA, etc.).
-	if err := m.ProcessIncomingMessageHooks(msg.ConversationUUID, isNewConversation); err != nil {
+	if err := m.processIncomingMessageHooks(ctx, msg.ConversationUUID, isNewConversation); err != nil {
 		m.lo.Error("error processing incoming message hooks", "conversation_uuid", msg.ConversationUUID, "error", err)
 		return models.Message{}, fmt.Errorf("processing incoming message hooks: %w", err)
 	}
@@ -902,6 +1204,9 @@ func (m *Manager) ProcessIncomingLiveChatMessage(msg models.Message) (models.Mes
 		m.lo.Error("error updating contact last seen after livechat message", "conversation_uuid", msg.ConversationUUID, "error", err)
 	}
 
+	// Checked in the background as the classifier can be slow to respond to the chat request.
+	go m.checkAbuse(msg)
+
 	// Process post-message hooks (automation rules, webhooks, SLA, etc.).
 	// isNewConversation = false since conversation always exists for live chat.
 	if err := m.ProcessIncomingMessageHooks(msg.ConversationUUID, false); err != nil {
@@ -925,20 +1230,49 @@ func (m *Manager) MessageExists(messageID string) (bool, error) {
 }
 
 // EnqueueIncoming enqueues an incoming message for inserting in db.
+// If the queue is full, the message is staged in the DB and picked up later by StagedIncomingMessageWorker.
 func (m *Manager) EnqueueIncoming(message models.IncomingMessage) error {
-	m.closedMu.Lock()
-	defer m.closedMu.Unlock()
+
+	// Start the trace here so that time spent waiting in the queue is visible.
+	if message.Trace == nil {
+		ctx, span := tracing.Start(context.Background(), "conversation.enqueue_incoming",
+			attribute.Int("inbox.id", message.InboxID),
+			attribute.String("message.channel", message.Channel),
+		)
+		message.Trace = tracing.Inject(ctx)
+		span.End()
+	}
+
+	// The read lock keeps Close from closing the queue during the send, staging happens outside it
+	// so intake isn't serialised on DB latency.
+	m.closedMu.RLock()
 	if m.closed {
+		m.closedMu.RUnlock()
 		return errors.New("incoming message queue is closed")
 	}
-
 	select {
 	case m.incomingMessageQueue <- message:
+		m.closedMu.RUnlock()
 		return nil
 	default:
-		m.lo.Warn("WARNING: incoming message queue is full")
-		return errors.New("incoming message queue is full")
 	}
+	m.closedMu.RUnlock()
+
+	m.lo.Warn("incoming message queue is full, staging message in DB", "inbox_id", message.InboxID, "source_id", message.SourceID.String)
+	return m.stageIncoming(message)
+}
+
+// stageIncoming writes an incoming message to the staging table.
+func (m *Manager) stageIncoming(message models.IncomingMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("marshalling incoming message: %w", err)
+	}
+	if _, err := m.q.InsertStagedIncomingMessage.Exec(message.InboxID, payload); err != nil {
+		m.lo.Error("error staging incoming message", "inbox_id", message.InboxID, "error", err)
+		return fmt.Errorf("staging incoming message: %w", err)
+	}
+	return nil
 }
 
 // GetConversationByMessageID returns conversation by message id.
@@ -1203,6 +1537,11 @@ func (m *Manager) getLatestMessage(conversationID int, typ []string, status []st
 // for incoming messages. This allows other channels to insert messages first and then call this
 // function to trigger the necessary hooks.
 func (m *Manager) ProcessIncomingMessageHooks(conversationUUID string, isNewConversation bool) error {
+	return m.processIncomingMessageHooks(context.Background(), conversationUUID, isNewConversation)
+}
+
+// processIncomingMessageHooks is ProcessIncomingMessageHooks with automations and webhooks traced as children of the span in ctx.
+func (m *Manager) processIncomingMessageHooks(ctx context.Context, conversationUUID string, isNewConversation bool) error {
 	// Start waiting since clock, cleared when agent replies.
 	now := time.Now()
 	m.UpdateConversationWaitingSince(conversationUUID, &now)
@@ -1211,8 +1550,9 @@ func (m *Manager) ProcessIncomingMessageHooks(conversationUUID string, isNewConv
 	if isNewConversation {
 		conversation, err := m.GetConversation(0, conversationUUID, "")
 		if err == nil {
-			m.webhookStore.TriggerEvent(wmodels.EventConversationCreated, conversation)
-			m.automation.EvaluateNewConversationRules(conversation)
+			m.webhookStore.TriggerEventContext(ctx, wmodels.EventConversationCreated, conversation)
+			m.automation.EvaluateNewConversationRulesContext(ctx, conversation)
+			m.sendAutoresponse(conversation)
 		}
 		return nil
 	}
@@ -1234,7 +1574,7 @@ func (m *Manager) ProcessIncomingMessageHooks(conversationUUID string, isNewConv
 		m.lo.Error("error fetching conversation for incoming message hooks", "conversation_uuid", conversationUUID, "error", err)
 	} else {
 		// Trigger automations on incoming message event.
-		m.automation.EvaluateConversationUpdateRules(conversation, amodels.EventConversationMessageIncoming)
+		m.automation.EvaluateConversationUpdateRulesContext(ctx, conversation, amodels.EventConversationMessageIncoming)
 
 		if conversation.SLAPolicyID.Int == 0 {
 			m.lo.Info("no SLA policy applied to conversation, skipping next response SLA event creation")
@@ -0,0 +1,65 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// insertMessageHeaders stores the retained raw headers of an incoming message. Failures are
+// only logged as the headers are not needed to process the message.
+func (m *Manager) insertMessageHeaders(messageID int, headers map[string][]string) {
+	if len(headers) == 0 {
+		return
+	}
+	b, err := json.Marshal(headers)
+	if err != nil {
+		m.lo.Error("error marshalling message headers", "message_id", messageID, "error", err)
+		return
+	}
+	if _, err := m.q.InsertMessageHeaders.Exec(messageID, b); err != nil {
+		m.lo.Error("error inserting message headers", "message_id", messageID, "error", err)
+	}
+}
+
+// GetMessageHeaders returns the retained raw headers of a message.
+func (m *Manager) GetMessageHeaders(messageUUID string) (models.MessageHeaders, error) {
+	var h models.MessageHeaders
+	if err := m.q.GetMessageHeaders.Get(&h, messageUUID); err != nil {
+		if err == sql.ErrNoRows {
+			return h, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching message headers", "message_uuid", messageUUID, "error", err)
+		return h, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return h, nil
+}
+
+// RunMessageHeaderCleaner deletes retained message headers older than the retention period every hour.
+func (m *Manager) RunMessageHeaderCleaner(ctx context.Context, retentionPeriod time.Duration) {
+	if retentionPeriod <= 0 {
+		m.lo.Info("message header retention period is non-positive, skipping message header cleaner", "retention_period", retentionPeriod)
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res, err := m.q.DeleteExpiredMessageHeaders.ExecContext(ctx, time.Now().Add(-retentionPeriod))
+			if err != nil {
+				m.lo.Error("error deleting expired message headers", "error", err)
+				continue
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				m.lo.Info("deleted expired message headers", "count", n)
+			}
+		}
+	}
+}
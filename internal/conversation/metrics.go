@@ -0,0 +1,59 @@
+package conversation
+
+import (
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/lib/pq"
+)
+
+// recalculateBatchSize is the number of conversations recalculated per batch.
+const recalculateBatchSize = 500
+
+// CountConversationsCreatedBetween returns the number of conversations created in [from, to).
+func (m *Manager) CountConversationsCreatedBetween(from, to time.Time) (int, error) {
+	var count int
+	if err := m.q.CountConversationsCreatedBetween.Get(&count, from, to); err != nil {
+		m.lo.Error("error counting conversations", "error", err)
+		return 0, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return count, nil
+}
+
+// RecalculateMetrics recomputes the derived fields of the conversations created in [from, to) from their
+// message history: first and last reply, waiting since, resolved and closed times, followed by the states of
+// their applied SLAs and next response SLA events. Conversations are processed in batches and progress is
+// called with the number of conversations recalculated in each. Returns the number of conversations recalculated.
+func (m *Manager) RecalculateMetrics(from, to time.Time, progress func(done int)) (int, error) {
+	systemUser, err := m.userStore.GetSystemUser()
+	if err != nil {
+		m.lo.Error("error fetching system user", "error", err)
+		return 0, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	var total, lastID int
+	for {
+		var ids []int
+		if err := m.q.GetConversationIDsCreatedBetween.Select(&ids, from, to, lastID, recalculateBatchSize); err != nil {
+			m.lo.Error("error fetching conversations to recalculate", "error", err)
+			return total, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		if _, err := m.q.RecalculateConversationMetrics.Exec(pq.Array(ids), systemUser.ID); err != nil {
+			m.lo.Error("error recalculating conversation metrics", "first_id", ids[0], "last_id", ids[len(ids)-1], "error", err)
+			return total, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		if err := m.slaStore.RecalculateConversationSLAs(ids, systemUser.ID); err != nil {
+			return total, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+
+		total += len(ids)
+		lastID = ids[len(ids)-1]
+		if progress != nil {
+			progress(len(ids))
+		}
+	}
+}
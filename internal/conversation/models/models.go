@@ -7,6 +7,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/attachment"
 	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/tracing"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
 	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
@@ -18,6 +19,7 @@ var (
 	StatusResolved = "Resolved"
 	StatusClosed   = "Closed"
 	StatusSnoozed  = "Snoozed"
+	StatusSpam     = "Spam"
 
 	AssigneeTypeTeam = "team"
 	AssigneeTypeUser = "user"
@@ -27,6 +29,7 @@ var (
 	UnassignedConversations     = "unassigned"
 	TeamUnassignedConversations = "team_unassigned"
 	TeamAllConversations        = "team_all"
+	TrashedConversations        = "trashed"
 	MentionedConversations      = "mentioned"
 
 	MessageIncoming = "incoming"
@@ -39,6 +42,10 @@ var (
 	MentionTypeAgent = "agent"
 	MentionTypeTeam  = "team"
 
+	// Send priorities of outgoing messages, lower is sent first.
+	MessageSendPriorityHigh = 0
+	MessageSendPriorityLow  = 1
+
 	MessageStatusPending  = "pending"
 	MessageStatusSent     = "sent"
 	MessageStatusFailed   = "failed"
@@ -53,6 +60,7 @@ var (
 	ActivityTagRemoved         = "tag_removed"
 	ActivitySLASet             = "sla_set"
 	ActivityParticipantAdded   = "participant_added"
+	ActivityContactIdentified  = "contact_identified"
 
 	ContentTypeText = "text"
 	ContentTypeHTML = "html"
@@ -125,6 +133,7 @@ type ConversationListItem struct {
 	LastReplyAt           null.Time               `db:"last_reply_at" json:"last_reply_at"`
 	ResolvedAt            null.Time               `db:"resolved_at" json:"resolved_at"`
 	Subject               null.String             `db:"subject" json:"subject"`
+	Summary               string                  `db:"summary" json:"summary"`
 	LastMessage           null.String             `db:"last_message" json:"last_message"`
 	LastMessageAt         null.Time               `db:"last_message_at" json:"last_message_at"`
 	LastMessageSender     null.String             `db:"last_message_sender" json:"last_message_sender"`
@@ -173,9 +182,13 @@ type Conversation struct {
 	AssignedUserID            null.Int               `db:"assigned_user_id" json:"assigned_user_id"`
 	AssignedTeamID            null.Int               `db:"assigned_team_id" json:"assigned_team_id"`
 	WaitingSince              null.Time              `db:"waiting_since" json:"waiting_since"`
+	StatusChangedAt           null.Time              `db:"status_changed_at" json:"status_changed_at"`
+	TrashedAt                 null.Time              `db:"trashed_at" json:"trashed_at"`
 	Subject                   null.String            `db:"subject" json:"subject"`
+	Summary                   string                 `db:"summary" json:"summary"`
 	InboxMail                 string                 `db:"inbox_mail" json:"inbox_mail"`
 	InboxReplyTo              string                 `db:"inbox_reply_to" json:"inbox_reply_to"`
+	InboxPreviousAddresses    pq.StringArray         `db:"inbox_previous_addresses" json:"inbox_previous_addresses"`
 	InboxName                 string                 `db:"inbox_name" json:"inbox_name"`
 	InboxChannel              string                 `db:"inbox_channel" json:"inbox_channel"`
 	Tags                      null.JSON              `db:"tags" json:"tags"`
@@ -197,6 +210,7 @@ type Conversation struct {
 	NextResponseMetAt         null.Time              `db:"next_response_met_at" json:"next_response_met_at"`
 	LastContinuityEmailSentAt null.Time              `db:"last_continuity_email_sent_at" json:"-"`
 	PreviousConversations     []PreviousConversation `db:"-" json:"previous_conversations"`
+	PinnedMessages            []PinnedMessage        `db:"-" json:"pinned_messages"`
 }
 
 type ConversationContact struct {
@@ -217,6 +231,7 @@ type ConversationContact struct {
 	LastActiveAt           null.Time       `db:"last_active_at" json:"last_active_at"`
 	LastLoginAt            null.Time       `db:"last_login_at" json:"last_login_at"`
 	ExternalUserID         null.String     `db:"external_user_id" json:"external_user_id"`
+	RiskFlags              pq.StringArray  `db:"risk_flags" json:"risk_flags"`
 }
 
 func (c *ConversationContact) FullName() string {
@@ -237,6 +252,21 @@ type PreviousConversation struct {
 	LastMessageAt null.Time                   `db:"last_message_at" json:"last_message_at"`
 }
 
+// RelatedConversation is a conversation suggested as context for another one.
+type RelatedConversation struct {
+	ID                int         `db:"id" json:"-"`
+	CreatedAt         time.Time   `db:"created_at" json:"created_at"`
+	UUID              string      `db:"uuid" json:"uuid"`
+	ReferenceNumber   string      `db:"reference_number" json:"reference_number"`
+	Subject           string      `db:"subject" json:"subject"`
+	Status            null.String `db:"status" json:"status"`
+	AssignedUserID    null.Int    `db:"assigned_user_id" json:"-"`
+	AssignedTeamID    null.Int    `db:"assigned_team_id" json:"-"`
+	LastMessageAt     null.Time   `db:"last_message_at" json:"last_message_at"`
+	SameContact       bool        `db:"same_contact" json:"same_contact"`
+	SubjectSimilarity float64     `db:"subject_similarity" json:"subject_similarity"`
+}
+
 type PreviousConversationContact struct {
 	FirstName string      `db:"first_name" json:"first_name"`
 	LastName  string      `db:"last_name" json:"last_name"`
@@ -301,10 +331,23 @@ type Message struct {
 	CC                pq.StringArray         `db:"cc" json:"-"`
 	BCC               pq.StringArray         `db:"bcc" json:"-"`
 	MessageReceiverID int                    `db:"message_receiver_id" json:"-"`
+	ThreadID          null.Int               `db:"thread_id" json:"thread_id"`
+	SendPriority      int                    `db:"send_priority" json:"-"`
+	Archived          bool                   `db:"conversation_archived" json:"-"`
+	SendClaimToken    string                 `db:"send_claim_token" json:"-"`
+	Reactions         json.RawMessage        `db:"reactions" json:"reactions,omitempty"`
 	Media             []mmodels.Media        `json:"-"`
 	Author            MessageAuthor          `db:"author" json:"author"`
 }
 
+// OutboxDepth is the number of pending outgoing messages of an inbox.
+type OutboxDepth struct {
+	Pending         int       `db:"pending" json:"pending"`
+	HighPriority    int       `db:"high_priority" json:"high_priority"`
+	LowPriority     int       `db:"low_priority" json:"low_priority"`
+	OldestPendingAt null.Time `db:"oldest_pending_at" json:"oldest_pending_at"`
+}
+
 // IsContinuityMessage returns true if the message is a continuity email.
 func (m *Message) IsContinuityMessage() bool {
 	var meta map[string]any
@@ -348,7 +391,7 @@ func (m *Message) ExtractCSATUUID() string {
 }
 
 // CensorCSATContentWithStatus redacts the content and adds submission status for CSAT messages.
-func (m *Message) CensorCSATContentWithStatus(csatSubmitted bool, csatUUID string, rating int, feedback string) {
+func (m *Message) CensorCSATContentWithStatus(csatSubmitted bool, csatUUID, scale string, rating null.Int, feedback string) {
 	meta, isCsat := m.csatMeta()
 	if !isCsat {
 		return
@@ -359,10 +402,11 @@ func (m *Message) CensorCSATContentWithStatus(csatSubmitted bool, csatUUID strin
 
 	meta["csat_submitted"] = csatSubmitted
 	meta["csat_uuid"] = csatUUID
+	meta["csat_scale"] = scale
 
 	if csatSubmitted {
-		if rating > 0 {
-			meta["submitted_rating"] = rating
+		if rating.Valid {
+			meta["submitted_rating"] = rating.Int
 		}
 		meta["submitted_feedback"] = feedback
 	}
@@ -372,7 +416,7 @@ func (m *Message) CensorCSATContentWithStatus(csatSubmitted bool, csatUUID strin
 	}
 }
 
-// StripCSATUUID removes the csat_uuid from the message meta.
+// StripCSATUUID removes the csat_uuid and csat_token from the message meta.
 // Used to hide CSAT links from agent sessions while keeping them for API key callers.
 func (m *Message) StripCSATUUID() {
 	var meta map[string]any
@@ -380,11 +424,57 @@ func (m *Message) StripCSATUUID() {
 		return
 	}
 	delete(meta, "csat_uuid")
+	delete(meta, "csat_token")
 	if updatedMeta, err := json.Marshal(meta); err == nil {
 		m.Meta = json.RawMessage(updatedMeta)
 	}
 }
 
+// PinnedMessage is a message or note pinned to the top of a conversation.
+type PinnedMessage struct {
+	UUID        string    `db:"uuid" json:"uuid"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	Type        string    `db:"type" json:"type"`
+	Private     bool      `db:"private" json:"private"`
+	TextContent string    `db:"text_content" json:"text_content"`
+	AuthorName  string    `db:"author_name" json:"author_name"`
+	PinnedAt    time.Time `db:"pinned_at" json:"pinned_at"`
+	PinnedByID  null.Int  `db:"pinned_by_id" json:"pinned_by_id"`
+}
+
+// Thread is a named internal discussion grouping private notes of a conversation.
+type Thread struct {
+	ID             int           `db:"id" json:"id"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+	ConversationID int           `db:"conversation_id" json:"-"`
+	Name           string        `db:"name" json:"name"`
+	CreatedByID    null.Int      `db:"created_by_id" json:"created_by_id"`
+	ResolvedAt     null.Time     `db:"resolved_at" json:"resolved_at"`
+	ResolvedByID   null.Int      `db:"resolved_by_id" json:"resolved_by_id"`
+	ParticipantIDs pq.Int64Array `db:"participant_ids" json:"participant_ids"`
+	MessageCount   int           `db:"message_count" json:"message_count"`
+}
+
+// HandoffNote is a structured note left for the new assignee when a conversation is reassigned.
+type HandoffNote struct {
+	ID             int       `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	ConversationID int       `db:"conversation_id" json:"-"`
+	FromUserID     null.Int  `db:"from_user_id" json:"from_user_id"`
+	FromUserName   string    `db:"from_user_name" json:"from_user_name"`
+	ToUserID       int       `db:"to_user_id" json:"to_user_id"`
+	Summary        string    `db:"summary" json:"summary"`
+	CurrentState   string    `db:"current_state" json:"current_state"`
+	NextStep       string    `db:"next_step" json:"next_step"`
+}
+
+// MessageHeaders are the raw email headers retained for an incoming message.
+type MessageHeaders struct {
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	Headers   json.RawMessage `db:"headers" json:"headers"`
+}
+
 // OutboundMessage contains fields needed for sending messages via inboxes.
 type OutboundMessage struct {
 	// Core message identifiers
@@ -468,10 +558,16 @@ type IncomingMessage struct {
 	Meta        json.RawMessage
 	Attachments attachment.Attachments
 
+	// Raw email headers retained for abuse investigations, keyed by canonical header name.
+	Headers map[string][]string
+
 	// Email threading
 	ConversationUUIDFromReplyTo string // UUID extracted from plus-addressed recipient (inbox+conv-{uuid}@domain)
 	InReplyTo                   string
 	References                  []string
+
+	// Trace context of the span that enqueued the message, so processing can be linked to it.
+	Trace tracing.Carrier
 }
 
 // ToMessage converts IncomingMessage to a Message for DB insertion.
@@ -517,6 +613,26 @@ type ConversationDraft struct {
 	CreatedAt        time.Time       `db:"created_at" json:"created_at"`
 	UpdatedAt        time.Time       `db:"updated_at" json:"updated_at"`
 	Meta             json.RawMessage `db:"meta" json:"meta"`
+	Version          int             `db:"version" json:"version"`
+	// Messages from others received since the draft was last saved.
+	NewMessageCount int `db:"new_message_count" json:"new_message_count"`
+}
+
+// TeamDraft is a reply draft shared with everyone who can access the conversation.
+// Only the agent holding the lock can edit it.
+type TeamDraft struct {
+	ID             int64           `db:"id" json:"id"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+	ConversationID int64           `db:"conversation_id" json:"-"`
+	Content        string          `db:"content" json:"content"`
+	Meta           json.RawMessage `db:"meta" json:"meta"`
+	Version        int             `db:"version" json:"version"`
+	CreatedByID    null.Int        `db:"created_by_id" json:"created_by_id"`
+	UpdatedByID    null.Int        `db:"updated_by_id" json:"updated_by_id"`
+	LockedByID     null.Int        `db:"locked_by_id" json:"locked_by_id"`
+	LockedByName   string          `db:"locked_by_name" json:"locked_by_name"`
+	LockedUntil    null.Time       `db:"locked_until" json:"locked_until"`
 }
 
 // MentionInput represents a mention in a private note from frontend.
@@ -524,3 +640,58 @@ type MentionInput struct {
 	Type string `json:"type"` // "agent" or "team"
 	ID   int    `json:"id"`
 }
+
+// BundleVersion is the version of the conversation bundle format written by this instance.
+const BundleVersion = 1
+
+// Bundle is the interchange format conversations are exported in and imported from, to move them
+// between instances.
+type Bundle struct {
+	Version      int                `json:"version"`
+	ExportedAt   time.Time          `json:"exported_at"`
+	Conversation BundleConversation `json:"conversation"`
+	Contact      BundleContact      `json:"contact"`
+	Tags         []string           `json:"tags"`
+	Messages     []BundleMessage    `json:"messages"`
+}
+
+type BundleConversation struct {
+	UUID            string    `json:"uuid"`
+	ReferenceNumber string    `json:"reference_number"`
+	Subject         string    `json:"subject"`
+	Status          string    `json:"status"`
+	Priority        string    `json:"priority"`
+	InboxName       string    `json:"inbox_name"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type BundleContact struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+type BundleMessage struct {
+	Type        string             `json:"type"`
+	SenderType  string             `json:"sender_type"`
+	AuthorName  string             `json:"author_name"`
+	AuthorEmail string             `json:"author_email"`
+	Private     bool               `json:"private"`
+	ContentType string             `json:"content_type"`
+	Content     string             `json:"content"`
+	SourceID    string             `json:"source_id,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Attachments []BundleAttachment `json:"attachments"`
+}
+
+// BundleAttachment is an entry of the attachments manifest of a bundle message. Data holds the
+// file content only if the bundle was exported with attachments.
+type BundleAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id,omitempty"`
+	Disposition string `json:"disposition,omitempty"`
+	Size        int    `json:"size"`
+	Data        []byte `json:"data,omitempty"`
+}
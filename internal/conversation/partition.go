@@ -0,0 +1,35 @@
+package conversation
+
+import (
+	"context"
+	"time"
+)
+
+// RunMessagePartitioner keeps the monthly partitions of the messages table created monthsAhead months in advance.
+// It is a no-op until the table is partitioned with `libredesk --partition-messages`.
+func (m *Manager) RunMessagePartitioner(ctx context.Context, monthsAhead int) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if err := m.CreateMessagePartitions(ctx, monthsAhead); err != nil {
+			m.lo.Error("error creating message partitions", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CreateMessagePartitions creates the missing monthly partitions of the messages table for the next monthsAhead months.
+func (m *Manager) CreateMessagePartitions(ctx context.Context, monthsAhead int) error {
+	var created int
+	if err := m.q.CreateMessagePartitions.GetContext(ctx, &created, monthsAhead); err != nil {
+		return err
+	}
+	if created > 0 {
+		m.lo.Info("created message partitions", "count", created)
+	}
+	return nil
+}
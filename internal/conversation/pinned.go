@@ -0,0 +1,54 @@
+package conversation
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// MaxPinnedMessages is the maximum number of messages that can be pinned in a conversation.
+const MaxPinnedMessages = 5
+
+// GetPinnedMessages returns the pinned messages of a conversation in the order they were pinned.
+func (m *Manager) GetPinnedMessages(conversationID int) ([]models.PinnedMessage, error) {
+	var pinned = make([]models.PinnedMessage, 0)
+	if err := m.q.GetPinnedMessages.Select(&pinned, conversationID); err != nil {
+		m.lo.Error("error fetching pinned messages", "conversation_id", conversationID, "error", err)
+		return pinned, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return pinned, nil
+}
+
+// PinMessage pins a message of a conversation. Pinning an already pinned message is a no-op.
+func (m *Manager) PinMessage(conversationUUID string, conversationID int, messageUUID string, userID int) ([]models.PinnedMessage, error) {
+	var messageID int
+	if err := m.q.PinMessage.Get(&messageID, messageUUID, userID, MaxPinnedMessages); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, envelope.NewError(envelope.InputError, m.i18n.Ts("conversation.maxPinnedMessages", "max", strconv.Itoa(MaxPinnedMessages)), nil)
+		}
+		m.lo.Error("error pinning message", "message_uuid", messageUUID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastPinnedMessages(conversationUUID, conversationID)
+}
+
+// UnpinMessage unpins a message of a conversation.
+func (m *Manager) UnpinMessage(conversationUUID string, conversationID int, messageUUID string) ([]models.PinnedMessage, error) {
+	if _, err := m.q.UnpinMessage.Exec(messageUUID); err != nil {
+		m.lo.Error("error unpinning message", "message_uuid", messageUUID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastPinnedMessages(conversationUUID, conversationID)
+}
+
+// broadcastPinnedMessages sends the current pinned messages of a conversation to subscribers and returns them.
+func (m *Manager) broadcastPinnedMessages(conversationUUID string, conversationID int) ([]models.PinnedMessage, error) {
+	pinned, err := m.GetPinnedMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	m.BroadcastConversationUpdate(conversationUUID, map[string]any{"pinned_messages": pinned})
+	return pinned, nil
+}
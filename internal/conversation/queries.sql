@@ -1,6 +1,6 @@
 -- name: unsnooze-all
 UPDATE conversations
-SET snoozed_until = NULL, status_id = (SELECT id FROM conversation_statuses WHERE name = 'Open')
+SET snoozed_until = NULL, status_id = (SELECT id FROM conversation_statuses WHERE name = 'Open'), status_changed_at = NOW()
 WHERE snoozed_until <= NOW()
   AND status_id = (SELECT id FROM conversation_statuses WHERE name = 'Snoozed');
 
@@ -33,10 +33,20 @@ WHERE $12::int = 0 OR (SELECT COUNT(*) FROM conversations WHERE contact_id = $1
 RETURNING id, uuid;
 
 -- name: get-conversations
+-- Selects a page of conversation IDs, the filters, ordering and pagination are appended by the list query builder.
+-- The columns of the listed conversations are then fetched for the page only by get-conversation-list-items.
+SELECT COUNT(*) OVER() as total, conversations.id
+FROM conversations
+JOIN users ON contact_id = users.id
+JOIN inboxes ON inbox_id = inboxes.id
+LEFT JOIN conversation_statuses ON status_id = conversation_statuses.id
+WHERE 1=1 %s
+
+-- name: get-conversation-list-items
 -- $1 = viewing user ID for per-agent unread count
 -- $2 = include mentioned message UUID (true for mentioned inbox, false otherwise)
+-- $3 = conversation IDs of the page, in list order
 SELECT
-    COUNT(*) OVER() as total,
     conversations.id,
     conversations.created_at,
     conversations.updated_at,
@@ -56,6 +66,7 @@ SELECT
     conversations.last_reply_at,
     conversations.resolved_at,
     conversations.subject,
+    conversations.summary,
     conversations.last_message,
     conversations.last_message_at,
     conversations.last_message_sender,
@@ -116,7 +127,8 @@ SELECT
         ORDER BY se.created_at DESC
         LIMIT 1
     ) nxt_resp_event ON true
-WHERE 1=1 %s
+WHERE conversations.id = ANY($3::BIGINT[])
+ORDER BY array_position($3::BIGINT[], conversations.id);
 
 -- name: get-conversation
 SELECT
@@ -129,6 +141,7 @@ SELECT
    inb.name as inbox_name,
    COALESCE(inb.from, '') as inbox_mail,
    COALESCE(inb.config->>'reply_to', '') as inbox_reply_to,
+   ARRAY(SELECT jsonb_array_elements_text(CASE WHEN jsonb_typeof(inb.config->'previous_addresses') = 'array' THEN inb.config->'previous_addresses' ELSE '[]'::JSONB END)) as inbox_previous_addresses,
    COALESCE(inb.channel::TEXT, '') as inbox_channel,
    c.status_id,
    c.priority_id,
@@ -139,9 +152,12 @@ SELECT
    c.first_reply_at,
    c.last_reply_at,
    c.waiting_since,
+   c.status_changed_at,
+   c.trashed_at,
    c.assigned_user_id,
    c.assigned_team_id,
    c.subject,
+   c.summary,
    c.contact_id,
    c.sla_policy_id,
    c.meta,
@@ -177,6 +193,7 @@ SELECT
    ct.last_active_at as "contact.last_active_at",
    ct.last_login_at as "contact.last_login_at",
    ct.external_user_id as "contact.external_user_id",
+   ct.risk_flags as "contact.risk_flags",
    as_latest.first_response_deadline_at,
    as_latest.resolution_deadline_at,
    as_latest.id as applied_sla_id,
@@ -212,12 +229,15 @@ WHERE
   ($3::TEXT != '' AND c.reference_number = $3::TEXT)
 
 
--- name: get-conversations-created-after
+-- name: get-time-trigger-conversations
+-- Conversations that are not resolved or have had activity since $1.
 SELECT
     c.id,
     c.uuid
 FROM conversations c
-WHERE c.created_at > $1;
+WHERE c.created_at > $1
+    OR c.last_message_at > $1
+    OR c.status_id IN (SELECT id FROM conversation_statuses WHERE category != 'resolved');
 
 -- name: get-contact-previous-conversations
 SELECT
@@ -237,6 +257,28 @@ WHERE c.contact_id = $1
 ORDER BY c.created_at DESC
 LIMIT $2;
 
+-- name: get-related-conversations
+-- Other conversations of the same contact or with a similar subject (pg_trgm similarity), closest first.
+SELECT
+    c.id,
+    c.created_at,
+    c.uuid,
+    c.reference_number,
+    COALESCE(c.subject, '') AS subject,
+    s.name AS status,
+    c.assigned_user_id,
+    c.assigned_team_id,
+    c.last_message_at,
+    (c.contact_id = cur.contact_id) AS same_contact,
+    similarity(COALESCE(c.subject, ''), COALESCE(cur.subject, '')) AS subject_similarity
+FROM conversations cur
+JOIN conversations c ON c.id <> cur.id
+LEFT JOIN conversation_statuses s ON s.id = c.status_id
+WHERE cur.id = $1
+AND (c.contact_id = cur.contact_id OR (COALESCE(cur.subject, '') <> '' AND c.subject % cur.subject))
+ORDER BY same_contact DESC, subject_similarity DESC, c.created_at DESC
+LIMIT $2;
+
 -- name: get-chat-conversation
 SELECT
     c.created_at,
@@ -311,9 +353,34 @@ WHERE c.contact_id = $1 AND c.inbox_id = $2
 ORDER BY c.created_at DESC
 LIMIT 200;
 
+-- name: get-contact-active-chat-conversation
+SELECT c.uuid
+FROM conversations c
+INNER JOIN conversation_statuses cs ON c.status_id = cs.id
+WHERE c.contact_id = $1 AND c.inbox_id = $2
+  AND cs.name NOT IN ('Resolved', 'Closed')
+ORDER BY c.last_message_at DESC NULLS LAST, c.created_at DESC
+LIMIT 1;
+
 -- name: get-conversation-uuid
 SELECT uuid from conversations where id = $1;
 
+-- name: get-conversation-uuids-by-reference-numbers
+SELECT reference_number, uuid FROM conversations WHERE reference_number = ANY($1::TEXT[]);
+
+-- name: get-conversation-uuid-by-order-number
+-- The attribute key is fixed to order_number, lookups use the index_conversations_on_order_number expression index.
+SELECT uuid FROM conversations
+WHERE custom_attributes->>'order_number' = $1 AND trashed_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: update-conversation-summary
+UPDATE conversations
+SET summary = $2,
+updated_at = NOW()
+WHERE uuid = $1;
+
 -- name: update-conversation-assigned-user
 UPDATE conversations
 SET assigned_user_id = $2,
@@ -342,6 +409,7 @@ SET status_id     = (SELECT id FROM new_status),
     resolved_at   = COALESCE(resolved_at, CASE WHEN (SELECT category FROM new_status) = 'resolved' THEN NOW() END),
     closed_at     = COALESCE(closed_at,   CASE WHEN $2 = 'Closed'                                  THEN NOW() END),
     snoozed_until = CASE WHEN $2 = 'Snoozed' THEN $3::timestamptz ELSE NULL END,
+    status_changed_at = CASE WHEN status_id IS DISTINCT FROM (SELECT id FROM new_status) THEN NOW() ELSE status_changed_at END,
     updated_at    = NOW()
 WHERE uuid = $1;
 
@@ -457,6 +525,20 @@ SET assigned_user_id = NULL,
     updated_at = NOW()
 WHERE assigned_user_id = $1 AND status_id IN (SELECT id FROM conversation_statuses WHERE category != 'resolved');
 
+-- name: get-conversation-custom-attribute-definitions
+SELECT
+    id,
+    name,
+    key,
+    values,
+    data_type,
+    COALESCE(regex, '') AS regex,
+    required
+FROM
+    custom_attribute_definitions
+WHERE
+    applies_to = 'conversation';
+
 -- name: update-conversation-custom-attributes
 UPDATE conversations
 SET custom_attributes = $2,
@@ -495,6 +577,7 @@ UPDATE conversations
 SET 
   status_id = (SELECT id FROM conversation_statuses WHERE name = 'Open'),
   snoozed_until = NULL,
+  status_changed_at = NOW(),
   updated_at = NOW(),
   assigned_user_id = CASE
     WHEN EXISTS (
@@ -523,6 +606,68 @@ WHERE m.id = $1;
 -- name: delete-conversation
 DELETE FROM conversations WHERE uuid = $1;
 
+-- name: trash-conversation
+UPDATE conversations SET trashed_at = NOW(), updated_at = NOW()
+WHERE uuid = $1 AND trashed_at IS NULL
+RETURNING trashed_at;
+
+-- name: restore-conversation
+UPDATE conversations SET trashed_at = NULL, updated_at = NOW()
+WHERE uuid = $1 AND trashed_at IS NOT NULL;
+
+-- name: purge-trashed-conversations
+DELETE FROM conversations WHERE trashed_at < $1;
+
+-- name: create-message-partitions
+SELECT create_message_partitions($1);
+
+-- name: get-archivable-conversations
+-- Resolved conversations without messages since $1.
+SELECT c.id FROM conversations c
+JOIN conversation_statuses s ON s.id = c.status_id
+WHERE s.category = 'resolved'
+  AND c.archived_at IS NULL
+  AND c.trashed_at IS NULL
+  AND COALESCE(c.last_message_at, c.created_at) < $1
+ORDER BY c.id
+LIMIT $2;
+
+-- name: lock-conversation-for-archive
+-- $2 = true locks an unarchived conversation to archive it, false an archived one to restore it.
+SELECT id FROM conversations
+WHERE id = $1 AND (archived_at IS NULL) = $2
+FOR UPDATE;
+
+-- name: get-archive-message-bodies
+SELECT id, COALESCE("content", '') AS "content", COALESCE(text_content, '') AS text_content
+FROM conversation_messages
+WHERE conversation_id = $1 AND ("content" IS NOT NULL OR text_content IS NOT NULL);
+
+-- name: insert-message-archive
+WITH archived AS (
+    INSERT INTO conversation_message_archives (message_id, body) VALUES ($1, $2)
+    ON CONFLICT (message_id) DO UPDATE SET body = EXCLUDED.body
+)
+UPDATE conversation_messages SET "content" = NULL, text_content = NULL WHERE id = $1;
+
+-- name: get-message-archives
+SELECT a.message_id, a.body
+FROM conversation_message_archives a
+JOIN conversation_messages m ON m.id = a.message_id
+WHERE m.conversation_id = $1;
+
+-- name: restore-message-archive
+WITH restored AS (
+    DELETE FROM conversation_message_archives WHERE message_id = $1
+)
+UPDATE conversation_messages SET "content" = $2, text_content = $3 WHERE id = $1;
+
+-- name: set-conversation-archived
+UPDATE conversations SET archived_at = CASE WHEN $2 THEN NOW() ELSE NULL END WHERE id = $1;
+
+-- name: get-archived-conversation-id
+SELECT id FROM conversations WHERE uuid = $1 AND archived_at IS NOT NULL;
+
 -- MESSAGE queries.
 -- name: delete-message
 DELETE FROM conversation_messages WHERE CASE 
@@ -540,7 +685,51 @@ and source_id > ''
 ORDER BY id DESC
 LIMIT $2;
 
+-- name: get-inbox-outbox-depth
+-- Pending outgoing messages of an inbox by send priority, see send_priority in schema.sql.
+SELECT
+    COUNT(*) AS pending,
+    COUNT(*) FILTER (WHERE m.send_priority = 0) AS high_priority,
+    COUNT(*) FILTER (WHERE m.send_priority > 0) AS low_priority,
+    MIN(m.created_at) AS oldest_pending_at
+FROM conversation_messages m
+JOIN conversations c ON c.id = m.conversation_id
+WHERE c.inbox_id = $1 AND m.status = 'pending' AND m.type = 'outgoing' AND m.private = false;
+
+-- name: extend-outgoing-message-claim
+-- Renews the claim $3 of a pending outgoing message for $2 seconds when a worker starts sending it.
+-- Nothing is updated if the claim lapsed or was taken over by another instance.
+UPDATE conversation_messages SET send_claimed_until = NOW() + MAKE_INTERVAL(secs => $2)
+WHERE id = $1 AND status = 'pending' AND send_claimed_until > NOW() AND send_claim_token = $3;
+
+-- name: release-outgoing-message
+-- Releases the claim $2 of a pending outgoing message so it is picked up by a later scan.
+UPDATE conversation_messages SET send_claimed_until = NULL, send_claim_token = NULL
+WHERE id = $1 AND status = 'pending' AND send_claim_token = $2;
+
 -- name: get-outgoing-pending-messages
+-- Claims up to $2 pending outgoing messages for $3 seconds so that multiple app instances can send concurrently.
+-- Rows locked by another instance are skipped, and a claim lapses after $3 so crashed senders are retried.
+WITH claimable AS (
+    SELECT id
+    FROM conversation_messages
+    WHERE status = 'pending' AND type = 'outgoing' AND private = false
+    AND (send_claimed_until IS NULL OR send_claimed_until < NOW())
+    AND NOT(id = ANY($1::INT[]))
+    -- Inboxes at their send limits are skipped.
+    AND NOT(conversation_id = ANY(SELECT id FROM conversations WHERE inbox_id = ANY($4::INT[])))
+    -- Agent replies are sent before CSAT and automated emails queued earlier.
+    ORDER BY send_priority, id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+),
+claimed AS (
+    UPDATE conversation_messages
+    SET send_claimed_until = NOW() + MAKE_INTERVAL(secs => $3), send_claim_token = gen_random_uuid()
+    FROM claimable
+    WHERE conversation_messages.id = claimable.id
+    RETURNING conversation_messages.id, conversation_messages.send_claim_token
+)
 SELECT
     m.id,
     m.created_at,
@@ -557,6 +746,7 @@ SELECT
     m.sender_type,
     m.sender_id,
     m.meta,
+    m.thread_id,
     c.uuid as conversation_uuid,
     m.content_type,
     m.source_id,
@@ -568,11 +758,12 @@ SELECT
     c.uuid as conversation_uuid,
     c.subject,
     c.contact_id as message_receiver_id,
-    c.subject
-FROM conversation_messages m
+    c.subject,
+    claimed.send_claim_token
+FROM claimed
+INNER JOIN conversation_messages m ON m.id = claimed.id
 INNER JOIN conversations c ON c.id = m.conversation_id
-WHERE m.status = 'pending' AND m.type = 'outgoing' AND m.private = false
-AND NOT(m.id = ANY($1::INT[]))
+ORDER BY m.id;
 
 -- name: get-message
 SELECT
@@ -591,6 +782,7 @@ SELECT
     m.sender_id,
     m.meta,
     c.uuid as conversation_uuid,
+    c.archived_at IS NOT NULL AS conversation_archived,
     u.id AS "author.id",
     u.first_name AS "author.first_name",
     u.last_name AS "author.last_name",
@@ -611,14 +803,23 @@ SELECT
             ) ORDER BY media.filename
         ) FILTER (WHERE media.id IS NOT NULL),
         '[]'::json
-    ) AS attachments
+    ) AS attachments,
+    COALESCE(
+      (SELECT json_agg(json_build_object('emoji', r.emoji, 'user_ids', r.user_ids) ORDER BY r.first_at)
+       FROM (
+         SELECT emoji, array_agg(user_id ORDER BY created_at) AS user_ids, MIN(created_at) AS first_at
+         FROM conversation_message_reactions
+         WHERE message_id = m.id
+         GROUP BY emoji
+       ) r),
+    '[]'::json) AS reactions
 FROM conversation_messages m
 INNER JOIN conversations c ON c.id = m.conversation_id
 JOIN users u ON m.sender_id = u.id
 LEFT JOIN media ON media.model_type = 'messages' AND media.model_id = m.id
 WHERE m.uuid = $1
 GROUP BY
-    m.id, m.created_at, m.updated_at, m.status, m.type, m.content, m.uuid, m.private, m.sender_type, c.uuid,
+    m.id, m.created_at, m.updated_at, m.status, m.type, m.content, m.uuid, m.private, m.sender_type, c.uuid, c.archived_at,
     u.id, u.first_name, u.last_name, u.email, u.avatar_url, u.availability_status, u.type, u.last_active_at
 ORDER BY m.created_at;
 
@@ -639,6 +840,7 @@ SELECT
    m.sender_id,
    m.sender_type,
    m.meta,
+   m.thread_id,
    $1::uuid AS conversation_uuid,
    u.id AS "author.id",
    u.first_name AS "author.first_name",
@@ -660,7 +862,16 @@ SELECT
        ) ORDER BY filename
      ) FROM media
      WHERE model_type = 'messages' AND model_id = m.id),
-   '[]'::json) AS attachments
+   '[]'::json) AS attachments,
+   COALESCE(
+     (SELECT json_agg(json_build_object('emoji', r.emoji, 'user_ids', r.user_ids) ORDER BY r.first_at)
+      FROM (
+        SELECT emoji, array_agg(user_id ORDER BY created_at) AS user_ids, MIN(created_at) AS first_at
+        FROM conversation_message_reactions
+        WHERE message_id = m.id
+        GROUP BY emoji
+      ) r),
+   '[]'::json) AS reactions
 FROM conversation_messages m
 JOIN users u ON m.sender_id = u.id
 WHERE m.conversation_id = (
@@ -668,6 +879,8 @@ WHERE m.conversation_id = (
 )
 AND ($2::boolean IS NULL OR m.private = $2)
 AND ($3::text[] IS NULL OR m.type::text = ANY($3))
+-- Thread notes are kept off the main timeline and listed per thread.
+AND (CASE WHEN $4::bigint > 0 THEN m.thread_id = $4 ELSE m.thread_id IS NULL END)
 AND (m.meta IS NULL OR NOT COALESCE((m.meta->>'continuity_email')::boolean, false))
 ORDER BY m.created_at DESC %s
 
@@ -684,11 +897,13 @@ inserted_msg AS (
    INSERT INTO conversation_messages (
        "type", status, conversation_id, "content",
        text_content, sender_id, sender_type, private,
-       content_type, source_id, meta
+       content_type, source_id, meta, thread_id,
+       send_priority
    )
    VALUES (
        $1, $2, (SELECT id FROM conversation_id),
-       $5, $6, $7, $8, $9, $10, $11, $12
+       $5, $6, $7, $8, $9, $10, $11, $12, $13,
+       $14
    )
    RETURNING *
 )
@@ -700,7 +915,19 @@ FROM conversation_messages
 WHERE source_id = ANY($1::text []);
 
 -- name: update-message-status
-update conversation_messages set status = $1, updated_at = NOW() where uuid = $2;
+update conversation_messages set status = $1, send_claimed_until = NULL, send_claim_token = NULL, updated_at = NOW() where uuid = $2;
+
+-- name: set-message-read-receipt
+-- Only the first receipt of a message is kept, MDNs are matched again on every mailbox scan.
+UPDATE conversation_messages m
+SET meta = COALESCE(m.meta, '{}'::jsonb) || jsonb_build_object('read_receipt', jsonb_build_object('disposition', $2::TEXT, 'from', $3::TEXT, 'received_at', NOW())),
+    updated_at = NOW()
+FROM conversations c
+WHERE m.source_id = $1
+    AND m.type = 'outgoing'
+    AND c.id = m.conversation_id
+    AND m.meta->'read_receipt' IS NULL
+RETURNING m.uuid, c.uuid AS conversation_uuid, m.meta;
 
 -- name: get-latest-message
 SELECT
@@ -802,19 +1029,34 @@ SET last_continuity_email_sent_at = NOW(),
 WHERE id = $1;
 
 -- name: upsert-conversation-draft
-INSERT INTO conversation_drafts (conversation_id, user_id, content, meta, updated_at)
-VALUES ($1, $2, $3, $4, NOW())
+-- $5 = draft version the client last saw, 0 to overwrite unconditionally. Returns no rows if the version is stale.
+INSERT INTO conversation_drafts (conversation_id, user_id, content, meta, updated_at, last_message_id)
+VALUES ($1, $2, $3, $4, NOW(), (SELECT MAX(id) FROM conversation_messages WHERE conversation_id = $1))
 ON CONFLICT (conversation_id, user_id)
-DO UPDATE SET content = EXCLUDED.content, meta = EXCLUDED.meta, updated_at = NOW()
+DO UPDATE SET content = EXCLUDED.content, meta = EXCLUDED.meta, updated_at = NOW(),
+    last_message_id = EXCLUDED.last_message_id, version = conversation_drafts.version + 1
+WHERE $5 = 0 OR conversation_drafts.version = $5
 RETURNING *;
 
 -- name: get-all-user-drafts
-SELECT cd.id, cd.conversation_id, cd.user_id, cd.content, cd.meta, cd.created_at, cd.updated_at, c.uuid as conversation_uuid
+SELECT cd.id, cd.conversation_id, cd.user_id, cd.content, cd.meta, cd.created_at, cd.updated_at, cd.version, c.uuid as conversation_uuid,
+    (SELECT COUNT(*) FROM conversation_messages m
+     WHERE m.conversation_id = cd.conversation_id AND m.id > COALESCE(cd.last_message_id, 0)
+     AND m.type != 'activity' AND m.sender_id != cd.user_id) AS new_message_count
 FROM conversation_drafts cd
 INNER JOIN conversations c ON cd.conversation_id = c.id
 WHERE cd.user_id = $1
 ORDER BY cd.updated_at DESC;
 
+-- name: get-conversation-draft
+SELECT cd.id, cd.conversation_id, cd.user_id, cd.content, cd.meta, cd.created_at, cd.updated_at, cd.version, c.uuid as conversation_uuid,
+    (SELECT COUNT(*) FROM conversation_messages m
+     WHERE m.conversation_id = cd.conversation_id AND m.id > COALESCE(cd.last_message_id, 0)
+     AND m.type != 'activity' AND m.sender_id != cd.user_id) AS new_message_count
+FROM conversation_drafts cd
+INNER JOIN conversations c ON cd.conversation_id = c.id
+WHERE cd.conversation_id = $1 AND cd.user_id = $2;
+
 -- name: delete-conversation-draft
 DELETE FROM conversation_drafts
 WHERE conversation_id IN (
@@ -826,6 +1068,51 @@ WHERE conversation_id IN (
 DELETE FROM conversation_drafts
 WHERE created_at < $1;
 
+-- name: get-team-draft
+SELECT td.id, td.created_at, td.updated_at, td.conversation_id, td.content, td.meta, td.version,
+    td.created_by_id, td.updated_by_id,
+    CASE WHEN td.locked_until > NOW() THEN td.locked_by_id END AS locked_by_id,
+    CASE WHEN td.locked_until > NOW() THEN td.locked_until END AS locked_until,
+    CASE WHEN td.locked_until > NOW() THEN TRIM(CONCAT(u.first_name, ' ', u.last_name)) ELSE '' END AS locked_by_name
+FROM conversation_team_drafts td
+LEFT JOIN users u ON u.id = td.locked_by_id
+WHERE td.conversation_id = $1;
+
+-- name: promote-draft
+-- Copies the user's ($2) draft into the team draft unless another agent holds its lock. The promoting agent keeps the lock.
+INSERT INTO conversation_team_drafts (conversation_id, content, meta, created_by_id, updated_by_id, locked_by_id, locked_until)
+SELECT d.conversation_id, d.content, d.meta, $2, $2, $2, NOW() + make_interval(secs => $3)
+FROM conversation_drafts d
+WHERE d.conversation_id = $1 AND d.user_id = $2
+ON CONFLICT (conversation_id) DO UPDATE
+SET content = EXCLUDED.content, meta = EXCLUDED.meta, updated_by_id = $2, updated_at = NOW(),
+    version = conversation_team_drafts.version + 1, locked_by_id = $2, locked_until = EXCLUDED.locked_until
+WHERE conversation_team_drafts.locked_by_id IS NULL OR conversation_team_drafts.locked_until < NOW() OR conversation_team_drafts.locked_by_id = $2
+RETURNING id;
+
+-- name: lock-team-draft
+UPDATE conversation_team_drafts
+SET locked_by_id = $2, locked_until = NOW() + make_interval(secs => $3)
+WHERE conversation_id = $1 AND (locked_by_id IS NULL OR locked_until < NOW() OR locked_by_id = $2)
+RETURNING id;
+
+-- name: unlock-team-draft
+UPDATE conversation_team_drafts
+SET locked_by_id = NULL, locked_until = NULL
+WHERE conversation_id = $1 AND locked_by_id = $2;
+
+-- name: update-team-draft
+-- Saves the team draft if the user ($2) holds the lock, renewing it.
+UPDATE conversation_team_drafts
+SET content = $3, meta = $4, updated_by_id = $2, updated_at = NOW(), version = version + 1,
+    locked_until = NOW() + make_interval(secs => $5)
+WHERE conversation_id = $1 AND locked_by_id = $2 AND locked_until > NOW()
+RETURNING id;
+
+-- name: delete-team-draft
+DELETE FROM conversation_team_drafts
+WHERE conversation_id = $1 AND (locked_by_id IS NULL OR locked_until < NOW() OR locked_by_id = $2);
+
 -- name: insert-mention
 INSERT INTO conversation_mentions (conversation_id, message_id, mentioned_user_id, mentioned_team_id, mentioned_by_user_id)
 VALUES ($1, $2, $3, $4, $5);
@@ -858,3 +1145,352 @@ WHERE c.assigned_user_id = $1
   AND u.availability_status = 'online'
 ORDER BY c.last_interaction_at DESC
 LIMIT 50;
+
+-- name: insert-staged-incoming-message
+INSERT INTO incoming_messages (inbox_id, payload) VALUES ($1, $2);
+
+-- name: claim-staged-incoming-messages
+-- Claims up to $1 staged incoming messages for $2 seconds, rows claimed by another instance are skipped.
+-- Messages that failed $3 times are left for inspection.
+WITH claimable AS (
+    SELECT id
+    FROM incoming_messages
+    WHERE (claimed_until IS NULL OR claimed_until < NOW())
+    AND attempts < $3
+    ORDER BY id
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE incoming_messages
+SET claimed_until = NOW() + MAKE_INTERVAL(secs => $2)
+FROM claimable
+WHERE incoming_messages.id = claimable.id
+RETURNING incoming_messages.id, incoming_messages.payload;
+
+-- name: retry-staged-incoming-message
+-- Records a failed attempt and holds the message back for 30 seconds doubling with every attempt, capped at an hour.
+UPDATE incoming_messages
+SET attempts = attempts + 1,
+    last_error = $2,
+    claimed_until = NOW() + LEAST(MAKE_INTERVAL(secs => 30 * POWER(2, attempts)), INTERVAL '1 hour')
+WHERE id = $1;
+
+-- name: delete-staged-incoming-message
+DELETE FROM incoming_messages WHERE id = $1;
+
+-- name: get-conversation-stats
+-- Computed metrics for a single conversation. Agent response times are measured from the first
+-- incoming message of an unanswered run to the next public reply. Status history is rebuilt from
+-- status change activities, older activities without meta fall back to the activity text.
+WITH conv AS (
+    SELECT id, created_at, first_reply_at, resolved_at FROM conversations WHERE uuid = $1
+),
+msgs AS (
+    SELECT m.type, m.private, m.created_at, m.content, m.meta
+    FROM conversation_messages m
+    JOIN conv ON m.conversation_id = conv.id
+),
+exchange AS (
+    SELECT type, created_at, LAG(type) OVER (ORDER BY created_at) AS prev_type
+    FROM msgs
+    WHERE type IN ('incoming', 'outgoing') AND private = false
+),
+runs AS (
+    SELECT *, SUM(CASE WHEN type = 'incoming' AND prev_type IS DISTINCT FROM 'incoming' THEN 1 ELSE 0 END)
+        OVER (ORDER BY created_at) AS run
+    FROM exchange
+),
+responses AS (
+    SELECT EXTRACT(EPOCH FROM (r.created_at - (
+        SELECT MIN(i.created_at) FROM runs i WHERE i.run = r.run AND i.type = 'incoming'
+    ))) AS secs
+    FROM runs r
+    WHERE r.type = 'outgoing' AND r.prev_type = 'incoming'
+),
+status_changes AS (
+    SELECT created_at,
+        COALESCE(meta->>'activity_value', substring(content FROM 'marked the conversation as (.+)$')) AS status
+    FROM msgs
+    WHERE type = 'activity'
+    AND (meta->>'activity_type' = 'status_change' OR (meta->>'activity_type' IS NULL AND content LIKE '% marked the conversation as %'))
+),
+timeline AS (
+    SELECT 'Open' AS status, (SELECT created_at FROM conv) AS started_at
+    UNION ALL
+    SELECT status, created_at FROM status_changes
+),
+segments AS (
+    SELECT status, started_at,
+        LAG(status) OVER (ORDER BY started_at) AS prev_status,
+        COALESCE(LEAD(started_at) OVER (ORDER BY started_at), NOW()) AS ended_at
+    FROM timeline
+)
+SELECT json_build_object(
+    'messages', (
+        SELECT json_build_object(
+            'total', COUNT(*),
+            'incoming', COUNT(*) FILTER (WHERE type = 'incoming'),
+            'outgoing', COUNT(*) FILTER (WHERE type = 'outgoing' AND private = false),
+            'private_notes', COUNT(*) FILTER (WHERE type = 'outgoing' AND private = true),
+            'activity', COUNT(*) FILTER (WHERE type = 'activity')
+        ) FROM msgs
+    ),
+    'first_response_sec', (SELECT EXTRACT(EPOCH FROM (first_reply_at - created_at))::BIGINT FROM conv),
+    'resolution_sec', (SELECT EXTRACT(EPOCH FROM (resolved_at - created_at))::BIGINT FROM conv),
+    'response_times', (
+        SELECT json_build_object(
+            'count', COUNT(*),
+            'avg_sec', COALESCE(ROUND(AVG(secs)), 0)::BIGINT,
+            'median_sec', COALESCE(ROUND(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY secs)), 0)::BIGINT,
+            'max_sec', COALESCE(ROUND(MAX(secs)), 0)::BIGINT
+        ) FROM responses
+    ),
+    'reopen_count', (
+        SELECT COUNT(*) FROM segments s
+        WHERE s.prev_status IN (SELECT name FROM conversation_statuses WHERE category = 'resolved')
+        AND s.status NOT IN (SELECT name FROM conversation_statuses WHERE category = 'resolved')
+    ),
+    'time_in_status', (
+        SELECT COALESCE(json_agg(json_build_object('status', status, 'seconds', secs) ORDER BY secs DESC), '[]'::json)
+        FROM (
+            SELECT status, SUM(EXTRACT(EPOCH FROM (ended_at - started_at)))::BIGINT AS secs
+            FROM segments
+            GROUP BY status
+        ) t
+    )
+)
+FROM conv;
+
+-- name: insert-message-headers
+INSERT INTO conversation_message_headers (message_id, headers)
+VALUES ($1, $2)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: get-message-headers
+SELECT h.created_at, h.headers
+FROM conversation_message_headers h
+JOIN conversation_messages m ON m.id = h.message_id
+WHERE m.uuid = $1;
+
+-- name: delete-expired-message-headers
+DELETE FROM conversation_message_headers
+WHERE created_at < $1;
+
+-- name: insert-handoff-note
+WITH deactivated AS (
+    UPDATE conversation_handoff_notes SET active = false
+    WHERE conversation_id = $1 AND active
+)
+INSERT INTO conversation_handoff_notes (conversation_id, from_user_id, to_user_id, summary, current_state, next_step)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: get-active-handoff-note
+SELECT n.id, n.created_at, n.conversation_id, n.from_user_id, n.to_user_id, n.summary, n.current_state, n.next_step,
+    COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), '') AS from_user_name
+FROM conversation_handoff_notes n
+JOIN conversations c ON c.id = n.conversation_id
+LEFT JOIN users u ON u.id = n.from_user_id
+WHERE n.conversation_id = $1 AND n.active AND n.to_user_id = c.assigned_user_id;
+
+-- name: deactivate-handoff-notes
+UPDATE conversation_handoff_notes SET active = false
+WHERE conversation_id = (SELECT id FROM conversations WHERE uuid = $1) AND active AND to_user_id <> $2;
+
+-- name: get-threads
+SELECT t.id, t.created_at, t.updated_at, t.conversation_id, t.name, t.created_by_id, t.resolved_at, t.resolved_by_id,
+    COALESCE(ARRAY(SELECT p.user_id FROM conversation_thread_participants p WHERE p.thread_id = t.id ORDER BY p.created_at), '{}') AS participant_ids,
+    (SELECT COUNT(*) FROM conversation_messages m WHERE m.thread_id = t.id) AS message_count
+FROM conversation_threads t
+WHERE t.conversation_id = $1
+ORDER BY t.resolved_at IS NOT NULL, t.updated_at DESC;
+
+-- name: get-thread
+SELECT t.id, t.created_at, t.updated_at, t.conversation_id, t.name, t.created_by_id, t.resolved_at, t.resolved_by_id,
+    COALESCE(ARRAY(SELECT p.user_id FROM conversation_thread_participants p WHERE p.thread_id = t.id ORDER BY p.created_at), '{}') AS participant_ids,
+    (SELECT COUNT(*) FROM conversation_messages m WHERE m.thread_id = t.id) AS message_count
+FROM conversation_threads t
+WHERE t.id = $1;
+
+-- name: insert-thread
+INSERT INTO conversation_threads (conversation_id, name, created_by_id)
+VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: update-thread
+UPDATE conversation_threads
+SET name = $2,
+    resolved_at = CASE WHEN $3 THEN COALESCE(resolved_at, NOW()) ELSE NULL END,
+    resolved_by_id = CASE WHEN $3 THEN COALESCE(resolved_by_id, $4) ELSE NULL END,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: touch-thread
+UPDATE conversation_threads SET updated_at = NOW() WHERE id = $1;
+
+-- name: set-thread-participants
+WITH deleted AS (
+    DELETE FROM conversation_thread_participants
+    WHERE thread_id = $1 AND user_id <> ALL($2::int[])
+)
+INSERT INTO conversation_thread_participants (thread_id, user_id)
+SELECT $1, unnest($2::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: add-thread-participant
+INSERT INTO conversation_thread_participants (thread_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: insert-message-reaction
+-- Reactions are only allowed on private notes.
+INSERT INTO conversation_message_reactions (message_id, user_id, emoji)
+SELECT id, $2, $3 FROM conversation_messages WHERE uuid = $1 AND private = true
+ON CONFLICT DO NOTHING;
+
+-- name: delete-message-reaction
+DELETE FROM conversation_message_reactions
+WHERE message_id = (SELECT id FROM conversation_messages WHERE uuid = $1) AND user_id = $2 AND emoji = $3;
+
+-- name: get-message-reactions
+SELECT COALESCE(json_agg(json_build_object('emoji', r.emoji, 'user_ids', r.user_ids) ORDER BY r.first_at), '[]'::json)
+FROM (
+    SELECT emoji, array_agg(user_id ORDER BY created_at) AS user_ids, MIN(created_at) AS first_at
+    FROM conversation_message_reactions
+    WHERE message_id = (SELECT id FROM conversation_messages WHERE uuid = $1)
+    GROUP BY emoji
+) r;
+
+-- name: pin-message
+-- Pins a message unless the conversation already has $3 pinned messages. Returns no rows if not pinned.
+INSERT INTO conversation_pinned_messages (message_id, conversation_id, pinned_by_id)
+SELECT m.id, m.conversation_id, $2
+FROM conversation_messages m
+WHERE m.uuid = $1 AND m.type != 'activity'
+AND (SELECT COUNT(*) FROM conversation_pinned_messages p WHERE p.conversation_id = m.conversation_id) < $3
+ON CONFLICT (message_id) DO UPDATE SET message_id = EXCLUDED.message_id
+RETURNING message_id;
+
+-- name: unpin-message
+DELETE FROM conversation_pinned_messages
+WHERE message_id = (SELECT id FROM conversation_messages WHERE uuid = $1);
+
+-- name: get-pinned-messages
+SELECT
+    m.uuid,
+    m.created_at,
+    m.type,
+    m.private,
+    m.text_content,
+    TRIM(CONCAT(u.first_name, ' ', u.last_name)) AS author_name,
+    p.created_at AS pinned_at,
+    p.pinned_by_id
+FROM conversation_pinned_messages p
+JOIN conversation_messages m ON m.id = p.message_id
+JOIN users u ON u.id = m.sender_id
+WHERE p.conversation_id = $1
+ORDER BY p.created_at;
+
+-- name: get-conversation-ids-created-between
+SELECT id FROM conversations
+WHERE created_at >= $1 AND created_at < $2 AND id > $3
+ORDER BY id
+LIMIT $4;
+
+-- name: count-conversations-created-between
+SELECT COUNT(*) FROM conversations WHERE created_at >= $1 AND created_at < $2;
+
+-- name: recalculate-conversation-metrics
+-- Recomputes the reply, waiting and resolution timestamps of conversations from their messages. Replies are sent
+-- outgoing messages of agents other than the system user ($2), resolution and closing times are read from the
+-- status change activities. The stored resolution and closing times are kept for conversations without any status change.
+WITH replies AS (
+    SELECT conversation_id, MIN(created_at) AS first_reply_at, MAX(created_at) AS last_reply_at
+    FROM conversation_messages
+    WHERE conversation_id = ANY($1::BIGINT[]) AND type = 'outgoing' AND private = false
+    AND status = 'sent' AND sender_type = 'agent' AND sender_id <> $2
+    GROUP BY conversation_id
+),
+incoming AS (
+    SELECT conversation_id, MAX(created_at) AS last_incoming_at
+    FROM conversation_messages
+    WHERE conversation_id = ANY($1::BIGINT[]) AND type = 'incoming'
+    GROUP BY conversation_id
+),
+status_changes AS (
+    SELECT m.conversation_id,
+        MIN(m.created_at) FILTER (WHERE s.category = 'resolved') AS resolved_at,
+        MIN(m.created_at) FILTER (WHERE s.name = 'Closed') AS closed_at
+    FROM conversation_messages m
+    LEFT JOIN conversation_statuses s ON s.name = m.meta->>'activity_value'
+    WHERE m.conversation_id = ANY($1::BIGINT[]) AND m.type = 'activity'
+    AND m.meta->>'activity_type' = 'status_change'
+    GROUP BY m.conversation_id
+)
+UPDATE conversations c SET
+    first_reply_at = r.first_reply_at,
+    last_reply_at = r.last_reply_at,
+    waiting_since = CASE
+        WHEN i.last_incoming_at IS NOT NULL AND (r.last_reply_at IS NULL OR i.last_incoming_at > r.last_reply_at)
+        THEN i.last_incoming_at
+    END,
+    resolved_at = CASE WHEN sc.conversation_id IS NULL THEN c.resolved_at ELSE sc.resolved_at END,
+    closed_at = CASE WHEN sc.conversation_id IS NULL THEN c.closed_at ELSE sc.closed_at END,
+    updated_at = NOW()
+FROM conversations c2
+LEFT JOIN replies r ON r.conversation_id = c2.id
+LEFT JOIN incoming i ON i.conversation_id = c2.id
+LEFT JOIN status_changes sc ON sc.conversation_id = c2.id
+WHERE c.id = c2.id AND c2.id = ANY($1::BIGINT[]);
+
+-- name: get-active-conversation-uuids-by-inbox
+SELECT c.uuid FROM conversations c
+JOIN conversation_statuses s ON s.id = c.status_id
+WHERE c.inbox_id = $1 AND s.category <> 'resolved'
+ORDER BY c.id;
+
+-- name: insert-imported-message
+-- Inserts a message of an imported conversation bundle, keeping its original timestamp.
+INSERT INTO conversation_messages (
+    "type", status, conversation_id, "content", text_content, sender_id, sender_type,
+    private, content_type, source_id, meta, created_at, updated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+RETURNING *;
+
+-- name: update-imported-conversation
+-- Sets the original creation time, status and priority of an imported conversation, unknown statuses and priorities are ignored.
+UPDATE conversations SET
+    created_at = $2,
+    status_id = COALESCE((SELECT id FROM conversation_statuses WHERE name = $3), status_id),
+    priority_id = COALESCE((SELECT id FROM conversation_priorities WHERE name = $4), priority_id),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: insert-missing-tags
+INSERT INTO tags (name)
+SELECT unnest($1::text[])
+ON CONFLICT (name) DO NOTHING;
+
+-- name: add-message-delivery-event
+-- Appends an event to the delivery timeline of an outgoing message matched by UUID ($1) or source ID ($4) and sets its
+-- delivery state. Nothing is recorded for messages that bounced, and only the first open is recorded.
+UPDATE conversation_messages m
+SET delivery_state = $2,
+    meta = COALESCE(m.meta, '{}'::jsonb) || jsonb_build_object('delivery',
+        COALESCE(m.meta->'delivery', '[]'::jsonb) || jsonb_build_array(jsonb_build_object('state', $2::TEXT, 'at', NOW(), 'detail', $3::TEXT))),
+    updated_at = NOW()
+FROM conversations c
+WHERE (m.uuid = NULLIF($1, '')::uuid OR m.source_id = NULLIF($4, ''))
+    AND m.type = 'outgoing'
+    AND c.id = m.conversation_id
+    AND m.delivery_state IS DISTINCT FROM 'bounced'
+    AND NOT ($2 = 'opened' AND COALESCE(m.meta->'delivery', '[]'::jsonb) @> '[{"state": "opened"}]')
+RETURNING m.uuid, c.uuid AS conversation_uuid, m.meta;
+
+-- name: get-unconfirmed-deliveries
+-- Messages accepted by the mail server before $1 without any delivery report since.
+SELECT uuid FROM conversation_messages
+WHERE delivery_state = 'accepted' AND (meta->'delivery'->-1->>'at')::TIMESTAMPTZ < $1
+ORDER BY id
+LIMIT $2;
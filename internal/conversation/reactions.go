@@ -0,0 +1,37 @@
+package conversation
+
+import (
+	"encoding/json"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// AddMessageReaction adds the user's emoji reaction to a private note and broadcasts the note's reactions.
+// Adding a reaction twice is a no-op.
+func (m *Manager) AddMessageReaction(conversationUUID, messageUUID string, userID int, emoji string) (json.RawMessage, error) {
+	if _, err := m.q.InsertMessageReaction.Exec(messageUUID, userID, emoji); err != nil {
+		m.lo.Error("error adding message reaction", "message_uuid", messageUUID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastMessageReactions(conversationUUID, messageUUID)
+}
+
+// RemoveMessageReaction removes the user's emoji reaction from a message and broadcasts the message's reactions.
+func (m *Manager) RemoveMessageReaction(conversationUUID, messageUUID string, userID int, emoji string) (json.RawMessage, error) {
+	if _, err := m.q.DeleteMessageReaction.Exec(messageUUID, userID, emoji); err != nil {
+		m.lo.Error("error removing message reaction", "message_uuid", messageUUID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastMessageReactions(conversationUUID, messageUUID)
+}
+
+// broadcastMessageReactions sends the current reactions of a message to subscribers and returns them.
+func (m *Manager) broadcastMessageReactions(conversationUUID, messageUUID string) (json.RawMessage, error) {
+	var reactions json.RawMessage
+	if err := m.q.GetMessageReactions.Get(&reactions, messageUUID); err != nil {
+		m.lo.Error("error fetching message reactions", "message_uuid", messageUUID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.BroadcastMessageUpdate(conversationUUID, messageUUID, map[string]any{"reactions": reactions})
+	return reactions, nil
+}
@@ -8,9 +8,10 @@ import (
 	"github.com/abhinavxd/libredesk/internal/stringutil"
 )
 
-// makeRecipients computes the recipients for a given conversation ID using the last message in the conversation.
-func (m *Manager) makeRecipients(conversationID int, contactEmail, inboxEmail, inboxReplyTo string) (to, cc, bcc []string, err error) {
-	lastMessage, err := m.getLatestMessage(conversationID, []string{models.MessageIncoming, models.MessageOutgoing}, []string{models.MessageStatusReceived, models.MessageStatusSent}, true)
+// makeRecipients computes the recipients of a reply to a conversation using the last message in the conversation.
+// The inbox's addresses, current and previous, are never recipients.
+func (m *Manager) makeRecipients(conversation models.Conversation) (to, cc, bcc []string, err error) {
+	lastMessage, err := m.getLatestMessage(conversation.ID, []string{models.MessageIncoming, models.MessageOutgoing}, []string{models.MessageStatusReceived, models.MessageStatusSent}, true)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("fetching message for makeRecipients: %w", err)
 	}
@@ -27,7 +28,11 @@ func (m *Manager) makeRecipients(conversationID int, contactEmail, inboxEmail, i
 
 	isIncoming := lastMessage.Type == models.MessageIncoming
 	to, cc, bcc = stringutil.ComputeRecipients(
-		meta.From, meta.To, meta.CC, meta.BCC, contactEmail, inboxEmail, inboxReplyTo, isIncoming,
+		meta.From, meta.To, meta.CC, meta.BCC, conversation.Contact.Email.String, conversation.InboxMail, conversation.InboxReplyTo, isIncoming,
 	)
+	if len(conversation.InboxPreviousAddresses) > 0 {
+		to = stringutil.DedupAndExcludePlusVariants(to, conversation.InboxPreviousAddresses...)
+		cc = stringutil.DedupAndExcludePlusVariants(cc, conversation.InboxPreviousAddresses...)
+	}
 	return
 }
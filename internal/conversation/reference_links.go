@@ -0,0 +1,122 @@
+package conversation
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// maxReferenceLinks is the maximum number of distinct references linked in a message.
+const maxReferenceLinks = 20
+
+var (
+	// referenceRe matches #<reference-number> mentions that aren't part of a word, URL or HTML entity.
+	referenceRe = regexp.MustCompile(`(^|[^\w&#/])#(\d+)\b`)
+	// htmlTagRe matches HTML tags, text between them is checked for references.
+	htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+)
+
+// findReferences returns the distinct reference numbers mentioned in the text of HTML content, outside of links.
+func findReferences(content string) []string {
+	var refs []string
+	eachTextSegment(content, func(text string) string {
+		for _, m := range referenceRe.FindAllStringSubmatch(text, -1) {
+			if len(refs) < maxReferenceLinks && !slices.Contains(refs, m[2]) {
+				refs = append(refs, m[2])
+			}
+		}
+		return text
+	})
+	return refs
+}
+
+// linkReferences replaces #<reference-number> mentions in the text of HTML content, outside of links,
+// with links built by linkFn. Mentions linkFn returns an empty URL for are left as is.
+func linkReferences(content string, linkFn func(ref string) string) string {
+	return eachTextSegment(content, func(text string) string {
+		return referenceRe.ReplaceAllStringFunc(text, func(match string) string {
+			m := referenceRe.FindStringSubmatch(match)
+			link := linkFn(m[2])
+			if link == "" {
+				return match
+			}
+			return m[1] + `<a href="` + html.EscapeString(link) + `">#` + m[2] + `</a>`
+		})
+	})
+}
+
+// eachTextSegment calls fn on the text between HTML tags that isn't inside a link and returns the content
+// with the text replaced by fn's result.
+func eachTextSegment(content string, fn func(string) string) string {
+	var (
+		b      strings.Builder
+		inLink int
+		last   int
+	)
+	for _, loc := range htmlTagRe.FindAllStringIndex(content, -1) {
+		if inLink == 0 {
+			b.WriteString(fn(content[last:loc[0]]))
+		} else {
+			b.WriteString(content[last:loc[0]])
+		}
+		tag := strings.ToLower(content[loc[0]:loc[1]])
+		switch {
+		case strings.HasPrefix(tag, "<a ") || tag == "<a>":
+			inLink++
+		case strings.HasPrefix(tag, "</a") && inLink > 0:
+			inLink--
+		}
+		b.WriteString(content[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	if inLink == 0 {
+		b.WriteString(fn(content[last:]))
+	} else {
+		b.WriteString(content[last:])
+	}
+	return b.String()
+}
+
+// LinkConversationReferences converts #<reference-number> mentions of existing conversations in HTML content
+// to links to those conversations. Content is returned as is if reference links are disabled or on errors.
+func (m *Manager) LinkConversationReferences(content string) string {
+	if !m.referenceLinks {
+		return content
+	}
+	refs := findReferences(content)
+	if len(refs) == 0 {
+		return content
+	}
+
+	var rows []struct {
+		ReferenceNumber string `db:"reference_number"`
+		UUID            string `db:"uuid"`
+	}
+	if err := m.q.GetConversationUUIDsByReferenceNumbers.Select(&rows, pq.Array(refs)); err != nil {
+		m.lo.Error("error fetching referenced conversations", "references", refs, "error", err)
+		return content
+	}
+	if len(rows) == 0 {
+		return content
+	}
+	rootURL, err := m.settingsStore.GetAppRootURL()
+	if err != nil {
+		m.lo.Error("error fetching app root URL for reference links", "error", err)
+		return content
+	}
+
+	uuids := make(map[string]string, len(rows))
+	for _, r := range rows {
+		uuids[r.ReferenceNumber] = r.UUID
+	}
+	return linkReferences(content, func(ref string) string {
+		uuid, ok := uuids[ref]
+		if !ok {
+			return ""
+		}
+		return strings.TrimRight(rootURL, "/") + "/inboxes/all/conversation/" + uuid
+	})
+}
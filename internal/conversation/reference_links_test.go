@@ -0,0 +1,36 @@
+package conversation
+
+import "testing"
+
+func TestLinkReferences(t *testing.T) {
+	link := func(ref string) string {
+		if ref == "404" {
+			return ""
+		}
+		return "/c/" + ref
+	}
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "<p>See #101</p>", `<p>See <a href="/c/101">#101</a></p>`},
+		{"start and punctuation", "#101, (#102)", `<a href="/c/101">#101</a>, (<a href="/c/102">#102</a>)`},
+		{"unknown reference", "<p>#404</p>", "<p>#404</p>"},
+		{"inside link", `<a href="/x">#101</a>`, `<a href="/x">#101</a>`},
+		{"attribute", `<span title="#101">x</span>`, `<span title="#101">x</span>`},
+		{"entity and url", "&#101; http://x/#101 a#101 #101a", "&#101; http://x/#101 a#101 #101a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkReferences(tt.in, link); got != tt.want {
+				t.Errorf("linkReferences(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	got := findReferences(`<p>#101 and #102, again #101</p><a href="/x">#103</a>`)
+	if len(got) != 2 || got[0] != "101" || got[1] != "102" {
+		t.Errorf("findReferences() = %v, want [101 102]", got)
+	}
+}
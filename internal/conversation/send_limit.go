@@ -0,0 +1,87 @@
+package conversation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/inbox"
+)
+
+// sendLimiter tracks the messages an inbox sent in the last minute and is sending, to enforce the send
+// limits of the inbox in this app instance.
+type sendLimiter struct {
+	mu          sync.Mutex
+	perMinute   int
+	concurrency int
+	sent        []time.Time
+	inFlight    int
+}
+
+// full returns true if the inbox can't send another message now.
+func (l *sendLimiter) full(now time.Time) bool {
+	// Drop sends that left the one minute window.
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(l.sent) && !l.sent[i].After(cutoff) {
+		i++
+	}
+	l.sent = l.sent[i:]
+
+	return (l.perMinute > 0 && len(l.sent) >= l.perMinute) || (l.concurrency > 0 && l.inFlight >= l.concurrency)
+}
+
+// acquireSendSlot reserves a send of the inbox within its send limits. It returns false if the inbox is at
+// its limits, otherwise a func to call once the message is sent.
+func (m *Manager) acquireSendSlot(inb inbox.Inbox) (func(), bool) {
+	limited, ok := inb.(inbox.SendLimiter)
+	if !ok {
+		return func() {}, true
+	}
+	perMinute, concurrency := limited.SendLimits()
+	if perMinute <= 0 && concurrency <= 0 {
+		m.sendLimiters.Delete(inb.Identifier())
+		return func() {}, true
+	}
+
+	v, _ := m.sendLimiters.LoadOrStore(inb.Identifier(), &sendLimiter{})
+	l := v.(*sendLimiter)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Limits are refreshed on every send as the inbox may have been updated.
+	l.perMinute, l.concurrency = perMinute, concurrency
+	now := time.Now()
+	if l.full(now) {
+		return nil, false
+	}
+	l.sent = append(l.sent, now)
+	l.inFlight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inFlight--
+			l.mu.Unlock()
+		})
+	}, true
+}
+
+// getThrottledInboxIDs returns the IDs of the inboxes at their send limits, whose pending messages are not
+// claimed until they can send again.
+func (m *Manager) getThrottledInboxIDs() []int {
+	var (
+		ids = []int{}
+		now = time.Now()
+	)
+	m.sendLimiters.Range(func(key, value any) bool {
+		l := value.(*sendLimiter)
+		l.mu.Lock()
+		if l.full(now) {
+			ids = append(ids, key.(int))
+		}
+		l.mu.Unlock()
+		return true
+	})
+	return ids
+}
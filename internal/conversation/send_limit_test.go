@@ -0,0 +1,30 @@
+package conversation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendLimiterFull(t *testing.T) {
+	now := time.Now()
+
+	l := &sendLimiter{perMinute: 2}
+	if l.full(now) {
+		t.Fatal("expected limiter without sends to have room")
+	}
+	l.sent = []time.Time{now.Add(-2 * time.Minute), now.Add(-30 * time.Second), now}
+	if !l.full(now) {
+		t.Fatal("expected limiter with 2 sends in the last minute to be full")
+	}
+	if len(l.sent) != 2 {
+		t.Fatalf("expected sends older than a minute to be dropped, got %d", len(l.sent))
+	}
+	if l.full(now.Add(45 * time.Second)) {
+		t.Fatal("expected limiter to have room once a send left the window")
+	}
+
+	l = &sendLimiter{concurrency: 1, inFlight: 1}
+	if !l.full(now) {
+		t.Fatal("expected limiter at its concurrency to be full")
+	}
+}
@@ -7,6 +7,7 @@ var DefaultStatuses = []string{
 	"Snoozed",
 	"Resolved",
 	"Closed",
+	"Spam",
 }
 
 const (
@@ -27,3 +28,32 @@ type Status struct {
 	Name      string    `db:"name" json:"name"`
 	Category  string    `db:"category" json:"category"`
 }
+
+// Snooze preset kinds.
+const (
+	// SnoozeKindDuration snoozes for a fixed duration, e.g. "3h".
+	SnoozeKindDuration = "duration"
+	// SnoozeKindNextBusinessDay snoozes until a time of day on the next open business day.
+	SnoozeKindNextBusinessDay = "next_business_day"
+	// SnoozeKindWeekday snoozes until a time of day on the next given weekday, or the first open day after it.
+	SnoozeKindWeekday = "weekday"
+)
+
+var ValidSnoozeKinds = []string{
+	SnoozeKindDuration,
+	SnoozeKindNextBusinessDay,
+	SnoozeKindWeekday,
+}
+
+// SnoozePreset is an admin defined snooze option offered to agents.
+type SnoozePreset struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Name      string    `db:"name" json:"name"`
+	Kind      string    `db:"kind" json:"kind"`
+	Duration  string    `db:"duration" json:"duration"`
+	Weekday   string    `db:"weekday" json:"weekday"`
+	TimeOfDay string    `db:"time_of_day" json:"time_of_day"`
+	SortOrder int       `db:"sort_order" json:"sort_order"`
+}
@@ -21,3 +21,27 @@ DELETE from conversation_statuses where id = $1;
 
 -- name: update-status
 UPDATE conversation_statuses set name = $2, category = $3 where id = $1 RETURNING *;
+
+-- name: get-snooze-preset
+SELECT id, created_at, updated_at, name, kind, duration, weekday, time_of_day, sort_order
+FROM snooze_presets
+WHERE id = $1;
+
+-- name: get-all-snooze-presets
+SELECT id, created_at, updated_at, name, kind, duration, weekday, time_of_day, sort_order
+FROM snooze_presets
+ORDER BY sort_order, id;
+
+-- name: insert-snooze-preset
+INSERT INTO snooze_presets (name, kind, duration, weekday, time_of_day, sort_order)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: update-snooze-preset
+UPDATE snooze_presets
+SET name = $2, kind = $3, duration = $4, weekday = $5, time_of_day = $6, sort_order = $7, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: delete-snooze-preset
+DELETE FROM snooze_presets WHERE id = $1;
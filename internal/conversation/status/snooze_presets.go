@@ -0,0 +1,145 @@
+package status
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/status/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+const maxSnoozePresetNameLength = 50
+
+var weekdays = map[string]time.Weekday{
+	"Sunday":    time.Sunday,
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+}
+
+// GetAllSnoozePresets retrieves all snooze presets in display order.
+func (m *Manager) GetAllSnoozePresets() ([]models.SnoozePreset, error) {
+	var presets = make([]models.SnoozePreset, 0)
+	if err := m.q.GetAllSnoozePresets.Select(&presets); err != nil {
+		m.lo.Error("error fetching snooze presets", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return presets, nil
+}
+
+// GetSnoozePreset retrieves a snooze preset by ID.
+func (m *Manager) GetSnoozePreset(id int) (models.SnoozePreset, error) {
+	var preset models.SnoozePreset
+	if err := m.q.GetSnoozePreset.Get(&preset, id); err != nil {
+		if err == sql.ErrNoRows {
+			return preset, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching snooze preset", "id", id, "error", err)
+		return preset, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return preset, nil
+}
+
+// CreateSnoozePreset creates a new snooze preset.
+func (m *Manager) CreateSnoozePreset(p models.SnoozePreset) (models.SnoozePreset, error) {
+	var preset models.SnoozePreset
+	if err := m.validateSnoozePreset(&p); err != nil {
+		return preset, err
+	}
+	if err := m.q.InsertSnoozePreset.Get(&preset, p.Name, p.Kind, p.Duration, p.Weekday, p.TimeOfDay, p.SortOrder); err != nil {
+		m.lo.Error("error inserting snooze preset", "error", err)
+		return preset, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return preset, nil
+}
+
+// UpdateSnoozePreset updates a snooze preset by ID.
+func (m *Manager) UpdateSnoozePreset(id int, p models.SnoozePreset) (models.SnoozePreset, error) {
+	var preset models.SnoozePreset
+	if err := m.validateSnoozePreset(&p); err != nil {
+		return preset, err
+	}
+	if err := m.q.UpdateSnoozePreset.Get(&preset, id, p.Name, p.Kind, p.Duration, p.Weekday, p.TimeOfDay, p.SortOrder); err != nil {
+		if err == sql.ErrNoRows {
+			return preset, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error updating snooze preset", "id", id, "error", err)
+		return preset, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return preset, nil
+}
+
+// DeleteSnoozePreset deletes a snooze preset by ID.
+func (m *Manager) DeleteSnoozePreset(id int) error {
+	if _, err := m.q.DeleteSnoozePreset.Exec(id); err != nil {
+		m.lo.Error("error deleting snooze preset", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// validateSnoozePreset validates a snooze preset and clears the fields its kind does not use.
+func (m *Manager) validateSnoozePreset(p *models.SnoozePreset) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if utf8.RuneCountInString(p.Name) > maxSnoozePresetNameLength {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.maxLength", "max", fmt.Sprintf("%d", maxSnoozePresetNameLength)), nil)
+	}
+	if !slices.Contains(models.ValidSnoozeKinds, p.Kind) {
+		return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+
+	if p.Kind == models.SnoozeKindDuration {
+		if d, err := time.ParseDuration(p.Duration); err != nil || d <= 0 {
+			return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidDuration"), nil)
+		}
+		p.Weekday, p.TimeOfDay = "", ""
+		return nil
+	}
+
+	p.Duration = ""
+	if _, err := time.Parse("15:04", p.TimeOfDay); err != nil {
+		return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidTimeFormat"), nil)
+	}
+	if p.Kind == models.SnoozeKindWeekday {
+		if _, ok := weekdays[p.Weekday]; !ok {
+			return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+	} else {
+		p.Weekday = ""
+	}
+	return nil
+}
+
+// SnoozeUntil returns when a conversation snoozed now with the preset wakes up, computed against
+// the given business hours and time zone.
+func SnoozeUntil(p models.SnoozePreset, bh bmodels.BusinessHours, timeZone string, now time.Time) (time.Time, error) {
+	switch p.Kind {
+	case models.SnoozeKindDuration:
+		d, err := time.ParseDuration(p.Duration)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(d), nil
+	case models.SnoozeKindNextBusinessDay:
+		return businesshours.NextOpenDay(bh, timeZone, now, p.TimeOfDay)
+	case models.SnoozeKindWeekday:
+		weekday, ok := weekdays[p.Weekday]
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid weekday %q", p.Weekday)
+		}
+		return businesshours.NextWeekday(bh, timeZone, now, weekday, p.TimeOfDay)
+	}
+	return time.Time{}, fmt.Errorf("unknown snooze preset kind %q", p.Kind)
+}
@@ -44,6 +44,12 @@ type queries struct {
 	InsertStatus   *sqlx.Stmt `query:"insert-status"`
 	DeleteStatus   *sqlx.Stmt `query:"delete-status"`
 	UpdateStatus   *sqlx.Stmt `query:"update-status"`
+
+	GetSnoozePreset     *sqlx.Stmt `query:"get-snooze-preset"`
+	GetAllSnoozePresets *sqlx.Stmt `query:"get-all-snooze-presets"`
+	InsertSnoozePreset  *sqlx.Stmt `query:"insert-snooze-preset"`
+	UpdateSnoozePreset  *sqlx.Stmt `query:"update-snooze-preset"`
+	DeleteSnoozePreset  *sqlx.Stmt `query:"delete-snooze-preset"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -0,0 +1,130 @@
+package conversation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// teamDraftLockLease is how long a team draft stays locked to an agent without a save.
+const teamDraftLockLease = 5 * time.Minute
+
+// GetTeamDraft returns the team draft of a conversation, nil if there is none.
+func (m *Manager) GetTeamDraft(conversationID int) (*models.TeamDraft, error) {
+	var draft models.TeamDraft
+	if err := m.q.GetTeamDraft.Get(&draft, conversationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		m.lo.Error("error fetching team draft", "conversation_id", conversationID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return &draft, nil
+}
+
+// PromoteDraft turns the user's draft of a conversation into its team draft, replacing any existing one
+// not locked by another agent. The user's own draft is removed and they keep the lock.
+func (m *Manager) PromoteDraft(conversationUUID string, conversationID, userID int) (*models.TeamDraft, error) {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		m.lo.Error("error starting transaction", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.Stmtx(m.q.PromoteDraft).Get(&id, conversationID, userID, teamDraftLockLease.Seconds()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, m.teamDraftUnavailable(conversationID)
+		}
+		m.lo.Error("error promoting draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := tx.Stmtx(m.q.DeleteConversationDraft).Exec(conversationID, nil, userID); err != nil {
+		m.lo.Error("error deleting promoted draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing promoted draft", "conversation_id", conversationID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastTeamDraft(conversationUUID, conversationID)
+}
+
+// LockTeamDraft locks the team draft of a conversation to the user, or renews their lock.
+func (m *Manager) LockTeamDraft(conversationUUID string, conversationID, userID int) (*models.TeamDraft, error) {
+	var id int64
+	if err := m.q.LockTeamDraft.Get(&id, conversationID, userID, teamDraftLockLease.Seconds()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, m.teamDraftUnavailable(conversationID)
+		}
+		m.lo.Error("error locking team draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastTeamDraft(conversationUUID, conversationID)
+}
+
+// UnlockTeamDraft releases the user's lock on the team draft of a conversation.
+func (m *Manager) UnlockTeamDraft(conversationUUID string, conversationID, userID int) (*models.TeamDraft, error) {
+	if _, err := m.q.UnlockTeamDraft.Exec(conversationID, userID); err != nil {
+		m.lo.Error("error unlocking team draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastTeamDraft(conversationUUID, conversationID)
+}
+
+// UpdateTeamDraft saves the team draft of a conversation. The user must hold the lock, which is renewed.
+func (m *Manager) UpdateTeamDraft(conversationUUID string, conversationID, userID int, content string, meta json.RawMessage) (*models.TeamDraft, error) {
+	if len(meta) == 0 {
+		meta = json.RawMessage(`{}`)
+	}
+	var id int64
+	if err := m.q.UpdateTeamDraft.Get(&id, conversationID, userID, content, meta, teamDraftLockLease.Seconds()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, m.teamDraftUnavailable(conversationID)
+		}
+		m.lo.Error("error updating team draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.broadcastTeamDraft(conversationUUID, conversationID)
+}
+
+// DeleteTeamDraft discards the team draft of a conversation unless another agent holds its lock.
+func (m *Manager) DeleteTeamDraft(conversationUUID string, conversationID, userID int) error {
+	res, err := m.q.DeleteTeamDraft.Exec(conversationID, userID)
+	if err != nil {
+		m.lo.Error("error deleting team draft", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return m.teamDraftUnavailable(conversationID)
+	}
+	m.BroadcastConversationUpdate(conversationUUID, map[string]any{"team_draft": nil})
+	return nil
+}
+
+// teamDraftUnavailable returns the error for a team draft operation that matched no row: either the
+// draft is locked by another agent or it does not exist.
+func (m *Manager) teamDraftUnavailable(conversationID int) error {
+	draft, err := m.GetTeamDraft(conversationID)
+	if err != nil {
+		return err
+	}
+	if draft != nil && draft.LockedByID.Valid {
+		return envelope.NewError(envelope.ConflictError, m.i18n.Ts("conversation.teamDraftLocked", "name", draft.LockedByName), nil)
+	}
+	return envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+}
+
+// broadcastTeamDraft sends the current team draft of a conversation to subscribers and returns it.
+func (m *Manager) broadcastTeamDraft(conversationUUID string, conversationID int) (*models.TeamDraft, error) {
+	draft, err := m.GetTeamDraft(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	m.BroadcastConversationUpdate(conversationUUID, map[string]any{"team_draft": draft})
+	return draft, nil
+}
@@ -0,0 +1,91 @@
+package conversation
+
+import (
+	"database/sql"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/lib/pq"
+)
+
+// GetThreads returns the internal threads of a conversation, open threads first.
+func (m *Manager) GetThreads(conversationID int) ([]models.Thread, error) {
+	var threads = make([]models.Thread, 0)
+	if err := m.q.GetThreads.Select(&threads, conversationID); err != nil {
+		m.lo.Error("error fetching threads", "conversation_id", conversationID, "error", err)
+		return threads, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return threads, nil
+}
+
+// GetThread returns an internal thread by ID.
+func (m *Manager) GetThread(id int) (models.Thread, error) {
+	var thread models.Thread
+	if err := m.q.GetThread.Get(&thread, id); err != nil {
+		if err == sql.ErrNoRows {
+			return thread, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching thread", "id", id, "error", err)
+		return thread, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return thread, nil
+}
+
+// CreateThread creates an internal thread in a conversation. The creator is always a participant.
+func (m *Manager) CreateThread(conversationID int, name string, creatorID int, participantIDs []int) (models.Thread, error) {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		m.lo.Error("error starting transaction", "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.Stmtx(m.q.InsertThread).Get(&id, conversationID, name, creatorID); err != nil {
+		m.lo.Error("error inserting thread", "conversation_id", conversationID, "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := tx.Stmtx(m.q.SetThreadParticipants).Exec(id, pq.Array(withUser(participantIDs, creatorID))); err != nil {
+		m.lo.Error("error setting thread participants", "thread_id", id, "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing thread", "thread_id", id, "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.GetThread(id)
+}
+
+// UpdateThread renames an internal thread, replaces its participants and sets its resolution state.
+func (m *Manager) UpdateThread(id int, name string, participantIDs []int, resolved bool, actorID int) (models.Thread, error) {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		m.lo.Error("error starting transaction", "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Stmtx(m.q.UpdateThread).Exec(id, name, resolved, actorID); err != nil {
+		m.lo.Error("error updating thread", "thread_id", id, "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := tx.Stmtx(m.q.SetThreadParticipants).Exec(id, pq.Array(participantIDs)); err != nil {
+		m.lo.Error("error setting thread participants", "thread_id", id, "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing thread", "thread_id", id, "error", err)
+		return models.Thread{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.GetThread(id)
+}
+
+// withUser returns ids with userID appended if it is not already present.
+func withUser(ids []int, userID int) []int {
+	for _, id := range ids {
+		if id == userID {
+			return ids
+		}
+	}
+	return append(ids, userID)
+}
@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template"
+	"github.com/abhinavxd/libredesk/internal/transcript"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+// transcriptTimeFormat is the format of message times in the transcript email.
+const transcriptTimeFormat = "2006-01-02 15:04 MST"
+
+// GetTranscript returns the incoming and outgoing messages of a conversation, oldest first, with
+// private notes only if includePrivate is set. Messages of internal threads and transcripts previously
+// emailed to the contact are not included.
+func (m *Manager) GetTranscript(uuid string, includePrivate bool) (transcript.Transcript, error) {
+	conversation, err := m.GetConversation(0, uuid, "")
+	if err != nil {
+		return transcript.Transcript{}, err
+	}
+
+	var private *bool
+	if !includePrivate {
+		private = new(bool)
+	}
+	types := []string{models.MessageIncoming, models.MessageOutgoing}
+
+	var messages []models.Message
+	for page := 1; ; page++ {
+		batch, _, err := m.GetConversationMessages(uuid, page, maxMessagesPerPage, private, types, 0)
+		if err != nil {
+			return transcript.Transcript{}, err
+		}
+		messages = append(messages, batch...)
+		if len(batch) < maxMessagesPerPage {
+			break
+		}
+	}
+	// Messages are fetched newest first.
+	slices.Reverse(messages)
+
+	t := transcript.Transcript{
+		ReferenceNumber: conversation.ReferenceNumber,
+		Subject:         conversation.Subject.String,
+		InboxName:       conversation.InboxName,
+		InboxMail:       conversation.InboxMail,
+		ContactName:     conversation.Contact.FullName(),
+		ContactEmail:    conversation.Contact.Email.String,
+		CreatedAt:       conversation.CreatedAt,
+		GeneratedAt:     time.Now(),
+		Messages:        make([]transcript.Message, 0, len(messages)),
+	}
+	for _, msg := range messages {
+		if isTranscriptMessage(msg) {
+			continue
+		}
+		tm := transcript.Message{
+			AuthorName:  strings.TrimSpace(msg.Author.FirstName + " " + msg.Author.LastName),
+			AuthorEmail: msg.Author.Email.String,
+			Private:     msg.Private,
+			CreatedAt:   msg.CreatedAt,
+			Text:        msg.TextContent,
+		}
+		for _, a := range msg.Attachments {
+			tm.Attachments = append(tm.Attachments, transcript.Attachment{
+				Name:        a.Name,
+				ContentType: a.ContentType,
+				Size:        a.Size,
+			})
+		}
+		t.Messages = append(t.Messages, tm)
+	}
+	return t, nil
+}
+
+// SendTranscript emails the contact a transcript of the conversation, rendered with the built-in
+// transcript template and sent as a reply from the conversation's inbox. Private notes are never
+// included. Transcripts sent by the system user are skipped for contacts who opted out of
+// automated messages.
+func (m *Manager) SendTranscript(uuid string, actor umodels.User) error {
+	conversation, err := m.GetConversation(0, uuid, "")
+	if err != nil {
+		return err
+	}
+	if actor.IsSystemUser() && !m.contactAllows(conversation.ContactID, umodels.ContactPreferences.AllowsAutomated) {
+		m.lo.Info("contact opted out of automated messages, skipping transcript", "conversation_uuid", uuid, "contact_id", conversation.ContactID)
+		return nil
+	}
+
+	t, err := m.GetTranscript(uuid, false)
+	if err != nil {
+		return err
+	}
+	data, err := m.BuildTemplateData(uuid, actor.ID)
+	if err != nil {
+		m.lo.Error("error building transcript template data", "conversation_uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	msgs := make([]map[string]any, 0, len(t.Messages))
+	for _, msg := range t.Messages {
+		attachments := make([]string, 0, len(msg.Attachments))
+		for _, a := range msg.Attachments {
+			attachments = append(attachments, a.Name)
+		}
+		msgs = append(msgs, map[string]any{
+			"AuthorName":  msg.AuthorName,
+			"CreatedAt":   msg.CreatedAt.UTC().Format(transcriptTimeFormat),
+			"Text":        strings.TrimSpace(msg.Text),
+			"Attachments": attachments,
+		})
+	}
+	data["Messages"] = msgs
+
+	content, err := m.template.RenderStoredTemplate(template.TmplTranscript, data)
+	if err != nil {
+		m.lo.Error("error rendering transcript template", "conversation_uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	to, cc, bcc, err := m.makeRecipients(conversation)
+	if err != nil {
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	// Store `is_transcript` meta to leave the transcript out of later transcripts.
+	meta := map[string]any{"is_transcript": true}
+	if _, err := m.QueueReply(nil /**media**/, conversation.InboxID, actor.ID, conversation.ContactID, uuid, content, to, cc, bcc, meta); err != nil {
+		m.lo.Error("error sending transcript", "conversation_uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// isTranscriptMessage returns true if the message is a transcript emailed to the contact.
+func isTranscriptMessage(msg models.Message) bool {
+	var meta map[string]any
+	if err := json.Unmarshal(msg.Meta, &meta); err != nil {
+		return false
+	}
+	is, _ := meta["is_transcript"].(bool)
+	return is
+}
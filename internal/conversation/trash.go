@@ -0,0 +1,93 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+// TrashConversation moves a conversation to the trash, hiding it from conversation lists until it is
+// restored or purged.
+func (m *Manager) TrashConversation(uuid string, actor umodels.User) error {
+	var trashedAt time.Time
+	if err := m.q.TrashConversation.Get(&trashedAt, uuid); err != nil {
+		// Already in the trash.
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		m.lo.Error("error trashing conversation", "uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.lo.Info("conversation moved to trash", "uuid", uuid, "actor_id", actor.ID)
+	m.BroadcastConversationUpdate(uuid, map[string]any{"trashed_at": trashedAt.Format(time.RFC3339)})
+	return nil
+}
+
+// RestoreConversation restores a conversation from the trash.
+func (m *Manager) RestoreConversation(uuid string, actor umodels.User) error {
+	if _, err := m.q.RestoreConversation.Exec(uuid); err != nil {
+		m.lo.Error("error restoring conversation", "uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.lo.Info("conversation restored from trash", "uuid", uuid, "actor_id", actor.ID)
+	m.BroadcastConversationUpdate(uuid, map[string]any{"trashed_at": nil})
+	return nil
+}
+
+// GetTrashedConversationsList retrieves the conversations in the trash that are in one of the given lists the user
+// can read, with optional filtering, ordering, and pagination.
+func (m *Manager) GetTrashedConversationsList(viewingUserID int, teamIDs []int, lists []string, order, orderBy, filters string, page, pageSize int) ([]models.ConversationListItem, error) {
+	listTypes := append(slices.Clone(lists), models.TrashedConversations)
+	return m.GetConversations(viewingUserID, viewingUserID, teamIDs, listTypes, order, orderBy, filters, page, pageSize)
+}
+
+// restoreOnContactMessage restores a trashed conversation a contact wrote to, so that their message
+// is seen and isn't purged with the conversation.
+func (m *Manager) restoreOnContactMessage(uuid string) {
+	res, err := m.q.RestoreConversation.Exec(uuid)
+	if err != nil {
+		m.lo.Error("error restoring trashed conversation on contact message", "uuid", uuid, "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		m.lo.Info("conversation restored from trash on contact message", "uuid", uuid)
+		m.BroadcastConversationUpdate(uuid, map[string]any{"trashed_at": nil})
+	}
+}
+
+// RunTrashPurger permanently deletes conversations that have been in the trash longer than the retention period, every hour.
+func (m *Manager) RunTrashPurger(ctx context.Context, retentionPeriod time.Duration) {
+	if retentionPeriod <= 0 {
+		m.lo.Info("trash retention period is non-positive, skipping trash purger", "retention_period", retentionPeriod)
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.PurgeTrash(ctx, retentionPeriod); err != nil {
+				m.lo.Error("error purging trashed conversations", "error", err)
+			}
+		}
+	}
+}
+
+// PurgeTrash permanently deletes conversations trashed before the retention period.
+func (m *Manager) PurgeTrash(ctx context.Context, retentionPeriod time.Duration) error {
+	res, err := m.q.PurgeTrashedConversations.ExecContext(ctx, time.Now().Add(-retentionPeriod))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		m.lo.Info("purged trashed conversations", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package conversation
+
+import (
+	vmodels "github.com/abhinavxd/libredesk/internal/variant/models"
+	"github.com/volatiletech/null/v9"
+)
+
+type variantStore interface {
+	PickTemplateVariant(name string) (vmodels.Variant, bool)
+	PickAutoresponderVariant(autoresponderID int) (vmodels.Variant, bool)
+	RecordSend(variantID, conversationID int, csatResponseID null.Int) error
+}
+
+// SetVariantStore sets the store that picks A/B variants of automated messages.
+func (m *Manager) SetVariantStore(store variantStore) {
+	m.variantStore = store
+}
+
+// pickTemplateVariant returns a variant of the named template if the variant store is set and the
+// template has variants.
+func (m *Manager) pickTemplateVariant(name string) (vmodels.Variant, bool) {
+	if m.variantStore == nil {
+		return vmodels.Variant{}, false
+	}
+	return m.variantStore.PickTemplateVariant(name)
+}
+
+// pickAutoresponderVariant returns a variant of an autoresponder if the variant store is set and the
+// autoresponder has variants.
+func (m *Manager) pickAutoresponderVariant(autoresponderID int) (vmodels.Variant, bool) {
+	if m.variantStore == nil {
+		return vmodels.Variant{}, false
+	}
+	return m.variantStore.PickAutoresponderVariant(autoresponderID)
+}
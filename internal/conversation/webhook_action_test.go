@@ -0,0 +1,76 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/template"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
+)
+
+// fakeWebhookStore records the calls made through it.
+type fakeWebhookStore struct {
+	urls   []string
+	bodies [][]byte
+}
+
+func (f *fakeWebhookStore) TriggerEvent(wmodels.WebhookEvent, any) {}
+
+func (f *fakeWebhookStore) TriggerEventContext(context.Context, wmodels.WebhookEvent, any) {}
+
+func (f *fakeWebhookStore) Call(url string, body []byte) {
+	f.urls = append(f.urls, url)
+	f.bodies = append(f.bodies, body)
+}
+
+func TestCallWebhook(t *testing.T) {
+	store := &fakeWebhookStore{}
+	m := &Manager{webhookStore: store}
+	conv := models.Conversation{UUID: "c0ffee", ReferenceNumber: "100"}
+
+	for _, u := range []string{"ftp://example.com/hook", "/relative", "https://"} {
+		if err := m.callWebhook(conv, umodels.User{}, []string{u}); err == nil {
+			t.Errorf("callWebhook(%q) expected an error", u)
+		}
+	}
+	if len(store.urls) != 0 {
+		t.Fatalf("invalid URLs should not be called, got %v", store.urls)
+	}
+
+	if err := m.callWebhook(conv, umodels.User{}, []string{" https://example.com/hook ", ""}); err != nil {
+		t.Fatalf("callWebhook() error = %v", err)
+	}
+	if len(store.urls) != 1 || store.urls[0] != "https://example.com/hook" {
+		t.Fatalf("called URLs = %v", store.urls)
+	}
+	var body struct {
+		Conversation models.Conversation `json:"conversation"`
+	}
+	if err := json.Unmarshal(store.bodies[0], &body); err != nil || body.Conversation.UUID != conv.UUID {
+		t.Errorf("body = %s, want the conversation (%v)", store.bodies[0], err)
+	}
+}
+
+func TestValidateWebhookAction(t *testing.T) {
+	m := &Manager{template: &template.Manager{}}
+	tests := []struct {
+		value []string
+		ok    bool
+	}{
+		{[]string{"https://example.com/hook"}, true},
+		{[]string{"https://example.com/hook", `{"ref": "{{ .Conversation.ReferenceNumber }}"}`}, true},
+		{[]string{"javascript:alert(1)"}, false},
+		{[]string{"https://example.com/hook", `{"ref": "{{ if }}"}`}, false},
+		{[]string{"https://example.com/hook", `{{ range 100000 }}.{{ end }}`}, false},
+	}
+	for _, tt := range tests {
+		err := m.ValidateAction(amodels.RuleAction{Type: amodels.ActionCallWebhook, Value: tt.value})
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateAction(%q) error = %v, want ok %v", tt.value, err, tt.ok)
+		}
+	}
+}
@@ -165,3 +165,19 @@ func (m *Manager) BroadcastConversationToWidget(conversationUUID string, contact
 		liveChatInbox.BroadcastConversationToClients(conversationUUID, contactID, data)
 	}
 }
+
+// DeliverWidgetBroadcast delivers a widget broadcast relayed from another app instance to the
+// contact's widget clients connected to this instance.
+func (m *Manager) DeliverWidgetBroadcast(inboxID, contactID int, data []byte) {
+	inboxInstance, err := m.inboxStore.Get(inboxID)
+	if err != nil {
+		if err != inbox.ErrInboxNotFound {
+			m.lo.Error("error getting inbox for relayed widget broadcast", "error", err, "inbox_id", inboxID)
+		}
+		return
+	}
+
+	if liveChatInbox, ok := inboxInstance.(*livechat.LiveChat); ok {
+		liveChatInbox.DeliverToContact(contactID, data)
+	}
+}
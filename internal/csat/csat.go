@@ -2,17 +2,26 @@
 package csat
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"embed"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/abhinavxd/libredesk/internal/csat/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
+	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/logf"
 )
 
@@ -20,6 +29,7 @@ var (
 	//go:embed queries.sql
 	efs                  embed.FS
 	ErrCSATAlreadyExists = errors.New("CSAT already exists")
+	ErrInvalidToken      = errors.New("invalid or expired CSAT token")
 )
 
 const (
@@ -28,9 +38,12 @@ const (
 
 // Manager manages CSAT.
 type Manager struct {
-	q    queries
-	lo   *logf.Logger
-	i18n *i18n.I18n
+	q           queries
+	lo          *logf.Logger
+	i18n        *i18n.I18n
+	signingKey  []byte
+	tokenExpiry time.Duration
+	latestWins  bool
 }
 
 // Opts contains options for initializing the Manager.
@@ -38,13 +51,31 @@ type Opts struct {
 	DB   *sqlx.DB
 	Lo   *logf.Logger
 	I18n *i18n.I18n
+
+	// SigningKey signs the tokens in public survey URLs.
+	SigningKey string
+	// TokenExpiry is how long a survey URL stays valid.
+	TokenExpiry time.Duration
+	// LatestWins lets a contact replace their response, instead of keeping the first one.
+	LatestWins bool
+}
+
+// Token is a survey token with a verified signature. Its nonce must match the one
+// issued to the survey when it was sent, see ValidToken.
+type Token struct {
+	UUID   string
+	Nonce  string
+	Expiry time.Time
+	// Legacy is set for bare survey UUIDs of links sent before signed tokens.
+	Legacy bool
 }
 
 // queries contains prepared SQL queries.
 type queries struct {
-	Insert *sqlx.Stmt `query:"insert"`
-	Get    *sqlx.Stmt `query:"get"`
-	Update *sqlx.Stmt `query:"update"`
+	Insert                *sqlx.Stmt `query:"insert"`
+	Get                   *sqlx.Stmt `query:"get"`
+	Update                *sqlx.Stmt `query:"update"`
+	ContactSurveyedWithin *sqlx.Stmt `query:"contact-surveyed-within"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -54,19 +85,79 @@ func New(opts Opts) (*Manager, error) {
 		return nil, err
 	}
 	return &Manager{
-		q:    q,
-		lo:   opts.Lo,
-		i18n: opts.I18n,
+		q:           q,
+		lo:          opts.Lo,
+		i18n:        opts.I18n,
+		signingKey:  []byte(opts.SigningKey),
+		tokenExpiry: opts.TokenExpiry,
+		latestWins:  opts.LatestWins,
 	}, nil
 }
 
-// Create creates a new CSAT for the given conversation ID, returning ErrCSATAlreadyExists if one already exists.
-func (m *Manager) Create(conversationID int) (models.CSATResponse, error) {
+// LatestWins reports whether a new response replaces an earlier one.
+func (m *Manager) LatestWins() bool {
+	return m.latestWins
+}
+
+// NewToken returns a signed token for the CSAT with the nonce issued to it on creation,
+// expiring after the configured expiry.
+func (m *Manager) NewToken(csat models.CSATResponse) string {
+	exp := time.Now().Add(m.tokenExpiry).Unix()
+	return fmt.Sprintf("%s.%d.%s.%s", csat.UUID, exp, csat.TokenNonce.String, m.sign(csat.UUID, exp, csat.TokenNonce.String))
+}
+
+// ParseToken verifies the signature and expiry of a token. Bare survey UUIDs of legacy links
+// are returned as legacy tokens.
+func (m *Manager) ParseToken(token string) (Token, error) {
+	if _, err := uuid.Parse(token); err == nil {
+		return Token{UUID: token, Legacy: true}, nil
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return Token{}, ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return Token{}, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[3]), []byte(m.sign(parts[0], exp, parts[2]))) {
+		return Token{}, ErrInvalidToken
+	}
+	return Token{UUID: parts[0], Nonce: parts[2], Expiry: time.Unix(exp, 0)}, nil
+}
+
+// newNonce returns a random token nonce.
+func newNonce() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sign creates the HMAC-SHA256 signature of a token.
+func (m *Manager) sign(uuid string, exp int64, nonce string) string {
+	h := hmac.New(sha256.New, m.signingKey)
+	fmt.Fprintf(h, "%s:%d:%s", uuid, exp, nonce)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Create creates a new CSAT with the given rating scale for the conversation ID, returning ErrCSATAlreadyExists
+// if one already exists.
+func (m *Manager) Create(conversationID int, scale string) (models.CSATResponse, error) {
 	var (
 		uuid string
 		rsp  models.CSATResponse
 	)
-	err := m.q.Insert.QueryRow(conversationID).Scan(&uuid)
+	if !models.ValidScale(scale) {
+		scale = models.ScaleStars
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		m.lo.Error("error generating CSAT token nonce", "error", err)
+		return rsp, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	err = m.q.Insert.QueryRow(conversationID, scale, nonce).Scan(&uuid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return rsp, ErrCSATAlreadyExists
@@ -91,30 +182,51 @@ func (m *Manager) Get(uuid string) (models.CSATResponse, error) {
 	return csat, nil
 }
 
-// UpdateResponse updates the CSAT response for the given csat.
-func (m *Manager) UpdateResponse(uuid string, score int, feedback string, meta json.RawMessage) error {
-	csat, err := m.Get(uuid)
-	if err != nil {
-		return err
+// ValidToken reports whether the token's nonce is the one issued to the CSAT when it was sent.
+// Legacy tokens are only valid for unanswered surveys sent before signed tokens.
+func (m *Manager) ValidToken(token Token, csat models.CSATResponse) bool {
+	if token.UUID != csat.UUID {
+		return false
 	}
-
-	if csat.ResponseTimestamp.Valid {
-		return envelope.NewError(envelope.InputError, m.i18n.T("csat.alreadySubmitted"), nil)
+	if token.Legacy {
+		return !csat.TokenNonce.Valid && !csat.ResponseTimestamp.Valid
 	}
+	return csat.TokenNonce.Valid && hmac.Equal([]byte(token.Nonce), []byte(csat.TokenNonce.String))
+}
 
+// UpdateResponse records the response submitted with a token, rating is null for feedback only responses.
+// An earlier response is only replaced when latest-wins is configured.
+func (m *Manager) UpdateResponse(token Token, rating null.Int, feedback string, meta json.RawMessage) error {
 	if len(meta) == 0 {
 		meta = json.RawMessage(`{}`)
 	}
 
-	_, err = m.q.Update.Exec(uuid, score, feedback, meta)
+	res, err := m.q.Update.Exec(token.UUID, rating, feedback, meta, null.NewString(token.Nonce, !token.Legacy), m.latestWins)
 	if err != nil {
 		m.lo.Error("error updating CSAT", "error", err)
 		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// Either the survey doesn't exist, was already answered, or the token is not the survey's.
+		if _, err := m.Get(token.UUID); err != nil {
+			return err
+		}
+		return envelope.NewError(envelope.InputError, m.i18n.T("csat.alreadySubmitted"), nil)
+	}
 	return nil
 }
 
-// MakePublicURL returns the public URL for the given CSAT UUID.
-func (m *Manager) MakePublicURL(appBaseURL, uuid string) string {
-	return fmt.Sprintf(csatURL, appBaseURL, uuid)
+// ContactSurveyedWithin reports whether a survey was sent to the contact in the last days days.
+func (m *Manager) ContactSurveyedWithin(contactID, days int) (bool, error) {
+	var exists bool
+	if err := m.q.ContactSurveyedWithin.Get(&exists, contactID, days); err != nil {
+		m.lo.Error("error checking recent CSAT surveys of contact", "contact_id", contactID, "error", err)
+		return false, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return exists, nil
+}
+
+// MakePublicURL returns the public URL for the given CSAT token.
+func (m *Manager) MakePublicURL(appBaseURL, token string) string {
+	return fmt.Sprintf(csatURL, appBaseURL, token)
 }
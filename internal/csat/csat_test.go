@@ -0,0 +1,50 @@
+package csat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/csat/models"
+	"github.com/volatiletech/null/v9"
+)
+
+func TestValidToken(t *testing.T) {
+	m := &Manager{signingKey: []byte("secret"), tokenExpiry: time.Hour}
+	csat := models.CSATResponse{UUID: "0b9a3c5e-4a8f-4d8e-9d6b-2f1c7e5a9b10", TokenNonce: null.StringFrom("nonce")}
+
+	tok, err := m.ParseToken(m.NewToken(csat))
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if !m.ValidToken(tok, csat) {
+		t.Error("token issued to the survey should be valid")
+	}
+
+	// Viewing or re-sending must not yield other valid nonces for the survey.
+	other := csat
+	other.TokenNonce = null.StringFrom("other")
+	if m.ValidToken(tok, other) {
+		t.Error("token with another nonce should be invalid")
+	}
+
+	// Legacy UUID links only work for unanswered surveys sent before signed tokens.
+	legacy, err := m.ParseToken(csat.UUID)
+	if err != nil || !legacy.Legacy {
+		t.Fatalf("ParseToken(uuid) = %+v, %v", legacy, err)
+	}
+	if m.ValidToken(legacy, csat) {
+		t.Error("legacy link should be invalid for a survey with a signed token")
+	}
+	csat.TokenNonce = null.String{}
+	if !m.ValidToken(legacy, csat) {
+		t.Error("legacy link should be valid for an unanswered legacy survey")
+	}
+	csat.ResponseTimestamp = null.TimeFrom(time.Now())
+	if m.ValidToken(legacy, csat) {
+		t.Error("legacy link should be invalid once the survey is answered")
+	}
+
+	if _, err := m.ParseToken(csat.UUID + ".1.x.y"); err == nil {
+		t.Error("forged token should be rejected")
+	}
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/volatiletech/null/v9"
 )
 
+// Rating scales of CSAT surveys.
+const (
+	ScaleStars  = "stars"
+	ScaleThumbs = "thumbs"
+	ScaleNPS    = "nps"
+)
+
 // CSATResponse represents a customer satisfaction survey response.
 type CSATResponse struct {
 	ID                int             `db:"id" json:"id"`
@@ -15,8 +22,60 @@ type CSATResponse struct {
 	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
 	UUID              string          `db:"uuid" json:"uuid"`
 	ConversationID    int             `db:"conversation_id" json:"conversation_id"`
-	Rating            int             `db:"rating" json:"rating"`
+	Rating            null.Int        `db:"rating" json:"rating"`
+	Scale             string          `db:"scale" json:"scale"`
 	Feedback          null.String     `db:"feedback" json:"feedback"`
 	Meta              json.RawMessage `db:"meta" json:"meta"`
 	ResponseTimestamp null.Time       `db:"response_timestamp" json:"response_timestamp"`
+	TokenNonce        null.String     `db:"token_nonce" json:"-"`
+}
+
+// RatingOption is a rating contacts can pick on a survey.
+type RatingOption struct {
+	Value int
+	Emoji string
+	// LabelKey is the i18n key of the rating's label, empty for numeric ratings.
+	LabelKey string
+}
+
+var ratingOptions = map[string][]RatingOption{
+	ScaleStars: {
+		{Value: 1, Emoji: "😢", LabelKey: "globals.terms.poor"},
+		{Value: 2, Emoji: "😕", LabelKey: "globals.terms.fair"},
+		{Value: 3, Emoji: "😊", LabelKey: "globals.terms.good"},
+		{Value: 4, Emoji: "😃", LabelKey: "globals.terms.great"},
+		{Value: 5, Emoji: "🤩", LabelKey: "globals.terms.excellent"},
+	},
+	ScaleThumbs: {
+		{Value: 1, Emoji: "👎", LabelKey: "csat.thumbsDown"},
+		{Value: 2, Emoji: "👍", LabelKey: "csat.thumbsUp"},
+	},
+	ScaleNPS: {
+		{Value: 0}, {Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5},
+		{Value: 6}, {Value: 7}, {Value: 8}, {Value: 9}, {Value: 10},
+	},
+}
+
+// ValidScale reports whether the scale is a known rating scale.
+func ValidScale(scale string) bool {
+	_, ok := ratingOptions[scale]
+	return ok
+}
+
+// RatingOptions returns the ratings of a scale, those of the stars scale if the scale is unknown.
+func RatingOptions(scale string) []RatingOption {
+	if opts, ok := ratingOptions[scale]; ok {
+		return opts
+	}
+	return ratingOptions[ScaleStars]
+}
+
+// ValidRating reports whether the rating is on the scale.
+func ValidRating(scale string, rating int) bool {
+	for _, o := range RatingOptions(scale) {
+		if o.Value == rating {
+			return true
+		}
+	}
+	return false
 }
@@ -1,6 +1,6 @@
 -- name: insert
-INSERT INTO csat_responses (conversation_id)
-SELECT $1
+INSERT INTO csat_responses (conversation_id, scale, token_nonce)
+SELECT $1, $2, $3
 WHERE NOT EXISTS (SELECT 1 FROM csat_responses WHERE conversation_id = $1)
 RETURNING uuid;
 
@@ -11,16 +11,33 @@ SELECT id,
     updated_at,
     conversation_id,
     rating,
+    scale,
     feedback,
     meta,
-    response_timestamp
+    response_timestamp,
+    token_nonce
 FROM csat_responses
 WHERE uuid = $1;
 
 -- name: update
+-- $5 is the nonce of the survey link, NULL for legacy UUID links. $6 allows replacing an earlier response,
+-- which legacy links never do.
 UPDATE csat_responses
 SET rating = $2,
     feedback = $3,
     meta = COALESCE($4::jsonb, '{}'),
-    response_timestamp = NOW()
-WHERE uuid = $1;
+    response_timestamp = NOW(),
+    updated_at = NOW()
+WHERE uuid = $1
+    AND token_nonce IS NOT DISTINCT FROM $5::TEXT
+    AND (response_timestamp IS NULL OR ($6::BOOLEAN AND token_nonce IS NOT NULL));
+
+-- name: contact-surveyed-within
+-- Whether a survey was sent to the contact in the last $2 days.
+SELECT EXISTS (
+    SELECT 1
+    FROM csat_responses r
+    JOIN conversations c ON c.id = r.conversation_id
+    WHERE c.contact_id = $1
+        AND r.created_at >= NOW() - make_interval(days => $2)
+);
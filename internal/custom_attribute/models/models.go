@@ -18,4 +18,5 @@ type CustomAttribute struct {
 	DataType    string         `db:"data_type" json:"data_type"`
 	Regex       string         `db:"regex" json:"regex"`
 	RegexHint   string         `db:"regex_hint" json:"regex_hint"`
+	Required    bool           `db:"required" json:"required"`
 }
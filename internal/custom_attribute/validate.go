@@ -0,0 +1,109 @@
+package customAttribute
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/custom_attribute/models"
+)
+
+// Data types of custom attributes.
+const (
+	DataTypeText     = "text"
+	DataTypeNumber   = "number"
+	DataTypeCheckbox = "checkbox"
+	DataTypeDate     = "date"
+	DataTypeLink     = "link"
+	DataTypeList     = "list"
+)
+
+// DataTypes is the list of supported data types.
+var DataTypes = []string{DataTypeText, DataTypeNumber, DataTypeCheckbox, DataTypeDate, DataTypeLink, DataTypeList}
+
+// ValidationError is returned when a custom attribute value doesn't match its definition.
+type ValidationError struct {
+	// Name is the display name of the offending attribute.
+	Name string
+	// Missing is true if a required attribute has no value.
+	Missing bool
+}
+
+func (e *ValidationError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("custom attribute %q is required", e.Name)
+	}
+	return fmt.Sprintf("invalid value for custom attribute %q", e.Name)
+}
+
+// Validate checks attribute values against their definitions. Null values are
+// treated as unset, and keys without a definition are left alone.
+func Validate(defs []models.CustomAttribute, values map[string]any) error {
+	for _, def := range defs {
+		v, ok := values[def.Key]
+		if !ok || v == nil || v == "" {
+			if def.Required {
+				return &ValidationError{Name: def.Name, Missing: true}
+			}
+			continue
+		}
+		if !validValue(def, v) {
+			return &ValidationError{Name: def.Name}
+		}
+	}
+	return nil
+}
+
+// validValue reports whether a non-null value is valid for the definition.
+func validValue(def models.CustomAttribute, v any) bool {
+	switch def.DataType {
+	case DataTypeNumber:
+		_, ok := v.(float64)
+		return ok
+	case DataTypeCheckbox:
+		_, ok := v.(bool)
+		return ok
+	case DataTypeDate:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		if _, err := time.Parse(time.DateOnly, s); err == nil {
+			return true
+		}
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case DataTypeList:
+		s, ok := v.(string)
+		return ok && slices.Contains(def.Values, s)
+	case DataTypeLink:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return false
+		}
+		return matchRegex(def.Regex, s)
+	default:
+		s, ok := v.(string)
+		return ok && matchRegex(def.Regex, s)
+	}
+}
+
+// matchRegex reports whether s matches the definition's regex. An empty or
+// invalid regex matches everything, as the regex is only an admin-provided hint.
+func matchRegex(expr, s string) bool {
+	if strings.TrimSpace(expr) == "" {
+		return true
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return true
+	}
+	return re.MatchString(s)
+}
@@ -0,0 +1,51 @@
+package customAttribute
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/custom_attribute/models"
+)
+
+func TestValidate(t *testing.T) {
+	defs := []models.CustomAttribute{
+		{Name: "Plan", Key: "plan", DataType: DataTypeList, Values: []string{"free", "pro"}, Required: true},
+		{Name: "Seats", Key: "seats", DataType: DataTypeNumber},
+		{Name: "Renews", Key: "renews", DataType: DataTypeDate},
+		{Name: "Trial", Key: "trial", DataType: DataTypeCheckbox},
+		{Name: "Site", Key: "site", DataType: DataTypeLink},
+		{Name: "Code", Key: "code", DataType: DataTypeText, Regex: `^[A-Z]{3}$`},
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]any
+		wantErr bool
+		missing bool
+	}{
+		{"valid", map[string]any{"plan": "pro", "seats": 3.0, "renews": "2026-01-31", "trial": true, "site": "https://example.com", "code": "ABC"}, false, false},
+		{"unknown keys allowed", map[string]any{"plan": "free", "other": []any{1}}, false, false},
+		{"null is unset", map[string]any{"plan": "free", "seats": nil}, false, false},
+		{"rfc3339 date", map[string]any{"plan": "free", "renews": "2026-01-31T10:00:00Z"}, false, false},
+		{"required missing", map[string]any{"seats": 1.0}, true, true},
+		{"required empty", map[string]any{"plan": ""}, true, true},
+		{"list value not allowed", map[string]any{"plan": "enterprise"}, true, false},
+		{"number as string", map[string]any{"plan": "free", "seats": "3"}, true, false},
+		{"bad date", map[string]any{"plan": "free", "renews": "31/01/2026"}, true, false},
+		{"checkbox as string", map[string]any{"plan": "free", "trial": "true"}, true, false},
+		{"non http link", map[string]any{"plan": "free", "site": "javascript:alert(1)"}, true, false},
+		{"regex mismatch", map[string]any{"plan": "free", "code": "abc"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(defs, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var verr *ValidationError
+			if err != nil && (!errors.As(err, &verr) || verr.Missing != tt.missing) {
+				t.Fatalf("Validate() error = %#v, want missing %v", err, tt.missing)
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ type Filter struct {
 	Field    string `json:"field"`
 	Operator string `json:"operator"`
 	Value    string `json:"value"`
+	// Attribute is the key to filter on when Field is a JSONB column.
+	Attribute string `json:"attribute,omitempty"`
 }
 
 // AllowedFields is a map of model names to a list of allowed fields for that model.
@@ -37,6 +39,12 @@ type AllowedFields map[string][]string
 
 // BuildPaginatedQuery builds a paginated query from the given base query, existing arguments, pagination options, filters JSON, and allowed fields.
 func BuildPaginatedQuery(baseQuery string, existingArgs []any, opts PaginationOptions, filtersJSON string, allowedFields AllowedFields) (string, []any, error) {
+	return BuildPaginatedQueryWithAttributes(baseQuery, existingArgs, opts, filtersJSON, allowedFields, nil)
+}
+
+// BuildPaginatedQueryWithAttributes is BuildPaginatedQuery that also accepts filters on the keys
+// of JSONB columns listed in allowedAttrs.
+func BuildPaginatedQueryWithAttributes(baseQuery string, existingArgs []any, opts PaginationOptions, filtersJSON string, allowedFields AllowedFields, allowedAttrs AllowedAttributes) (string, []any, error) {
 	if opts.Page <= 0 {
 		return "", nil, fmt.Errorf("invalid page number: %d", opts.Page)
 	}
@@ -51,7 +59,7 @@ func BuildPaginatedQuery(baseQuery string, existingArgs []any, opts PaginationOp
 		}
 	}
 
-	whereClause, filterArgs, err := buildWhereClause(filters, existingArgs, allowedFields)
+	whereClause, filterArgs, err := buildWhereClause(filters, existingArgs, allowedFields, allowedAttrs)
 	if err != nil {
 		return "", nil, err
 	}
@@ -92,12 +100,27 @@ func BuildPaginatedQuery(baseQuery string, existingArgs []any, opts PaginationOp
 }
 
 // buildWhereClause builds a WHERE clause from the given filters and returns the WHERE clause and the arguments to be passed to the query.
-func buildWhereClause(filters []Filter, existingArgs []interface{}, allowedFields AllowedFields) (string, []interface{}, error) {
+func buildWhereClause(filters []Filter, existingArgs []interface{}, allowedFields AllowedFields, allowedAttrs AllowedAttributes) (string, []interface{}, error) {
 	conditions := []string{}
 	args := []interface{}{}
 	paramCount := len(existingArgs) + 1
 
 	for _, f := range filters {
+		// Filters on keys of JSONB columns are gated by the allowed attributes.
+		if f.Attribute != "" {
+			if !attributeAllowed(allowedAttrs, f) {
+				return "", nil, fmt.Errorf("invalid attribute: %s for field: %s.%s", f.Attribute, f.Model, f.Field)
+			}
+			cond, condArgs, err := jsonbCondition(fmt.Sprintf("%s.%s", f.Model, f.Field), f, paramCount)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, cond)
+			args = append(args, condArgs...)
+			paramCount += len(condArgs)
+			continue
+		}
+
 		modelFields, ok := allowedFields[f.Model]
 		if !ok {
 			return "", nil, fmt.Errorf("invalid model: %s", f.Model)
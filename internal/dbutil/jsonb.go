@@ -0,0 +1,107 @@
+package dbutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var numberRe = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// AllowedAttributes is a map of JSONB columns, as "model.field", to the keys that can be filtered on.
+type AllowedAttributes map[string][]string
+
+// jsonbCondition builds the condition of a filter on a key of a JSONB column, with placeholders
+// starting at paramCount. The key is always passed as an argument. Comparisons are typed by the
+// filter value: dates (YYYY-MM-DD) compare the date part of the stored value and numbers only
+// match numeric values.
+func jsonbCondition(column string, f Filter, paramCount int) (string, []any, error) {
+	var (
+		val  = fmt.Sprintf("(%s->>$%d::TEXT)", column, paramCount)
+		typ  = fmt.Sprintf("COALESCE(jsonb_typeof(%s->$%d::TEXT), 'null')", column, paramCount)
+		next = fmt.Sprintf("$%d", paramCount+1)
+		args = []any{f.Attribute}
+	)
+
+	switch f.Operator {
+	case "key exists":
+		return fmt.Sprintf("%s ? $%d", column, paramCount), args, nil
+	case "key not exists":
+		return fmt.Sprintf("NOT (%s ? $%d)", column, paramCount), args, nil
+	case "jsonb_contains":
+		if !json.Valid([]byte(f.Value)) {
+			return "", nil, fmt.Errorf("invalid JSON value for jsonb_contains: %q", f.Value)
+		}
+		return fmt.Sprintf("%s @> jsonb_build_object($%d::TEXT, %s::JSONB)", column, paramCount, next), append(args, f.Value), nil
+	case "set":
+		return fmt.Sprintf("(%s <> 'null' AND %s <> '')", typ, val), args, nil
+	case "not set":
+		return fmt.Sprintf("(%s = 'null' OR %s = '')", typ, val), args, nil
+	case "ilike", "contains":
+		return fmt.Sprintf("%s ILIKE %s", val, next), append(args, "%"+f.Value+"%"), nil
+	case "not contains":
+		return fmt.Sprintf("(%s IS NULL OR %s NOT ILIKE %s)", val, val, next), append(args, "%"+f.Value+"%"), nil
+	case "equals", "not equals":
+		var cond string
+		switch {
+		case dateOnlyRe.MatchString(f.Value):
+			cond = fmt.Sprintf("LEFT(%s, 10) = %s", val, next)
+		case numberRe.MatchString(f.Value):
+			cond = fmt.Sprintf("(CASE WHEN %s = 'number' THEN %s::NUMERIC = %s::NUMERIC ELSE FALSE END)", typ, val, next)
+		default:
+			cond = fmt.Sprintf("%s = %s", val, next)
+		}
+		if f.Operator == "not equals" {
+			cond = fmt.Sprintf("(%s IS NULL OR NOT %s)", val, cond)
+		}
+		return cond, append(args, f.Value), nil
+	case "greater than", "less than":
+		op := ">"
+		if f.Operator == "less than" {
+			op = "<"
+		}
+		switch {
+		case dateOnlyRe.MatchString(f.Value):
+			return fmt.Sprintf("(%s = 'string' AND LEFT(%s, 10) %s %s)", typ, val, op, next), append(args, f.Value), nil
+		case numberRe.MatchString(f.Value):
+			return fmt.Sprintf("(CASE WHEN %s = 'number' THEN %s::NUMERIC %s %s::NUMERIC ELSE FALSE END)", typ, val, op, next), append(args, f.Value), nil
+		}
+		return "", nil, fmt.Errorf("invalid value for %s on attribute %s: %q", f.Operator, f.Attribute, f.Value)
+	case "in":
+		var arr []string
+		if err := json.Unmarshal([]byte(f.Value), &arr); err != nil {
+			return "", nil, fmt.Errorf("invalid array format for 'in' operator: %v", err)
+		}
+		placeholders := make([]string, len(arr))
+		for i, v := range arr {
+			placeholders[i] = fmt.Sprintf("$%d", paramCount+1+i)
+			args = append(args, v)
+		}
+		if len(arr) == 0 {
+			return "FALSE", nil, nil
+		}
+		return fmt.Sprintf("%s IN (%s)", val, strings.Join(placeholders, ",")), args, nil
+	case "between":
+		values := strings.Split(f.Value, ",")
+		if len(values) != 2 {
+			return "", nil, fmt.Errorf("between requires 2 values")
+		}
+		start, end := strings.TrimSpace(values[0]), strings.TrimSpace(values[1])
+		switch {
+		case dateOnlyRe.MatchString(start) && dateOnlyRe.MatchString(end):
+			return fmt.Sprintf("(%s = 'string' AND LEFT(%s, 10) BETWEEN %s AND $%d)", typ, val, next, paramCount+2), append(args, start, end), nil
+		case numberRe.MatchString(start) && numberRe.MatchString(end):
+			return fmt.Sprintf("(CASE WHEN %s = 'number' THEN %s::NUMERIC BETWEEN %s::NUMERIC AND $%d::NUMERIC ELSE FALSE END)", typ, val, next, paramCount+2), append(args, start, end), nil
+		}
+		return "", nil, fmt.Errorf("invalid values for between on attribute %s: %q", f.Attribute, f.Value)
+	}
+	return "", nil, fmt.Errorf("invalid operator: %s", f.Operator)
+}
+
+// attributeAllowed reports whether a filter on a key of a JSONB column is allowed.
+func attributeAllowed(allowed AllowedAttributes, f Filter) bool {
+	keys, ok := allowed[f.Model+"."+f.Field]
+	return ok && slices.Contains(keys, f.Attribute)
+}
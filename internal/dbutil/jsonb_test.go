@@ -0,0 +1,48 @@
+package dbutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildWhereClauseAttributes(t *testing.T) {
+	var (
+		fields = AllowedFields{"conversations": {"status_id"}}
+		attrs  = AllowedAttributes{"conversations.custom_attributes": {"plan", "seats"}}
+	)
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		wantErr  bool
+		wantCond string
+		wantArgs int
+	}{
+		{"key not allowed", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "secret", Operator: "equals", Value: "x"}, true, "", 0},
+		{"column not allowed", Filter{Model: "conversations", Field: "meta", Attribute: "plan", Operator: "equals", Value: "x"}, true, "", 0},
+		{"text equals", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "plan", Operator: "equals", Value: "pro"}, false, "(conversations.custom_attributes->>$2::TEXT) = $3", 2},
+		{"numeric greater than", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "seats", Operator: "greater than", Value: "10"}, false, "::NUMERIC > $3::NUMERIC", 2},
+		{"non numeric greater than", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "seats", Operator: "greater than", Value: "ten"}, true, "", 0},
+		{"key exists", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "plan", Operator: "key exists"}, false, "conversations.custom_attributes ? $2", 1},
+		{"jsonb contains", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "plan", Operator: "jsonb_contains", Value: `"pro"`}, false, "@> jsonb_build_object($2::TEXT, $3::JSONB)", 2},
+		{"jsonb contains invalid", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "plan", Operator: "jsonb_contains", Value: `pro`}, true, "", 0},
+		{"in", Filter{Model: "conversations", Field: "custom_attributes", Attribute: "plan", Operator: "in", Value: `["free","pro"]`}, false, "IN ($3,$4)", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args, err := buildWhereClause([]Filter{tt.filter}, []any{1}, fields, attrs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildWhereClause() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.Contains(cond, tt.wantCond) {
+				t.Errorf("buildWhereClause() cond = %q, want it to contain %q", cond, tt.wantCond)
+			}
+			if len(args) != tt.wantArgs {
+				t.Errorf("buildWhereClause() args = %v, want %d args", args, tt.wantArgs)
+			}
+		})
+	}
+}
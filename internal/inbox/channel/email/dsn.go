@@ -0,0 +1,107 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"mime"
+	"net/textproto"
+	"strings"
+
+	"github.com/jhillyerd/enmime"
+)
+
+const (
+	contentTypeDeliveryStatus = "message/delivery-status"
+	contentTypeRFC822         = "message/rfc822"
+	contentTypeRFC822Headers  = "text/rfc822-headers"
+	reportTypeDeliveryStatus  = "delivery-status"
+)
+
+// dsnActionFailed is the action of recipients a message could not be delivered to.
+const dsnActionFailed = "failed"
+
+// deliveryReport is a delivery status notification (DSN, RFC 3464) received for an outgoing message,
+// e.g. a bounce.
+type deliveryReport struct {
+	// OriginalMessageID is the Message-ID of the message the report is for, without angle brackets.
+	OriginalMessageID string
+	// Action is the action of the first recipient the report is about, e.g. "failed" or "delayed".
+	Action string
+	// Status is the status code of the recipient, e.g. "5.1.1".
+	Status string
+	// Diagnostic is the diagnostic code of the remote server, if any.
+	Diagnostic string
+	// Recipient is the address of the recipient.
+	Recipient string
+}
+
+// isDeliveryReport reports whether the Content-Type header of the email is that of a delivery status notification.
+func isDeliveryReport(envelope *enmime.Envelope) bool {
+	mediaType, params, err := mime.ParseMediaType(envelope.GetHeader("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == contentTypeReport && strings.EqualFold(params["report-type"], reportTypeDeliveryStatus)
+}
+
+// parseDeliveryReport returns the delivery status notification carried by the email, ok is false if it carries none
+// or the message it is for can't be told.
+func parseDeliveryReport(envelope *enmime.Envelope) (deliveryReport, bool) {
+	part := findPart(envelope.Root, contentTypeDeliveryStatus)
+	if part == nil {
+		return deliveryReport{}, false
+	}
+
+	// The per-message fields come first, followed by a group of fields for every recipient.
+	var r deliveryReport
+	groups := strings.Split(strings.ReplaceAll(string(part.Content), "\r\n", "\n"), "\n\n")
+	for _, group := range groups[min(1, len(groups)-1):] {
+		fields := readFields([]byte(group))
+		action := strings.ToLower(strings.TrimSpace(fields.Get("Action")))
+		if action == "" {
+			continue
+		}
+		r.Action = action
+		r.Status = strings.TrimSpace(fields.Get("Status"))
+		r.Diagnostic = strings.TrimSpace(fields.Get("Diagnostic-Code"))
+		// e.g. "rfc822; customer@example.com".
+		recipient := fields.Get("Final-Recipient")
+		if i := strings.Index(recipient, ";"); i >= 0 {
+			recipient = recipient[i+1:]
+		}
+		r.Recipient = strings.TrimSpace(recipient)
+		// Failures are the most useful to report if the notification covers several recipients.
+		if action == dsnActionFailed {
+			break
+		}
+	}
+	if r.Action == "" {
+		return deliveryReport{}, false
+	}
+
+	// The original message or its headers are attached to the report.
+	for _, contentType := range []string{contentTypeRFC822Headers, contentTypeRFC822} {
+		if original := findPart(envelope.Root, contentType); original != nil {
+			r.OriginalMessageID = strings.Trim(strings.TrimSpace(readFields(original.Content).Get("Message-ID")), "<>")
+			if r.OriginalMessageID != "" {
+				break
+			}
+		}
+	}
+	if r.OriginalMessageID == "" {
+		r.OriginalMessageID = strings.Trim(strings.TrimSpace(envelope.GetHeader(headerInReplyTo)), "<>")
+	}
+	if r.OriginalMessageID == "" {
+		return deliveryReport{}, false
+	}
+	return r, true
+}
+
+// readFields reads header style fields, returning the ones read before any error.
+func readFields(b []byte) textproto.MIMEHeader {
+	fields, _ := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(bytes.TrimSpace(b), "\r\n\r\n"...)))).ReadMIMEHeader()
+	if fields == nil {
+		return textproto.MIMEHeader{}
+	}
+	return fields
+}
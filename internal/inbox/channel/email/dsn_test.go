@@ -0,0 +1,99 @@
+package email
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhillyerd/enmime"
+)
+
+func TestParseDeliveryReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected deliveryReport
+		ok       bool
+	}{
+		{
+			name: "bounce with original headers",
+			email: "From: MAILER-DAEMON@mx.example.com\r\n" +
+				"To: support@example.com\r\n" +
+				"Subject: Undelivered Mail Returned to Sender\r\n" +
+				"Content-Type: multipart/report; report-type=delivery-status; boundary=\"b\"\r\n" +
+				"\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"Your message could not be delivered.\r\n" +
+				"--b\r\n" +
+				"Content-Type: message/delivery-status\r\n" +
+				"\r\n" +
+				"Reporting-MTA: dns; mx.example.com\r\n" +
+				"\r\n" +
+				"Final-Recipient: rfc822; gone@example.org\r\n" +
+				"Action: failed\r\n" +
+				"Status: 5.1.1\r\n" +
+				"Diagnostic-Code: smtp; 550 5.1.1 User unknown\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/rfc822-headers\r\n" +
+				"\r\n" +
+				"From: support@example.com\r\n" +
+				"Message-ID: <abc@support.example.com>\r\n" +
+				"Subject: Your order\r\n" +
+				"--b--\r\n",
+			expected: deliveryReport{
+				OriginalMessageID: "abc@support.example.com",
+				Action:            "failed",
+				Status:            "5.1.1",
+				Diagnostic:        "smtp; 550 5.1.1 User unknown",
+				Recipient:         "gone@example.org",
+			},
+			ok: true,
+		},
+		{
+			name: "delay with In-Reply-To fallback",
+			email: "From: MAILER-DAEMON@mx.example.com\r\n" +
+				"In-Reply-To: <def@support.example.com>\r\n" +
+				"Content-Type: multipart/report; report-type=delivery-status; boundary=\"b\"\r\n" +
+				"\r\n" +
+				"--b\r\n" +
+				"Content-Type: message/delivery-status\r\n" +
+				"\r\n" +
+				"Reporting-MTA: dns; mx.example.com\r\n" +
+				"\r\n" +
+				"Final-Recipient: rfc822; slow@example.org\r\n" +
+				"Action: delayed\r\n" +
+				"Status: 4.4.7\r\n" +
+				"--b--\r\n",
+			expected: deliveryReport{
+				OriginalMessageID: "def@support.example.com",
+				Action:            "delayed",
+				Status:            "4.4.7",
+				Recipient:         "slow@example.org",
+			},
+			ok: true,
+		},
+		{
+			name: "regular message",
+			email: "From: customer@example.com\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"Hello\r\n",
+			ok: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := enmime.ReadEnvelope(strings.NewReader(tt.email))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, ok := parseDeliveryReport(envelope)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.expected, tt.ok, result, ok)
+			}
+		})
+	}
+}
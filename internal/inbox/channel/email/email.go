@@ -35,10 +35,23 @@ type Email struct {
 	from                 string
 	replyTo              string
 	enablePlusAddressing bool
+	previousAddresses    []string
+	mdnPolicy            string
+	requestReadReceipts  bool
+	trackOpens           bool
+	sendRateLimit        int
+	sendConcurrency      int
 	messageStore         inbox.MessageStore
 	userStore            inbox.UserStore
+	senderFilter         inbox.SenderFilter
 	wg                   sync.WaitGroup
 	tokenRefreshCallback TokenRefreshCallback
+	retainHeaders        []string
+
+	// Health of the IMAP pollers keyed by mailbox, and of the SMTP sender.
+	healthMu  sync.RWMutex
+	receivers map[string]*receiverState
+	sender    senderState
 }
 
 // TokenRefreshCallback is called when OAuth tokens are refreshed.
@@ -52,6 +65,8 @@ type Opts struct {
 	Config               models.Config
 	Lo                   *logf.Logger
 	TokenRefreshCallback TokenRefreshCallback // Optional callback for token refresh
+	RetainHeaders        []string             // Raw headers of incoming messages to store for abuse investigations
+	SenderFilter         inbox.SenderFilter   // Optional per inbox sender block list
 }
 
 // New returns a new instance of the email inbox.
@@ -81,7 +96,15 @@ func New(store inbox.MessageStore, userStore inbox.UserStore, opts Opts) (*Email
 		oauth:                opts.Config.OAuth,
 		authType:             opts.Config.AuthType,
 		enablePlusAddressing: opts.Config.EnablePlusAddressing,
+		previousAddresses:    opts.Config.PreviousAddresses,
+		mdnPolicy:            opts.Config.MDNPolicy,
+		requestReadReceipts:  opts.Config.RequestReadReceipts,
+		trackOpens:           opts.Config.TrackOpens,
+		sendRateLimit:        max(opts.Config.SendRateLimit, 0),
+		sendConcurrency:      max(opts.Config.SendConcurrency, 0),
 		tokenRefreshCallback: opts.TokenRefreshCallback,
+		retainHeaders:        opts.RetainHeaders,
+		senderFilter:         opts.SenderFilter,
 	}
 	return e, nil
 }
@@ -126,6 +149,11 @@ func (e *Email) Channel() string {
 	return ChannelEmail
 }
 
+// SendLimits returns the maximum number of messages the inbox sends per minute and at once, zero is unlimited.
+func (e *Email) SendLimits() (int, int) {
+	return e.sendRateLimit, e.sendConcurrency
+}
+
 // getCurrentConfig returns the current config with all SMTP and IMAP settings.
 func (e *Email) getCurrentConfig() models.Config {
 	e.oauthMu.RLock()
@@ -140,6 +168,10 @@ func (e *Email) getCurrentConfig() models.Config {
 		OAuth:                oauth,
 		AuthType:             e.authType,
 		EnablePlusAddressing: e.enablePlusAddressing,
+		PreviousAddresses:    e.previousAddresses,
+		MDNPolicy:            e.mdnPolicy,
+		RequestReadReceipts:  e.requestReadReceipts,
+		TrackOpens:           e.trackOpens,
 	}
 }
 
@@ -0,0 +1,124 @@
+package email
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/inbox"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// missedPollsThreshold is the number of read intervals without a successful poll after which a mailbox is unhealthy.
+	missedPollsThreshold = 3
+
+	// sendFailuresThreshold is the number of consecutive failed sends after which the sender is unhealthy,
+	// provided none has succeeded for sendFailureWindow. A single rejected recipient does not flip it.
+	sendFailuresThreshold = 5
+	sendFailureWindow     = 10 * time.Minute
+)
+
+// receiverState tracks the outcome of the polls of a single IMAP mailbox.
+type receiverState struct {
+	interval      time.Duration
+	startedAt     time.Time
+	lastPollAt    null.Time
+	lastSuccessAt null.Time
+	lastError     string
+}
+
+// senderState tracks the outcome of SMTP sends.
+type senderState struct {
+	lastSendAt          null.Time
+	lastSuccessAt       null.Time
+	firstFailureAt      time.Time
+	consecutiveFailures int
+	lastError           string
+}
+
+// startReceiverHealth registers a mailbox poller for health reporting.
+func (e *Email) startReceiverHealth(name string, interval time.Duration) {
+	e.healthMu.Lock()
+	defer e.healthMu.Unlock()
+	if e.receivers == nil {
+		e.receivers = make(map[string]*receiverState)
+	}
+	e.receivers[name] = &receiverState{interval: interval, startedAt: time.Now()}
+}
+
+// recordPoll records the outcome of a mailbox poll.
+func (e *Email) recordPoll(name string, err error) {
+	e.healthMu.Lock()
+	defer e.healthMu.Unlock()
+	s, ok := e.receivers[name]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	s.lastPollAt = null.TimeFrom(now)
+	if err != nil {
+		s.lastError = err.Error()
+		return
+	}
+	s.lastSuccessAt = null.TimeFrom(now)
+	s.lastError = ""
+}
+
+// recordSend records the outcome of an SMTP send.
+func (e *Email) recordSend(err error) {
+	e.healthMu.Lock()
+	defer e.healthMu.Unlock()
+	now := time.Now()
+	e.sender.lastSendAt = null.TimeFrom(now)
+	if err != nil {
+		if e.sender.consecutiveFailures == 0 {
+			e.sender.firstFailureAt = now
+		}
+		e.sender.consecutiveFailures++
+		e.sender.lastError = err.Error()
+		return
+	}
+	e.sender.lastSuccessAt = null.TimeFrom(now)
+	e.sender.consecutiveFailures = 0
+	e.sender.lastError = ""
+}
+
+// Health returns the status of the inbox's IMAP pollers and SMTP sender.
+// A mailbox is unhealthy if it has not been polled successfully for missedPollsThreshold read intervals,
+// the sender is unhealthy if at least sendFailuresThreshold consecutive sends have failed over sendFailureWindow.
+func (e *Email) Health() inbox.Health {
+	e.healthMu.RLock()
+	defer e.healthMu.RUnlock()
+
+	h := inbox.Health{
+		Healthy:   true,
+		Receivers: make([]inbox.ReceiverHealth, 0, len(e.receivers)),
+		Sender: inbox.SenderHealth{
+			Healthy:             e.sender.consecutiveFailures < sendFailuresThreshold || time.Since(e.sender.firstFailureAt) < sendFailureWindow,
+			LastSendAt:          e.sender.lastSendAt,
+			LastSuccessAt:       e.sender.lastSuccessAt,
+			ConsecutiveFailures: e.sender.consecutiveFailures,
+			LastError:           e.sender.lastError,
+		},
+	}
+
+	now := time.Now()
+	for name, s := range e.receivers {
+		since := s.startedAt
+		if s.lastSuccessAt.Valid {
+			since = s.lastSuccessAt.Time
+		}
+		r := inbox.ReceiverHealth{
+			Name:          name,
+			Healthy:       now.Sub(since) <= missedPollsThreshold*s.interval,
+			LastPollAt:    s.lastPollAt,
+			LastSuccessAt: s.lastSuccessAt,
+			LastError:     s.lastError,
+		}
+		h.Healthy = h.Healthy && r.Healthy
+		h.Receivers = append(h.Receivers, r)
+	}
+	sort.Slice(h.Receivers, func(i, j int) bool { return h.Receivers[i].Name < h.Receivers[j].Name })
+	h.Healthy = h.Healthy && h.Sender.Healthy
+	return h
+}
@@ -0,0 +1,34 @@
+package email
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSenderHealth(t *testing.T) {
+	e := &Email{}
+	rejected := errors.New("550 mailbox unavailable")
+
+	e.recordSend(rejected)
+	if !e.Health().Sender.Healthy {
+		t.Fatal("a single failed send should not make the sender unhealthy")
+	}
+
+	for i := 1; i < sendFailuresThreshold; i++ {
+		e.recordSend(rejected)
+	}
+	if !e.Health().Sender.Healthy {
+		t.Fatal("failures within the window should not make the sender unhealthy")
+	}
+
+	e.sender.firstFailureAt = time.Now().Add(-sendFailureWindow)
+	if e.Health().Sender.Healthy {
+		t.Fatal("sustained failures should make the sender unhealthy")
+	}
+
+	e.recordSend(nil)
+	if h := e.Health().Sender; !h.Healthy || h.ConsecutiveFailures != 0 {
+		t.Fatalf("a successful send should reset the sender, got %+v", h)
+	}
+}
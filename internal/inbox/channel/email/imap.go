@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -37,6 +38,9 @@ func (e *Email) ReadIncomingMessages(ctx context.Context, cfg imodels.IMAPConfig
 		scanInboxSince = defaultScanInboxSince
 	}
 
+	healthName := fmt.Sprintf("%s@%s/%s", cfg.Username, cfg.Host, cfg.Mailbox)
+	e.startReceiverHealth(healthName, readInterval)
+
 	readTicker := time.NewTicker(readInterval)
 	defer readTicker.Stop()
 
@@ -51,9 +55,13 @@ func (e *Email) ReadIncomingMessages(ctx context.Context, cfg imodels.IMAPConfig
 				return nil
 			}
 
-			if err := e.processMailbox(ctx, scanInboxSince, cfg); err != nil && err != context.Canceled {
+			err := e.processMailbox(ctx, scanInboxSince, cfg)
+			if err != nil && err != context.Canceled {
 				e.lo.Error("error searching emails", "error", err)
 			}
+			if err != context.Canceled {
+				e.recordPoll(healthName, err)
+			}
 			e.lo.Info("email search complete", "mailbox", cfg.Mailbox, "inbox_id", e.Identifier())
 		}
 	}
@@ -181,6 +189,7 @@ func (e *Email) fetchAndProcessMessages(ctx context.Context, client *imapclient.
 					headerAutoreply,
 					headerLibredeskLoopPrevention,
 					headerMessageID,
+					"Content-Type",
 				},
 			},
 		},
@@ -252,10 +261,11 @@ func (e *Email) fetchAndProcessMessages(ctx context.Context, client *imapclient.
 					e.lo.Error("error reading envelope", "error", err)
 					continue
 				}
-				if isAutoReply(envelope) {
+				// Read receipts and delivery reports are often auto-submitted too, they are recorded on the messages they are for.
+				if isAutoReply(envelope) && !isReadReceiptReport(envelope) && !isDeliveryReport(envelope) {
 					autoReply = true
 				}
-				if isLoopMessage(envelope, inboxEmail) {
+				if isLoopMessage(envelope, append([]string{inboxEmail}, e.previousAddresses...)...) {
 					isLoop = true
 				}
 
@@ -350,6 +360,17 @@ func (e *Email) processEnvelope(ctx context.Context, client *imapclient.Client,
 		return nil
 	}
 
+	// Check if the sender is blocked in this inbox, e.g. after marking their mail as spam.
+	if e.senderFilter != nil {
+		if blocked, err := e.senderFilter.IsSenderBlocked(inboxID, fromAddress); err != nil {
+			e.lo.Error("error checking if sender is blocked", "email", fromAddress, "inbox_id", inboxID, "error", err)
+			return fmt.Errorf("checking if sender is blocked: %w", err)
+		} else if blocked {
+			e.lo.Info("sender is blocked in inbox dropping incoming email", "email", fromAddress, "inbox_id", inboxID)
+			return nil
+		}
+	}
+
 	e.lo.Debug("processing new incoming message", "message_id", messageID, "subject", env.Subject, "from", fromAddress, "inbox_id", inboxID)
 
 	// Make contact.
@@ -456,6 +477,30 @@ func (e *Email) processFullMessage(item imapclient.FetchItemDataBodySection, inc
 		e.lo.Error("error parsing email envelope", "error", err.Error(), "message_id", incomingMsg.SourceID.String)
 	}
 
+	// Record read receipts on the messages they are for instead of adding them to conversations.
+	if receipt, ok := parseReadReceipt(envelope); ok {
+		e.lo.Debug("recording read receipt", "original_message_id", receipt.OriginalMessageID, "disposition", receipt.Disposition)
+		return e.messageStore.RecordReadReceipt(receipt.OriginalMessageID, receipt.Disposition, incomingMsg.Contact.Email.String)
+	}
+
+	// Record delivery reports such as bounces on the messages they are for too.
+	if report, ok := parseDeliveryReport(envelope); ok {
+		e.lo.Debug("recording delivery report", "original_message_id", report.OriginalMessageID, "action", report.Action, "status", report.Status)
+		return e.messageStore.RecordDeliveryReport(report.OriginalMessageID, report.Action, report.Status, report.Diagnostic, report.Recipient)
+	}
+
+	// Retain configured raw headers.
+	for _, name := range e.retainHeaders {
+		vals := envelope.GetHeaderValues(name)
+		if len(vals) == 0 {
+			continue
+		}
+		if incomingMsg.Headers == nil {
+			incomingMsg.Headers = make(map[string][]string, len(e.retainHeaders))
+		}
+		incomingMsg.Headers[textproto.CanonicalMIMEHeaderKey(name)] = vals
+	}
+
 	// Extract all HTML content by traversing the tree
 	var allHTML strings.Builder
 	if envelope.Root != nil {
@@ -538,6 +583,7 @@ func (e *Email) processFullMessage(item imapclient.FetchItemDataBodySection, inc
 	if err := e.messageStore.EnqueueIncoming(incomingMsg); err != nil {
 		return err
 	}
+	e.handleReadReceiptRequest(envelope, incomingMsg.Contact.Email.String, incomingMsg.SourceID.String, incomingMsg.Subject)
 	return nil
 }
 
@@ -565,13 +611,19 @@ func isAutoReply(envelope *enmime.Envelope) bool {
 	return false
 }
 
-// isLoopMessage returns true if the email is a loop prevention message. i.e., it has the `X-Libredesk-Loop-Prevention` header with the inbox email address.
-func isLoopMessage(envelope *enmime.Envelope, inboxEmailaddress string) bool {
+// isLoopMessage returns true if the email is a loop prevention message. i.e., it has the `X-Libredesk-Loop-Prevention` header
+// with one of the inbox email addresses, current or previous.
+func isLoopMessage(envelope *enmime.Envelope, inboxAddresses ...string) bool {
 	loopHeader := envelope.GetHeader(headerLibredeskLoopPrevention)
 	if loopHeader == "" {
 		return false
 	}
-	return strings.EqualFold(loopHeader, inboxEmailaddress)
+	for _, addr := range inboxAddresses {
+		if strings.EqualFold(loopHeader, addr) {
+			return true
+		}
+	}
+	return false
 }
 
 // extractAllHTMLParts extracts all HTML parts from the given enmime part by traversing the tree.
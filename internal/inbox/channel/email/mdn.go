@@ -0,0 +1,152 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"mime"
+	"net/textproto"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/inbox/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/jhillyerd/enmime"
+	"github.com/knadh/smtppool"
+)
+
+const (
+	contentTypeReport                  = "multipart/report"
+	contentTypeDispositionNotification = "message/disposition-notification"
+	reportTypeDispositionNotification  = "disposition-notification"
+)
+
+// readReceipt is a read receipt (MDN, RFC 8098) received for an outgoing message.
+type readReceipt struct {
+	// OriginalMessageID is the Message-ID of the message the receipt is for, without angle brackets.
+	OriginalMessageID string
+	// Disposition is the disposition type, e.g. "displayed", "deleted" or "processed".
+	Disposition string
+}
+
+// isReadReceiptReport reports whether the Content-Type header of the email is that of a read receipt.
+// Only the headers are needed, so that receipts can be told apart from other auto-submitted mail.
+func isReadReceiptReport(envelope *enmime.Envelope) bool {
+	mediaType, params, err := mime.ParseMediaType(envelope.GetHeader("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == contentTypeReport && strings.EqualFold(params["report-type"], reportTypeDispositionNotification)
+}
+
+// parseReadReceipt returns the read receipt carried by the email, ok is false if it carries none.
+// Receipts with a message/disposition-notification part outside of a multipart/report are accepted too.
+func parseReadReceipt(envelope *enmime.Envelope) (readReceipt, bool) {
+	part := findPart(envelope.Root, contentTypeDispositionNotification)
+	if part == nil {
+		return readReceipt{}, false
+	}
+
+	// The notification fields have the same syntax as message headers.
+	fields, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(bytes.TrimSpace(part.Content), "\r\n\r\n"...)))).ReadMIMEHeader()
+	if err != nil && len(fields) == 0 {
+		return readReceipt{}, false
+	}
+
+	r := readReceipt{
+		OriginalMessageID: strings.Trim(strings.TrimSpace(fields.Get("Original-Message-ID")), "<>"),
+	}
+	if r.OriginalMessageID == "" {
+		r.OriginalMessageID = strings.Trim(strings.TrimSpace(envelope.GetHeader(headerInReplyTo)), "<>")
+	}
+
+	// e.g. "manual-action/MDN-sent-manually; displayed".
+	disposition := fields.Get("Disposition")
+	if i := strings.LastIndex(disposition, ";"); i >= 0 {
+		disposition = disposition[i+1:]
+	}
+	// Drop modifiers such as "displayed/error".
+	disposition, _, _ = strings.Cut(disposition, "/")
+	r.Disposition = strings.ToLower(strings.TrimSpace(disposition))
+
+	if r.OriginalMessageID == "" || r.Disposition == "" {
+		return readReceipt{}, false
+	}
+	return r, true
+}
+
+// findPart returns the first part in the tree with the given content type.
+func findPart(part *enmime.Part, contentType string) *enmime.Part {
+	if part == nil {
+		return nil
+	}
+	if strings.EqualFold(part.ContentType, contentType) {
+		return part
+	}
+	for child := part.FirstChild; child != nil; child = child.NextSibling {
+		if p := findPart(child, contentType); p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+// readReceiptRecipient returns the address the sender of the email asked to send a read receipt to.
+// It is empty if no receipt was asked for, or if the address is not the sender's, as receipts
+// must not be sent to third parties.
+func readReceiptRecipient(envelope *enmime.Envelope, fromAddress string) string {
+	addr, err := stringutil.ExtractEmail(envelope.GetHeader(headerDispositionNotification))
+	if err != nil || !strings.EqualFold(addr, fromAddress) {
+		return ""
+	}
+	return addr
+}
+
+// handleReadReceiptRequest sends the read receipt the sender of an incoming message asked for if the
+// inbox policy allows it. The receipt reports the message as processed as it is sent on arrival,
+// not when an agent reads the message.
+func (e *Email) handleReadReceiptRequest(envelope *enmime.Envelope, fromAddress, messageID, subject string) {
+	if e.mdnPolicy != models.MDNPolicySend {
+		return
+	}
+	to := readReceiptRecipient(envelope, fromAddress)
+	if to == "" {
+		return
+	}
+	if err := e.sendReadReceipt(to, messageID, subject); err != nil {
+		e.lo.Error("error sending read receipt", "message_id", messageID, "to", to, "error", err)
+		return
+	}
+	e.lo.Debug("sent read receipt", "message_id", messageID, "to", to)
+}
+
+// sendReadReceipt sends a read receipt for the message with the given Message-ID.
+// smtppool always writes multipart/mixed messages, so the notification is sent as a
+// message/disposition-notification part next to a human readable text instead of in a multipart/report.
+func (e *Email) sendReadReceipt(to, messageID, subject string) error {
+	inboxEmail, err := stringutil.ExtractEmail(e.FromAddress())
+	if err != nil {
+		return fmt.Errorf("extracting inbox email address: %w", err)
+	}
+
+	fields := fmt.Sprintf("Reporting-UA: Libredesk\r\nFinal-Recipient: rfc822; %s\r\nOriginal-Message-ID: <%s>\r\nDisposition: automatic-action/MDN-sent-automatically; processed\r\n", inboxEmail, messageID)
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Type", contentTypeDispositionNotification)
+	partHeader.Set("Content-Transfer-Encoding", "base64")
+
+	email := smtppool.Email{
+		From:    e.FromAddress(),
+		To:      []string{to},
+		Subject: "Received: " + subject,
+		Text:    []byte(fmt.Sprintf("Your message %q was received.\r\n", subject)),
+		Headers: textproto.MIMEHeader{},
+		Attachments: []smtppool.Attachment{{
+			Filename: "MDN",
+			Header:   partHeader,
+			Content:  []byte(fields),
+		}},
+	}
+	email.Headers.Set(headerLibredeskLoopPrevention, inboxEmail)
+	email.Headers.Set(headerInReplyTo, "<"+messageID+">")
+	email.Headers.Set(headerReferences, "<"+messageID+">")
+	return e.deliver(email)
+}
@@ -0,0 +1,110 @@
+package email
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhillyerd/enmime"
+)
+
+func TestParseReadReceipt(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected readReceipt
+		ok       bool
+	}{
+		{
+			name: "multipart/report",
+			email: "From: customer@example.com\r\n" +
+				"To: support@example.com\r\n" +
+				"Subject: Read: Your order\r\n" +
+				"Content-Type: multipart/report; report-type=disposition-notification; boundary=\"b\"\r\n" +
+				"\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"Your message was displayed.\r\n" +
+				"--b\r\n" +
+				"Content-Type: message/disposition-notification\r\n" +
+				"\r\n" +
+				"Reporting-UA: mail.example.com\r\n" +
+				"Final-Recipient: rfc822; customer@example.com\r\n" +
+				"Original-Message-ID: <abc@support.example.com>\r\n" +
+				"Disposition: manual-action/MDN-sent-manually; displayed\r\n" +
+				"--b--\r\n",
+			expected: readReceipt{OriginalMessageID: "abc@support.example.com", Disposition: "displayed"},
+			ok:       true,
+		},
+		{
+			name: "notification part in multipart/mixed, In-Reply-To fallback",
+			email: "From: customer@example.com\r\n" +
+				"In-Reply-To: <def@support.example.com>\r\n" +
+				"Content-Type: multipart/mixed; boundary=\"b\"\r\n" +
+				"\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"Received.\r\n" +
+				"--b\r\n" +
+				"Content-Type: message/disposition-notification\r\n" +
+				"Content-Transfer-Encoding: base64\r\n" +
+				"\r\n" +
+				"RGlzcG9zaXRpb246IGF1dG9tYXRpYy1hY3Rpb24vTUROLXNlbnQtYXV0b21hdGljYWxseTsgcHJvY2Vzc2VkDQo=\r\n" +
+				"--b--\r\n",
+			expected: readReceipt{OriginalMessageID: "def@support.example.com", Disposition: "processed"},
+			ok:       true,
+		},
+		{
+			name: "regular message",
+			email: "From: customer@example.com\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"Hello\r\n",
+			ok: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := enmime.ReadEnvelope(strings.NewReader(tt.email))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, ok := parseReadReceipt(envelope)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.expected, tt.ok, result, ok)
+			}
+		})
+	}
+}
+
+func TestReadReceiptRecipient(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"sender", "Customer <customer@example.com>", "customer@example.com"},
+		{"third party", "tracker@other.com", ""},
+		{"not asked", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := "From: customer@example.com\r\n"
+			if tt.header != "" {
+				raw += "Disposition-Notification-To: " + tt.header + "\r\n"
+			}
+			envelope, err := enmime.ReadEnvelope(strings.NewReader(raw + "\r\nHello\r\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := readReceiptRecipient(envelope, "customer@example.com"); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ const (
 	headerLibredeskConversationID = "X-Libredesk-Conversation-UUID"
 	headerAutoreply               = "X-Autoreply"
 	headerAutoSubmitted           = "Auto-Submitted"
+	headerDispositionNotification = "Disposition-Notification-To"
 
 	dispositionInline = "inline"
 )
@@ -106,34 +107,6 @@ func NewSmtpPool(configs []imodels.SMTPConfig, oauth *imodels.OAuthConfig) ([]*s
 
 // Send sends an email using one of the configured SMTP servers.
 func (e *Email) Send(m models.OutboundMessage) error {
-	// Refresh OAuth token if needed
-	oauthConfig, _, err := e.refreshOAuthIfNeeded()
-	if err != nil {
-		return err
-	}
-
-	// Recreate SMTP pools if token changed (handles both: we refreshed or IMAP refreshed)
-	if e.authType == imodels.AuthTypeOAuth2 && oauthConfig != nil {
-		e.smtpPoolsMu.Lock()
-		if e.smtpPoolsToken != oauthConfig.AccessToken {
-			// Close existing pools
-			for _, p := range e.smtpPools {
-				p.Close()
-			}
-
-			// Create new pools with current token
-			newPools, err := NewSmtpPool(e.smtpCfg, oauthConfig)
-			if err != nil {
-				e.smtpPoolsMu.Unlock()
-				e.lo.Error("failed to recreate smtp pools after token refresh", "inbox_id", e.Identifier(), "error", err)
-				return fmt.Errorf("failed to recreate SMTP pools: %w", err)
-			}
-			e.smtpPools = newPools
-			e.smtpPoolsToken = oauthConfig.AccessToken
-		}
-		e.smtpPoolsMu.Unlock()
-	}
-
 	// Prepare attachments if there are any
 	var attachments []smtppool.Attachment
 	if m.Attachments != nil {
@@ -198,6 +171,11 @@ func (e *Email) Send(m models.OutboundMessage) error {
 
 	e.lo.Debug("references header set", "references", references)
 
+	// Ask for a read receipt, only messages with a Message-ID can be matched to the receipt.
+	if e.requestReadReceipts && m.SourceID != "" {
+		email.Headers.Set(headerDispositionNotification, emailAddress)
+	}
+
 	// Set conversation uuid header
 	if m.ConversationUUID != "" {
 		email.Headers.Set(headerLibredeskConversationID, m.ConversationUUID)
@@ -215,6 +193,39 @@ func (e *Email) Send(m models.OutboundMessage) error {
 		}
 	}
 
+	return e.deliver(email)
+}
+
+// deliver sends a prepared email using one of the configured SMTP servers.
+func (e *Email) deliver(email smtppool.Email) error {
+	// Refresh OAuth token if needed
+	oauthConfig, _, err := e.refreshOAuthIfNeeded()
+	if err != nil {
+		return err
+	}
+
+	// Recreate SMTP pools if token changed (handles both: we refreshed or IMAP refreshed)
+	if e.authType == imodels.AuthTypeOAuth2 && oauthConfig != nil {
+		e.smtpPoolsMu.Lock()
+		if e.smtpPoolsToken != oauthConfig.AccessToken {
+			// Close existing pools
+			for _, p := range e.smtpPools {
+				p.Close()
+			}
+
+			// Create new pools with current token
+			newPools, err := NewSmtpPool(e.smtpCfg, oauthConfig)
+			if err != nil {
+				e.smtpPoolsMu.Unlock()
+				e.lo.Error("failed to recreate smtp pools after token refresh", "inbox_id", e.Identifier(), "error", err)
+				return fmt.Errorf("failed to recreate SMTP pools: %w", err)
+			}
+			e.smtpPools = newPools
+			e.smtpPoolsToken = oauthConfig.AccessToken
+		}
+		e.smtpPoolsMu.Unlock()
+	}
+
 	e.smtpPoolsMu.RLock()
 	defer e.smtpPoolsMu.RUnlock()
 
@@ -227,7 +238,9 @@ func (e *Email) Send(m models.OutboundMessage) error {
 	} else {
 		server = e.smtpPools[0]
 	}
-	return server.Send(email)
+	err = server.Send(email)
+	e.recordSend(err)
+	return err
 }
 
 // buildPlusAddress creates a plus-addressed email for conversation matching.
@@ -151,6 +151,13 @@ type LiveChat struct {
 	signAvatarURL func(*null.String)   // Signs a raw /uploads/ avatar path into a signed URL.
 	clients       map[string][]*Client // Maps user IDs to slices of clients (to handle multiple devices)
 	clientsMutex  sync.RWMutex
+	relay         Relay
+}
+
+// Relay publishes broadcasts to widget clients to the other app instances, which deliver them to the
+// clients connected to them with DeliverToContact.
+type Relay interface {
+	PublishWidget(inboxID, contactID int, data []byte)
 }
 
 // Opts holds the options required for the live chat inbox.
@@ -160,6 +167,8 @@ type Opts struct {
 	From          string
 	Lo            *logf.Logger
 	SignAvatarURL func(*null.String)
+	// Relay is optional, without it broadcasts only reach clients connected to this instance.
+	Relay Relay
 }
 
 // New returns a new instance of the live chat inbox.
@@ -173,6 +182,7 @@ func New(store inbox.MessageStore, userStore inbox.UserStore, opts Opts) (*LiveC
 		userStore:     userStore,
 		signAvatarURL: opts.SignAvatarURL,
 		clients:       make(map[string][]*Client),
+		relay:         opts.Relay,
 	}
 	return lc, nil
 }
@@ -194,13 +204,9 @@ func (lc *LiveChat) Send(message models.OutboundMessage) error {
 		return nil
 	}
 
-	msgReceiverStr := strconv.Itoa(message.MessageReceiverID)
-	lc.clientsMutex.RLock()
-	defer lc.clientsMutex.RUnlock()
-
-	clients, exists := lc.clients[msgReceiverStr]
-	if !exists {
-		lc.lo.Debug("websocket client not connected for live chat message", "receiver_id", msgReceiverStr, "message_id", message.UUID)
+	// The contact may be connected to another app instance if broadcasts are relayed.
+	if lc.relay == nil && !lc.hasClients(strconv.Itoa(message.MessageReceiverID)) {
+		lc.lo.Debug("websocket client not connected for live chat message", "receiver_id", message.MessageReceiverID, "message_id", message.UUID)
 		return ErrClientNotConnected
 	}
 
@@ -245,18 +251,9 @@ func (lc *LiveChat) Send(message models.OutboundMessage) error {
 		return fmt.Errorf("failed to marshal message data: %w", err)
 	}
 
-	for _, client := range clients {
-		if client.closed.Load() {
-			continue
-		}
-		select {
-		case client.Channel <- messageJSON:
-			lc.lo.Info("message sent to live chat client", "client_id", client.ID, "message_id", message.UUID)
-		default:
-			lc.lo.Warn("client channel full, dropping message", "client_id", client.ID, "message_id", message.UUID)
-		}
+	if lc.broadcast(message.MessageReceiverID, messageJSON) > 0 {
+		lc.lo.Info("message sent to live chat client", "receiver_id", message.MessageReceiverID, "message_id", message.UUID)
 	}
-
 	return nil
 }
 
@@ -333,9 +330,6 @@ func (lc *LiveChat) RemoveClient(c *Client) {
 
 // BroadcastTypingToClients broadcasts typing status to specific widget clients for a conversation.
 func (lc *LiveChat) BroadcastTypingToClients(conversationUUID string, contactID int, isTyping bool) {
-	lc.clientsMutex.RLock()
-	defer lc.clientsMutex.RUnlock()
-
 	// Create typing status message for widget clients
 	typingMessage := map[string]interface{}{
 		"type": "typing",
@@ -352,27 +346,11 @@ func (lc *LiveChat) BroadcastTypingToClients(conversationUUID string, contactID
 	}
 
 	// Only send to the specific contact's clients
-	contactIDStr := strconv.Itoa(contactID)
-	if clients, exists := lc.clients[contactIDStr]; exists {
-		for _, client := range clients {
-			if client.closed.Load() {
-				continue
-			}
-			select {
-			case client.Channel <- messageJSON:
-				lc.lo.Debug("typing status sent to widget client", "contact_id", contactID, "client_id", client.ID, "conversation_uuid", conversationUUID, "is_typing", isTyping)
-			default:
-				lc.lo.Warn("client channel full, dropping typing message", "contact_id", contactID, "client_id", client.ID)
-			}
-		}
-	}
+	lc.broadcast(contactID, messageJSON)
 }
 
 // BroadcastMessageToClients broadcasts a new message to specific widget clients.
 func (lc *LiveChat) BroadcastMessageToClients(conversationUUID string, contactID int, messageData any) {
-	lc.clientsMutex.RLock()
-	defer lc.clientsMutex.RUnlock()
-
 	msg := map[string]any{
 		"type": "new_message",
 		"data": messageData,
@@ -384,26 +362,11 @@ func (lc *LiveChat) BroadcastMessageToClients(conversationUUID string, contactID
 		return
 	}
 
-	contactIDStr := strconv.Itoa(contactID)
-	if clients, exists := lc.clients[contactIDStr]; exists {
-		for _, client := range clients {
-			if client.closed.Load() {
-				continue
-			}
-			select {
-			case client.Channel <- messageJSON:
-			default:
-				lc.lo.Warn("client channel full, dropping message broadcast", "contact_id", contactID, "client_id", client.ID)
-			}
-		}
-	}
+	lc.broadcast(contactID, messageJSON)
 }
 
 // BroadcastConversationToClients broadcasts conversation updates to specific widget clients.
 func (lc *LiveChat) BroadcastConversationToClients(conversationUUID string, contactID int, conversationData interface{}) {
-	lc.clientsMutex.RLock()
-	defer lc.clientsMutex.RUnlock()
-
 	conversationMessage := map[string]any{
 		"type": "conversation_update",
 		"data": conversationData,
@@ -416,18 +379,49 @@ func (lc *LiveChat) BroadcastConversationToClients(conversationUUID string, cont
 	}
 
 	// Only send to the specific contact's clients
-	contactIDStr := strconv.Itoa(contactID)
-	if clients, exists := lc.clients[contactIDStr]; exists {
-		for _, client := range clients {
-			if client.closed.Load() {
-				continue
-			}
-			select {
-			case client.Channel <- messageJSON:
-				lc.lo.Debug("conversation update sent to widget client", "contact_id", contactID, "client_id", client.ID, "conversation_uuid", conversationUUID)
-			default:
-				lc.lo.Warn("client channel full, dropping conversation update", "contact_id", contactID, "client_id", client.ID)
-			}
+	lc.broadcast(contactID, messageJSON)
+}
+
+// DeliverToContact delivers a broadcast relayed from another app instance to the contact's clients
+// connected to this instance.
+func (lc *LiveChat) DeliverToContact(contactID int, data []byte) {
+	lc.deliver(strconv.Itoa(contactID), data)
+}
+
+// broadcast delivers data to the contact's clients on this instance and relays it to the other instances.
+// It returns the number of local clients the data was queued for.
+func (lc *LiveChat) broadcast(contactID int, data []byte) int {
+	n := lc.deliver(strconv.Itoa(contactID), data)
+	if lc.relay != nil {
+		lc.relay.PublishWidget(lc.id, contactID, data)
+	}
+	return n
+}
+
+// deliver queues data for the contact's clients connected to this instance and returns the number of clients
+// it was queued for. Clients with a full channel are skipped.
+func (lc *LiveChat) deliver(contactID string, data []byte) int {
+	lc.clientsMutex.RLock()
+	defer lc.clientsMutex.RUnlock()
+
+	n := 0
+	for _, client := range lc.clients[contactID] {
+		if client.closed.Load() {
+			continue
+		}
+		select {
+		case client.Channel <- data:
+			n++
+		default:
+			lc.lo.Warn("client channel full, dropping message", "contact_id", contactID, "client_id", client.ID)
 		}
 	}
+	return n
+}
+
+// hasClients returns true if the contact has clients connected to this instance.
+func (lc *LiveChat) hasClients(contactID string) bool {
+	lc.clientsMutex.RLock()
+	defer lc.clientsMutex.RUnlock()
+	return len(lc.clients[contactID]) > 0
 }
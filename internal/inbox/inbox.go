@@ -71,6 +71,11 @@ type Inbox interface {
 type MessageStore interface {
 	MessageExists(string) (bool, error)
 	EnqueueIncoming(models.IncomingMessage) error
+	// RecordReadReceipt records a read receipt (MDN) received for the outgoing message with the given source ID.
+	RecordReadReceipt(sourceID, disposition, from string) error
+	// RecordDeliveryReport records a delivery status notification (DSN), e.g. a bounce, received for the
+	// outgoing message with the given source ID.
+	RecordDeliveryReport(sourceID, action, status, diagnostic, recipient string) error
 }
 
 // UserStore defines methods for fetching user information.
@@ -79,6 +84,17 @@ type UserStore interface {
 	IsEmailBlocked(email string) (bool, error)
 }
 
+// SendLimiter is implemented by inboxes that cap their outgoing messages.
+type SendLimiter interface {
+	// SendLimits returns the maximum number of messages sent per minute and at once, zero is unlimited.
+	SendLimits() (perMinute, concurrency int)
+}
+
+// SenderFilter decides whether an inbox accepts mail from a sender.
+type SenderFilter interface {
+	IsSenderBlocked(inboxID int, email string) (bool, error)
+}
+
 // Opts contains the options for initializing the inbox manager.
 type Opts struct {
 	QueueSize   int
@@ -115,6 +131,20 @@ type queries struct {
 	SoftDelete     *sqlx.Stmt `query:"soft-delete"`
 	InsertInbox    *sqlx.Stmt `query:"insert-inbox"`
 	UpdateConfig   *sqlx.Stmt `query:"update-config"`
+
+	// Address migration queries.
+	UpdateAddress           *sqlx.Stmt `query:"update-address"`
+	GetPendingMigration     *sqlx.Stmt `query:"get-pending-migration"`
+	InsertMigration         *sqlx.Stmt `query:"insert-migration"`
+	SetMigrationVerified    *sqlx.Stmt `query:"set-migration-verified"`
+	SetMigrationCompleted   *sqlx.Stmt `query:"set-migration-completed"`
+	DeletePendingMigrations *sqlx.Stmt `query:"delete-pending-migrations"`
+
+	// Sender rule queries.
+	GetSenderRules    *sqlx.Stmt `query:"get-sender-rules"`
+	GetSenderRuleType *sqlx.Stmt `query:"get-sender-rule-type"`
+	UpsertSenderRule  *sqlx.Stmt `query:"upsert-sender-rule"`
+	DeleteSenderRule  *sqlx.Stmt `query:"delete-sender-rule"`
 }
 
 // New returns a new inbox manager.
@@ -254,7 +284,7 @@ func (m *Manager) Create(inbox imodels.Inbox) (imodels.Inbox, error) {
 	}
 
 	var createdInbox imodels.Inbox
-	if err := m.queries.InsertInbox.Get(&createdInbox, inbox.Channel, encryptedConfig, inbox.Name, inbox.From, inbox.Enabled, inbox.CSATEnabled, inbox.PromptTagsOnReply, inbox.Secret, inbox.LinkedEmailInboxID); err != nil {
+	if err := m.queries.InsertInbox.Get(&createdInbox, inbox.Channel, encryptedConfig, inbox.Name, inbox.From, inbox.Enabled, inbox.CSATEnabled, inbox.PromptTagsOnReply, inbox.Secret, inbox.LinkedEmailInboxID, inbox.CSATConfig, inbox.EmailTemplateID); err != nil {
 		m.lo.Error("error creating inbox", "error", err)
 		return imodels.Inbox{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -345,6 +375,7 @@ func (m *Manager) Update(id int, inbox imodels.Inbox) (imodels.Inbox, error) {
 			SMTP                 []map[string]any  `json:"smtp"`
 			ReplyTo              string            `json:"reply_to"`
 			EnablePlusAddressing bool              `json:"enable_plus_addressing"`
+			PreviousAddresses    []string          `json:"previous_addresses"`
 		}
 		var updateCfg struct {
 			AuthType             string            `json:"auth_type"`
@@ -353,6 +384,12 @@ func (m *Manager) Update(id int, inbox imodels.Inbox) (imodels.Inbox, error) {
 			SMTP                 []map[string]any  `json:"smtp"`
 			ReplyTo              string            `json:"reply_to"`
 			EnablePlusAddressing bool              `json:"enable_plus_addressing"`
+			PreviousAddresses    []string          `json:"previous_addresses"`
+			MDNPolicy            string            `json:"mdn_policy"`
+			RequestReadReceipts  bool              `json:"request_read_receipts"`
+			TrackOpens           bool              `json:"track_opens"`
+			SendRateLimit        int               `json:"send_rate_limit"`
+			SendConcurrency      int               `json:"send_concurrency"`
 		}
 
 		if err := json.Unmarshal(current.Config, &currentCfg); err != nil {
@@ -389,6 +426,9 @@ func (m *Manager) Update(id int, inbox imodels.Inbox) (imodels.Inbox, error) {
 			}
 		}
 
+		// Previous addresses are only changed by address migrations.
+		updateCfg.PreviousAddresses = currentCfg.PreviousAddresses
+
 		// Preserve existing OAuth fields if update has empty
 		if currentCfg.OAuth != nil {
 			if updateCfg.OAuth == nil {
@@ -430,7 +470,7 @@ func (m *Manager) Update(id int, inbox imodels.Inbox) (imodels.Inbox, error) {
 
 	// Update the inbox in the DB.
 	var updatedInbox imodels.Inbox
-	if err := m.queries.Update.Get(&updatedInbox, id, inbox.Channel, encryptedConfig, inbox.Name, inbox.From, inbox.CSATEnabled, inbox.PromptTagsOnReply, inbox.Enabled, inbox.Secret, inbox.LinkedEmailInboxID); err != nil {
+	if err := m.queries.Update.Get(&updatedInbox, id, inbox.Channel, encryptedConfig, inbox.Name, inbox.From, inbox.CSATEnabled, inbox.PromptTagsOnReply, inbox.Enabled, inbox.Secret, inbox.LinkedEmailInboxID, inbox.CSATConfig, inbox.EmailTemplateID); err != nil {
 		m.lo.Error("error updating inbox", "error", err)
 		return imodels.Inbox{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -715,3 +755,60 @@ func (m *Manager) decryptInboxSecret(inbox *imodels.Inbox) {
 		inbox.Secret = null.StringFrom(decrypted)
 	}
 }
+
+// HealthReporter is implemented by inboxes that can report the status of their receivers and senders.
+type HealthReporter interface {
+	Health() Health
+}
+
+// Health is the status of an inbox's receivers and sender.
+type Health struct {
+	Healthy   bool             `json:"healthy"`
+	Receivers []ReceiverHealth `json:"receivers"`
+	Sender    SenderHealth     `json:"sender"`
+}
+
+// ReceiverHealth is the status of a single receiver, e.g. an IMAP mailbox poller.
+type ReceiverHealth struct {
+	Name          string    `json:"name"`
+	Healthy       bool      `json:"healthy"`
+	LastPollAt    null.Time `json:"last_poll_at"`
+	LastSuccessAt null.Time `json:"last_success_at"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// SenderHealth is the status of an inbox's outgoing sender, e.g. its SMTP pools.
+type SenderHealth struct {
+	Healthy             bool      `json:"healthy"`
+	LastSendAt          null.Time `json:"last_send_at"`
+	LastSuccessAt       null.Time `json:"last_success_at"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// InboxHealth is the health of a single running inbox.
+type InboxHealth struct {
+	ID      int    `json:"id"`
+	Channel string `json:"channel"`
+	Health
+}
+
+// Health returns the health of all running inboxes that report it.
+func (m *Manager) Health() []InboxHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]InboxHealth, 0, len(m.inboxes))
+	for _, inb := range m.inboxes {
+		hr, ok := inb.(HealthReporter)
+		if !ok {
+			continue
+		}
+		out = append(out, InboxHealth{
+			ID:      inb.Identifier(),
+			Channel: inb.Channel(),
+			Health:  hr.Health(),
+		})
+	}
+	return out
+}
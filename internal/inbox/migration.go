@@ -0,0 +1,164 @@
+package inbox
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+)
+
+const (
+	// migrationCodeLength is the number of digits of the code emailed to verify a new address.
+	migrationCodeLength = 6
+
+	// migrationCodeTTL is how long a verification code can be used.
+	migrationCodeTTL = 24 * time.Hour
+)
+
+// GetPendingMigration returns the address migration of an inbox that is not completed yet.
+func (m *Manager) GetPendingMigration(inboxID int) (imodels.Migration, error) {
+	var mig imodels.Migration
+	if err := m.queries.GetPendingMigration.Get(&mig, inboxID); err != nil {
+		if err == sql.ErrNoRows {
+			return mig, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching inbox migration", "inbox_id", inboxID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return mig, nil
+}
+
+// CreateMigration starts an address migration of an email inbox, replacing its pending migration if any.
+// The returned migration holds the code to email to the new address for verification.
+func (m *Manager) CreateMigration(inboxID int, from, replyTo string, enablePlusAddressing bool) (imodels.Migration, error) {
+	inbox, err := m.GetDBRecord(inboxID)
+	if err != nil {
+		return imodels.Migration{}, err
+	}
+	if inbox.Channel != ChannelEmail {
+		return imodels.Migration{}, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+
+	code, err := stringutil.RandomNumeric(migrationCodeLength)
+	if err != nil {
+		m.lo.Error("error generating inbox migration code", "error", err)
+		return imodels.Migration{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	var mig imodels.Migration
+	if err := m.queries.InsertMigration.Get(&mig, inboxID, from, replyTo, enablePlusAddressing, inbox.From, code); err != nil {
+		m.lo.Error("error inserting inbox migration", "inbox_id", inboxID, "error", err)
+		return imodels.Migration{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return mig, nil
+}
+
+// VerifyMigration verifies the new address of the pending migration of an inbox with the code emailed to it.
+func (m *Manager) VerifyMigration(inboxID int, code string) (imodels.Migration, error) {
+	mig, err := m.GetPendingMigration(inboxID)
+	if err != nil {
+		return mig, err
+	}
+	if mig.VerifiedAt.Valid {
+		return mig, nil
+	}
+	if time.Since(mig.CreatedAt) > migrationCodeTTL || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(code)), []byte(mig.VerificationCode)) != 1 {
+		return mig, envelope.NewError(envelope.InputError, m.i18n.T("admin.inbox.migration.invalidCode"), nil)
+	}
+	if _, err := m.queries.SetMigrationVerified.Exec(mig.ID); err != nil {
+		m.lo.Error("error verifying inbox migration", "id", mig.ID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return m.GetPendingMigration(inboxID)
+}
+
+// CompleteMigration switches an inbox to the verified address of its pending migration. The addresses it
+// used before are kept in its config so replies to them are still threaded and not added as recipients.
+// The inbox must be reloaded afterwards.
+func (m *Manager) CompleteMigration(inboxID int) (imodels.Migration, error) {
+	mig, err := m.GetPendingMigration(inboxID)
+	if err != nil {
+		return mig, err
+	}
+	if !mig.VerifiedAt.Valid {
+		return mig, envelope.NewError(envelope.InputError, m.i18n.T("admin.inbox.migration.notVerified"), nil)
+	}
+
+	inbox, err := m.GetDBRecord(inboxID)
+	if err != nil {
+		return mig, err
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal(inbox.Config, &cfg); err != nil {
+		m.lo.Error("error unmarshalling inbox config", "inbox_id", inboxID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	var current imodels.Config
+	if err := json.Unmarshal(inbox.Config, &current); err != nil {
+		m.lo.Error("error unmarshalling inbox config", "inbox_id", inboxID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	cfg["reply_to"] = mig.ReplyTo
+	cfg["enable_plus_addressing"] = mig.EnablePlusAddressing
+	cfg["previous_addresses"] = previousAddresses(current.PreviousAddresses, []string{inbox.From, current.ReplyTo}, []string{mig.From, mig.ReplyTo})
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		m.lo.Error("error marshalling inbox config", "inbox_id", inboxID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	encrypted, err := m.encryptInboxConfig(b)
+	if err != nil {
+		m.lo.Error("error encrypting inbox config", "inbox_id", inboxID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := m.queries.UpdateAddress.Exec(inboxID, mig.From, encrypted); err != nil {
+		m.lo.Error("error updating inbox address", "inbox_id", inboxID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := m.queries.SetMigrationCompleted.Exec(mig.ID); err != nil {
+		m.lo.Error("error completing inbox migration", "id", mig.ID, "error", err)
+		return mig, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	m.lo.Info("inbox address migrated", "inbox_id", inboxID, "from", mig.PreviousFrom, "to", mig.From)
+	return mig, nil
+}
+
+// CancelMigration deletes the pending address migration of an inbox.
+func (m *Manager) CancelMigration(inboxID int) error {
+	if _, err := m.queries.DeletePendingMigrations.Exec(inboxID); err != nil {
+		m.lo.Error("error deleting inbox migration", "inbox_id", inboxID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// previousAddresses returns the email addresses of existing and old, lowercased and deduplicated, without
+// the addresses of current. Addresses that can't be parsed are dropped.
+func previousAddresses(existing, old, current []string) []string {
+	exclude := make([]string, 0, len(current))
+	for _, a := range current {
+		if email, err := stringutil.ExtractEmail(a); err == nil && email != "" {
+			exclude = append(exclude, strings.ToLower(email))
+		}
+	}
+	out := []string{}
+	for _, a := range slices.Concat(existing, old) {
+		email, err := stringutil.ExtractEmail(a)
+		if err != nil || email == "" {
+			continue
+		}
+		email = strings.ToLower(email)
+		if slices.Contains(exclude, email) || slices.Contains(out, email) {
+			continue
+		}
+		out = append(out, email)
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package inbox
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPreviousAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		old      []string
+		current  []string
+		want     []string
+	}{
+		{
+			name:    "old from and reply-to are kept",
+			old:     []string{"Support <support@old.example.com>", "help@old.example.com"},
+			current: []string{"Support <support@new.example.com>", ""},
+			want:    []string{"support@old.example.com", "help@old.example.com"},
+		},
+		{
+			name:     "earlier migrations are kept and deduplicated",
+			existing: []string{"support@first.example.com"},
+			old:      []string{"Support <SUPPORT@first.example.com>", ""},
+			current:  []string{"support@new.example.com"},
+			want:     []string{"support@first.example.com"},
+		},
+		{
+			name:     "migrating back to a previous address drops it",
+			existing: []string{"support@first.example.com"},
+			old:      []string{"support@second.example.com"},
+			current:  []string{"Support <support@first.example.com>"},
+			want:     []string{"support@second.example.com"},
+		},
+		{
+			name:    "nothing to keep",
+			current: []string{"support@new.example.com"},
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := previousAddresses(tt.existing, tt.old, tt.current)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("previousAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"crypto/tls"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"net/smtp"
 	"strings"
 	"time"
@@ -27,11 +29,54 @@ type Inbox struct {
 	Channel            string          `db:"channel" json:"channel"`
 	Enabled            bool            `db:"enabled" json:"enabled"`
 	CSATEnabled        bool            `db:"csat_enabled" json:"csat_enabled"`
+	CSATConfig         CSATConfig      `db:"csat_config" json:"csat_config"`
 	PromptTagsOnReply  bool            `db:"prompt_tags_on_reply" json:"prompt_tags_on_reply"`
 	From               string          `db:"from" json:"from"`
 	Config             json.RawMessage `db:"config" json:"config"`
 	Secret             null.String     `db:"secret" json:"secret"`
 	LinkedEmailInboxID null.Int        `db:"linked_email_inbox_id" json:"linked_email_inbox_id"`
+	// EmailTemplateID is the outgoing email template of the inbox, the default template when unset.
+	EmailTemplateID null.Int `db:"email_template_id" json:"email_template_id"`
+}
+
+// CSATConfig holds the CSAT survey settings of an inbox.
+type CSATConfig struct {
+	// Scale is the rating scale of the survey, stars when empty.
+	Scale string `json:"scale"`
+	// Question replaces the default survey question.
+	Question string `json:"question"`
+	// HideComment hides the free-text comment field of the survey.
+	HideComment bool `json:"hide_comment"`
+	// Language is the language of the survey page, the contact's browser language when empty.
+	Language string `json:"language"`
+	// ThrottleDays skips the survey sent on resolve if the contact was sent one in the last
+	// ThrottleDays days. Zero sends a survey on every resolve.
+	ThrottleDays int `json:"throttle_days"`
+	// ExcludeTags skips the survey sent on resolve for conversations with any of these tags.
+	ExcludeTags []string `json:"exclude_tags"`
+}
+
+// Value implements the driver.Valuer interface.
+func (c CSATConfig) Value() (driver.Value, error) {
+	if c.ExcludeTags == nil {
+		c.ExcludeTags = []string{}
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface.
+func (c *CSATConfig) Scan(src any) error {
+	var data []byte
+
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+	return json.Unmarshal(data, c)
 }
 
 // Config holds the email inbox configuration with multiple SMTP servers and IMAP clients.
@@ -43,8 +88,29 @@ type Config struct {
 	From                 string       `json:"from"`
 	ReplyTo              string       `json:"reply_to"`
 	EnablePlusAddressing bool         `json:"enable_plus_addressing"`
+	// PreviousAddresses are the addresses the inbox used before its address was migrated. Replies to them
+	// are still threaded and they are never added as recipients.
+	PreviousAddresses []string `json:"previous_addresses"`
+	// MDNPolicy is how read-receipt requests (MDNs) in incoming mail are handled, MDNPolicyIgnore or MDNPolicySend.
+	MDNPolicy string `json:"mdn_policy"`
+	// RequestReadReceipts asks recipients of outgoing mail for a read receipt.
+	RequestReadReceipts bool `json:"request_read_receipts"`
+	// TrackOpens adds a tracking pixel to outgoing HTML mail to record when recipients open it. Off by
+	// default as it lets the recipient's mail client report back to the instance.
+	TrackOpens bool `json:"track_opens"`
+	// SendRateLimit is the maximum number of messages sent per minute, e.g. to stay under the SMTP
+	// provider's throttling. Zero is unlimited.
+	SendRateLimit int `json:"send_rate_limit"`
+	// SendConcurrency is the maximum number of messages sent at once. Zero is unlimited.
+	SendConcurrency int `json:"send_concurrency"`
 }
 
+// Read-receipt request (MDN) policies of email inboxes.
+const (
+	MDNPolicyIgnore = "ignore"
+	MDNPolicySend   = "send"
+)
+
 // OAuthConfig holds OAuth 2.0 authentication details.
 type OAuthConfig struct {
 	Provider     string    `json:"provider"`      // "microsoft" or "google"
@@ -145,3 +211,36 @@ func (m *Inbox) ClearPasswords() error {
 
 	return nil
 }
+
+// Migration is a change of an email inbox's address, completed once the new address is verified.
+type Migration struct {
+	ID                   int       `db:"id" json:"id"`
+	CreatedAt            time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
+	InboxID              int       `db:"inbox_id" json:"inbox_id"`
+	From                 string    `db:"from" json:"from"`
+	ReplyTo              string    `db:"reply_to" json:"reply_to"`
+	EnablePlusAddressing bool      `db:"enable_plus_addressing" json:"enable_plus_addressing"`
+	PreviousFrom         string    `db:"previous_from" json:"previous_from"`
+	VerificationCode     string    `db:"verification_code" json:"-"`
+	VerifiedAt           null.Time `db:"verified_at" json:"verified_at"`
+	CompletedAt          null.Time `db:"completed_at" json:"completed_at"`
+}
+
+// Sender rule types.
+const (
+	SenderRuleBlock = "block"
+	SenderRuleAllow = "allow"
+)
+
+// SenderRule blocks or allows mail from a sender address in an inbox.
+type SenderRule struct {
+	ID             int       `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	InboxID        int       `db:"inbox_id" json:"inbox_id"`
+	Email          string    `db:"email" json:"email"`
+	Type           string    `db:"type" json:"type"`
+	CreatedByID    null.Int  `db:"created_by_id" json:"created_by_id"`
+	ConversationID null.Int  `db:"conversation_id" json:"conversation_id"`
+}
@@ -1,24 +1,24 @@
 -- name: get-active-inboxes
-SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, prompt_tags_on_reply, config, "from", linked_email_inbox_id FROM inboxes where enabled is TRUE and deleted_at is NULL;
+SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, csat_config, prompt_tags_on_reply, config, "from", linked_email_inbox_id, email_template_id FROM inboxes where enabled is TRUE and deleted_at is NULL;
 
 -- name: get-all-inboxes
-SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, prompt_tags_on_reply, config, "from", linked_email_inbox_id FROM inboxes where deleted_at is NULL;
+SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, csat_config, prompt_tags_on_reply, config, "from", linked_email_inbox_id, email_template_id FROM inboxes where deleted_at is NULL;
 
 -- name: insert-inbox
 INSERT INTO inboxes
-(channel, config, "name", "from", enabled, csat_enabled, prompt_tags_on_reply, secret, linked_email_inbox_id)
-VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+(channel, config, "name", "from", enabled, csat_enabled, prompt_tags_on_reply, secret, linked_email_inbox_id, csat_config, email_template_id)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *
 
 -- name: get-inbox
-SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, prompt_tags_on_reply, config, "from", secret, linked_email_inbox_id FROM inboxes where id = $1 and deleted_at is NULL;
+SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, csat_config, prompt_tags_on_reply, config, "from", secret, linked_email_inbox_id, email_template_id FROM inboxes where id = $1 and deleted_at is NULL;
 
 -- name: get-inbox-by-uuid
-SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, prompt_tags_on_reply, config, "from", secret, linked_email_inbox_id FROM inboxes where uuid = $1 and deleted_at is NULL;
+SELECT id, uuid, created_at, updated_at, "name", deleted_at, channel, enabled, csat_enabled, csat_config, prompt_tags_on_reply, config, "from", secret, linked_email_inbox_id, email_template_id FROM inboxes where uuid = $1 and deleted_at is NULL;
 
 -- name: update
 UPDATE inboxes
-set channel = $2, config = $3, "name" = $4, "from" = $5, csat_enabled = $6, prompt_tags_on_reply = $7, enabled = $8, secret = $9, linked_email_inbox_id = $10, updated_at = now()
+set channel = $2, config = $3, "name" = $4, "from" = $5, csat_enabled = $6, prompt_tags_on_reply = $7, enabled = $8, secret = $9, linked_email_inbox_id = $10, csat_config = $11, email_template_id = $12, updated_at = now()
 where id = $1 and deleted_at is NULL
 RETURNING *;
 
@@ -34,4 +34,53 @@ RETURNING *;
 -- name: update-config
 UPDATE inboxes
 SET config = $2, updated_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL;
\ No newline at end of file
+WHERE id = $1 AND deleted_at IS NULL;
+-- name: update-address
+UPDATE inboxes
+SET "from" = $2, config = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: get-pending-migration
+SELECT id, created_at, updated_at, inbox_id, "from", reply_to, enable_plus_addressing, previous_from, verification_code, verified_at, completed_at
+FROM inbox_migrations
+WHERE inbox_id = $1 AND completed_at IS NULL
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: insert-migration
+-- Replaces the pending migration of the inbox, if any.
+WITH deleted AS (
+    DELETE FROM inbox_migrations WHERE inbox_id = $1 AND completed_at IS NULL
+)
+INSERT INTO inbox_migrations (inbox_id, "from", reply_to, enable_plus_addressing, previous_from, verification_code)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, inbox_id, "from", reply_to, enable_plus_addressing, previous_from, verification_code, verified_at, completed_at;
+
+-- name: set-migration-verified
+UPDATE inbox_migrations SET verified_at = NOW(), updated_at = NOW() WHERE id = $1;
+
+-- name: set-migration-completed
+UPDATE inbox_migrations SET completed_at = NOW(), updated_at = NOW() WHERE id = $1;
+
+-- name: delete-pending-migrations
+DELETE FROM inbox_migrations WHERE inbox_id = $1 AND completed_at IS NULL;
+
+-- name: get-sender-rules
+SELECT id, created_at, updated_at, inbox_id, email, "type", created_by_id, conversation_id
+FROM inbox_sender_rules
+WHERE inbox_id = $1 AND ($2 = '' OR "type" = $2::sender_rule_type)
+ORDER BY email;
+
+-- name: get-sender-rule-type
+SELECT "type" FROM inbox_sender_rules WHERE inbox_id = $1 AND email = $2;
+
+-- name: upsert-sender-rule
+-- A sender is either blocked or allowed in an inbox, the latest rule replaces the previous one.
+INSERT INTO inbox_sender_rules (inbox_id, email, "type", created_by_id, conversation_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (inbox_id, email) DO UPDATE
+SET "type" = EXCLUDED."type", created_by_id = EXCLUDED.created_by_id, conversation_id = EXCLUDED.conversation_id, updated_at = NOW()
+RETURNING id, created_at, updated_at, inbox_id, email, "type", created_by_id, conversation_id;
+
+-- name: delete-sender-rule
+DELETE FROM inbox_sender_rules WHERE inbox_id = $1 AND email = $2;
@@ -0,0 +1,69 @@
+package inbox
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	imodels "github.com/abhinavxd/libredesk/internal/inbox/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// GetSenderRules returns the sender rules of an inbox, optionally only those of the given type.
+func (m *Manager) GetSenderRules(inboxID int, ruleType string) ([]imodels.SenderRule, error) {
+	var rules = make([]imodels.SenderRule, 0)
+	if err := m.queries.GetSenderRules.Select(&rules, inboxID, ruleType); err != nil {
+		m.lo.Error("error fetching sender rules", "inbox_id", inboxID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return rules, nil
+}
+
+// SetSenderRule blocks or allows a sender address in an inbox, replacing its existing rule if any.
+// conversationID is the conversation marked as spam that blocked the sender, zero otherwise.
+func (m *Manager) SetSenderRule(inboxID int, email, ruleType string, createdByID, conversationID int) (imodels.SenderRule, error) {
+	var rule imodels.SenderRule
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || !strings.Contains(email, "@") {
+		return rule, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidEmail"), nil)
+	}
+	if ruleType != imodels.SenderRuleBlock && ruleType != imodels.SenderRuleAllow {
+		return rule, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+	if err := m.queries.UpsertSenderRule.Get(&rule, inboxID, email, ruleType, null.NewInt(createdByID, createdByID > 0), null.NewInt(conversationID, conversationID > 0)); err != nil {
+		m.lo.Error("error upserting sender rule", "inbox_id", inboxID, "email", email, "error", err)
+		return rule, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return rule, nil
+}
+
+// DeleteSenderRule removes the rule of a sender address in an inbox, unblocking or un-allowing it.
+func (m *Manager) DeleteSenderRule(inboxID int, email string) error {
+	if _, err := m.queries.DeleteSenderRule.Exec(inboxID, strings.ToLower(strings.TrimSpace(email))); err != nil {
+		m.lo.Error("error deleting sender rule", "inbox_id", inboxID, "email", email, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// GetSenderRuleType returns the type of the rule of a sender address in an inbox, empty if it has none.
+func (m *Manager) GetSenderRuleType(inboxID int, email string) (string, error) {
+	var ruleType string
+	if err := m.queries.GetSenderRuleType.Get(&ruleType, inboxID, strings.ToLower(email)); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		m.lo.Error("error fetching sender rule", "inbox_id", inboxID, "email", email, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return ruleType, nil
+}
+
+// IsSenderBlocked returns true if mail from the sender address is blocked in the inbox.
+func (m *Manager) IsSenderBlocked(inboxID int, email string) (bool, error) {
+	ruleType, err := m.GetSenderRuleType(inboxID, email)
+	if err != nil {
+		return false, err
+	}
+	return ruleType == imodels.SenderRuleBlock, nil
+}
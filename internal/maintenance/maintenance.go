@@ -0,0 +1,238 @@
+// Package maintenance manages maintenance windows, during which non-critical notifications are held
+// and then summarized to each recipient once the window ends.
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/maintenance/models"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	nmodels "github.com/abhinavxd/libredesk/internal/notification/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// maxSummaryLines is the number of held notifications listed in a summary, the rest are counted.
+	maxSummaryLines = 20
+	// maxSummaryChars keeps the summary within the length of a notification body.
+	maxSummaryChars = 1900
+)
+
+type dispatcher interface {
+	Send(n notifier.Notification)
+}
+
+// Manager manages maintenance windows.
+type Manager struct {
+	q          queries
+	lo         *logf.Logger
+	i18n       *i18n.I18n
+	dispatcher dispatcher
+}
+
+// Opts contains options for initializing the maintenance Manager.
+type Opts struct {
+	DB         *sqlx.DB
+	Lo         *logf.Logger
+	I18n       *i18n.I18n
+	Dispatcher dispatcher
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAll          *sqlx.Stmt `query:"get-all-windows"`
+	Get             *sqlx.Stmt `query:"get-window"`
+	Insert          *sqlx.Stmt `query:"insert-window"`
+	Update          *sqlx.Stmt `query:"update-window"`
+	Delete          *sqlx.Stmt `query:"delete-window"`
+	InWindow        *sqlx.Stmt `query:"in-window"`
+	Hold            *sqlx.Stmt `query:"hold-notification"`
+	GetEndedWindows *sqlx.Stmt `query:"get-ended-windows"`
+	GetHeld         *sqlx.Stmt `query:"get-held-notifications"`
+	SetSummarized   *sqlx.Stmt `query:"set-window-summarized"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:          q,
+		lo:         opts.Lo,
+		i18n:       opts.I18n,
+		dispatcher: opts.Dispatcher,
+	}, nil
+}
+
+// GetAll returns all maintenance windows, most recent first.
+func (m *Manager) GetAll() ([]models.Window, error) {
+	var windows = make([]models.Window, 0)
+	if err := m.q.GetAll.Select(&windows); err != nil {
+		m.lo.Error("error fetching maintenance windows", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return windows, nil
+}
+
+// Get returns a maintenance window by ID.
+func (m *Manager) Get(id int) (models.Window, error) {
+	var window models.Window
+	if err := m.q.Get.Get(&window, id); err != nil {
+		if err == sql.ErrNoRows {
+			return window, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching maintenance window", "id", id, "error", err)
+		return window, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return window, nil
+}
+
+// Create creates a maintenance window.
+func (m *Manager) Create(w models.Window) (models.Window, error) {
+	var result models.Window
+	if err := m.q.Insert.Get(&result, w.Name, w.StartsAt, w.EndsAt); err != nil {
+		m.lo.Error("error inserting maintenance window", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Update updates a maintenance window that was not summarized yet.
+func (m *Manager) Update(id int, w models.Window) (models.Window, error) {
+	var result models.Window
+	if err := m.q.Update.Get(&result, id, w.Name, w.StartsAt, w.EndsAt); err != nil {
+		if err == sql.ErrNoRows {
+			return result, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error updating maintenance window", "id", id, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Delete deletes a maintenance window along with the notifications it held.
+func (m *Manager) Delete(id int) error {
+	if _, err := m.q.Delete.Exec(id); err != nil {
+		m.lo.Error("error deleting maintenance window", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// InWindow returns true if a maintenance window is in progress at the given time.
+func (m *Manager) InWindow(at time.Time) bool {
+	var in bool
+	if err := m.q.InWindow.Get(&in, at); err != nil {
+		m.lo.Error("error checking maintenance windows", "error", err)
+		return false
+	}
+	return in
+}
+
+// Hold records the notification to a recipient for the summary of the maintenance window in
+// progress. Returns false if no window is in progress and the notification is to be sent.
+func (m *Manager) Hold(recipientID int, n notifier.Notification) bool {
+	var id int
+	if err := m.q.Hold.Get(&id, recipientID, n.Type, n.Title, n.ConversationID); err != nil {
+		if err != sql.ErrNoRows {
+			m.lo.Error("error holding notification", "recipient_id", recipientID, "type", n.Type, "error", err)
+		}
+		return false
+	}
+	return true
+}
+
+// Run periodically sends the summaries of ended maintenance windows.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.summarizeEnded(); err != nil {
+				m.lo.Error("error summarizing maintenance windows", "error", err)
+			}
+		}
+	}
+}
+
+// summarizeEnded sends each recipient of notifications held during an ended window a single
+// notification listing them.
+func (m *Manager) summarizeEnded() error {
+	var windows []models.Window
+	if err := m.q.GetEndedWindows.Select(&windows); err != nil {
+		return err
+	}
+	for _, w := range windows {
+		var held []models.HeldNotification
+		if err := m.q.GetHeld.Select(&held, w.ID); err != nil {
+			return err
+		}
+		for userID, titles := range groupByUser(held) {
+			m.dispatcher.Send(notifier.Notification{
+				Type:         nmodels.NotificationTypeMaintenanceSummary,
+				RecipientIDs: []int{userID},
+				Title:        m.i18n.Ts("notification.maintenanceSummary", "count", strconv.Itoa(len(titles)), "name", w.Name),
+				Body:         null.StringFrom(summaryBody(titles)),
+			})
+		}
+		if _, err := m.q.SetSummarized.Exec(w.ID); err != nil {
+			return err
+		}
+		m.lo.Info("summarized maintenance window", "id", w.ID, "held", len(held))
+	}
+	return nil
+}
+
+// groupByUser returns the titles of held notifications by recipient.
+func groupByUser(held []models.HeldNotification) map[int][]string {
+	users := make(map[int][]string)
+	for _, h := range held {
+		users[h.UserID] = append(users[h.UserID], h.Title)
+	}
+	return users
+}
+
+// summaryBody lists the titles of held notifications, one per line, up to maxSummaryLines and
+// maxSummaryChars, followed by the count of the titles left out.
+func summaryBody(titles []string) string {
+	var (
+		b     strings.Builder
+		count int
+	)
+	for _, t := range titles {
+		if count == maxSummaryLines || b.Len()+len(t)+1 > maxSummaryChars {
+			break
+		}
+		if count > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(t)
+		count++
+	}
+	if rest := len(titles) - count; rest > 0 {
+		fmt.Fprintf(&b, "\n+%d more", rest)
+	}
+	return b.String()
+}
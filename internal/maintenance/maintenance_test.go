@@ -0,0 +1,41 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/maintenance/models"
+)
+
+func TestGroupByUser(t *testing.T) {
+	got := groupByUser([]models.HeldNotification{
+		{UserID: 1, Title: "a"},
+		{UserID: 2, Title: "b"},
+		{UserID: 1, Title: "c"},
+	})
+	if len(got) != 2 || strings.Join(got[1], ",") != "a,c" || strings.Join(got[2], ",") != "b" {
+		t.Errorf("groupByUser() = %v", got)
+	}
+}
+
+func TestSummaryBody(t *testing.T) {
+	if got := summaryBody([]string{"a", "b"}); got != "a\nb" {
+		t.Errorf("summaryBody() = %q", got)
+	}
+
+	var titles []string
+	for i := 0; i < maxSummaryLines+3; i++ {
+		titles = append(titles, fmt.Sprint(i))
+	}
+	lines := strings.Split(summaryBody(titles), "\n")
+	if len(lines) != maxSummaryLines+1 || lines[maxSummaryLines] != "+3 more" {
+		t.Errorf("summaryBody() lines = %v", lines)
+	}
+
+	long := strings.Repeat("x", 400)
+	body := summaryBody([]string{long, long, long, long, long, long})
+	if len(body) > maxSummaryChars+20 || !strings.HasSuffix(body, "\n+2 more") {
+		t.Errorf("summaryBody() of long titles = %d chars, %q", len(body), body[len(body)-10:])
+	}
+}
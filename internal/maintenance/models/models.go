@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Window is a period of planned work during which non-critical notifications are held and
+// summarized to each recipient once it ends.
+type Window struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Name      string    `db:"name" json:"name"`
+	StartsAt  time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt    time.Time `db:"ends_at" json:"ends_at"`
+	// Set once the held notifications were summarized.
+	SummarizedAt null.Time `db:"summarized_at" json:"summarized_at"`
+	HeldCount    int       `db:"held_count" json:"held_count"`
+}
+
+// HeldNotification is a notification held during a window.
+type HeldNotification struct {
+	UserID int    `db:"user_id"`
+	Title  string `db:"title"`
+}
@@ -0,0 +1,53 @@
+-- name: get-all-windows
+SELECT w.id, w.created_at, w.updated_at, w."name", w.starts_at, w.ends_at, w.summarized_at,
+    (SELECT COUNT(*) FROM held_notifications h WHERE h.window_id = w.id) AS held_count
+FROM maintenance_windows w
+ORDER BY w.starts_at DESC;
+
+-- name: get-window
+SELECT w.id, w.created_at, w.updated_at, w."name", w.starts_at, w.ends_at, w.summarized_at,
+    (SELECT COUNT(*) FROM held_notifications h WHERE h.window_id = w.id) AS held_count
+FROM maintenance_windows w
+WHERE w.id = $1;
+
+-- name: insert-window
+INSERT INTO maintenance_windows ("name", starts_at, ends_at)
+VALUES ($1, $2, $3)
+RETURNING id, created_at, updated_at, "name", starts_at, ends_at, summarized_at, 0 AS held_count;
+
+-- name: update-window
+UPDATE maintenance_windows
+SET "name" = $2, starts_at = $3, ends_at = $4, updated_at = NOW()
+WHERE id = $1 AND summarized_at IS NULL
+RETURNING id, created_at, updated_at, "name", starts_at, ends_at, summarized_at,
+    (SELECT COUNT(*) FROM held_notifications h WHERE h.window_id = maintenance_windows.id) AS held_count;
+
+-- name: delete-window
+DELETE FROM maintenance_windows WHERE id = $1;
+
+-- name: in-window
+SELECT EXISTS (SELECT 1 FROM maintenance_windows WHERE starts_at <= $1 AND ends_at > $1);
+
+-- name: hold-notification
+INSERT INTO held_notifications (window_id, user_id, notification_type, title, conversation_id)
+SELECT id, $1, $2, $3, $4
+FROM maintenance_windows
+WHERE starts_at <= NOW() AND ends_at > NOW()
+ORDER BY starts_at
+LIMIT 1
+RETURNING id;
+
+-- name: get-ended-windows
+SELECT id, created_at, updated_at, "name", starts_at, ends_at, summarized_at, 0 AS held_count
+FROM maintenance_windows
+WHERE ends_at <= NOW() AND summarized_at IS NULL
+ORDER BY ends_at;
+
+-- name: get-held-notifications
+SELECT user_id, title
+FROM held_notifications
+WHERE window_id = $1
+ORDER BY user_id, id;
+
+-- name: set-window-summarized
+UPDATE maintenance_windows SET summarized_at = NOW() WHERE id = $1;
@@ -257,7 +257,7 @@ func (m *Manager) Delete(name string) error {
 	return nil
 }
 
-// DeleteUnlinkedMedia is a blocking function that periodically deletes media files that are not linked to any conversation message.
+// DeleteUnlinkedMedia is a blocking function that periodically deletes media files that are not linked to any conversation message or asset.
 func (m *Manager) DeleteUnlinkedMedia(ctx context.Context) {
 	m.deleteUnlinkedMessageMedia()
 	for {
@@ -273,7 +273,7 @@ func (m *Manager) DeleteUnlinkedMedia(ctx context.Context) {
 	}
 }
 
-// deleteUnlinkedMessageMedia fetches all media files that are not linked to any message and deletes them from the storage backend and the database.
+// deleteUnlinkedMessageMedia fetches all media files uploaded for a message or asset but never linked to one and deletes them from the storage backend and the database.
 func (m *Manager) deleteUnlinkedMessageMedia() error {
 	var media []models.Media
 	if err := m.queries.GetUnlinkedMessageMedia.Select(&media); err != nil {
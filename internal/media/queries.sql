@@ -46,7 +46,7 @@ WHERE model_type = $1
 -- name: get-unlinked-message-media
 SELECT id, created_at, updated_at, "uuid", store, filename, content_type, content_id, model_id, model_type, disposition, "size", meta
 FROM media
-WHERE model_type = 'messages' 
+WHERE model_type IN ('messages', 'assets')
   AND (model_id IS NULL OR model_id = 0) 
   AND created_at < NOW() - INTERVAL '1 day';
 
@@ -0,0 +1,1336 @@
+package migrations
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/koanf/v2"
+	"github.com/knadh/stuffbin"
+)
+
+func V2_3_0(db *sqlx.DB, fs stuffbin.FileSystem, ko *koanf.Koanf) error {
+	// Workspace announcements.
+	_, err := db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'announcement_severity') THEN
+				CREATE TYPE announcement_severity AS ENUM ('info', 'warning', 'critical');
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS announcements (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			message TEXT NOT NULL,
+			severity announcement_severity DEFAULT 'info' NOT NULL,
+			starts_at TIMESTAMPTZ NULL,
+			ends_at TIMESTAMPTZ NULL,
+			team_ids INT[] DEFAULT '{}' NOT NULL,
+			created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			broadcast_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_announcements_on_message CHECK (length(message) <= 2000)
+		);
+		CREATE INDEX IF NOT EXISTS index_announcements_on_ends_at ON announcements(ends_at);
+
+		CREATE TABLE IF NOT EXISTS announcement_acknowledgements (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			announcement_id INT REFERENCES announcements(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			CONSTRAINT constraint_announcement_acknowledgements_unique UNIQUE (announcement_id, user_id)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'announcements:manage')
+		WHERE name = 'Admin' AND NOT ('announcements:manage' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Lease column for claiming outgoing messages across app instances.
+	_, err = db.Exec(`ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS send_claimed_until TIMESTAMPTZ NULL;`)
+	if err != nil {
+		return err
+	}
+
+	// Token of the current claim, so that an instance only renews or releases a claim it still holds.
+	_, err = db.Exec(`ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS send_claim_token UUID NULL;`)
+	if err != nil {
+		return err
+	}
+
+	// Staging table for incoming messages that overflow the in-memory queue.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS incoming_messages (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			payload JSONB NOT NULL,
+			claimed_until TIMESTAMPTZ NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Push notification device tokens.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'device_platform') THEN
+				CREATE TYPE device_platform AS ENUM ('apns', 'fcm');
+			END IF;
+		END$$;
+
+		CREATE TABLE IF NOT EXISTS user_device_tokens (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			platform device_platform NOT NULL,
+			token TEXT NOT NULL UNIQUE,
+			device_name TEXT NULL,
+			CONSTRAINT constraint_user_device_tokens_on_token CHECK (length(token) <= 4096),
+			CONSTRAINT constraint_user_device_tokens_on_device_name CHECK (length(device_name) <= 255)
+		);
+		CREATE INDEX IF NOT EXISTS index_user_device_tokens_on_user_id ON user_device_tokens(user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// SLA incidents.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sla_incidents (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			title TEXT NOT NULL,
+			description TEXT DEFAULT '' NOT NULL,
+			starts_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			ends_at TIMESTAMPTZ NULL,
+			inbox_ids INT[] DEFAULT '{}'::INT[] NOT NULL,
+			tag_ids INT[] DEFAULT '{}'::INT[] NOT NULL,
+			pause_sla BOOLEAN DEFAULT TRUE NOT NULL,
+			source TEXT DEFAULT 'manual' NOT NULL,
+			external_id TEXT NULL,
+			CONSTRAINT constraint_sla_incidents_on_title CHECK (length(title) <= 255),
+			CONSTRAINT constraint_sla_incidents_on_description CHECK (length(description) <= 5000),
+			CONSTRAINT constraint_sla_incidents_on_source_and_external_id UNIQUE (source, external_id)
+		);
+		CREATE INDEX IF NOT EXISTS index_sla_incidents_on_starts_at_and_ends_at ON sla_incidents(starts_at, ends_at);
+
+		ALTER TABLE applied_slas
+			ADD COLUMN IF NOT EXISTS first_response_incident_id INT REFERENCES sla_incidents(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			ADD COLUMN IF NOT EXISTS resolution_incident_id INT REFERENCES sla_incidents(id) ON DELETE SET NULL ON UPDATE CASCADE NULL;
+		ALTER TABLE sla_events
+			ADD COLUMN IF NOT EXISTS incident_id INT REFERENCES sla_incidents(id) ON DELETE SET NULL ON UPDATE CASCADE NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Contact communication preferences.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS contact_preferences (
+			contact_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			do_not_contact BOOLEAN DEFAULT FALSE NOT NULL,
+			no_surveys BOOLEAN DEFAULT FALSE NOT NULL,
+			no_marketing BOOLEAN DEFAULT FALSE NOT NULL,
+			preferred_channel channels NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Inbox autoresponders.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'autoresponder_condition') THEN
+				CREATE TYPE autoresponder_condition AS ENUM ('always', 'outside_business_hours', 'holiday', 'high_backlog');
+			END IF;
+		END$$;
+
+		CREATE TABLE IF NOT EXISTS inbox_autoresponders (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"name" TEXT NOT NULL,
+			enabled BOOLEAN DEFAULT TRUE NOT NULL,
+			-- Rules are evaluated in ascending priority, the first matching rule is sent.
+			priority INT DEFAULT 0 NOT NULL,
+			"condition" autoresponder_condition NOT NULL,
+			business_hours_id INT REFERENCES business_hours(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			timezone TEXT NULL,
+			backlog_threshold INT NULL,
+			starts_at TIMESTAMPTZ NULL,
+			ends_at TIMESTAMPTZ NULL,
+			content TEXT NOT NULL,
+			CONSTRAINT constraint_inbox_autoresponders_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_inbox_autoresponders_on_content CHECK (length(content) <= 10000)
+		);
+		CREATE INDEX IF NOT EXISTS index_inbox_autoresponders_on_inbox_id ON inbox_autoresponders(inbox_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Conversation topic clustering.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_embeddings (
+			conversation_id BIGINT PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			embedding DOUBLE PRECISION[] NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS conversation_topics (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			period_start TIMESTAMPTZ NOT NULL,
+			period_end TIMESTAMPTZ NOT NULL,
+			label TEXT NOT NULL,
+			conversation_count INT NOT NULL,
+			-- Conversations in the preceding period of the same length that fall into the topic.
+			previous_count INT DEFAULT 0 NOT NULL,
+			conversation_ids BIGINT[] NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_topics_on_period_end ON conversation_topics(period_end);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Digest of open conversations sent to contacts.
+	_, err = db.Exec(`
+		ALTER TABLE contact_preferences ADD COLUMN IF NOT EXISTS no_digest BOOLEAN DEFAULT FALSE NOT NULL;
+		ALTER TABLE contact_preferences ADD COLUMN IF NOT EXISTS digest_sent_at TIMESTAMPTZ NULL;
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO templates ("type", body, is_default, "name", subject, is_builtin)
+		SELECT 'email_notification'::template_type, $1, false, 'Open conversations digest', 'Your open conversations', true
+		WHERE NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Open conversations digest');
+	`, `<p>Hi {{ .Contact.FirstName }},</p>
+<p>Here is a summary of your open conversations with us.</p>
+<table style="width: 100%; border-collapse: collapse; font-size: 14px;">
+  <tr>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Reference</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Subject</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Status</th>
+  </tr>
+  {{ range .Conversations }}
+  <tr>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ if .Link }}<a href="{{ .Link }}">#{{ .ReferenceNumber }}</a>{{ else }}#{{ .ReferenceNumber }}{{ end }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Subject }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Status }}</td>
+  </tr>
+  {{ end }}
+</table>
+<p style="font-size: 12px; color: #9ca3af;">Reply to any of your conversations to add more information.</p>
+`)
+	if err != nil {
+		return err
+	}
+
+	// TOTP two-factor authentication.
+	_, err = db.Exec(`
+		ALTER TABLE roles ADD COLUMN IF NOT EXISTS require_two_factor BOOLEAN DEFAULT FALSE NOT NULL;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT NULL;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ NULL;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NULL;
+		CREATE TABLE IF NOT EXISTS user_recovery_codes (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			code_hash TEXT NOT NULL,
+			used_at TIMESTAMPTZ NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_user_recovery_codes_on_user_id ON user_recovery_codes(user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Embeddable web forms.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS web_forms (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"uuid" UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+			"name" TEXT NOT NULL,
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			enabled BOOLEAN DEFAULT TRUE NOT NULL,
+			fields JSONB DEFAULT '[]'::JSONB NOT NULL,
+			allow_attachments BOOLEAN DEFAULT FALSE NOT NULL,
+			success_message TEXT DEFAULT '' NOT NULL,
+			trusted_domains TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			CONSTRAINT constraint_web_forms_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_web_forms_on_success_message CHECK (length(success_message) <= 1000)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// CAPTCHA settings for public endpoints.
+	_, err = db.Exec(`
+		INSERT INTO settings (key, value)
+		VALUES
+			('captcha.provider', '""'::jsonb),
+			('captcha.site_key', '""'::jsonb),
+			('captcha.secret_key', '""'::jsonb),
+			('captcha.endpoints', '[]'::jsonb)
+		ON CONFLICT (key) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Private notes get their own permission, granted to every role that can already send messages.
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'messages:write_private')
+		WHERE 'messages:write' = ANY(permissions)
+		AND NOT ('messages:write_private' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Collaborator role that can only read conversations and post private notes.
+	_, err = db.Exec(`
+		INSERT INTO roles ("name", description, permissions)
+		SELECT 'Collaborator',
+			'Role for users who can read conversations and post private notes, but cannot reply to contacts or change conversations.',
+			'{conversations:read_all,conversations:read,messages:read,messages:write_private}'
+		WHERE NOT EXISTS (SELECT 1 FROM roles WHERE "name" = 'Collaborator');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Signed CSAT survey links.
+	_, err = db.Exec(`
+		ALTER TABLE csat_responses ADD COLUMN IF NOT EXISTS token_nonce TEXT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Conversation handoff notes.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_handoff_notes (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			from_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			to_user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			summary TEXT NOT NULL,
+			current_state TEXT DEFAULT '' NOT NULL,
+			next_step TEXT DEFAULT '' NOT NULL,
+			-- Only the latest note is active, and only while its recipient remains the assignee.
+			active BOOLEAN DEFAULT TRUE NOT NULL,
+			CONSTRAINT constraint_conversation_handoff_notes_on_summary CHECK (length(summary) <= 2000),
+			CONSTRAINT constraint_conversation_handoff_notes_on_current_state CHECK (length(current_state) <= 2000),
+			CONSTRAINT constraint_conversation_handoff_notes_on_next_step CHECK (length(next_step) <= 2000)
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_handoff_notes_on_conversation_id ON conversation_handoff_notes(conversation_id) WHERE active;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Include the handoff note in the built-in assignment notification, unless already customized to do so.
+	_, err = db.Exec(`
+		UPDATE templates
+		SET body = replace(body, '<p>
+    <a href="{{ RootURL }}/inboxes/assigned/conversation/', '{{ if .Handoff.Summary }}
+<div>
+    <p>Handoff note from {{ .Handoff.FromName }}:</p>
+    Summary: {{ .Handoff.Summary }} <br>
+    {{ if .Handoff.CurrentState }}Current state: {{ .Handoff.CurrentState }} <br>{{ end }}
+    {{ if .Handoff.NextStep }}Next step: {{ .Handoff.NextStep }}{{ end }}
+</div>
+{{ end }}
+
+<p>
+    <a href="{{ RootURL }}/inboxes/assigned/conversation/')
+		WHERE type = 'email_notification' AND name = 'Conversation assigned' AND body NOT LIKE '%.Handoff%';
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Internal threads of private notes.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_threads (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			updated_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"name" TEXT NOT NULL,
+			created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			resolved_at TIMESTAMPTZ NULL,
+			resolved_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			CONSTRAINT constraint_conversation_threads_on_name CHECK (length("name") <= 140)
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_threads_on_conversation_id ON conversation_threads(conversation_id);
+
+		CREATE TABLE IF NOT EXISTS conversation_thread_participants (
+			thread_id BIGINT REFERENCES conversation_threads(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			PRIMARY KEY (thread_id, user_id)
+		);
+
+		ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS thread_id BIGINT REFERENCES conversation_threads(id) ON DELETE SET NULL ON UPDATE CASCADE NULL;
+		CREATE INDEX IF NOT EXISTS index_conversation_messages_on_thread_id ON conversation_messages (thread_id) WHERE thread_id IS NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// References to messages and monthly partitions of the messages table, used once it is partitioned with --partition-messages.
+	_, err = db.Exec(`
+		-- References to conversation_messages(id) once it is partitioned and can't be referenced by foreign keys,
+		-- enforced by the triggers below. Actions are pg_constraint codes: c (cascade), n (set null), a (no action), r (restrict).
+		CREATE TABLE IF NOT EXISTS conversation_message_refs (
+		    table_name TEXT NOT NULL,
+		    column_name TEXT NOT NULL,
+		    on_delete TEXT DEFAULT 'c' NOT NULL,
+		    on_update TEXT DEFAULT 'c' NOT NULL,
+		    PRIMARY KEY (table_name, column_name),
+		    CONSTRAINT constraint_conversation_message_refs_on_actions CHECK (on_delete IN ('c', 'n', 'a', 'r') AND on_update IN ('c', 'n', 'a', 'r'))
+		);
+
+		-- Adds a reference to conversation_messages(id), a foreign key or, once the table is partitioned, a registered
+		-- reference with a trigger that checks inserted and updated message ids. Migrations use it instead of REFERENCES.
+		CREATE OR REPLACE FUNCTION reference_conversation_messages(tbl TEXT, col TEXT, delete_action TEXT DEFAULT 'c', update_action TEXT DEFAULT 'c')
+		RETURNS VOID AS $$
+		BEGIN
+		    IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'conversation_messages'::regclass) THEN
+		        INSERT INTO conversation_message_refs (table_name, column_name, on_delete, on_update)
+		        VALUES (tbl, col, delete_action, update_action)
+		        ON CONFLICT (table_name, column_name) DO UPDATE SET on_delete = EXCLUDED.on_delete, on_update = EXCLUDED.on_update;
+		        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'trigger_check_' || col || '_message_ref', tbl);
+		        EXECUTE format('CREATE TRIGGER %I BEFORE INSERT OR UPDATE OF %I ON %I FOR EACH ROW EXECUTE FUNCTION check_conversation_message_ref(%L)',
+		            'trigger_check_' || col || '_message_ref', col, tbl, col);
+		    ELSIF NOT EXISTS (
+		        SELECT 1 FROM pg_constraint c
+		        JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		        WHERE c.contype = 'f' AND c.conrelid = tbl::regclass AND c.confrelid = 'conversation_messages'::regclass AND a.attname = col
+		    ) THEN
+		        EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (%I) REFERENCES conversation_messages(id) ON DELETE %s ON UPDATE %s', tbl, col,
+		            CASE delete_action WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END,
+		            CASE update_action WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END);
+		    END IF;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		-- Rejects a row referencing a message that doesn't exist, the argument is the referencing column.
+		CREATE OR REPLACE FUNCTION check_conversation_message_ref()
+		RETURNS TRIGGER AS $$
+		DECLARE
+		    ref_id BIGINT;
+		BEGIN
+		    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) INTO ref_id USING NEW;
+		    IF ref_id IS NOT NULL THEN
+		        PERFORM 1 FROM conversation_messages WHERE id = ref_id FOR KEY SHARE;
+		        IF NOT FOUND THEN
+		            RAISE foreign_key_violation USING MESSAGE = format('%s.%s references message %s which does not exist', TG_TABLE_NAME, TG_ARGV[0], ref_id);
+		        END IF;
+		    END IF;
+		    RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		-- Does the ON DELETE and ON UPDATE actions of the registered references when a message is deleted or its id changes.
+		CREATE OR REPLACE FUNCTION apply_conversation_message_refs()
+		RETURNS TRIGGER AS $$
+		DECLARE
+		    ref RECORD;
+		    action TEXT;
+		    referenced BOOL;
+		BEGIN
+		    -- Messages moved out of the default partition by create_message_partitions() keep their references.
+		    IF current_setting('libredesk.moving_messages', true) = 'on' THEN
+		        RETURN NULL;
+		    END IF;
+		    IF TG_OP = 'UPDATE' AND NEW.id = OLD.id THEN
+		        RETURN NULL;
+		    END IF;
+		    -- An update of created_at moving the message to another partition is a delete and an insert.
+		    IF TG_OP = 'DELETE' AND EXISTS (SELECT 1 FROM conversation_messages WHERE id = OLD.id) THEN
+		        RETURN NULL;
+		    END IF;
+		    FOR ref IN SELECT * FROM conversation_message_refs WHERE to_regclass(table_name) IS NOT NULL LOOP
+		        action := CASE TG_OP WHEN 'DELETE' THEN ref.on_delete ELSE ref.on_update END;
+		        IF action = 'c' AND TG_OP = 'DELETE' THEN
+		            EXECUTE format('DELETE FROM %I WHERE %I = $1', ref.table_name, ref.column_name) USING OLD.id;
+		        ELSIF action = 'c' THEN
+		            EXECUTE format('UPDATE %I SET %I = $2 WHERE %I = $1', ref.table_name, ref.column_name, ref.column_name) USING OLD.id, NEW.id;
+		        ELSIF action = 'n' THEN
+		            EXECUTE format('UPDATE %I SET %I = NULL WHERE %I = $1', ref.table_name, ref.column_name, ref.column_name) USING OLD.id;
+		        ELSE
+		            EXECUTE format('SELECT EXISTS (SELECT 1 FROM %I WHERE %I = $1)', ref.table_name, ref.column_name) INTO referenced USING OLD.id;
+		            IF referenced THEN
+		                RAISE foreign_key_violation USING MESSAGE = format('message %s is still referenced from %s.%s', OLD.id, ref.table_name, ref.column_name);
+		            END IF;
+		        END IF;
+		    END LOOP;
+		    RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		-- Creates the monthly partitions of conversation_messages from the current month to months_ahead months ahead
+		-- once the table has been partitioned with libredesk --partition-messages. Does nothing on an unpartitioned table.
+		CREATE OR REPLACE FUNCTION create_message_partitions(months_ahead INT)
+		RETURNS INT AS $$
+		DECLARE
+		    created INT := 0;
+		    legacy_end TIMESTAMPTZ;
+		    month_start TIMESTAMPTZ;
+		    month_end TIMESTAMPTZ;
+		    partition_name TEXT;
+		BEGIN
+		    IF NOT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'conversation_messages'::regclass) THEN
+		        RETURN 0;
+		    END IF;
+		    -- Serializes app instances creating the same partitions.
+		    PERFORM pg_advisory_xact_lock(hashtext('create_message_partitions'));
+
+		    -- Messages before the upper bound of the legacy partition stay in it.
+		    SELECT substring(pg_get_expr(relpartbound, oid) FROM 'TO \(''([^'']+)''\)')::TIMESTAMPTZ INTO legacy_end
+		    FROM pg_class WHERE oid = to_regclass('conversation_messages_legacy');
+
+		    FOR i IN 0..months_ahead LOOP
+		        month_start := (date_trunc('month', NOW() AT TIME ZONE 'UTC') + make_interval(months => i)) AT TIME ZONE 'UTC';
+		        month_end := (date_trunc('month', NOW() AT TIME ZONE 'UTC') + make_interval(months => i + 1)) AT TIME ZONE 'UTC';
+		        partition_name := 'conversation_messages_p' || to_char(month_start AT TIME ZONE 'UTC', 'YYYYMM');
+		        IF to_regclass(partition_name) IS NULL AND (legacy_end IS NULL OR month_start >= legacy_end) THEN
+		            -- Messages that went to the default partition while the month had no partition are moved to it.
+		            EXECUTE format('CREATE TABLE %I (LIKE conversation_messages INCLUDING DEFAULTS INCLUDING CONSTRAINTS)', partition_name);
+		            IF to_regclass('conversation_messages_default') IS NOT NULL THEN
+		                PERFORM set_config('libredesk.moving_messages', 'on', true);
+		                EXECUTE format('WITH moved AS (DELETE FROM conversation_messages_default WHERE created_at >= $1 AND created_at < $2 RETURNING *) INSERT INTO %I SELECT * FROM moved', partition_name)
+		                    USING month_start, month_end;
+		                PERFORM set_config('libredesk.moving_messages', 'off', true);
+		            END IF;
+		            EXECUTE format('ALTER TABLE conversation_messages ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', partition_name, month_start, month_end);
+		            created := created + 1;
+		        END IF;
+		    END LOOP;
+		    RETURN created;
+		END;
+		$$ LANGUAGE plpgsql;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Pinned messages.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_pinned_messages (
+			message_id BIGINT PRIMARY KEY,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			pinned_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_pinned_messages_on_conversation_id ON conversation_pinned_messages(conversation_id);
+		SELECT reference_conversation_messages('conversation_pinned_messages', 'message_id');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Conversation summary maintained by agents.
+	_, err = db.Exec(`
+		ALTER TABLE conversations ADD COLUMN IF NOT EXISTS summary TEXT DEFAULT '' NOT NULL;
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'constraint_conversations_on_summary') THEN
+				ALTER TABLE conversations ADD CONSTRAINT constraint_conversations_on_summary CHECK (length(summary) <= 2000);
+			END IF;
+		END $$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Show the summary in the built-in assignment and mention notifications, unless already customized to do so.
+	_, err = db.Exec(`
+		UPDATE templates
+		SET body = replace(body, 'Subject: {{ .Conversation.Subject }}
+</div>', 'Subject: {{ .Conversation.Subject }}{{ if .Conversation.Summary }} <br>
+    Summary: {{ .Conversation.Summary }}{{ end }}
+</div>')
+		WHERE type = 'email_notification' AND name = 'Conversation assigned' AND body NOT LIKE '%.Conversation.Summary%';
+
+		UPDATE templates
+		SET body = replace(body, '<blockquote', '{{ if .Conversation.Summary }}
+<p>Summary: {{ .Conversation.Summary }}</p>
+{{ end }}
+
+<blockquote')
+		WHERE type = 'email_notification' AND name = 'Mentioned in conversation' AND body NOT LIKE '%.Conversation.Summary%';
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Draft versions and conflict detection.
+	_, err = db.Exec(`
+		ALTER TABLE conversation_drafts ADD COLUMN IF NOT EXISTS version INT DEFAULT 1 NOT NULL;
+		ALTER TABLE conversation_drafts ADD COLUMN IF NOT EXISTS last_message_id BIGINT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Trigram index for related conversation suggestions.
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS index_trgm_conversations_on_subject ON conversations USING GIN ("subject" gin_trgm_ops);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Shared team drafts.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_team_drafts (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			updated_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL UNIQUE,
+			content TEXT NOT NULL,
+			meta JSONB DEFAULT '{}'::jsonb NOT NULL,
+			version INT DEFAULT 1 NOT NULL,
+			created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			updated_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			-- Only the lock holder can edit, the lock expires unless renewed by saving.
+			locked_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			locked_until TIMESTAMPTZ NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Snooze presets.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'snooze_preset_kind') THEN
+				CREATE TYPE snooze_preset_kind AS ENUM ('duration', 'next_business_day', 'weekday');
+			END IF;
+		END$$;
+
+		CREATE TABLE IF NOT EXISTS snooze_presets (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"name" TEXT NOT NULL,
+			kind snooze_preset_kind NOT NULL,
+			duration TEXT NOT NULL DEFAULT '',
+			weekday TEXT NOT NULL DEFAULT '',
+			time_of_day TEXT NOT NULL DEFAULT '',
+			sort_order INT NOT NULL DEFAULT 0,
+			CONSTRAINT constraint_snooze_presets_on_name CHECK (length("name") <= 50)
+		);
+
+		INSERT INTO snooze_presets (name, kind, duration, weekday, time_of_day, sort_order)
+		SELECT * FROM (VALUES
+			('Later today', 'duration'::snooze_preset_kind, '3h', '', '', 1),
+			('Tomorrow morning', 'next_business_day'::snooze_preset_kind, '', '', '09:00', 2),
+			('Next Monday 9am', 'weekday'::snooze_preset_kind, '', 'Monday', '09:00', 3)
+		) AS v
+		WHERE NOT EXISTS (SELECT 1 FROM snooze_presets);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Reactions on private notes.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_message_reactions (
+			message_id BIGINT NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			emoji TEXT NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			PRIMARY KEY (message_id, user_id, emoji),
+			CONSTRAINT constraint_conversation_message_reactions_on_emoji CHECK (length(emoji) <= 16)
+		);
+		SELECT reference_conversation_messages('conversation_message_reactions', 'message_id');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Agent working hours and schedule overrides.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS agent_schedules (
+			user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			timezone TEXT NOT NULL,
+			-- Working hours per weekday, same shape as business_hours.hours.
+			hours JSONB DEFAULT '{}'::jsonb NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS agent_schedule_overrides (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			-- Whether the agent is working during the override, e.g. false for leave, true for an extra shift.
+			available BOOLEAN NOT NULL,
+			note TEXT DEFAULT '' NOT NULL,
+			CONSTRAINT constraint_agent_schedule_overrides_on_range CHECK (ends_at > starts_at),
+			CONSTRAINT constraint_agent_schedule_overrides_on_note CHECK (length(note) <= 200)
+		);
+		CREATE INDEX IF NOT EXISTS index_agent_schedule_overrides_on_user_id_ends_at ON agent_schedule_overrides(user_id, ends_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Required flag of custom attribute definitions.
+	_, err = db.Exec(`
+		ALTER TABLE custom_attribute_definitions ADD COLUMN IF NOT EXISTS required BOOLEAN DEFAULT FALSE NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Status change time of conversations and executions of time trigger automation rules.
+	_, err = db.Exec(`
+		ALTER TABLE conversations ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ DEFAULT NOW();
+
+		CREATE TABLE IF NOT EXISTS automation_rule_executions (
+			rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			executed_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			PRIMARY KEY (rule_id, conversation_id)
+		);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_executions_on_conversation_id ON automation_rule_executions(conversation_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Permission to post notes from integrations, and lookup of conversations by order number.
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'messages:write_integration_notes')
+		WHERE name = 'Admin' AND NOT ('messages:write_integration_notes' = ANY(permissions));
+
+		CREATE INDEX IF NOT EXISTS index_conversations_on_order_number ON conversations ((custom_attributes->>'order_number'));
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Agent keyboard bindings for macros and status changes.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS agent_keyboard_bindings (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			keys TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL,
+			CONSTRAINT constraint_agent_keyboard_bindings_on_keys CHECK (length(keys) <= 50),
+			CONSTRAINT constraint_agent_keyboard_bindings_on_target CHECK (length(target) <= 50),
+			CONSTRAINT constraint_agent_keyboard_bindings_on_user_id_keys_unique UNIQUE (user_id, keys)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Log of automation rule executions.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS automation_rule_logs (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			failed BOOLEAN DEFAULT FALSE NOT NULL,
+			actions JSONB DEFAULT '[]'::jsonb NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_logs_on_rule_id_created_at ON automation_rule_logs(rule_id, created_at);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_logs_on_created_at ON automation_rule_logs(created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Delayed automation actions.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS automation_pending_actions (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			action JSONB NOT NULL,
+			run_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_automation_pending_actions_on_run_at ON automation_pending_actions(run_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Automation rule stop processing flag and active window.
+	_, err = db.Exec(`
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS stop_processing BOOL DEFAULT FALSE NOT NULL;
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS active_from TIMESTAMPTZ NULL;
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS active_until TIMESTAMPTZ NULL;
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'constraint_automation_rules_on_active_window') THEN
+				ALTER TABLE automation_rules ADD CONSTRAINT constraint_automation_rules_on_active_window CHECK (active_until > active_from);
+			END IF;
+		END $$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// CSAT survey customization, per-inbox survey settings and the rating scale of each response.
+	// Rating 0 meant no rating, it's NULL now as 0 is a valid NPS score.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'csat_scale') THEN
+				CREATE TYPE csat_scale AS ENUM ('stars', 'thumbs', 'nps');
+			END IF;
+		END$$;
+		ALTER TABLE inboxes ADD COLUMN IF NOT EXISTS csat_config JSONB DEFAULT '{}'::jsonb NOT NULL;
+		ALTER TABLE csat_responses ADD COLUMN IF NOT EXISTS scale csat_scale DEFAULT 'stars' NOT NULL;
+		ALTER TABLE csat_responses ALTER COLUMN rating DROP NOT NULL, ALTER COLUMN rating DROP DEFAULT;
+		UPDATE csat_responses SET rating = NULL WHERE rating = 0 AND scale <> 'nps';
+		ALTER TABLE csat_responses DROP CONSTRAINT IF EXISTS constraint_csat_responses_on_rating;
+		ALTER TABLE csat_responses ADD CONSTRAINT constraint_csat_responses_on_rating CHECK (rating >= 0 AND rating <= 10);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Render the ratings of the survey's scale in the built-in CSAT request template, if it wasn't edited.
+	_, err = db.Exec(`
+		UPDATE templates SET body = $1, updated_at = NOW()
+		WHERE name = 'CSAT request' AND is_builtin = true AND md5(body) = '1965bdde7399ecc9419a44e70840736a';
+	`, csatRequestTemplateBody)
+	if err != nil {
+		return err
+	}
+
+	// Periodic NPS surveys sent to contacts.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS nps_surveys (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			uuid UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+			contact_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			rating INT NULL,
+			"comment" TEXT NULL,
+			responded_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_nps_surveys_on_rating CHECK (rating >= 0 AND rating <= 10),
+			CONSTRAINT constraint_nps_surveys_on_comment CHECK (length("comment") <= 1000)
+		);
+		CREATE INDEX IF NOT EXISTS index_nps_surveys_on_contact_id_created_at ON nps_surveys(contact_id, created_at);
+		CREATE INDEX IF NOT EXISTS index_nps_surveys_on_created_at ON nps_surveys(created_at);
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO templates ("type", body, is_default, "name", subject, is_builtin)
+		SELECT 'email_notification'::template_type, $1, false, 'NPS survey', 'How likely are you to recommend us?', true
+		WHERE NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'NPS survey');
+	`, npsSurveyTemplateBody)
+	if err != nil {
+		return err
+	}
+
+	// Abusive incoming message detection.
+	_, err = db.Exec(`
+		INSERT INTO settings (key, value)
+		VALUES
+			('abuse.enabled', 'false'::jsonb),
+			('abuse.words', '[]'::jsonb),
+			('abuse.classifier', 'false'::jsonb),
+			('abuse.tag', '""'::jsonb),
+			('abuse.notify_team_id', '0'::jsonb)
+		ON CONFLICT (key) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`ALTER TYPE user_notification_type ADD VALUE IF NOT EXISTS 'abuse'`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS abuse_flags (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			message_id BIGINT NOT NULL UNIQUE,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			contact_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			assigned_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			"source" TEXT NOT NULL,
+			matches TEXT[] DEFAULT '{}'::TEXT[] NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_abuse_flags_on_created_at ON abuse_flags(created_at);
+		CREATE INDEX IF NOT EXISTS index_abuse_flags_on_conversation_id ON abuse_flags(conversation_id);
+		SELECT reference_conversation_messages('abuse_flags', 'message_id');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Risk flags on contacts, changed by admins and recorded in the activity log.
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS risk_flags TEXT[] DEFAULT '{}'::TEXT[] NOT NULL;
+
+		UPDATE roles
+		SET permissions = array_append(permissions, 'contacts:manage_risk_flags')
+		WHERE name = 'Admin' AND NOT ('contacts:manage_risk_flags' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`ALTER TYPE activity_log_type ADD VALUE IF NOT EXISTS 'contact_risk_flags_changed'`)
+	if err != nil {
+		return err
+	}
+
+	// Built-in template of the conversation transcript emailed to contacts.
+	_, err = db.Exec(`
+		INSERT INTO templates ("type", body, is_default, "name", subject, is_builtin)
+		SELECT 'email_notification'::template_type, $1, false, 'Conversation transcript', '', true
+		WHERE NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Conversation transcript');
+	`, transcriptTemplateBody)
+	if err != nil {
+		return err
+	}
+
+	// Time-to-assignment SLA target, tracked as SLA events while conversations wait unassigned.
+	_, err = db.Exec(`ALTER TYPE sla_metric ADD VALUE IF NOT EXISTS 'assignment';`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE sla_policies ADD COLUMN IF NOT EXISTS assignment_time TEXT NULL;`)
+	if err != nil {
+		return err
+	}
+
+	// A/B variants of automated messages and the log of their sends.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS message_variants (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			template_id INT REFERENCES templates(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			autoresponder_id INT REFERENCES inbox_autoresponders(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			"name" TEXT NOT NULL,
+			content TEXT NOT NULL,
+			weight INT DEFAULT 1 NOT NULL,
+			CONSTRAINT constraint_message_variants_on_owner CHECK (num_nonnulls(template_id, autoresponder_id) = 1),
+			CONSTRAINT constraint_message_variants_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_message_variants_on_content CHECK (length(content) <= 10000),
+			CONSTRAINT constraint_message_variants_on_weight CHECK (weight >= 0 AND weight <= 100)
+		);
+		CREATE INDEX IF NOT EXISTS index_message_variants_on_template_id ON message_variants(template_id);
+		CREATE INDEX IF NOT EXISTS index_message_variants_on_autoresponder_id ON message_variants(autoresponder_id);
+
+		CREATE TABLE IF NOT EXISTS message_variant_sends (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			variant_id INT REFERENCES message_variants(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			csat_response_id INT REFERENCES csat_responses(id) ON DELETE SET NULL ON UPDATE CASCADE NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_message_variant_sends_on_variant_id_and_created_at ON message_variant_sends(variant_id, created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Maintenance windows holding non-critical notifications, summarized once they end.
+	_, err = db.Exec(`ALTER TYPE user_notification_type ADD VALUE IF NOT EXISTS 'maintenance_summary'`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS maintenance_windows (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"name" TEXT NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			summarized_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_maintenance_windows_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_maintenance_windows_on_ends_at CHECK (ends_at > starts_at)
+		);
+		CREATE INDEX IF NOT EXISTS index_maintenance_windows_on_starts_at_and_ends_at ON maintenance_windows(starts_at, ends_at);
+
+		CREATE TABLE IF NOT EXISTS held_notifications (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			window_id INT REFERENCES maintenance_windows(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			notification_type user_notification_type NOT NULL,
+			title TEXT NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_held_notifications_on_window_id ON held_notifications(window_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Payload fields included in and excluded from the deliveries of a webhook.
+	_, err = db.Exec(`
+		ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS include_fields TEXT[] DEFAULT '{}'::TEXT[] NOT NULL;
+		ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS exclude_fields TEXT[] DEFAULT '{}'::TEXT[] NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Address migrations of email inboxes.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS inbox_migrations (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"from" TEXT NOT NULL,
+			reply_to TEXT DEFAULT '' NOT NULL,
+			enable_plus_addressing BOOL DEFAULT FALSE NOT NULL,
+			previous_from TEXT DEFAULT '' NOT NULL,
+			verification_code TEXT NOT NULL,
+			verified_at TIMESTAMPTZ NULL,
+			completed_at TIMESTAMPTZ NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_inbox_migrations_on_inbox_id ON inbox_migrations(inbox_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Digest of activity on conversations agents watch.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS agent_notification_preferences (
+			user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			watch_digest_hours INT DEFAULT 0 NOT NULL,
+			watch_digest_sent_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_agent_notification_preferences_on_watch_digest_hours CHECK (watch_digest_hours >= 0)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO templates ("type", body, is_default, "name", subject, is_builtin)
+		SELECT 'email_notification'::template_type, $1, false, 'Watched conversations digest', 'Activity on conversations you watch', true
+		WHERE NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Watched conversations digest');
+	`, `<p>Hi {{ .Agent.FirstName }},</p>
+<p>Here is the activity on conversations you are following but are not assigned to.</p>
+<table style="width: 100%; border-collapse: collapse; font-size: 14px;">
+  <tr>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Reference</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Subject</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Status</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">New messages</th>
+  </tr>
+  {{ range .Conversations }}
+  <tr>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;"><a href="{{ RootURL }}/inboxes/all/conversation/{{ .UUID }}">#{{ .ReferenceNumber }}</a></td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Subject }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Status }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .NewMessages }}</td>
+  </tr>
+  {{ end }}
+</table>
+<p style="font-size: 12px; color: #9ca3af;">You can change how often you receive this digest in your notification preferences.</p>
+`)
+	if err != nil {
+		return err
+	}
+
+	// Per agent sort and page size of conversation lists.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS view_preferences (
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+			view_key TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			order_by TEXT NOT NULL DEFAULT '',
+			sort_order TEXT NOT NULL DEFAULT '',
+			page_size INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (user_id, view_key),
+			CONSTRAINT constraint_view_preferences_on_view_key CHECK (length(view_key) <= 140),
+			CONSTRAINT constraint_view_preferences_on_page_size CHECK (page_size >= 0)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Agent signatures.
+	_, err = db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS signature TEXT NULL;`)
+	if err != nil {
+		return err
+	}
+
+	// Outgoing email template per inbox.
+	_, err = db.Exec(`ALTER TABLE inboxes ADD COLUMN IF NOT EXISTS email_template_id INT NULL;`)
+	if err != nil {
+		return err
+	}
+
+	// Delivery state of outgoing messages.
+	_, err = db.Exec(`
+		ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS delivery_state TEXT NULL;
+		CREATE INDEX IF NOT EXISTS index_conversation_messages_on_delivery_accepted ON conversation_messages (id) WHERE delivery_state = 'accepted';
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Shared asset library.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS assets (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"name" TEXT NOT NULL,
+			description TEXT DEFAULT '' NOT NULL,
+			media_id INT REFERENCES media(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			team_ids INT[] DEFAULT '{}' NOT NULL,
+			created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			usage_count INT DEFAULT 0 NOT NULL,
+			last_used_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_assets_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_assets_on_description CHECK (length(description) <= 1000)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'assets:manage')
+		WHERE name = 'Admin' AND NOT ('assets:manage' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Spam status and per inbox sender block and allow lists.
+	_, err = db.Exec(`
+		INSERT INTO conversation_statuses (name, category) VALUES ('Spam', 'resolved')
+		ON CONFLICT (name) DO NOTHING;
+
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'sender_rule_type') THEN
+				CREATE TYPE sender_rule_type AS ENUM ('block', 'allow');
+			END IF;
+		END$$;
+
+		CREATE TABLE IF NOT EXISTS inbox_sender_rules (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			email TEXT NOT NULL,
+			"type" sender_rule_type NOT NULL,
+			created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE,
+			CONSTRAINT constraint_inbox_sender_rules_on_inbox_id_and_email_unique UNIQUE (inbox_id, email)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Conversation trash.
+	_, err = db.Exec(`
+		ALTER TABLE conversations ADD COLUMN IF NOT EXISTS trashed_at TIMESTAMPTZ NULL;
+		CREATE INDEX IF NOT EXISTS index_conversations_on_trashed_at ON conversations (trashed_at) WHERE trashed_at IS NOT NULL;
+
+		UPDATE roles
+		SET permissions = array_append(permissions, 'conversations:trash')
+		WHERE name = 'Admin' AND NOT ('conversations:trash' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Archive storage tier of old conversations.
+	_, err = db.Exec(`
+		ALTER TABLE conversations ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ NULL;
+
+		CREATE TABLE IF NOT EXISTS conversation_message_archives (
+			message_id BIGINT PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			body BYTEA NOT NULL
+		);
+		SELECT reference_conversation_messages('conversation_message_archives', 'message_id');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Send priority of outgoing messages, agent replies are sent before CSAT and automated emails.
+	_, err = db.Exec(`
+		ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS send_priority SMALLINT DEFAULT 0 NOT NULL;
+		CREATE INDEX IF NOT EXISTS index_conversation_messages_on_outbox ON conversation_messages (send_priority, id) WHERE status = 'pending' AND type = 'outgoing';
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Hourly rollups for the waiting time heatmap.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS waiting_time_rollups (
+			hour_start TIMESTAMPTZ PRIMARY KEY,
+			incoming INT DEFAULT 0 NOT NULL,
+			responses INT DEFAULT 0 NOT NULL,
+			waits_started INT DEFAULT 0 NOT NULL,
+			waits_answered INT DEFAULT 0 NOT NULL,
+			-- Total seconds customers waited for the answered waits.
+			wait_sec_total BIGINT DEFAULT 0 NOT NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Retry failed staged incoming messages instead of dropping them.
+	_, err = db.Exec(`
+		ALTER TABLE incoming_messages ADD COLUMN IF NOT EXISTS attempts INT DEFAULT 0 NOT NULL;
+		ALTER TABLE incoming_messages ADD COLUMN IF NOT EXISTS last_error TEXT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Permission to update conversation custom attributes, granted to roles that can update conversations.
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'conversations:update_custom_attributes')
+		WHERE (name = 'Admin' OR 'conversations:update_status' = ANY(permissions))
+		AND NOT ('conversations:update_custom_attributes' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Retained email headers of incoming messages.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_message_headers (
+			message_id BIGINT PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			headers JSONB DEFAULT '{}'::JSONB NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_message_headers_on_created_at ON conversation_message_headers(created_at);
+		SELECT reference_conversation_messages('conversation_message_headers', 'message_id');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// SLA webhook events.
+	for _, event := range []string{"sla.applied", "sla.met", "sla.breached"} {
+		_, err = db.Exec(`ALTER TYPE webhook_event ADD VALUE IF NOT EXISTS '` + event + `'`)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csatRequestTemplateBody is the built-in CSAT request template, rendering the ratings of the survey's scale.
+const csatRequestTemplateBody = `
+<p style="margin: 0 0 4px; font-size: 15px; color: #374151; text-align: center; line-height: 1.5;">
+  Your conversation <strong style="color: #111827;">#{{ .Conversation.ReferenceNumber }}</strong> has been resolved.
+</p>
+<p style="margin: 0 0 28px; font-size: 13px; color: #9ca3af; text-align: center;">
+  We would love to hear how it went.
+</p>
+<p style="margin: 0 0 20px; font-size: 14px; font-weight: 600; color: #374151; text-align: center;">
+  {{ if .CSATQuestion }}{{ .CSATQuestion }}{{ else if eq .CSATScale "nps" }}How likely are you to recommend us to a friend or colleague?{{ else }}How would you rate your experience?{{ end }}
+</p>
+<!-- Variables CSATUUID and CSATScale (stars, thumbs or nps) are also available -->
+<div style="text-align: center; margin: 0 auto; max-width: 440px; font-size: 0;">
+  {{ range .CSATRatings }}
+  <div style="display: inline-block; width: {{ if .Emoji }}72px{{ else }}40px{{ end }}; text-align: center; vertical-align: top; padding: 4px 0;">
+    <a href="{{ .Link }}" style="text-decoration: none; display: block;">
+      {{ if .Emoji }}
+      <span style="font-size: 34px; display: block; line-height: 1.4;">{{ .Emoji }}</span>
+      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">{{ .Label }}</span>
+      {{ else }}
+      <span style="font-size: 15px; display: block; font-weight: 600; color: #374151; line-height: 32px; margin: 0 2px; border: 1px solid #e5e7eb; border-radius: 6px;">{{ .Value }}</span>
+      {{ end }}
+    </a>
+  </div>
+  {{ end }}
+</div>
+`
+
+// npsSurveyTemplateBody is the body of the built-in NPS survey email template.
+const npsSurveyTemplateBody = `<p>Hi {{ .Contact.FirstName }},</p>
+<p>How likely are you to recommend us to a friend or colleague?</p>
+<table style="border-collapse: collapse;">
+  <tr>
+    {{ range .Ratings }}
+    <td style="padding: 2px;"><a href="{{ .Link }}" style="display: inline-block; width: 32px; padding: 8px 0; text-align: center; border: 1px solid #e5e7eb; border-radius: 4px; text-decoration: none; color: #111827;">{{ .Value }}</a></td>
+    {{ end }}
+  </tr>
+</table>
+<p style="font-size: 12px; color: #6b7280;">0 is not likely at all, 10 is extremely likely.</p>
+<p style="font-size: 12px; color: #9ca3af;">To stop receiving these surveys, <a href="{{ .UnsubscribeLink }}">unsubscribe</a>.</p>
+`
+
+// transcriptTemplateBody is the body of the built-in conversation transcript email template.
+const transcriptTemplateBody = `<p>Hi {{ .Contact.FirstName }},</p>
+<p>Here is the transcript of your conversation <strong>#{{ .Conversation.ReferenceNumber }}</strong>{{ if .Conversation.Subject }}, {{ html .Conversation.Subject }}{{ end }}.</p>
+{{ range .Messages }}
+<div style="border-top: 1px solid #e5e7eb; padding: 12px 0;">
+  <p style="margin: 0 0 6px; font-size: 12px; color: #6b7280;"><strong style="color: #111827;">{{ html .AuthorName }}</strong> &middot; {{ .CreatedAt }}</p>
+  <div style="white-space: pre-wrap; font-size: 14px; color: #374151;">{{ html .Text }}</div>
+  {{ if .Attachments }}<p style="margin: 6px 0 0; font-size: 12px; color: #6b7280;">Attachments: {{ range $i, $name := .Attachments }}{{ if $i }}, {{ end }}{{ html $name }}{{ end }}</p>{{ end }}
+</div>
+{{ end }}
+`
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/notification/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// GetDeviceTokens returns the push device tokens registered by a user.
+func (m *UserNotificationManager) GetDeviceTokens(userID int) ([]models.DeviceToken, error) {
+	var tokens = make([]models.DeviceToken, 0)
+	if err := m.q.GetDeviceTokens.Select(&tokens, userID); err != nil {
+		m.lo.Error("error fetching device tokens", "user_id", userID, "error", err)
+		return tokens, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return tokens, nil
+}
+
+// RegisterDeviceToken registers a push device token for a user.
+func (m *UserNotificationManager) RegisterDeviceToken(userID int, platform, token string, deviceName null.String) (models.DeviceToken, error) {
+	var deviceToken models.DeviceToken
+	if err := m.q.UpsertDeviceToken.Get(&deviceToken, userID, platform, token, deviceName); err != nil {
+		m.lo.Error("error registering device token", "user_id", userID, "error", err)
+		return deviceToken, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return deviceToken, nil
+}
+
+// DeleteDeviceToken deletes a user's push device token.
+func (m *UserNotificationManager) DeleteDeviceToken(id, userID int) error {
+	if _, err := m.q.DeleteDeviceToken.Exec(id, userID); err != nil {
+		m.lo.Error("error deleting device token", "id", id, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// DeleteDeviceTokenByToken deletes a device token that the push provider reported as no longer valid.
+func (m *UserNotificationManager) DeleteDeviceTokenByToken(token string) {
+	if _, err := m.q.DeleteDeviceTokenByToken.Exec(token); err != nil {
+		m.lo.Error("error deleting invalid device token", "error", err)
+	}
+}
@@ -2,6 +2,7 @@ package notifier
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/abhinavxd/libredesk/internal/notification/models"
 	wsmodels "github.com/abhinavxd/libredesk/internal/ws/models"
@@ -42,12 +43,31 @@ type EmailNotification struct {
 	Content    string
 }
 
-// Dispatcher coordinates sending notifications through multiple channels: WS, DB, email.
+// pushTypes are the notification types delivered to registered devices as push notifications.
+var pushTypes = map[models.NotificationType]bool{
+	models.NotificationTypeMention:    true,
+	models.NotificationTypeAssignment: true,
+}
+
+// holdableTypes are the non-critical notification types held during maintenance windows.
+var holdableTypes = map[models.NotificationType]bool{
+	models.NotificationTypeSLAWarning: true,
+}
+
+// Holder holds notifications during maintenance windows.
+type Holder interface {
+	// Hold returns true if the notification to the recipient was held instead of being sent.
+	Hold(recipientID int, n Notification) bool
+}
+
+// Dispatcher coordinates sending notifications through multiple channels: WS, DB, email, push.
 type Dispatcher struct {
 	inApp        *UserNotificationManager
 	outbound     *Service
 	wsHub        WSHub
 	emailEnabled bool
+	pushEnabled  bool
+	holder       Holder
 	lo           *logf.Logger
 }
 
@@ -57,6 +77,7 @@ type DispatcherOpts struct {
 	Outbound     *Service
 	WSHub        WSHub
 	EmailEnabled bool
+	PushEnabled  bool
 	Lo           *logf.Logger
 }
 
@@ -67,15 +88,24 @@ func NewDispatcher(opts DispatcherOpts) *Dispatcher {
 		outbound:     opts.Outbound,
 		wsHub:        opts.WSHub,
 		emailEnabled: opts.EmailEnabled,
+		pushEnabled:  opts.PushEnabled,
 		lo:           opts.Lo,
 	}
 }
 
+// SetHolder sets the holder of non-critical notifications during maintenance windows.
+func (d *Dispatcher) SetHolder(h Holder) {
+	d.holder = h
+}
+
 // Send sends a notification through all configured channels.
 // For each recipient: creates in-app notification (DB), broadcasts via Websocket,
 // and sends email if Email field is provided.
 func (d *Dispatcher) Send(n Notification) {
 	for i, recipientID := range n.RecipientIDs {
+		if d.held(recipientID, n) {
+			continue
+		}
 		d.sendToRecipient(recipientID, n)
 
 		if d.outbound != nil && n.Email != nil && d.emailEnabled {
@@ -96,6 +126,9 @@ func (d *Dispatcher) Send(n Notification) {
 // This is useful when email content is personalized per recipient.
 func (d *Dispatcher) SendWithEmails(n Notification, emails []EmailNotification) {
 	for i, recipientID := range n.RecipientIDs {
+		if d.held(recipientID, n) {
+			continue
+		}
 		d.sendToRecipient(recipientID, n)
 
 		if d.outbound != nil && i < len(emails) && len(emails[i].Recipients) > 0 && d.emailEnabled {
@@ -105,6 +138,12 @@ func (d *Dispatcher) SendWithEmails(n Notification, emails []EmailNotification)
 	}
 }
 
+// held returns true if the notification to the recipient is of a non-critical type and was held
+// for a maintenance window in progress.
+func (d *Dispatcher) held(recipientID int, n Notification) bool {
+	return d.holder != nil && holdableTypes[n.Type] && d.holder.Hold(recipientID, n)
+}
+
 // sendToRecipient creates in-app notification and broadcasts via Websocket.
 // Returns the created notification or nil if creation failed.
 func (d *Dispatcher) sendToRecipient(recipientID int, n Notification) *models.UserNotification {
@@ -129,9 +168,34 @@ func (d *Dispatcher) sendToRecipient(recipientID int, n Notification) *models.Us
 	notification.ActorFirstName = null.StringFrom(n.ActorFirstName)
 	notification.ActorLastName = null.StringFrom(n.ActorLastName)
 	d.broadcastNotification([]int{recipientID}, notification)
+	d.sendPush(recipientID, notification)
 	return &notification
 }
 
+// sendPush queues a push notification to the recipient's registered devices.
+func (d *Dispatcher) sendPush(recipientID int, notification models.UserNotification) {
+	if d.outbound == nil || !d.pushEnabled || !pushTypes[notification.NotificationType] {
+		return
+	}
+	tokens, err := d.inApp.GetDeviceTokens(recipientID)
+	if err != nil || len(tokens) == 0 {
+		return
+	}
+	if err := d.outbound.Send(Message{
+		Subject:      notification.Title,
+		Content:      notification.Body.String,
+		Provider:     ProviderPush,
+		DeviceTokens: tokens,
+		Data: map[string]string{
+			"notification_id":   strconv.Itoa(notification.ID),
+			"notification_type": string(notification.NotificationType),
+			"conversation_uuid": notification.ConversationUUID.String,
+		},
+	}); err != nil {
+		d.lo.Error("error sending push notification", "recipient_id", recipientID, "error", err)
+	}
+}
+
 // sendEmail sends an email notification through the outbound service.
 func (d *Dispatcher) sendEmail(recipientID int, email, subject, content string, nType models.NotificationType) {
 	if err := d.outbound.Send(Message{
@@ -15,6 +15,9 @@ const (
 	NotificationTypeAssignment NotificationType = "assignment"
 	NotificationTypeSLAWarning NotificationType = "sla_warning"
 	NotificationTypeSLABreach  NotificationType = "sla_breach"
+	NotificationTypeAbuse      NotificationType = "abuse"
+
+	NotificationTypeMaintenanceSummary NotificationType = "maintenance_summary"
 )
 
 // UserNotification represents an in-app notification for a user.
@@ -45,3 +48,20 @@ type NotificationStats struct {
 	UnreadCount int `db:"unread_count" json:"unread_count"`
 	TotalCount  int `db:"total_count" json:"total_count"`
 }
+
+// Push platforms a device token can be registered for.
+const (
+	PlatformAPNs = "apns"
+	PlatformFCM  = "fcm"
+)
+
+// DeviceToken is a push notification token registered by a user's device.
+type DeviceToken struct {
+	ID         int         `db:"id" json:"id"`
+	CreatedAt  time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at" json:"updated_at"`
+	UserID     int         `db:"user_id" json:"user_id"`
+	Platform   string      `db:"platform" json:"platform"`
+	Token      string      `db:"token" json:"token"`
+	DeviceName null.String `db:"device_name" json:"device_name"`
+}
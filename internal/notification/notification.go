@@ -6,11 +6,13 @@ import (
 	"sync"
 
 	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/notification/models"
 	"github.com/zerodha/logf"
 )
 
 const (
 	ProviderEmail = "email"
+	ProviderPush  = "push"
 )
 
 // Message represents a message to be sent as a notification.
@@ -31,6 +33,10 @@ type Message struct {
 	AltContent string
 	// Additional email headers
 	Headers map[string][]string
+	// Device tokens of the recipients, for push providers
+	DeviceTokens []models.DeviceToken
+	// Custom key-value data delivered with push messages
+	Data map[string]string
 }
 
 // Notifier defines the interface for sending notifications through various providers.
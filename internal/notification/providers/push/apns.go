@@ -0,0 +1,146 @@
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+
+	// APNs rejects provider tokens older than an hour and throttles refreshes more frequent than 20 minutes.
+	apnsTokenTTL = 50 * time.Minute
+)
+
+// APNsConfig holds the token based authentication config for APNs.
+type APNsConfig struct {
+	// Path to the .p8 signing key downloaded from the Apple developer account.
+	KeyFile string `json:"key_file"`
+	KeyID   string `json:"key_id"`
+	TeamID  string `json:"team_id"`
+	// App bundle ID.
+	Topic      string `json:"topic"`
+	Production bool   `json:"production"`
+}
+
+type apns struct {
+	cfg    APNsConfig
+	key    *ecdsa.PrivateKey
+	url    string
+	client *http.Client
+
+	mu       sync.Mutex
+	token    string
+	tokenExp time.Time
+}
+
+func newAPNs(cfg APNsConfig, client *http.Client) (*apns, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, fmt.Errorf("key_id, team_id and topic are required")
+	}
+	b, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing key file: %w", err)
+	}
+	url := apnsSandboxURL
+	if cfg.Production {
+		url = apnsProductionURL
+	}
+	return &apns{cfg: cfg, key: key, url: url, client: client}, nil
+}
+
+// send sends a push message to an APNs device token.
+func (a *apns) send(deviceToken string, msg notifier.Message) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": msg.Subject,
+				"body":  msg.Content,
+			},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.providerToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.url+"/3/device/"+deviceToken, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", a.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	json.Unmarshal(b, &apnsErr)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		apnsErr.Reason == "BadDeviceToken",
+		apnsErr.Reason == "Unregistered",
+		apnsErr.Reason == "DeviceTokenNotForTopic":
+		return errInvalidToken
+	}
+	return fmt.Errorf("APNs returned %d: %s", resp.StatusCode, apnsErr.Reason)
+}
+
+// providerToken returns a cached JWT provider token, refreshing it when it is about to expire.
+func (a *apns) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Now().Before(a.tokenExp) {
+		return a.token, nil
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.cfg.TeamID,
+		"iat": time.Now().Unix(),
+	})
+	t.Header["kid"] = a.cfg.KeyID
+	signed, err := t.SignedString(a.key)
+	if err != nil {
+		return "", fmt.Errorf("signing APNs provider token: %w", err)
+	}
+	a.token = signed
+	a.tokenExp = time.Now().Add(apnsTokenTTL)
+	return a.token, nil
+}
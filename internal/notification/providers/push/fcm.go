@@ -0,0 +1,99 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	fcmScope   = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmSendURL = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// FCMConfig holds the service account config for the FCM HTTP v1 API.
+type FCMConfig struct {
+	// Path to the Firebase service account JSON key.
+	CredentialsFile string `json:"credentials_file"`
+	// Optional, defaults to the project in the service account key.
+	ProjectID string `json:"project_id"`
+}
+
+type fcm struct {
+	url    string
+	client *http.Client
+}
+
+func newFCM(cfg FCMConfig) (*fcm, error) {
+	b, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading credentials file: %w", err)
+	}
+	creds, err := google.CredentialsFromJSON(context.Background(), b, fcmScope)
+	if err != nil {
+		return nil, fmt.Errorf("parsing credentials file: %w", err)
+	}
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = creds.ProjectID
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("project_id is required")
+	}
+
+	client := oauth2.NewClient(context.Background(), creds.TokenSource)
+	client.Timeout = httpTimeout
+	return &fcm{
+		url:    fmt.Sprintf(fcmSendURL, projectID),
+		client: client,
+	}, nil
+}
+
+// send sends a push message to an FCM registration token.
+func (f *fcm) send(deviceToken string, msg notifier.Message) error {
+	message := map[string]any{
+		"token": deviceToken,
+		"notification": map[string]string{
+			"title": msg.Subject,
+			"body":  msg.Content,
+		},
+	}
+	if len(msg.Data) > 0 {
+		message["data"] = msg.Data
+	}
+	body, err := json.Marshal(map[string]any{"message": message})
+	if err != nil {
+		return err
+	}
+
+	resp, err := f.client.Post(f.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var fcmErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	json.Unmarshal(b, &fcmErr)
+	if resp.StatusCode == http.StatusNotFound || fcmErr.Error.Status == "UNREGISTERED" {
+		return errInvalidToken
+	}
+	return fmt.Errorf("FCM returned %d: %s", resp.StatusCode, fcmErr.Error.Message)
+}
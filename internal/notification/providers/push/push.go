@@ -0,0 +1,104 @@
+// Package push implements a notification provider that delivers push messages to
+// mobile and PWA devices through APNs and FCM.
+package push
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/notification/models"
+	"github.com/zerodha/logf"
+)
+
+const httpTimeout = 15 * time.Second
+
+// errInvalidToken is returned by a platform when a device token is no longer valid.
+var errInvalidToken = errors.New("invalid device token")
+
+// platform sends a push message to a single device token.
+type platform interface {
+	send(token string, msg notifier.Message) error
+}
+
+// Push implements the notifier.Notifier interface for push notifications.
+type Push struct {
+	lo             *logf.Logger
+	platforms      map[string]platform
+	onInvalidToken func(token string)
+}
+
+// Opts contains options for creating a new Push provider.
+type Opts struct {
+	Lo *logf.Logger
+	// APNs is optional, nil disables delivery to iOS devices.
+	APNs *APNsConfig
+	// FCM is optional, nil disables delivery to Android and web devices.
+	FCM *FCMConfig
+	// OnInvalidToken is called with tokens rejected by the platform so that they can be pruned.
+	OnInvalidToken func(token string)
+}
+
+// New initializes a new Push provider.
+func New(opts Opts) (*Push, error) {
+	var (
+		client    = &http.Client{Timeout: httpTimeout}
+		platforms = make(map[string]platform)
+	)
+	if opts.APNs != nil {
+		a, err := newAPNs(*opts.APNs, client)
+		if err != nil {
+			return nil, fmt.Errorf("initializing APNs: %w", err)
+		}
+		platforms[models.PlatformAPNs] = a
+	}
+	if opts.FCM != nil {
+		f, err := newFCM(*opts.FCM)
+		if err != nil {
+			return nil, fmt.Errorf("initializing FCM: %w", err)
+		}
+		platforms[models.PlatformFCM] = f
+	}
+	if len(platforms) == 0 {
+		return nil, errors.New("no push platforms configured")
+	}
+	return &Push{
+		lo:             opts.Lo,
+		platforms:      platforms,
+		onInvalidToken: opts.OnInvalidToken,
+	}, nil
+}
+
+// Send sends a push message to all device tokens in the message.
+func (p *Push) Send(msg notifier.Message) error {
+	var failed int
+	for _, t := range msg.DeviceTokens {
+		pl, ok := p.platforms[t.Platform]
+		if !ok {
+			p.lo.Debug("skipping push to unconfigured platform", "platform", t.Platform, "user_id", t.UserID)
+			continue
+		}
+		if err := pl.send(t.Token, msg); err != nil {
+			if errors.Is(err, errInvalidToken) {
+				p.lo.Info("removing invalid device token", "platform", t.Platform, "user_id", t.UserID)
+				if p.onInvalidToken != nil {
+					p.onInvalidToken(t.Token)
+				}
+				continue
+			}
+			p.lo.Error("error sending push notification", "platform", t.Platform, "user_id", t.UserID, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("sending push notification to %d of %d devices failed", failed, len(msg.DeviceTokens))
+	}
+	return nil
+}
+
+// Name returns the name of the provider.
+func (p *Push) Name() string {
+	return notifier.ProviderPush
+}
@@ -38,3 +38,26 @@ DELETE FROM user_notifications WHERE user_id = $1;
 
 -- name: delete-old-notifications
 DELETE FROM user_notifications WHERE created_at < NOW() - INTERVAL '30 days';
+
+-- name: get-device-tokens
+SELECT id, created_at, updated_at, user_id, platform, token, device_name
+FROM user_device_tokens
+WHERE user_id = $1
+ORDER BY updated_at DESC;
+
+-- name: upsert-device-token
+-- A token belongs to a single device, re-registering it moves it to the current user.
+INSERT INTO user_device_tokens (user_id, platform, token, device_name)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (token) DO UPDATE SET
+    user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    device_name = EXCLUDED.device_name,
+    updated_at = NOW()
+RETURNING id, created_at, updated_at, user_id, platform, token, device_name;
+
+-- name: delete-device-token
+DELETE FROM user_device_tokens WHERE id = $1 AND user_id = $2;
+
+-- name: delete-device-token-by-token
+DELETE FROM user_device_tokens WHERE token = $1;
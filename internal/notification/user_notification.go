@@ -41,6 +41,11 @@ type queries struct {
 	DeleteNotification     *sqlx.Stmt `query:"delete-notification"`
 	DeleteAllNotifications *sqlx.Stmt `query:"delete-all-notifications"`
 	DeleteOldNotifications *sqlx.Stmt `query:"delete-old-notifications"`
+
+	GetDeviceTokens          *sqlx.Stmt `query:"get-device-tokens"`
+	UpsertDeviceToken        *sqlx.Stmt `query:"upsert-device-token"`
+	DeleteDeviceToken        *sqlx.Stmt `query:"delete-device-token"`
+	DeleteDeviceTokenByToken *sqlx.Stmt `query:"delete-device-token-by-token"`
 }
 
 // NewUserNotificationManager creates and returns a new instance of UserNotificationManager.
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Contact is a contact due for an NPS survey.
+type Contact struct {
+	ID        int    `db:"id" json:"id"`
+	FirstName string `db:"first_name" json:"first_name"`
+	LastName  string `db:"last_name" json:"last_name"`
+	Email     string `db:"email" json:"email"`
+}
+
+// Survey is an NPS survey sent to a contact.
+type Survey struct {
+	ID          int         `db:"id" json:"id"`
+	CreatedAt   time.Time   `db:"created_at" json:"created_at"`
+	UUID        string      `db:"uuid" json:"uuid"`
+	ContactID   int         `db:"contact_id" json:"contact_id"`
+	Rating      null.Int    `db:"rating" json:"rating"`
+	Comment     null.String `db:"comment" json:"comment"`
+	RespondedAt null.Time   `db:"responded_at" json:"responded_at"`
+}
+
+// Rating is a score of the survey email, linking to the survey page with the score picked.
+type Rating struct {
+	Value int
+	Link  string
+}
@@ -0,0 +1,260 @@
+// Package nps periodically sends Net Promoter Score surveys to contacts, independent of conversations.
+package nps
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/nps/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs             embed.FS
+	ErrInvalidToken = errors.New("invalid NPS survey token")
+)
+
+const (
+	// TmplSurvey is the name of the stored email template of the survey.
+	TmplSurvey = "NPS survey"
+
+	// MaxRating is the highest score of a survey, scores range from 0 to MaxRating.
+	MaxRating = 10
+
+	surveyURL = "%s/nps/%s"
+)
+
+type templateStore interface {
+	RenderStoredEmailTemplate(name string, data any) (string, string, error)
+}
+
+type notifierStore interface {
+	Send(message notifier.Message) error
+}
+
+type settingsStore interface {
+	GetAppRootURL() (string, error)
+}
+
+// Manager sends NPS surveys to contacts and records their responses.
+type Manager struct {
+	q          queries
+	lo         *logf.Logger
+	i18n       *i18n.I18n
+	template   templateStore
+	notifier   notifierStore
+	settings   settingsStore
+	signingKey []byte
+	period     time.Duration
+	batchSize  int
+}
+
+// Opts contains options for initializing the NPS Manager.
+type Opts struct {
+	DB       *sqlx.DB
+	Lo       *logf.Logger
+	I18n     *i18n.I18n
+	Template templateStore
+	Notifier notifierStore
+	Settings settingsStore
+	// SigningKey signs the tokens in survey URLs.
+	SigningKey string
+	// Period is the minimum time between two surveys to the same contact. Only contacts with a
+	// conversation within the period are surveyed.
+	Period time.Duration
+	// BatchSize caps the surveys sent per run, the rest are sent in later runs.
+	BatchSize int
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetDueContacts *sqlx.Stmt `query:"get-due-contacts"`
+	InsertSurvey   *sqlx.Stmt `query:"insert-survey"`
+	DeleteSurvey   *sqlx.Stmt `query:"delete-survey"`
+	GetSurvey      *sqlx.Stmt `query:"get-survey"`
+	UpdateResponse *sqlx.Stmt `query:"update-response"`
+	Unsubscribe    *sqlx.Stmt `query:"unsubscribe"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:          q,
+		lo:         opts.Lo,
+		i18n:       opts.I18n,
+		template:   opts.Template,
+		notifier:   opts.Notifier,
+		settings:   opts.Settings,
+		signingKey: []byte(opts.SigningKey),
+		period:     opts.Period,
+		batchSize:  opts.BatchSize,
+	}, nil
+}
+
+// Run periodically sends surveys to contacts that are due one.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.sendDue(ctx); err != nil {
+				m.lo.Error("error sending NPS surveys", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends a survey to a batch of contacts that had a conversation within the period and were not
+// surveyed within it. Contacts that opted out of surveys or all automated messages are skipped.
+func (m *Manager) sendDue(ctx context.Context) error {
+	var contacts []models.Contact
+	if err := m.q.GetDueContacts.SelectContext(ctx, &contacts, int(m.period.Seconds()), m.batchSize); err != nil {
+		return err
+	}
+	if len(contacts) == 0 {
+		return nil
+	}
+
+	rootURL, err := m.settings.GetAppRootURL()
+	if err != nil {
+		return err
+	}
+	var sent int
+	for _, contact := range contacts {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := m.send(ctx, contact, rootURL); err != nil {
+			m.lo.Error("error sending NPS survey", "contact_id", contact.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+	m.lo.Info("sent NPS surveys", "count", sent)
+	return nil
+}
+
+// send records a survey for the contact and emails it. The survey is removed if it can't be sent,
+// so that the contact is picked up again in a later run.
+func (m *Manager) send(ctx context.Context, contact models.Contact, rootURL string) error {
+	var uuid string
+	if err := m.q.InsertSurvey.GetContext(ctx, &uuid, contact.ID); err != nil {
+		return err
+	}
+
+	link := m.MakePublicURL(rootURL, m.NewToken(uuid))
+	ratings := make([]models.Rating, 0, MaxRating+1)
+	for i := 0; i <= MaxRating; i++ {
+		ratings = append(ratings, models.Rating{Value: i, Link: link + "?rating=" + strconv.Itoa(i)})
+	}
+
+	content, subject, err := m.template.RenderStoredEmailTemplate(TmplSurvey, map[string]any{
+		"Contact":         contact,
+		"SurveyLink":      link,
+		"Ratings":         ratings,
+		"UnsubscribeLink": link + "/unsubscribe",
+	})
+	if err == nil {
+		err = m.notifier.Send(notifier.Message{
+			RecipientEmails: []string{contact.Email},
+			Subject:         subject,
+			Content:         content,
+			Provider:        notifier.ProviderEmail,
+		})
+	}
+	if err != nil {
+		if _, derr := m.q.DeleteSurvey.ExecContext(ctx, uuid); derr != nil {
+			m.lo.Error("error deleting unsent NPS survey", "uuid", uuid, "error", derr)
+		}
+		return err
+	}
+	return nil
+}
+
+// NewToken returns a signed token for the survey.
+func (m *Manager) NewToken(uuid string) string {
+	return uuid + "." + m.sign(uuid)
+}
+
+// ParseToken verifies the signature of a token and returns the survey UUID.
+func (m *Manager) ParseToken(token string) (string, error) {
+	uuid, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(m.sign(uuid))) {
+		return "", ErrInvalidToken
+	}
+	return uuid, nil
+}
+
+// sign creates the HMAC-SHA256 signature of a survey UUID.
+func (m *Manager) sign(uuid string) string {
+	h := hmac.New(sha256.New, m.signingKey)
+	fmt.Fprintf(h, "nps:%s", uuid)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// MakePublicURL returns the public URL of the survey page for a token.
+func (m *Manager) MakePublicURL(appBaseURL, token string) string {
+	return fmt.Sprintf(surveyURL, appBaseURL, token)
+}
+
+// Get retrieves the survey with the given UUID.
+func (m *Manager) Get(uuid string) (models.Survey, error) {
+	var survey models.Survey
+	if err := m.q.GetSurvey.Get(&survey, uuid); err != nil {
+		if err == sql.ErrNoRows {
+			return survey, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.pageNotFound"), nil)
+		}
+		m.lo.Error("error fetching NPS survey", "uuid", uuid, "error", err)
+		return survey, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return survey, nil
+}
+
+// Respond records the response to a survey, only the first response is kept.
+func (m *Manager) Respond(uuid string, rating int, comment string) error {
+	res, err := m.q.UpdateResponse.Exec(uuid, rating, null.NewString(comment, comment != ""))
+	if err != nil {
+		m.lo.Error("error updating NPS survey response", "uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := m.Get(uuid); err != nil {
+			return err
+		}
+		return envelope.NewError(envelope.InputError, m.i18n.T("nps.alreadySubmitted"), nil)
+	}
+	return nil
+}
+
+// Unsubscribe opts the contact of a survey out of surveys.
+func (m *Manager) Unsubscribe(uuid string) error {
+	if _, err := m.q.Unsubscribe.Exec(uuid); err != nil {
+		m.lo.Error("error unsubscribing contact from NPS surveys", "uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
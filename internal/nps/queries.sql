@@ -0,0 +1,47 @@
+-- name: get-due-contacts
+-- Contacts with a conversation in the period (in seconds) that have not been sent a survey within it.
+SELECT u.id, u.first_name, u.last_name, u.email
+FROM users u
+LEFT JOIN contact_preferences p ON p.contact_id = u.id
+WHERE u.type = 'contact'
+    AND u.deleted_at IS NULL
+    AND COALESCE(u.email, '') <> ''
+    AND NOT COALESCE(p.do_not_contact, FALSE)
+    AND NOT COALESCE(p.no_surveys, FALSE)
+    AND NOT EXISTS (
+        SELECT 1 FROM nps_surveys n
+        WHERE n.contact_id = u.id AND n.created_at >= NOW() - $1 * INTERVAL '1 second'
+    )
+    AND EXISTS (
+        SELECT 1 FROM conversations c
+        WHERE c.contact_id = u.id AND c.created_at >= NOW() - $1 * INTERVAL '1 second'
+    )
+ORDER BY u.id
+LIMIT $2;
+
+-- name: insert-survey
+INSERT INTO nps_surveys (contact_id)
+VALUES ($1)
+RETURNING uuid;
+
+-- name: delete-survey
+DELETE FROM nps_surveys WHERE uuid = $1;
+
+-- name: get-survey
+SELECT id, created_at, uuid, contact_id, rating, comment, responded_at
+FROM nps_surveys
+WHERE uuid = $1;
+
+-- name: update-response
+-- Only the first response to a survey is kept.
+UPDATE nps_surveys
+SET rating = $2,
+    comment = $3,
+    responded_at = NOW(),
+    updated_at = NOW()
+WHERE uuid = $1 AND responded_at IS NULL;
+
+-- name: unsubscribe
+INSERT INTO contact_preferences (contact_id, no_surveys)
+SELECT contact_id, TRUE FROM nps_surveys WHERE uuid = $1
+ON CONFLICT (contact_id) DO UPDATE SET no_surveys = TRUE, updated_at = NOW();
@@ -36,15 +36,20 @@ func (l *Limiter) AddRule(rule Rule) {
 	l.rules[rule.Name] = rule
 }
 
-// Check checks if the request should be rate limited for the given rule.
+// Check checks if the request should be rate limited for the given rule, counting requests per client IP.
 func (l *Limiter) Check(ctx *fasthttp.RequestCtx, ruleName string) error {
+	return l.CheckKey(ctx, ruleName, "ip:"+realip.FromRequest(ctx))
+}
+
+// CheckKey checks if the request should be rate limited for the given rule, counting requests per subject,
+// e.g. a user or an API key.
+func (l *Limiter) CheckKey(ctx *fasthttp.RequestCtx, ruleName, subject string) error {
 	rule, ok := l.rules[ruleName]
 	if !ok || !rule.Enabled {
 		return nil
 	}
 
-	clientIP := realip.FromRequest(ctx)
-	key := fmt.Sprintf("rate_limit:%s:%s", ruleName, clientIP)
+	key := fmt.Sprintf("rate_limit:%s:%s", ruleName, subject)
 
 	now := time.Now()
 	nowUnix := now.Unix()
@@ -56,6 +61,7 @@ func (l *Limiter) Check(ctx *fasthttp.RequestCtx, ruleName string) error {
 	pipe.ZRemRangeByScore(ctx, key, "-inf", windowStart)
 	pipe.ZAdd(ctx, key, redis.Z{Score: float64(nowUnix), Member: nowNano})
 	countCmd := pipe.ZCard(ctx, key)
+	oldestCmd := pipe.ZRangeWithScores(ctx, key, 0, 0)
 	pipe.Expire(ctx, key, time.Minute*2)
 	if _, err := pipe.Exec(ctx); err != nil {
 		return nil
@@ -69,7 +75,7 @@ func (l *Limiter) Check(ctx *fasthttp.RequestCtx, ruleName string) error {
 
 	if count > limit {
 		ctx.Response.Header.Set("X-RateLimit-Remaining", "0")
-		ctx.Response.Header.Set("Retry-After", "60")
+		ctx.Response.Header.Set("Retry-After", strconv.FormatInt(retryAfter(oldestCmd.Val(), nowUnix), 10))
 		ctx.Response.Header.Set("Content-Type", "application/json")
 		ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
 		ctx.SetBodyString(`{"status":"error","message":"Rate limit exceeded"}`)
@@ -80,3 +86,11 @@ func (l *Limiter) Check(ctx *fasthttp.RequestCtx, ruleName string) error {
 	ctx.Response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
 	return nil
 }
+
+// retryAfter returns the seconds until the oldest request in the window expires and frees up a slot.
+func retryAfter(oldest []redis.Z, nowUnix int64) int64 {
+	if len(oldest) == 0 {
+		return 60
+	}
+	return min(max(int64(oldest[0].Score)+60-nowUnix, 1), 60)
+}
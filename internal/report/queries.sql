@@ -249,18 +249,19 @@ SELECT
     ) AS result;
 
 -- name: get-overview-csat
+-- The average rating is of surveys on the 1 to 5 stars scale.
 SELECT
     json_build_object(
         'average_rating',
-        COALESCE(AVG(rating) FILTER (WHERE rating > 0), 0),
+        COALESCE(AVG(rating) FILTER (WHERE rating IS NOT NULL AND scale = 'stars'), 0),
         'total_responses',
-        COUNT(*) FILTER (WHERE rating > 0),
+        COUNT(*) FILTER (WHERE rating IS NOT NULL),
         'total_sent',
         COUNT(*),
         'response_rate',
         CASE
             WHEN COUNT(*) > 0
-            THEN ROUND((COUNT(*) FILTER (WHERE rating > 0)::numeric / COUNT(*)::numeric) * 100, 1)
+            THEN ROUND((COUNT(*) FILTER (WHERE rating IS NOT NULL)::numeric / COUNT(*)::numeric) * 100, 1)
             ELSE 0
         END
     ) AS result
@@ -360,4 +361,746 @@ SELECT
         END
     ) AS result
 FROM
-    tagging;
\ No newline at end of file
+    tagging;
+-- name: get-waiting-time-heatmap
+-- Sums the hourly waiting time rollups by weekday (ISO, 1 = Monday) and hour in the app timezone.
+-- Rollups are bucketed by UTC hour, so for timezones with a half hour offset a bucket spans two local hours.
+WITH tz AS (
+    SELECT
+        COALESCE((SELECT value #>> '{}' FROM settings WHERE key = 'app.timezone'), 'UTC') AS name
+),
+buckets AS (
+    SELECT
+        EXTRACT(ISODOW FROM r.hour_start AT TIME ZONE (SELECT name FROM tz))::int AS day,
+        EXTRACT(HOUR FROM r.hour_start AT TIME ZONE (SELECT name FROM tz))::int AS hour,
+        SUM(r.incoming) AS incoming,
+        SUM(r.responses) AS responses,
+        SUM(r.waits_started) AS waits_started,
+        SUM(r.waits_started - r.waits_answered) AS waits_unanswered,
+        CASE WHEN SUM(r.waits_answered) > 0
+            THEN ROUND(SUM(r.wait_sec_total)::numeric / SUM(r.waits_answered), 0)
+            ELSE 0
+        END AS avg_wait_sec
+    FROM
+        waiting_time_rollups r
+    WHERE
+        r.hour_start >= CASE
+            WHEN %d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%d days'
+        END
+    GROUP BY
+        day, hour
+)
+SELECT
+    json_build_object(
+        'timezone', (SELECT name FROM tz),
+        'cells',
+        json_agg(
+            json_build_object(
+                'day', d.day,
+                'hour', h.hour,
+                'incoming', COALESCE(b.incoming, 0),
+                'responses', COALESCE(b.responses, 0),
+                'waits_started', COALESCE(b.waits_started, 0),
+                'waits_unanswered', COALESCE(b.waits_unanswered, 0),
+                'avg_wait_sec', COALESCE(b.avg_wait_sec, 0)
+            ) ORDER BY d.day, h.hour
+        )
+    ) AS result
+FROM
+    generate_series(1, 7) AS d(day)
+    CROSS JOIN generate_series(0, 23) AS h(hour)
+    LEFT JOIN buckets b ON b.day = d.day AND b.hour = h.hour;
+
+-- name: lock-waiting-time-rollup
+-- Returns false if another app instance is rolling up, the lock is held until the end of the transaction.
+SELECT pg_try_advisory_xact_lock(hashtext('rollup_waiting_time'));
+
+-- name: get-waiting-time-rollup-start
+-- Rollups are recomputed for the last $1 hours so waits answered since the last run are counted,
+-- the first run backfills the last $2 days.
+SELECT COALESCE(
+    MAX(hour_start) - MAKE_INTERVAL(hours => $1),
+    date_trunc('hour', NOW()) - MAKE_INTERVAL(days => $2)
+)
+FROM waiting_time_rollups;
+
+-- name: delete-waiting-time-rollups
+-- Deletes the rollups from $1 before they are recomputed, so hours without messages anymore don't keep stale counts.
+DELETE FROM waiting_time_rollups WHERE hour_start >= $1;
+
+-- name: rollup-waiting-time
+-- Rolls up customer messages, agent replies and waits into hourly buckets from $1 up to the current hour.
+-- A wait starts with the first incoming message after an agent reply and ends with the next agent reply.
+-- Messages of the day before $1 are read so the first messages in the range know what came before them.
+WITH msgs AS (
+    SELECT
+        m.conversation_id,
+        m.type,
+        m.created_at,
+        LAG(m.type) OVER (PARTITION BY m.conversation_id ORDER BY m.created_at) AS prev_type
+    FROM
+        conversation_messages m
+    WHERE
+        m.private = false
+        AND (m.type = 'incoming' OR (m.type = 'outgoing' AND m.sender_type = 'agent'))
+        AND (m.meta IS NULL OR NOT COALESCE((m.meta->>'continuity_email')::boolean, false))
+        AND m.created_at >= $1::TIMESTAMPTZ - INTERVAL '1 day'
+),
+transitions AS (
+    SELECT
+        type,
+        created_at,
+        LEAD(type) OVER w AS next_type,
+        LEAD(created_at) OVER w AS next_at
+    FROM
+        msgs
+    WHERE
+        (type = 'incoming' AND prev_type IS DISTINCT FROM 'incoming')
+        OR (type = 'outgoing' AND prev_type = 'incoming')
+    WINDOW w AS (PARTITION BY conversation_id ORDER BY created_at)
+),
+volume AS (
+    SELECT
+        date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour_start,
+        COUNT(*) FILTER (WHERE type = 'incoming') AS incoming,
+        COUNT(*) FILTER (WHERE type = 'outgoing') AS responses
+    FROM
+        msgs
+    WHERE
+        created_at >= $1
+    GROUP BY
+        1
+),
+waits AS (
+    SELECT
+        date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour_start,
+        COUNT(*) AS waits_started,
+        COUNT(*) FILTER (WHERE next_type = 'outgoing') AS waits_answered,
+        COALESCE(SUM(EXTRACT(EPOCH FROM (next_at - created_at))) FILTER (WHERE next_type = 'outgoing'), 0)::BIGINT AS wait_sec_total
+    FROM
+        transitions
+    WHERE
+        type = 'incoming'
+        AND created_at >= $1
+    GROUP BY
+        1
+)
+INSERT INTO waiting_time_rollups (hour_start, incoming, responses, waits_started, waits_answered, wait_sec_total)
+SELECT
+    v.hour_start,
+    v.incoming,
+    v.responses,
+    COALESCE(w.waits_started, 0),
+    COALESCE(w.waits_answered, 0),
+    COALESCE(w.wait_sec_total, 0)
+FROM
+    volume v
+    LEFT JOIN waits w ON w.hour_start = v.hour_start;
+
+-- name: get-overview-sla-incident-breaches
+-- SLA breaches in the period split into those that happened during a declared incident and the rest.
+WITH breaches AS (
+    SELECT 'first_response' AS metric, first_response_incident_id AS incident_id
+    FROM applied_slas
+    WHERE first_response_breached_at >= CASE WHEN %d = 0 THEN CURRENT_DATE ELSE NOW() - INTERVAL '%d days' END
+    UNION ALL
+    SELECT 'resolution', resolution_incident_id
+    FROM applied_slas
+    WHERE resolution_breached_at >= CASE WHEN %d = 0 THEN CURRENT_DATE ELSE NOW() - INTERVAL '%d days' END
+    UNION ALL
+    SELECT 'next_response', incident_id
+    FROM sla_events
+    WHERE breached_at >= CASE WHEN %d = 0 THEN CURRENT_DATE ELSE NOW() - INTERVAL '%d days' END
+)
+SELECT json_build_object(
+    'total', (SELECT COUNT(*) FROM breaches),
+    'during_incident', (SELECT COUNT(*) FROM breaches WHERE incident_id IS NOT NULL),
+    'outside_incident', (SELECT COUNT(*) FROM breaches WHERE incident_id IS NULL),
+    'by_metric', (
+        SELECT COALESCE(json_object_agg(metric, json_build_object('during_incident', during_incident, 'outside_incident', outside_incident)), '{}'::json)
+        FROM (
+            SELECT metric,
+                COUNT(*) FILTER (WHERE incident_id IS NOT NULL) AS during_incident,
+                COUNT(*) FILTER (WHERE incident_id IS NULL) AS outside_incident
+            FROM breaches
+            GROUP BY metric
+        ) m
+    ),
+    'incidents', (
+        SELECT COALESCE(json_agg(json_build_object(
+            'id', i.id,
+            'title', i.title,
+            'starts_at', i.starts_at,
+            'ends_at', i.ends_at,
+            'breaches', b.breaches
+        ) ORDER BY i.starts_at DESC), '[]'::json)
+        FROM (
+            SELECT incident_id, COUNT(*) AS breaches
+            FROM breaches
+            WHERE incident_id IS NOT NULL
+            GROUP BY incident_id
+        ) b
+        JOIN sla_incidents i ON i.id = b.incident_id
+    )
+);
+
+-- name: get-overview-collaboration
+-- Internal collaboration on conversations created in the period: private notes, distinct agents involved,
+-- team reassignments and how long mentioned agents take to respond in the conversation.
+-- Conversations reassigned between teams at least 3 times are listed as ping-ponging.
+WITH convs AS (
+    SELECT id, uuid, reference_number, subject
+    FROM conversations
+    WHERE created_at >= CASE
+        WHEN %d = 0 THEN CURRENT_DATE
+        ELSE NOW() - INTERVAL '%d days'
+    END
+),
+per_conv AS (
+    SELECT
+        c.id,
+        c.uuid,
+        c.reference_number,
+        c.subject,
+        COUNT(m.id) FILTER (WHERE m.type = 'outgoing' AND m.private = true) AS notes,
+        COUNT(DISTINCT m.sender_id) FILTER (WHERE m.type = 'outgoing' AND m.sender_type = 'agent') AS agents,
+        COUNT(m.id) FILTER (WHERE m.type = 'activity' AND m.meta->>'activity_type' = 'assigned_team_change') AS team_changes
+    FROM
+        convs c
+        LEFT JOIN conversation_messages m ON m.conversation_id = c.id
+    GROUP BY
+        c.id, c.uuid, c.reference_number, c.subject
+),
+mention_responses AS (
+    SELECT
+        cm.created_at,
+        (
+            SELECT MIN(m.created_at)
+            FROM conversation_messages m
+            WHERE m.conversation_id = cm.conversation_id
+                AND m.sender_id = cm.mentioned_user_id
+                AND m.type = 'outgoing'
+                AND m.created_at > cm.created_at
+        ) AS responded_at
+    FROM
+        conversation_mentions cm
+        JOIN convs c ON c.id = cm.conversation_id
+    WHERE
+        cm.mentioned_user_id IS NOT NULL
+)
+SELECT
+    json_build_object(
+        'conversations', (SELECT COUNT(*) FROM per_conv),
+        'avg_notes_per_conversation', (SELECT ROUND(COALESCE(AVG(notes), 0)::numeric, 1) FROM per_conv),
+        'avg_agents_per_conversation', (SELECT ROUND(COALESCE(AVG(agents), 0)::numeric, 1) FROM per_conv),
+        'multi_agent_conversations', (SELECT COUNT(*) FROM per_conv WHERE agents > 1),
+        'reassigned_between_teams', (SELECT COUNT(*) FROM per_conv WHERE team_changes > 1),
+        'mentions', (SELECT COUNT(*) FROM mention_responses),
+        'mentions_unanswered', (SELECT COUNT(*) FROM mention_responses WHERE responded_at IS NULL),
+        'avg_mention_response_sec', (
+            SELECT ROUND(COALESCE(AVG(EXTRACT(EPOCH FROM (responded_at - created_at))), 0)::numeric, 0)
+            FROM mention_responses
+            WHERE responded_at IS NOT NULL
+        ),
+        'ping_pong', (
+            SELECT COALESCE(json_agg(row_to_json(p)), '[]'::json)
+            FROM (
+                SELECT uuid, reference_number, subject, team_changes, agents, notes
+                FROM per_conv
+                WHERE team_changes >= 3
+                ORDER BY team_changes DESC, id DESC
+                LIMIT 20
+            ) p
+        )
+    ) AS result;
+
+-- name: get-csat-breakdown
+-- CSAT surveys sent in the period grouped by the conversation's assigned agent, team or inbox (%[2]s),
+-- with a daily series per group. Ratings are only comparable within a scale, so each group has the
+-- average of 1 to 5 stars ratings, the thumbs up rate and the NPS (promoters minus detractors).
+-- Conversations without an assignee are grouped under a NULL id.
+WITH responses AS (
+    SELECT
+        %[2]s AS group_id,
+        r.created_at::date AS day,
+        r.rating,
+        r.scale
+    FROM
+        csat_responses r
+        JOIN conversations c ON c.id = r.conversation_id
+    WHERE
+        r.created_at >= CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END
+),
+stats AS (
+    SELECT
+        group_id,
+        day,
+        COUNT(*) AS sent,
+        COUNT(*) FILTER (WHERE rating IS NOT NULL) AS responses,
+        ROUND(AVG(rating) FILTER (WHERE scale = 'stars')::numeric, 2) AS average_rating,
+        ROUND(
+            100.0 * COUNT(*) FILTER (WHERE scale = 'thumbs' AND rating = 2)
+            / NULLIF(COUNT(*) FILTER (WHERE scale = 'thumbs' AND rating IS NOT NULL), 0), 1
+        ) AS thumbs_up_rate,
+        ROUND(
+            100.0 * (COUNT(*) FILTER (WHERE scale = 'nps' AND rating >= 9) - COUNT(*) FILTER (WHERE scale = 'nps' AND rating <= 6))
+            / NULLIF(COUNT(*) FILTER (WHERE scale = 'nps' AND rating IS NOT NULL), 0), 1
+        ) AS nps
+    FROM
+        responses
+    GROUP BY
+        GROUPING SETS ((group_id), (group_id, day))
+)
+SELECT
+    COALESCE(json_agg(
+        json_build_object(
+            'id', g.group_id,
+            'name', %[3]s,
+            'sent', g.sent,
+            'responses', g.responses,
+            'response_rate', ROUND(100.0 * g.responses / NULLIF(g.sent, 0), 1),
+            'average_rating', g.average_rating,
+            'thumbs_up_rate', g.thumbs_up_rate,
+            'nps', g.nps,
+            'series', (
+                SELECT json_agg(
+                    json_build_object(
+                        'date', d.day,
+                        'sent', d.sent,
+                        'responses', d.responses,
+                        'average_rating', d.average_rating,
+                        'thumbs_up_rate', d.thumbs_up_rate,
+                        'nps', d.nps
+                    ) ORDER BY d.day
+                )
+                FROM stats d
+                WHERE d.day IS NOT NULL AND d.group_id IS NOT DISTINCT FROM g.group_id
+            )
+        ) ORDER BY g.responses DESC, g.sent DESC
+    ), '[]'::json) AS result
+FROM
+    stats g
+WHERE
+    g.day IS NULL;
+
+-- name: get-csat-low-scores
+-- Rated CSAT responses in the period with a low score for their scale: 1 or 2 stars, thumbs down
+-- or an NPS detractor (0 to 6), latest first.
+SELECT
+    COALESCE(json_agg(row_to_json(l)), '[]'::json) AS result
+FROM (
+    SELECT
+        r.uuid,
+        r.rating,
+        r.scale,
+        r.feedback,
+        r.response_timestamp,
+        c.uuid AS conversation_uuid,
+        c.reference_number,
+        c.subject,
+        NULLIF(CONCAT(u.first_name, ' ', u.last_name), ' ') AS agent_name,
+        t.name AS team_name,
+        i.name AS inbox_name
+    FROM
+        csat_responses r
+        JOIN conversations c ON c.id = r.conversation_id
+        JOIN inboxes i ON i.id = c.inbox_id
+        LEFT JOIN users u ON u.id = c.assigned_user_id
+        LEFT JOIN teams t ON t.id = c.assigned_team_id
+    WHERE
+        r.created_at >= CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END
+        AND (
+            (r.scale = 'stars' AND r.rating <= 2)
+            OR (r.scale = 'thumbs' AND r.rating = 1)
+            OR (r.scale = 'nps' AND r.rating <= 6)
+        )
+    ORDER BY
+        r.response_timestamp DESC NULLS LAST, r.id DESC
+    LIMIT %[2]d
+) l;
+
+-- name: get-agent-dashboard
+-- Personal metrics of an agent ($1) for today and this week, and their current workload. Response times are
+-- from a contact's message to the agent's first reply to it. Follow-ups are the agent's snoozed conversations
+-- due to reopen by the end of today, SLAs at risk are the agent's unresolved conversations with an SLA
+-- deadline in the next hour.
+WITH replies AS (
+    SELECT
+        m.created_at,
+        prev.type AS prev_type,
+        prev.created_at AS prev_at
+    FROM
+        conversation_messages m
+        LEFT JOIN LATERAL (
+            SELECT p.type, p.created_at
+            FROM conversation_messages p
+            WHERE p.conversation_id = m.conversation_id
+                AND p.created_at < m.created_at
+                AND p.type IN ('incoming', 'outgoing')
+                AND p.private = false
+            ORDER BY p.created_at DESC
+            LIMIT 1
+        ) prev ON true
+    WHERE
+        m.sender_id = $1
+        AND m.sender_type = 'agent'
+        AND m.type = 'outgoing'
+        AND m.private = false
+        AND m.created_at >= date_trunc('week', NOW())
+),
+periods AS (
+    SELECT 'today' AS period, date_trunc('day', NOW()) AS since
+    UNION ALL
+    SELECT 'week', date_trunc('week', NOW())
+),
+workload AS (
+    SELECT c.uuid, c.reference_number, c.subject, c.last_message_sender, c.snoozed_until, c.next_sla_deadline_at, s.category
+    FROM conversations c
+    JOIN conversation_statuses s ON s.id = c.status_id
+    WHERE c.assigned_user_id = $1
+        AND s.category != 'resolved'
+)
+SELECT
+    json_build_object(
+        'periods', (
+            SELECT json_object_agg(
+                p.period,
+                json_build_object(
+                    'replies', (SELECT COUNT(*) FROM replies r WHERE r.created_at >= p.since),
+                    'resolutions', (
+                        SELECT COUNT(*)
+                        FROM conversations c
+                        WHERE c.assigned_user_id = $1 AND c.resolved_at >= p.since
+                    ),
+                    'median_response_sec', (
+                        SELECT ROUND(COALESCE(
+                            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (r.created_at - r.prev_at))), 0
+                        )::numeric, 0)
+                        FROM replies r
+                        WHERE r.created_at >= p.since AND r.prev_type = 'incoming'
+                    )
+                )
+            )
+            FROM periods p
+        ),
+        'open', (SELECT COUNT(*) FROM workload WHERE category = 'open'),
+        'awaiting_reply', (SELECT COUNT(*) FROM workload WHERE category = 'open' AND last_message_sender = 'contact'),
+        'follow_ups', (
+            SELECT COUNT(*)
+            FROM workload
+            WHERE category = 'waiting' AND snoozed_until < date_trunc('day', NOW()) + INTERVAL '1 day'
+        ),
+        'sla_at_risk', (
+            SELECT COALESCE(json_agg(row_to_json(a) ORDER BY a.next_sla_deadline_at), '[]'::json)
+            FROM (
+                SELECT uuid, reference_number, subject, next_sla_deadline_at
+                FROM workload
+                WHERE next_sla_deadline_at BETWEEN NOW() AND NOW() + INTERVAL '1 hour'
+            ) a
+        )
+    ) AS result;
+
+-- name: get-nps-trend
+-- NPS of the periodic contact surveys sent in the period, overall and by week. The score is the percentage
+-- of promoters (9 or 10) minus the percentage of detractors (0 to 6) among responses.
+WITH surveys AS (
+    SELECT
+        date_trunc('week', created_at)::date AS week,
+        rating
+    FROM
+        nps_surveys
+    WHERE
+        created_at >= CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END
+),
+stats AS (
+    SELECT
+        week,
+        COUNT(*) AS sent,
+        COUNT(rating) AS responses,
+        COUNT(*) FILTER (WHERE rating >= 9) AS promoters,
+        COUNT(*) FILTER (WHERE rating BETWEEN 7 AND 8) AS passives,
+        COUNT(*) FILTER (WHERE rating <= 6) AS detractors
+    FROM
+        surveys
+    GROUP BY
+        GROUPING SETS ((), (week))
+)
+SELECT
+    json_build_object(
+        'total', (
+            SELECT json_build_object(
+                'sent', COALESCE(sent, 0),
+                'responses', COALESCE(responses, 0),
+                'promoters', COALESCE(promoters, 0),
+                'passives', COALESCE(passives, 0),
+                'detractors', COALESCE(detractors, 0),
+                'nps', ROUND(100.0 * (promoters - detractors) / NULLIF(responses, 0), 1)
+            )
+            FROM stats
+            WHERE week IS NULL
+        ),
+        'weeks', (
+            SELECT COALESCE(json_agg(
+                json_build_object(
+                    'week', week,
+                    'sent', sent,
+                    'responses', responses,
+                    'nps', ROUND(100.0 * (promoters - detractors) / NULLIF(responses, 0), 1)
+                ) ORDER BY week
+            ), '[]'::json)
+            FROM stats
+            WHERE week IS NOT NULL
+        )
+    ) AS result;
+
+-- name: get-abuse-volume
+-- Abusive messages flagged in the period by day, and by the agent the conversation was assigned to when
+-- the message arrived, to keep an eye on the abuse agents are exposed to.
+WITH flags AS (
+    SELECT
+        created_at::date AS day,
+        assigned_user_id,
+        contact_id,
+        conversation_id
+    FROM
+        abuse_flags
+    WHERE
+        created_at >= CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END
+)
+SELECT
+    json_build_object(
+        'total', (
+            SELECT json_build_object(
+                'messages', COUNT(*),
+                'contacts', COUNT(DISTINCT contact_id),
+                'conversations', COUNT(DISTINCT conversation_id)
+            )
+            FROM flags
+        ),
+        'days', (
+            SELECT COALESCE(json_agg(d ORDER BY d.day), '[]'::json)
+            FROM (
+                SELECT day, COUNT(*) AS messages
+                FROM flags
+                GROUP BY day
+            ) d
+        ),
+        'agents', (
+            SELECT COALESCE(json_agg(a ORDER BY a.messages DESC), '[]'::json)
+            FROM (
+                SELECT
+                    f.assigned_user_id AS id,
+                    CONCAT(u.first_name, ' ', u.last_name) AS name,
+                    COUNT(*) AS messages,
+                    COUNT(DISTINCT f.contact_id) AS contacts,
+                    COUNT(DISTINCT f.conversation_id) AS conversations
+                FROM flags f
+                LEFT JOIN users u ON u.id = f.assigned_user_id
+                GROUP BY f.assigned_user_id, u.first_name, u.last_name
+            ) a
+        )
+    ) AS result;
+
+-- name: get-assignment-sla
+-- Time conversations waited unassigned in the queue against the assignment SLA target, overall and by the team
+-- the conversation is assigned to.
+WITH events AS (
+    SELECT
+        c.assigned_team_id AS team_id,
+        e.status,
+        e.met_at,
+        e.created_at
+    FROM
+        sla_events e
+        JOIN applied_slas a ON a.id = e.applied_sla_id
+        JOIN conversations c ON c.id = a.conversation_id
+    WHERE
+        e.type = 'assignment'
+        AND e.created_at >= CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END
+)
+SELECT
+    json_build_object(
+        'total', (
+            SELECT json_build_object(
+                'met_count', COUNT(*) FILTER (WHERE status = 'met'),
+                'breached_count', COUNT(*) FILTER (WHERE status = 'breached'),
+                'waiting_count', COUNT(*) FILTER (WHERE met_at IS NULL AND status = 'pending'),
+                'avg_wait_sec', COALESCE(AVG(EXTRACT(EPOCH FROM (met_at - created_at))) FILTER (WHERE met_at IS NOT NULL), 0),
+                'compliance_percent', CASE
+                    WHEN COUNT(*) FILTER (WHERE status IN ('met', 'breached')) > 0
+                    THEN ROUND(COUNT(*) FILTER (WHERE status = 'met')::numeric / COUNT(*) FILTER (WHERE status IN ('met', 'breached'))::numeric * 100, 1)
+                    ELSE 0
+                END
+            )
+            FROM events
+        ),
+        'teams', (
+            SELECT COALESCE(json_agg(t ORDER BY t.breached_count DESC, t.name), '[]'::json)
+            FROM (
+                SELECT
+                    e.team_id AS id,
+                    tm.name,
+                    COUNT(*) FILTER (WHERE e.status = 'met') AS met_count,
+                    COUNT(*) FILTER (WHERE e.status = 'breached') AS breached_count,
+                    COUNT(*) FILTER (WHERE e.met_at IS NULL AND e.status = 'pending') AS waiting_count,
+                    COALESCE(AVG(EXTRACT(EPOCH FROM (e.met_at - e.created_at))) FILTER (WHERE e.met_at IS NOT NULL), 0) AS avg_wait_sec
+                FROM events e
+                LEFT JOIN teams tm ON tm.id = e.team_id
+                GROUP BY e.team_id, tm.name
+            ) t
+        )
+    ) AS result;
+
+-- name: get-tag-trend
+-- Conversation volume of the most used tags in the period, by day, week or month, with the volume of the
+-- previous period of the same length to compare against.
+-- %[1]d = days, %[2]s = interval to bucket by, %[3]d = number of tags.
+WITH period AS (
+    SELECT
+        CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE::timestamptz
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END AS start_at,
+        CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE::timestamptz - INTERVAL '1 day'
+            ELSE NOW() - INTERVAL '%[1]d days' * 2
+        END AS previous_start_at
+),
+tagged AS (
+    SELECT
+        ct.tag_id,
+        c.created_at,
+        c.created_at >= p.start_at AS in_period
+    FROM
+        conversation_tags ct
+        INNER JOIN conversations c ON c.id = ct.conversation_id
+        CROSS JOIN period p
+    WHERE
+        c.created_at >= p.previous_start_at
+),
+top_tags AS (
+    SELECT
+        t.id AS tag_id,
+        t.name AS tag_name,
+        COUNT(*) FILTER (WHERE tg.in_period) AS total,
+        COUNT(*) FILTER (WHERE NOT tg.in_period) AS previous_total
+    FROM
+        tagged tg
+        INNER JOIN tags t ON t.id = tg.tag_id
+    GROUP BY
+        t.id, t.name
+    HAVING
+        COUNT(*) FILTER (WHERE tg.in_period) > 0
+    ORDER BY
+        total DESC, t.name
+    LIMIT %[3]d
+),
+series AS (
+    SELECT
+        tg.tag_id,
+        date_trunc('%[2]s', tg.created_at)::date AS bucket,
+        COUNT(*) AS count
+    FROM
+        tagged tg
+        INNER JOIN top_tags tt ON tt.tag_id = tg.tag_id
+    WHERE
+        tg.in_period
+    GROUP BY
+        tg.tag_id, bucket
+)
+SELECT
+    json_build_object(
+        'interval', '%[2]s',
+        'tags', COALESCE((
+            SELECT json_agg(
+                json_build_object(
+                    'tag_id', tt.tag_id,
+                    'tag_name', tt.tag_name,
+                    'total', tt.total,
+                    'previous_total', tt.previous_total,
+                    'change_percentage', ROUND(100.0 * (tt.total - tt.previous_total) / NULLIF(tt.previous_total, 0), 1),
+                    'series', COALESCE((
+                        SELECT json_agg(json_build_object('bucket', s.bucket, 'count', s.count) ORDER BY s.bucket)
+                        FROM series s
+                        WHERE s.tag_id = tt.tag_id
+                    ), '[]'::json)
+                ) ORDER BY tt.total DESC, tt.tag_name
+            )
+            FROM top_tags tt
+        ), '[]'::json)
+    ) AS result;
+
+-- name: get-tag-co-occurrence
+-- Pairs of tags most often found together on conversations created in the period, with the share of each
+-- tag's conversations that also have the other tag.
+-- %[1]d = days, %[2]d = tag ID the pairs must include or 0 for all, %[3]d = number of pairs.
+WITH tagged AS (
+    SELECT
+        ct.conversation_id,
+        ct.tag_id
+    FROM
+        conversation_tags ct
+        INNER JOIN conversations c ON c.id = ct.conversation_id
+    WHERE
+        c.created_at >= CASE
+            WHEN %[1]d = 0 THEN CURRENT_DATE
+            ELSE NOW() - INTERVAL '%[1]d days'
+        END
+),
+tag_totals AS (
+    SELECT tag_id, COUNT(*) AS total FROM tagged GROUP BY tag_id
+),
+pairs AS (
+    SELECT
+        a.tag_id AS tag_a_id,
+        b.tag_id AS tag_b_id,
+        COUNT(*) AS count
+    FROM
+        tagged a
+        INNER JOIN tagged b ON b.conversation_id = a.conversation_id AND b.tag_id > a.tag_id
+    WHERE
+        %[2]d = 0 OR a.tag_id = %[2]d OR b.tag_id = %[2]d
+    GROUP BY
+        a.tag_id, b.tag_id
+    ORDER BY
+        count DESC
+    LIMIT %[3]d
+)
+SELECT
+    json_build_object(
+        'pairs', COALESCE((
+            SELECT json_agg(
+                json_build_object(
+                    'tag_a_id', p.tag_a_id,
+                    'tag_a_name', ta.name,
+                    'tag_b_id', p.tag_b_id,
+                    'tag_b_name', tb.name,
+                    'count', p.count,
+                    'tag_a_percentage', ROUND(100.0 * p.count / NULLIF(tta.total, 0), 1),
+                    'tag_b_percentage', ROUND(100.0 * p.count / NULLIF(ttb.total, 0), 1)
+                ) ORDER BY p.count DESC, ta.name, tb.name
+            )
+            FROM
+                pairs p
+                INNER JOIN tags ta ON ta.id = p.tag_a_id
+                INNER JOIN tags tb ON tb.id = p.tag_b_id
+                INNER JOIN tag_totals tta ON tta.tag_id = p.tag_a_id
+                INNER JOIN tag_totals ttb ON ttb.tag_id = p.tag_b_id
+        ), '[]'::json)
+    ) AS result;
@@ -7,6 +7,7 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
@@ -43,8 +44,58 @@ type queries struct {
 	GetOverviewCSAT            string `query:"get-overview-csat"`
 	GetOverviewMessageVolume   string `query:"get-overview-message-volume"`
 	GetOverviewTagDistribution string `query:"get-overview-tag-distribution"`
+	GetWaitingTimeHeatmap      string `query:"get-waiting-time-heatmap"`
+	GetSLAIncidentBreaches     string `query:"get-overview-sla-incident-breaches"`
+	GetOverviewCollaboration   string `query:"get-overview-collaboration"`
+	GetCSATBreakdown           string `query:"get-csat-breakdown"`
+	GetCSATLowScores           string `query:"get-csat-low-scores"`
+	GetAgentDashboard          string `query:"get-agent-dashboard"`
+	GetNPSTrend                string `query:"get-nps-trend"`
+	GetAbuseVolume             string `query:"get-abuse-volume"`
+	GetAssignmentSLA           string `query:"get-assignment-sla"`
+	GetTagTrend                string `query:"get-tag-trend"`
+	GetTagCoOccurrence         string `query:"get-tag-co-occurrence"`
+
+	LockWaitingTimeRollup     *sqlx.Stmt `query:"lock-waiting-time-rollup"`
+	GetWaitingTimeRollupStart *sqlx.Stmt `query:"get-waiting-time-rollup-start"`
+	DeleteWaitingTimeRollups  *sqlx.Stmt `query:"delete-waiting-time-rollups"`
+	RollupWaitingTime         *sqlx.Stmt `query:"rollup-waiting-time"`
+}
+
+// csatGroups maps the CSAT breakdown groupings to the conversation column grouped by and the
+// expression for the group's name.
+var csatGroups = map[string][2]string{
+	"agent": {"c.assigned_user_id", "(SELECT CONCAT(first_name, ' ', last_name) FROM users WHERE id = g.group_id)"},
+	"team":  {"c.assigned_team_id", "(SELECT name FROM teams WHERE id = g.group_id)"},
+	"inbox": {"c.inbox_id", "(SELECT name FROM inboxes WHERE id = g.group_id)"},
+}
+
+const (
+	// waitingTimeRollupLookbackHours is how many hours of waiting time rollups are recomputed on every run.
+	// Waits answered later than this stay counted as unanswered in their hour.
+	waitingTimeRollupLookbackHours = 48
+	// waitingTimeRollupBackfillDays is how many days the first waiting time rollup covers.
+	waitingTimeRollupBackfillDays = 90
+)
+
+// maxCSATLowScores is the maximum number of low-score CSAT responses listed.
+const maxCSATLowScores = 100
+
+// tagTrendIntervals are the intervals the tag trend can be bucketed by.
+var tagTrendIntervals = map[string]struct{}{
+	"day":   {},
+	"week":  {},
+	"month": {},
 }
 
+const (
+	// maxTagTrendTags is the maximum number of tags in the tag trend.
+	maxTagTrendTags = 20
+
+	// maxTagPairs is the maximum number of tag pairs in the tag co-occurrence report.
+	maxTagPairs = 100
+)
+
 // New creates and returns a new instance of the Manager.
 func New(opts Opts) (*Manager, error) {
 	var q queries
@@ -196,3 +247,303 @@ func (m *Manager) GetOverviewTagDistribution(days int) (json.RawMessage, error)
 	}
 	return stats, nil
 }
+
+// GetWaitingTimeHeatmap returns incoming message, agent reply and wait time counts bucketed by weekday and hour,
+// summed from the hourly rollups kept by RunWaitingTimeRollup.
+func (m *Manager) GetWaitingTimeHeatmap(days int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetWaitingTimeHeatmap, days, days)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching waiting time heatmap", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetOverviewSLAIncidents returns SLA breaches split by whether they happened during a declared incident.
+func (m *Manager) GetOverviewSLAIncidents(days int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetSLAIncidentBreaches, days, days, days, days, days, days)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching SLA incident breaches", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetOverviewCollaboration returns internal collaboration metrics: notes, agents involved, team reassignments
+// and mention response times, along with the conversations that ping-pong between teams.
+func (m *Manager) GetOverviewCollaboration(days int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetOverviewCollaboration, days, days)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching overview collaboration", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetCSATBreakdown returns CSAT response rates and scores grouped by agent, team or inbox, with a daily series per group.
+func (m *Manager) GetCSATBreakdown(groupBy string, days int) (json.RawMessage, error) {
+	group, ok := csatGroups[groupBy]
+	if !ok {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetCSATBreakdown, days, group[0], group[1])
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching CSAT breakdown", "group_by", groupBy, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetCSATLowScores returns the latest low-score CSAT responses with their conversations, at most limit of them.
+func (m *Manager) GetCSATLowScores(days, limit int) (json.RawMessage, error) {
+	if limit <= 0 || limit > maxCSATLowScores {
+		limit = maxCSATLowScores
+	}
+
+	var responses = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetCSATLowScores, days, limit)
+	if err := tx.Get(&responses, query); err != nil {
+		m.lo.Error("error fetching low-score CSAT responses", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return responses, nil
+}
+
+// GetAgentDashboard returns the personal metrics of an agent for today and this week, and their current workload.
+func (m *Manager) GetAgentDashboard(userID int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	if err := tx.Get(&stats, m.q.GetAgentDashboard, userID); err != nil {
+		m.lo.Error("error fetching agent dashboard", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetNPSTrend returns the score of the periodic NPS surveys of contacts, overall and by week.
+func (m *Manager) GetNPSTrend(days int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetNPSTrend, days)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching NPS trend", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetAbuseVolume returns the abusive messages flagged in the period, overall, by day and by assigned agent.
+func (m *Manager) GetAbuseVolume(days int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetAbuseVolume, days)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching abuse volume", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetAssignmentSLA returns how long conversations waited unassigned in the queue against the assignment SLA
+// target in the period, overall and by team.
+func (m *Manager) GetAssignmentSLA(days int) (json.RawMessage, error) {
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetAssignmentSLA, days)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching assignment SLA report", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetTagTrend returns the conversation volume of the most used limit tags in the period by the given
+// interval, with their volume in the previous period of the same length.
+func (m *Manager) GetTagTrend(days int, interval string, limit int) (json.RawMessage, error) {
+	if _, ok := tagTrendIntervals[interval]; !ok {
+		return nil, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+	if limit <= 0 || limit > maxTagTrendTags {
+		limit = maxTagTrendTags
+	}
+
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetTagTrend, days, interval, limit)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching tag trend", "interval", interval, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// GetTagCoOccurrence returns the pairs of tags most often applied together to conversations in the period,
+// at most limit of them. If tagID is set only pairs including that tag are returned.
+func (m *Manager) GetTagCoOccurrence(days, tagID, limit int) (json.RawMessage, error) {
+	if limit <= 0 || limit > maxTagPairs {
+		limit = maxTagPairs
+	}
+	if tagID < 0 {
+		tagID = 0
+	}
+
+	var stats = json.RawMessage{}
+	tx, err := m.db.BeginTxx(context.Background(), &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(m.q.GetTagCoOccurrence, days, tagID, limit)
+	if err := tx.Get(&stats, query); err != nil {
+		m.lo.Error("error fetching tag co-occurrence", "tag_id", tagID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// RunWaitingTimeRollup keeps the hourly waiting time rollups up to date, rolling up every interval.
+func (m *Manager) RunWaitingTimeRollup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	if err := m.RollupWaitingTime(ctx); err != nil {
+		m.lo.Error("error rolling up waiting time", "error", err)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.RollupWaitingTime(ctx); err != nil {
+				m.lo.Error("error rolling up waiting time", "error", err)
+			}
+		}
+	}
+}
+
+// RollupWaitingTime rolls up the waiting time of the hours since the last rollup, including the current hour.
+// The last waitingTimeRollupLookbackHours are rolled up again to count waits answered since, and the first
+// rollup backfills waitingTimeRollupBackfillDays. It is skipped if another app instance is rolling up.
+func (m *Manager) RollupWaitingTime(ctx context.Context) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting waiting time rollup txn: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.StmtxContext(ctx, m.q.LockWaitingTimeRollup).GetContext(ctx, &locked); err != nil {
+		return fmt.Errorf("locking waiting time rollup: %w", err)
+	}
+	if !locked {
+		return nil
+	}
+
+	var from time.Time
+	if err := tx.StmtxContext(ctx, m.q.GetWaitingTimeRollupStart).GetContext(ctx, &from, waitingTimeRollupLookbackHours, waitingTimeRollupBackfillDays); err != nil {
+		return fmt.Errorf("fetching waiting time rollup start: %w", err)
+	}
+	if _, err := tx.StmtxContext(ctx, m.q.DeleteWaitingTimeRollups).ExecContext(ctx, from); err != nil {
+		return fmt.Errorf("deleting waiting time rollups from %s: %w", from, err)
+	}
+	if _, err := tx.StmtxContext(ctx, m.q.RollupWaitingTime).ExecContext(ctx, from); err != nil {
+		return fmt.Errorf("rolling up waiting time from %s: %w", from, err)
+	}
+	return tx.Commit()
+}
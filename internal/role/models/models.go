@@ -23,4 +23,6 @@ type Role struct {
 	Name        string         `db:"name" json:"name"`
 	Description string         `db:"description" json:"description"`
 	Permissions pq.StringArray `db:"permissions" json:"permissions"`
+	// RequireTwoFactor requires agents with the role to enroll in two-factor authentication.
+	RequireTwoFactor bool `db:"require_two_factor" json:"require_two_factor"`
 }
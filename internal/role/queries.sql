@@ -1,14 +1,14 @@
 -- name: get-all
-SELECT id, created_at, updated_at, name, description, permissions FROM roles;
+SELECT id, created_at, updated_at, name, description, permissions, require_two_factor FROM roles;
 
 -- name: get-role
-SELECT id, created_at, updated_at, name, description, permissions FROM roles where id = $1;
+SELECT id, created_at, updated_at, name, description, permissions, require_two_factor FROM roles where id = $1;
 
 -- name: delete-role
 DELETE FROM roles where id = $1;
 
 -- name: insert-role
-INSERT INTO roles (name, description, permissions) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO roles (name, description, permissions, require_two_factor) VALUES ($1, $2, $3, $4) RETURNING *;
 
 -- name: update-role
-UPDATE roles SET name = $2, description = $3, permissions = $4 WHERE id = $1 RETURNING *;
\ No newline at end of file
+UPDATE roles SET name = $2, description = $3, permissions = $4, require_two_factor = $5 WHERE id = $1 RETURNING *;
@@ -110,7 +110,7 @@ func (u *Manager) Create(r models.Role) (models.Role, error) {
 		return models.Role{}, envelope.NewError(envelope.InputError, u.i18n.Ts("globals.messages.empty", "name", u.i18n.P("globals.terms.permission")), nil)
 	}
 	var result models.Role
-	if err := u.q.Insert.Get(&result, r.Name, r.Description, pq.Array(validPermissions)); err != nil {
+	if err := u.q.Insert.Get(&result, r.Name, r.Description, pq.Array(validPermissions), r.RequireTwoFactor); err != nil {
 		if dbutil.IsUniqueViolationError(err) {
 			return models.Role{}, envelope.NewError(envelope.InputError, u.i18n.T("errors.alreadyExistsRole"), nil)
 		}
@@ -139,7 +139,7 @@ func (u *Manager) Update(id int, r models.Role) (models.Role, error) {
 	}
 
 	var result models.Role
-	if err := u.q.Update.Get(&result, id, r.Name, r.Description, pq.Array(validPermissions)); err != nil {
+	if err := u.q.Update.Get(&result, id, r.Name, r.Description, pq.Array(validPermissions), r.RequireTwoFactor); err != nil {
 		u.lo.Error("error updating role", "error", err)
 		return models.Role{}, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
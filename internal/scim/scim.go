@@ -0,0 +1,379 @@
+// Package scim contains the SCIM 2.0 (RFC 7643, RFC 7644) resource types and the parsing
+// of filters and PATCH operations used to provision agents and teams from identity providers.
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SCIM schema URNs.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	// ContentType is the media type of SCIM requests and responses.
+	ContentType = "application/scim+json"
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidPatch  = errors.New("invalid patch operation")
+	ErrEmailTaken    = errors.New("email already in use")
+
+	// filterRe matches the single `attribute eq "value"` filters sent by identity providers.
+	filterRe = regexp.MustCompile(`(?i)^\s*([a-z.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+	// memberPathRe matches member value paths such as `members[value eq "12"]`.
+	memberPathRe = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+)
+
+// Meta holds resource metadata.
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// Name is the name of a user.
+type Name struct {
+	GivenName  string `json:"givenName"`
+	FamilyName string `json:"familyName"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+// MultiValue is an entry of a multi-valued attribute such as emails or members.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// User is a SCIM user resource.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        Name         `json:"name"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []MultiValue `json:"groups,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// Email returns the primary email of the user, falling back to the first email and then the user name.
+func (u User) Email() string {
+	for _, e := range u.Emails {
+		if e.Primary && e.Value != "" {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 && u.Emails[0].Value != "" {
+		return u.Emails[0].Value
+	}
+	return u.UserName
+}
+
+// Group is a SCIM group resource.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse is a page of resources.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// Error is a SCIM error response.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// NewError returns a SCIM error response for the given HTTP status.
+func NewError(status int, scimType, detail string) Error {
+	return Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+// PatchRequest is a SCIM PATCH request.
+type PatchRequest struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// Operation is a single PATCH operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ParseFilter parses an `attribute eq "value"` filter, returning the lowercased attribute and the value.
+// An empty filter returns empty strings.
+func ParseFilter(filter string) (string, string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+	m := filterRe.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", ErrInvalidFilter
+	}
+	return strings.ToLower(m[1]), strings.ReplaceAll(m[2], `\"`, `"`), nil
+}
+
+// UserPatch holds the user attributes that PATCH operations can change.
+type UserPatch struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Active    bool
+}
+
+// CheckEmail returns ErrEmailTaken if the user userID can't change their email from current to email because
+// another user has it. findID returns the ID of the user with an email, or 0 if there's none.
+func CheckEmail(userID int, current, email string, findID func(email string) (int, error)) error {
+	if strings.EqualFold(current, email) {
+		return nil
+	}
+	id, err := findID(email)
+	if err != nil {
+		return err
+	}
+	if id != 0 && id != userID {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// Apply applies PATCH operations to the user attributes. Attributes that are not stored are ignored.
+func (p *UserPatch) Apply(ops []Operation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		case "remove":
+			// Only clearing the family name is meaningful, other attributes are required.
+			if strings.EqualFold(op.Path, "name.familyName") {
+				p.LastName = ""
+			}
+			continue
+		default:
+			return ErrInvalidPatch
+		}
+
+		// Without a path the value is an object of attributes.
+		if op.Path == "" {
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return ErrInvalidPatch
+			}
+			for k, v := range attrs {
+				if err := p.set(k, v); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := p.set(op.Path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *UserPatch) set(path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		p.Active = active
+	case "username":
+		return unmarshalString(value, &p.Email)
+	case "name.givenname":
+		return unmarshalString(value, &p.FirstName)
+	case "name.familyname":
+		return unmarshalString(value, &p.LastName)
+	case "name":
+		var n Name
+		if err := json.Unmarshal(value, &n); err != nil {
+			return ErrInvalidPatch
+		}
+		if n.GivenName != "" {
+			p.FirstName = n.GivenName
+		}
+		p.LastName = n.FamilyName
+	case `emails[type eq "work"].value`, "emails":
+		var email string
+		if err := unmarshalString(value, &email); err == nil {
+			p.Email = email
+			return nil
+		}
+		var emails []MultiValue
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return ErrInvalidPatch
+		}
+		if e := (User{Emails: emails}).Email(); e != "" {
+			p.Email = e
+		}
+	}
+	return nil
+}
+
+// GroupPatch holds the changes PATCH operations make to a group.
+type GroupPatch struct {
+	DisplayName string
+	// ReplaceMembers is set when the member list is replaced by Members.
+	ReplaceMembers bool
+	Members        []int
+	Add            []int
+	Remove         []int
+}
+
+// Apply applies PATCH operations to the group changes.
+func (p *GroupPatch) Apply(ops []Operation) error {
+	for _, op := range ops {
+		path := strings.TrimSpace(op.Path)
+		switch strings.ToLower(op.Op) {
+		case "add":
+			if !strings.EqualFold(path, "members") {
+				return ErrInvalidPatch
+			}
+			ids, err := memberIDs(op.Value)
+			if err != nil {
+				return err
+			}
+			p.Add = append(p.Add, ids...)
+		case "remove":
+			if m := memberPathRe.FindStringSubmatch(path); m != nil {
+				id, err := strconv.Atoi(m[1])
+				if err != nil {
+					return ErrInvalidPatch
+				}
+				p.Remove = append(p.Remove, id)
+				continue
+			}
+			if !strings.EqualFold(path, "members") {
+				return ErrInvalidPatch
+			}
+			// Removing all members when no values are given.
+			if len(op.Value) == 0 || string(op.Value) == "null" {
+				p.ReplaceMembers, p.Members = true, []int{}
+				continue
+			}
+			ids, err := memberIDs(op.Value)
+			if err != nil {
+				return err
+			}
+			p.Remove = append(p.Remove, ids...)
+		case "replace":
+			switch {
+			case strings.EqualFold(path, "members"):
+				ids, err := memberIDs(op.Value)
+				if err != nil {
+					return err
+				}
+				p.ReplaceMembers, p.Members = true, ids
+			case strings.EqualFold(path, "displayName"):
+				if err := unmarshalString(op.Value, &p.DisplayName); err != nil {
+					return err
+				}
+			case path == "":
+				var g struct {
+					DisplayName string       `json:"displayName"`
+					Members     []MultiValue `json:"members"`
+				}
+				if err := json.Unmarshal(op.Value, &g); err != nil {
+					return ErrInvalidPatch
+				}
+				if g.DisplayName != "" {
+					p.DisplayName = g.DisplayName
+				}
+				if g.Members != nil {
+					ids, err := ParseMemberIDs(g.Members)
+					if err != nil {
+						return err
+					}
+					p.ReplaceMembers, p.Members = true, ids
+				}
+			default:
+				return ErrInvalidPatch
+			}
+		default:
+			return ErrInvalidPatch
+		}
+	}
+	return nil
+}
+
+// ParseMemberIDs returns the user IDs of group members.
+func ParseMemberIDs(members []MultiValue) ([]int, error) {
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.Atoi(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid member %q", ErrInvalidPatch, m.Value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func memberIDs(value json.RawMessage) ([]int, error) {
+	var members []MultiValue
+	if err := json.Unmarshal(value, &members); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	return ParseMemberIDs(members)
+}
+
+// parseBool parses a boolean that some identity providers send as a string, e.g. "False".
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, ErrInvalidPatch
+	}
+	b, err := strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, ErrInvalidPatch
+	}
+	return b, nil
+}
+
+func unmarshalString(value json.RawMessage, out *string) error {
+	if err := json.Unmarshal(value, out); err != nil {
+		return ErrInvalidPatch
+	}
+	return nil
+}
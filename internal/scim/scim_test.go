@@ -0,0 +1,73 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	attr, value, err := ParseFilter(`userName eq "jane@example.com"`)
+	require.NoError(t, err)
+	assert.Equal(t, "username", attr)
+	assert.Equal(t, "jane@example.com", value)
+
+	attr, value, err = ParseFilter("")
+	require.NoError(t, err)
+	assert.Empty(t, attr)
+	assert.Empty(t, value)
+
+	_, _, err = ParseFilter(`userName sw "jane"`)
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestUserPatchApply(t *testing.T) {
+	var req PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations": [
+		{"op": "Replace", "path": "active", "value": "False"},
+		{"op": "replace", "value": {"name.givenName": "Janet", "userName": "janet@example.com"}}
+	]}`), &req))
+
+	p := UserPatch{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Active: true}
+	require.NoError(t, p.Apply(req.Operations))
+	assert.Equal(t, UserPatch{FirstName: "Janet", LastName: "Doe", Email: "janet@example.com", Active: false}, p)
+
+	assert.ErrorIs(t, p.Apply([]Operation{{Op: "move"}}), ErrInvalidPatch)
+}
+
+func TestGroupPatchApply(t *testing.T) {
+	var req PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "3"}, {"value": "4"}]},
+		{"op": "remove", "path": "members[value eq \"5\"]"},
+		{"op": "replace", "path": "displayName", "value": "Billing"}
+	]}`), &req))
+
+	var p GroupPatch
+	require.NoError(t, p.Apply(req.Operations))
+	assert.Equal(t, GroupPatch{DisplayName: "Billing", Add: []int{3, 4}, Remove: []int{5}}, p)
+
+	p = GroupPatch{}
+	require.NoError(t, p.Apply([]Operation{{Op: "remove", Path: "members"}}))
+	assert.True(t, p.ReplaceMembers)
+	assert.Empty(t, p.Members)
+
+	_, err := ParseMemberIDs([]MultiValue{{Value: "abc"}})
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestCheckEmail(t *testing.T) {
+	agents := map[string]int{"jane@example.com": 1, "john@example.com": 2}
+	findID := func(email string) (int, error) { return agents[email], nil }
+
+	assert.NoError(t, CheckEmail(1, "jane@example.com", "jane@example.com", findID))
+	assert.NoError(t, CheckEmail(1, "Jane@example.com", "jane@example.com", findID))
+	assert.NoError(t, CheckEmail(1, "jane@example.com", "janet@example.com", findID))
+	assert.ErrorIs(t, CheckEmail(1, "jane@example.com", "john@example.com", findID), ErrEmailTaken)
+
+	lookupErr := errors.New("db down")
+	assert.ErrorIs(t, CheckEmail(1, "jane@example.com", "janet@example.com", func(string) (int, error) { return 0, lookupErr }), lookupErr)
+}
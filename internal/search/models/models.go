@@ -30,3 +30,12 @@ type ContactResult struct {
 	Email          string      `db:"email" json:"email"`
 	ExternalUserID null.String `db:"external_user_id" json:"external_user_id"`
 }
+
+// MentionResult is an agent or team that can be @-mentioned.
+type MentionResult struct {
+	Type      string      `db:"type" json:"type"`
+	ID        int         `db:"id" json:"id"`
+	Name      string      `db:"name" json:"name"`
+	AvatarURL null.String `db:"avatar_url" json:"avatar_url"`
+	Emoji     null.String `db:"emoji" json:"emoji"`
+}
@@ -49,3 +49,36 @@ WHERE type = 'contact'
 AND deleted_at IS NULL
 AND email ILIKE '%' || $1 || '%'
 LIMIT 15;
+
+-- name: search-mentions
+-- Agents and teams matching $2, ranked by how often the user ($1) mentioned them recently.
+-- When $3 is true only teams the user belongs to and agents sharing one of them are returned.
+WITH my_teams AS (
+    SELECT team_id FROM team_members WHERE user_id = $1
+),
+recent AS (
+    SELECT mentioned_user_id, mentioned_team_id, COUNT(*) AS uses, MAX(created_at) AS last_used_at
+    FROM conversation_mentions
+    WHERE mentioned_by_user_id = $1 AND created_at > NOW() - INTERVAL '90 days'
+    GROUP BY mentioned_user_id, mentioned_team_id
+),
+candidates AS (
+    SELECT 'agent' AS type, u.id, TRIM(CONCAT(u.first_name, ' ', u.last_name)) AS name, u.avatar_url, NULL AS emoji,
+        COALESCE(r.uses, 0) AS uses, r.last_used_at
+    FROM users u
+    LEFT JOIN recent r ON r.mentioned_user_id = u.id
+    WHERE u.type = 'agent' AND u.deleted_at IS NULL AND u.enabled AND u.id <> $1
+    AND (NOT $3 OR EXISTS (SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_id IN (SELECT team_id FROM my_teams)))
+    AND ($2 = '' OR CONCAT(u.first_name, ' ', u.last_name) ILIKE '%' || $2 || '%' OR u.email ILIKE '%' || $2 || '%')
+    UNION ALL
+    SELECT 'team' AS type, t.id, t.name, NULL AS avatar_url, t.emoji,
+        COALESCE(r.uses, 0) AS uses, r.last_used_at
+    FROM teams t
+    LEFT JOIN recent r ON r.mentioned_team_id = t.id
+    WHERE (NOT $3 OR t.id IN (SELECT team_id FROM my_teams))
+    AND ($2 = '' OR t.name ILIKE '%' || $2 || '%')
+)
+SELECT type, id, name, avatar_url, emoji
+FROM candidates
+ORDER BY uses DESC, last_used_at DESC NULLS LAST, name
+LIMIT $4;
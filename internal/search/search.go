@@ -3,6 +3,7 @@ package search
 
 import (
 	"embed"
+	"strings"
 
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
@@ -37,6 +38,7 @@ type queries struct {
 	SearchConversationsByContactEmail *sqlx.Stmt `query:"search-conversations-by-contact-email"`
 	SearchMessages                    *sqlx.Stmt `query:"search-messages"`
 	SearchContacts                    *sqlx.Stmt `query:"search-contacts"`
+	SearchMentions                    *sqlx.Stmt `query:"search-mentions"`
 }
 
 // New creates a new search manager
@@ -83,3 +85,21 @@ func (s *Manager) Contacts(query string) ([]models.ContactResult, error) {
 	}
 	return results, nil
 }
+
+// Mentions returns the agents and teams the user can mention matching the query, recently mentioned ones first.
+// If ownTeamsOnly is set, results are limited to the user's teams and their members.
+func (s *Manager) Mentions(userID int, query string, ownTeamsOnly bool, limit int) ([]models.MentionResult, error) {
+	var results = make([]models.MentionResult, 0)
+	if err := s.q.SearchMentions.Select(&results, userID, escapeLike(query), ownTeamsOnly, limit); err != nil {
+		s.lo.Error("error searching mentions", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, s.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return results, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -29,7 +29,28 @@ type EmailNotification struct {
 	Enabled       bool   `json:"notification.email.enabled" db:"notification.email.enabled"`
 }
 
+// Captcha contains the bot protection settings of public endpoints.
+type Captcha struct {
+	Provider  string   `json:"captcha.provider"`
+	SiteKey   string   `json:"captcha.site_key"`
+	SecretKey string   `json:"captcha.secret_key"`
+	Endpoints []string `json:"captcha.endpoints"`
+}
+
+// Abuse contains the settings of abusive incoming message detection.
+type Abuse struct {
+	Enabled bool `json:"abuse.enabled"`
+	// Words and phrases that flag a message, matched case-insensitively as whole words.
+	Words []string `json:"abuse.words"`
+	// Classifier asks the AI provider about messages the word list doesn't flag.
+	Classifier   bool   `json:"abuse.classifier"`
+	Tag          string `json:"abuse.tag"`
+	NotifyTeamID int    `json:"abuse.notify_team_id"`
+}
+
 type Settings struct {
 	EmailNotification
 	General
+	Captcha
+	Abuse
 }
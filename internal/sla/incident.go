@@ -0,0 +1,142 @@
+package sla
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/sla/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// IncidentSourceManual is the source of incidents declared from within Libredesk.
+const IncidentSourceManual = "manual"
+
+// GetAllIncidents returns all incidents, latest first.
+func (m *Manager) GetAllIncidents() ([]models.Incident, error) {
+	var incidents = make([]models.Incident, 0)
+	if err := m.q.GetAllIncidents.Select(&incidents); err != nil {
+		m.lo.Error("error fetching incidents", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return incidents, nil
+}
+
+// GetIncident returns an incident by ID.
+func (m *Manager) GetIncident(id int) (models.Incident, error) {
+	var incident models.Incident
+	if err := m.q.GetIncident.Get(&incident, id); err != nil {
+		if err == sql.ErrNoRows {
+			return incident, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching incident", "id", id, "error", err)
+		return incident, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return incident, nil
+}
+
+// CreateIncident declares a new incident.
+func (m *Manager) CreateIncident(i models.Incident) (models.Incident, error) {
+	var result models.Incident
+	if err := m.q.InsertIncident.Get(&result, i.Title, i.Description, i.StartsAt, i.EndsAt, i.InboxIDs, i.TagIDs, i.PauseSLA, IncidentSourceManual, nil); err != nil {
+		m.lo.Error("error inserting incident", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if result.EndsAt.Valid {
+		m.extendPausedDeadlines(result)
+	}
+	return result, nil
+}
+
+// UpdateIncident updates an incident. Ending an incident that pauses SLAs extends the deadlines of the affected conversations.
+func (m *Manager) UpdateIncident(id int, i models.Incident) (models.Incident, error) {
+	prev, err := m.GetIncident(id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+
+	var result models.Incident
+	if err := m.q.UpdateIncident.Get(&result, id, i.Title, i.Description, i.StartsAt, i.EndsAt, i.InboxIDs, i.TagIDs, i.PauseSLA); err != nil {
+		m.lo.Error("error updating incident", "id", id, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if !prev.EndsAt.Valid && result.EndsAt.Valid {
+		m.extendPausedDeadlines(result)
+	}
+	return result, nil
+}
+
+// DeleteIncident deletes an incident. Breaches annotated with it are kept but no longer attributed to an incident.
+func (m *Manager) DeleteIncident(id int) error {
+	if _, err := m.q.DeleteIncident.Exec(id); err != nil {
+		m.lo.Error("error deleting incident", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// SyncExternalIncident creates or updates an incident reported by an external status page.
+// New incidents affect all conversations; agents can narrow them down to inboxes or tags afterwards.
+func (m *Manager) SyncExternalIncident(source, externalID, title, description string, startsAt time.Time, endsAt null.Time, pauseSLA bool) (models.Incident, error) {
+	var prev models.Incident
+	if err := m.q.GetIncidentByExternalID.Get(&prev, source, externalID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		m.lo.Error("error fetching external incident", "source", source, "external_id", externalID, "error", err)
+		return prev, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	var result models.Incident
+	if err := m.q.UpsertExternalIncident.Get(&result, title, description, startsAt, endsAt, pauseSLA, source, externalID); err != nil {
+		m.lo.Error("error syncing external incident", "source", source, "external_id", externalID, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if !prev.EndsAt.Valid && result.EndsAt.Valid {
+		m.extendPausedDeadlines(result)
+	}
+	return result, nil
+}
+
+// incidentAt returns the incident affecting a conversation at the given time, if any.
+func (m *Manager) incidentAt(conversationID int, at time.Time) (models.Incident, bool) {
+	var incident models.Incident
+	if err := m.q.GetConversationIncident.Get(&incident, conversationID, at); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			m.lo.Error("error fetching conversation incident", "conversation_id", conversationID, "error", err)
+		}
+		return incident, false
+	}
+	return incident, true
+}
+
+// incidentID returns the ID of the incident affecting a conversation at the given time for annotating breaches.
+func (m *Manager) incidentID(conversationID int, at time.Time) null.Int {
+	if incident, ok := m.incidentAt(conversationID, at); ok {
+		return null.IntFrom(incident.ID)
+	}
+	return null.Int{}
+}
+
+// isPausedAt reports whether the SLA clock of a conversation is paused at the given time by an ongoing incident.
+func (m *Manager) isPausedAt(conversationID int, at time.Time) bool {
+	incident, ok := m.incidentAt(conversationID, at)
+	return ok && incident.PauseSLA && !incident.EndsAt.Valid
+}
+
+// extendPausedDeadlines pushes back the deadlines of SLAs paused by an ended incident and refreshes the
+// next SLA deadline of the affected conversations.
+func (m *Manager) extendPausedDeadlines(incident models.Incident) {
+	if !incident.PauseSLA {
+		return
+	}
+	var conversationIDs []int
+	if err := m.q.ExtendPausedSLADeadlines.Select(&conversationIDs, incident.ID); err != nil {
+		m.lo.Error("error extending SLA deadlines paused by incident", "incident_id", incident.ID, "error", err)
+		return
+	}
+	for _, id := range conversationIDs {
+		if _, err := m.q.UpdateConversationNextSLADeadline.Exec(id, nil); err != nil {
+			m.lo.Error("error updating conversation next SLA deadline", "conversation_id", id, "error", err)
+		}
+	}
+	m.lo.Info("extended SLA deadlines paused by incident", "incident_id", incident.ID, "conversations", len(conversationIDs))
+}
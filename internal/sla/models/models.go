@@ -20,6 +20,7 @@ type SLAPolicy struct {
 	FirstResponseTime null.String      `db:"first_response_time" json:"first_response_time"`
 	NextResponseTime  null.String      `db:"next_response_time" json:"next_response_time"`
 	ResolutionTime    null.String      `db:"resolution_time" json:"resolution_time"`
+	AssignmentTime    null.String      `db:"assignment_time" json:"assignment_time"`
 	Notifications     SlaNotifications `db:"notifications" json:"notifications"`
 }
 
@@ -103,4 +104,24 @@ type SLAEvent struct {
 	DeadlineAt   time.Time `db:"deadline_at"`
 	MetAt        null.Time `db:"met_at"`
 	BreachedAt   null.Time `db:"breached_at"`
+
+	// Conversation fields.
+	ConversationID   int    `db:"conversation_id"`
+	ConversationUUID string `db:"conversation_uuid"`
+}
+
+// Incident is a declared incident during which SLAs of the affected conversations are paused or annotated.
+type Incident struct {
+	ID          int           `db:"id" json:"id"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at" json:"updated_at"`
+	Title       string        `db:"title" json:"title"`
+	Description string        `db:"description" json:"description"`
+	StartsAt    time.Time     `db:"starts_at" json:"starts_at"`
+	EndsAt      null.Time     `db:"ends_at" json:"ends_at"`
+	InboxIDs    pq.Int64Array `db:"inbox_ids" json:"inbox_ids"`
+	TagIDs      pq.Int64Array `db:"tag_ids" json:"tag_ids"`
+	PauseSLA    bool          `db:"pause_sla" json:"pause_sla"`
+	Source      string        `db:"source" json:"source"`
+	ExternalID  null.String   `db:"external_id" json:"external_id"`
 }
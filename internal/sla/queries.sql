@@ -1,8 +1,8 @@
 -- name: get-sla-policy
-SELECT id, name, description, first_response_time, resolution_time, next_response_time, notifications, created_at, updated_at FROM sla_policies WHERE id = $1;
+SELECT id, name, description, first_response_time, resolution_time, next_response_time, assignment_time, notifications, created_at, updated_at FROM sla_policies WHERE id = $1;
 
 -- name: get-all-sla-policies
-SELECT id, name, description, first_response_time, resolution_time, next_response_time, notifications, created_at, updated_at FROM sla_policies ORDER BY updated_at DESC;
+SELECT id, name, description, first_response_time, resolution_time, next_response_time, assignment_time, notifications, created_at, updated_at FROM sla_policies ORDER BY updated_at DESC;
 
 -- name: insert-sla-policy
 INSERT INTO sla_policies (
@@ -11,8 +11,9 @@ INSERT INTO sla_policies (
    first_response_time,
    resolution_time,
    next_response_time,
+   assignment_time,
    notifications
-) VALUES ($1, $2, $3, $4, $5, $6)
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: update-sla-policy
@@ -22,7 +23,8 @@ UPDATE sla_policies SET
    first_response_time = $4,
    resolution_time = $5,
    next_response_time = $6,
-   notifications = $7,
+   assignment_time = $7,
+   notifications = $8,
    updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -47,20 +49,23 @@ SET
    next_sla_deadline_at = LEAST($3, $4)
 FROM new_sla ns
 WHERE c.id = ns.conversation_id
-RETURNING ns.id;
+RETURNING ns.id, c.uuid, c.assigned_user_id IS NOT NULL;
 
 -- name: get-pending-applied-sla
 -- Get all the applied SLAs (applied to a conversation) that are pending
 SELECT a.id, a.first_response_deadline_at, c.first_reply_at as conversation_first_response_at, a.sla_policy_id,
-a.resolution_deadline_at, c.resolved_at as conversation_resolved_at, c.id as conversation_id, a.first_response_met_at, a.resolution_met_at, a.first_response_breached_at, a.resolution_breached_at
+a.resolution_deadline_at, c.resolved_at as conversation_resolved_at, c.id as conversation_id, c.uuid as conversation_uuid, a.first_response_met_at, a.resolution_met_at, a.first_response_breached_at, a.resolution_breached_at
 FROM applied_slas a 
 JOIN conversations c ON a.conversation_id = c.id and c.sla_policy_id = a.sla_policy_id
 WHERE a.status = 'pending'::applied_sla_status;
 
 -- name: update-applied-sla-breached-at
+-- $3 is the incident ongoing at the time of the breach, if any.
 UPDATE applied_slas SET
    first_response_breached_at = CASE WHEN $2 = 'first_response' THEN NOW() ELSE first_response_breached_at END,
    resolution_breached_at = CASE WHEN $2 = 'resolution' THEN NOW() ELSE resolution_breached_at END,
+   first_response_incident_id = CASE WHEN $2 = 'first_response' THEN $3 ELSE first_response_incident_id END,
+   resolution_incident_id = CASE WHEN $2 = 'resolution' THEN $3 ELSE resolution_incident_id END,
    updated_at = NOW()
 WHERE id = $1;
 
@@ -170,6 +175,15 @@ WHERE NOT EXISTS (
 )
 RETURNING id;
 
+-- name: insert-assignment-sla-event
+INSERT INTO sla_events (applied_sla_id, sla_policy_id, type, deadline_at)
+SELECT $1, $2, 'assignment', $3
+WHERE NOT EXISTS (
+  SELECT 1 FROM sla_events
+  WHERE applied_sla_id = $1 AND type = 'assignment' AND met_at IS NULL
+)
+RETURNING id;
+
 -- name: set-latest-sla-event-met-at
 UPDATE sla_events
 SET met_at = NOW()
@@ -184,7 +198,8 @@ RETURNING met_at;
 -- name: update-sla-event-as-breached
 UPDATE sla_events
 SET breached_at = NOW(),
-    status = 'breached'
+    status = 'breached',
+    incident_id = $2
 WHERE id = $1;
 
 -- name: update-sla-event-as-met
@@ -193,11 +208,194 @@ SET status = 'met'
 WHERE id = $1;
 
 -- name: get-sla-event
-SELECT id, created_at, updated_at, applied_sla_id, sla_policy_id, type, deadline_at, met_at, breached_at
-FROM sla_events
-WHERE id = $1;
+SELECT e.id, e.created_at, e.updated_at, e.applied_sla_id, e.sla_policy_id, e.type, e.deadline_at, e.met_at, e.breached_at,
+   a.conversation_id, c.uuid as conversation_uuid
+FROM sla_events e
+JOIN applied_slas a ON a.id = e.applied_sla_id
+JOIN conversations c ON c.id = a.conversation_id
+WHERE e.id = $1;
 
 -- name: get-pending-sla-events
 SELECT id
 FROM sla_events
 WHERE status = 'pending' AND deadline_at IS NOT NULL;
+
+-- name: get-all-incidents
+SELECT id, created_at, updated_at, title, description, starts_at, ends_at, inbox_ids, tag_ids, pause_sla, source, external_id
+FROM sla_incidents
+ORDER BY starts_at DESC;
+
+-- name: get-incident
+SELECT id, created_at, updated_at, title, description, starts_at, ends_at, inbox_ids, tag_ids, pause_sla, source, external_id
+FROM sla_incidents
+WHERE id = $1;
+
+-- name: insert-incident
+INSERT INTO sla_incidents (title, description, starts_at, ends_at, inbox_ids, tag_ids, pause_sla, source, external_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: update-incident
+UPDATE sla_incidents SET
+   title = $2,
+   description = $3,
+   starts_at = $4,
+   ends_at = $5,
+   inbox_ids = $6,
+   tag_ids = $7,
+   pause_sla = $8,
+   updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: get-incident-by-external-id
+SELECT id, created_at, updated_at, title, description, starts_at, ends_at, inbox_ids, tag_ids, pause_sla, source, external_id
+FROM sla_incidents
+WHERE source = $1 AND external_id = $2;
+
+-- name: upsert-external-incident
+-- Scope and pause behaviour of an already synced incident are left as edited by agents.
+INSERT INTO sla_incidents (title, description, starts_at, ends_at, pause_sla, source, external_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (source, external_id) DO UPDATE SET
+   title = EXCLUDED.title,
+   description = EXCLUDED.description,
+   ends_at = EXCLUDED.ends_at,
+   updated_at = NOW()
+RETURNING *;
+
+-- name: delete-incident
+DELETE FROM sla_incidents WHERE id = $1;
+
+-- name: get-conversation-incident
+-- Returns the incident affecting a conversation at the given time, preferring incidents that pause SLAs.
+SELECT i.id, i.created_at, i.updated_at, i.title, i.description, i.starts_at, i.ends_at, i.inbox_ids, i.tag_ids, i.pause_sla, i.source, i.external_id
+FROM sla_incidents i
+JOIN conversations c ON c.id = $1
+WHERE i.starts_at <= $2
+AND (i.ends_at IS NULL OR i.ends_at >= $2)
+AND (
+   (cardinality(i.inbox_ids) = 0 AND cardinality(i.tag_ids) = 0)
+   OR c.inbox_id = ANY(i.inbox_ids)
+   OR EXISTS (SELECT 1 FROM conversation_tags ct WHERE ct.conversation_id = c.id AND ct.tag_id = ANY(i.tag_ids))
+)
+ORDER BY i.pause_sla DESC, i.starts_at
+LIMIT 1;
+
+-- name: extend-paused-sla-deadlines
+-- Once a pausing incident has ended, pushes the unmet deadlines of affected pending SLAs that fell after the incident
+-- started back by the time the SLA clock was paused. Returns the affected conversation IDs.
+WITH incident AS (
+   SELECT * FROM sla_incidents WHERE id = $1 AND pause_sla AND ends_at IS NOT NULL
+),
+affected AS (
+   SELECT a.id, a.conversation_id, i.ends_at - GREATEST(i.starts_at, a.created_at) AS paused_for, i.starts_at
+   FROM applied_slas a
+   JOIN conversations c ON c.id = a.conversation_id
+   CROSS JOIN incident i
+   WHERE a.status = 'pending'::applied_sla_status
+   AND a.created_at < i.ends_at
+   AND (
+      (cardinality(i.inbox_ids) = 0 AND cardinality(i.tag_ids) = 0)
+      OR c.inbox_id = ANY(i.inbox_ids)
+      OR EXISTS (SELECT 1 FROM conversation_tags ct WHERE ct.conversation_id = c.id AND ct.tag_id = ANY(i.tag_ids))
+   )
+),
+extended_events AS (
+   UPDATE sla_events e SET
+      deadline_at = e.deadline_at + (i.ends_at - GREATEST(i.starts_at, e.created_at)),
+      updated_at = NOW()
+   FROM affected af
+   CROSS JOIN incident i
+   WHERE e.applied_sla_id = af.id
+   AND e.status = 'pending'
+   AND e.met_at IS NULL
+   AND e.created_at < i.ends_at
+   AND e.deadline_at >= i.starts_at
+)
+UPDATE applied_slas a SET
+   first_response_deadline_at = CASE
+      WHEN a.first_response_met_at IS NULL AND a.first_response_breached_at IS NULL AND a.first_response_deadline_at >= af.starts_at
+      THEN a.first_response_deadline_at + af.paused_for
+      ELSE a.first_response_deadline_at
+   END,
+   resolution_deadline_at = CASE
+      WHEN a.resolution_met_at IS NULL AND a.resolution_breached_at IS NULL AND a.resolution_deadline_at >= af.starts_at
+      THEN a.resolution_deadline_at + af.paused_for
+      ELSE a.resolution_deadline_at
+   END,
+   updated_at = NOW()
+FROM affected af
+WHERE a.id = af.id
+RETURNING a.conversation_id;
+
+-- name: recalculate-applied-slas
+-- Recomputes the first response and resolution states of the SLAs applied to conversations from the conversation
+-- reply and resolution times. Metrics without a met time keep a recorded breach once their deadline has passed and
+-- are otherwise left pending for the evaluator. Metrics breached during an incident are left as they are.
+UPDATE applied_slas a SET
+   first_response_met_at = CASE
+      WHEN a.first_response_incident_id IS NOT NULL OR a.first_response_deadline_at IS NULL THEN a.first_response_met_at
+      WHEN c.first_reply_at <= a.first_response_deadline_at THEN c.first_reply_at
+   END,
+   first_response_breached_at = CASE
+      WHEN a.first_response_incident_id IS NOT NULL OR a.first_response_deadline_at IS NULL THEN a.first_response_breached_at
+      WHEN c.first_reply_at > a.first_response_deadline_at THEN COALESCE(a.first_response_breached_at, a.first_response_deadline_at)
+      WHEN c.first_reply_at IS NULL AND a.first_response_deadline_at < NOW() THEN a.first_response_breached_at
+   END,
+   resolution_met_at = CASE
+      WHEN a.resolution_incident_id IS NOT NULL OR a.resolution_deadline_at IS NULL THEN a.resolution_met_at
+      WHEN c.resolved_at <= a.resolution_deadline_at THEN c.resolved_at
+   END,
+   resolution_breached_at = CASE
+      WHEN a.resolution_incident_id IS NOT NULL OR a.resolution_deadline_at IS NULL THEN a.resolution_breached_at
+      WHEN c.resolved_at > a.resolution_deadline_at THEN COALESCE(a.resolution_breached_at, a.resolution_deadline_at)
+      WHEN c.resolved_at IS NULL AND a.resolution_deadline_at < NOW() THEN a.resolution_breached_at
+   END,
+   updated_at = NOW()
+FROM conversations c
+WHERE c.id = a.conversation_id AND a.conversation_id = ANY($1::BIGINT[]);
+
+-- name: recalculate-applied-sla-statuses
+UPDATE applied_slas
+SET
+  status = CASE
+     WHEN first_response_met_at IS NOT NULL AND resolution_met_at IS NOT NULL THEN 'met'::applied_sla_status
+     WHEN first_response_breached_at IS NOT NULL AND resolution_breached_at IS NOT NULL THEN 'breached'::applied_sla_status
+     WHEN (first_response_met_at IS NOT NULL OR first_response_breached_at IS NOT NULL)
+          AND (resolution_met_at IS NOT NULL OR resolution_breached_at IS NOT NULL) THEN 'partially_met'::applied_sla_status
+     ELSE 'pending'::applied_sla_status
+  END,
+  updated_at = NOW()
+WHERE conversation_id = ANY($1::BIGINT[]);
+
+-- name: recalculate-next-response-sla-events
+-- Recomputes next response SLA events of conversations, each met by the first agent reply ($2 is the system user)
+-- sent after the event was created. Events without a reply keep a recorded breach once their deadline has passed
+-- and are otherwise left pending for the evaluator. Events breached during an incident are left as they are.
+WITH replies AS (
+   SELECT e.id, (
+      SELECT MIN(m.created_at) FROM conversation_messages m
+      WHERE m.conversation_id = a.conversation_id AND m.created_at >= e.created_at
+      AND m.type = 'outgoing' AND m.private = false AND m.status = 'sent'
+      AND m.sender_type = 'agent' AND m.sender_id <> $2
+   ) AS replied_at
+   FROM sla_events e
+   JOIN applied_slas a ON a.id = e.applied_sla_id
+   WHERE a.conversation_id = ANY($1::BIGINT[]) AND e.type = 'next_response' AND e.incident_id IS NULL
+)
+UPDATE sla_events e SET
+   met_at = r.replied_at,
+   breached_at = CASE
+      WHEN r.replied_at > e.deadline_at THEN COALESCE(e.breached_at, e.deadline_at)
+      WHEN r.replied_at IS NULL AND e.deadline_at < NOW() THEN e.breached_at
+   END,
+   status = CASE
+      WHEN r.replied_at <= e.deadline_at THEN 'met'::sla_event_status
+      WHEN r.replied_at > e.deadline_at THEN 'breached'::sla_event_status
+      WHEN e.deadline_at < NOW() AND e.breached_at IS NOT NULL THEN 'breached'::sla_event_status
+      ELSE 'pending'::sla_event_status
+   END,
+   updated_at = NOW()
+FROM replies r
+WHERE e.id = r.id;
@@ -23,6 +23,7 @@ import (
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
 	"github.com/abhinavxd/libredesk/internal/template"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 	"github.com/knadh/go-i18n"
@@ -42,6 +43,7 @@ const (
 	MetricFirstResponse = "first_response"
 	MetricResolution    = "resolution"
 	MetricNextResponse  = "next_response"
+	MetricAssignment    = "assignment"
 	MetricAll           = "all"
 
 	NotificationTypeWarning = "warning"
@@ -52,6 +54,7 @@ var metricLabels = map[string]string{
 	MetricFirstResponse: "First response",
 	MetricResolution:    "Resolution",
 	MetricNextResponse:  "Next response",
+	MetricAssignment:    "Assignment",
 }
 
 type Manager struct {
@@ -64,6 +67,7 @@ type Manager struct {
 	businessHrsStore businessHrsStore
 	template         *template.Manager
 	dispatcher       *notifier.Dispatcher
+	webhookStore     webhookStore
 	wg               sync.WaitGroup
 	opts             Opts
 }
@@ -80,6 +84,7 @@ type Deadlines struct {
 	FirstResponse null.Time
 	Resolution    null.Time
 	NextResponse  null.Time
+	Assignment    null.Time
 }
 
 // Breaches holds the breach timestamps for an SLA policy.
@@ -87,6 +92,7 @@ type Breaches struct {
 	FirstResponse null.Time
 	Resolution    null.Time
 	NextResponse  null.Time
+	Assignment    null.Time
 }
 
 type teamStore interface {
@@ -105,6 +111,10 @@ type businessHrsStore interface {
 	Get(id int) (bmodels.BusinessHours, error)
 }
 
+type webhookStore interface {
+	TriggerEvent(event wmodels.WebhookEvent, data any)
+}
+
 // queries hold prepared SQL queries.
 type queries struct {
 	GetSLAPolicy                      *sqlx.Stmt `query:"get-sla-policy"`
@@ -117,17 +127,30 @@ type queries struct {
 	InsertScheduledSLANotification    *sqlx.Stmt `query:"insert-scheduled-sla-notification"`
 	InsertSLAPolicy                   *sqlx.Stmt `query:"insert-sla-policy"`
 	InsertNextResponseSLAEvent        *sqlx.Stmt `query:"insert-next-response-sla-event"`
+	InsertAssignmentSLAEvent          *sqlx.Stmt `query:"insert-assignment-sla-event"`
 	UpdateSLAPolicy                   *sqlx.Stmt `query:"update-sla-policy"`
 	UpdateAppliedSLABreachedAt        *sqlx.Stmt `query:"update-applied-sla-breached-at"`
 	UpdateAppliedSLAMetAt             *sqlx.Stmt `query:"update-applied-sla-met-at"`
 	UpdateConversationNextSLADeadline *sqlx.Stmt `query:"update-conversation-sla-deadline"`
 	UpdateAppliedSLAStatus            *sqlx.Stmt `query:"update-applied-sla-status"`
+	RecalculateAppliedSLAs            *sqlx.Stmt `query:"recalculate-applied-slas"`
+	RecalculateAppliedSLAStatuses     *sqlx.Stmt `query:"recalculate-applied-sla-statuses"`
+	RecalculateNextResponseSLAEvents  *sqlx.Stmt `query:"recalculate-next-response-sla-events"`
 	UpdateSLANotificationProcessed    *sqlx.Stmt `query:"update-notification-processed"`
 	UpdateSLAEventAsBreached          *sqlx.Stmt `query:"update-sla-event-as-breached"`
 	UpdateSLAEventAsMet               *sqlx.Stmt `query:"update-sla-event-as-met"`
 	SetLatestSLAEventMetAt            *sqlx.Stmt `query:"set-latest-sla-event-met-at"`
 	ApplySLA                          *sqlx.Stmt `query:"apply-sla"`
 	DeleteSLAPolicy                   *sqlx.Stmt `query:"delete-sla-policy"`
+	GetAllIncidents                   *sqlx.Stmt `query:"get-all-incidents"`
+	GetIncident                       *sqlx.Stmt `query:"get-incident"`
+	GetIncidentByExternalID           *sqlx.Stmt `query:"get-incident-by-external-id"`
+	GetConversationIncident           *sqlx.Stmt `query:"get-conversation-incident"`
+	InsertIncident                    *sqlx.Stmt `query:"insert-incident"`
+	UpdateIncident                    *sqlx.Stmt `query:"update-incident"`
+	UpsertExternalIncident            *sqlx.Stmt `query:"upsert-external-incident"`
+	DeleteIncident                    *sqlx.Stmt `query:"delete-incident"`
+	ExtendPausedSLADeadlines          *sqlx.Stmt `query:"extend-paused-sla-deadlines"`
 }
 
 // New creates a new SLA manager.
@@ -139,6 +162,7 @@ func New(
 	template *template.Manager,
 	userStore userStore,
 	dispatcher *notifier.Dispatcher,
+	webhookStore webhookStore,
 ) (*Manager, error) {
 	var q queries
 	if err := dbutil.ScanSQLFile(
@@ -159,6 +183,7 @@ func New(
 		template:         template,
 		userStore:        userStore,
 		dispatcher:       dispatcher,
+		webhookStore:     webhookStore,
 		opts:             opts,
 	}, nil
 }
@@ -187,9 +212,9 @@ func (m *Manager) GetAll() ([]models.SLAPolicy, error) {
 }
 
 // Create creates a new SLA policy.
-func (m *Manager) Create(name, description string, firstResponseTime, resolutionTime, nextResponseTime null.String, notifications models.SlaNotifications) (models.SLAPolicy, error) {
+func (m *Manager) Create(name, description string, firstResponseTime, resolutionTime, nextResponseTime, assignmentTime null.String, notifications models.SlaNotifications) (models.SLAPolicy, error) {
 	var result models.SLAPolicy
-	if err := m.q.InsertSLAPolicy.Get(&result, name, description, firstResponseTime, resolutionTime, nextResponseTime, notifications); err != nil {
+	if err := m.q.InsertSLAPolicy.Get(&result, name, description, firstResponseTime, resolutionTime, nextResponseTime, assignmentTime, notifications); err != nil {
 		m.lo.Error("error inserting SLA", "error", err)
 		return models.SLAPolicy{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -197,9 +222,9 @@ func (m *Manager) Create(name, description string, firstResponseTime, resolution
 }
 
 // Update updates a SLA policy.
-func (m *Manager) Update(id int, name, description string, firstResponseTime, resolutionTime, nextResponseTime null.String, notifications models.SlaNotifications) (models.SLAPolicy, error) {
+func (m *Manager) Update(id int, name, description string, firstResponseTime, resolutionTime, nextResponseTime, assignmentTime null.String, notifications models.SlaNotifications) (models.SLAPolicy, error) {
 	var result models.SLAPolicy
-	if err := m.q.UpdateSLAPolicy.Get(&result, id, name, description, firstResponseTime, resolutionTime, nextResponseTime, notifications); err != nil {
+	if err := m.q.UpdateSLAPolicy.Get(&result, id, name, description, firstResponseTime, resolutionTime, nextResponseTime, assignmentTime, notifications); err != nil {
 		m.lo.Error("error updating SLA", "error", err)
 		return models.SLAPolicy{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -219,7 +244,7 @@ func (m *Manager) Delete(id int) error {
 func (m *Manager) GetDeadlines(startTime time.Time, slaPolicyID, assignedTeamID int) (Deadlines, error) {
 	var deadlines Deadlines
 
-	businessHrs, timezone, err := m.getBusinessHoursAndTimezone(assignedTeamID)
+	businessHrs, timezone, err := m.GetBusinessHoursAndTimezone(assignedTeamID)
 	if err != nil {
 		return deadlines, err
 	}
@@ -256,6 +281,9 @@ func (m *Manager) GetDeadlines(startTime time.Time, slaPolicyID, assignedTeamID
 	if deadlines.NextResponse, err = calculateDeadline(sla.NextResponseTime.String); err != nil {
 		return deadlines, err
 	}
+	if deadlines.Assignment, err = calculateDeadline(sla.AssignmentTime.String); err != nil {
+		return deadlines, err
+	}
 	return deadlines, nil
 }
 
@@ -268,17 +296,22 @@ func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID,
 	if err != nil {
 		return sla, err
 	}
-	// Next response is not set at this point, next response are stored in SLA events as there can be multiple entries for next response.
+	// Next response and assignment are not set at this point, they are stored in SLA events as there can be multiple entries for them.
 	deadlines.NextResponse = null.Time{}
+	deadlines.Assignment = null.Time{}
 
 	// Insert applied SLA entry.
-	var appliedSLAID int
+	var (
+		appliedSLAID     int
+		conversationUUID string
+		assigned         bool
+	)
 	if err := m.q.ApplySLA.QueryRowx(
 		conversationID,
 		slaPolicyID,
 		deadlines.FirstResponse,
 		deadlines.Resolution,
-	).Scan(&appliedSLAID); err != nil {
+	).Scan(&appliedSLAID, &conversationUUID, &assigned); err != nil {
 		m.lo.Error("error applying SLA", "error", err)
 		return sla, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -291,6 +324,23 @@ func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID,
 	}
 	m.createNotificationSchedule(sla.Notifications, appliedSLAID, null.Int{}, deadlines, Breaches{})
 
+	m.webhookStore.TriggerEvent(wmodels.EventSLAApplied, map[string]any{
+		"conversation_id":            conversationID,
+		"conversation_uuid":          conversationUUID,
+		"applied_sla_id":             appliedSLAID,
+		"sla_policy_id":              sla.ID,
+		"sla_policy_name":            sla.Name,
+		"first_response_deadline_at": deadlines.FirstResponse,
+		"resolution_deadline_at":     deadlines.Resolution,
+	})
+
+	// The conversation waits in the queue until it is assigned to an agent.
+	if !assigned {
+		if _, err := m.CreateAssignmentSLAEvent(conversationID, appliedSLAID, slaPolicyID, assignedTeamID); err != nil && !errors.Is(err, ErrUnmetSLAEventAlreadyExists) {
+			m.lo.Error("error creating assignment SLA event", "conversation_id", conversationID, "applied_sla_id", appliedSLAID, "error", err)
+		}
+	}
+
 	return sla, nil
 }
 
@@ -359,13 +409,54 @@ func (m *Manager) CreateNextResponseSLAEvent(conversationID, appliedSLAID, slaPo
 	}
 
 	// Create notification schedule for the next response SLA event.
-	deadlines.FirstResponse = null.Time{}
-	deadlines.Resolution = null.Time{}
-	m.createNotificationSchedule(slaPolicy.Notifications, appliedSLAID, null.IntFrom(slaEventID), deadlines, Breaches{})
+	m.createNotificationSchedule(slaPolicy.Notifications, appliedSLAID, null.IntFrom(slaEventID), Deadlines{NextResponse: deadlines.NextResponse}, Breaches{})
 
 	return deadlines.NextResponse.Time, nil
 }
 
+// CreateAssignmentSLAEvent creates an assignment SLA event for a conversation that entered the unassigned queue,
+// the event is met once the conversation is assigned to an agent. Returns a zero time without creating an event if the
+// SLA policy has no assignment time set.
+func (m *Manager) CreateAssignmentSLAEvent(conversationID, appliedSLAID, slaPolicyID, assignedTeamID int) (time.Time, error) {
+	slaPolicy, err := m.Get(slaPolicyID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if slaPolicy.AssignmentTime.String == "" {
+		return time.Time{}, nil
+	}
+
+	// Time in the queue is counted from now, not from the creation of the conversation.
+	deadlines, err := m.GetDeadlines(time.Now(), slaPolicy.ID, assignedTeamID)
+	if err != nil {
+		m.lo.Error("error calculating deadlines for assignment SLA event", "error", err)
+		return time.Time{}, fmt.Errorf("calculating deadlines for assignment SLA event: %w", err)
+	}
+	if deadlines.Assignment.IsZero() {
+		return time.Time{}, nil
+	}
+
+	var slaEventID int
+	if err := m.q.InsertAssignmentSLAEvent.QueryRow(appliedSLAID, slaPolicyID, deadlines.Assignment).Scan(&slaEventID); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, ErrUnmetSLAEventAlreadyExists
+		}
+		m.lo.Error("error inserting assignment SLA event", "error", err, "conversation_id", conversationID, "applied_sla_id", appliedSLAID)
+		return time.Time{}, fmt.Errorf("inserting assignment SLA event (applied_sla: %d): %w", appliedSLAID, err)
+	}
+
+	// Update next SLA deadline (SLA target) in the conversation.
+	if _, err := m.q.UpdateConversationNextSLADeadline.Exec(conversationID, deadlines.Assignment); err != nil {
+		m.lo.Error("error updating conversation next SLA deadline", "error", err, "conversation_id", conversationID, "applied_sla_id", appliedSLAID)
+		return time.Time{}, fmt.Errorf("updating conversation next SLA deadline (applied_sla: %d): %w", appliedSLAID, err)
+	}
+
+	// Create notification schedule for the assignment SLA event.
+	m.createNotificationSchedule(slaPolicy.Notifications, appliedSLAID, null.IntFrom(slaEventID), Deadlines{Assignment: deadlines.Assignment}, Breaches{})
+
+	return deadlines.Assignment.Time, nil
+}
+
 // SetLatestSLAEventMetAt marks the latest SLA event as met for a given applied SLA.
 func (m *Manager) SetLatestSLAEventMetAt(appliedSLAID int, metric string) (time.Time, error) {
 	var metAt time.Time
@@ -380,6 +471,26 @@ func (m *Manager) SetLatestSLAEventMetAt(appliedSLAID int, metric string) (time.
 	return metAt, nil
 }
 
+// RecalculateConversationSLAs recomputes the first response, resolution and next response states of the SLAs
+// applied to conversations from their stored reply and resolution times and their messages. Replies sent by
+// systemUserID don't count as responses. No breach notifications or webhooks are sent for the recomputed states.
+func (m *Manager) RecalculateConversationSLAs(conversationIDs []int, systemUserID int) error {
+	ids := pq.Array(conversationIDs)
+	if _, err := m.q.RecalculateAppliedSLAs.Exec(ids); err != nil {
+		m.lo.Error("error recalculating applied SLAs", "error", err)
+		return fmt.Errorf("recalculating applied SLAs: %w", err)
+	}
+	if _, err := m.q.RecalculateAppliedSLAStatuses.Exec(ids); err != nil {
+		m.lo.Error("error recalculating applied SLA statuses", "error", err)
+		return fmt.Errorf("recalculating applied SLA statuses: %w", err)
+	}
+	if _, err := m.q.RecalculateNextResponseSLAEvents.Exec(ids, systemUserID); err != nil {
+		m.lo.Error("error recalculating next response SLA events", "error", err)
+		return fmt.Errorf("recalculating next response SLA events: %w", err)
+	}
+	return nil
+}
+
 // evaluatePendingSLAEvents fetches pending SLA events, updates their status based on deadlines, and schedules notifications for breached SLAs.
 func (m *Manager) evaluatePendingSLAEvents(ctx context.Context) error {
 	var slaEvents []models.SLAEvent
@@ -412,22 +523,31 @@ func (m *Manager) evaluatePendingSLAEvents(ctx context.Context) error {
 			continue
 		}
 
+		// Deadline passed while the SLA is paused by an ongoing incident, the deadline is extended once the incident ends.
+		// Met while paused is counted as met.
+		paused := time.Now().After(event.DeadlineAt) && m.isPausedAt(event.ConversationID, event.DeadlineAt)
+		if paused && !event.MetAt.Valid {
+			continue
+		}
+
 		// Met at after the deadline or current time is after the deadline - mark event breached.
 		var hasBreached bool
-		if (event.MetAt.Valid && event.MetAt.Time.After(event.DeadlineAt)) || (time.Now().After(event.DeadlineAt) && !event.MetAt.Valid) {
+		if !paused && ((event.MetAt.Valid && event.MetAt.Time.After(event.DeadlineAt)) || (time.Now().After(event.DeadlineAt) && !event.MetAt.Valid)) {
 			hasBreached = true
-			if _, err := m.q.UpdateSLAEventAsBreached.Exec(event.ID); err != nil {
+			if _, err := m.q.UpdateSLAEventAsBreached.Exec(event.ID, m.incidentID(event.ConversationID, event.DeadlineAt)); err != nil {
 				m.lo.Error("error marking SLA event as breached", "error", err)
 				continue
 			}
+			m.triggerMetricWebhook(wmodels.EventSLABreached, event.ConversationID, event.ConversationUUID, event.AppliedSLAID, event.SlaPolicyID, event.Type, event.DeadlineAt, time.Now())
 		}
 
-		// Met at before the deadline - mark event met.
-		if event.MetAt.Valid && event.MetAt.Time.Before(event.DeadlineAt) {
+		// Met at before the deadline, or while paused - mark event met.
+		if event.MetAt.Valid && (paused || event.MetAt.Time.Before(event.DeadlineAt)) {
 			if _, err := m.q.UpdateSLAEventAsMet.Exec(event.ID); err != nil {
 				m.lo.Error("error marking SLA event as met", "error", err)
 				continue
 			}
+			m.triggerMetricWebhook(wmodels.EventSLAMet, event.ConversationID, event.ConversationUUID, event.AppliedSLAID, event.SlaPolicyID, event.Type, event.DeadlineAt, event.MetAt.Time)
 		}
 
 		// Schedule a breach notification if the event is not met at all and SLA breached.
@@ -443,9 +563,13 @@ func (m *Manager) evaluatePendingSLAEvents(ctx context.Context) error {
 				}
 				slaPolicyCache[event.SlaPolicyID] = slaPolicy
 			}
-			m.createNotificationSchedule(slaPolicy.Notifications, event.AppliedSLAID, null.IntFrom(event.ID), Deadlines{}, Breaches{
-				NextResponse: null.TimeFrom(time.Now()),
-			})
+			var breaches Breaches
+			if event.Type == MetricAssignment {
+				breaches.Assignment = null.TimeFrom(time.Now())
+			} else {
+				breaches.NextResponse = null.TimeFrom(time.Now())
+			}
+			m.createNotificationSchedule(slaPolicy.Notifications, event.AppliedSLAID, null.IntFrom(event.ID), Deadlines{}, breaches)
 		}
 	}
 	return nil
@@ -573,13 +697,13 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 				}
 				continue
 			}
-		case MetricNextResponse:
+		case MetricNextResponse, MetricAssignment:
 			if slaEvent.ID == 0 {
-				m.lo.Warn("next response SLA event not found", "scheduled_notification_id", scheduledNotification.ID)
-				return fmt.Errorf("next response SLA event not found for notification: %d", scheduledNotification.ID)
+				m.lo.Warn("SLA event not found", "metric", scheduledNotification.Metric, "scheduled_notification_id", scheduledNotification.ID)
+				return fmt.Errorf("%s SLA event not found for notification: %d", scheduledNotification.Metric, scheduledNotification.ID)
 			}
 			if slaEvent.MetAt.Valid {
-				m.lo.Info("skipping notification as next response is already met", "applied_sla_id", appliedSLA.ID)
+				m.lo.Info("skipping notification as SLA event is already met", "metric", scheduledNotification.Metric, "applied_sla_id", appliedSLA.ID)
 				if _, err := m.q.UpdateSLANotificationProcessed.Exec(scheduledNotification.ID); err != nil {
 					m.lo.Error("error marking notification as processed", "error", err)
 				}
@@ -648,7 +772,7 @@ func (m *Manager) SendNotification(scheduledNotification models.ScheduledSLANoti
 		case MetricResolution:
 			dueIn = getFriendlyDuration(appliedSLA.ResolutionDeadlineAt.Time)
 			overdueBy = getFriendlyDuration(appliedSLA.ResolutionBreachedAt.Time)
-		case MetricNextResponse:
+		case MetricNextResponse, MetricAssignment:
 			dueIn = getFriendlyDuration(slaEvent.DeadlineAt)
 			overdueBy = getFriendlyDuration(slaEvent.BreachedAt.Time)
 		default:
@@ -745,8 +869,8 @@ func (m *Manager) Close() error {
 	return nil
 }
 
-// getBusinessHoursAndTimezone returns the business hours ID and timezone for a team, falling back to app settings i.e. default helpdesk settings.
-func (m *Manager) getBusinessHoursAndTimezone(assignedTeamID int) (bmodels.BusinessHours, string, error) {
+// GetBusinessHoursAndTimezone returns the business hours ID and timezone for a team, falling back to app settings i.e. default helpdesk settings.
+func (m *Manager) GetBusinessHoursAndTimezone(assignedTeamID int) (bmodels.BusinessHours, string, error) {
 	var (
 		businessHrsID int
 		timezone      string
@@ -843,10 +967,12 @@ func (m *Manager) createNotificationSchedule(notifications models.SlaNotificatio
 			schedule(deadlines.FirstResponse, MetricFirstResponse)
 			schedule(deadlines.Resolution, MetricResolution)
 			schedule(deadlines.NextResponse, MetricNextResponse)
+			schedule(deadlines.Assignment, MetricAssignment)
 		case NotificationTypeBreach:
 			schedule(breaches.FirstResponse, MetricFirstResponse)
 			schedule(breaches.Resolution, MetricResolution)
 			schedule(breaches.NextResponse, MetricNextResponse)
+			schedule(breaches.Assignment, MetricAssignment)
 		}
 	}
 }
@@ -884,24 +1010,33 @@ func (m *Manager) evaluateSLA(appliedSLA models.AppliedSLA) error {
 
 		now := time.Now()
 		if !metAt.Valid && now.After(deadline) {
+			// The deadline is extended once the incident pausing the SLA ends.
+			if m.isPausedAt(appliedSLA.ConversationID, deadline) {
+				m.lo.Debug("SLA deadline passed during an ongoing incident, SLA paused", "deadline", deadline, "metric", metric)
+				return nil
+			}
 			m.lo.Debug("SLA breached as current time is after deadline", "deadline", deadline, "now", now, "metric", metric)
-			if err := m.handleSLABreach(appliedSLA.ID, appliedSLA.SLAPolicyID, metric); err != nil {
+			if err := m.handleSLABreach(appliedSLA.ID, appliedSLA.SLAPolicyID, metric, m.incidentID(appliedSLA.ConversationID, deadline)); err != nil {
 				return fmt.Errorf("updating SLA breach timestamp: %w", err)
 			}
+			m.triggerMetricWebhook(wmodels.EventSLABreached, appliedSLA.ConversationID, appliedSLA.ConversationUUID, appliedSLA.ID, appliedSLA.SLAPolicyID, metric, deadline, now)
 			return nil
 		}
 
 		if metAt.Valid {
-			if metAt.Time.After(deadline) {
+			// Met after the deadline while the SLA was paused by an ongoing incident is counted as met.
+			if metAt.Time.After(deadline) && !m.isPausedAt(appliedSLA.ConversationID, deadline) {
 				m.lo.Debug("SLA breached as met_at is after deadline", "deadline", deadline, "met_at", metAt.Time, "metric", metric)
-				if err := m.handleSLABreach(appliedSLA.ID, appliedSLA.SLAPolicyID, metric); err != nil {
+				if err := m.handleSLABreach(appliedSLA.ID, appliedSLA.SLAPolicyID, metric, m.incidentID(appliedSLA.ConversationID, deadline)); err != nil {
 					return fmt.Errorf("updating SLA breach: %w", err)
 				}
+				m.triggerMetricWebhook(wmodels.EventSLABreached, appliedSLA.ConversationID, appliedSLA.ConversationUUID, appliedSLA.ID, appliedSLA.SLAPolicyID, metric, deadline, metAt.Time)
 			} else {
 				m.lo.Debug("SLA type met", "deadline", deadline, "met_at", metAt.Time, "metric", metric)
 				if _, err := m.q.UpdateAppliedSLAMetAt.Exec(appliedSLA.ID, metric); err != nil {
 					return fmt.Errorf("updating SLA met: %w", err)
 				}
+				m.triggerMetricWebhook(wmodels.EventSLAMet, appliedSLA.ConversationID, appliedSLA.ConversationUUID, appliedSLA.ID, appliedSLA.SLAPolicyID, metric, deadline, metAt.Time)
 			}
 		}
 		return nil
@@ -937,9 +1072,9 @@ func (m *Manager) evaluateSLA(appliedSLA models.AppliedSLA) error {
 }
 
 // handleSLABreach processes a breach for the given SLA metric on an applied SLA.
-// It updates the breach timestamp and schedules breach notifications if applicable.
-func (m *Manager) handleSLABreach(appliedSLAID, slaPolicyID int, metric string) error {
-	if _, err := m.q.UpdateAppliedSLABreachedAt.Exec(appliedSLAID, metric); err != nil {
+// It updates the breach timestamp, attributing it to incidentID if set, and schedules breach notifications if applicable.
+func (m *Manager) handleSLABreach(appliedSLAID, slaPolicyID int, metric string, incidentID null.Int) error {
+	if _, err := m.q.UpdateAppliedSLABreachedAt.Exec(appliedSLAID, metric, incidentID); err != nil {
 		return err
 	}
 
@@ -965,3 +1100,21 @@ func (m *Manager) handleSLABreach(appliedSLAID, slaPolicyID int, metric string)
 
 	return nil
 }
+
+// triggerMetricWebhook triggers a met or breached webhook event for a single SLA metric.
+// The `at` time is when the metric was met, or when the breach was detected.
+func (m *Manager) triggerMetricWebhook(event wmodels.WebhookEvent, conversationID int, conversationUUID string, appliedSLAID, slaPolicyID int, metric string, deadline, at time.Time) {
+	timestampKey := "met_at"
+	if event == wmodels.EventSLABreached {
+		timestampKey = "breached_at"
+	}
+	m.webhookStore.TriggerEvent(event, map[string]any{
+		"conversation_id":   conversationID,
+		"conversation_uuid": conversationUUID,
+		"applied_sla_id":    appliedSLAID,
+		"sla_policy_id":     slaPolicyID,
+		"metric":            metric,
+		"deadline_at":       deadline,
+		timestampKey:        at,
+	})
+}
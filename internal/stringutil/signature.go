@@ -0,0 +1,68 @@
+package stringutil
+
+import (
+	"strings"
+)
+
+var (
+	// signatureLinePrefixes start the sign-off footers mail clients add, matched case-insensitively.
+	signatureLinePrefixes = []string{
+		"sent from my ",
+		"sent from mail for windows",
+		"sent from outlook",
+		"sent from yahoo mail",
+		"get outlook for ",
+	}
+
+	// disclaimerPrefixes start common legal disclaimers, matched case-insensitively.
+	disclaimerPrefixes = []string{
+		"confidentiality notice",
+		"disclaimer:",
+		"this email and any",
+		"this e-mail and any",
+		"this message and any",
+		"this email is confidential",
+		"this e-mail is confidential",
+		"this message is intended only",
+		"this email is intended only",
+		"this e-mail is intended only",
+		"the information contained in this",
+		"the information in this email",
+	}
+)
+
+// StripSignature removes the sender's signature and any legal disclaimer from the end of a plain
+// text email body. The signature starts at the "-- " delimiter line (RFC 3676) or at a mail
+// client footer like "Sent from my iPhone". The text is returned unchanged if nothing would remain.
+func StripSignature(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if i == 0 || !isSignatureStart(line) {
+			continue
+		}
+		if stripped := strings.TrimSpace(strings.Join(lines[:i], "\n")); stripped != "" {
+			return stripped
+		}
+		break
+	}
+	return text
+}
+
+// isSignatureStart reports whether the line starts a signature or a disclaimer.
+func isSignatureStart(line string) bool {
+	if strings.TrimRight(line, " ") == "--" {
+		return true
+	}
+	line = strings.ToLower(strings.TrimSpace(line))
+	for _, p := range signatureLinePrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	for _, p := range disclaimerPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package stringutil
+
+import "testing"
+
+func TestStripSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "delimiter",
+			in:   "Where is my refund?\n\n-- \nJane Doe\nAcme Inc.",
+			want: "Where is my refund?",
+		},
+		{
+			name: "delimiter without trailing space",
+			in:   "Thanks\r\n--\r\nJane",
+			want: "Thanks",
+		},
+		{
+			name: "mobile footer",
+			in:   "Sounds good.\n\nSent from my iPhone",
+			want: "Sounds good.",
+		},
+		{
+			name: "disclaimer",
+			in:   "Please call me back.\n\nJane\n\nCONFIDENTIALITY NOTICE: This email may contain privileged information.",
+			want: "Please call me back.\n\nJane",
+		},
+		{
+			name: "nothing left keeps the text",
+			in:   "Sent from my iPhone",
+			want: "Sent from my iPhone",
+		},
+		{
+			name: "signature only after blank lines keeps the text",
+			in:   "\n-- \nJane",
+			want: "\n-- \nJane",
+		},
+		{
+			name: "no signature",
+			in:   "I said -- twice -- in this line.",
+			want: "I said -- twice -- in this line.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripSignature(tt.in); got != tt.want {
+				t.Errorf("StripSignature(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
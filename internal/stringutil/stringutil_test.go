@@ -375,3 +375,24 @@ func TestExtractReferenceNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		ua      string
+		browser string
+		os      string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0", "Edge", "Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15", "Safari", "macOS"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0 Mobile/15E148 Safari/604.1", "Chrome", "iOS"},
+		{"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36", "Chrome", "Android"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox", "Linux"},
+		{"curl/8.4.0", "", ""},
+	}
+	for _, tt := range tests {
+		browser, os := ParseUserAgent(tt.ua)
+		if browser != tt.browser || os != tt.os {
+			t.Errorf("ParseUserAgent(%q) = %q, %q; want %q, %q", tt.ua, browser, os, tt.browser, tt.os)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package stringutil
+
+import "strings"
+
+// uaMatch maps a user agent token to a display name. Order matters as many user
+// agents carry the tokens of the engines they're built on, e.g. Edge includes "Chrome".
+type uaMatch struct {
+	token string
+	name  string
+}
+
+var (
+	uaBrowsers = []uaMatch{
+		{"Edg/", "Edge"},
+		{"EdgA/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	uaOSes = []uaMatch{
+		{"Windows", "Windows"},
+		{"iPhone", "iOS"},
+		{"iPad", "iOS"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Mac OS X", "macOS"},
+		{"Macintosh", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// ParseUserAgent returns the browser and operating system names of a User-Agent header.
+// Unrecognized values are returned as empty strings.
+func ParseUserAgent(ua string) (browser, os string) {
+	for _, m := range uaBrowsers {
+		if strings.Contains(ua, m.token) {
+			browser = m.name
+			break
+		}
+	}
+	for _, m := range uaOSes {
+		if strings.Contains(ua, m.token) {
+			os = m.name
+			break
+		}
+	}
+	return browser, os
+}
@@ -34,6 +34,13 @@ type TeamMember struct {
 	TeamID             int    `db:"team_id" json:"team_id"`
 }
 
+// TeamAgent is an agent in a team.
+type TeamAgent struct {
+	ID        int    `db:"id" json:"id"`
+	FirstName string `db:"first_name" json:"first_name"`
+	LastName  string `db:"last_name" json:"last_name"`
+}
+
 type TeamsCompact []TeamCompact
 
 func (t TeamsCompact) IDs() []int {
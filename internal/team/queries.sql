@@ -42,4 +42,28 @@ SELECT 1;
 DELETE FROM teams where id = $1;
 
 -- name: user-belongs-to-team
-SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2);
\ No newline at end of file
+SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2);
+-- name: get-team-agents
+-- All agents in a team, including disabled ones.
+SELECT u.id, u.first_name, u.last_name
+FROM users u
+JOIN team_members tm ON tm.user_id = u.id
+WHERE tm.team_id = $1 AND u.deleted_at IS NULL AND u.type = 'agent'
+ORDER BY u.id;
+
+-- name: add-team-members
+INSERT INTO team_members (team_id, user_id)
+SELECT $1, u.id FROM users u WHERE u.id = ANY($2::INT[]) AND u.type = 'agent' AND u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- name: remove-team-members
+DELETE FROM team_members WHERE team_id = $1 AND user_id = ANY($2::INT[]);
+
+-- name: set-team-members
+WITH delete_old_members AS (
+    DELETE FROM team_members
+    WHERE team_id = $1 AND user_id <> ALL($2::INT[])
+)
+INSERT INTO team_members (team_id, user_id)
+SELECT $1, u.id FROM users u WHERE u.id = ANY($2::INT[]) AND u.type = 'agent' AND u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
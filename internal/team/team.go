@@ -48,6 +48,10 @@ type queries struct {
 	GetTeamMembers    *sqlx.Stmt `query:"get-team-members"`
 	UpsertUserTeams   *sqlx.Stmt `query:"upsert-user-teams"`
 	UserBelongsToTeam *sqlx.Stmt `query:"user-belongs-to-team"`
+	GetTeamAgents     *sqlx.Stmt `query:"get-team-agents"`
+	AddTeamMembers    *sqlx.Stmt `query:"add-team-members"`
+	RemoveTeamMembers *sqlx.Stmt `query:"remove-team-members"`
+	SetTeamMembers    *sqlx.Stmt `query:"set-team-members"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -179,3 +183,40 @@ func (u *Manager) GetMembers(id int) ([]models.TeamMember, error) {
 	}
 	return members, nil
 }
+
+// GetAgents retrieves all agents of a team, including disabled ones.
+func (u *Manager) GetAgents(id int) ([]models.TeamAgent, error) {
+	var agents = make([]models.TeamAgent, 0)
+	if err := u.q.GetTeamAgents.Select(&agents, id); err != nil {
+		u.lo.Error("error fetching team agents", "team_id", id, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return agents, nil
+}
+
+// AddMembers adds agents to a team, IDs that are not agents are ignored.
+func (u *Manager) AddMembers(id int, userIDs []int) error {
+	if _, err := u.q.AddTeamMembers.Exec(id, pq.Array(userIDs)); err != nil {
+		u.lo.Error("error adding team members", "team_id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// RemoveMembers removes users from a team.
+func (u *Manager) RemoveMembers(id int, userIDs []int) error {
+	if _, err := u.q.RemoveTeamMembers.Exec(id, pq.Array(userIDs)); err != nil {
+		u.lo.Error("error removing team members", "team_id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// SetMembers replaces the members of a team with the given agents.
+func (u *Manager) SetMembers(id int, userIDs []int) error {
+	if _, err := u.q.SetTeamMembers.Exec(id, pq.Array(userIDs)); err != nil {
+		u.lo.Error("error setting team members", "team_id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package template
+
+import (
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// previewContent is the sample reply rendered in email template previews.
+const previewContent = `<p>Hi {{ contact.first_name }},</p>
+<p>Thanks for getting in touch. This is a preview of how replies from this inbox look to your contacts.</p>
+<p>{{ agent.full_name }}</p>`
+
+// previewData is the sample conversation data email template previews are rendered with.
+var previewData = map[string]any{
+	"Conversation": map[string]any{
+		"ReferenceNumber": "100",
+		"Subject":         "Question about my order",
+		"Priority":        "Medium",
+		"UUID":            "00000000-0000-4000-8000-000000000000",
+	},
+	"Contact": map[string]any{
+		"FirstName": "Jane",
+		"LastName":  "Doe",
+		"FullName":  "Jane Doe",
+		"Email":     "jane@example.com",
+	},
+	"Recipient": map[string]any{
+		"FirstName": "Jane",
+		"LastName":  "Doe",
+		"FullName":  "Jane Doe",
+		"Email":     "jane@example.com",
+	},
+	"Author": map[string]any{
+		"FirstName": "Alex",
+		"LastName":  "Smith",
+		"FullName":  "Alex Smith",
+		"Email":     "alex@example.com",
+	},
+	"IsContinuityEmail": false,
+}
+
+// PreviewEmailTemplate renders a sample reply in the outgoing email template with the given id,
+// or in the default outgoing email template if id is 0.
+func (m *Manager) PreviewEmailTemplate(id int) (string, error) {
+	if id > 0 {
+		tmpl, err := m.Get(id)
+		if err != nil {
+			return "", err
+		}
+		if tmpl.Type != TypeEmailOutgoing {
+			return "", envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+	}
+	html, err := m.RenderEmailWithTemplate(previewData, previewContent, id)
+	if err != nil {
+		m.lo.Error("error rendering email template preview", "id", id, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return html, nil
+}
@@ -34,4 +34,7 @@ DELETE FROM templates WHERE id = $1;
 SELECT id, created_at, updated_at, type, body, is_default, name, subject, is_builtin FROM templates WHERE name = $1;
 
 -- name: is-builtin
-SELECT EXISTS(SELECT 1 FROM templates WHERE id = $1 AND is_builtin is TRUE);
\ No newline at end of file
+SELECT EXISTS(SELECT 1 FROM templates WHERE id = $1 AND is_builtin is TRUE);
+
+-- name: get-outgoing-template
+SELECT id, created_at, updated_at, type, body, is_default, name, subject, is_builtin FROM templates WHERE id = $1 AND type = 'email_outgoing';
\ No newline at end of file
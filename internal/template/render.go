@@ -3,8 +3,6 @@ package template
 import (
 	"bytes"
 	"fmt"
-	"strings"
-	"text/template"
 
 	"github.com/valyala/fasthttp"
 )
@@ -16,6 +14,7 @@ const (
 	TmplSLABreached          = "SLA breached"
 	TmplMentioned            = "Mentioned in conversation"
 	TmplCSATRequest          = "CSAT request"
+	TmplTranscript           = "Conversation transcript"
 
 	// Built-in templates fetched from memory stored in `static` directory.
 	TmplResetPassword = "reset-password"
@@ -24,20 +23,28 @@ const (
 	// Template names for rendering.
 	TmplBase    = "base"
 	TmplContent = "content"
+	TmplSubject = "subject"
 )
 
-// RenderString renders Go template variables in the given content string
+// RenderString renders Go template variables and registry variables in the given content string
 // without wrapping it in the base email template. Returns original content on any error.
 func (m *Manager) RenderString(data any, content string) string {
-	t, err := template.New("content").Funcs(m.funcMap).Parse(content)
+	t, err := m.parseContent(TmplContent, content)
 	if err != nil {
 		return content
 	}
-	var buf strings.Builder
-	if err := t.Execute(&buf, data); err != nil {
+	rendered, err := execute(t, TmplContent, data)
+	if err != nil {
 		return content
 	}
-	return buf.String()
+	return rendered
+}
+
+// ValidateString parses content as a template, returning an error if it isn't valid or uses constructs
+// templates aren't allowed to use.
+func (m *Manager) ValidateString(content string) error {
+	_, err := m.parseContent(TmplContent, content)
+	return err
 }
 
 // RenderStoredTemplate fetches a template by name and renders its body with the provided data
@@ -50,26 +57,27 @@ func (m *Manager) RenderStoredTemplate(name string, data any) (string, error) {
 	return m.RenderString(data, tmpl.Body), nil
 }
 
-// RenderEmailWithTemplate renders content inside the default outgoing email template.
-func (m *Manager) RenderEmailWithTemplate(data any, content string) (string, error) {
+// RenderEmailWithTemplate renders content inside the outgoing email template with the given id,
+// the default outgoing email template is used if templateID is 0 or the template doesn't exist.
+func (m *Manager) RenderEmailWithTemplate(data any, content string, templateID int) (string, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	defaultTmpl, err := m.getDefaultOutgoingEmailTemplate()
+	defaultTmpl, err := m.getOutgoingEmailTemplate(templateID)
 	if err != nil {
-		m.lo.Error("error fetching default outgoing email template", "error", err)
+		m.lo.Error("error fetching outgoing email template", "id", templateID, "error", err)
 	}
 
 	if defaultTmpl.Body == "" {
 		defaultTmpl.Body = `{{ template "content" . }}`
 	}
 
-	baseTemplate, err := template.New(TmplBase).Funcs(m.funcMap).Parse(defaultTmpl.Body)
+	baseTemplate, err := m.parseContent(TmplBase, defaultTmpl.Body, TmplContent)
 	if err != nil {
 		return "", fmt.Errorf("parsing base template: %w", err)
 	}
 
-	contentTemplate, err := template.New(TmplContent).Funcs(m.funcMap).Parse(content)
+	contentTemplate, err := m.parseContent(TmplContent, content)
 	if err != nil {
 		return "", fmt.Errorf("parsing content template: %w", err)
 	}
@@ -79,12 +87,12 @@ func (m *Manager) RenderEmailWithTemplate(data any, content string) (string, err
 		return "", fmt.Errorf("adding content template: %w", err)
 	}
 
-	var rendered strings.Builder
-	if err := baseTemplate.ExecuteTemplate(&rendered, TmplBase, data); err != nil {
+	rendered, err := execute(baseTemplate, TmplBase, data)
+	if err != nil {
 		return "", fmt.Errorf("executing base template: %w", err)
 	}
 
-	return rendered.String(), nil
+	return rendered, nil
 }
 
 // RenderStoredEmailTemplate fetches and renders an email template from the database, including subject and body and returns the rendered content.
@@ -98,15 +106,15 @@ func (m *Manager) RenderStoredEmailTemplate(name string, data any) (string, stri
 	}
 
 	executeSubjectTemplate := func(subject string) (string, error) {
-		var sb strings.Builder
-		subjectTmpl, err := template.New("subject").Funcs(m.funcMap).Parse(subject)
+		subjectTmpl, err := m.parseContent(TmplSubject, subject)
 		if err != nil {
 			return "", fmt.Errorf("parsing subject template: %w", err)
 		}
-		if err := subjectTmpl.Execute(&sb, data); err != nil {
+		rendered, err := execute(subjectTmpl, TmplSubject, data)
+		if err != nil {
 			return "", fmt.Errorf("executing subject template: %w", err)
 		}
-		return sb.String(), nil
+		return rendered, nil
 	}
 
 	defaultTmpl, err := m.getDefaultOutgoingEmailTemplate()
@@ -118,12 +126,12 @@ func (m *Manager) RenderStoredEmailTemplate(name string, data any) (string, stri
 		defaultTmpl.Body = `{{ template "content" . }}`
 	}
 
-	baseTemplate, err := template.New(TmplBase).Funcs(m.funcMap).Parse(defaultTmpl.Body)
+	baseTemplate, err := m.parseContent(TmplBase, defaultTmpl.Body, TmplContent)
 	if err != nil {
 		return "", "", fmt.Errorf("parsing base template: %w", err)
 	}
 
-	contentTemplate, err := template.New(TmplContent).Funcs(m.funcMap).Parse(tmpl.Body)
+	contentTemplate, err := m.parseContent(TmplContent, tmpl.Body)
 	if err != nil {
 		return "", "", fmt.Errorf("parsing content template: %w", err)
 	}
@@ -133,8 +141,8 @@ func (m *Manager) RenderStoredEmailTemplate(name string, data any) (string, stri
 		return "", "", fmt.Errorf("adding content template: %w", err)
 	}
 
-	var rendered strings.Builder
-	if err := baseTemplate.ExecuteTemplate(&rendered, TmplBase, data); err != nil {
+	rendered, err := execute(baseTemplate, TmplBase, data)
+	if err != nil {
 		return "", "", fmt.Errorf("executing base template: %w", err)
 	}
 
@@ -143,7 +151,7 @@ func (m *Manager) RenderStoredEmailTemplate(name string, data any) (string, stri
 		return "", "", err
 	}
 
-	return rendered.String(), subject, nil
+	return rendered, subject, nil
 }
 
 // RenderInMemoryTemplate executes an in-memory template with data and returns the rendered content.
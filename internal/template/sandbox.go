@@ -0,0 +1,242 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template/models"
+)
+
+const (
+	// maxRangeCount is the largest integer a template can range over.
+	maxRangeCount = 1000
+
+	// maxRangeSteps is the most range iterations, nested or not, rendering a template can run.
+	maxRangeSteps = 100000
+
+	// rangeStepFunc is the function the sandbox calls at the start of every range iteration to count it.
+	rangeStepFunc = "_rangeStep"
+
+	// maxRenderedSize is the largest output in bytes rendering a template can produce.
+	maxRenderedSize = 5 * 1024 * 1024
+)
+
+var (
+	errOutputTooLarge = errors.New("rendered template is too large")
+	errTooManySteps   = fmt.Errorf("more than %d range iterations are not allowed", maxRangeSteps)
+
+	// reTemplateError matches the position and message of text/template parse and execution errors,
+	// e.g. `template: content:3:14: executing "content" at <.Foo>: ...`.
+	reTemplateError = regexp.MustCompile(`^template: [^:]*:(\d+)(?::(\d+))?: (.*)$`)
+)
+
+// SyntaxError is an error in a template with its position, the column is 0 if unknown.
+type SyntaxError struct {
+	Field   string `json:"field"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (e SyntaxError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// parseContent parses content as a template with the given name, expanding registry variables and
+// rejecting constructs templates aren't allowed to use: template definitions, the call builtin,
+// including templates other than the ones in allowedIncludes and ranging over large integer literals.
+// Range iterations are counted when the template is executed, see execute.
+func (m *Manager) parseContent(name, content string, allowedIncludes ...string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(m.funcMap).Parse(expandVariables(content))
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Templates()) > 1 {
+		return nil, fmt.Errorf("template: %s:1: template definitions are not allowed", name)
+	}
+	if t.Tree == nil || t.Root == nil {
+		return t, nil
+	}
+	if err := checkNode(t.Tree, t.Root, allowedIncludes); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkNode walks the parse tree below node and returns an error for constructs templates aren't allowed to use.
+// The body of every range is prefixed with a call to rangeStepFunc so that iterations can be counted.
+func checkNode(tree *parse.Tree, node parse.Node, allowedIncludes []string) error {
+	fail := func(n parse.Node, format string, args ...any) error {
+		location, _ := tree.ErrorContext(n)
+		return fmt.Errorf("template: %s: %s", location, fmt.Sprintf(format, args...))
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := checkNode(tree, c, allowedIncludes); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkNode(tree, n.Pipe, allowedIncludes)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := checkNode(tree, c, allowedIncludes); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if id, ok := arg.(*parse.IdentifierNode); ok && id.Ident == "call" {
+				return fail(n, "call is not allowed")
+			}
+			if err := checkNode(tree, arg, allowedIncludes); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return checkBranch(tree, &n.BranchNode, allowedIncludes)
+	case *parse.WithNode:
+		return checkBranch(tree, &n.BranchNode, allowedIncludes)
+	case *parse.RangeNode:
+		if n.Pipe != nil && len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 {
+			if num, ok := n.Pipe.Cmds[0].Args[0].(*parse.NumberNode); ok && (!num.IsInt || num.Int64 > maxRangeCount) {
+				return fail(n, "range over more than %d items is not allowed", maxRangeCount)
+			}
+		}
+		if n.List == nil {
+			n.List = &parse.ListNode{NodeType: parse.NodeList, Pos: n.Pos}
+		}
+		n.List.Nodes = append([]parse.Node{rangeStepNode(tree, n.Pos, n.Line)}, n.List.Nodes...)
+		return checkBranch(tree, &n.BranchNode, allowedIncludes)
+	case *parse.TemplateNode:
+		allowed := false
+		for _, name := range allowedIncludes {
+			if n.Name == name {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return fail(n, "including template %q is not allowed", n.Name)
+		}
+		return checkNode(tree, n.Pipe, allowedIncludes)
+	}
+	return nil
+}
+
+// rangeStepNode returns an action calling rangeStepFunc, which renders nothing.
+func rangeStepNode(tree *parse.Tree, pos parse.Pos, line int) *parse.ActionNode {
+	fn := parse.NewIdentifier(rangeStepFunc).SetTree(tree).SetPos(pos)
+	return &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      pos,
+		Line:     line,
+		Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe,
+			Pos:      pos,
+			Line:     line,
+			Cmds:     []*parse.CommandNode{{NodeType: parse.NodeCommand, Pos: pos, Args: []parse.Node{fn}}},
+		},
+	}
+}
+
+func checkBranch(tree *parse.Tree, n *parse.BranchNode, allowedIncludes []string) error {
+	if err := checkNode(tree, n.Pipe, allowedIncludes); err != nil {
+		return err
+	}
+	if err := checkNode(tree, n.List, allowedIncludes); err != nil {
+		return err
+	}
+	return checkNode(tree, n.ElseList, allowedIncludes)
+}
+
+// validate parses the body and subject of a template and renders outgoing email templates with
+// sample data, returning an input error with the position of the first problem found.
+func (m *Manager) validate(t models.Template) error {
+	var (
+		field = "body"
+		err   error
+	)
+	if t.Type == TypeEmailOutgoing {
+		var base *template.Template
+		if base, err = m.parseContent(TmplBase, t.Body, TmplContent); err == nil {
+			content, _ := m.parseContent(TmplContent, previewContent)
+			if base, err = base.AddParseTree(TmplContent, content.Tree); err == nil {
+				_, err = execute(base, TmplBase, previewData)
+			}
+		}
+	} else if _, err = m.parseContent(TmplContent, t.Body); err == nil {
+		field = "subject"
+		_, err = m.parseContent(TmplSubject, t.Subject.String)
+	}
+	if err == nil {
+		return nil
+	}
+	se := toSyntaxError(field, err)
+	return envelope.NewError(envelope.InputError, m.i18n.Ts("template.invalidSyntax", "field", field, "error", se.Error()), se)
+}
+
+// limitedWriter fails writes once more than n bytes have been written in total.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n {
+		return 0, errOutputTooLarge
+	}
+	l.n -= len(p)
+	return l.w.Write(p)
+}
+
+// execute executes the named template of t with data, failing if the output grows beyond maxRenderedSize
+// or the ranges of the template iterate more than maxRangeSteps times in total.
+func execute(t *template.Template, name string, data any) (string, error) {
+	steps := 0
+	t.Funcs(template.FuncMap{rangeStepFunc: func() (string, error) {
+		if steps++; steps > maxRangeSteps {
+			return "", errTooManySteps
+		}
+		return "", nil
+	}})
+
+	var sb strings.Builder
+	if err := t.ExecuteTemplate(&limitedWriter{w: &sb, n: maxRenderedSize}, name, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// toSyntaxError converts a text/template error into a SyntaxError for the given field.
+func toSyntaxError(field string, err error) SyntaxError {
+	msg := err.Error()
+	// Execution errors wrap the template error.
+	if i := strings.Index(msg, "template: "); i > 0 {
+		msg = msg[i:]
+	}
+	se := SyntaxError{Field: field, Line: 1, Message: msg}
+	if match := reTemplateError.FindStringSubmatch(msg); match != nil {
+		se.Line, _ = strconv.Atoi(match[1])
+		se.Column, _ = strconv.Atoi(match[2])
+		se.Message = match[3]
+	}
+	return se
+}
@@ -0,0 +1,69 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseContent(t *testing.T) {
+	m := &Manager{}
+	tests := []struct {
+		content string
+		line    int
+		ok      bool
+	}{
+		{"{{ if contact.first_name }}Hi {{ contact.first_name }}{{ else }}Hi there{{ end }}", 0, true},
+		{"{{ range .Items }}{{ .Name }}{{ end }}", 0, true},
+		{"{{ range 10 }}.{{ end }}", 0, true},
+		{"Hi\n{{ range 100000 }}.{{ end }}", 2, false},
+		{"{{ define \"x\" }}{{ end }}", 1, false},
+		{"\n\n{{ call .Func }}", 3, false},
+		{"{{ template \"base\" . }}", 1, false},
+		{"Hi\n{{ if }}", 2, false},
+	}
+	for _, tt := range tests {
+		_, err := m.parseContent(TmplContent, tt.content)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseContent(%q) error = %v, want ok %v", tt.content, err, tt.ok)
+			continue
+		}
+		if err != nil {
+			if se := toSyntaxError("body", err); se.Line != tt.line {
+				t.Errorf("parseContent(%q) error line = %d, want %d (%v)", tt.content, se.Line, tt.line, err)
+			}
+		}
+	}
+}
+
+func TestExecuteRangeSteps(t *testing.T) {
+	m := &Manager{}
+	items := make([]int, maxRangeSteps+1)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		ok      bool
+	}{
+		{"literal", "{{ range 3 }}.{{ end }}", "...", true},
+		{"nested within limit", "{{ range 10 }}{{ range 10 }}.{{ end }}{{ end }}", strings.Repeat(".", 100), true},
+		{"else", "{{ range .Empty }}.{{ else }}none{{ end }}", "none", true},
+		{"nested", "{{ range 1000 }}{{ range 1000 }}{{ end }}{{ end }}", "", false},
+		{"variable", "{{ $n := 100000000 }}{{ range $n }}{{ end }}", "", false},
+		{"large data", "{{ range .Items }}{{ end }}", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := m.parseContent(TmplContent, tt.content)
+			if err != nil {
+				t.Fatalf("parseContent() error = %v", err)
+			}
+			got, err := execute(tmpl, TmplContent, map[string]any{"Items": items, "Empty": []int{}})
+			if (err == nil) != tt.ok {
+				t.Fatalf("execute() error = %v, want ok %v", err, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -38,14 +38,15 @@ type Manager struct {
 
 // queries contains prepared SQL queries.
 type queries struct {
-	InsertTemplate     *sqlx.Stmt `query:"insert"`
-	UpdateTemplate     *sqlx.Stmt `query:"update"`
-	DeleteTemplate     *sqlx.Stmt `query:"delete"`
-	GetDefaultTemplate *sqlx.Stmt `query:"get-default"`
-	GetAllTemplates    *sqlx.Stmt `query:"get-all"`
-	GetTemplate        *sqlx.Stmt `query:"get-template"`
-	GetByName          *sqlx.Stmt `query:"get-by-name"`
-	IsBuiltIn          *sqlx.Stmt `query:"is-builtin"`
+	InsertTemplate      *sqlx.Stmt `query:"insert"`
+	UpdateTemplate      *sqlx.Stmt `query:"update"`
+	DeleteTemplate      *sqlx.Stmt `query:"delete"`
+	GetDefaultTemplate  *sqlx.Stmt `query:"get-default"`
+	GetAllTemplates     *sqlx.Stmt `query:"get-all"`
+	GetTemplate         *sqlx.Stmt `query:"get-template"`
+	GetByName           *sqlx.Stmt `query:"get-by-name"`
+	IsBuiltIn           *sqlx.Stmt `query:"is-builtin"`
+	GetOutgoingTemplate *sqlx.Stmt `query:"get-outgoing-template"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -67,6 +68,9 @@ func New(lo *logf.Logger, db *sqlx.DB, webTpls *template.Template, tpls *templat
 
 // Update updates a new template with the given name, and body.
 func (m *Manager) Update(id int, t models.Template) (models.Template, error) {
+	if err := m.validate(t); err != nil {
+		return models.Template{}, err
+	}
 	var result models.Template
 	if err := m.q.UpdateTemplate.Get(&result, id, t.Name, t.Body, t.IsDefault, t.Subject, t.Type); err != nil {
 		m.lo.Error("error updating template", "error", err)
@@ -80,6 +84,9 @@ func (m *Manager) Create(t models.Template) (models.Template, error) {
 	if t.IsDefault {
 		t.Type = TypeEmailOutgoing
 	}
+	if err := m.validate(t); err != nil {
+		return models.Template{}, err
+	}
 	var result models.Template
 	if err := m.q.InsertTemplate.Get(&result, t.Name, t.Body, t.IsDefault, t.Subject, t.Type); err != nil {
 		if dbutil.IsUniqueViolationError(err) && t.IsDefault {
@@ -154,6 +161,23 @@ func (m *Manager) getDefaultOutgoingEmailTemplate() (models.Template, error) {
 	return template, nil
 }
 
+// getOutgoingEmailTemplate returns the outgoing email template with the given id, or the default
+// outgoing email template if id is 0 or there's no outgoing template with the id.
+func (m *Manager) getOutgoingEmailTemplate(id int) (models.Template, error) {
+	if id > 0 {
+		var template models.Template
+		err := m.q.GetOutgoingTemplate.Get(&template, id)
+		if err == nil {
+			return template, nil
+		}
+		if err != sql.ErrNoRows {
+			m.lo.Error("error fetching outgoing template", "id", id, "error", err)
+			return template, fmt.Errorf("error fetching outgoing template(%d): %w", id, err)
+		}
+	}
+	return m.getDefaultOutgoingEmailTemplate()
+}
+
 // getByName returns a template by name.
 func (m *Manager) getByName(name string) (models.Template, error) {
 	var template models.Template
@@ -0,0 +1,91 @@
+package template
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Variable is a template variable available in content rendered with conversation data, e.g. replies,
+// macros, automated replies and the outgoing email template.
+type Variable struct {
+	// Name is the short form used in content, e.g. "contact.first_name" for {{ contact.first_name }}.
+	Name string `json:"name"`
+	// Path is the Go template field the variable expands to, e.g. ".Contact.FirstName".
+	Path string `json:"path"`
+}
+
+// Variables is the registry of template variables. The Go template fields can be used directly as well.
+var Variables = []Variable{
+	{Name: "contact.first_name", Path: ".Contact.FirstName"},
+	{Name: "contact.last_name", Path: ".Contact.LastName"},
+	{Name: "contact.full_name", Path: ".Contact.FullName"},
+	{Name: "contact.email", Path: ".Contact.Email"},
+	{Name: "conversation.reference_number", Path: ".Conversation.ReferenceNumber"},
+	{Name: "conversation.subject", Path: ".Conversation.Subject"},
+	{Name: "conversation.priority", Path: ".Conversation.Priority"},
+	{Name: "conversation.uuid", Path: ".Conversation.UUID"},
+	{Name: "agent.first_name", Path: ".Author.FirstName"},
+	{Name: "agent.last_name", Path: ".Author.LastName"},
+	{Name: "agent.full_name", Path: ".Author.FullName"},
+	{Name: "agent.email", Path: ".Author.Email"},
+}
+
+var (
+	reVariable   = regexp.MustCompile(`\{\{\s*([a-z_]+\.[a-z_]+)\s*\}\}`)
+	reAction     = regexp.MustCompile(`(?s)\{\{(.*?)\}\}`)
+	reName       = regexp.MustCompile(`[a-z_]+\.[a-z_]+`)
+	variablePath = func() map[string]string {
+		paths := make(map[string]string, len(Variables))
+		for _, v := range Variables {
+			paths[v.Name] = v.Path
+		}
+		return paths
+	}()
+)
+
+// expandVariables rewrites registry variables like {{ contact.first_name }} in content into their
+// Go template fields, unknown names are left as they are. Variables can also be used as arguments
+// of other actions, e.g. {{ if contact.first_name }}.
+func expandVariables(content string) string {
+	content = reVariable.ReplaceAllStringFunc(content, func(match string) string {
+		name := reVariable.FindStringSubmatch(match)[1]
+		if path, ok := variablePath[name]; ok {
+			return "{{ " + path + " }}"
+		}
+		return match
+	})
+	return reAction.ReplaceAllStringFunc(content, func(action string) string {
+		return "{{" + expandActionVariables(action[2:len(action)-2]) + "}}"
+	})
+}
+
+// expandActionVariables rewrites registry variables used as arguments in the body of an action,
+// leaving string literals alone.
+func expandActionVariables(action string) string {
+	parts := strings.Split(action, `"`)
+	for i := 0; i < len(parts); i += 2 {
+		part := parts[i]
+		var b strings.Builder
+		last := 0
+		for _, loc := range reName.FindAllStringIndex(part, -1) {
+			path, ok := variablePath[part[loc[0]:loc[1]]]
+			if !ok || !isArgBoundary(part, loc[0]-1) || !isArgBoundary(part, loc[1]) {
+				continue
+			}
+			b.WriteString(part[last:loc[0]])
+			b.WriteString(path)
+			last = loc[1]
+		}
+		b.WriteString(part[last:])
+		parts[i] = b.String()
+	}
+	return strings.Join(parts, `"`)
+}
+
+// isArgBoundary reports whether the byte at i in s can border an action argument.
+func isArgBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	return strings.IndexByte(" \t\r\n()|-", s[i]) >= 0
+}
@@ -0,0 +1,22 @@
+package template
+
+import "testing"
+
+func TestExpandVariables(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hi {{ contact.first_name }},", "Hi {{ .Contact.FirstName }},"},
+		{"{{agent.full_name}} on #{{ conversation.reference_number }}", "{{ .Author.FullName }} on #{{ .Conversation.ReferenceNumber }}"},
+		{"{{ order.number }}", "{{ order.number }}"},
+		{"{{ .Contact.Email }}", "{{ .Contact.Email }}"},
+		{"{{ if contact.first_name }}Hi{{ end }}", "{{ if .Contact.FirstName }}Hi{{ end }}"},
+		{`{{- if eq conversation.priority "contact.email" -}}`, `{{- if eq .Conversation.Priority "contact.email" -}}`},
+		{"{{ if .order.number }}", "{{ if .order.number }}"},
+	}
+	for _, tt := range tests {
+		if got := expandVariables(tt.in); got != tt.want {
+			t.Errorf("expandVariables(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
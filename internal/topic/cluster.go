@@ -0,0 +1,74 @@
+package topic
+
+import "math"
+
+// normalize returns v scaled to unit length.
+func normalize(v []float64) []float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	out := make([]float64, len(v))
+	if sum == 0 {
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// dot returns the dot product of a and b, which is their cosine similarity for unit vectors.
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// cluster greedily groups unit vectors, adding each to the most similar existing cluster
+// whose centroid similarity is at least threshold, or starting a new cluster otherwise.
+// It returns the member indexes of each cluster.
+func cluster(vectors [][]float64, threshold float64) [][]int {
+	var (
+		clusters  [][]int
+		sums      [][]float64
+		centroids [][]float64
+	)
+	for i, v := range vectors {
+		best, bestSim := -1, threshold
+		for c, centroid := range centroids {
+			if sim := dot(v, centroid); sim >= bestSim {
+				best, bestSim = c, sim
+			}
+		}
+		if best < 0 {
+			clusters = append(clusters, []int{i})
+			sums = append(sums, append([]float64(nil), v...))
+			centroids = append(centroids, v)
+			continue
+		}
+		clusters[best] = append(clusters[best], i)
+		for j := range sums[best] {
+			sums[best][j] += v[j]
+		}
+		centroids[best] = normalize(sums[best])
+	}
+	return clusters
+}
+
+// centroid returns the unit length mean of the vectors at the given indexes.
+func centroid(vectors [][]float64, members []int) []float64 {
+	if len(members) == 0 {
+		return nil
+	}
+	sum := make([]float64, len(vectors[members[0]]))
+	for _, i := range members {
+		for j, x := range vectors[i] {
+			sum[j] += x
+		}
+	}
+	return normalize(sum)
+}
@@ -0,0 +1,34 @@
+package topic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCluster(t *testing.T) {
+	vectors := [][]float64{
+		normalize([]float64{1, 0, 0}),
+		normalize([]float64{0, 1, 0}),
+		normalize([]float64{0.95, 0.05, 0}),
+		normalize([]float64{0.05, 0.95, 0.05}),
+		normalize([]float64{0, 0, 1}),
+		normalize([]float64{0.9, 0.1, 0}),
+	}
+
+	clusters := cluster(vectors, 0.9)
+	assert.Equal(t, [][]int{{0, 2, 5}, {1, 3}, {4}}, clusters)
+
+	// Nothing is similar enough at a threshold of 1 except identical vectors.
+	assert.Len(t, cluster(vectors, 1), len(vectors))
+	assert.Empty(t, cluster(nil, 0.9))
+}
+
+func TestCentroid(t *testing.T) {
+	vectors := [][]float64{{1, 0}, {0, 1}}
+	c := centroid(vectors, []int{0, 1})
+	assert.InDelta(t, 0.7071, c[0], 0.0001)
+	assert.InDelta(t, 0.7071, c[1], 0.0001)
+	assert.InDelta(t, 1, dot(c, c), 0.0001)
+	assert.Nil(t, centroid(vectors, nil))
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Topic is a group of recent conversations about the same theme found by clustering their embeddings.
+type Topic struct {
+	ID                int64     `db:"id" json:"id"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	PeriodStart       time.Time `db:"period_start" json:"period_start"`
+	PeriodEnd         time.Time `db:"period_end" json:"period_end"`
+	Label             string    `db:"label" json:"label"`
+	ConversationCount int       `db:"conversation_count" json:"conversation_count"`
+	// Conversations about the topic in the preceding period of the same length.
+	PreviousCount           int            `db:"previous_count" json:"previous_count"`
+	SampleConversationUUIDs pq.StringArray `db:"sample_conversation_uuids" json:"sample_conversation_uuids"`
+}
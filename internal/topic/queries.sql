@@ -0,0 +1,79 @@
+-- name: get-conversations-without-embeddings
+-- Conversations created since $1 with a subject or an incoming message that are not embedded yet.
+SELECT
+    c.id,
+    COALESCE(c.subject, '') AS subject,
+    COALESCE(LEFT(m.text_content, 2000), '') AS text_content
+FROM conversations c
+LEFT JOIN LATERAL (
+    SELECT text_content
+    FROM conversation_messages
+    WHERE conversation_id = c.id AND type = 'incoming' AND private = FALSE
+    ORDER BY id
+    LIMIT 1
+) m ON TRUE
+WHERE
+    c.created_at >= $1
+    AND NOT EXISTS (SELECT 1 FROM conversation_embeddings e WHERE e.conversation_id = c.id)
+    AND (COALESCE(c.subject, '') <> '' OR COALESCE(m.text_content, '') <> '')
+ORDER BY c.id
+LIMIT $2;
+
+-- name: insert-embedding
+INSERT INTO conversation_embeddings (conversation_id, embedding)
+VALUES ($1, $2)
+ON CONFLICT (conversation_id) DO NOTHING;
+
+-- name: get-embeddings
+-- Embeddings of conversations created since $1.
+SELECT
+    e.conversation_id,
+    c.created_at,
+    e.embedding
+FROM conversation_embeddings e
+INNER JOIN conversations c ON c.id = e.conversation_id
+WHERE c.created_at >= $1
+ORDER BY c.created_at;
+
+-- name: get-topic-samples
+-- Subjects and opening messages of the given conversations, used to label a topic.
+SELECT
+    COALESCE(c.subject, '') AS subject,
+    COALESCE(LEFT(m.text_content, 300), '') AS text_content
+FROM conversations c
+LEFT JOIN LATERAL (
+    SELECT text_content
+    FROM conversation_messages
+    WHERE conversation_id = c.id AND type = 'incoming' AND private = FALSE
+    ORDER BY id
+    LIMIT 1
+) m ON TRUE
+WHERE c.id = ANY($1::BIGINT[])
+LIMIT 10;
+
+-- name: insert-topic
+INSERT INTO conversation_topics (period_start, period_end, label, conversation_count, previous_count, conversation_ids)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: delete-old-topics
+DELETE FROM conversation_topics WHERE period_end < $1;
+
+-- name: get-latest-topics
+SELECT
+    t.id,
+    t.created_at,
+    t.period_start,
+    t.period_end,
+    t.label,
+    t.conversation_count,
+    t.previous_count,
+    ARRAY(
+        SELECT c.uuid::TEXT
+        FROM conversations c
+        WHERE c.id = ANY(t.conversation_ids)
+        ORDER BY c.created_at DESC
+        LIMIT 10
+    ) AS sample_conversation_uuids
+FROM conversation_topics t
+WHERE t.period_end = (SELECT MAX(period_end) FROM conversation_topics)
+ORDER BY t.conversation_count DESC, t.id;
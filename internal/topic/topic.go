@@ -0,0 +1,271 @@
+// Package topic periodically clusters recent conversations by topic using embeddings
+// to surface emerging themes in reports.
+package topic
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/topic/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/lib/pq"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// embedBatchSize is the number of conversations embedded per provider request.
+	embedBatchSize = 100
+	// maxEmbedBatches caps the provider requests made per run, the rest are embedded in later runs.
+	maxEmbedBatches = 20
+	// maxTopics is the number of largest topics stored per run.
+	maxTopics = 20
+	// topicRetention is how long past runs are kept.
+	topicRetention = 30 * 24 * time.Hour
+
+	labelSystemPrompt = "You are given customer support conversations that are about the same topic. " +
+		"Reply with a short label of at most six words describing the topic, without quotes or punctuation at the end."
+)
+
+type aiStore interface {
+	Embed(inputs []string) ([][]float64, error)
+	CompletionWithSystemPrompt(systemPrompt, prompt string) (string, error)
+}
+
+// Manager clusters conversations into topics.
+type Manager struct {
+	q                   queries
+	db                  *sqlx.DB
+	lo                  *logf.Logger
+	i18n                *i18n.I18n
+	ai                  aiStore
+	window              time.Duration
+	minClusterSize      int
+	similarityThreshold float64
+}
+
+// Opts contains options for initializing the topic Manager.
+type Opts struct {
+	DB   *sqlx.DB
+	Lo   *logf.Logger
+	I18n *i18n.I18n
+	AI   aiStore
+	// Window is the period of conversations clustered on each run.
+	Window time.Duration
+	// MinClusterSize is the number of conversations a cluster needs to be reported as a topic.
+	MinClusterSize int
+	// SimilarityThreshold is the cosine similarity, between 0 and 1, for a conversation to join a topic.
+	SimilarityThreshold float64
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetConversationsWithoutEmbeddings *sqlx.Stmt `query:"get-conversations-without-embeddings"`
+	InsertEmbedding                   *sqlx.Stmt `query:"insert-embedding"`
+	GetEmbeddings                     *sqlx.Stmt `query:"get-embeddings"`
+	GetTopicSamples                   *sqlx.Stmt `query:"get-topic-samples"`
+	InsertTopic                       *sqlx.Stmt `query:"insert-topic"`
+	DeleteOldTopics                   *sqlx.Stmt `query:"delete-old-topics"`
+	GetLatestTopics                   *sqlx.Stmt `query:"get-latest-topics"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:                   q,
+		db:                  opts.DB,
+		lo:                  opts.Lo,
+		i18n:                opts.I18n,
+		ai:                  opts.AI,
+		window:              opts.Window,
+		minClusterSize:      opts.MinClusterSize,
+		similarityThreshold: opts.SimilarityThreshold,
+	}, nil
+}
+
+// GetLatest returns the topics found by the latest run, largest first.
+func (m *Manager) GetLatest() ([]models.Topic, error) {
+	var topics = make([]models.Topic, 0)
+	if err := m.q.GetLatestTopics.Select(&topics); err != nil {
+		m.lo.Error("error fetching topics", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return topics, nil
+}
+
+// Run periodically embeds new conversations and clusters them into topics.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.refresh(ctx); err != nil {
+				m.lo.Error("error clustering conversation topics", "error", err)
+			}
+		}
+	}
+}
+
+// refresh clusters the conversations of the current window and stores the resulting topics,
+// counting how many conversations of the preceding window fall into each topic.
+func (m *Manager) refresh(ctx context.Context) error {
+	var (
+		periodEnd   = time.Now()
+		periodStart = periodEnd.Add(-m.window)
+		prevStart   = periodStart.Add(-m.window)
+	)
+	if err := m.embedPending(ctx, prevStart); err != nil {
+		return err
+	}
+
+	var rows []struct {
+		ConversationID int64           `db:"conversation_id"`
+		CreatedAt      time.Time       `db:"created_at"`
+		Embedding      pq.Float64Array `db:"embedding"`
+	}
+	if err := m.q.GetEmbeddings.SelectContext(ctx, &rows, prevStart); err != nil {
+		return fmt.Errorf("fetching embeddings: %w", err)
+	}
+
+	var (
+		ids      []int64
+		current  [][]float64
+		previous [][]float64
+	)
+	for _, r := range rows {
+		v := normalize(r.Embedding)
+		if r.CreatedAt.Before(periodStart) {
+			previous = append(previous, v)
+			continue
+		}
+		ids = append(ids, r.ConversationID)
+		current = append(current, v)
+	}
+
+	clusters := cluster(current, m.similarityThreshold)
+	sort.SliceStable(clusters, func(i, j int) bool { return len(clusters[i]) > len(clusters[j]) })
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var count int
+	for _, members := range clusters {
+		if len(members) < m.minClusterSize || count >= maxTopics {
+			break
+		}
+		count++
+
+		c := centroid(current, members)
+		var prevCount int
+		for _, v := range previous {
+			if dot(v, c) >= m.similarityThreshold {
+				prevCount++
+			}
+		}
+		memberIDs := make(pq.Int64Array, len(members))
+		for i, idx := range members {
+			memberIDs[i] = ids[idx]
+		}
+
+		label := m.label(ctx, memberIDs)
+		if _, err := tx.StmtxContext(ctx, m.q.InsertTopic).ExecContext(ctx, periodStart, periodEnd, label, len(members), prevCount, memberIDs); err != nil {
+			return fmt.Errorf("inserting topic: %w", err)
+		}
+	}
+	if _, err := tx.StmtxContext(ctx, m.q.DeleteOldTopics).ExecContext(ctx, periodEnd.Add(-topicRetention)); err != nil {
+		return fmt.Errorf("deleting old topics: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing topics: %w", err)
+	}
+	m.lo.Info("clustered conversation topics", "conversations", len(current), "topics", count)
+	return nil
+}
+
+// embedPending embeds conversations created since the given time that have no embedding yet.
+func (m *Manager) embedPending(ctx context.Context, since time.Time) error {
+	for range maxEmbedBatches {
+		var pending []struct {
+			ID          int64  `db:"id"`
+			Subject     string `db:"subject"`
+			TextContent string `db:"text_content"`
+		}
+		if err := m.q.GetConversationsWithoutEmbeddings.SelectContext(ctx, &pending, since, embedBatchSize); err != nil {
+			return fmt.Errorf("fetching conversations to embed: %w", err)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		inputs := make([]string, len(pending))
+		for i, p := range pending {
+			inputs[i] = strings.TrimSpace(p.Subject + "\n\n" + p.TextContent)
+		}
+		embeddings, err := m.ai.Embed(inputs)
+		if err != nil {
+			return fmt.Errorf("embedding conversations: %w", err)
+		}
+		for i, p := range pending {
+			if _, err := m.q.InsertEmbedding.ExecContext(ctx, p.ID, pq.Float64Array(embeddings[i])); err != nil {
+				return fmt.Errorf("inserting embedding: %w", err)
+			}
+		}
+		if len(pending) < embedBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// label asks the AI provider for a short label describing the given conversations,
+// falling back to the first available subject.
+func (m *Manager) label(ctx context.Context, conversationIDs pq.Int64Array) string {
+	var samples []struct {
+		Subject     string `db:"subject"`
+		TextContent string `db:"text_content"`
+	}
+	if err := m.q.GetTopicSamples.SelectContext(ctx, &samples, conversationIDs); err != nil {
+		m.lo.Error("error fetching topic samples", "error", err)
+		return ""
+	}
+
+	var (
+		prompt   strings.Builder
+		fallback string
+	)
+	for i, s := range samples {
+		if fallback == "" {
+			fallback = s.Subject
+		}
+		fmt.Fprintf(&prompt, "Conversation %d:\nSubject: %s\n%s\n\n", i+1, s.Subject, s.TextContent)
+	}
+
+	label, err := m.ai.CompletionWithSystemPrompt(labelSystemPrompt, prompt.String())
+	if err != nil || strings.TrimSpace(label) == "" {
+		m.lo.Warn("could not label topic, using conversation subject", "error", err)
+		return fallback
+	}
+	return strings.TrimSpace(label)
+}
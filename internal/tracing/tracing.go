@@ -0,0 +1,109 @@
+// Package tracing sets up OpenTelemetry tracing and provides helpers to carry
+// trace context across the in-memory queues of the message pipeline.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used for all spans.
+const instrumentationName = "github.com/abhinavxd/libredesk"
+
+// Opts contains options for initializing tracing.
+type Opts struct {
+	// Endpoint is the OTLP/HTTP collector host:port.
+	Endpoint string
+	// Insecure disables TLS when talking to the collector.
+	Insecure bool
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// ServiceVersion is reported as the service.version resource attribute.
+	ServiceVersion string
+	// SampleRatio is the fraction of root traces sampled, between 0 and 1.
+	SampleRatio float64
+}
+
+// Carrier holds serialized trace context so that it can travel with queued work.
+type Carrier map[string]string
+
+// Init configures the global tracer provider to export spans to an OTLP collector.
+// It returns a function that flushes pending spans and shuts the provider down.
+func Init(ctx context.Context, opts Opts) (func(context.Context) error, error) {
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(opts.ServiceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("creating tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx, if any.
+// Without Init the global no-op provider is used and spans cost next to nothing.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if non-nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject serializes the trace context in ctx into a Carrier.
+// It returns nil if ctx carries no span.
+func Inject(ctx context.Context) Carrier {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	c := Carrier{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(c))
+	return c
+}
+
+// Extract returns a copy of ctx carrying the trace context serialized in c.
+func (c Carrier) Extract(ctx context.Context) context.Context {
+	if len(c) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(c))
+}
+
+// InjectHTTPHeaders writes the trace context in ctx into outgoing HTTP request headers.
+func InjectHTTPHeaders(ctx context.Context, header propagation.HeaderCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, header)
+}
@@ -0,0 +1,127 @@
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// The PDF is laid out on A4 pages in the standard Courier font, whose fixed glyph width lets lines be
+// wrapped by character count without embedding font metrics.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfFontSize   = 10
+	pdfLineHeight = 12
+
+	// Courier glyphs are 0.6 em wide.
+	pdfLineChars = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+	pdfPageLines = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// winAnsi maps the characters of the Windows-1252 range 0x80-0x9F that commonly appear in messages.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// PDF renders the plain text transcript as a PDF document.
+func PDF(t Transcript) []byte {
+	var lines []string
+	for _, l := range strings.Split(Text(t), "\n") {
+		lines = append(lines, wrapLine(l, pdfLineChars)...)
+	}
+
+	var pages [][]string
+	for len(lines) > pdfPageLines {
+		pages = append(pages, lines[:pdfPageLines])
+		lines = lines[pdfPageLines:]
+	}
+	pages = append(pages, lines)
+
+	// Objects: 1 catalog, 2 page tree, 3 font, then a page and its content stream per page.
+	var objects []string
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range pages {
+		var s bytes.Buffer
+		fmt.Fprintf(&s, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, l := range page {
+			fmt.Fprintf(&s, "(%s) '\n", pdfString(l))
+		}
+		s.WriteString("ET")
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", s.Len(), s.String()),
+		)
+	}
+
+	var (
+		b       bytes.Buffer
+		offsets = make([]int, len(objects))
+	)
+	b.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// wrapLine splits a line into lines of at most width characters, breaking at spaces where possible.
+func wrapLine(line string, width int) []string {
+	line = strings.ReplaceAll(strings.TrimRight(line, "\r "), "\t", "    ")
+	var out []string
+	for utf8.RuneCountInString(line) > width {
+		runes := []rune(line)
+		cut := width
+		for i := width; i > width/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		out = append(out, string(runes[:cut]))
+		line = strings.TrimLeft(string(runes[cut:]), " ")
+	}
+	return append(out, line)
+}
+
+// pdfString encodes text as the content of a PDF literal string in WinAnsiEncoding. Characters
+// the encoding doesn't have are replaced with '?'.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if c, ok := winAnsi[r]; ok {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
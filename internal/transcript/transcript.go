@@ -0,0 +1,232 @@
+// Package transcript renders the message history of a conversation as HTML, EML and PDF documents.
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	FormatHTML = "html"
+	FormatEML  = "eml"
+	FormatPDF  = "pdf"
+
+	timeFormat = "2006-01-02 15:04 MST"
+)
+
+// Formats are the formats a transcript can be rendered in.
+var Formats = []string{FormatPDF, FormatEML, FormatHTML}
+
+// Transcript is the message history of a conversation.
+type Transcript struct {
+	ReferenceNumber string
+	Subject         string
+	InboxName       string
+	InboxMail       string
+	ContactName     string
+	ContactEmail    string
+	CreatedAt       time.Time
+	GeneratedAt     time.Time
+	Messages        []Message
+}
+
+// Message is a message of a transcript, oldest first.
+type Message struct {
+	AuthorName  string
+	AuthorEmail string
+	Private     bool
+	CreatedAt   time.Time
+	Text        string
+	Attachments []Attachment
+}
+
+// Attachment is listed in a transcript without its content.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Size        int
+}
+
+// Title returns the title of the transcript.
+func (t Transcript) Title() string {
+	if t.Subject == "" {
+		return "#" + t.ReferenceNumber
+	}
+	return "#" + t.ReferenceNumber + " " + t.Subject
+}
+
+// FileName returns the file name of the transcript rendered in a format.
+func FileName(t Transcript, format string) string {
+	return fmt.Sprintf("conversation-%s.%s", t.ReferenceNumber, format)
+}
+
+// Render renders the transcript in a format and returns it with its content type.
+func Render(t Transcript, format string) ([]byte, string, error) {
+	switch format {
+	case FormatHTML:
+		b, err := HTML(t)
+		return b, "text/html; charset=utf-8", err
+	case FormatEML:
+		b, err := EML(t)
+		return b, "message/rfc822", err
+	case FormatPDF:
+		return PDF(t), "application/pdf", nil
+	}
+	return nil, "", fmt.Errorf("unknown transcript format: %s", format)
+}
+
+var htmlTpl = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.UTC().Format(timeFormat) },
+	"size": formatSize,
+}).Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; max-width: 50rem; margin: 2rem auto; color: #111; }
+.meta { color: #555; font-size: 0.9rem; }
+.message { border-top: 1px solid #ddd; padding: 1rem 0; }
+.private { background: #fffbea; }
+.text { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p class="meta">
+{{ if .ContactName }}{{ .ContactName }}{{ end }}{{ if .ContactEmail }} &lt;{{ .ContactEmail }}&gt;{{ end }}<br>
+{{ .InboxName }} &middot; {{ time .CreatedAt }}<br>
+Exported {{ time .GeneratedAt }}
+</p>
+{{ range .Messages }}
+<div class="message{{ if .Private }} private{{ end }}">
+<p class="meta"><strong>{{ .AuthorName }}</strong>{{ if .AuthorEmail }} &lt;{{ .AuthorEmail }}&gt;{{ end }} &middot; {{ time .CreatedAt }}{{ if .Private }} &middot; Private note{{ end }}</p>
+<div class="text">{{ .Text }}</div>
+{{ if .Attachments }}<ul class="meta">{{ range .Attachments }}<li>{{ .Name }} ({{ .ContentType }}, {{ size .Size }})</li>{{ end }}</ul>{{ end }}
+</div>
+{{ end }}
+</body>
+</html>
+`))
+
+// HTML renders the transcript as a standalone HTML page.
+func HTML(t Transcript) ([]byte, error) {
+	var b bytes.Buffer
+	if err := htmlTpl.Execute(&b, t); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Text renders the transcript as plain text.
+func Text(t Transcript) string {
+	var b strings.Builder
+	b.WriteString(t.Title() + "\n")
+	if t.ContactEmail != "" {
+		fmt.Fprintf(&b, "%s <%s>\n", t.ContactName, t.ContactEmail)
+	} else if t.ContactName != "" {
+		b.WriteString(t.ContactName + "\n")
+	}
+	fmt.Fprintf(&b, "%s, %s\n", t.InboxName, t.CreatedAt.UTC().Format(timeFormat))
+	fmt.Fprintf(&b, "Exported %s\n", t.GeneratedAt.UTC().Format(timeFormat))
+
+	for _, m := range t.Messages {
+		b.WriteString("\n" + strings.Repeat("-", 72) + "\n")
+		b.WriteString(m.AuthorName)
+		if m.AuthorEmail != "" {
+			b.WriteString(" <" + m.AuthorEmail + ">")
+		}
+		b.WriteString(", " + m.CreatedAt.UTC().Format(timeFormat))
+		if m.Private {
+			b.WriteString(", private note")
+		}
+		b.WriteString("\n\n" + strings.TrimSpace(m.Text) + "\n")
+		if len(m.Attachments) > 0 {
+			b.WriteString("\nAttachments:\n")
+			for _, a := range m.Attachments {
+				fmt.Fprintf(&b, "  %s (%s, %s)\n", a.Name, a.ContentType, formatSize(a.Size))
+			}
+		}
+	}
+	return b.String()
+}
+
+// EML renders the transcript as an email from the inbox to the contact, with plain text and HTML parts.
+func EML(t Transcript) ([]byte, error) {
+	htmlBody, err := HTML(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		b  bytes.Buffer
+		mw = multipart.NewWriter(&b)
+	)
+	from := t.InboxMail
+	if from == "" {
+		from = t.InboxName
+	}
+	to := (&mail.Address{Name: t.ContactName, Address: t.ContactEmail}).String()
+	if t.ContactEmail == "" {
+		to = t.ContactName
+	}
+
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Date: %s\r\n", t.GeneratedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(to))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(t.Title())))
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", []byte(Text(t))},
+		{"text/html; charset=utf-8", htmlBody},
+	}
+	for _, p := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write(p.body); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// headerValue strips line breaks from an email header value.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// formatSize returns a human readable file size.
+func formatSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/1024/1024)
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%d B", size)
+}
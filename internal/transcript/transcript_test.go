@@ -0,0 +1,102 @@
+package transcript
+
+import (
+	"bytes"
+	"io"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTranscript() Transcript {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	return Transcript{
+		ReferenceNumber: "100",
+		Subject:         "Refund (order 42)",
+		InboxName:       "Support",
+		InboxMail:       "support@example.com",
+		ContactName:     "Jane Doe",
+		ContactEmail:    "jane@example.com",
+		CreatedAt:       at,
+		GeneratedAt:     at.Add(time.Hour),
+		Messages: []Message{
+			{AuthorName: "Jane Doe", CreatedAt: at, Text: "Where is my refund? <script>x</script>",
+				Attachments: []Attachment{{Name: "receipt.pdf", ContentType: "application/pdf", Size: 2048}}},
+			{AuthorName: "Agent", CreatedAt: at.Add(time.Minute), Text: "On its way — café", Private: true},
+		},
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"hello world again", 11, []string{"hello world", "again"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+		{"", 5, []string{""}},
+	}
+	for _, tt := range tests {
+		if got := wrapLine(tt.line, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	if got, want := pdfString(`a(b)\c é — 日`), `a\(b\)\\c \351 \227 ?`; got != want {
+		t.Errorf("pdfString() = %q, want %q", got, want)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	b := PDF(testTranscript())
+	if !bytes.HasPrefix(b, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(b, []byte("%%EOF\n")) {
+		t.Fatalf("missing PDF header or trailer")
+	}
+	// startxref must point at the cross-reference table.
+	i := bytes.LastIndex(b, []byte("startxref\n"))
+	off, err := strconv.Atoi(strings.Fields(string(b[i+len("startxref\n"):]))[0])
+	if err != nil || !bytes.HasPrefix(b[off:], []byte("xref\n")) {
+		t.Errorf("startxref %d doesn't point at xref table", off)
+	}
+}
+
+func TestHTMLEscapesText(t *testing.T) {
+	b, err := HTML(testTranscript())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("<script>")) {
+		t.Errorf("message text isn't escaped")
+	}
+	if !bytes.Contains(b, []byte("receipt.pdf (application/pdf, 2.0 KB)")) {
+		t.Errorf("attachment isn't listed")
+	}
+}
+
+func TestEML(t *testing.T) {
+	b, err := EML(testTranscript())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("From"); got != "support@example.com" {
+		t.Errorf("From = %q", got)
+	}
+	if !strings.HasPrefix(msg.Header.Get("Content-Type"), "multipart/alternative; boundary=") {
+		t.Errorf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !bytes.Contains(body, []byte("text/plain")) || !bytes.Contains(body, []byte("text/html")) {
+		t.Errorf("missing text or HTML part")
+	}
+}
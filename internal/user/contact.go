@@ -7,6 +7,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
 )
 
@@ -92,6 +93,15 @@ func (u *Manager) UpdateContact(id int, user models.User) error {
 	return nil
 }
 
+// SetRiskFlags replaces the risk flags of a contact.
+func (u *Manager) SetRiskFlags(id int, flags []string) error {
+	if _, err := u.q.SetRiskFlags.Exec(id, pq.Array(flags)); err != nil {
+		u.lo.Error("error setting contact risk flags", "contact_id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
 // GetAllContacts returns a list of all contacts.
 func (u *Manager) GetContacts(page, pageSize int, order, orderBy string, filtersJSON string) ([]models.UserCompact, error) {
 	if pageSize > maxListPageSize {
@@ -0,0 +1,125 @@
+package user
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/lib/pq"
+)
+
+// Keyboard binding actions.
+const (
+	KeyboardActionMacro  = "macro"
+	KeyboardActionStatus = "status"
+)
+
+const maxKeyboardBindings = 50
+
+var (
+	// keyModifiers are the modifiers a key combination can have, in their normalized order.
+	keyModifiers = []string{"ctrl", "alt", "shift", "meta"}
+	// reservedKeyCombos are combinations the app already handles.
+	reservedKeyCombos = []string{"ctrl+k", "meta+k", "ctrl+b", "ctrl+enter"}
+)
+
+// normalizeKeyCombo returns the normalized form of a key combination like "Shift+Ctrl+1", i.e. "ctrl+shift+1".
+// Combinations need a ctrl, alt or meta modifier so that they don't fire while typing.
+func normalizeKeyCombo(keys string) (string, error) {
+	var (
+		parts = strings.Split(strings.ToLower(strings.ReplaceAll(keys, " ", "")), "+")
+		mods  = make([]string, 0, len(parts))
+		key   string
+	)
+	for _, p := range parts {
+		switch p {
+		case "control":
+			p = "ctrl"
+		case "cmd", "command":
+			p = "meta"
+		case "option":
+			p = "alt"
+		}
+		if slices.Contains(keyModifiers, p) {
+			if slices.Contains(mods, p) {
+				return "", fmt.Errorf("repeated modifier %q", p)
+			}
+			mods = append(mods, p)
+			continue
+		}
+		if p == "" || key != "" {
+			return "", fmt.Errorf("key combination %q must have exactly one key", keys)
+		}
+		key = p
+	}
+	if key == "" {
+		return "", fmt.Errorf("key combination %q must have exactly one key", keys)
+	}
+	if !slices.ContainsFunc(mods, func(m string) bool { return m != "shift" }) {
+		return "", fmt.Errorf("key combination %q needs a ctrl, alt or meta modifier", keys)
+	}
+	slices.SortFunc(mods, func(a, b string) int {
+		return slices.Index(keyModifiers, a) - slices.Index(keyModifiers, b)
+	})
+	return strings.Join(append(mods, key), "+"), nil
+}
+
+// GetKeyboardBindings returns the keyboard bindings of an agent.
+func (u *Manager) GetKeyboardBindings(userID int) ([]models.KeyboardBinding, error) {
+	var bindings = make([]models.KeyboardBinding, 0)
+	if err := u.q.GetKeyboardBindings.Select(&bindings, userID); err != nil {
+		u.lo.Error("error fetching keyboard bindings", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return bindings, nil
+}
+
+// SetKeyboardBindings replaces the keyboard bindings of an agent. Key combinations are normalized and
+// must not repeat or clash with the app's own shortcuts.
+func (u *Manager) SetKeyboardBindings(userID int, bindings []models.KeyboardBinding) ([]models.KeyboardBinding, error) {
+	if len(bindings) > maxKeyboardBindings {
+		return nil, envelope.NewError(envelope.InputError, u.i18n.Ts("globals.messages.maxLength", "max", fmt.Sprintf("%d", maxKeyboardBindings)), nil)
+	}
+	var (
+		keys    = make([]string, 0, len(bindings))
+		actions = make([]string, 0, len(bindings))
+		targets = make([]string, 0, len(bindings))
+	)
+	for _, b := range bindings {
+		combo, err := normalizeKeyCombo(b.Keys)
+		if err != nil {
+			return nil, envelope.NewError(envelope.InputError, u.i18n.Ts("validation.invalidKeyCombination", "keys", b.Keys), nil)
+		}
+		if slices.Contains(reservedKeyCombos, combo) || slices.Contains(keys, combo) {
+			return nil, envelope.NewError(envelope.InputError, u.i18n.Ts("validation.keyCombinationInUse", "keys", combo), nil)
+		}
+		if (b.Action != KeyboardActionMacro && b.Action != KeyboardActionStatus) || b.Target == "" {
+			return nil, envelope.NewError(envelope.InputError, u.i18n.T("validation.invalidValue"), nil)
+		}
+		keys = append(keys, combo)
+		actions = append(actions, b.Action)
+		targets = append(targets, b.Target)
+	}
+
+	tx, err := u.db.Beginx()
+	if err != nil {
+		u.lo.Error("error starting transaction", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Stmtx(u.q.DeleteKeyboardBindings).Exec(userID); err != nil {
+		u.lo.Error("error deleting keyboard bindings", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := tx.Stmtx(u.q.InsertKeyboardBindings).Exec(userID, pq.Array(keys), pq.Array(actions), pq.Array(targets)); err != nil {
+		u.lo.Error("error inserting keyboard bindings", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := tx.Commit(); err != nil {
+		u.lo.Error("error committing keyboard bindings", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return u.GetKeyboardBindings(userID)
+}
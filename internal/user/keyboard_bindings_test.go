@@ -0,0 +1,32 @@
+package user
+
+import "testing"
+
+func TestNormalizeKeyCombo(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"Ctrl+1", "ctrl+1", false},
+		{"Shift + Ctrl + R", "ctrl+shift+r", false},
+		{"cmd+alt+s", "alt+meta+s", false},
+		{"Control+Option+Enter", "ctrl+alt+enter", false},
+		{"r", "", true},
+		{"shift+r", "", true},
+		{"ctrl+", "", true},
+		{"ctrl+a+b", "", true},
+		{"ctrl+ctrl+a", "", true},
+		{"ctrl+alt", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeKeyCombo(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeKeyCombo(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeKeyCombo(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
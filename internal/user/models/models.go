@@ -7,6 +7,7 @@ import (
 
 	rmodels "github.com/abhinavxd/libredesk/internal/role/models"
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
+	"github.com/jmoiron/sqlx/types"
 	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
 )
@@ -68,6 +69,7 @@ type User struct {
 	Meta                   json.RawMessage      `db:"meta" json:"meta"`
 	CustomAttributes       json.RawMessage      `db:"custom_attributes" json:"custom_attributes"`
 	ExternalUserID         null.String          `db:"external_user_id" json:"external_user_id"`
+	RiskFlags              pq.StringArray       `db:"risk_flags" json:"risk_flags"`
 	Teams                  tmodels.TeamsCompact `db:"teams" json:"teams"`
 	ContactChannelID       int                  `db:"contact_channel_id" json:"contact_channel_id,omitempty"`
 	NewPassword            string               `db:"-" json:"new_password,omitempty"`
@@ -80,6 +82,10 @@ type User struct {
 	APIKey           null.String `db:"api_key" json:"api_key"`
 	APIKeyLastUsedAt null.Time   `db:"api_key_last_used_at" json:"api_key_last_used_at"`
 	APISecret        null.String `db:"api_secret" json:"-"`
+
+	// Two-factor authentication fields
+	TOTPEnabled       bool `db:"totp_enabled" json:"totp_enabled"`
+	TwoFactorRequired bool `db:"two_factor_required" json:"two_factor_required"`
 }
 
 // ChatUser is a user with limited fields for live chat.
@@ -121,3 +127,87 @@ func (u *User) HasAdminRole() bool {
 func (u *User) IsSystemUser() bool {
 	return u.Email.String == SystemUserEmail
 }
+
+// ContactPreferences holds the communication preferences of a contact.
+type ContactPreferences struct {
+	ContactID        int         `db:"contact_id" json:"contact_id"`
+	DoNotContact     bool        `db:"do_not_contact" json:"do_not_contact"`
+	NoSurveys        bool        `db:"no_surveys" json:"no_surveys"`
+	NoMarketing      bool        `db:"no_marketing" json:"no_marketing"`
+	PreferredChannel null.String `db:"preferred_channel" json:"preferred_channel"`
+	NoDigest         bool        `db:"no_digest" json:"no_digest"`
+	UpdatedAt        null.Time   `db:"updated_at" json:"updated_at"`
+}
+
+// AllowsSurveys reports whether the contact may be sent surveys such as CSAT.
+func (p ContactPreferences) AllowsSurveys() bool {
+	return !p.DoNotContact && !p.NoSurveys
+}
+
+// AllowsMarketing reports whether the contact may be sent marketing messages.
+func (p ContactPreferences) AllowsMarketing() bool {
+	return !p.DoNotContact && !p.NoMarketing
+}
+
+// AllowsDigest reports whether the contact may be sent the digest of their open conversations.
+func (p ContactPreferences) AllowsDigest() bool {
+	return !p.DoNotContact && !p.NoDigest
+}
+
+// AllowsAutomated reports whether the contact may be sent messages not written by an agent.
+func (p ContactPreferences) AllowsAutomated() bool {
+	return !p.DoNotContact
+}
+
+// WatchDigestHours are the hours an agent can choose between two digests of activity on the
+// conversations they watch, 0 turns the digest off.
+var WatchDigestHours = []int{0, 12, 24}
+
+// AgentNotificationPreferences holds the notification preferences of an agent.
+type AgentNotificationPreferences struct {
+	UserID            int       `db:"user_id" json:"user_id"`
+	WatchDigestHours  int       `db:"watch_digest_hours" json:"watch_digest_hours"`
+	WatchDigestSentAt null.Time `db:"watch_digest_sent_at" json:"watch_digest_sent_at"`
+	UpdatedAt         null.Time `db:"updated_at" json:"updated_at"`
+}
+
+// AgentSchedule holds the weekly working hours of an agent, distinct from the hours of their teams.
+type AgentSchedule struct {
+	UserID    int            `db:"user_id" json:"user_id"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+	Timezone  string         `db:"timezone" json:"timezone"`
+	Hours     types.JSONText `db:"hours" json:"hours"`
+}
+
+// AgentScheduleOverride marks a time range in which an agent is on or off shift regardless of their schedule.
+type AgentScheduleOverride struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UserID    int       `db:"user_id" json:"user_id"`
+	StartsAt  time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt    time.Time `db:"ends_at" json:"ends_at"`
+	Available bool      `db:"available" json:"available"`
+	Note      string    `db:"note" json:"note"`
+}
+
+// KeyboardBinding is an agent's key combination for a macro or status change.
+type KeyboardBinding struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Keys      string    `db:"keys" json:"keys"`
+	Action    string    `db:"action" json:"action"`
+	// Target is the macro or status ID.
+	Target string `db:"target" json:"target"`
+}
+
+// AgentShift is the shift state of an agent at a point in time.
+type AgentShift struct {
+	ID                 int            `db:"id" json:"id"`
+	FirstName          string         `db:"first_name" json:"first_name"`
+	LastName           string         `db:"last_name" json:"last_name"`
+	AvailabilityStatus string         `db:"availability_status" json:"availability_status"`
+	Timezone           null.String    `db:"timezone" json:"timezone"`
+	Hours              types.JSONText `db:"hours" json:"-"`
+	OverrideAvailable  null.Bool      `db:"override_available" json:"-"`
+	OnShift            bool           `db:"-" json:"on_shift"`
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+// GetContactPreferences returns the communication preferences of a contact, defaults if none are stored.
+func (u *Manager) GetContactPreferences(contactID int) (models.ContactPreferences, error) {
+	var prefs models.ContactPreferences
+	if err := u.q.GetContactPreferences.Get(&prefs, contactID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return prefs, envelope.NewError(envelope.NotFoundError, u.i18n.T("validation.notFoundUser"), nil)
+		}
+		u.lo.Error("error fetching contact preferences", "contact_id", contactID, "error", err)
+		return prefs, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return prefs, nil
+}
+
+// UpdateContactPreferences stores the communication preferences of a contact.
+func (u *Manager) UpdateContactPreferences(contactID int, prefs models.ContactPreferences) (models.ContactPreferences, error) {
+	// Make sure the user exists and is a contact.
+	if _, err := u.GetContactPreferences(contactID); err != nil {
+		return prefs, err
+	}
+	var result models.ContactPreferences
+	if err := u.q.UpsertContactPreferences.Get(&result, contactID, prefs.DoNotContact, prefs.NoSurveys, prefs.NoMarketing, prefs.PreferredChannel, prefs.NoDigest); err != nil {
+		u.lo.Error("error updating contact preferences", "contact_id", contactID, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// GetAgentNotificationPreferences returns the notification preferences of an agent, defaults if none are stored.
+func (u *Manager) GetAgentNotificationPreferences(userID int) (models.AgentNotificationPreferences, error) {
+	var prefs models.AgentNotificationPreferences
+	if err := u.q.GetAgentNotificationPreferences.Get(&prefs, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return prefs, envelope.NewError(envelope.NotFoundError, u.i18n.T("validation.notFoundUser"), nil)
+		}
+		u.lo.Error("error fetching agent notification preferences", "user_id", userID, "error", err)
+		return prefs, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return prefs, nil
+}
+
+// UpdateAgentNotificationPreferences stores the notification preferences of an agent.
+func (u *Manager) UpdateAgentNotificationPreferences(userID int, prefs models.AgentNotificationPreferences) (models.AgentNotificationPreferences, error) {
+	if !slices.Contains(models.WatchDigestHours, prefs.WatchDigestHours) {
+		return prefs, envelope.NewError(envelope.InputError, u.i18n.T("validation.invalidValue"), nil)
+	}
+	var result models.AgentNotificationPreferences
+	if err := u.q.UpsertAgentNotificationPreferences.Get(&result, userID, prefs.WatchDigestHours); err != nil {
+		u.lo.Error("error updating agent notification preferences", "user_id", userID, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
@@ -44,7 +44,10 @@ SELECT
     u.api_key,
     u.api_key_last_used_at,
     u.external_user_id,
+    u.risk_flags,
     u.api_secret,
+    u.totp_enabled_at IS NOT NULL AS totp_enabled,
+    COALESCE(bool_or(r.require_two_factor), FALSE) AS two_factor_required,
     array_agg(DISTINCT r.name) FILTER (WHERE r.name IS NOT NULL) AS roles,
     COALESCE(
         (SELECT json_agg(json_build_object('id', t.id, 'name', t.name, 'emoji', t.emoji))
@@ -229,6 +232,11 @@ SET first_name = COALESCE(NULLIF($2, ''), first_name),
     updated_at = now()
 WHERE id = $1 AND type IN ('contact', 'visitor');
 
+-- name: set-risk-flags
+UPDATE users
+SET risk_flags = $2, updated_at = now()
+WHERE id = $1 AND type IN ('contact', 'visitor');
+
 -- name: get-notes
 SELECT 
     cn.id,
@@ -411,3 +419,147 @@ SELECT
     (SELECT COUNT(*) FROM transfer_conversations) as conversations_transferred,
     (SELECT COUNT(*) FROM transfer_messages) as messages_transferred,
     (SELECT COUNT(*) FROM delete_visitor) as visitor_deleted;
+
+-- name: get-contact-preferences
+-- Returns default preferences for contacts that have none stored.
+SELECT u.id AS contact_id,
+    COALESCE(p.do_not_contact, FALSE) AS do_not_contact,
+    COALESCE(p.no_surveys, FALSE) AS no_surveys,
+    COALESCE(p.no_marketing, FALSE) AS no_marketing,
+    p.preferred_channel,
+    COALESCE(p.no_digest, FALSE) AS no_digest,
+    p.updated_at
+FROM users u
+LEFT JOIN contact_preferences p ON p.contact_id = u.id
+WHERE u.id = $1 AND u.type IN ('contact', 'visitor') AND u.deleted_at IS NULL;
+
+-- name: upsert-contact-preferences
+INSERT INTO contact_preferences (contact_id, do_not_contact, no_surveys, no_marketing, preferred_channel, no_digest)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (contact_id) DO UPDATE SET
+    do_not_contact = EXCLUDED.do_not_contact,
+    no_surveys = EXCLUDED.no_surveys,
+    no_marketing = EXCLUDED.no_marketing,
+    preferred_channel = EXCLUDED.preferred_channel,
+    no_digest = EXCLUDED.no_digest,
+    updated_at = NOW()
+RETURNING contact_id, do_not_contact, no_surveys, no_marketing, preferred_channel, no_digest, updated_at;
+
+-- name: get-totp
+SELECT totp_secret, totp_enabled_at IS NOT NULL AS totp_enabled, totp_last_step
+FROM users
+WHERE id = $1 AND type = 'agent' AND deleted_at IS NULL;
+
+-- name: set-totp-secret
+-- Stores a pending secret, enrollment is completed by enable-totp.
+UPDATE users SET totp_secret = $2, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+WHERE id = $1 AND totp_enabled_at IS NULL;
+
+-- name: enable-totp
+UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW()
+WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL;
+
+-- name: disable-totp
+WITH codes AS (
+    DELETE FROM user_recovery_codes WHERE user_id = $1
+)
+UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: update-totp-last-step
+-- Only advances the step so a code cannot be used twice.
+UPDATE users SET totp_last_step = $2
+WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2);
+
+-- name: delete-recovery-codes
+DELETE FROM user_recovery_codes WHERE user_id = $1;
+
+-- name: insert-recovery-codes
+INSERT INTO user_recovery_codes (user_id, code_hash)
+SELECT $1, unnest($2::TEXT[]);
+
+-- name: use-recovery-code
+UPDATE user_recovery_codes SET used_at = NOW()
+WHERE id = (
+    SELECT id FROM user_recovery_codes
+    WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+    LIMIT 1
+    FOR UPDATE
+);
+
+-- name: get-agent-schedule
+SELECT user_id, updated_at, timezone, hours FROM agent_schedules WHERE user_id = $1;
+
+-- name: upsert-agent-schedule
+INSERT INTO agent_schedules (user_id, timezone, hours)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE SET timezone = EXCLUDED.timezone, hours = EXCLUDED.hours, updated_at = NOW()
+RETURNING user_id, updated_at, timezone, hours;
+
+-- name: delete-agent-schedule
+DELETE FROM agent_schedules WHERE user_id = $1;
+
+-- name: get-agent-schedule-overrides
+-- Returns current and upcoming overrides.
+SELECT id, created_at, user_id, starts_at, ends_at, available, note
+FROM agent_schedule_overrides
+WHERE user_id = $1 AND ends_at > NOW()
+ORDER BY starts_at;
+
+-- name: insert-agent-schedule-override
+INSERT INTO agent_schedule_overrides (user_id, starts_at, ends_at, available, note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, user_id, starts_at, ends_at, available, note;
+
+-- name: delete-agent-schedule-override
+DELETE FROM agent_schedule_overrides WHERE id = $1 AND user_id = $2;
+
+-- name: get-agent-shifts
+-- Returns the schedule of every enabled agent with the override in effect at $1, if any. The latest override wins.
+SELECT u.id, u.first_name, COALESCE(u.last_name, '') AS last_name, u.availability_status,
+    s.timezone, s.hours,
+    (
+        SELECT o.available FROM agent_schedule_overrides o
+        WHERE o.user_id = u.id AND o.starts_at <= $1 AND o.ends_at > $1
+        ORDER BY o.created_at DESC LIMIT 1
+    ) AS override_available
+FROM users u
+LEFT JOIN agent_schedules s ON s.user_id = u.id
+WHERE u.type = 'agent' AND u.deleted_at IS NULL AND u.enabled = true
+ORDER BY u.first_name, u.last_name;
+
+-- name: get-keyboard-bindings
+SELECT id, created_at, keys, action, target FROM agent_keyboard_bindings WHERE user_id = $1 ORDER BY id;
+
+-- name: delete-keyboard-bindings
+DELETE FROM agent_keyboard_bindings WHERE user_id = $1;
+
+-- name: insert-keyboard-bindings
+INSERT INTO agent_keyboard_bindings (user_id, keys, action, target)
+SELECT $1, unnest($2::text[]), unnest($3::text[]), unnest($4::text[]);
+
+-- name: get-agent-notification-preferences
+-- Returns default preferences for agents that have none stored.
+SELECT u.id AS user_id,
+    COALESCE(p.watch_digest_hours, 0) AS watch_digest_hours,
+    p.watch_digest_sent_at,
+    p.updated_at
+FROM users u
+LEFT JOIN agent_notification_preferences p ON p.user_id = u.id
+WHERE u.id = $1 AND u.type = 'agent' AND u.deleted_at IS NULL;
+
+-- name: upsert-agent-notification-preferences
+INSERT INTO agent_notification_preferences (user_id, watch_digest_hours)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+    watch_digest_hours = EXCLUDED.watch_digest_hours,
+    updated_at = NOW()
+RETURNING user_id, watch_digest_hours, watch_digest_sent_at, updated_at;
+
+-- name: get-agent-signature
+SELECT COALESCE(signature, '') FROM users WHERE id = $1 AND type = 'agent' AND deleted_at IS NULL;
+
+-- name: update-agent-signature
+UPDATE users SET signature = NULLIF($2, ''), updated_at = NOW()
+WHERE id = $1 AND type = 'agent' AND deleted_at IS NULL
+RETURNING COALESCE(signature, '');
@@ -0,0 +1,167 @@
+package user
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	businesshours "github.com/abhinavxd/libredesk/internal/business_hours"
+	bmodels "github.com/abhinavxd/libredesk/internal/business_hours/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/jmoiron/sqlx/types"
+)
+
+const maxScheduleOverrideNoteLength = 200
+
+// GetAgentSchedule returns the working hours of an agent, nil if the agent has none.
+func (u *Manager) GetAgentSchedule(userID int) (*models.AgentSchedule, error) {
+	var schedule models.AgentSchedule
+	if err := u.q.GetAgentSchedule.Get(&schedule, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		u.lo.Error("error fetching agent schedule", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return &schedule, nil
+}
+
+// UpsertAgentSchedule sets the working hours of an agent.
+func (u *Manager) UpsertAgentSchedule(userID int, timezone string, hours types.JSONText) (models.AgentSchedule, error) {
+	var schedule models.AgentSchedule
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		return schedule, envelope.NewError(envelope.InputError, u.i18n.T("validation.invalidValue"), nil)
+	}
+	if err := validateWorkingHours(hours); err != nil {
+		return schedule, envelope.NewError(envelope.InputError, u.i18n.T("validation.invalidTimeFormat"), nil)
+	}
+	if err := u.q.UpsertAgentSchedule.Get(&schedule, userID, timezone, hours); err != nil {
+		u.lo.Error("error saving agent schedule", "user_id", userID, "error", err)
+		return schedule, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return schedule, nil
+}
+
+// DeleteAgentSchedule removes the working hours of an agent, who is then always considered on shift.
+func (u *Manager) DeleteAgentSchedule(userID int) error {
+	if _, err := u.q.DeleteAgentSchedule.Exec(userID); err != nil {
+		u.lo.Error("error deleting agent schedule", "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// GetAgentScheduleOverrides returns the current and upcoming schedule overrides of an agent.
+func (u *Manager) GetAgentScheduleOverrides(userID int) ([]models.AgentScheduleOverride, error) {
+	var overrides = make([]models.AgentScheduleOverride, 0)
+	if err := u.q.GetAgentScheduleOverrides.Select(&overrides, userID); err != nil {
+		u.lo.Error("error fetching agent schedule overrides", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return overrides, nil
+}
+
+// CreateAgentScheduleOverride adds a schedule override for an agent.
+func (u *Manager) CreateAgentScheduleOverride(o models.AgentScheduleOverride) (models.AgentScheduleOverride, error) {
+	var override models.AgentScheduleOverride
+	if o.StartsAt.IsZero() || !o.EndsAt.After(o.StartsAt) {
+		return override, envelope.NewError(envelope.InputError, u.i18n.T("validation.invalidValue"), nil)
+	}
+	if utf8.RuneCountInString(o.Note) > maxScheduleOverrideNoteLength {
+		return override, envelope.NewError(envelope.InputError, u.i18n.Ts("globals.messages.maxLength", "max", fmt.Sprintf("%d", maxScheduleOverrideNoteLength)), nil)
+	}
+	if err := u.q.InsertAgentScheduleOverride.Get(&override, o.UserID, o.StartsAt, o.EndsAt, o.Available, o.Note); err != nil {
+		u.lo.Error("error inserting agent schedule override", "user_id", o.UserID, "error", err)
+		return override, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return override, nil
+}
+
+// DeleteAgentScheduleOverride deletes a schedule override of an agent.
+func (u *Manager) DeleteAgentScheduleOverride(userID, id int) error {
+	if _, err := u.q.DeleteAgentScheduleOverride.Exec(id, userID); err != nil {
+		u.lo.Error("error deleting agent schedule override", "user_id", userID, "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// GetAgentShifts returns the shift state of all enabled agents at the given time.
+// An override in effect decides the state, else the agent's working hours do. Agents without
+// working hours are always on shift.
+func (u *Manager) GetAgentShifts(at time.Time) ([]models.AgentShift, error) {
+	var shifts = make([]models.AgentShift, 0)
+	if err := u.q.GetAgentShifts.Select(&shifts, at); err != nil {
+		u.lo.Error("error fetching agent shifts", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	for i := range shifts {
+		s := &shifts[i]
+		switch {
+		case s.OverrideAvailable.Valid:
+			s.OnShift = s.OverrideAvailable.Bool
+		case !s.Timezone.Valid:
+			s.OnShift = true
+		default:
+			open, err := businesshours.IsOpen(bmodels.BusinessHours{Hours: s.Hours}, s.Timezone.String, at)
+			if err != nil {
+				// Don't keep agents with a broken schedule out of assignment.
+				u.lo.Error("error evaluating agent schedule", "user_id", s.ID, "error", err)
+				open = true
+			}
+			s.OnShift = open
+		}
+	}
+	return shifts, nil
+}
+
+// GetOnShiftAgentIDs returns the IDs of the agents on shift at the given time.
+func (u *Manager) GetOnShiftAgentIDs(at time.Time) (map[int]struct{}, error) {
+	shifts, err := u.GetAgentShifts(at)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]struct{}, len(shifts))
+	for _, s := range shifts {
+		if s.OnShift {
+			ids[s.ID] = struct{}{}
+		}
+	}
+	return ids, nil
+}
+
+// validateWorkingHours checks working hours are keyed by weekday name with "HH:MM" opening times before closing times.
+func validateWorkingHours(hours types.JSONText) error {
+	var days map[string]bmodels.WorkingHours
+	if err := json.Unmarshal(hours, &days); err != nil {
+		return err
+	}
+	for day, h := range days {
+		valid := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if d.String() == day {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid weekday %q", day)
+		}
+		open, err := time.Parse("15:04", h.Open)
+		if err != nil {
+			return err
+		}
+		cls, err := time.Parse("15:04", h.Close)
+		if err != nil {
+			return err
+		}
+		if !cls.After(open) {
+			return fmt.Errorf("closing time before opening time on %s", day)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// maxSignatureLength is the maximum length of an agent signature in bytes of HTML.
+const maxSignatureLength = 10000
+
+// GetSignature returns the HTML signature of an agent, empty if the agent has none.
+func (u *Manager) GetSignature(userID int) (string, error) {
+	var signature string
+	if err := u.q.GetAgentSignature.Get(&signature, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", envelope.NewError(envelope.NotFoundError, u.i18n.T("validation.notFoundUser"), nil)
+		}
+		u.lo.Error("error fetching agent signature", "user_id", userID, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return signature, nil
+}
+
+// UpdateSignature sets the HTML signature of an agent, an empty signature removes it.
+func (u *Manager) UpdateSignature(userID int, signature string) (string, error) {
+	signature = strings.TrimSpace(signature)
+	if len(signature) > maxSignatureLength {
+		return "", envelope.NewError(envelope.InputError, u.i18n.Ts("globals.messages.maxLength", "max", fmt.Sprintf("%d", maxSignatureLength)), nil)
+	}
+	var saved string
+	if err := u.q.UpdateAgentSignature.Get(&saved, userID, signature); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", envelope.NewError(envelope.NotFoundError, u.i18n.T("validation.notFoundUser"), nil)
+		}
+		u.lo.Error("error updating agent signature", "user_id", userID, "error", err)
+		return "", envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return saved, nil
+}
@@ -0,0 +1,215 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/crypto"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/totp"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes generated on enrollment and regeneration.
+	recoveryCodeCount = 10
+	// recoveryCodeSize is the number of random bytes of a recovery code.
+	recoveryCodeSize = 5
+)
+
+type totpState struct {
+	Secret   null.String `db:"totp_secret"`
+	Enabled  bool        `db:"totp_enabled"`
+	LastStep null.Int64  `db:"totp_last_step"`
+}
+
+// SetupTOTP generates a new TOTP secret for the agent and returns it with the otpauth:// URI for authenticator apps.
+// Two-factor authentication is enabled once a code is confirmed with EnableTOTP.
+func (u *Manager) SetupTOTP(userID int, issuer string) (string, string, error) {
+	agent, err := u.GetAgent(userID, "")
+	if err != nil {
+		return "", "", err
+	}
+	if agent.TOTPEnabled {
+		return "", "", envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorAlreadyEnabled"), nil)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		u.lo.Error("error generating TOTP secret", "error", err)
+		return "", "", envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	encrypted, err := crypto.Encrypt(secret, u.encryptionKey)
+	if err != nil {
+		u.lo.Error("error encrypting TOTP secret", "error", err)
+		return "", "", envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := u.q.SetTOTPSecret.Exec(userID, encrypted); err != nil {
+		u.lo.Error("error saving TOTP secret", "user_id", userID, "error", err)
+		return "", "", envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return secret, totp.URI(issuer, agent.Email.String, secret), nil
+}
+
+// EnableTOTP enables two-factor authentication for the agent after confirming a code of the pending secret,
+// returning the recovery codes.
+func (u *Manager) EnableTOTP(userID int, code string) ([]string, error) {
+	state, err := u.getTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorAlreadyEnabled"), nil)
+	}
+	if !state.Secret.Valid {
+		return nil, envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorNotEnabled"), nil)
+	}
+
+	secret, err := crypto.Decrypt(state.Secret.String, u.encryptionKey)
+	if err != nil {
+		u.lo.Error("error decrypting TOTP secret", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorInvalidCode"), nil)
+	}
+	if _, err := u.q.EnableTOTP.Exec(userID, step); err != nil {
+		u.lo.Error("error enabling TOTP", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+
+	codes, err := u.replaceRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	u.InvalidateAgentCache(userID)
+	return codes, nil
+}
+
+// VerifyTOTP checks a TOTP code or an unused recovery code of the agent. Recovery codes are single use.
+func (u *Manager) VerifyTOTP(userID int, code string) error {
+	state, err := u.getTOTP(userID)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled || !state.Secret.Valid {
+		return envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorNotEnabled"), nil)
+	}
+
+	secret, err := crypto.Decrypt(state.Secret.String, u.encryptionKey)
+	if err != nil {
+		u.lo.Error("error decrypting TOTP secret", "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		res, err := u.q.UpdateTOTPLastStep.Exec(userID, step)
+		if err != nil {
+			u.lo.Error("error updating TOTP last step", "user_id", userID, "error", err)
+			return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		// The code was already used.
+		if n, _ := res.RowsAffected(); n == 0 {
+			return envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorInvalidCode"), nil)
+		}
+		return nil
+	}
+
+	res, err := u.q.UseRecoveryCode.Exec(userID, hashRecoveryCode(code))
+	if err != nil {
+		u.lo.Error("error using recovery code", "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorInvalidCode"), nil)
+	}
+	u.lo.Info("two-factor recovery code used", "user_id", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the agent, returning the new codes.
+func (u *Manager) RegenerateRecoveryCodes(userID int) ([]string, error) {
+	state, err := u.getTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Enabled {
+		return nil, envelope.NewError(envelope.InputError, u.i18n.T("user.twoFactorNotEnabled"), nil)
+	}
+	return u.replaceRecoveryCodes(userID)
+}
+
+// DisableTOTP disables two-factor authentication for the agent, removing the secret and recovery codes.
+func (u *Manager) DisableTOTP(userID int) error {
+	if _, err := u.q.DisableTOTP.Exec(userID); err != nil {
+		u.lo.Error("error disabling TOTP", "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	u.InvalidateAgentCache(userID)
+	return nil
+}
+
+func (u *Manager) getTOTP(userID int) (totpState, error) {
+	var state totpState
+	if err := u.q.GetTOTP.Get(&state, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return state, envelope.NewError(envelope.NotFoundError, u.i18n.T("validation.notFoundUser"), nil)
+		}
+		u.lo.Error("error fetching TOTP state", "user_id", userID, "error", err)
+		return state, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return state, nil
+}
+
+// replaceRecoveryCodes generates new recovery codes for the agent, storing their hashes.
+func (u *Manager) replaceRecoveryCodes(userID int) ([]string, error) {
+	var (
+		codes  = make([]string, 0, recoveryCodeCount)
+		hashes = make([]string, 0, recoveryCodeCount)
+	)
+	for range recoveryCodeCount {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			u.lo.Error("error generating recovery code", "error", err)
+			return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	tx, err := u.db.Beginx()
+	if err != nil {
+		u.lo.Error("error starting transaction", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Stmtx(u.q.DeleteRecoveryCodes).Exec(userID); err != nil {
+		u.lo.Error("error deleting recovery codes", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if _, err := tx.Stmtx(u.q.InsertRecoveryCodes).Exec(userID, pq.Array(hashes)); err != nil {
+		u.lo.Error("error inserting recovery codes", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	if err := tx.Commit(); err != nil {
+		u.lo.Error("error committing recovery codes", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, u.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
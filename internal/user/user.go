@@ -51,12 +51,15 @@ type Manager struct {
 	db           *sqlx.DB
 	agentCache   map[int]models.User
 	agentCacheMu sync.RWMutex
+	// encryptionKey encrypts TOTP secrets.
+	encryptionKey string
 }
 
 // Opts contains options for initializing the Manager.
 type Opts struct {
-	DB *sqlx.DB
-	Lo *logf.Logger
+	DB            *sqlx.DB
+	Lo            *logf.Logger
+	EncryptionKey string
 }
 
 // queries contains prepared SQL queries.
@@ -68,6 +71,7 @@ type queries struct {
 	GetUsersCompact               string     `query:"get-users-compact"`
 	UpdateContact                 *sqlx.Stmt `query:"update-contact"`
 	UpdateContactBasicInfo        *sqlx.Stmt `query:"update-contact-basic-info"`
+	SetRiskFlags                  *sqlx.Stmt `query:"set-risk-flags"`
 	UpdateAgent                   *sqlx.Stmt `query:"update-agent"`
 	UpdateCustomAttributes        *sqlx.Stmt `query:"update-custom-attributes"`
 	UpsertCustomAttributes        *sqlx.Stmt `query:"upsert-custom-attributes"`
@@ -102,6 +106,39 @@ type queries struct {
 	UpdateAPIKeyLastUsed *sqlx.Stmt `query:"update-api-key-last-used"`
 
 	MergeVisitorToContact *sqlx.Stmt `query:"merge-visitor-to-contact"`
+
+	GetContactPreferences    *sqlx.Stmt `query:"get-contact-preferences"`
+	UpsertContactPreferences *sqlx.Stmt `query:"upsert-contact-preferences"`
+
+	// TOTP two-factor authentication queries
+	GetTOTP             *sqlx.Stmt `query:"get-totp"`
+	SetTOTPSecret       *sqlx.Stmt `query:"set-totp-secret"`
+	EnableTOTP          *sqlx.Stmt `query:"enable-totp"`
+	DisableTOTP         *sqlx.Stmt `query:"disable-totp"`
+	UpdateTOTPLastStep  *sqlx.Stmt `query:"update-totp-last-step"`
+	DeleteRecoveryCodes *sqlx.Stmt `query:"delete-recovery-codes"`
+	InsertRecoveryCodes *sqlx.Stmt `query:"insert-recovery-codes"`
+	UseRecoveryCode     *sqlx.Stmt `query:"use-recovery-code"`
+
+	// Agent working hours queries
+	GetAgentSchedule            *sqlx.Stmt `query:"get-agent-schedule"`
+	UpsertAgentSchedule         *sqlx.Stmt `query:"upsert-agent-schedule"`
+	DeleteAgentSchedule         *sqlx.Stmt `query:"delete-agent-schedule"`
+	GetAgentScheduleOverrides   *sqlx.Stmt `query:"get-agent-schedule-overrides"`
+	InsertAgentScheduleOverride *sqlx.Stmt `query:"insert-agent-schedule-override"`
+	DeleteAgentScheduleOverride *sqlx.Stmt `query:"delete-agent-schedule-override"`
+	GetAgentShifts              *sqlx.Stmt `query:"get-agent-shifts"`
+
+	// Keyboard binding queries
+	GetKeyboardBindings    *sqlx.Stmt `query:"get-keyboard-bindings"`
+	DeleteKeyboardBindings *sqlx.Stmt `query:"delete-keyboard-bindings"`
+	InsertKeyboardBindings *sqlx.Stmt `query:"insert-keyboard-bindings"`
+
+	GetAgentNotificationPreferences    *sqlx.Stmt `query:"get-agent-notification-preferences"`
+	UpsertAgentNotificationPreferences *sqlx.Stmt `query:"upsert-agent-notification-preferences"`
+
+	GetAgentSignature    *sqlx.Stmt `query:"get-agent-signature"`
+	UpdateAgentSignature *sqlx.Stmt `query:"update-agent-signature"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -111,11 +148,12 @@ func New(i18n *i18n.I18n, opts Opts) (*Manager, error) {
 		return nil, fmt.Errorf("error scanning SQL file: %w", err)
 	}
 	return &Manager{
-		q:          q,
-		lo:         opts.Lo,
-		i18n:       i18n,
-		db:         opts.DB,
-		agentCache: make(map[int]models.User),
+		q:             q,
+		lo:            opts.Lo,
+		i18n:          i18n,
+		db:            opts.DB,
+		agentCache:    make(map[int]models.User),
+		encryptionKey: opts.EncryptionKey,
 	}, nil
 }
 
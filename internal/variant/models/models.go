@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Variant is an alternative wording of an automated message, picked at random in proportion to its
+// weight each time the message is sent. A variant belongs to either a template or an autoresponder.
+type Variant struct {
+	ID              int       `db:"id" json:"id"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	TemplateID      null.Int  `db:"template_id" json:"template_id"`
+	AutoresponderID null.Int  `db:"autoresponder_id" json:"autoresponder_id"`
+	Name            string    `db:"name" json:"name"`
+	Content         string    `db:"content" json:"content"`
+	// Variants with a zero weight are kept but never sent.
+	Weight int `db:"weight" json:"weight"`
+}
+
+// Stats holds the outcome of the messages sent with a variant.
+type Stats struct {
+	ID     int    `db:"id" json:"id"`
+	Name   string `db:"name" json:"name"`
+	Weight int    `db:"weight" json:"weight"`
+	Sent   int    `db:"sent" json:"sent"`
+	// Conversations in which the contact replied after the variant was sent.
+	Responded    int          `db:"responded" json:"responded"`
+	ResponseRate null.Float64 `db:"response_rate" json:"response_rate"`
+	// CSAT surveys sent with the variant that the contact rated.
+	CSATCompleted      int          `db:"csat_completed" json:"csat_completed"`
+	CSATCompletionRate null.Float64 `db:"csat_completion_rate" json:"csat_completion_rate"`
+}
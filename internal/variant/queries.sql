@@ -0,0 +1,65 @@
+-- name: get-template-variants
+SELECT id, created_at, updated_at, template_id, autoresponder_id, "name", content, weight
+FROM message_variants
+WHERE template_id = $1
+ORDER BY id;
+
+-- name: get-autoresponder-variants
+SELECT id, created_at, updated_at, template_id, autoresponder_id, "name", content, weight
+FROM message_variants
+WHERE autoresponder_id = $1
+ORDER BY id;
+
+-- name: get-template-variants-by-name
+SELECT v.id, v.created_at, v.updated_at, v.template_id, v.autoresponder_id, v."name", v.content, v.weight
+FROM message_variants v
+JOIN templates t ON t.id = v.template_id
+WHERE t."name" = $1
+ORDER BY v.id;
+
+-- name: insert-variant
+INSERT INTO message_variants (template_id, autoresponder_id, "name", content, weight)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at, template_id, autoresponder_id, "name", content, weight;
+
+-- name: update-variant
+UPDATE message_variants
+SET "name" = $4, content = $5, weight = $6, updated_at = NOW()
+WHERE id = $1 AND (template_id = $2 OR autoresponder_id = $3)
+RETURNING id, created_at, updated_at, template_id, autoresponder_id, "name", content, weight;
+
+-- name: delete-variant
+DELETE FROM message_variants
+WHERE id = $1 AND (template_id = $2 OR autoresponder_id = $3);
+
+-- name: insert-send
+INSERT INTO message_variant_sends (variant_id, conversation_id, csat_response_id)
+VALUES ($1, $2, $3);
+
+-- name: get-stats
+SELECT
+    v.id,
+    v."name",
+    v.weight,
+    COUNT(s.id) AS sent,
+    COUNT(s.id) FILTER (WHERE EXISTS (
+        SELECT 1 FROM conversation_messages m
+        WHERE m.conversation_id = s.conversation_id
+            AND m.type = 'incoming'
+            AND m.created_at > s.created_at
+    )) AS responded,
+    ROUND(100.0 * COUNT(s.id) FILTER (WHERE EXISTS (
+        SELECT 1 FROM conversation_messages m
+        WHERE m.conversation_id = s.conversation_id
+            AND m.type = 'incoming'
+            AND m.created_at > s.created_at
+    )) / NULLIF(COUNT(s.id), 0), 1) AS response_rate,
+    COUNT(s.id) FILTER (WHERE cr.rating IS NOT NULL) AS csat_completed,
+    ROUND(100.0 * COUNT(s.id) FILTER (WHERE cr.rating IS NOT NULL)
+        / NULLIF(COUNT(s.id) FILTER (WHERE s.csat_response_id IS NOT NULL), 0), 1) AS csat_completion_rate
+FROM message_variants v
+LEFT JOIN message_variant_sends s ON s.variant_id = v.id AND s.created_at >= NOW() - make_interval(days => $3::INT)
+LEFT JOIN csat_responses cr ON cr.id = s.csat_response_id
+WHERE v.template_id = $1 OR v.autoresponder_id = $2
+GROUP BY v.id
+ORDER BY v.id;
@@ -0,0 +1,182 @@
+// Package variant manages A/B variants of automated messages, such as the CSAT survey request and
+// autoresponders, and reports how contacts responded to each.
+package variant
+
+import (
+	"database/sql"
+	"embed"
+	"math/rand"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/variant/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+// Manager manages message variants.
+type Manager struct {
+	q    queries
+	lo   *logf.Logger
+	i18n *i18n.I18n
+}
+
+// Opts contains options for initializing the variant Manager.
+type Opts struct {
+	DB   *sqlx.DB
+	Lo   *logf.Logger
+	I18n *i18n.I18n
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetTemplateVariants       *sqlx.Stmt `query:"get-template-variants"`
+	GetAutoresponderVariants  *sqlx.Stmt `query:"get-autoresponder-variants"`
+	GetTemplateVariantsByName *sqlx.Stmt `query:"get-template-variants-by-name"`
+	Insert                    *sqlx.Stmt `query:"insert-variant"`
+	Update                    *sqlx.Stmt `query:"update-variant"`
+	Delete                    *sqlx.Stmt `query:"delete-variant"`
+	InsertSend                *sqlx.Stmt `query:"insert-send"`
+	GetStats                  *sqlx.Stmt `query:"get-stats"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:    q,
+		lo:   opts.Lo,
+		i18n: opts.I18n,
+	}, nil
+}
+
+// GetAll returns the variants of a template or, if templateID is zero, of an autoresponder.
+func (m *Manager) GetAll(templateID, autoresponderID int) ([]models.Variant, error) {
+	var (
+		variants = make([]models.Variant, 0)
+		err      error
+	)
+	if templateID > 0 {
+		err = m.q.GetTemplateVariants.Select(&variants, templateID)
+	} else {
+		err = m.q.GetAutoresponderVariants.Select(&variants, autoresponderID)
+	}
+	if err != nil {
+		m.lo.Error("error fetching variants", "template_id", templateID, "autoresponder_id", autoresponderID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return variants, nil
+}
+
+// Create creates a variant of a template or an autoresponder.
+func (m *Manager) Create(v models.Variant) (models.Variant, error) {
+	var result models.Variant
+	if err := m.q.Insert.Get(&result, v.TemplateID, v.AutoresponderID, v.Name, v.Content, v.Weight); err != nil {
+		if dbutil.IsForeignKeyError(err) {
+			return result, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+		m.lo.Error("error inserting variant", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Update updates a variant of a template or an autoresponder.
+func (m *Manager) Update(id int, v models.Variant) (models.Variant, error) {
+	var result models.Variant
+	if err := m.q.Update.Get(&result, id, v.TemplateID, v.AutoresponderID, v.Name, v.Content, v.Weight); err != nil {
+		if err == sql.ErrNoRows {
+			return result, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error updating variant", "id", id, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Delete deletes a variant of a template or, if templateID is zero, of an autoresponder.
+func (m *Manager) Delete(id, templateID, autoresponderID int) error {
+	if _, err := m.q.Delete.Exec(id, templateID, autoresponderID); err != nil {
+		m.lo.Error("error deleting variant", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+// GetStats returns the outcome of the messages sent with each variant of a template or, if templateID
+// is zero, of an autoresponder over the last days.
+func (m *Manager) GetStats(templateID, autoresponderID, days int) ([]models.Stats, error) {
+	var stats = make([]models.Stats, 0)
+	if err := m.q.GetStats.Select(&stats, templateID, autoresponderID, days); err != nil {
+		m.lo.Error("error fetching variant stats", "template_id", templateID, "autoresponder_id", autoresponderID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return stats, nil
+}
+
+// PickTemplateVariant picks a variant of the named template by weight. Returns false if the
+// template has no variant with a positive weight, in which case the template body is sent.
+func (m *Manager) PickTemplateVariant(name string) (models.Variant, bool) {
+	var variants []models.Variant
+	if err := m.q.GetTemplateVariantsByName.Select(&variants, name); err != nil {
+		m.lo.Error("error fetching template variants", "name", name, "error", err)
+		return models.Variant{}, false
+	}
+	return pick(variants, rand.Intn)
+}
+
+// PickAutoresponderVariant picks a variant of an autoresponder by weight. Returns false if the
+// autoresponder has no variant with a positive weight, in which case its content is sent.
+func (m *Manager) PickAutoresponderVariant(autoresponderID int) (models.Variant, bool) {
+	var variants []models.Variant
+	if err := m.q.GetAutoresponderVariants.Select(&variants, autoresponderID); err != nil {
+		m.lo.Error("error fetching autoresponder variants", "autoresponder_id", autoresponderID, "error", err)
+		return models.Variant{}, false
+	}
+	return pick(variants, rand.Intn)
+}
+
+// RecordSend records that a variant was sent to a conversation, along with the CSAT survey it
+// requested, if any.
+func (m *Manager) RecordSend(variantID, conversationID int, csatResponseID null.Int) error {
+	if _, err := m.q.InsertSend.Exec(variantID, conversationID, csatResponseID); err != nil {
+		m.lo.Error("error recording variant send", "variant_id", variantID, "conversation_id", conversationID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// pick returns a variant chosen with probability proportional to its weight, using intn to draw
+// a random number in [0, n).
+func pick(variants []models.Variant, intn func(n int) int) (models.Variant, bool) {
+	total := 0
+	for _, v := range variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	if total == 0 {
+		return models.Variant{}, false
+	}
+	n := intn(total)
+	for _, v := range variants {
+		if v.Weight <= 0 {
+			continue
+		}
+		if n < v.Weight {
+			return v, true
+		}
+		n -= v.Weight
+	}
+	return models.Variant{}, false
+}
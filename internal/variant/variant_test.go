@@ -0,0 +1,29 @@
+package variant
+
+import (
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/variant/models"
+)
+
+func TestPick(t *testing.T) {
+	variants := []models.Variant{
+		{ID: 1, Weight: 1},
+		{ID: 2, Weight: 0},
+		{ID: 3, Weight: 3},
+	}
+	want := map[int]int{0: 1, 1: 3, 2: 3, 3: 3}
+	for n, id := range want {
+		v, ok := pick(variants, func(int) int { return n })
+		if !ok || v.ID != id {
+			t.Errorf("pick with n=%d = %d, %v; want %d", n, v.ID, ok, id)
+		}
+	}
+
+	if _, ok := pick([]models.Variant{{ID: 1, Weight: 0}}, func(int) int { return 0 }); ok {
+		t.Error("pick returned a variant with zero weight")
+	}
+	if _, ok := pick(nil, func(int) int { return 0 }); ok {
+		t.Error("pick returned a variant from an empty list")
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 )
 
@@ -21,4 +22,39 @@ type View struct {
 	Visibility string          `db:"visibility" json:"visibility"`
 	UserID     *int            `db:"user_id" json:"user_id,omitempty"`
 	TeamID     *int            `db:"team_id" json:"team_id,omitempty"`
+	// Preference is the requesting agent's preference for the view, if any.
+	Preference *Preference `db:"-" json:"preference,omitempty"`
+}
+
+// Keys of the built-in conversation lists, the lists of a team and of a view are keyed by
+// "team_unassigned:<team id>" and "view:<view id>".
+const (
+	ListAll            = "all"
+	ListAssigned       = "assigned"
+	ListUnassigned     = "unassigned"
+	ListMentioned      = "mentioned"
+	ListTeamUnassigned = "team_unassigned"
+	ListView           = "view"
+)
+
+// ListKey returns the key of the list of the given team or view.
+func ListKey(list string, id int) string {
+	return list + ":" + strconv.Itoa(id)
+}
+
+// SortFields are the conversation fields a list can be sorted by.
+var SortFields = []string{
+	"conversations.last_message_at",
+	"conversations.created_at",
+	"conversations.waiting_since",
+	"conversations.next_sla_deadline_at",
+	"conversations.priority_id",
+}
+
+// Preference is an agent's sort and page size of a conversation list.
+type Preference struct {
+	ViewKey  string `db:"view_key" json:"view_key"`
+	OrderBy  string `db:"order_by" json:"order_by"`
+	Order    string `db:"sort_order" json:"order"`
+	PageSize int    `db:"page_size" json:"page_size"`
 }
@@ -36,3 +36,19 @@ UPDATE views
 SET name = $2, filters = $3, visibility = $4, user_id = $5, team_id = $6, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: get-view-preferences
+SELECT view_key, order_by, sort_order, page_size
+FROM view_preferences WHERE user_id = $1
+ORDER BY view_key;
+
+-- name: get-view-preference
+SELECT view_key, order_by, sort_order, page_size
+FROM view_preferences WHERE user_id = $1 AND view_key = $2;
+
+-- name: upsert-view-preference
+INSERT INTO view_preferences (user_id, view_key, order_by, sort_order, page_size)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, view_key) DO UPDATE
+SET order_by = EXCLUDED.order_by, sort_order = EXCLUDED.sort_order, page_size = EXCLUDED.page_size, updated_at = NOW()
+RETURNING view_key, order_by, sort_order, page_size;
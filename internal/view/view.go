@@ -4,6 +4,9 @@ package view
 import (
 	"database/sql"
 	"embed"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
@@ -17,8 +20,14 @@ import (
 var (
 	//go:embed queries.sql
 	efs embed.FS
+
+	// reListKey matches the keys of conversation lists preferences can be saved for.
+	reListKey = regexp.MustCompile(`^(all|assigned|unassigned|mentioned|(team_unassigned|view):[1-9][0-9]*)$`)
 )
 
+// maxPageSize is the largest page size a preference can save.
+const maxPageSize = 100
+
 type Manager struct {
 	q    queries
 	lo   *logf.Logger
@@ -41,6 +50,9 @@ type queries struct {
 	InsertView            *sqlx.Stmt `query:"insert-view"`
 	DeleteView            *sqlx.Stmt `query:"delete-view"`
 	UpdateView            *sqlx.Stmt `query:"update-view"`
+	GetViewPreferences    *sqlx.Stmt `query:"get-view-preferences"`
+	GetViewPreference     *sqlx.Stmt `query:"get-view-preference"`
+	UpsertViewPreference  *sqlx.Stmt `query:"upsert-view-preference"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -147,3 +159,63 @@ func (v *Manager) Delete(id int) error {
 	}
 	return nil
 }
+
+// GetPreferences returns the conversation list preferences of a user.
+func (v *Manager) GetPreferences(userID int) ([]models.Preference, error) {
+	prefs := make([]models.Preference, 0)
+	if err := v.q.GetViewPreferences.Select(&prefs, userID); err != nil {
+		v.lo.Error("error fetching view preferences", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, v.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return prefs, nil
+}
+
+// GetPreference returns the preference of a user for a conversation list, the zero preference is
+// returned if none is saved.
+func (v *Manager) GetPreference(userID int, key string) (models.Preference, error) {
+	var pref models.Preference
+	if err := v.q.GetViewPreference.Get(&pref, userID, key); err != nil {
+		if err == sql.ErrNoRows {
+			return models.Preference{ViewKey: key}, nil
+		}
+		v.lo.Error("error fetching view preference", "user_id", userID, "view_key", key, "error", err)
+		return pref, envelope.NewError(envelope.GeneralError, v.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return pref, nil
+}
+
+// AttachPreferences sets the preference of a user on each of the views that has one.
+func (v *Manager) AttachPreferences(userID int, views []models.View) error {
+	prefs, err := v.GetPreferences(userID)
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]models.Preference, len(prefs))
+	for _, p := range prefs {
+		byKey[p.ViewKey] = p
+	}
+	for i := range views {
+		if p, ok := byKey[models.ListKey(models.ListView, views[i].ID)]; ok {
+			views[i].Preference = &p
+		}
+	}
+	return nil
+}
+
+// UpdatePreference saves the preference of a user for a conversation list.
+func (v *Manager) UpdatePreference(userID int, pref models.Preference) (models.Preference, error) {
+	pref.Order = strings.ToLower(pref.Order)
+	if !reListKey.MatchString(pref.ViewKey) ||
+		(pref.OrderBy != "" && !slices.Contains(models.SortFields, pref.OrderBy)) ||
+		(pref.Order != "" && pref.Order != "asc" && pref.Order != "desc") ||
+		pref.PageSize < 0 || pref.PageSize > maxPageSize {
+		return models.Preference{}, envelope.NewError(envelope.InputError, v.i18n.T("validation.invalidValue"), nil)
+	}
+
+	var saved models.Preference
+	if err := v.q.UpsertViewPreference.Get(&saved, userID, pref.ViewKey, pref.OrderBy, pref.Order, pref.PageSize); err != nil {
+		v.lo.Error("error saving view preference", "user_id", userID, "view_key", pref.ViewKey, "error", err)
+		return models.Preference{}, envelope.NewError(envelope.GeneralError, v.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return saved, nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+// Agent is an agent due for a digest of activity on the conversations they watch.
+type Agent struct {
+	ID                int       `db:"id" json:"id"`
+	FirstName         string    `db:"first_name" json:"first_name"`
+	LastName          string    `db:"last_name" json:"last_name"`
+	Email             string    `db:"email" json:"email"`
+	WatchDigestHours  int       `db:"watch_digest_hours" json:"watch_digest_hours"`
+	WatchDigestSentAt null.Time `db:"watch_digest_sent_at" json:"watch_digest_sent_at"`
+}
+
+// Conversation is a watched conversation with activity listed in a digest.
+type Conversation struct {
+	UUID            string    `db:"uuid" json:"uuid"`
+	ReferenceNumber string    `db:"reference_number" json:"reference_number"`
+	Subject         string    `db:"subject" json:"subject"`
+	Status          string    `db:"status" json:"status"`
+	NewMessages     int       `db:"new_messages" json:"new_messages"`
+	LastActivityAt  time.Time `db:"last_activity_at" json:"last_activity_at"`
+}
@@ -0,0 +1,33 @@
+-- name: get-due-agents
+-- Agents with the digest turned on that have not been sent one within their chosen hours.
+SELECT u.id, u.first_name, COALESCE(u.last_name, '') AS last_name, u.email, p.watch_digest_hours, p.watch_digest_sent_at
+FROM agent_notification_preferences p
+JOIN users u ON u.id = p.user_id
+WHERE u.type = 'agent'
+    AND u.enabled
+    AND u.deleted_at IS NULL
+    AND COALESCE(u.email, '') <> ''
+    AND p.watch_digest_hours > 0
+    AND (p.watch_digest_sent_at IS NULL OR p.watch_digest_sent_at < NOW() - p.watch_digest_hours * INTERVAL '1 hour')
+ORDER BY u.id
+LIMIT $1;
+
+-- name: get-watched-conversations
+-- Conversations the agent participates in but is not assigned to, with messages from others since $2.
+SELECT c.uuid, c.reference_number, COALESCE(c.subject, '') AS subject, s.name AS status,
+    COUNT(m.id) AS new_messages, MAX(m.created_at) AS last_activity_at
+FROM conversation_participants p
+JOIN conversations c ON c.id = p.conversation_id
+JOIN conversation_statuses s ON s.id = c.status_id
+JOIN conversation_messages m ON m.conversation_id = c.id
+WHERE p.user_id = $1
+    AND c.assigned_user_id IS DISTINCT FROM $1
+    AND m.created_at > $2
+    AND m.sender_id <> $1
+    AND m.type <> 'activity'
+GROUP BY c.id, s.name
+ORDER BY last_activity_at DESC
+LIMIT $3;
+
+-- name: mark-digest-sent
+UPDATE agent_notification_preferences SET watch_digest_sent_at = NOW() WHERE user_id = $1;
@@ -0,0 +1,171 @@
+// Package watchdigest periodically emails agents a digest of activity on the conversations they watch.
+//
+// An agent watches the conversations they participate in, e.g. by replying or adding a note, while
+// not being assigned to them. Agents choose how often they receive the digest in their notification preferences.
+package watchdigest
+
+import (
+	"context"
+	"embed"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/watch_digest/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// TmplDigest is the name of the stored email template of the digest.
+	TmplDigest = "Watched conversations digest"
+
+	// agentsPerRun caps the digests sent per run, the rest are sent in later runs.
+	agentsPerRun = 500
+	// maxConversations is the number of most recently active conversations listed per digest.
+	maxConversations = 50
+)
+
+type templateStore interface {
+	RenderStoredEmailTemplate(name string, data any) (string, string, error)
+}
+
+type notifierStore interface {
+	Send(message notifier.Message) error
+}
+
+type maintenanceStore interface {
+	InWindow(at time.Time) bool
+}
+
+// Manager sends digests of activity on watched conversations to agents.
+type Manager struct {
+	q           queries
+	lo          *logf.Logger
+	template    templateStore
+	notifier    notifierStore
+	maintenance maintenanceStore
+}
+
+// Opts contains options for initializing the watch digest Manager.
+type Opts struct {
+	DB       *sqlx.DB
+	Lo       *logf.Logger
+	Template templateStore
+	Notifier notifierStore
+	// Maintenance defers digests while a maintenance window is in progress.
+	Maintenance maintenanceStore
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetDueAgents            *sqlx.Stmt `query:"get-due-agents"`
+	GetWatchedConversations *sqlx.Stmt `query:"get-watched-conversations"`
+	MarkDigestSent          *sqlx.Stmt `query:"mark-digest-sent"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:           q,
+		lo:          opts.Lo,
+		template:    opts.Template,
+		notifier:    opts.Notifier,
+		maintenance: opts.Maintenance,
+	}, nil
+}
+
+// Run periodically sends digests to agents that are due one.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.sendDue(ctx); err != nil {
+				m.lo.Error("error sending watch digests", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends a digest to every agent whose chosen hours have passed since their last digest.
+// Agents are marked as sent even when their watched conversations had no activity, so that the next
+// digest covers the following period. Nothing is sent during a maintenance window.
+func (m *Manager) sendDue(ctx context.Context) error {
+	if m.maintenance != nil && m.maintenance.InWindow(time.Now()) {
+		return nil
+	}
+
+	var agents []models.Agent
+	if err := m.q.GetDueAgents.SelectContext(ctx, &agents, agentsPerRun); err != nil {
+		return err
+	}
+
+	var sent int
+	for _, agent := range agents {
+		if ctx.Err() != nil {
+			return nil
+		}
+		ok, err := m.send(ctx, agent)
+		if err != nil {
+			m.lo.Error("error sending watch digest", "user_id", agent.ID, "error", err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+		if _, err := m.q.MarkDigestSent.ExecContext(ctx, agent.ID); err != nil {
+			return err
+		}
+	}
+	if sent > 0 {
+		m.lo.Info("sent watch digests", "count", sent)
+	}
+	return nil
+}
+
+// send emails the agent a digest of the activity since their last digest, reporting whether one was sent.
+func (m *Manager) send(ctx context.Context, agent models.Agent) (bool, error) {
+	since := agent.WatchDigestSentAt.Time
+	if !agent.WatchDigestSentAt.Valid {
+		since = time.Now().Add(-time.Duration(agent.WatchDigestHours) * time.Hour)
+	}
+
+	var conversations []models.Conversation
+	if err := m.q.GetWatchedConversations.SelectContext(ctx, &conversations, agent.ID, since, maxConversations); err != nil {
+		return false, err
+	}
+	if len(conversations) == 0 {
+		return false, nil
+	}
+
+	content, subject, err := m.template.RenderStoredEmailTemplate(TmplDigest, map[string]any{
+		"Agent":         agent,
+		"Conversations": conversations,
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := m.notifier.Send(notifier.Message{
+		RecipientEmails: []string{agent.Email},
+		Subject:         subject,
+		Content:         content,
+		Provider:        notifier.ProviderEmail,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Field types.
+const (
+	FieldTypeText     = "text"
+	FieldTypeTextarea = "textarea"
+	FieldTypeSelect   = "select"
+)
+
+// Form is an embeddable contact form whose submissions create conversations in an inbox.
+type Form struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	UUID      string    `db:"uuid" json:"uuid"`
+	Name      string    `db:"name" json:"name"`
+	InboxID   int       `db:"inbox_id" json:"inbox_id"`
+	Enabled   bool      `db:"enabled" json:"enabled"`
+	// Fields are asked in addition to the built-in name, email, subject and message fields.
+	Fields           Fields `db:"fields" json:"fields"`
+	AllowAttachments bool   `db:"allow_attachments" json:"allow_attachments"`
+	SuccessMessage   string `db:"success_message" json:"success_message"`
+	// TrustedDomains may embed the form in an iframe, any domain may when empty.
+	TrustedDomains pq.StringArray `db:"trusted_domains" json:"trusted_domains"`
+}
+
+// Field is a custom field of a form.
+type Field struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// Fields is a list of form fields stored as JSONB.
+type Fields []Field
+
+// Value implements driver.Valuer.
+func (f Fields) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements sql.Scanner.
+func (f *Fields) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported type %T for form fields", src)
+	}
+	return json.Unmarshal(b, f)
+}
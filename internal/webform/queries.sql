@@ -0,0 +1,23 @@
+-- name: get-forms
+SELECT id, created_at, updated_at, uuid, name, inbox_id, enabled, fields, allow_attachments, success_message, trusted_domains
+FROM web_forms
+ORDER BY name;
+
+-- name: get-form
+SELECT id, created_at, updated_at, uuid, name, inbox_id, enabled, fields, allow_attachments, success_message, trusted_domains
+FROM web_forms
+WHERE ($1 > 0 AND id = $1) OR ($2 != '' AND uuid::TEXT = $2);
+
+-- name: insert-form
+INSERT INTO web_forms (name, inbox_id, enabled, fields, allow_attachments, success_message, trusted_domains)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at, updated_at, uuid, name, inbox_id, enabled, fields, allow_attachments, success_message, trusted_domains;
+
+-- name: update-form
+UPDATE web_forms
+SET name = $2, inbox_id = $3, enabled = $4, fields = $5, allow_attachments = $6, success_message = $7, trusted_domains = $8, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, uuid, name, inbox_id, enabled, fields, allow_attachments, success_message, trusted_domains;
+
+-- name: delete-form
+DELETE FROM web_forms WHERE id = $1;
@@ -0,0 +1,235 @@
+// Package webform manages embeddable contact forms whose submissions create conversations.
+package webform
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/webform/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/go-i18n"
+	"github.com/lib/pq"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+
+	fieldKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+	// trustedDomainRe matches a host with an optional leading wildcard label and port,
+	// e.g. example.com, *.example.com, sub.example.com or example.com:8080.
+	trustedDomainRe = regexp.MustCompile(`(?i)^(\*\.)?[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(:[0-9]{1,5})?$`)
+
+	// reservedKeys are the names of the built-in and honeypot inputs of the form page.
+	reservedKeys = []string{"name", "email", "subject", "message", "attachments", "website"}
+
+	// ErrRequired is returned when a required field of a submission is empty.
+	ErrRequired = errors.New("required field is empty")
+	// ErrInvalidOption is returned when a select field of a submission is not one of its options.
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// FieldError is returned by FormatFields for an invalid field value.
+type FieldError struct {
+	Field models.Field
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Err.Error() + ": " + e.Field.Label }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+const (
+	maxFields      = 20
+	maxNameLength  = 140
+	maxSuccessLen  = 1000
+	maxFieldLength = 5000
+)
+
+// Manager manages web forms.
+type Manager struct {
+	q    queries
+	lo   *logf.Logger
+	i18n *i18n.I18n
+}
+
+// Opts contains options for initializing the web form Manager.
+type Opts struct {
+	DB   *sqlx.DB
+	Lo   *logf.Logger
+	I18n *i18n.I18n
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAll *sqlx.Stmt `query:"get-forms"`
+	Get    *sqlx.Stmt `query:"get-form"`
+	Insert *sqlx.Stmt `query:"insert-form"`
+	Update *sqlx.Stmt `query:"update-form"`
+	Delete *sqlx.Stmt `query:"delete-form"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		q:    q,
+		lo:   opts.Lo,
+		i18n: opts.I18n,
+	}, nil
+}
+
+// GetAll returns all web forms.
+func (m *Manager) GetAll() ([]models.Form, error) {
+	var forms = make([]models.Form, 0)
+	if err := m.q.GetAll.Select(&forms); err != nil {
+		m.lo.Error("error fetching web forms", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return forms, nil
+}
+
+// Get returns a web form by ID.
+func (m *Manager) Get(id int) (models.Form, error) {
+	return m.get(id, "")
+}
+
+// GetByUUID returns a web form by its public UUID.
+func (m *Manager) GetByUUID(uuid string) (models.Form, error) {
+	return m.get(0, uuid)
+}
+
+func (m *Manager) get(id int, uuid string) (models.Form, error) {
+	var form models.Form
+	if err := m.q.Get.Get(&form, id, uuid); err != nil {
+		if err == sql.ErrNoRows {
+			return form, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		m.lo.Error("error fetching web form", "id", id, "uuid", uuid, "error", err)
+		return form, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return form, nil
+}
+
+// Create creates a new web form.
+func (m *Manager) Create(f models.Form) (models.Form, error) {
+	if err := m.validate(f); err != nil {
+		return models.Form{}, err
+	}
+	var result models.Form
+	if err := m.q.Insert.Get(&result, f.Name, f.InboxID, f.Enabled, f.Fields, f.AllowAttachments, f.SuccessMessage, pq.Array(f.TrustedDomains)); err != nil {
+		if dbutil.IsForeignKeyError(err) {
+			return result, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+		m.lo.Error("error inserting web form", "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Update updates a web form.
+func (m *Manager) Update(id int, f models.Form) (models.Form, error) {
+	if err := m.validate(f); err != nil {
+		return models.Form{}, err
+	}
+	var result models.Form
+	if err := m.q.Update.Get(&result, id, f.Name, f.InboxID, f.Enabled, f.Fields, f.AllowAttachments, f.SuccessMessage, pq.Array(f.TrustedDomains)); err != nil {
+		if err == sql.ErrNoRows {
+			return result, envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
+		}
+		if dbutil.IsForeignKeyError(err) {
+			return result, envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+		m.lo.Error("error updating web form", "id", id, "error", err)
+		return result, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return result, nil
+}
+
+// Delete deletes a web form.
+func (m *Manager) Delete(id int) error {
+	if _, err := m.q.Delete.Exec(id); err != nil {
+		m.lo.Error("error deleting web form", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
+	}
+	return nil
+}
+
+func (m *Manager) validate(f models.Form) error {
+	if strings.TrimSpace(f.Name) == "" {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.empty", "name", "`name`"), nil)
+	}
+	if len(f.Name) > maxNameLength {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.maxLength", "name", "`name`", "max", fmt.Sprint(maxNameLength)), nil)
+	}
+	if len(f.SuccessMessage) > maxSuccessLen {
+		return envelope.NewError(envelope.InputError, m.i18n.Ts("globals.messages.maxLength", "name", "`success_message`", "max", fmt.Sprint(maxSuccessLen)), nil)
+	}
+	if f.InboxID <= 0 || len(f.Fields) > maxFields {
+		return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+	}
+	// Trusted domains end up in the frame-ancestors policy of the form page.
+	for _, d := range f.TrustedDomains {
+		if !ValidTrustedDomain(d) {
+			return envelope.NewError(envelope.InputError, m.i18n.Ts("validation.invalidDomain", "domain", d), nil)
+		}
+	}
+	keys := make(map[string]bool, len(f.Fields))
+	for _, field := range f.Fields {
+		if !fieldKeyRe.MatchString(field.Key) || slices.Contains(reservedKeys, field.Key) || keys[field.Key] || strings.TrimSpace(field.Label) == "" {
+			return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+		keys[field.Key] = true
+		switch field.Type {
+		case models.FieldTypeText, models.FieldTypeTextarea:
+		case models.FieldTypeSelect:
+			if len(field.Options) == 0 {
+				return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+			}
+		default:
+			return envelope.NewError(envelope.InputError, m.i18n.T("validation.invalidValue"), nil)
+		}
+	}
+	return nil
+}
+
+// ValidTrustedDomain returns true if d is a host source that can be put in the frame-ancestors
+// policy of the form page as is.
+func ValidTrustedDomain(d string) bool {
+	return len(d) <= 253 && trustedDomainRe.MatchString(d)
+}
+
+// FormatFields validates the custom field values of a submission and returns them as
+// "Label: value" lines in field order. Values are keyed by field key.
+func FormatFields(form models.Form, values map[string]string) (string, error) {
+	var sb strings.Builder
+	for _, field := range form.Fields {
+		value := strings.TrimSpace(values[field.Key])
+		if r := []rune(value); len(r) > maxFieldLength {
+			value = string(r[:maxFieldLength])
+		}
+		if value == "" {
+			if field.Required {
+				return "", &FieldError{Field: field, Err: ErrRequired}
+			}
+			continue
+		}
+		if field.Type == models.FieldTypeSelect && !slices.Contains(field.Options, value) {
+			return "", &FieldError{Field: field, Err: ErrInvalidOption}
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", field.Label, value)
+	}
+	return sb.String(), nil
+}
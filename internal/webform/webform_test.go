@@ -0,0 +1,107 @@
+package webform
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/webform/models"
+	"github.com/knadh/go-i18n"
+)
+
+func TestValidate(t *testing.T) {
+	i, err := i18n.New([]byte(`{"_.code": "en", "_.name": "English"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{i18n: i}
+
+	valid := func(edit func(f *models.Form)) models.Form {
+		f := models.Form{
+			Name:           "Support",
+			InboxID:        1,
+			Fields:         models.Fields{{Key: "order_id", Label: "Order", Type: models.FieldTypeText}},
+			TrustedDomains: []string{"example.com"},
+		}
+		if edit != nil {
+			edit(&f)
+		}
+		return f
+	}
+	manyFields := make(models.Fields, maxFields+1)
+	for n := range manyFields {
+		manyFields[n] = models.Field{Key: "f" + strings.Repeat("x", n), Label: "F", Type: models.FieldTypeText}
+	}
+
+	tests := []struct {
+		name    string
+		form    models.Form
+		wantErr bool
+	}{
+		{"valid", valid(nil), false},
+		{"empty name", valid(func(f *models.Form) { f.Name = " " }), true},
+		{"long name", valid(func(f *models.Form) { f.Name = strings.Repeat("a", maxNameLength+1) }), true},
+		{"long success message", valid(func(f *models.Form) { f.SuccessMessage = strings.Repeat("a", maxSuccessLen+1) }), true},
+		{"no inbox", valid(func(f *models.Form) { f.InboxID = 0 }), true},
+		{"too many fields", valid(func(f *models.Form) { f.Fields = manyFields }), true},
+		{"honeypot key", valid(func(f *models.Form) { f.Fields[0].Key = "website" }), true},
+		{"built-in key", valid(func(f *models.Form) { f.Fields[0].Key = "email" }), true},
+		{"invalid key", valid(func(f *models.Form) { f.Fields[0].Key = "Order ID" }), true},
+		{"duplicate key", valid(func(f *models.Form) { f.Fields = append(f.Fields, f.Fields[0]) }), true},
+		{"empty label", valid(func(f *models.Form) { f.Fields[0].Label = "" }), true},
+		{"unknown type", valid(func(f *models.Form) { f.Fields[0].Type = "file" }), true},
+		{"select without options", valid(func(f *models.Form) { f.Fields[0].Type = models.FieldTypeSelect }), true},
+		{"select", valid(func(f *models.Form) { f.Fields[0].Type, f.Fields[0].Options = models.FieldTypeSelect, []string{"a"} }), false},
+		{"no trusted domains", valid(func(f *models.Form) { f.TrustedDomains = nil }), false},
+		{"wildcard domain", valid(func(f *models.Form) { f.TrustedDomains = []string{"*.example.com", "shop.example.co.uk:8443"} }), false},
+		{"empty domain", valid(func(f *models.Form) { f.TrustedDomains = []string{""} }), true},
+		{"domain with scheme", valid(func(f *models.Form) { f.TrustedDomains = []string{"https://example.com"} }), true},
+		{"domain with path", valid(func(f *models.Form) { f.TrustedDomains = []string{"example.com/page"} }), true},
+		{"wildcard only", valid(func(f *models.Form) { f.TrustedDomains = []string{"*"} }), true},
+		{"wildcard inside", valid(func(f *models.Form) { f.TrustedDomains = []string{"shop.*.com"} }), true},
+		{"policy keyword", valid(func(f *models.Form) { f.TrustedDomains = []string{"'none'"} }), true},
+		{"policy injection", valid(func(f *models.Form) { f.TrustedDomains = []string{"example.com; script-src *"} }), true},
+		{"header injection", valid(func(f *models.Form) { f.TrustedDomains = []string{"example.com\r\nSet-Cookie: a=b"} }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.validate(tt.form); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatFields(t *testing.T) {
+	form := models.Form{Fields: models.Fields{
+		{Key: "order_id", Label: "Order", Type: models.FieldTypeText, Required: true},
+		{Key: "topic", Label: "Topic", Type: models.FieldTypeSelect, Options: []string{"Billing", "Shipping"}},
+		{Key: "notes", Label: "Notes", Type: models.FieldTypeTextarea},
+	}}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    string
+		wantErr error
+	}{
+		{"required only", map[string]string{"order_id": " 42 "}, "Order: 42\n", nil},
+		{"all fields in field order", map[string]string{"notes": "Fragile", "topic": "Billing", "order_id": "42"}, "Order: 42\nTopic: Billing\nNotes: Fragile\n", nil},
+		{"unknown keys ignored", map[string]string{"order_id": "42", "website": "spam"}, "Order: 42\n", nil},
+		{"required missing", map[string]string{"topic": "Billing"}, "", ErrRequired},
+		{"required blank", map[string]string{"order_id": "  "}, "", ErrRequired},
+		{"option not allowed", map[string]string{"order_id": "42", "topic": "Refunds"}, "", ErrInvalidOption},
+		{"long value truncated", map[string]string{"order_id": "42", "notes": strings.Repeat("é", maxFieldLength+10)}, "Order: 42\nNotes: " + strings.Repeat("é", maxFieldLength) + "\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatFields(form, tt.values)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FormatFields() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatFields() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zerodha/logf"
+)
+
+func TestCall(t *testing.T) {
+	type request struct {
+		contentType string
+		body        string
+	}
+	received := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received <- request{contentType: r.Header.Get("Content-Type"), body: string(b)}
+	}))
+	defer srv.Close()
+
+	lo := logf.New(logf.Opts{Level: logf.ErrorLevel})
+	m := &Manager{
+		lo:            &lo,
+		deliveryQueue: make(chan DeliveryTask, 1),
+		httpClient:    srv.Client(),
+		workers:       1,
+	}
+	m.Run(context.Background())
+
+	m.Call(srv.URL, []byte(`{"ok":true}`))
+	m.Close()
+
+	select {
+	case got := <-received:
+		if got.contentType != "application/json" || got.body != `{"ok":true}` {
+			t.Errorf("received %+v", got)
+		}
+	default:
+		t.Fatal("call was not delivered")
+	}
+
+	// Calls after closing are dropped instead of panicking on the closed queue.
+	m.Call(srv.URL, []byte(`{}`))
+}
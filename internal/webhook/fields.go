@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// filterFields returns the JSON representation of an event payload with only the fields at the
+// include paths, or all fields if there are none, minus the fields at the exclude paths. Paths are
+// dot separated object keys and apply to every element of the arrays they cross.
+func filterFields(payload any, include, exclude []string) (any, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	if len(include) > 0 {
+		paths := make([][]string, 0, len(include))
+		for _, p := range include {
+			paths = append(paths, strings.Split(p, "."))
+		}
+		v = pickPaths(v, paths)
+	}
+	for _, p := range exclude {
+		dropPath(v, strings.Split(p, "."))
+	}
+	return v, nil
+}
+
+// pickPaths returns a copy of v with only the values at the paths.
+func pickPaths(v any, paths [][]string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		// Group the remainders of the paths by their first key, a nil remainder keeps the whole value.
+		rest := make(map[string][][]string)
+		for _, p := range paths {
+			if r, ok := rest[p[0]]; ok && r == nil {
+				continue
+			}
+			if len(p) == 1 {
+				rest[p[0]] = nil
+				continue
+			}
+			rest[p[0]] = append(rest[p[0]], p[1:])
+		}
+		out := make(map[string]any, len(rest))
+		for key, r := range rest {
+			val, ok := t[key]
+			if !ok {
+				continue
+			}
+			if r == nil {
+				out[key] = val
+			} else {
+				out[key] = pickPaths(val, r)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = pickPaths(e, paths)
+		}
+		return out
+	}
+	return v
+}
+
+// dropPath removes the value at the path from v.
+func dropPath(v any, path []string) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			delete(t, path[0])
+			return
+		}
+		dropPath(t[path[0]], path[1:])
+	case []any:
+		for _, e := range t {
+			dropPath(e, path)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilterFields(t *testing.T) {
+	payload := map[string]any{
+		"uuid":    "abc",
+		"content": "secret",
+		"contact": map[string]any{"email": "a@example.com", "first_name": "A"},
+		"attachments": []any{
+			map[string]any{"name": "a.pdf", "url": "https://x"},
+			map[string]any{"name": "b.pdf", "url": "https://y"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             string
+	}{
+		{"none", nil, nil, `{"attachments":[{"name":"a.pdf","url":"https://x"},{"name":"b.pdf","url":"https://y"}],"contact":{"email":"a@example.com","first_name":"A"},"content":"secret","uuid":"abc"}`},
+		{"exclude", nil, []string{"content", "contact.email", "attachments.url"}, `{"attachments":[{"name":"a.pdf"},{"name":"b.pdf"}],"contact":{"first_name":"A"},"uuid":"abc"}`},
+		{"include", []string{"uuid", "contact.email", "attachments.name", "missing"}, nil, `{"attachments":[{"name":"a.pdf"},{"name":"b.pdf"}],"contact":{"email":"a@example.com"},"uuid":"abc"}`},
+		{"include whole and nested", []string{"contact", "contact.email"}, nil, `{"contact":{"email":"a@example.com","first_name":"A"}}`},
+		{"include and exclude", []string{"contact"}, []string{"contact.email"}, `{"contact":{"first_name":"A"}}`},
+	}
+	for _, tt := range tests {
+		got, err := filterFields(payload, tt.include, tt.exclude)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		b, _ := json.Marshal(got)
+		if string(b) != tt.want {
+			t.Errorf("%s: filterFields() = %s, want %s", tt.name, b, tt.want)
+		}
+	}
+}
@@ -16,6 +16,11 @@ type Webhook struct {
 	Events    pq.StringArray `db:"events" json:"events"`
 	Secret    string         `db:"secret" json:"secret"`
 	IsActive  bool           `db:"is_active" json:"is_active"`
+
+	// Dot separated paths of the event payload fields sent to the endpoint, e.g. `contact.email`.
+	// All fields are sent if IncludeFields is empty, ExcludeFields are then removed.
+	IncludeFields pq.StringArray `db:"include_fields" json:"include_fields"`
+	ExcludeFields pq.StringArray `db:"exclude_fields" json:"exclude_fields"`
 }
 
 // WebhookEvent represents an event that can trigger a webhook
@@ -29,10 +34,18 @@ const (
 	EventConversationAssigned      WebhookEvent = "conversation.assigned"
 	EventConversationUnassigned    WebhookEvent = "conversation.unassigned"
 
+	// Contact events
+	EventContactMerged WebhookEvent = "contact.merged"
+
 	// Message events
 	EventMessageCreated WebhookEvent = "message.created"
 	EventMessageUpdated WebhookEvent = "message.updated"
 
+	// SLA events
+	EventSLAApplied  WebhookEvent = "sla.applied"
+	EventSLAMet      WebhookEvent = "sla.met"
+	EventSLABreached WebhookEvent = "sla.breached"
+
 	// Test event
 	EventWebhookTest WebhookEvent = "webhook.test"
 )
@@ -7,7 +7,9 @@ SELECT
     url,
     events,
     secret,
-    is_active
+    is_active,
+    include_fields,
+    exclude_fields
 FROM
     webhooks
 ORDER BY created_at DESC;
@@ -21,7 +23,9 @@ SELECT
     url,
     events,
     secret,
-    is_active
+    is_active,
+    include_fields,
+    exclude_fields
 FROM
     webhooks
 WHERE
@@ -44,7 +48,9 @@ SELECT
     url,
     events,
     secret,
-    is_active
+    is_active,
+    include_fields,
+    exclude_fields
 FROM
     webhooks
 WHERE
@@ -60,7 +66,9 @@ SELECT
     url,
     events,
     secret,
-    is_active
+    is_active,
+    include_fields,
+    exclude_fields
 FROM
     webhooks
 WHERE
@@ -69,9 +77,9 @@ WHERE
 
 -- name: insert-webhook
 INSERT INTO
-    webhooks (name, url, events, secret, is_active)
+    webhooks (name, url, events, secret, is_active, include_fields, exclude_fields)
 VALUES
-    ($1, $2, $3, $4, $5)
+    ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: update-webhook
@@ -83,6 +91,8 @@ SET
     events = $4,
     secret = $5,
     is_active = $6,
+    include_fields = $7,
+    exclude_fields = $8,
     updated_at = NOW()
 WHERE
     id = $1
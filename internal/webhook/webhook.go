@@ -23,6 +23,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/tracing"
 	"github.com/abhinavxd/libredesk/internal/version"
 	"github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/abhinavxd/ssrfguard"
@@ -30,6 +31,8 @@ import (
 	"github.com/knadh/go-i18n"
 	"github.com/lib/pq"
 	"github.com/zerodha/logf"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 var (
@@ -68,6 +71,13 @@ type Opts struct {
 type DeliveryTask struct {
 	Event   models.WebhookEvent
 	Payload any
+
+	// ctx carries the trace context of the code that triggered the event.
+	ctx context.Context
+
+	// url and body are set for calls to a URL outside of the configured webhooks, see Call.
+	url  string
+	body []byte
 }
 
 // queries contains prepared SQL queries.
@@ -161,7 +171,7 @@ func (m *Manager) Create(webhook models.Webhook) (models.Webhook, error) {
 		return models.Webhook{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
 
-	if err := m.q.InsertWebhook.Get(&result, webhook.Name, webhook.URL, pq.Array(webhook.Events), encryptedSecret, webhook.IsActive); err != nil {
+	if err := m.q.InsertWebhook.Get(&result, webhook.Name, webhook.URL, pq.Array(webhook.Events), encryptedSecret, webhook.IsActive, pq.Array(webhook.IncludeFields), pq.Array(webhook.ExcludeFields)); err != nil {
 		if dbutil.IsUniqueViolationError(err) {
 			return models.Webhook{}, envelope.NewError(envelope.ConflictError, m.i18n.T("globals.messages.errorAlreadyExists"), nil)
 		}
@@ -199,7 +209,7 @@ func (m *Manager) Update(id int, webhook models.Webhook) (models.Webhook, error)
 		}
 	}
 
-	if err := m.q.UpdateWebhook.Get(&result, id, webhook.Name, webhook.URL, pq.Array(webhook.Events), encryptedSecret, webhook.IsActive); err != nil {
+	if err := m.q.UpdateWebhook.Get(&result, id, webhook.Name, webhook.URL, pq.Array(webhook.Events), encryptedSecret, webhook.IsActive, pq.Array(webhook.IncludeFields), pq.Array(webhook.ExcludeFields)); err != nil {
 		m.lo.Error("error updating webhook", "error", err)
 		return models.Webhook{}, envelope.NewError(envelope.GeneralError, m.i18n.T("globals.messages.somethingWentWrong"), nil)
 	}
@@ -238,7 +248,7 @@ func (m *Manager) SendTestWebhook(id int) error {
 		return envelope.NewError(envelope.NotFoundError, m.i18n.T("globals.messages.notFound"), nil)
 	}
 
-	m.deliverSingleWebhook(webhook, DeliveryTask{
+	m.deliverSingleWebhook(context.Background(), webhook, DeliveryTask{
 		Event: models.EventWebhookTest,
 		Payload: map[string]any{
 			"id":   webhook.ID,
@@ -251,6 +261,11 @@ func (m *Manager) SendTestWebhook(id int) error {
 
 // TriggerEvent triggers webhooks for a specific event with the provided data.
 func (m *Manager) TriggerEvent(event models.WebhookEvent, data any) {
+	m.TriggerEventContext(context.Background(), event, data)
+}
+
+// TriggerEventContext is like TriggerEvent but deliveries are traced as children of the span in ctx.
+func (m *Manager) TriggerEventContext(ctx context.Context, event models.WebhookEvent, data any) {
 	m.closedMu.RLock()
 	defer m.closedMu.RUnlock()
 	if m.closed {
@@ -261,12 +276,32 @@ func (m *Manager) TriggerEvent(event models.WebhookEvent, data any) {
 	case m.deliveryQueue <- DeliveryTask{
 		Event:   event,
 		Payload: data,
+		ctx:     ctx,
 	}:
 	default:
 		m.lo.Warn("webhook delivery queue is full, dropping webhook delivery", "event", event, "queue_size", len(m.deliveryQueue))
 	}
 }
 
+// Call queues a POST of a JSON body to a URL that isn't a configured webhook, e.g. for automation rule actions.
+// The call goes through the same SSRF protected HTTP client as webhook deliveries.
+func (m *Manager) Call(url string, body []byte) {
+	m.closedMu.RLock()
+	defer m.closedMu.RUnlock()
+	if m.closed {
+		return
+	}
+
+	select {
+	case m.deliveryQueue <- DeliveryTask{
+		url:  url,
+		body: body,
+	}:
+	default:
+		m.lo.Warn("webhook delivery queue is full, dropping webhook call", "url", url, "queue_size", len(m.deliveryQueue))
+	}
+}
+
 // Run starts the webhook delivery worker pool.
 func (m *Manager) Run(ctx context.Context) {
 	for i := 0; i < m.workers; i++ {
@@ -307,23 +342,51 @@ func (m *Manager) worker(ctx context.Context) {
 
 // deliverWebhook delivers webhooks for an event by making HTTP requests.
 func (m *Manager) deliverWebhook(task DeliveryTask) {
+	ctx := task.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if task.url != "" {
+		m.deliverCall(ctx, task)
+		return
+	}
+	ctx, span := tracing.Start(ctx, "webhook.dispatch", attribute.String("webhook.event", string(task.Event)))
+
 	webhooks, err := m.getWebhooksByEvent(string(task.Event))
 	if err != nil {
 		m.lo.Error("error fetching webhooks for event", "event", task.Event, "error", err)
+		tracing.End(span, err)
 		return
 	}
+	defer span.End()
+	span.SetAttributes(attribute.Int("webhook.count", len(webhooks)))
 
 	for _, webhook := range webhooks {
-		m.deliverSingleWebhook(webhook, task)
+		m.deliverSingleWebhook(ctx, webhook, task)
 	}
 }
 
 // deliverSingleWebhook delivers a webhook to a single endpoint.
-func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask) {
+func (m *Manager) deliverSingleWebhook(ctx context.Context, webhook models.Webhook, task DeliveryTask) {
+	ctx, span := tracing.Start(ctx, "webhook.deliver",
+		attribute.Int("webhook.id", webhook.ID),
+		attribute.String("webhook.event", string(task.Event)),
+	)
+	var spanErr error
+	defer func() { tracing.End(span, spanErr) }()
+
+	payload := task.Payload
+	if len(webhook.IncludeFields) > 0 || len(webhook.ExcludeFields) > 0 {
+		var err error
+		if payload, err = filterFields(payload, webhook.IncludeFields, webhook.ExcludeFields); err != nil {
+			m.lo.Error("error filtering webhook payload fields", "webhook_id", webhook.ID, "event", task.Event, "error", err)
+			return
+		}
+	}
 	basePayload := map[string]any{
 		"event":     task.Event,
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"payload":   task.Payload,
+		"payload":   payload,
 	}
 
 	payloadBytes, err := json.Marshal(basePayload)
@@ -333,8 +396,9 @@ func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(payloadBytes))
 	if err != nil {
+		spanErr = err
 		m.lo.Error("error creating webhook request", "webhook_id", webhook.ID, "url", webhook.URL, "event", task.Event, "error", err)
 		return
 	}
@@ -342,6 +406,7 @@ func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Libredesk-Webhook/"+version.Version)
+	tracing.InjectHTTPHeaders(ctx, propagation.HeaderCarrier(req.Header))
 
 	// Add signature if secret is provided
 	if webhook.Secret != "" {
@@ -360,6 +425,7 @@ func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask
 	// Make the request
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
+		spanErr = err
 		m.lo.Error("webhook delivery failed - HTTP request error",
 			"webhook_id", webhook.ID,
 			"url", webhook.URL,
@@ -378,6 +444,7 @@ func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask
 
 	// Check if delivery was successful (2xx status codes)
 	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	if success {
 		m.lo.Info("webhook delivered successfully",
@@ -386,6 +453,7 @@ func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask
 			"url", webhook.URL,
 			"status_code", resp.StatusCode)
 	} else {
+		spanErr = fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
 		m.lo.Error("webhook delivery failed",
 			"webhook_id", webhook.ID,
 			"event", task.Event,
@@ -395,6 +463,40 @@ func (m *Manager) deliverSingleWebhook(webhook models.Webhook, task DeliveryTask
 	}
 }
 
+// deliverCall POSTs the body of a call task to its URL.
+func (m *Manager) deliverCall(ctx context.Context, task DeliveryTask) {
+	ctx, span := tracing.Start(ctx, "webhook.call")
+	var spanErr error
+	defer func() { tracing.End(span, spanErr) }()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", task.url, bytes.NewReader(task.body))
+	if err != nil {
+		spanErr = err
+		m.lo.Error("error creating webhook call request", "url", task.url, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Libredesk-Webhook/"+version.Version)
+	tracing.InjectHTTPHeaders(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		spanErr = err
+		m.lo.Error("webhook call failed - HTTP request error", "url", task.url, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		spanErr = fmt.Errorf("webhook call endpoint returned status %d", resp.StatusCode)
+		m.lo.Error("webhook call failed", "url", task.url, "status_code", resp.StatusCode, "response", string(responseBody))
+		return
+	}
+	m.lo.Info("webhook call delivered successfully", "url", task.url, "status_code", resp.StatusCode)
+}
+
 // generateSignature generates HMAC-SHA256 signature for webhook payload.
 func (m *Manager) generateSignature(payload []byte, secret string) string {
 	h := hmac.New(sha256.New, []byte(secret))
@@ -0,0 +1,166 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ws/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// brokerRetryInterval is the wait between broker resubscription attempts.
+	brokerRetryInterval = 5 * time.Second
+
+	// brokerPublishTimeout bounds a single publish so a slow broker doesn't hold up the publish queue.
+	brokerPublishTimeout = 2 * time.Second
+
+	// brokerPublishQueueSize is the number of broadcasts queued for publishing, broadcasts are dropped when it is full.
+	brokerPublishQueueSize = 1000
+)
+
+// Broker relays hub broadcasts between app instances so that agents connected
+// to any node receive them.
+type Broker interface {
+	// Publish publishes a payload to all nodes.
+	Publish(ctx context.Context, data []byte) error
+	// Subscribe blocks and calls fn for every payload published by any node until ctx is cancelled.
+	Subscribe(ctx context.Context, fn func(data []byte)) error
+}
+
+// RedisBroker is a Broker backed by Redis pub/sub.
+type RedisBroker struct {
+	rd      *redis.Client
+	channel string
+}
+
+// NewRedisBroker returns a Redis pub/sub backed broker publishing on the given channel.
+func NewRedisBroker(rd *redis.Client, channel string) *RedisBroker {
+	return &RedisBroker{rd: rd, channel: channel}
+}
+
+// Publish publishes data on the broker channel.
+func (b *RedisBroker) Publish(ctx context.Context, data []byte) error {
+	return b.rd.Publish(ctx, b.channel, data).Err()
+}
+
+// Subscribe listens on the broker channel and calls fn for every message received.
+func (b *RedisBroker) Subscribe(ctx context.Context, fn func(data []byte)) error {
+	sub := b.rd.Subscribe(ctx, b.channel)
+	defer sub.Close()
+
+	// Wait for the subscription to be confirmed.
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			fn([]byte(msg.Payload))
+		}
+	}
+}
+
+// SetBroker sets the broker used to fan out broadcasts to other app instances.
+func (h *Hub) SetBroker(broker Broker) {
+	h.broker = broker
+	h.publishQueue = make(chan []byte, brokerPublishQueueSize)
+}
+
+// Run publishes queued broadcasts to the broker, subscribes to it and delivers broadcasts
+// published by other nodes to the clients connected to this node. It is a no-op if no broker is set.
+func (h *Hub) Run(ctx context.Context) {
+	if h.broker == nil {
+		return
+	}
+	go h.runPublisher(ctx)
+	for {
+		err := h.broker.Subscribe(ctx, h.handleBrokerMessage)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("error subscribing to websocket broker: %v", err)
+		}
+		// Back off a little before resubscribing.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(brokerRetryInterval):
+		}
+	}
+}
+
+// publish queues a broadcast for publishing to the other nodes. Broadcasts are dropped
+// if the queue is full so that a slow or unreachable broker never blocks broadcasters.
+func (h *Hub) publish(msg models.BrokerMessage) {
+	if h.broker == nil {
+		return
+	}
+	msg.Node = h.nodeID
+	b, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("error marshalling websocket broker message: %v", err)
+		return
+	}
+	select {
+	case h.publishQueue <- b:
+	default:
+		log.Printf("websocket broker publish queue full, dropping %s broadcast", msg.Kind)
+	}
+}
+
+// runPublisher publishes queued broadcasts until ctx is cancelled.
+func (h *Hub) runPublisher(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-h.publishQueue:
+			pctx, cancel := context.WithTimeout(ctx, brokerPublishTimeout)
+			if err := h.broker.Publish(pctx, b); err != nil {
+				log.Printf("error publishing websocket broker message: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// PublishWidget relays a broadcast to the widget clients of a contact on a live chat inbox to the other nodes.
+func (h *Hub) PublishWidget(inboxID, contactID int, data []byte) {
+	h.publish(models.BrokerMessage{Kind: models.BrokerKindWidget, InboxID: inboxID, ContactID: contactID, Data: data})
+}
+
+// handleBrokerMessage delivers a broadcast received from the broker to local clients.
+func (h *Hub) handleBrokerMessage(data []byte) {
+	var msg models.BrokerMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Printf("error unmarshalling websocket broker message: %v", err)
+		return
+	}
+
+	// Already delivered locally by the publishing node.
+	if msg.Node == h.nodeID {
+		return
+	}
+
+	switch msg.Kind {
+	case models.BrokerKindUsers:
+		h.broadcastLocal(models.BroadcastMessage{Data: msg.Data, Users: msg.Users})
+	case models.BrokerKindConversation:
+		h.broadcastConversationLocal(msg.ConversationUUID, msg.Data)
+	case models.BrokerKindWidget:
+		if h.conversationStore != nil {
+			h.conversationStore.DeliverWidgetBroadcast(msg.InboxID, msg.ContactID, msg.Data)
+		}
+	}
+}
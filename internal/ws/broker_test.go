@@ -0,0 +1,132 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/ws/models"
+)
+
+type widgetDelivery struct {
+	inboxID, contactID int
+	data               string
+}
+
+type fakeConversationStore struct {
+	widget []widgetDelivery
+}
+
+func (f *fakeConversationStore) BroadcastTypingToWidgetClientsOnly(string, bool) {}
+
+func (f *fakeConversationStore) DeliverWidgetBroadcast(inboxID, contactID int, data []byte) {
+	f.widget = append(f.widget, widgetDelivery{inboxID, contactID, string(data)})
+}
+
+type fakeBroker struct {
+	published chan []byte
+}
+
+func (b *fakeBroker) Publish(ctx context.Context, data []byte) error {
+	b.published <- data
+	return nil
+}
+
+func (b *fakeBroker) Subscribe(ctx context.Context, fn func(data []byte)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func brokerPayload(t *testing.T, msg models.BrokerMessage) []byte {
+	t.Helper()
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestHandleBrokerMessage(t *testing.T) {
+	h := NewHub(nil)
+	store := &fakeConversationStore{}
+	h.SetConversationStore(store)
+
+	agent := &Client{ID: 1, Hub: h, Send: make(chan models.WSMessage, 10)}
+	h.AddClient(agent)
+	subscriber := &Client{ID: 2, Hub: h, Send: make(chan models.WSMessage, 10)}
+	h.SubscribeToConversation(subscriber, "c1")
+
+	tests := []struct {
+		name       string
+		data       []byte
+		agent      int
+		subscriber int
+		widget     int
+	}{
+		{"malformed", []byte(`{`), 0, 0, 0},
+		{"not an object", []byte(`"users"`), 0, 0, 0},
+		{"unknown kind", brokerPayload(t, models.BrokerMessage{Node: "other", Kind: "unknown", Data: []byte(`{}`)}), 0, 0, 0},
+		{"own node", brokerPayload(t, models.BrokerMessage{Node: h.nodeID, Kind: models.BrokerKindUsers, Users: []int{1}, Data: []byte(`{}`)}), 0, 0, 0},
+		{"users", brokerPayload(t, models.BrokerMessage{Node: "other", Kind: models.BrokerKindUsers, Users: []int{1}, Data: []byte(`{}`)}), 1, 0, 0},
+		{"other users", brokerPayload(t, models.BrokerMessage{Node: "other", Kind: models.BrokerKindUsers, Users: []int{3}, Data: []byte(`{}`)}), 0, 0, 0},
+		{"conversation", brokerPayload(t, models.BrokerMessage{Node: "other", Kind: models.BrokerKindConversation, ConversationUUID: "c1", Data: []byte(`{}`)}), 0, 1, 0},
+		{"widget", brokerPayload(t, models.BrokerMessage{Node: "other", Kind: models.BrokerKindWidget, InboxID: 4, ContactID: 5, Data: []byte(`{"type":"typing"}`)}), 0, 0, 1},
+		{"own node widget", brokerPayload(t, models.BrokerMessage{Node: h.nodeID, Kind: models.BrokerKindWidget, InboxID: 4, ContactID: 5, Data: []byte(`{}`)}), 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.widget = nil
+			h.handleBrokerMessage(tt.data)
+			if got := len(agent.Send); got != tt.agent {
+				t.Errorf("agent received %d messages, want %d", got, tt.agent)
+			}
+			if got := len(subscriber.Send); got != tt.subscriber {
+				t.Errorf("subscriber received %d messages, want %d", got, tt.subscriber)
+			}
+			if got := len(store.widget); got != tt.widget {
+				t.Errorf("widget deliveries = %d, want %d", got, tt.widget)
+			}
+			for len(agent.Send) > 0 {
+				<-agent.Send
+			}
+			for len(subscriber.Send) > 0 {
+				<-subscriber.Send
+			}
+		})
+	}
+
+	h.handleBrokerMessage(brokerPayload(t, models.BrokerMessage{Node: "other", Kind: models.BrokerKindWidget, InboxID: 4, ContactID: 5, Data: []byte(`{"type":"typing"}`)}))
+	if want := (widgetDelivery{4, 5, `{"type":"typing"}`}); len(store.widget) != 1 || store.widget[0] != want {
+		t.Errorf("widget deliveries = %+v, want %+v", store.widget, want)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	// Without a broker publishing is a no-op.
+	NewHub(nil).PublishWidget(1, 2, []byte(`{}`))
+
+	h := NewHub(nil)
+	broker := &fakeBroker{published: make(chan []byte)}
+	h.SetBroker(broker)
+
+	// Publishing never blocks, broadcasts beyond the queue are dropped.
+	for range brokerPublishQueueSize + 10 {
+		h.PublishWidget(1, 2, []byte(`{}`))
+	}
+	if got := len(h.publishQueue); got != brokerPublishQueueSize {
+		t.Fatalf("queued broadcasts = %d, want %d", got, brokerPublishQueueSize)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.Run(ctx)
+
+	var msg models.BrokerMessage
+	if err := json.Unmarshal(<-broker.published, &msg); err != nil {
+		t.Fatal(err)
+	}
+	want := models.BrokerMessage{Node: h.nodeID, Kind: models.BrokerKindWidget, InboxID: 1, ContactID: 2, Data: []byte(`{}`)}
+	if msg.Node != want.Node || msg.Kind != want.Kind || msg.InboxID != want.InboxID || msg.ContactID != want.ContactID || string(msg.Data) != string(want.Data) {
+		t.Errorf("published %+v, want %+v", msg, want)
+	}
+}
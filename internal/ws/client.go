@@ -47,6 +47,9 @@ type Client struct {
 
 	// Buffered channel of outbound ws messages.
 	Send chan models.WSMessage
+
+	// Broadcast filters requested by the client.
+	subscription subscription
 }
 
 // Serve handles heartbeats and sending messages to the client.
@@ -113,6 +116,10 @@ func (c *Client) processIncomingMessage(data []byte) {
 		c.handleConversationSubscribe(msg.Data)
 	case models.MessageTypeTyping:
 		c.handleTyping(msg.Data)
+	case models.MessageTypeSubscribe:
+		c.handleSubscribe(msg.Data, false)
+	case models.MessageTypeUnsubscribe:
+		c.handleSubscribe(msg.Data, true)
 	default:
 		c.SendError("unknown message type")
 	}
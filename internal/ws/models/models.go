@@ -11,6 +11,9 @@ const (
 	MessageTypeConversationSubscribe  = "conversation_subscribe"
 	MessageTypeConversationSubscribed = "conversation_subscribed"
 	MessageTypeTyping                 = "typing"
+	MessageTypeSubscribe              = "subscribe"
+	MessageTypeUnsubscribe            = "unsubscribe"
+	MessageTypeSubscribed             = "subscribed"
 )
 
 // WSMessage represents a WS message.
@@ -31,11 +34,37 @@ type BroadcastMessage struct {
 	Users []int  `json:"users"`
 }
 
+// Broker message kinds.
+const (
+	BrokerKindUsers        = "users"
+	BrokerKindConversation = "conversation"
+	BrokerKindWidget       = "widget"
+)
+
+// BrokerMessage is a broadcast relayed between app instances through the broker.
+type BrokerMessage struct {
+	Node             string `json:"node"`
+	Kind             string `json:"kind"`
+	Users            []int  `json:"users,omitempty"`
+	ConversationUUID string `json:"conversation_uuid,omitempty"`
+	InboxID          int    `json:"inbox_id,omitempty"`
+	ContactID        int    `json:"contact_id,omitempty"`
+	Data             []byte `json:"data"`
+}
+
 // ConversationSubscribe represents a conversation subscription message.
 type ConversationSubscribe struct {
 	ConversationUUID string `json:"conversation_uuid"`
 }
 
+// Subscription narrows the broadcasts delivered to a client to the given event types, and to the given
+// conversations and the conversations listed in the given views. An empty list means no filtering on that dimension.
+type Subscription struct {
+	EventTypes        []string `json:"event_types"`
+	ConversationUUIDs []string `json:"conversation_uuids"`
+	ViewIDs           []int    `json:"view_ids"`
+}
+
 // TypingMessage represents a typing indicator message.
 type TypingMessage struct {
 	ConversationUUID string `json:"conversation_uuid"`
@@ -0,0 +1,204 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/abhinavxd/libredesk/internal/ws/models"
+	"github.com/fasthttp/websocket"
+)
+
+// maxSubscriptionEntries caps the number of event types, conversations and views a single client can subscribe to.
+const maxSubscriptionEntries = 1000
+
+// subscription holds a client's broadcast filters. A zero value delivers everything,
+// which keeps clients that never subscribe working as before.
+//
+// Conversation broadcasts are delivered if the conversation is one of the subscribed conversations
+// or is listed in one of the subscribed views, evaluated with the view's filters when broadcast.
+type subscription struct {
+	mu            sync.RWMutex
+	eventTypes    map[string]struct{}
+	conversations map[string]struct{}
+	views         map[int]struct{}
+}
+
+// add adds event types, conversations and views to the subscription.
+func (s *subscription) add(sub models.Subscription) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.eventTypes)+len(sub.EventTypes) > maxSubscriptionEntries ||
+		len(s.conversations)+len(sub.ConversationUUIDs) > maxSubscriptionEntries ||
+		len(s.views)+len(sub.ViewIDs) > maxSubscriptionEntries {
+		return false
+	}
+	if s.eventTypes == nil {
+		s.eventTypes = make(map[string]struct{})
+	}
+	if s.conversations == nil {
+		s.conversations = make(map[string]struct{})
+	}
+	if s.views == nil {
+		s.views = make(map[int]struct{})
+	}
+	for _, t := range sub.EventTypes {
+		s.eventTypes[t] = struct{}{}
+	}
+	for _, uuid := range sub.ConversationUUIDs {
+		s.conversations[uuid] = struct{}{}
+	}
+	for _, id := range sub.ViewIDs {
+		s.views[id] = struct{}{}
+	}
+	return true
+}
+
+// remove removes event types, conversations and views from the subscription, an empty
+// request clears all filters.
+func (s *subscription) remove(sub models.Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(sub.EventTypes) == 0 && len(sub.ConversationUUIDs) == 0 && len(sub.ViewIDs) == 0 {
+		s.eventTypes = nil
+		s.conversations = nil
+		s.views = nil
+		return
+	}
+	for _, t := range sub.EventTypes {
+		delete(s.eventTypes, t)
+	}
+	for _, uuid := range sub.ConversationUUIDs {
+		delete(s.conversations, uuid)
+	}
+	for _, id := range sub.ViewIDs {
+		delete(s.views, id)
+	}
+}
+
+// isFiltered returns true if the client has any filters set.
+func (s *subscription) isFiltered() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.eventTypes) > 0 || len(s.conversations) > 0 || len(s.views) > 0
+}
+
+// matches returns true if a broadcast should be delivered to the client, inView reports whether the
+// broadcast conversation is listed in a view. Broadcasts not tied to a conversation, such as notifications,
+// are only filtered by event type.
+func (s *subscription) matches(meta broadcastMeta, inView func(viewID int) bool) bool {
+	s.mu.RLock()
+	if len(s.eventTypes) > 0 {
+		if _, ok := s.eventTypes[meta.Type]; !ok {
+			s.mu.RUnlock()
+			return false
+		}
+	}
+	if meta.ConversationUUID == "" || (len(s.conversations) == 0 && len(s.views) == 0) {
+		s.mu.RUnlock()
+		return true
+	}
+	if _, ok := s.conversations[meta.ConversationUUID]; ok {
+		s.mu.RUnlock()
+		return true
+	}
+	views := make([]int, 0, len(s.views))
+	for id := range s.views {
+		views = append(views, id)
+	}
+	s.mu.RUnlock()
+
+	// Views are evaluated without the lock held, it can take a database query.
+	for _, id := range views {
+		if inView(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot returns the current filters.
+func (s *subscription) snapshot() models.Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := models.Subscription{
+		EventTypes:        make([]string, 0, len(s.eventTypes)),
+		ConversationUUIDs: make([]string, 0, len(s.conversations)),
+		ViewIDs:           make([]int, 0, len(s.views)),
+	}
+	for t := range s.eventTypes {
+		out.EventTypes = append(out.EventTypes, t)
+	}
+	for uuid := range s.conversations {
+		out.ConversationUUIDs = append(out.ConversationUUIDs, uuid)
+	}
+	for id := range s.views {
+		out.ViewIDs = append(out.ViewIDs, id)
+	}
+	return out
+}
+
+// broadcastMeta is the part of a broadcast payload used for subscription filtering.
+type broadcastMeta struct {
+	Type             string
+	ConversationUUID string
+}
+
+// parseBroadcastMeta extracts the event type and conversation UUID from a marshalled models.Message.
+func parseBroadcastMeta(data []byte) broadcastMeta {
+	var msg struct {
+		Type string `json:"type"`
+		Data struct {
+			ConversationUUID string `json:"conversation_uuid"`
+			UUID             string `json:"uuid"`
+		} `json:"data"`
+	}
+	// Payloads whose data is not an object still carry a usable type.
+	_ = json.Unmarshal(data, &msg)
+
+	meta := broadcastMeta{Type: msg.Type, ConversationUUID: msg.Data.ConversationUUID}
+	// Conversation payloads carry the conversation UUID as `uuid`.
+	if meta.ConversationUUID == "" && (msg.Type == models.MessageTypeConversationUpdate || msg.Type == models.MessageTypeNewConversation) {
+		meta.ConversationUUID = msg.Data.UUID
+	}
+	return meta
+}
+
+// handleSubscribe handles subscribe and unsubscribe requests and replies with the resulting filters.
+func (c *Client) handleSubscribe(data interface{}, unsubscribe bool) {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		c.SendError("invalid subscription data")
+		return
+	}
+
+	var sub models.Subscription
+	if err := json.Unmarshal(dataBytes, &sub); err != nil {
+		c.SendError("invalid subscription format")
+		return
+	}
+
+	if unsubscribe {
+		c.subscription.remove(sub)
+	} else {
+		for _, id := range sub.ViewIDs {
+			if c.Hub.viewStore == nil || !c.Hub.viewStore.CanAccessView(c.ID, id) {
+				c.SendError("view not found")
+				return
+			}
+		}
+		if !c.subscription.add(sub) {
+			c.SendError("too many subscriptions")
+			return
+		}
+	}
+
+	response := models.Message{
+		Type: models.MessageTypeSubscribed,
+		Data: c.subscription.snapshot(),
+	}
+	responseBytes, _ := json.Marshal(response)
+	c.SendMessage(responseBytes, websocket.TextMessage)
+}
@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/abhinavxd/libredesk/internal/ws/models"
+)
+
+func TestParseBroadcastMeta(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want broadcastMeta
+	}{
+		{"message", `{"type":"new_message","data":{"conversation_uuid":"c1","uuid":"m1"}}`, broadcastMeta{Type: "new_message", ConversationUUID: "c1"}},
+		{"conversation update", `{"type":"conversation_update","data":{"uuid":"c1"}}`, broadcastMeta{Type: "conversation_update", ConversationUUID: "c1"}},
+		{"new conversation", `{"type":"new_conversation","data":{"uuid":"c2"}}`, broadcastMeta{Type: "new_conversation", ConversationUUID: "c2"}},
+		{"uuid of other types is not a conversation", `{"type":"new_notification","data":{"uuid":"n1"}}`, broadcastMeta{Type: "new_notification"}},
+		{"data not an object", `{"type":"typing","data":"x"}`, broadcastMeta{Type: "typing"}},
+		{"malformed", `{`, broadcastMeta{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBroadcastMeta([]byte(tt.data)); got != tt.want {
+				t.Errorf("parseBroadcastMeta() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	inViews := func(ids ...int) func(int) bool {
+		return func(id int) bool { return slices.Contains(ids, id) }
+	}
+	tests := []struct {
+		name   string
+		sub    models.Subscription
+		meta   broadcastMeta
+		inView func(int) bool
+		want   bool
+	}{
+		{"no filters", models.Subscription{}, broadcastMeta{Type: "new_message", ConversationUUID: "c1"}, inViews(), true},
+		{"event type matches", models.Subscription{EventTypes: []string{"new_message"}}, broadcastMeta{Type: "new_message"}, inViews(), true},
+		{"event type doesn't match", models.Subscription{EventTypes: []string{"new_message"}}, broadcastMeta{Type: "typing"}, inViews(), false},
+		{"conversation matches", models.Subscription{ConversationUUIDs: []string{"c1"}}, broadcastMeta{Type: "new_message", ConversationUUID: "c1"}, inViews(), true},
+		{"conversation doesn't match", models.Subscription{ConversationUUIDs: []string{"c1"}}, broadcastMeta{Type: "new_message", ConversationUUID: "c2"}, inViews(), false},
+		{"not tied to a conversation", models.Subscription{ConversationUUIDs: []string{"c1"}}, broadcastMeta{Type: "new_notification"}, inViews(), true},
+		{"listed in a view", models.Subscription{ViewIDs: []int{1, 2}}, broadcastMeta{Type: "conversation_update", ConversationUUID: "c2"}, inViews(2), true},
+		{"not listed in a view", models.Subscription{ViewIDs: []int{1}}, broadcastMeta{Type: "conversation_update", ConversationUUID: "c2"}, inViews(2), false},
+		{"conversation or view", models.Subscription{ConversationUUIDs: []string{"c1"}, ViewIDs: []int{1}}, broadcastMeta{Type: "new_message", ConversationUUID: "c1"}, inViews(), true},
+		{"view and event type", models.Subscription{EventTypes: []string{"new_message"}, ViewIDs: []int{1}}, broadcastMeta{Type: "typing", ConversationUUID: "c1"}, inViews(1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s subscription
+			if !s.add(tt.sub) {
+				t.Fatal("add() = false")
+			}
+			if got := s.matches(tt.meta, tt.inView); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionAddRemove(t *testing.T) {
+	var s subscription
+	if s.isFiltered() {
+		t.Fatal("zero subscription is filtered")
+	}
+
+	uuids := make([]string, maxSubscriptionEntries)
+	for i := range uuids {
+		uuids[i] = fmt.Sprintf("c%d", i)
+	}
+	if !s.add(models.Subscription{ConversationUUIDs: uuids}) {
+		t.Fatalf("add() of %d conversations = false, want true", maxSubscriptionEntries)
+	}
+	if s.add(models.Subscription{ConversationUUIDs: []string{"one-too-many"}}) {
+		t.Error("add() over the conversation cap = true, want false")
+	}
+	views := make([]int, maxSubscriptionEntries+1)
+	if s.add(models.Subscription{ViewIDs: views}) {
+		t.Error("add() over the view cap = true, want false")
+	}
+	if !s.add(models.Subscription{ViewIDs: []int{7}}) {
+		t.Error("add() of a view = false, want true")
+	}
+
+	s.remove(models.Subscription{ConversationUUIDs: []string{"c0"}})
+	if got := s.snapshot(); len(got.ConversationUUIDs) != maxSubscriptionEntries-1 || len(got.ViewIDs) != 1 {
+		t.Errorf("snapshot() after remove = %d conversations, %d views", len(got.ConversationUUIDs), len(got.ViewIDs))
+	}
+
+	s.remove(models.Subscription{})
+	if s.isFiltered() {
+		t.Error("subscription is filtered after removing all filters")
+	}
+}
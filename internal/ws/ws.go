@@ -6,6 +6,7 @@ import (
 
 	"github.com/abhinavxd/libredesk/internal/ws/models"
 	"github.com/fasthttp/websocket"
+	"github.com/google/uuid"
 )
 
 // Hub maintains the set of registered websockets clients.
@@ -20,6 +21,12 @@ type Hub struct {
 
 	userStore         userStore
 	conversationStore conversationStore
+	viewStore         viewStore
+
+	// Optional broker to fan out broadcasts to other app instances, nodeID identifies this instance.
+	broker       Broker
+	publishQueue chan []byte
+	nodeID       string
 }
 
 type userStore interface {
@@ -28,6 +35,15 @@ type userStore interface {
 
 type conversationStore interface {
 	BroadcastTypingToWidgetClientsOnly(conversationUUID string, isTyping bool)
+	DeliverWidgetBroadcast(inboxID, contactID int, data []byte)
+}
+
+// viewStore evaluates the views of view subscriptions.
+type viewStore interface {
+	// CanAccessView returns true if the user can see the view.
+	CanAccessView(userID, viewID int) bool
+	// ConversationInView returns true if the conversation is listed in the view for the user.
+	ConversationInView(userID, viewID int, conversationUUID string) bool
 }
 
 // NewHub creates a new websocket hub.
@@ -40,6 +56,7 @@ func NewHub(userStore userStore) *Hub {
 		userStore:                userStore,
 		// To be set later via conversationStore.
 		conversationStore: nil,
+		nodeID:            uuid.NewString(),
 	}
 }
 
@@ -48,6 +65,11 @@ func (h *Hub) SetConversationStore(manager conversationStore) {
 	h.conversationStore = manager
 }
 
+// SetViewStore sets the store evaluating view subscriptions.
+func (h *Hub) SetViewStore(store viewStore) {
+	h.viewStore = store
+}
+
 // AddClient adds a new client to the hub.
 func (h *Hub) AddClient(client *Client) {
 	h.clientsMutex.Lock()
@@ -75,27 +97,60 @@ func (h *Hub) RemoveClient(client *Client) {
 	}
 }
 
-// BroadcastMessage broadcasts a message to the specified users.
+// BroadcastMessage broadcasts a message to the specified users on all app instances.
 // If no users are specified, the message is broadcast to all users.
 func (h *Hub) BroadcastMessage(msg models.BroadcastMessage) {
-	h.clientsMutex.RLock()
-	defer h.clientsMutex.RUnlock()
+	h.broadcastLocal(msg)
+	h.publish(models.BrokerMessage{Kind: models.BrokerKindUsers, Users: msg.Users, Data: msg.Data})
+}
 
-	// Broadcast to all users if no users are specified.
+// broadcastLocal broadcasts a message to the specified users connected to this instance.
+func (h *Hub) broadcastLocal(msg models.BroadcastMessage) {
+	// Recipients are collected first so view subscriptions aren't evaluated with the clients locked.
+	var recipients []*Client
+	h.clientsMutex.RLock()
 	if len(msg.Users) == 0 {
+		// Broadcast to all users if no users are specified.
 		for _, clients := range h.clients {
-			for _, client := range clients {
-				client.SendMessage(msg.Data, websocket.TextMessage)
-			}
+			recipients = append(recipients, clients...)
+		}
+	} else {
+		for _, userID := range msg.Users {
+			recipients = append(recipients, h.clients[userID]...)
 		}
-		return
 	}
-
-	// Broadcast to specified users.
-	for _, userID := range msg.Users {
-		for _, client := range h.clients[userID] {
-			client.SendMessage(msg.Data, websocket.TextMessage)
+	h.clientsMutex.RUnlock()
+
+	// Payload is parsed at most once and only if a recipient has subscription filters. A view is
+	// evaluated at most once per user.
+	var (
+		meta   broadcastMeta
+		parsed bool
+		inView = make(map[[2]int]bool)
+	)
+	for _, client := range recipients {
+		if client.subscription.isFiltered() {
+			if !parsed {
+				meta = parseBroadcastMeta(msg.Data)
+				parsed = true
+			}
+			matches := client.subscription.matches(meta, func(viewID int) bool {
+				if h.viewStore == nil {
+					return false
+				}
+				key := [2]int{client.ID, viewID}
+				ok, done := inView[key]
+				if !done {
+					ok = h.viewStore.ConversationInView(client.ID, viewID, meta.ConversationUUID)
+					inView[key] = ok
+				}
+				return ok
+			})
+			if !matches {
+				continue
+			}
 		}
+		client.SendMessage(msg.Data, websocket.TextMessage)
 	}
 }
 
@@ -135,8 +190,14 @@ func (h *Hub) BroadcastTypingToConversation(conversationUUID string, typingMsg m
 	}
 }
 
-// BroadcastTypingToAllConversationClients broadcasts typing status to all clients subscribed to a conversation.
+// BroadcastTypingToAllConversationClients broadcasts typing status to all clients subscribed to a conversation on all app instances.
 func (h *Hub) BroadcastTypingToAllConversationClients(conversationUUID string, data []byte) {
+	h.broadcastConversationLocal(conversationUUID, data)
+	h.publish(models.BrokerMessage{Kind: models.BrokerKindConversation, ConversationUUID: conversationUUID, Data: data})
+}
+
+// broadcastConversationLocal broadcasts data to clients on this instance subscribed to a conversation.
+func (h *Hub) broadcastConversationLocal(conversationUUID string, data []byte) {
 	h.conversationClientsMutex.RLock()
 	defer h.conversationClientsMutex.RUnlock()
 
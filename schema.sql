@@ -18,12 +18,18 @@ DROP TYPE IF EXISTS "media_store" CASCADE; CREATE TYPE "media_store" AS ENUM ('s
 DROP TYPE IF EXISTS "user_availability_status" CASCADE; CREATE TYPE "user_availability_status" AS ENUM ('online', 'away', 'away_manual', 'offline', 'away_and_reassigning');
 DROP TYPE IF EXISTS "applied_sla_status" CASCADE; CREATE TYPE "applied_sla_status" AS ENUM ('pending', 'breached', 'met', 'partially_met');
 DROP TYPE IF EXISTS "sla_event_status" CASCADE; CREATE TYPE "sla_event_status" AS ENUM ('pending', 'breached', 'met');
-DROP TYPE IF EXISTS "sla_metric" CASCADE; CREATE TYPE "sla_metric" AS ENUM ('first_response', 'resolution', 'next_response');
+DROP TYPE IF EXISTS "sla_metric" CASCADE; CREATE TYPE "sla_metric" AS ENUM ('first_response', 'resolution', 'next_response', 'assignment');
 DROP TYPE IF EXISTS "sla_notification_type" CASCADE; CREATE TYPE "sla_notification_type" AS ENUM ('warning', 'breach');
-DROP TYPE IF EXISTS "activity_log_type" CASCADE; CREATE TYPE "activity_log_type" AS ENUM ('agent_login', 'agent_logout', 'agent_away', 'agent_away_reassigned', 'agent_online', 'agent_password_set', 'agent_role_permissions_changed');
+DROP TYPE IF EXISTS "activity_log_type" CASCADE; CREATE TYPE "activity_log_type" AS ENUM ('agent_login', 'agent_logout', 'agent_away', 'agent_away_reassigned', 'agent_online', 'agent_password_set', 'agent_role_permissions_changed', 'contact_risk_flags_changed');
 DROP TYPE IF EXISTS "macro_visible_when" CASCADE; CREATE TYPE "macro_visible_when" AS ENUM ('replying', 'starting_conversation', 'adding_private_note');
-DROP TYPE IF EXISTS "user_notification_type" CASCADE; CREATE TYPE "user_notification_type" AS ENUM ('mention', 'assignment', 'sla_warning', 'sla_breach');
+DROP TYPE IF EXISTS "user_notification_type" CASCADE; CREATE TYPE "user_notification_type" AS ENUM ('mention', 'assignment', 'sla_warning', 'sla_breach', 'abuse', 'maintenance_summary');
 DROP TYPE IF EXISTS "conversation_status_category" CASCADE; CREATE TYPE "conversation_status_category" AS ENUM ('open', 'waiting', 'resolved');
+DROP TYPE IF EXISTS "announcement_severity" CASCADE; CREATE TYPE "announcement_severity" AS ENUM ('info', 'warning', 'critical');
+DROP TYPE IF EXISTS "device_platform" CASCADE; CREATE TYPE "device_platform" AS ENUM ('apns', 'fcm');
+DROP TYPE IF EXISTS "autoresponder_condition" CASCADE; CREATE TYPE "autoresponder_condition" AS ENUM ('always', 'outside_business_hours', 'holiday', 'high_backlog');
+DROP TYPE IF EXISTS "snooze_preset_kind" CASCADE; CREATE TYPE "snooze_preset_kind" AS ENUM ('duration', 'next_business_day', 'weekday');
+DROP TYPE IF EXISTS "sender_rule_type" CASCADE; CREATE TYPE "sender_rule_type" AS ENUM ('block', 'allow');
+DROP TYPE IF EXISTS "csat_scale" CASCADE; CREATE TYPE "csat_scale" AS ENUM ('stars', 'thumbs', 'nps');
 DROP TYPE IF EXISTS "webhook_event" CASCADE; CREATE TYPE webhook_event AS ENUM (
 	'conversation.created',
 	'conversation.status_changed',
@@ -31,7 +37,10 @@ DROP TYPE IF EXISTS "webhook_event" CASCADE; CREATE TYPE webhook_event AS ENUM (
 	'conversation.assigned',
 	'conversation.unassigned',
 	'message.created',
-	'message.updated'
+	'message.updated',
+	'sla.applied',
+	'sla.met',
+	'sla.breached'
 );
 
 -- Sequence to generate reference number for conversations.
@@ -55,6 +64,8 @@ CREATE TABLE sla_policies (
 	first_response_time TEXT NOT NULL,
 	resolution_time TEXT NOT NULL,
 	next_response_time TEXT NULL,
+	-- Time a conversation may wait unassigned in the queue.
+	assignment_time TEXT NULL,
 	notifications JSONB DEFAULT '[]'::jsonb NOT NULL,
 	CONSTRAINT constraint_sla_policies_on_name CHECK (length(name) <= 140),
 	CONSTRAINT constraint_sla_policies_on_description CHECK (length(description) <= 300)
@@ -85,14 +96,75 @@ CREATE TABLE inboxes (
 	channel channels NOT NULL,
 	enabled bool DEFAULT TRUE NOT NULL,
 	csat_enabled bool DEFAULT false NOT NULL,
+	-- CSAT survey settings, rating scale, question, comment field and language.
+	csat_config jsonb DEFAULT '{}'::jsonb NOT NULL,
 	prompt_tags_on_reply bool DEFAULT false NOT NULL,
 	config jsonb DEFAULT '{}'::jsonb NOT NULL,
 	"from" TEXT NULL,
 	secret TEXT NULL,
 	linked_email_inbox_id INT REFERENCES inboxes(id) ON DELETE SET NULL,
+	-- Outgoing email template of the inbox, the default template is used when unset or deleted.
+	email_template_id INT NULL,
 	CONSTRAINT constraint_inboxes_on_name CHECK (length("name") <= 140)
 );
 
+-- Address migrations of email inboxes, completed once the new address is verified.
+DROP TABLE IF EXISTS inbox_migrations CASCADE;
+CREATE TABLE inbox_migrations (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	"from" TEXT NOT NULL,
+	reply_to TEXT DEFAULT '' NOT NULL,
+	enable_plus_addressing BOOL DEFAULT FALSE NOT NULL,
+	previous_from TEXT DEFAULT '' NOT NULL,
+	verification_code TEXT NOT NULL,
+	verified_at TIMESTAMPTZ NULL,
+	completed_at TIMESTAMPTZ NULL
+);
+CREATE INDEX index_inbox_migrations_on_inbox_id ON inbox_migrations(inbox_id);
+
+DROP TABLE IF EXISTS inbox_autoresponders CASCADE;
+CREATE TABLE inbox_autoresponders (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	"name" TEXT NOT NULL,
+	enabled BOOLEAN DEFAULT TRUE NOT NULL,
+	-- Rules are evaluated in ascending priority, the first matching rule is sent.
+	priority INT DEFAULT 0 NOT NULL,
+	"condition" autoresponder_condition NOT NULL,
+	business_hours_id INT REFERENCES business_hours(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	timezone TEXT NULL,
+	backlog_threshold INT NULL,
+	starts_at TIMESTAMPTZ NULL,
+	ends_at TIMESTAMPTZ NULL,
+	content TEXT NOT NULL,
+	CONSTRAINT constraint_inbox_autoresponders_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_inbox_autoresponders_on_content CHECK (length(content) <= 10000)
+);
+
+DROP TABLE IF EXISTS web_forms CASCADE;
+CREATE TABLE web_forms (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"uuid" UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+	"name" TEXT NOT NULL,
+	-- Submissions create conversations in the inbox.
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	enabled BOOLEAN DEFAULT TRUE NOT NULL,
+	fields JSONB DEFAULT '[]'::JSONB NOT NULL,
+	allow_attachments BOOLEAN DEFAULT FALSE NOT NULL,
+	success_message TEXT DEFAULT '' NOT NULL,
+	trusted_domains TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	CONSTRAINT constraint_web_forms_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_web_forms_on_success_message CHECK (length(success_message) <= 1000)
+);
+CREATE INDEX index_inbox_autoresponders_on_inbox_id ON inbox_autoresponders(inbox_id);
+
 DROP TABLE IF EXISTS teams CASCADE;
 CREATE TABLE teams (
 	id SERIAL PRIMARY KEY,
@@ -122,6 +194,8 @@ CREATE TABLE roles (
     permissions TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
     "name" TEXT UNIQUE NOT NULL,
     description TEXT NULL,
+	-- Agents with the role must enroll in two-factor authentication when logging in with a password.
+	require_two_factor BOOLEAN DEFAULT FALSE NOT NULL,
 	CONSTRAINT constraint_roles_on_name CHECK (length("name") <= 50),
 	CONSTRAINT constraint_roles_on_description CHECK (length(description) <= 300)
 );
@@ -153,6 +227,15 @@ CREATE TABLE users (
 	api_key TEXT NULL,
 	api_secret TEXT NULL,
 	api_key_last_used_at TIMESTAMPTZ NULL,
+	-- TOTP two-factor authentication fields, the secret is encrypted.
+	totp_secret TEXT NULL,
+	totp_enabled_at TIMESTAMPTZ NULL,
+	-- Time step of the last accepted code, to reject replayed codes.
+	totp_last_step BIGINT NULL,
+	-- Warnings about a contact shown to agents on all their conversations, e.g. "chargeback risk".
+	risk_flags TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	-- HTML signature appended to the agent's email replies.
+	signature TEXT NULL,
     CONSTRAINT constraint_users_on_country CHECK (LENGTH(country) <= 140),
     CONSTRAINT constraint_users_on_phone_number CHECK (LENGTH(phone_number) <= 20),
 	CONSTRAINT constraint_users_on_phone_number_country_code CHECK (LENGTH(phone_number_country_code) <= 10),
@@ -172,6 +255,69 @@ CREATE UNIQUE INDEX index_unique_users_on_email_when_no_ext_id_contact
 	ON users (email)
 	WHERE type = 'contact' AND deleted_at IS NULL AND external_user_id IS NULL;
 
+DROP TABLE IF EXISTS user_recovery_codes CASCADE;
+CREATE TABLE user_recovery_codes (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- SHA-256 hash of the two-factor recovery code.
+	code_hash TEXT NOT NULL,
+	used_at TIMESTAMPTZ NULL
+);
+CREATE INDEX index_user_recovery_codes_on_user_id ON user_recovery_codes(user_id);
+
+DROP TABLE IF EXISTS agent_schedules CASCADE;
+CREATE TABLE agent_schedules (
+	user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	timezone TEXT NOT NULL,
+	-- Working hours per weekday, same shape as business_hours.hours.
+	hours JSONB DEFAULT '{}'::jsonb NOT NULL
+);
+
+DROP TABLE IF EXISTS agent_schedule_overrides CASCADE;
+CREATE TABLE agent_schedule_overrides (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL,
+	-- Whether the agent is working during the override, e.g. false for leave, true for an extra shift.
+	available BOOLEAN NOT NULL,
+	note TEXT DEFAULT '' NOT NULL,
+	CONSTRAINT constraint_agent_schedule_overrides_on_range CHECK (ends_at > starts_at),
+	CONSTRAINT constraint_agent_schedule_overrides_on_note CHECK (length(note) <= 200)
+);
+CREATE INDEX index_agent_schedule_overrides_on_user_id_ends_at ON agent_schedule_overrides(user_id, ends_at);
+
+DROP TABLE IF EXISTS agent_keyboard_bindings CASCADE;
+CREATE TABLE agent_keyboard_bindings (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Normalized key combination, e.g. "ctrl+shift+1".
+	keys TEXT NOT NULL,
+	-- Either "macro" or "status".
+	action TEXT NOT NULL,
+	-- Macro or status ID.
+	target TEXT NOT NULL,
+	CONSTRAINT constraint_agent_keyboard_bindings_on_keys CHECK (length(keys) <= 50),
+	CONSTRAINT constraint_agent_keyboard_bindings_on_target CHECK (length(target) <= 50),
+	CONSTRAINT constraint_agent_keyboard_bindings_on_user_id_keys_unique UNIQUE (user_id, keys)
+);
+
+DROP TABLE IF EXISTS agent_notification_preferences CASCADE;
+CREATE TABLE agent_notification_preferences (
+	user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	-- Hours between two digests of activity on watched conversations, 0 disables the digest.
+	watch_digest_hours INT DEFAULT 0 NOT NULL,
+	watch_digest_sent_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_agent_notification_preferences_on_watch_digest_hours CHECK (watch_digest_hours >= 0)
+);
+
 DROP TABLE IF EXISTS user_roles CASCADE;
 CREATE TABLE user_roles (
 	id SERIAL PRIMARY KEY,
@@ -195,6 +341,23 @@ CREATE TABLE conversation_statuses (
 	category conversation_status_category NOT NULL DEFAULT 'open'
 );
 
+DROP TABLE IF EXISTS snooze_presets CASCADE;
+CREATE TABLE snooze_presets (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"name" TEXT NOT NULL,
+	kind snooze_preset_kind NOT NULL,
+	-- Go duration for `duration` presets, e.g. "3h".
+	duration TEXT NOT NULL DEFAULT '',
+	-- Weekday name for `weekday` presets, e.g. "Monday".
+	weekday TEXT NOT NULL DEFAULT '',
+	-- "HH:MM" in the team's time zone for `next_business_day` and `weekday` presets.
+	time_of_day TEXT NOT NULL DEFAULT '',
+	sort_order INT NOT NULL DEFAULT 0,
+	CONSTRAINT constraint_snooze_presets_on_name CHECK (length("name") <= 50)
+);
+
 DROP TABLE IF EXISTS conversation_priorities CASCADE;
 CREATE TABLE conversation_priorities (
 	id SERIAL PRIMARY KEY,
@@ -248,12 +411,21 @@ CREATE TABLE conversations (
 	last_interaction_at TIMESTAMPTZ NULL,
 	next_sla_deadline_at TIMESTAMPTZ NULL,
 	snoozed_until TIMESTAMPTZ NULL,
-	last_continuity_email_sent_at TIMESTAMPTZ NULL
+	status_changed_at TIMESTAMPTZ DEFAULT NOW(),
+	last_continuity_email_sent_at TIMESTAMPTZ NULL,
+	-- Description of the conversation maintained by agents.
+	summary TEXT DEFAULT '' NOT NULL,
+	-- Set when moved to the trash, trashed conversations are purged after the retention period.
+	trashed_at TIMESTAMPTZ NULL,
+	-- Set while the message bodies are moved to conversation_message_archives.
+	archived_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_conversations_on_summary CHECK (length(summary) <= 2000)
 );
 CREATE INDEX index_conversations_on_assigned_user_id ON conversations (assigned_user_id);
 CREATE INDEX index_conversations_on_assigned_team_id ON conversations (assigned_team_id);
 CREATE INDEX index_conversations_on_snoozed_until ON conversations (snoozed_until);
 CREATE INDEX index_conversations_on_contact_id ON conversations (contact_id);
+CREATE INDEX index_trgm_conversations_on_subject ON conversations USING GIN ("subject" gin_trgm_ops);
 CREATE INDEX index_conversations_on_inbox_id ON conversations (inbox_id);
 CREATE INDEX index_conversations_on_status_id ON conversations (status_id);
 CREATE INDEX index_conversations_on_priority_id ON conversations (priority_id);
@@ -261,9 +433,36 @@ CREATE INDEX index_conversations_on_created_at ON conversations (created_at);
 CREATE INDEX index_conversations_on_last_message_at ON conversations (last_message_at);
 CREATE INDEX index_conversations_on_last_interaction_at ON conversations (last_interaction_at);
 CREATE INDEX index_conversations_on_next_sla_deadline_at ON conversations (next_sla_deadline_at);
+CREATE INDEX index_conversations_on_trashed_at ON conversations (trashed_at) WHERE trashed_at IS NOT NULL;
+CREATE INDEX index_conversations_on_order_number ON conversations ((custom_attributes->>'order_number'));
 CREATE INDEX index_conversations_on_waiting_since ON conversations (waiting_since);
 CREATE INDEX index_conversations_on_last_continuity_email_sent_at ON conversations (last_continuity_email_sent_at);
 
+-- Named internal discussions grouping private notes of a conversation.
+DROP TABLE IF EXISTS conversation_threads CASCADE;
+CREATE TABLE conversation_threads (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	updated_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	"name" TEXT NOT NULL,
+	created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	resolved_at TIMESTAMPTZ NULL,
+	resolved_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	CONSTRAINT constraint_conversation_threads_on_name CHECK (length("name") <= 140)
+);
+CREATE INDEX index_conversation_threads_on_conversation_id ON conversation_threads(conversation_id);
+
+DROP TABLE IF EXISTS conversation_thread_participants CASCADE;
+CREATE TABLE conversation_thread_participants (
+	thread_id BIGINT REFERENCES conversation_threads(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	PRIMARY KEY (thread_id, user_id)
+);
+
+-- Large installs partition this table by month with `libredesk --partition-messages`, after which other tables
+-- can't reference it with foreign keys. Migrations add references with reference_conversation_messages().
 DROP TABLE IF EXISTS conversation_messages CASCADE;
 CREATE TABLE conversation_messages (
     id BIGSERIAL PRIMARY KEY,
@@ -280,7 +479,16 @@ CREATE TABLE conversation_messages (
     source_id TEXT NULL,
  	sender_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
     sender_type message_sender_type NOT NULL,
-    meta JSONB DEFAULT '{}'::JSONB NULL
+    meta JSONB DEFAULT '{}'::JSONB NULL,
+    -- Set while an app instance is sending the message, see get-outgoing-pending-messages.
+    send_claimed_until TIMESTAMPTZ NULL,
+    send_claim_token UUID NULL,
+    -- Order of pending outgoing messages in the outbox, lower is sent first. 0 for agent replies, 1 for CSAT and automated emails.
+    send_priority SMALLINT DEFAULT 0 NOT NULL,
+    -- Internal thread of a private note, NULL for messages on the main timeline.
+    thread_id BIGINT REFERENCES conversation_threads(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+    -- Latest delivery state of an outgoing email, the timeline is kept in meta.
+    delivery_state TEXT NULL
 );
 CREATE INDEX index_trgm_conversation_messages_on_text_content ON conversation_messages USING GIN (text_content gin_trgm_ops);
 CREATE INDEX index_conversation_messages_on_conversation_id ON conversation_messages (conversation_id);
@@ -288,6 +496,176 @@ CREATE INDEX index_conversation_messages_on_created_at ON conversation_messages
 CREATE INDEX index_conversation_messages_on_source_id ON conversation_messages (source_id);
 CREATE INDEX index_conversation_messages_on_status ON conversation_messages (status);
 CREATE INDEX index_conversation_messages_on_conversation_id_and_created_at ON conversation_messages (conversation_id, created_at);
+CREATE INDEX index_conversation_messages_on_thread_id ON conversation_messages (thread_id) WHERE thread_id IS NOT NULL;
+CREATE INDEX index_conversation_messages_on_delivery_accepted ON conversation_messages (id) WHERE delivery_state = 'accepted';
+CREATE INDEX index_conversation_messages_on_outbox ON conversation_messages (send_priority, id) WHERE status = 'pending' AND type = 'outgoing';
+
+-- References to conversation_messages(id) once it is partitioned and can't be referenced by foreign keys,
+-- enforced by the triggers below. Actions are pg_constraint codes: c (cascade), n (set null), a (no action), r (restrict).
+DROP TABLE IF EXISTS conversation_message_refs CASCADE;
+CREATE TABLE conversation_message_refs (
+    table_name TEXT NOT NULL,
+    column_name TEXT NOT NULL,
+    on_delete TEXT DEFAULT 'c' NOT NULL,
+    on_update TEXT DEFAULT 'c' NOT NULL,
+    PRIMARY KEY (table_name, column_name),
+    CONSTRAINT constraint_conversation_message_refs_on_actions CHECK (on_delete IN ('c', 'n', 'a', 'r') AND on_update IN ('c', 'n', 'a', 'r'))
+);
+
+-- Adds a reference to conversation_messages(id), a foreign key or, once the table is partitioned, a registered
+-- reference with a trigger that checks inserted and updated message ids. Migrations use it instead of REFERENCES.
+CREATE OR REPLACE FUNCTION reference_conversation_messages(tbl TEXT, col TEXT, delete_action TEXT DEFAULT 'c', update_action TEXT DEFAULT 'c')
+RETURNS VOID AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'conversation_messages'::regclass) THEN
+        INSERT INTO conversation_message_refs (table_name, column_name, on_delete, on_update)
+        VALUES (tbl, col, delete_action, update_action)
+        ON CONFLICT (table_name, column_name) DO UPDATE SET on_delete = EXCLUDED.on_delete, on_update = EXCLUDED.on_update;
+        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'trigger_check_' || col || '_message_ref', tbl);
+        EXECUTE format('CREATE TRIGGER %I BEFORE INSERT OR UPDATE OF %I ON %I FOR EACH ROW EXECUTE FUNCTION check_conversation_message_ref(%L)',
+            'trigger_check_' || col || '_message_ref', col, tbl, col);
+    ELSIF NOT EXISTS (
+        SELECT 1 FROM pg_constraint c
+        JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+        WHERE c.contype = 'f' AND c.conrelid = tbl::regclass AND c.confrelid = 'conversation_messages'::regclass AND a.attname = col
+    ) THEN
+        EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (%I) REFERENCES conversation_messages(id) ON DELETE %s ON UPDATE %s', tbl, col,
+            CASE delete_action WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END,
+            CASE update_action WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END);
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Rejects a row referencing a message that doesn't exist, the argument is the referencing column.
+CREATE OR REPLACE FUNCTION check_conversation_message_ref()
+RETURNS TRIGGER AS $$
+DECLARE
+    ref_id BIGINT;
+BEGIN
+    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) INTO ref_id USING NEW;
+    IF ref_id IS NOT NULL THEN
+        PERFORM 1 FROM conversation_messages WHERE id = ref_id FOR KEY SHARE;
+        IF NOT FOUND THEN
+            RAISE foreign_key_violation USING MESSAGE = format('%s.%s references message %s which does not exist', TG_TABLE_NAME, TG_ARGV[0], ref_id);
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Does the ON DELETE and ON UPDATE actions of the registered references when a message is deleted or its id changes.
+CREATE OR REPLACE FUNCTION apply_conversation_message_refs()
+RETURNS TRIGGER AS $$
+DECLARE
+    ref RECORD;
+    action TEXT;
+    referenced BOOL;
+BEGIN
+    -- Messages moved out of the default partition by create_message_partitions() keep their references.
+    IF current_setting('libredesk.moving_messages', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE' AND NEW.id = OLD.id THEN
+        RETURN NULL;
+    END IF;
+    -- An update of created_at moving the message to another partition is a delete and an insert.
+    IF TG_OP = 'DELETE' AND EXISTS (SELECT 1 FROM conversation_messages WHERE id = OLD.id) THEN
+        RETURN NULL;
+    END IF;
+    FOR ref IN SELECT * FROM conversation_message_refs WHERE to_regclass(table_name) IS NOT NULL LOOP
+        action := CASE TG_OP WHEN 'DELETE' THEN ref.on_delete ELSE ref.on_update END;
+        IF action = 'c' AND TG_OP = 'DELETE' THEN
+            EXECUTE format('DELETE FROM %I WHERE %I = $1', ref.table_name, ref.column_name) USING OLD.id;
+        ELSIF action = 'c' THEN
+            EXECUTE format('UPDATE %I SET %I = $2 WHERE %I = $1', ref.table_name, ref.column_name, ref.column_name) USING OLD.id, NEW.id;
+        ELSIF action = 'n' THEN
+            EXECUTE format('UPDATE %I SET %I = NULL WHERE %I = $1', ref.table_name, ref.column_name, ref.column_name) USING OLD.id;
+        ELSE
+            EXECUTE format('SELECT EXISTS (SELECT 1 FROM %I WHERE %I = $1)', ref.table_name, ref.column_name) INTO referenced USING OLD.id;
+            IF referenced THEN
+                RAISE foreign_key_violation USING MESSAGE = format('message %s is still referenced from %s.%s', OLD.id, ref.table_name, ref.column_name);
+            END IF;
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Creates the monthly partitions of conversation_messages from the current month to months_ahead months ahead
+-- once the table has been partitioned with `libredesk --partition-messages`. Does nothing on an unpartitioned table.
+CREATE OR REPLACE FUNCTION create_message_partitions(months_ahead INT)
+RETURNS INT AS $$
+DECLARE
+    created INT := 0;
+    legacy_end TIMESTAMPTZ;
+    month_start TIMESTAMPTZ;
+    month_end TIMESTAMPTZ;
+    partition_name TEXT;
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'conversation_messages'::regclass) THEN
+        RETURN 0;
+    END IF;
+    -- Serializes app instances creating the same partitions.
+    PERFORM pg_advisory_xact_lock(hashtext('create_message_partitions'));
+
+    -- Messages before the upper bound of the legacy partition stay in it.
+    SELECT substring(pg_get_expr(relpartbound, oid) FROM 'TO \(''([^'']+)''\)')::TIMESTAMPTZ INTO legacy_end
+    FROM pg_class WHERE oid = to_regclass('conversation_messages_legacy');
+
+    FOR i IN 0..months_ahead LOOP
+        month_start := (date_trunc('month', NOW() AT TIME ZONE 'UTC') + make_interval(months => i)) AT TIME ZONE 'UTC';
+        month_end := (date_trunc('month', NOW() AT TIME ZONE 'UTC') + make_interval(months => i + 1)) AT TIME ZONE 'UTC';
+        partition_name := 'conversation_messages_p' || to_char(month_start AT TIME ZONE 'UTC', 'YYYYMM');
+        IF to_regclass(partition_name) IS NULL AND (legacy_end IS NULL OR month_start >= legacy_end) THEN
+            -- Messages that went to the default partition while the month had no partition are moved to it.
+            EXECUTE format('CREATE TABLE %I (LIKE conversation_messages INCLUDING DEFAULTS INCLUDING CONSTRAINTS)', partition_name);
+            IF to_regclass('conversation_messages_default') IS NOT NULL THEN
+                PERFORM set_config('libredesk.moving_messages', 'on', true);
+                EXECUTE format('WITH moved AS (DELETE FROM conversation_messages_default WHERE created_at >= $1 AND created_at < $2 RETURNING *) INSERT INTO %I SELECT * FROM moved', partition_name)
+                    USING month_start, month_end;
+                PERFORM set_config('libredesk.moving_messages', 'off', true);
+            END IF;
+            EXECUTE format('ALTER TABLE conversation_messages ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', partition_name, month_start, month_end);
+            created := created + 1;
+        END IF;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Cold storage of the bodies of messages of archived conversations, restored to conversation_messages on access.
+DROP TABLE IF EXISTS conversation_message_archives CASCADE;
+CREATE TABLE conversation_message_archives (
+	message_id BIGINT PRIMARY KEY REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	-- Gzipped JSON of the content and text_content of the message.
+	body BYTEA NOT NULL
+);
+
+-- Hourly rollups of customer messages, agent replies and waits for the waiting time heatmap.
+DROP TABLE IF EXISTS waiting_time_rollups CASCADE;
+CREATE TABLE waiting_time_rollups (
+	hour_start TIMESTAMPTZ PRIMARY KEY,
+	incoming INT DEFAULT 0 NOT NULL,
+	responses INT DEFAULT 0 NOT NULL,
+	waits_started INT DEFAULT 0 NOT NULL,
+	waits_answered INT DEFAULT 0 NOT NULL,
+	-- Total seconds customers waited for the answered waits.
+	wait_sec_total BIGINT DEFAULT 0 NOT NULL
+);
+
+-- Incoming messages staged when the in-memory incoming queue is full, drained by the app.
+DROP TABLE IF EXISTS incoming_messages CASCADE;
+CREATE TABLE incoming_messages (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	payload JSONB NOT NULL,
+	claimed_until TIMESTAMPTZ NULL,
+	-- Failed processing attempts, retried with backoff until the limit in the app.
+	attempts INT DEFAULT 0 NOT NULL,
+	last_error TEXT NULL
+);
 
 DROP TABLE IF EXISTS automation_rules CASCADE;
 CREATE TABLE automation_rules (
@@ -302,12 +680,54 @@ CREATE TABLE automation_rules (
     enabled BOOL DEFAULT TRUE NOT NULL,
 	weight INT DEFAULT 0 NOT NULL,
 	execution_mode automation_execution_mode DEFAULT 'all' NOT NULL,
+	-- Stop evaluating further rules once this rule matches.
+	stop_processing BOOL DEFAULT FALSE NOT NULL,
+	-- Optional window the rule is evaluated in.
+	active_from TIMESTAMPTZ NULL,
+	active_until TIMESTAMPTZ NULL,
     CONSTRAINT constraint_automation_rules_on_name CHECK (length("name") <= 140),
-    CONSTRAINT constraint_automation_rules_on_description CHECK (length(description) <= 300)
+    CONSTRAINT constraint_automation_rules_on_description CHECK (length(description) <= 300),
+	CONSTRAINT constraint_automation_rules_on_active_window CHECK (active_until > active_from)
 );
 CREATE INDEX index_automation_rules_on_enabled_and_weight ON automation_rules(enabled, weight);
 CREATE INDEX index_automation_rules_on_type_and_weight ON automation_rules(type, weight);
 
+DROP TABLE IF EXISTS automation_rule_executions CASCADE;
+CREATE TABLE automation_rule_executions (
+	rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Last time a time trigger rule executed on the conversation.
+	executed_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	PRIMARY KEY (rule_id, conversation_id)
+);
+CREATE INDEX index_automation_rule_executions_on_conversation_id ON automation_rule_executions(conversation_id);
+
+DROP TABLE IF EXISTS automation_rule_logs CASCADE;
+CREATE TABLE automation_rule_logs (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Whether any of the actions failed.
+	failed BOOLEAN DEFAULT FALSE NOT NULL,
+	-- Executed actions with their errors, if any.
+	actions JSONB DEFAULT '[]'::jsonb NOT NULL
+);
+CREATE INDEX index_automation_rule_logs_on_rule_id_created_at ON automation_rule_logs(rule_id, created_at);
+CREATE INDEX index_automation_rule_logs_on_created_at ON automation_rule_logs(created_at);
+
+DROP TABLE IF EXISTS automation_pending_actions CASCADE;
+CREATE TABLE automation_pending_actions (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- The delayed rule action.
+	action JSONB NOT NULL,
+	run_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX index_automation_pending_actions_on_run_at ON automation_pending_actions(run_at);
+
 DROP TABLE IF EXISTS conversation_drafts CASCADE;
 CREATE TABLE conversation_drafts (
     id BIGSERIAL PRIMARY KEY,
@@ -316,10 +736,31 @@ CREATE TABLE conversation_drafts (
     conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
     user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
     content TEXT NOT NULL,
-	meta JSONB DEFAULT '{}'::jsonb NOT NULL
+	meta JSONB DEFAULT '{}'::jsonb NOT NULL,
+	-- Incremented on every save, used to detect concurrent saves from another tab or device.
+	version INT DEFAULT 1 NOT NULL,
+	-- Latest message of the conversation when the draft was saved, used to warn about newer messages.
+	last_message_id BIGINT NULL
 );
 CREATE UNIQUE INDEX index_uniq_conversation_drafts_on_conversation_id_and_user_id ON conversation_drafts (conversation_id, user_id);
 
+-- Drafts shared with everyone who can access the conversation, for handing over replies between shifts.
+DROP TABLE IF EXISTS conversation_team_drafts CASCADE;
+CREATE TABLE conversation_team_drafts (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	updated_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL UNIQUE,
+	content TEXT NOT NULL,
+	meta JSONB DEFAULT '{}'::jsonb NOT NULL,
+	version INT DEFAULT 1 NOT NULL,
+	created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	updated_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	-- Only the lock holder can edit, the lock expires unless renewed by saving.
+	locked_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	locked_until TIMESTAMPTZ NULL
+);
+
 DROP TABLE IF EXISTS macros CASCADE;
 CREATE TABLE macros (
    id SERIAL PRIMARY KEY,
@@ -367,6 +808,52 @@ CREATE INDEX index_conversation_mentions_on_mentioned_user_id ON conversation_me
 CREATE INDEX index_conversation_mentions_on_mentioned_team_id ON conversation_mentions(mentioned_team_id);
 CREATE INDEX index_conversation_mentions_on_conversation_id ON conversation_mentions(conversation_id);
 
+DROP TABLE IF EXISTS conversation_pinned_messages CASCADE;
+CREATE TABLE conversation_pinned_messages (
+	message_id BIGINT PRIMARY KEY REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	pinned_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+CREATE INDEX index_conversation_pinned_messages_on_conversation_id ON conversation_pinned_messages(conversation_id);
+
+DROP TABLE IF EXISTS conversation_message_reactions CASCADE;
+CREATE TABLE conversation_message_reactions (
+	message_id BIGINT REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	emoji TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	PRIMARY KEY (message_id, user_id, emoji),
+	CONSTRAINT constraint_conversation_message_reactions_on_emoji CHECK (length(emoji) <= 16)
+);
+
+DROP TABLE IF EXISTS conversation_handoff_notes CASCADE;
+CREATE TABLE conversation_handoff_notes (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	from_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	to_user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	summary TEXT NOT NULL,
+	current_state TEXT DEFAULT '' NOT NULL,
+	next_step TEXT DEFAULT '' NOT NULL,
+	-- Only the latest note is active, and only while its recipient remains the assignee.
+	active BOOLEAN DEFAULT TRUE NOT NULL,
+	CONSTRAINT constraint_conversation_handoff_notes_on_summary CHECK (length(summary) <= 2000),
+	CONSTRAINT constraint_conversation_handoff_notes_on_current_state CHECK (length(current_state) <= 2000),
+	CONSTRAINT constraint_conversation_handoff_notes_on_next_step CHECK (length(next_step) <= 2000)
+);
+CREATE INDEX index_conversation_handoff_notes_on_conversation_id ON conversation_handoff_notes(conversation_id) WHERE active;
+
+DROP TABLE IF EXISTS conversation_message_headers CASCADE;
+CREATE TABLE conversation_message_headers (
+	-- Raw headers of incoming email messages kept for abuse investigations, purged after the retention period.
+	message_id BIGINT PRIMARY KEY REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	headers JSONB DEFAULT '{}'::JSONB NOT NULL
+);
+CREATE INDEX index_conversation_message_headers_on_created_at ON conversation_message_headers(created_at);
+
 DROP TABLE IF EXISTS conversation_last_seen CASCADE;
 CREATE TABLE conversation_last_seen (
 	id BIGSERIAL PRIMARY KEY,
@@ -484,16 +971,52 @@ CREATE TABLE csat_responses (
 	-- Cascade deletes when conversation is deleted.
     conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
 
-    rating INT DEFAULT 0 NOT NULL,
+    -- NULL when the contact only left feedback.
+    rating INT NULL,
+    scale csat_scale DEFAULT 'stars' NOT NULL,
     feedback TEXT NULL,
     meta JSONB DEFAULT '{}' NOT NULL,
     response_timestamp TIMESTAMPTZ NULL,
-    CONSTRAINT constraint_csat_responses_on_rating CHECK (rating >= 0 AND rating <= 5),
+    -- Nonce of the survey's signed link, issued once when the survey is sent.
+    -- NULL for surveys sent before signed links, which are reachable by their UUID until answered.
+    token_nonce TEXT NULL,
+    CONSTRAINT constraint_csat_responses_on_rating CHECK (rating >= 0 AND rating <= 10),
     CONSTRAINT constraint_csat_responses_on_feedback CHECK (length(feedback) <= 1000)
 );
 CREATE INDEX index_csat_responses_on_uuid ON csat_responses(uuid);
 CREATE INDEX index_csat_responses_on_conversation_id ON csat_responses(conversation_id);
 
+DROP TABLE IF EXISTS message_variants CASCADE;
+CREATE TABLE message_variants (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	-- A variant belongs to either a template or an autoresponder.
+	template_id INT REFERENCES templates(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	autoresponder_id INT REFERENCES inbox_autoresponders(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	"name" TEXT NOT NULL,
+	content TEXT NOT NULL,
+	-- Relative chance of the variant being sent, zero pauses it.
+	weight INT DEFAULT 1 NOT NULL,
+	CONSTRAINT constraint_message_variants_on_owner CHECK (num_nonnulls(template_id, autoresponder_id) = 1),
+	CONSTRAINT constraint_message_variants_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_message_variants_on_content CHECK (length(content) <= 10000),
+	CONSTRAINT constraint_message_variants_on_weight CHECK (weight >= 0 AND weight <= 100)
+);
+CREATE INDEX index_message_variants_on_template_id ON message_variants(template_id);
+CREATE INDEX index_message_variants_on_autoresponder_id ON message_variants(autoresponder_id);
+
+DROP TABLE IF EXISTS message_variant_sends CASCADE;
+CREATE TABLE message_variant_sends (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	variant_id INT REFERENCES message_variants(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- The CSAT survey requested by the message, if any.
+	csat_response_id INT REFERENCES csat_responses(id) ON DELETE SET NULL ON UPDATE CASCADE NULL
+);
+CREATE INDEX index_message_variant_sends_on_variant_id_and_created_at ON message_variant_sends(variant_id, created_at);
+
 DROP TABLE IF EXISTS views CASCADE;
 CREATE TABLE views (
     id SERIAL PRIMARY KEY,
@@ -513,6 +1036,46 @@ CREATE INDEX index_views_on_user_id ON views(user_id);
 CREATE INDEX index_views_on_visibility ON views(visibility);
 CREATE INDEX index_views_on_team_id ON views(team_id);
 
+DROP TABLE IF EXISTS view_preferences CASCADE;
+CREATE TABLE view_preferences (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    -- The conversation list, e.g. "assigned", "team_unassigned:3" or "view:12".
+    view_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Empty order_by and sort_order, or a page_size of 0, use the list defaults.
+    order_by TEXT NOT NULL DEFAULT '',
+    sort_order TEXT NOT NULL DEFAULT '',
+    page_size INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, view_key),
+    CONSTRAINT constraint_view_preferences_on_view_key CHECK (length(view_key) <= 140),
+    CONSTRAINT constraint_view_preferences_on_page_size CHECK (page_size >= 0)
+);
+
+DROP TABLE IF EXISTS sla_incidents CASCADE;
+CREATE TABLE sla_incidents (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	title TEXT NOT NULL,
+	description TEXT DEFAULT '' NOT NULL,
+	starts_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	-- NULL while the incident is ongoing.
+	ends_at TIMESTAMPTZ NULL,
+	-- Empty inbox_ids and tag_ids means the incident affects all conversations.
+	inbox_ids INT[] DEFAULT '{}'::INT[] NOT NULL,
+	tag_ids INT[] DEFAULT '{}'::INT[] NOT NULL,
+	-- Pause SLA clocks of affected conversations for the duration of the incident instead of only annotating breaches.
+	pause_sla BOOLEAN DEFAULT TRUE NOT NULL,
+	-- `manual` or the status page provider the incident was synced from.
+	source TEXT DEFAULT 'manual' NOT NULL,
+	external_id TEXT NULL,
+	CONSTRAINT constraint_sla_incidents_on_title CHECK (length(title) <= 255),
+	CONSTRAINT constraint_sla_incidents_on_description CHECK (length(description) <= 5000),
+	CONSTRAINT constraint_sla_incidents_on_source_and_external_id UNIQUE (source, external_id)
+);
+CREATE INDEX index_sla_incidents_on_starts_at_and_ends_at ON sla_incidents(starts_at, ends_at);
+
 DROP TABLE IF EXISTS applied_slas CASCADE;
 CREATE TABLE applied_slas (
 	id BIGSERIAL PRIMARY KEY,
@@ -530,7 +1093,11 @@ CREATE TABLE applied_slas (
 	first_response_breached_at TIMESTAMPTZ NULL,
 	resolution_breached_at TIMESTAMPTZ NULL,
 	first_response_met_at TIMESTAMPTZ NULL,
-	resolution_met_at TIMESTAMPTZ NULL
+	resolution_met_at TIMESTAMPTZ NULL,
+
+	-- Incident that was ongoing when the metric breached, if any.
+	first_response_incident_id INT REFERENCES sla_incidents(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	resolution_incident_id INT REFERENCES sla_incidents(id) ON DELETE SET NULL ON UPDATE CASCADE NULL
 );
 CREATE INDEX index_applied_slas_on_conversation_id ON applied_slas(conversation_id);
 CREATE INDEX index_applied_slas_on_status ON applied_slas(status);
@@ -546,7 +1113,9 @@ CREATE TABLE sla_events (
 	type sla_metric NOT NULL,
 	deadline_at TIMESTAMPTZ NOT NULL,
 	met_at TIMESTAMPTZ,
-	breached_at TIMESTAMPTZ
+	breached_at TIMESTAMPTZ,
+	-- Incident that was ongoing when the event breached, if any.
+	incident_id INT REFERENCES sla_incidents(id) ON DELETE SET NULL ON UPDATE CASCADE NULL
 );
 CREATE INDEX index_sla_events_on_applied_sla_id ON sla_events(applied_sla_id);
 CREATE INDEX index_sla_events_on_status ON sla_events(status);
@@ -594,6 +1163,62 @@ CREATE TABLE ai_prompts (
 );
 CREATE INDEX index_ai_prompts_on_key ON ai_prompts USING btree (key);
 
+DROP TABLE IF EXISTS conversation_embeddings CASCADE;
+CREATE TABLE conversation_embeddings (
+	conversation_id BIGINT PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	embedding DOUBLE PRECISION[] NOT NULL
+);
+
+DROP TABLE IF EXISTS conversation_topics CASCADE;
+CREATE TABLE conversation_topics (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	period_start TIMESTAMPTZ NOT NULL,
+	period_end TIMESTAMPTZ NOT NULL,
+	label TEXT NOT NULL,
+	conversation_count INT NOT NULL,
+	-- Conversations in the preceding period of the same length that fall into the topic.
+	previous_count INT DEFAULT 0 NOT NULL,
+	conversation_ids BIGINT[] NOT NULL
+);
+CREATE INDEX index_conversation_topics_on_period_end ON conversation_topics(period_end);
+
+DROP TABLE IF EXISTS nps_surveys CASCADE;
+CREATE TABLE nps_surveys (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	uuid UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+	-- Cascade deletes when contact is deleted.
+	contact_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- NULL until the contact responds.
+	rating INT NULL,
+	"comment" TEXT NULL,
+	responded_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_nps_surveys_on_rating CHECK (rating >= 0 AND rating <= 10),
+	CONSTRAINT constraint_nps_surveys_on_comment CHECK (length("comment") <= 1000)
+);
+CREATE INDEX index_nps_surveys_on_contact_id_created_at ON nps_surveys(contact_id, created_at);
+CREATE INDEX index_nps_surveys_on_created_at ON nps_surveys(created_at);
+
+DROP TABLE IF EXISTS abuse_flags CASCADE;
+CREATE TABLE abuse_flags (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	message_id BIGINT REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL UNIQUE,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	contact_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Agent the conversation was assigned to when the message arrived.
+	assigned_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	-- 'word_list' or 'classifier'.
+	"source" TEXT NOT NULL,
+	-- Words of the word list found in the message.
+	matches TEXT[] DEFAULT '{}'::TEXT[] NOT NULL
+);
+CREATE INDEX index_abuse_flags_on_created_at ON abuse_flags(created_at);
+CREATE INDEX index_abuse_flags_on_conversation_id ON abuse_flags(conversation_id);
+
 DROP TABLE IF EXISTS custom_attribute_definitions CASCADE;
 CREATE TABLE custom_attribute_definitions (
 	id SERIAL PRIMARY KEY,
@@ -607,6 +1232,7 @@ CREATE TABLE custom_attribute_definitions (
 	data_type TEXT NOT NULL,
 	regex TEXT NULL,
 	regex_hint TEXT NULL,
+	required BOOLEAN DEFAULT FALSE NOT NULL,
 	CONSTRAINT constraint_custom_attribute_definitions_on_name CHECK (length("name") <= 140),
 	CONSTRAINT constraint_custom_attribute_definitions_on_description CHECK (length(description) <= 300),
 	CONSTRAINT constraint_custom_attribute_definitions_on_key CHECK (length(key) <= 140),
@@ -628,6 +1254,21 @@ CREATE TABLE contact_notes (
 );
 CREATE INDEX index_contact_notes_on_contact_id_created_at ON contact_notes (contact_id, created_at);
 
+DROP TABLE IF EXISTS contact_preferences CASCADE;
+CREATE TABLE contact_preferences (
+	contact_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	-- Suppresses all messages not sent by an agent, e.g. CSAT surveys, continuity emails and automated replies.
+	do_not_contact BOOLEAN DEFAULT FALSE NOT NULL,
+	no_surveys BOOLEAN DEFAULT FALSE NOT NULL,
+	no_marketing BOOLEAN DEFAULT FALSE NOT NULL,
+	preferred_channel channels NULL,
+	-- Suppresses the digest of open conversations.
+	no_digest BOOLEAN DEFAULT FALSE NOT NULL,
+	digest_sent_at TIMESTAMPTZ NULL
+);
+
 DROP TABLE IF EXISTS activity_logs CASCADE;
 CREATE TABLE activity_logs (
 	id BIGSERIAL PRIMARY KEY,
@@ -654,6 +1295,9 @@ CREATE TABLE webhooks (
 	events webhook_event[] NOT NULL DEFAULT '{}',
 	secret TEXT DEFAULT '',
 	is_active BOOLEAN DEFAULT true,
+	-- Dot separated paths of the payload fields sent, all if empty, and of the fields left out.
+	include_fields TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	exclude_fields TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
 	CONSTRAINT constraint_webhooks_on_name CHECK (length(name) <= 255),
 	CONSTRAINT constraint_webhooks_on_url CHECK (length(url) <= 2048),
 	CONSTRAINT constraint_webhooks_on_secret CHECK (length(secret) <= 255),
@@ -697,6 +1341,107 @@ CREATE INDEX index_user_notifications_on_user_id_is_read ON user_notifications(u
 CREATE INDEX index_user_notifications_on_created_at ON user_notifications(created_at);
 CREATE INDEX index_user_notifications_on_conversation_id ON user_notifications(conversation_id);
 
+DROP TABLE IF EXISTS maintenance_windows CASCADE;
+CREATE TABLE maintenance_windows (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"name" TEXT NOT NULL,
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL,
+	-- Set once the notifications held during the window were summarized.
+	summarized_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_maintenance_windows_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_maintenance_windows_on_ends_at CHECK (ends_at > starts_at)
+);
+CREATE INDEX index_maintenance_windows_on_starts_at_and_ends_at ON maintenance_windows(starts_at, ends_at);
+
+-- Non-critical notifications held during maintenance windows.
+DROP TABLE IF EXISTS held_notifications CASCADE;
+CREATE TABLE held_notifications (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	window_id INT REFERENCES maintenance_windows(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	notification_type user_notification_type NOT NULL,
+	title TEXT NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE NULL
+);
+CREATE INDEX index_held_notifications_on_window_id ON held_notifications(window_id);
+
+DROP TABLE IF EXISTS user_device_tokens CASCADE;
+CREATE TABLE user_device_tokens (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	platform device_platform NOT NULL,
+	token TEXT NOT NULL UNIQUE,
+	device_name TEXT NULL,
+	CONSTRAINT constraint_user_device_tokens_on_token CHECK (length(token) <= 4096),
+	CONSTRAINT constraint_user_device_tokens_on_device_name CHECK (length(device_name) <= 255)
+);
+CREATE INDEX index_user_device_tokens_on_user_id ON user_device_tokens(user_id);
+
+DROP TABLE IF EXISTS announcements CASCADE;
+CREATE TABLE announcements (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	message TEXT NOT NULL,
+	severity announcement_severity DEFAULT 'info' NOT NULL,
+	starts_at TIMESTAMPTZ NULL,
+	ends_at TIMESTAMPTZ NULL,
+	-- Empty means all agents.
+	team_ids INT[] DEFAULT '{}' NOT NULL,
+	created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	-- Set once the announcement has been pushed to connected agents over websocket.
+	broadcast_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_announcements_on_message CHECK (length(message) <= 2000)
+);
+CREATE INDEX index_announcements_on_ends_at ON announcements(ends_at);
+
+DROP TABLE IF EXISTS announcement_acknowledgements CASCADE;
+CREATE TABLE announcement_acknowledgements (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	announcement_id INT REFERENCES announcements(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	CONSTRAINT constraint_announcement_acknowledgements_unique UNIQUE (announcement_id, user_id)
+);
+
+DROP TABLE IF EXISTS assets CASCADE;
+CREATE TABLE assets (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"name" TEXT NOT NULL,
+	description TEXT DEFAULT '' NOT NULL,
+	media_id INT REFERENCES media(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Empty means all agents.
+	team_ids INT[] DEFAULT '{}' NOT NULL,
+	created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	usage_count INT DEFAULT 0 NOT NULL,
+	last_used_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_assets_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_assets_on_description CHECK (length(description) <= 1000)
+);
+
+DROP TABLE IF EXISTS inbox_sender_rules CASCADE;
+CREATE TABLE inbox_sender_rules (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Lowercased sender address.
+	email TEXT NOT NULL,
+	"type" sender_rule_type NOT NULL,
+	created_by_id BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	-- Conversation marked as spam that created the rule, if any.
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE,
+	CONSTRAINT constraint_inbox_sender_rules_on_inbox_id_and_email_unique UNIQUE (inbox_id, email)
+);
+
 INSERT INTO ai_providers
 ("name", provider, config, is_default)
 VALUES('openai', 'openai', '{"api_key": ""}'::jsonb, true);
@@ -735,7 +1480,16 @@ VALUES
 	('notification.email.hello_hostname', '""'::jsonb),
     ('notification.email.email_address', '"admin@yourcompany.com"'::jsonb),
     ('notification.email.max_msg_retries', '3'::jsonb),
-    ('notification.email.enabled', 'false'::jsonb);
+    ('notification.email.enabled', 'false'::jsonb),
+    ('captcha.provider', '""'::jsonb),
+    ('captcha.site_key', '""'::jsonb),
+    ('captcha.secret_key', '""'::jsonb),
+    ('captcha.endpoints', '[]'::jsonb),
+    ('abuse.enabled', 'false'::jsonb),
+    ('abuse.words', '[]'::jsonb),
+    ('abuse.classifier', 'false'::jsonb),
+    ('abuse.tag', '""'::jsonb),
+    ('abuse.notify_team_id', '0'::jsonb);
 
 -- Default conversation priorities
 INSERT INTO conversation_priorities (name) VALUES
@@ -748,7 +1502,14 @@ INSERT INTO conversation_statuses (name, category) VALUES
 ('Open', 'open'),
 ('Snoozed', 'waiting'),
 ('Resolved', 'resolved'),
-('Closed', 'resolved');
+('Closed', 'resolved'),
+('Spam', 'resolved');
+
+-- Default snooze presets
+INSERT INTO snooze_presets (name, kind, duration, weekday, time_of_day, sort_order) VALUES
+('Later today', 'duration', '3h', '', '', 1),
+('Tomorrow morning', 'next_business_day', '', '', '09:00', 2),
+('Next Monday 9am', 'weekday', '', 'Monday', '09:00', 3);
 
 -- Default roles
 INSERT INTO
@@ -757,7 +1518,7 @@ VALUES
 	(
 		'Agent',
 		'Role for all agents with limited access to conversations.',
-		'{conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read_team_all,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,messages:read,messages:write,view:manage}'
+		'{conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read_team_all,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,conversations:update_custom_attributes,conversations:trash,messages:read,messages:write,messages:write_private,view:manage}'
 	);
 
 INSERT INTO
@@ -766,7 +1527,16 @@ VALUES
 	(
 		'Admin',
 		'Role for users who have complete access to everything.',
-		'{webhooks:manage,context_links:manage,activity_logs:manage,custom_attributes:manage,contacts:read_all,contacts:read,contacts:write,contacts:block,contact_notes:read,contact_notes:write,contact_notes:delete,conversations:write,ai:manage,general_settings:manage,notification_settings:manage,oidc:manage,conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read_team_all,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,messages:read,messages:write,view:manage,shared_views:manage,status:manage,tags:manage,macros:manage,users:manage,teams:manage,automations:manage,inboxes:manage,roles:manage,reports:manage,templates:manage,business_hours:manage,sla:manage}'
+		'{messages:write_integration_notes,webhooks:manage,context_links:manage,announcements:manage,assets:manage,activity_logs:manage,custom_attributes:manage,contacts:read_all,contacts:read,contacts:write,contacts:block,contacts:manage_risk_flags,contact_notes:read,contact_notes:write,contact_notes:delete,conversations:write,ai:manage,general_settings:manage,notification_settings:manage,oidc:manage,conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read_team_all,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,conversations:update_custom_attributes,messages:read,messages:write,messages:write_private,view:manage,shared_views:manage,status:manage,tags:manage,macros:manage,users:manage,teams:manage,automations:manage,inboxes:manage,roles:manage,reports:manage,templates:manage,business_hours:manage,sla:manage}'
+	);
+
+INSERT INTO
+	roles ("name", description, permissions)
+VALUES
+	(
+		'Collaborator',
+		'Role for users who can read conversations and post private notes, but cannot reply to contacts or change conversations.',
+		'{conversations:read_all,conversations:read,messages:read,messages:write_private}'
 	);
 
 
@@ -778,8 +1548,18 @@ VALUES('email_notification'::template_type, '
 
 <div>
     Reference number: {{ .Conversation.ReferenceNumber }} <br>
-    Subject: {{ .Conversation.Subject }}
+    Subject: {{ .Conversation.Subject }}{{ if .Conversation.Summary }} <br>
+    Summary: {{ .Conversation.Summary }}{{ end }}
+</div>
+
+{{ if .Handoff.Summary }}
+<div>
+    <p>Handoff note from {{ .Handoff.FromName }}:</p>
+    Summary: {{ .Handoff.Summary }} <br>
+    {{ if .Handoff.CurrentState }}Current state: {{ .Handoff.CurrentState }} <br>{{ end }}
+    {{ if .Handoff.NextStep }}Next step: {{ .Handoff.NextStep }}{{ end }}
 </div>
+{{ end }}
 
 <p>
     <a href="{{ RootURL }}/inboxes/assigned/conversation/{{ .Conversation.UUID }}">View Conversation</a>
@@ -861,6 +1641,9 @@ VALUES (
   'email_notification'::template_type,
   '
 <p>{{ .MentionedBy.FullName }} mentioned you in a private note on conversation #{{ .Conversation.ReferenceNumber }}.</p>
+{{ if .Conversation.Summary }}
+<p>Summary: {{ .Conversation.Summary }}</p>
+{{ end }}
 
 <blockquote style="background-color: #f5f5f5; padding: 12px; margin: 16px 0; border-left: 4px solid #ddd;">
 {{ .Message.Content }}
@@ -893,40 +1676,22 @@ VALUES (
   We would love to hear how it went.
 </p>
 <p style="margin: 0 0 20px; font-size: 14px; font-weight: 600; color: #374151; text-align: center;">
-  How would you rate your experience?
+  {{ if .CSATQuestion }}{{ .CSATQuestion }}{{ else if eq .CSATScale "nps" }}How likely are you to recommend us to a friend or colleague?{{ else }}How would you rate your experience?{{ end }}
 </p>
-<!-- Variable CSATUUID is also available -->
-<div style="text-align: center; margin: 0 auto; max-width: 400px; font-size: 0;">
-  <div style="display: inline-block; width: 72px; text-align: center; vertical-align: top; padding: 4px 0;">
-    <a href="{{ .CSATLink }}?rating=1" style="text-decoration: none; display: block;">
-      <span style="font-size: 34px; display: block; line-height: 1.4;">&#128546;</span>
-      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">Poor</span>
-    </a>
-  </div>
-  <div style="display: inline-block; width: 72px; text-align: center; vertical-align: top; padding: 4px 0;">
-    <a href="{{ .CSATLink }}?rating=2" style="text-decoration: none; display: block;">
-      <span style="font-size: 34px; display: block; line-height: 1.4;">&#128533;</span>
-      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">Fair</span>
-    </a>
-  </div>
-  <div style="display: inline-block; width: 72px; text-align: center; vertical-align: top; padding: 4px 0;">
-    <a href="{{ .CSATLink }}?rating=3" style="text-decoration: none; display: block;">
-      <span style="font-size: 34px; display: block; line-height: 1.4;">&#128522;</span>
-      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">Good</span>
-    </a>
-  </div>
-  <div style="display: inline-block; width: 72px; text-align: center; vertical-align: top; padding: 4px 0;">
-    <a href="{{ .CSATLink }}?rating=4" style="text-decoration: none; display: block;">
-      <span style="font-size: 34px; display: block; line-height: 1.4;">&#128515;</span>
-      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">Great</span>
-    </a>
-  </div>
-  <div style="display: inline-block; width: 72px; text-align: center; vertical-align: top; padding: 4px 0;">
-    <a href="{{ .CSATLink }}?rating=5" style="text-decoration: none; display: block;">
-      <span style="font-size: 34px; display: block; line-height: 1.4;">&#129321;</span>
-      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">Excellent</span>
+<!-- Variables CSATUUID and CSATScale (stars, thumbs or nps) are also available -->
+<div style="text-align: center; margin: 0 auto; max-width: 440px; font-size: 0;">
+  {{ range .CSATRatings }}
+  <div style="display: inline-block; width: {{ if .Emoji }}72px{{ else }}40px{{ end }}; text-align: center; vertical-align: top; padding: 4px 0;">
+    <a href="{{ .Link }}" style="text-decoration: none; display: block;">
+      {{ if .Emoji }}
+      <span style="font-size: 34px; display: block; line-height: 1.4;">{{ .Emoji }}</span>
+      <span style="font-size: 10px; display: block; font-weight: 600; color: #b0b5bd; text-transform: uppercase; letter-spacing: 0.05em; margin-top: 4px;">{{ .Label }}</span>
+      {{ else }}
+      <span style="font-size: 15px; display: block; font-weight: 600; color: #374151; line-height: 32px; margin: 0 2px; border: 1px solid #e5e7eb; border-radius: 6px;">{{ .Value }}</span>
+      {{ end }}
     </a>
   </div>
+  {{ end }}
 </div>
 ',
   false,
@@ -934,3 +1699,107 @@ VALUES (
   '',
   true
 );
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES (
+  'email_notification'::template_type,
+  '
+<p>Hi {{ .Contact.FirstName }},</p>
+<p>Here is a summary of your open conversations with us.</p>
+<table style="width: 100%; border-collapse: collapse; font-size: 14px;">
+  <tr>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Reference</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Subject</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Status</th>
+  </tr>
+  {{ range .Conversations }}
+  <tr>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ if .Link }}<a href="{{ .Link }}">#{{ .ReferenceNumber }}</a>{{ else }}#{{ .ReferenceNumber }}{{ end }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Subject }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Status }}</td>
+  </tr>
+  {{ end }}
+</table>
+<p style="font-size: 12px; color: #9ca3af;">Reply to any of your conversations to add more information.</p>
+',
+  false,
+  'Open conversations digest',
+  'Your open conversations',
+  true
+);
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES (
+  'email_notification'::template_type,
+  '
+<p>Hi {{ .Agent.FirstName }},</p>
+<p>Here is the activity on conversations you are following but are not assigned to.</p>
+<table style="width: 100%; border-collapse: collapse; font-size: 14px;">
+  <tr>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Reference</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Subject</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">Status</th>
+    <th style="text-align: left; padding: 8px; border-bottom: 1px solid #e5e7eb;">New messages</th>
+  </tr>
+  {{ range .Conversations }}
+  <tr>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;"><a href="{{ RootURL }}/inboxes/all/conversation/{{ .UUID }}">#{{ .ReferenceNumber }}</a></td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Subject }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .Status }}</td>
+    <td style="padding: 8px; border-bottom: 1px solid #f3f4f6;">{{ .NewMessages }}</td>
+  </tr>
+  {{ end }}
+</table>
+<p style="font-size: 12px; color: #9ca3af;">You can change how often you receive this digest in your notification preferences.</p>
+',
+  false,
+  'Watched conversations digest',
+  'Activity on conversations you watch',
+  true
+);
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES (
+  'email_notification'::template_type,
+  '
+<p>Hi {{ .Contact.FirstName }},</p>
+<p>How likely are you to recommend us to a friend or colleague?</p>
+<table style="border-collapse: collapse;">
+  <tr>
+    {{ range .Ratings }}
+    <td style="padding: 2px;"><a href="{{ .Link }}" style="display: inline-block; width: 32px; padding: 8px 0; text-align: center; border: 1px solid #e5e7eb; border-radius: 4px; text-decoration: none; color: #111827;">{{ .Value }}</a></td>
+    {{ end }}
+  </tr>
+</table>
+<p style="font-size: 12px; color: #6b7280;">0 is not likely at all, 10 is extremely likely.</p>
+<p style="font-size: 12px; color: #9ca3af;">To stop receiving these surveys, <a href="{{ .UnsubscribeLink }}">unsubscribe</a>.</p>
+',
+  false,
+  'NPS survey',
+  'How likely are you to recommend us?',
+  true
+);
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES (
+  'email_notification'::template_type,
+  '
+<p>Hi {{ .Contact.FirstName }},</p>
+<p>Here is the transcript of your conversation <strong>#{{ .Conversation.ReferenceNumber }}</strong>{{ if .Conversation.Subject }}, {{ html .Conversation.Subject }}{{ end }}.</p>
+{{ range .Messages }}
+<div style="border-top: 1px solid #e5e7eb; padding: 12px 0;">
+  <p style="margin: 0 0 6px; font-size: 12px; color: #6b7280;"><strong style="color: #111827;">{{ html .AuthorName }}</strong> &middot; {{ .CreatedAt }}</p>
+  <div style="white-space: pre-wrap; font-size: 14px; color: #374151;">{{ html .Text }}</div>
+  {{ if .Attachments }}<p style="margin: 6px 0 0; font-size: 12px; color: #6b7280;">Attachments: {{ range $i, $name := .Attachments }}{{ if $i }}, {{ end }}{{ html $name }}{{ end }}</p>{{ end }}
+</div>
+{{ end }}
+',
+  false,
+  'Conversation transcript',
+  '',
+  true
+);
@@ -314,7 +314,8 @@
             this.postToIframe({
                 type: 'PAGE_VISIT',
                 url: window.location.href,
-                title: document.title || ''
+                title: document.title || '',
+                referrer: document.referrer || ''
             });
         }
 